@@ -18,6 +18,7 @@ import (
 // - Schedule
 // - StartingDeadlineSeconds
 // - ConcurrencyPolicy
+// - Suspend
 // - SuccessfulJobsHistoryLimit
 // - FailedJobsHistoryLimit
 //
@@ -52,16 +53,25 @@ type BackupPolicySpec struct {
 	// +optional
 	ConcurrencyPolicy batchv1.ConcurrencyPolicy `json:"concurrencyPolicy,omitempty"`
 
+	// Suspend tells the CronJob to suspend subsequent backups.
+	// Backups that have already started are not affected.
+	// Setting this back to false resumes the scheduled backups.
+	// Defaults to false.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
 	// Specifies the duration in seconds relative to the startTime that the job
 	// may be continuously active before the system tries to terminate it; value
 	// must be positive integer. If a Job is suspended (at creation or through an
 	// update), this timer will effectively be stopped and reset when the Job is
 	// resumed again.
+	// Defaults to 86400 (24 hours) so that retries of a failing backup do not run forever.
 	// +nullable
 	// +optional
 	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
 
 	// Specifies the number of retries before marking this job failed.
+	// Failed Pods are retried with an exponential back-off delay (10s, 20s, 40s ...) capped at six minutes.
 	// Defaults to 6
 	// +kubebuilder:validation:Minimum=0
 	// +nullable
@@ -83,8 +93,26 @@ type BackupPolicySpec struct {
 	// +nullable
 	// +optional
 	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+
+	// BackupAffinity controls where backup Pods are scheduled relative to the Pods of MySQL.
+	// Valid values are:
+	// - "None" (default): does not add any preference;
+	// - "PreferReplica": prefers the nodes running a replica instance of the cluster;
+	// - "AvoidPrimary": prefers the nodes not running the primary instance of the cluster.
+	// The preference is added to the affinity in jobConfig.
+	// +kubebuilder:validation:Enum=None;PreferReplica;AvoidPrimary
+	// +kubebuilder:default=None
+	// +optional
+	BackupAffinity string `json:"backupAffinity,omitempty"`
 }
 
+// Values of BackupAffinity.
+const (
+	BackupAffinityNone          = "None"
+	BackupAffinityPreferReplica = "PreferReplica"
+	BackupAffinityAvoidPrimary  = "AvoidPrimary"
+)
+
 func (s *BackupPolicySpec) validate() (admission.Warnings, field.ErrorList) {
 	var allErrs field.ErrorList
 	p := field.NewPath("spec")
@@ -92,6 +120,16 @@ func (s *BackupPolicySpec) validate() (admission.Warnings, field.ErrorList) {
 	if _, err := cron.ParseStandard(s.Schedule); err != nil {
 		allErrs = append(allErrs, field.Invalid(p.Child("schedule"), s.Schedule, err.Error()))
 	}
+	allErrs = append(allErrs, s.JobConfig.validate(p.Child("jobConfig"))...)
+	if s.JobConfig.Image != "" || len(s.JobConfig.Command) > 0 || len(s.JobConfig.Args) > 0 {
+		allErrs = append(allErrs, field.Forbidden(p.Child("jobConfig", "image"), "a custom container is not supported for backup jobs"))
+	}
+	if s.ActiveDeadlineSeconds != nil && *s.ActiveDeadlineSeconds <= 0 {
+		allErrs = append(allErrs, field.Invalid(p.Child("activeDeadlineSeconds"), *s.ActiveDeadlineSeconds, "must be a positive integer"))
+	}
+	if s.BackoffLimit != nil && *s.BackoffLimit < 0 {
+		allErrs = append(allErrs, field.Invalid(p.Child("backoffLimit"), *s.BackoffLimit, "must not be negative"))
+	}
 
 	return nil, allErrs
 }
@@ -4,10 +4,13 @@ import (
 	"context"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	batchv1 "k8s.io/api/batch/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -75,6 +78,126 @@ var _ = Describe("BackupPolicy Webhook", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should validate podFailurePolicy", func() {
+		for _, rule := range []mocov1beta2.PodFailurePolicyRule{
+			{Action: "FailJob", ExitCodes: []int32{0}},
+			{Action: "FailJob", ExitCodes: []int32{1, 1}},
+			{Action: "FailJob"},
+			{Action: "FailIndex", ExitCodes: []int32{1}},
+		} {
+			r := makeBackupPolicy()
+			r.Spec.JobConfig.PodFailurePolicy = []mocov1beta2.PodFailurePolicyRule{rule}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "%+v", rule)
+		}
+
+		r := makeBackupPolicy()
+		r.Spec.JobConfig.PodFailurePolicy = []mocov1beta2.PodFailurePolicyRule{
+			{Action: "FailJob", ExitCodes: []int32{1, 2}},
+			{Action: "Ignore", ExitCodes: []int32{137}},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate includeDatabases and excludeDatabases", func() {
+		for _, jc := range []mocov1beta2.JobConfig{
+			{IncludeDatabases: []string{"foo"}, ExcludeDatabases: []string{"bar"}},
+			{IncludeDatabases: []string{""}},
+			{IncludeDatabases: []string{"foo,bar"}},
+			{ExcludeDatabases: []string{"foo", "foo"}},
+		} {
+			r := makeBackupPolicy()
+			r.Spec.JobConfig.IncludeDatabases = jc.IncludeDatabases
+			r.Spec.JobConfig.ExcludeDatabases = jc.ExcludeDatabases
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "include=%v, exclude=%v", jc.IncludeDatabases, jc.ExcludeDatabases)
+		}
+
+		r := makeBackupPolicy()
+		r.Spec.JobConfig.ExcludeDatabases = []string{"analytics", "tmp"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate partSize", func() {
+		for _, size := range []string{"4Mi", "5242879", "6Gi"} {
+			r := makeBackupPolicy()
+			q := resource.MustParse(size)
+			r.Spec.JobConfig.PartSize = &q
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "partSize=%s", size)
+		}
+
+		r := makeBackupPolicy()
+		q := resource.MustParse("5Gi")
+		r.Spec.JobConfig.PartSize = &q
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate caBundle", func() {
+		for _, cb := range []*mocov1beta2.CABundleSource{
+			{},
+			{ConfigMapName: "foo", SecretName: "bar"},
+		} {
+			r := makeBackupPolicy()
+			r.Spec.JobConfig.CABundle = cb
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "caBundle=%+v", cb)
+		}
+
+		r := makeBackupPolicy()
+		r.Spec.JobConfig.CABundle = &mocov1beta2.CABundleSource{ConfigMapName: "foo"}
+		r.Spec.JobConfig.BucketConfig.CaCert = "/ca/ca.crt"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeBackupPolicy()
+		r.Spec.JobConfig.CABundle = &mocov1beta2.CABundleSource{SecretName: "foo", Key: "bundle.pem"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny reserved volume names", func() {
+		for _, name := range []string{constants.JobWorkVolumeName, constants.JobCABundleVolumeName} {
+			r := makeBackupPolicy()
+			r.Spec.JobConfig.Volumes = []mocov1beta2.VolumeApplyConfiguration{
+				mocov1beta2.VolumeApplyConfiguration(*corev1ac.Volume().WithName(name).WithEmptyDir(corev1ac.EmptyDirVolumeSource())),
+			}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), name)
+		}
+	})
+
+	It("should validate workVolumeMountPath", func() {
+		for _, path := range []string{"work", "/", "/work/", "/var/../work"} {
+			r := makeBackupPolicy()
+			r.Spec.JobConfig.WorkVolumeMountPath = path
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "workVolumeMountPath=%s", path)
+		}
+
+		r := makeBackupPolicy()
+		r.Spec.JobConfig.WorkVolumeMountPath = "/etc/moco-backup"
+		r.Spec.JobConfig.CABundle = &mocov1beta2.CABundleSource{ConfigMapName: "foo"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeBackupPolicy()
+		r.Spec.JobConfig.WorkVolumeMountPath = "/var/lib/backup"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny BackupPolicy with a custom container", func() {
+		r := makeBackupPolicy()
+		r.Spec.JobConfig.Image = "custom-backup:1"
+		r.Spec.JobConfig.Args = []string{"--foo"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should deny BackupPolicy with invalid backoffLimit", func() {
 		r := makeBackupPolicy()
 		r.Spec.BackoffLimit = ptr.To[int32](-1)
@@ -82,6 +205,13 @@ var _ = Describe("BackupPolicy Webhook", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should deny BackupPolicy with invalid activeDeadlineSeconds", func() {
+		r := makeBackupPolicy()
+		r.Spec.ActiveDeadlineSeconds = ptr.To[int64](0)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should deny BackupPolicy with invalid successfulJobsHistoryLimit", func() {
 		r := makeBackupPolicy()
 		r.Spec.SuccessfulJobsHistoryLimit = ptr.To[int32](-1)
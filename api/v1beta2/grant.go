@@ -0,0 +1,127 @@
+package v1beta2
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Grant represents an item of `spec.users[].grants`.
+// +kubebuilder:object:generate=false
+type Grant struct {
+	// Privileges are the names of the privileges in upper case, e.g. "SELECT".
+	// "ALL" is normalized to "ALL PRIVILEGES".
+	Privileges []string
+
+	// Database is the name of the database, or "*" for all databases.
+	Database string
+
+	// Table is the name of the table, or "*" for all tables.
+	Table string
+}
+
+var privilegeRegexp = regexp.MustCompile(`^[A-Z_]+( [A-Z_]+)*$`)
+
+// ParseGrant parses a grant in the form of `<privileges> ON <database>.<table>`.
+// Privileges are separated by commas, and each of them must consist of words of letters
+// and underscores, e.g. "SELECT" or "CREATE TEMPORARY TABLES".  Column privileges are not supported.
+// The database and the table are `*` or names optionally quoted with backticks.
+func ParseGrant(s string) (*Grant, error) {
+	idx := strings.Index(strings.ToUpper(s), " ON ")
+	if idx < 0 {
+		return nil, errors.New("must be in the form of `<privileges> ON <database>.<table>`")
+	}
+
+	g := &Grant{}
+	for _, p := range strings.Split(s[:idx], ",") {
+		p = strings.ToUpper(strings.Join(strings.Fields(p), " "))
+		if !privilegeRegexp.MatchString(p) {
+			return nil, fmt.Errorf("invalid privilege %q", p)
+		}
+		if p == "ALL" {
+			p = "ALL PRIVILEGES"
+		}
+		g.Privileges = append(g.Privileges, p)
+	}
+
+	db, rest, err := parseGrantName(strings.TrimSpace(s[idx+len(" ON "):]))
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(rest, ".") {
+		return nil, errors.New("the privilege level must be in the form of `<database>.<table>`")
+	}
+	table, rest, err := parseGrantName(rest[1:])
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(rest) != "" {
+		return nil, fmt.Errorf("unexpected %q after the privilege level", rest)
+	}
+	if db == "*" && table != "*" {
+		return nil, errors.New("a table cannot be specified for all databases")
+	}
+	g.Database = db
+	g.Table = table
+	return g, nil
+}
+
+func isUnquotedNameChar(c byte) bool {
+	return c == '_' || c == '$' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// parseGrantName parses `*` or a database or table name at the beginning of s,
+// and returns the name and the rest of s.
+func parseGrantName(s string) (string, string, error) {
+	if strings.HasPrefix(s, "*") {
+		return "*", s[1:], nil
+	}
+
+	if strings.HasPrefix(s, "`") {
+		var sb strings.Builder
+		for i := 1; i < len(s); i++ {
+			if s[i] != '`' {
+				sb.WriteByte(s[i])
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '`' {
+				sb.WriteByte('`')
+				i++
+				continue
+			}
+			name := sb.String()
+			if name == "" || name == "*" {
+				return "", "", fmt.Errorf("invalid name %q", name)
+			}
+			return name, s[i+1:], nil
+		}
+		return "", "", errors.New("unterminated quoted name")
+	}
+
+	i := 0
+	for i < len(s) && isUnquotedNameChar(s[i]) {
+		i++
+	}
+	if i == 0 {
+		return "", "", fmt.Errorf("invalid name at %q", s)
+	}
+	return s[:i], s[i:], nil
+}
+
+func quoteGrantName(name string) string {
+	if name == "*" {
+		return name
+	}
+	return "`" + strings.ReplaceAll(name, "`", "``") + "`"
+}
+
+// Level returns the privilege level of the grant with the names quoted, e.g. "`app`.*".
+func (g *Grant) Level() string {
+	return quoteGrantName(g.Database) + "." + quoteGrantName(g.Table)
+}
+
+// String returns the grant in the form of `<privileges> ON <level>`.
+func (g *Grant) String() string {
+	return strings.Join(g.Privileges, ", ") + " ON " + g.Level()
+}
@@ -2,8 +2,13 @@ package v1beta2
 
 import (
 	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
 
+	"github.com/cybozu-go/moco/pkg/constants"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation/field"
 	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 )
 
@@ -24,6 +29,13 @@ type JobConfig struct {
 	// https://kubernetes.io/docs/concepts/storage/ephemeral-volumes/#generic-ephemeral-volumes
 	WorkVolume VolumeSourceApplyConfiguration `json:"workVolume"`
 
+	// WorkVolumeMountPath is the path to mount WorkVolume in the container.
+	// This is useful for custom images that expect the working directory at another path.
+	// If not specified, `/work` is used.
+	//
+	// +optional
+	WorkVolumeMountPath string `json:"workVolumeMountPath,omitempty"`
+
 	// Threads is the number of threads used for backup or restoration.
 	// +kubebuilder:validation:Minimum=1
 	// +kubebuilder:default=4
@@ -77,6 +89,89 @@ type JobConfig struct {
 	// +optional
 	Affinity *AffinityApplyConfiguration `json:"affinity,omitempty"`
 
+	// SchedulerName is the name of the scheduler to dispatch the Pod.
+	// If not specified, the Pod will be dispatched by the default scheduler.
+	//
+	// +optional
+	SchedulerName string `json:"schedulerName,omitempty"`
+
+	// RuntimeClassName is the name of the RuntimeClass to run the Pod.
+	// If not specified, the default container runtime is used.
+	//
+	// +nullable
+	// +optional
+	RuntimeClassName *string `json:"runtimeClassName,omitempty"`
+
+	// TerminationGracePeriodSeconds is the duration in seconds the Pod needs to terminate gracefully.
+	// The backup or restore task uses this time to abort the ongoing uploads.
+	// If not specified, 60 seconds is used.
+	//
+	// +kubebuilder:validation:Minimum=0
+	// +nullable
+	// +optional
+	TerminationGracePeriodSeconds *int64 `json:"terminationGracePeriodSeconds,omitempty"`
+
+	// PodFailurePolicy is the list of rules to handle failures of the backup or restore
+	// container by its exit code.  This is set to `spec.podFailurePolicy` of the Job.
+	// It is ignored on Kubernetes clusters older than 1.26.
+	//
+	// +optional
+	PodFailurePolicy []PodFailurePolicyRule `json:"podFailurePolicy,omitempty"`
+
+	// IncludeDatabases is the list of databases to be backed up.
+	// If specified, other databases are not backed up.
+	// This cannot be specified together with ExcludeDatabases.
+	// This is ignored for restore jobs.
+	//
+	// Note that a backup of specific databases cannot be used for point-in-time recovery;
+	// it can only be restored to the time the backup was taken.
+	//
+	// +optional
+	IncludeDatabases []string `json:"includeDatabases,omitempty"`
+
+	// ExcludeDatabases is the list of databases not to be backed up.
+	// This cannot be specified together with IncludeDatabases.
+	// This is ignored for restore jobs.
+	//
+	// Note that a backup of specific databases cannot be used for point-in-time recovery;
+	// it can only be restored to the time the backup was taken.
+	//
+	// +optional
+	ExcludeDatabases []string `json:"excludeDatabases,omitempty"`
+
+	// PartSize is the size of each part of the multipart uploads to S3.
+	// It is raised for a large file so that the file is uploaded within the limit of the number of parts.
+	// It must be between 5Mi and 5Gi, the range that S3 allows.
+	// If not specified, moco-backup decides it from the size of each file.
+	// This is ignored for restore jobs, which do not upload files, and for GCS.
+	//
+	// +nullable
+	// +optional
+	PartSize *resource.Quantity `json:"partSize,omitempty"`
+
+	// Image is the container image of a custom restore tool.
+	// If specified, the restore Job runs this image with Command and Args instead of
+	// the built-in restore subcommand of moco-backup.  The container still gets
+	// MYSQL_PASSWORD environment variable and the working directory mounted on WorkVolumeMountPath.
+	// BucketConfig is not used in this mode.
+	// This can be specified only for restore jobs.
+	//
+	// +optional
+	Image string `json:"image,omitempty"`
+
+	// Command is the entrypoint of the custom restore container.
+	// If not specified, the entrypoint of Image is used.
+	// This requires Image.
+	//
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args is the arguments of the custom restore container.
+	// This requires Image.
+	//
+	// +optional
+	Args []string `json:"args,omitempty"`
+
 	// Volumes defines the list of volumes that can be mounted by containers in the Pod.
 	//
 	// +optional
@@ -86,6 +181,177 @@ type JobConfig struct {
 	//
 	// +optional
 	VolumeMounts []VolumeMountApplyConfiguration `json:"volumeMounts,omitempty"`
+
+	// CABundle refers to a ConfigMap or Secret that has a PEM-encoded CA bundle to verify
+	// the TLS certificate of the object storage.  The bundle is mounted in the container
+	// and used in addition to the system CAs.  It is also set to `AWS_CA_BUNDLE`
+	// environment variable.  If not specified, only the system CAs are used.
+	// This cannot be specified together with BucketConfig.CaCert.
+	//
+	// +nullable
+	// +optional
+	CABundle *CABundleSource `json:"caBundle,omitempty"`
+
+	// RoleRef refers to an existing Role or ClusterRole to be bound to ServiceAccountName.
+	// If specified, MOCO binds it instead of creating a Role for each MySQLCluster.
+	// The role must be labeled with `moco.cybozu.com/job-role: "true"`, and must grant
+	// the permissions that the backup or restore job needs.
+	//
+	// +nullable
+	// +optional
+	RoleRef *JobRoleRef `json:"roleRef,omitempty"`
+}
+
+// JobRoleRef refers to a Role in the same namespace or a ClusterRole.
+type JobRoleRef struct {
+	// Kind is the kind of the role.
+	//
+	// +kubebuilder:validation:Enum=Role;ClusterRole
+	Kind string `json:"kind"`
+
+	// Name is the name of the role.
+	//
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// CABundleSource refers to a CA bundle in a ConfigMap or Secret in the same namespace.
+// Exactly one of ConfigMapName and SecretName must be specified.
+type CABundleSource struct {
+	// ConfigMapName is the name of the ConfigMap that has the CA bundle.
+	// +optional
+	ConfigMapName string `json:"configMapName,omitempty"`
+
+	// SecretName is the name of the Secret that has the CA bundle.
+	// +optional
+	SecretName string `json:"secretName,omitempty"`
+
+	// Key is the key of the CA bundle in the ConfigMap or Secret.
+	// The default is "ca.crt".
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// BundleKey returns the key of the CA bundle in the ConfigMap or Secret.
+func (s *CABundleSource) BundleKey() string {
+	if s.Key != "" {
+		return s.Key
+	}
+	return constants.DefaultCABundleKey
+}
+
+// PodFailurePolicyRule maps exit codes of the backup or restore container to an action.
+type PodFailurePolicyRule struct {
+	// Action is the action taken when the container exits with one of ExitCodes.
+	// "FailJob" marks the Job as failed without retrying.
+	// "Ignore" retries the Pod without counting it towards the backoff limit.
+	// "Count" handles the failure in the default way.
+	// +kubebuilder:validation:Enum=FailJob;Ignore;Count
+	Action string `json:"action"`
+
+	// ExitCodes is the list of exit codes.  0 is not allowed.
+	// +kubebuilder:validation:MinItems=1
+	// +kubebuilder:validation:MaxItems=255
+	ExitCodes []int32 `json:"exitCodes"`
+}
+
+// HasCustomContainer returns true if the job runs a custom image instead of moco-backup.
+func (jc *JobConfig) HasCustomContainer() bool {
+	return jc.Image != ""
+}
+
+// WorkDir returns the path where WorkVolume is mounted in the container.
+func (jc *JobConfig) WorkDir() string {
+	if jc.WorkVolumeMountPath == "" {
+		return constants.DefaultJobWorkVolumeMount
+	}
+	return jc.WorkVolumeMountPath
+}
+
+func (jc *JobConfig) validate(p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if path := jc.WorkVolumeMountPath; path != "" {
+		pp := p.Child("workVolumeMountPath")
+		if !filepath.IsAbs(path) || filepath.Clean(path) != path || path == "/" {
+			allErrs = append(allErrs, field.Invalid(pp, path, "must be a clean absolute path other than /"))
+		} else if jc.CABundle != nil && (isSubPath(path, constants.JobCABundleMountPath) || isSubPath(constants.JobCABundleMountPath, path)) {
+			allErrs = append(allErrs, field.Invalid(pp, path, fmt.Sprintf("conflicts with the CA bundle mounted at %s", constants.JobCABundleMountPath)))
+		}
+	}
+
+	pp := p.Child("podFailurePolicy")
+	for i, rule := range jc.PodFailurePolicy {
+		seen := make(map[int32]bool)
+		for j, code := range rule.ExitCodes {
+			p := pp.Index(i).Child("exitCodes").Index(j)
+			if code == 0 {
+				allErrs = append(allErrs, field.Invalid(p, code, "0 is not allowed"))
+			}
+			if seen[code] {
+				allErrs = append(allErrs, field.Duplicate(p, code))
+			}
+			seen[code] = true
+		}
+	}
+
+	if jc.Image == "" && (len(jc.Command) > 0 || len(jc.Args) > 0) {
+		allErrs = append(allErrs, field.Required(p.Child("image"), "image is required to run a custom command"))
+	}
+	if jc.Image != "" && len(jc.Command) == 0 && len(jc.Args) == 0 {
+		allErrs = append(allErrs, field.Required(p.Child("args"), "command or args is required to run a custom image"))
+	}
+
+	if len(jc.IncludeDatabases) > 0 && len(jc.ExcludeDatabases) > 0 {
+		allErrs = append(allErrs, field.Forbidden(p.Child("excludeDatabases"), "excludeDatabases cannot be specified together with includeDatabases"))
+	}
+	allErrs = append(allErrs, validateDatabaseNames(p.Child("includeDatabases"), jc.IncludeDatabases)...)
+	allErrs = append(allErrs, validateDatabaseNames(p.Child("excludeDatabases"), jc.ExcludeDatabases)...)
+
+	if ps := jc.PartSize; ps != nil && (ps.Value() < constants.MinPartSize || ps.Value() > constants.MaxPartSize) {
+		allErrs = append(allErrs, field.Invalid(p.Child("partSize"), ps.String(), "must be between 5Mi and 5Gi"))
+	}
+
+	if cb := jc.CABundle; cb != nil {
+		pp := p.Child("caBundle")
+		if (cb.ConfigMapName == "") == (cb.SecretName == "") {
+			allErrs = append(allErrs, field.Invalid(pp, cb, "exactly one of configMapName and secretName must be specified"))
+		}
+		if jc.BucketConfig.CaCert != "" {
+			allErrs = append(allErrs, field.Forbidden(pp, "caBundle cannot be specified together with bucketConfig.caCert"))
+		}
+	}
+
+	for i, vol := range jc.Volumes {
+		if vol.Name == nil {
+			continue
+		}
+		switch *vol.Name {
+		case constants.JobWorkVolumeName, constants.JobCABundleVolumeName:
+			allErrs = append(allErrs, field.Invalid(p.Child("volumes").Index(i).Child("name"), *vol.Name, "reserved volume name"))
+		}
+	}
+
+	return allErrs
+}
+
+func validateDatabaseNames(p *field.Path, names []string) field.ErrorList {
+	var allErrs field.ErrorList
+
+	seen := make(map[string]bool)
+	for i, name := range names {
+		switch {
+		case name == "":
+			allErrs = append(allErrs, field.Required(p.Index(i), "database name must not be empty"))
+		case strings.Contains(name, ","):
+			allErrs = append(allErrs, field.Invalid(p.Index(i), name, "database name must not contain a comma"))
+		case seen[name]:
+			allErrs = append(allErrs, field.Duplicate(p.Index(i), name))
+		}
+		seen[name] = true
+	}
+
+	return allErrs
 }
 
 // VolumeSourceApplyConfiguration is the type defined to implement the DeepCopy method.
@@ -5,15 +5,24 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/cybozu-go/moco/pkg/constants"
 	"github.com/robfig/cron/v3"
 	corev1 "k8s.io/api/core/v1"
 	storagev1 "k8s.io/api/storage/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
 	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -29,10 +38,29 @@ type MySQLClusterSpec struct {
 	// Important: Run "make" to regenerate code after modifying this file
 
 	// Replicas is the number of instances. Available values are positive odd numbers.
+	// An even number is allowed only if the cluster is annotated with
+	// `moco.cybozu.com/allow-even-replicas: "true"`.
 	// +kubebuilder:default=1
 	// +optional
 	Replicas int32 `json:"replicas,omitempty"`
 
+	// Ordinals controls the numbering of the Pods.
+	// New clusters with this field are rejected on Kubernetes clusters not supporting `spec.ordinals` of StatefulSet.
+	// This field cannot be changed after the cluster is created.
+	// +optional
+	Ordinals *OrdinalsSpec `json:"ordinals,omitempty"`
+
+	// MinReadySeconds is the minimum number of seconds for which a replica should be ready
+	// before it becomes eligible to be the primary.
+	// This prevents promoting a replica that has just been added or cloned and is still catching up.
+	// A switchover chooses the new primary only from eligible replicas.  A failover is postponed
+	// if the replica with the most transactions is not eligible yet.
+	// This is also set to `spec.minReadySeconds` of the StatefulSet.
+	// The default is 0, that is, a replica is eligible as soon as it becomes ready.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+
 	// PodTemplate is a `Pod` template for MySQL server container.
 	PodTemplate PodTemplateSpec `json:"podTemplate"`
 
@@ -41,25 +69,116 @@ type MySQLClusterSpec struct {
 	// +kubebuilder:validation:MinItems=1
 	VolumeClaimTemplates []PersistentVolumeClaim `json:"volumeClaimTemplates"`
 
+	// PersistentVolumeClaimRetentionPolicy is set to `spec.persistentVolumeClaimRetentionPolicy`
+	// of the StatefulSet to control whether the PVCs are deleted with the cluster.
+	// This is effective only on Kubernetes clusters supporting the field of StatefulSet.
+	// If not set, MOCO makes the MySQLCluster own the PVCs so that they are deleted with it.
+	// Because the owner of the PVCs cannot be changed, this can be set only at creation,
+	// but the values in it can be modified later.
+	// +optional
+	PersistentVolumeClaimRetentionPolicy *PersistentVolumeClaimRetentionPolicy `json:"persistentVolumeClaimRetentionPolicy,omitempty"`
+
 	// PrimaryServiceTemplate is a `Service` template for primary.
+	// `spec.selector` is reserved for MOCO and cannot be set.
 	// +optional
 	PrimaryServiceTemplate *ServiceTemplate `json:"primaryServiceTemplate,omitempty"`
 
 	// ReplicaServiceTemplate is a `Service` template for replica.
+	// `spec.selector` is reserved for MOCO and cannot be set.
 	// +optional
 	ReplicaServiceTemplate *ServiceTemplate `json:"replicaServiceTemplate,omitempty"`
 
+	// TopologyAwareReplicaService enables Topology Aware Routing on the replica `Service`
+	// so that clients are preferably routed to replicas in the same zone.
+	// +optional
+	TopologyAwareReplicaService bool `json:"topologyAwareReplicaService,omitempty"`
+
+	// GatewayRoute configures a route of Gateway API that exposes the primary `Service`
+	// through Gateways.  If this field is null, no route is created.
+	// The route is not created if the CRD of the route is not installed.
+	// +nullable
+	// +optional
+	GatewayRoute *GatewayRouteSpec `json:"gatewayRoute,omitempty"`
+
+	// HeadlessServicePorts is the list of additional ports published by the headless `Service`.
+	// The ports for mysql, mysqlx, and mysql-admin are always published.
+	// +optional
+	HeadlessServicePorts []ServicePortApplyConfiguration `json:"headlessServicePorts,omitempty"`
+
 	// MySQLConfigMapName is a `ConfigMap` name of MySQL config.
 	// +nullable
 	// +optional
 	MySQLConfigMapName *string `json:"mysqlConfigMapName,omitempty"`
 
+	// DataDir is the directory where the "mysql-data" volume is mounted in mysqld container
+	// and the init container.  mysqld stores its data in `data` subdirectory of it.
+	// The default is "/var/lib/mysql".
+	// +optional
+	DataDir string `json:"dataDir,omitempty"`
+
+	// TmpDir is the directory used for `tmpdir` and `innodb_tmpdir` of mysqld.
+	// It must be the mount path of a volume mounted in mysqld container, that is, "/tmp", `dataDir`,
+	// or a mount path of mysqld container given in `podTemplate`.
+	// This takes precedence over the values in the ConfigMap of `mysqlConfigMapName`.
+	// The default is "/tmp".
+	// +optional
+	TmpDir string `json:"tmpDir,omitempty"`
+
+	// MySQLConfPath is the path of the generated my.cnf in mysqld container.
+	// If set, only my.cnf is mounted with `subPath` so that it can coexist with
+	// the other files in the directory of the container image.
+	// It must be a file in "/etc/mysql".
+	// The default is empty, which mounts the whole ConfigMap on "/etc/mysql".
+	// +optional
+	MySQLConfPath string `json:"mysqlConfPath,omitempty"`
+
+	// RedoLog configures the size of InnoDB redo log.
+	// This takes precedence over the values in the ConfigMap of `mysqlConfigMapName`.
+	// If not specified, the default of MOCO, i.e., two files of 800MiB, is used.
+	// +optional
+	RedoLog *RedoLogSpec `json:"redoLog,omitempty"`
+
+	// MaxConnections derives `max_connections` of mysqld from the memory request of mysqld container
+	// to avoid OOM caused by the buffers allocated for each connection.
+	// This is ignored if `max_connections` is specified in the ConfigMap of `mysqlConfigMapName`
+	// or if mysqld container has no memory request.
+	// If not specified, `max_connections` is fixed to the default of MOCO, i.e., 100000.
+	// +optional
+	MaxConnections *MaxConnectionsSpec `json:"maxConnections,omitempty"`
+
+	// MysqldCommand overrides the entrypoint of mysqld container, e.g. with a wrapper script
+	// that sets ulimits.  MOCO passes the arguments to run mysqld such as `--defaults-file`
+	// to the command, so the command must eventually exec mysqld with the arguments,
+	// e.g. `exec mysqld "$@"`; otherwise MOCO cannot manage the instance.
+	// This cannot be specified together with `command` of mysqld container in `podTemplate`.
+	// If not specified, the entrypoint of the image is used.
+	// +optional
+	MysqldCommand []string `json:"mysqldCommand,omitempty"`
+
 	// ReplicationSourceSecretName is a `Secret` name which contains replication source info.
 	// If this field is given, the `MySQLCluster` works as an intermediate primary.
 	// +nullable
 	// +optional
 	ReplicationSourceSecretName *string `json:"replicationSourceSecretName,omitempty"`
 
+	// ReplicationSource makes the `MySQLCluster` a read replica of another `MySQLCluster`.
+	// MOCO clones the data from the source cluster and keeps replicating from its primary
+	// `Service`, so the replication follows switchovers and failovers of the source.
+	// The primary of this cluster works as an intermediate primary and never becomes writable.
+	// This cannot be specified together with `replicationSourceSecretName`.
+	// +nullable
+	// +optional
+	ReplicationSource *ReplicationSourceSpec `json:"replicationSource,omitempty"`
+
+	// AdoptSourceSecretName is a `Secret` name which contains the information of an external mysqld to adopt.
+	// MOCO clones the data from the external mysqld only once, and then manages the cluster
+	// as a normal one whose primary is writable.  The keys of the Secret are the same as
+	// those for `replicationSourceSecretName`.
+	// This cannot be specified together with `replicationSourceSecretName`, `replicationSource`, or `restore`.
+	// +nullable
+	// +optional
+	AdoptSourceSecretName *string `json:"adoptSourceSecretName,omitempty"`
+
 	// Collectors is the list of collector flag names of mysqld_exporter.
 	// If this field is not empty, MOCO adds mysqld_exporter as a sidecar to collect
 	// and export mysqld metrics in Prometheus format.
@@ -70,6 +189,46 @@ type MySQLClusterSpec struct {
 	// +optional
 	Collectors []string `json:"collectors,omitempty"`
 
+	// ExporterMode controls how mysqld_exporter runs when Collectors is not empty.
+	// If "sidecar", MOCO adds mysqld_exporter to each Pod of MySQL.
+	// If "deployment", MOCO runs a single mysqld_exporter as a Deployment that
+	// connects to the primary instance through the primary Service, and creates
+	// a Service named `moco-exporter-<name>` for it.
+	// The default is "sidecar".
+	// +kubebuilder:validation:Enum=sidecar;deployment
+	// +kubebuilder:default=sidecar
+	// +optional
+	ExporterMode string `json:"exporterMode,omitempty"`
+
+	// ExporterUserName is the name of a user in `spec.users` that mysqld_exporter uses
+	// instead of `moco-exporter`.  The password is passed to mysqld_exporter from the
+	// password Secret of the user through an environment variable.
+	// Grant only the privileges needed for monitoring to the user, e.g.
+	// "PROCESS, REPLICATION CLIENT ON *.*" and "SELECT ON performance_schema.*".
+	// +optional
+	ExporterUserName string `json:"exporterUserName,omitempty"`
+
+	// CreateDashboard controls whether to create a ConfigMap containing a Grafana dashboard
+	// for this cluster.  The ConfigMap is labeled with `grafana_dashboard` so that the
+	// Grafana sidecar can provision it.  Panels for mysqld_exporter metrics are added
+	// according to Collectors.  The default is false.
+	// +optional
+	CreateDashboard bool `json:"createDashboard,omitempty"`
+
+	// CreatePodMonitor controls whether to create a PodMonitor of Prometheus Operator
+	// that scrapes mysqld_exporter running as a sidecar in each Pod.
+	// The PodMonitor is not created if mysqld_exporter does not run as a sidecar or
+	// the PodMonitor CRD is not installed.  The default is false.
+	// +optional
+	CreatePodMonitor bool `json:"createPodMonitor,omitempty"`
+
+	// CreateMetricsService controls whether to create a headless Service that selects
+	// the Pods of this cluster and exposes the port of mysqld_exporter running as a sidecar.
+	// The Service is not created if mysqld_exporter does not run as a sidecar.
+	// The default is false.
+	// +optional
+	CreateMetricsService bool `json:"createMetricsService,omitempty"`
+
 	// ServerIDBase, if set, will become the base number of server-id of each MySQL
 	// instance of this cluster.  For example, if this is 100, the server-ids will be
 	// 100, 101, 102, and so on.
@@ -87,6 +246,22 @@ type MySQLClusterSpec struct {
 	// +optional
 	MaxDelaySeconds *int `json:"maxDelaySeconds,omitempty"`
 
+	// MinSyncedReplicas is the minimum number of replicas, excluding the primary,
+	// that must be synced with the primary.  A replica is synced while its Pod is ready,
+	// i.e. it replicates from the primary without too much delay.  When fewer replicas
+	// are synced, MOCO sets the `Degraded` condition to True.
+	// If not set, there is no minimum and the `Degraded` condition is not reported.
+	// This must be less than `spec.replicas`.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MinSyncedReplicas *int32 `json:"minSyncedReplicas,omitempty"`
+
+	// SemiSync configures the semi-synchronous replication between the primary and the replicas.
+	// If not set, the primary waits for the acknowledgements from `spec.replicas / 2` replicas
+	// with a timeout of 24 hours.
+	// +optional
+	SemiSync *SemiSyncSpec `json:"semiSync,omitempty"`
+
 	// StartupWaitSeconds is the maximum duration to wait for `mysqld` container to start working.
 	// The default is 3600 seconds.
 	// +kubebuilder:validation:Minimum=0
@@ -94,6 +269,35 @@ type MySQLClusterSpec struct {
 	// +optional
 	StartupWaitSeconds int32 `json:"startupWaitSeconds,omitempty"`
 
+	// CloneRetryBackoff is the minimum delay before MOCO retries cloning data to an instance
+	// after the previous attempt for the instance failed.  This keeps a failing clone from
+	// loading the donor instance.  If not set, MOCO retries at the next check of the cluster.
+	// +optional
+	CloneRetryBackoff *metav1.Duration `json:"cloneRetryBackoff,omitempty"`
+
+	// MaxConcurrentClones is the maximum number of replicas to which MOCO clones data from the
+	// primary at a time.  The other replicas wait for the running clones to finish so that
+	// adding many replicas at once does not saturate the primary.  The default is 1.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=1
+	// +optional
+	MaxConcurrentClones int32 `json:"maxConcurrentClones,omitempty"`
+
+	// RevisionHistoryLimit is the maximum number of revisions of the StatefulSet to be kept.
+	// The default is 3.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:default=3
+	// +optional
+	RevisionHistoryLimit *int32 `json:"revisionHistoryLimit,omitempty"`
+
+	// CanaryUpgrade, if true, makes MOCO roll out changes of the Pod template one instance at a time
+	// using the partition of the StatefulSet.  The instance with the highest ordinal is updated first,
+	// and the next one is updated only after the cluster becomes healthy.  The primary is switched
+	// over before it is updated so that it is updated last.  If the updated instances
+	// become unhealthy, MOCO holds the rollout and records an event.  The default is false.
+	// +optional
+	CanaryUpgrade bool `json:"canaryUpgrade,omitempty"`
+
 	// LogRotationSchedule specifies the schedule to rotate MySQL logs.
 	// If not set, the default is to rotate logs every 5 minutes.
 	// See https://pkg.go.dev/github.com/robfig/cron/v3#hdr-CRON_Expression_Format for the field format.
@@ -106,6 +310,13 @@ type MySQLClusterSpec struct {
 	// +optional
 	BackupPolicyName *string `json:"backupPolicyName,omitempty"`
 
+	// Maintenance configures a CronJob to refresh the statistics of tables periodically
+	// by running `ANALYZE TABLE` on the replica instances.  The primary instance is not analyzed.
+	// If this is not set, MOCO does not create the CronJob.
+	// +nullable
+	// +optional
+	Maintenance *MaintenanceSpec `json:"maintenance,omitempty"`
+
 	// Restore is the specification to perform Point-in-Time-Recovery from existing cluster.
 	// If this field is not null, MOCO restores the data as specified and create a new
 	// cluster with the data.  This field is not editable.
@@ -117,9 +328,700 @@ type MySQLClusterSpec struct {
 	// If set to true, the sidecar container is not added. The default is false.
 	// +optional
 	DisableSlowQueryLogContainer bool `json:"disableSlowQueryLogContainer,omitempty"`
+
+	// DisableSlowQueryLog turns off the slow query log of mysqld.
+	// This takes precedence over `slow_query_log` in the ConfigMap of `mysqlConfigMapName`.
+	// This does not remove the "slow-log" sidecar container; set `disableSlowQueryLogContainer`
+	// to remove it.  Conversely, setting only `disableSlowQueryLogContainer` keeps the slow logs
+	// written in the log volume.  The default is false.
+	// +optional
+	DisableSlowQueryLog bool `json:"disableSlowQueryLog,omitempty"`
+
+	// SlowQueryLog configures what queries are written in the slow query log.
+	// This takes precedence over the values in the ConfigMap of `mysqlConfigMapName`.
+	// If not specified, the defaults of MOCO are used.  Changing this restarts the Pods.
+	// +optional
+	SlowQueryLog *SlowQueryLogSpec `json:"slowQueryLog,omitempty"`
+
+	// DisableMyCnfSecretVolume stops mounting the Secret of my.cnf formatted credentials
+	// in the mysqld container.  Set this when nothing in the Pods logs in to mysqld with
+	// the passwords managed by MOCO.  This cannot be set together with the mysqld_exporter
+	// sidecar or the default warm-up command, which use the credentials.
+	// `kubectl moco mysql` does not work when this is true.  The default is false.
+	// Changing this restarts the Pods.
+	// +optional
+	DisableMyCnfSecretVolume bool `json:"disableMyCnfSecretVolume,omitempty"`
+
+	// SlowQueryLogOutput configures where the "slow-log" sidecar container sends slow logs.
+	// If not given, slow logs are written to the standard output of the container.
+	// +optional
+	SlowQueryLogOutput *SlowQueryLogOutputSpec `json:"slowQueryLogOutput,omitempty"`
+
+	// EnableAuditLogContainer, if true, loads the audit log plugin of mysqld and adds
+	// a sidecar container named "audit-log" to output the audit logs as the container output.
+	// The plugin, `audit_log.so`, has to be available in the mysqld image.
+	// The default is false.
+	// +optional
+	EnableAuditLogContainer bool `json:"enableAuditLogContainer,omitempty"`
+
+	// PublishInstanceRoles, if true, makes MOCO record the role of each instance
+	// in `moco.instance_roles` table on the primary instance.
+	// The table is replicated to the replicas so that clients can find the role of
+	// the instance they are connected to with its `@@server_id`.
+	// This has no effect if `replicationSourceSecretName` is set.  The default is false.
+	// +optional
+	PublishInstanceRoles bool `json:"publishInstanceRoles,omitempty"`
+
+	// SlowQueryLogAgentPreStopSeconds is the duration in seconds for which the "slow-log"
+	// sidecar container sleeps in its preStop hook.  This lets the sidecar keep reading
+	// the slow logs until mysqld stops.  The default is 25, which is 5 seconds longer
+	// than the preStop hook of mysqld container.
+	// +kubebuilder:validation:Minimum=0
+	// +nullable
+	// +optional
+	SlowQueryLogAgentPreStopSeconds *int32 `json:"slowQueryLogAgentPreStopSeconds,omitempty"`
+
+	// PrimaryDrainTimeoutSeconds is the maximum duration in seconds to wait for connections
+	// to the primary instance to finish before switching over when the primary Pod is being deleted.
+	// MOCO removes the Pod from the primary Service first so that no new connections come in.
+	// This has to fit in the preStop hook of mysqld container, which sleeps for 20 seconds.
+	// The default is 5.  Setting 0 disables draining.
+	// +kubebuilder:validation:Minimum=0
+	// +kubebuilder:validation:Maximum=15
+	// +nullable
+	// +optional
+	PrimaryDrainTimeoutSeconds *int32 `json:"primaryDrainTimeoutSeconds,omitempty"`
+
+	// ReplicaDrainSeconds is the maximum duration in seconds for which the preStop hook of
+	// mysqld container waits for client connections to finish.  Kubernetes removes a terminating
+	// Pod from the replica Service, so no new connections come in while waiting.
+	// The hook finishes as soon as no client connections remain and no replica replicates
+	// from the instance, so the primary instance waits for the whole duration for the switchover.
+	// The default is 20.  Setting 0 makes the hook just sleep for 20 seconds.
+	// Other values must be at least 20 not to stop the primary before the switchover.
+	// +kubebuilder:validation:Minimum=0
+	// +nullable
+	// +optional
+	ReplicaDrainSeconds *int32 `json:"replicaDrainSeconds,omitempty"`
+
+	// DisablePodDisruptionBudget controls whether to create a PodDisruptionBudget for the cluster.
+	// If set to true, MOCO does not create a PodDisruptionBudget and deletes the one
+	// created by MOCO, if any.  The default is false.
+	// +optional
+	DisablePodDisruptionBudget bool `json:"disablePodDisruptionBudget,omitempty"`
+
+	// ZoneAwarePodDisruptionBudget, if true, computes `maxUnavailable` of the PodDisruptionBudget
+	// from the distribution of the Pods over zones so that the Pods in a single zone can be
+	// evicted at once, e.g. to drain a zone.  The zone of a Pod is read from
+	// "topology.kubernetes.io/zone" label of its Node.  `maxUnavailable` is the number of
+	// the Pods in the largest zone but does not exceed the half of the replicas so that
+	// the majority of the instances keeps running.  If the zones of some Pods are unknown,
+	// the default of the half of the replicas is used.  The default is false.
+	// +optional
+	ZoneAwarePodDisruptionBudget bool `json:"zoneAwarePodDisruptionBudget,omitempty"`
+
+	// AgentOnlyServiceAccountToken, if true, disables the automatic mount of the
+	// ServiceAccount token in the MySQL Pods and projects the token only into the
+	// "agent" container, because the other containers do not access the Kubernetes API.
+	// `spec.podTemplate.spec.automountServiceAccountToken` cannot be true with this.
+	// Changing this restarts the Pods.  The default is false.
+	// +optional
+	AgentOnlyServiceAccountToken bool `json:"agentOnlyServiceAccountToken,omitempty"`
+
+	// MysqldReadOnlyRootFilesystem, if true, makes the root filesystem of the mysqld container
+	// read-only.  mysqld can still write to the data directory and the volumes mounted on
+	// /tmp, /run, and /var/log/mysql.  The value given in the security context of the mysqld
+	// container in `spec.podTemplate` takes precedence.
+	// Changing this restarts the Pods.  The default is false.
+	// +optional
+	MysqldReadOnlyRootFilesystem bool `json:"mysqldReadOnlyRootFilesystem,omitempty"`
+
+	// MysqldReadinessProbe overrides the thresholds of the readiness probe of the mysqld
+	// container without redefining the whole probe in `spec.podTemplate`, e.g. to tolerate
+	// more failures on slow replicas.  The thresholds given in the readiness probe of the
+	// mysqld container in `spec.podTemplate` take precedence.
+	// Changing this restarts the Pods.  If not set, the Kubernetes defaults are used.
+	// +optional
+	MysqldReadinessProbe *ProbeThresholdsSpec `json:"mysqldReadinessProbe,omitempty"`
+
+	// InstanceResources overrides the resources of the mysqld container of the listed
+	// instances, e.g. to give a replica used for backups or analytics more memory and CPU
+	// than the others.  The resources are set by a mutating webhook of MOCO when the Pods
+	// are created, so the StatefulSet keeps the resources of `spec.podTemplate`.
+	// The instances are not pinned to the replica role; a failover or a switchover
+	// may promote them to the primary.
+	// Changing this restarts the Pods.  If empty, all instances have the same resources.
+	// +listType=map
+	// +listMapKey=index
+	// +optional
+	InstanceResources []InstanceResourcesSpec `json:"instanceResources,omitempty"`
+
+	// AgentMemoryPercent, if set, derives the memory request and limit of the "agent"
+	// container from the memory of mysqld container.  The memory of mysqld is taken from
+	// its resources.requests.memory, or resources.limits.memory if the request is not set.
+	// The derived value is never less than the default memory of the agent.
+	// Resources given in `spec.podTemplate.overwriteContainers` take precedence.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100
+	// +nullable
+	// +optional
+	AgentMemoryPercent *int32 `json:"agentMemoryPercent,omitempty"`
+
+	// AgentProbe, if set, adds a startup probe and a liveness probe to the "agent" container.
+	// If this field is null, the "agent" container has no probes.
+	// Changing this restarts the Pods.
+	// +nullable
+	// +optional
+	AgentProbe *AgentProbeSpec `json:"agentProbe,omitempty"`
+
+	// WarmUp configures a postStart hook of the mysqld container to warm up the instance.
+	// If this field is null, no hook is added.
+	// +nullable
+	// +optional
+	WarmUp *WarmUpSpec `json:"warmUp,omitempty"`
+
+	// Users is the list of MySQL users that MOCO creates and keeps up to date.
+	// Users removed from this list are dropped.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Users []UserSpec `json:"users,omitempty"`
+
+	// Databases is the list of databases that MOCO creates once the cluster is available.
+	// Databases removed from this list are not dropped.
+	// +listType=set
+	// +optional
+	Databases []string `json:"databases,omitempty"`
+}
+
+// UserSpec represents a MySQL user managed by MOCO.
+type UserSpec struct {
+	// Name is the name of the user.  The user can connect from any host.
+	// +kubebuilder:validation:MinLength=1
+	// +kubebuilder:validation:MaxLength=32
+	Name string `json:"name"`
+
+	// Grants is the list of privileges granted to the user.
+	// Each item is in the form of `<privileges> ON <database>.<table>`, e.g. "SELECT, INSERT ON app.*".
+	// Column privileges are not supported.
+	// +optional
+	Grants []string `json:"grants,omitempty"`
+
+	// PasswordSecretName is the name of the Secret that has the password of the user
+	// in `password` key.  If the Secret does not exist, MOCO creates it with a random password.
+	// +kubebuilder:validation:MinLength=1
+	PasswordSecretName string `json:"passwordSecretName"`
+}
+
+// OrdinalsSpec represents the numbering of the Pods of a cluster.
+type OrdinalsSpec struct {
+	// Start is the ordinal number of the first Pod.
+	// For example, if this is 3, the Pods will be named `moco-<name>-3`, `moco-<name>-4`, and so on.
+	// The default is 0.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	Start int32 `json:"start,omitempty"`
+}
+
+// Values of PersistentVolumeClaimRetentionPolicy.
+const (
+	PersistentVolumeClaimRetain = "Retain"
+	PersistentVolumeClaimDelete = "Delete"
+)
+
+// PersistentVolumeClaimRetentionPolicy describes the lifecycle of the PVCs of MySQL Pods.
+type PersistentVolumeClaimRetentionPolicy struct {
+	// WhenDeleted specifies what happens to the PVCs when the cluster is deleted.
+	// "Delete" (default) deletes them, and "Retain" keeps them.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Delete
+	// +optional
+	WhenDeleted string `json:"whenDeleted,omitempty"`
+
+	// WhenScaled specifies what happens to the PVCs when the StatefulSet is scaled down.
+	// "Retain" (default) keeps them, and "Delete" deletes them.
+	// MOCO does not scale down clusters by itself, so this matters only if the StatefulSet is scaled down manually.
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	// +optional
+	WhenScaled string `json:"whenScaled,omitempty"`
+}
+
+// Modes of mysqld_exporter.
+const (
+	ExporterModeSidecar    = "sidecar"
+	ExporterModeDeployment = "deployment"
+)
+
+// Types of SlowQueryLogOutputSpec.
+const (
+	SlowQueryLogOutputStdout        = "stdout"
+	SlowQueryLogOutputLoki          = "loki"
+	SlowQueryLogOutputElasticsearch = "elasticsearch"
+	SlowQueryLogOutputKafka         = "kafka"
+)
+
+// SlowQueryLogOutputSpec represents a log sink for slow logs.
+type SlowQueryLogOutputSpec struct {
+	// Type is the type of the log sink.
+	// +kubebuilder:validation:Enum=stdout;loki;elasticsearch;kafka
+	// +kubebuilder:default=stdout
+	// +optional
+	Type string `json:"type,omitempty"`
+
+	// Host is the host name of Loki or Elasticsearch.
+	// +optional
+	Host string `json:"host,omitempty"`
+
+	// Port is the port number of Loki or Elasticsearch.
+	// The default is 3100 for Loki and 9200 for Elasticsearch.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=65535
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// TLS enables TLS to connect to the log sink.
+	// +optional
+	TLS bool `json:"tls,omitempty"`
+
+	// Index is the index of Elasticsearch.  The default is "moco-slow-log".
+	// +optional
+	Index string `json:"index,omitempty"`
+
+	// Brokers is the list of Kafka brokers in "host:port" form.
+	// +optional
+	Brokers []string `json:"brokers,omitempty"`
+
+	// Topic is the topic of Kafka.
+	// +optional
+	Topic string `json:"topic,omitempty"`
+
+	// CredentialsSecretName is the name of the Secret that has `username` and `password` keys.
+	// They are used for HTTP basic authentication to Loki or Elasticsearch, and for SASL/PLAIN to Kafka.
+	// The Secret must be in the same namespace as the cluster.
+	// +optional
+	CredentialsSecretName *string `json:"credentialsSecretName,omitempty"`
+}
+
+var (
+	elasticsearchIndexRegexp = regexp.MustCompile(`^[a-z0-9][a-z0-9._+-]{0,254}$`)
+	kafkaTopicRegexp         = regexp.MustCompile(`^[a-zA-Z0-9._-]{1,249}$`)
+)
+
+func validateHost(p *field.Path, host string) field.ErrorList {
+	if validation.IsValidIP(host) == nil {
+		return nil
+	}
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(host) {
+		allErrs = append(allErrs, field.Invalid(p, host, msg))
+	}
+	return allErrs
+}
+
+// validate validates the values written in the configuration file of fluent-bit,
+// so that they cannot inject other directives.
+func (o *SlowQueryLogOutputSpec) validate(p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if o.Host != "" {
+		allErrs = append(allErrs, validateHost(p.Child("host"), o.Host)...)
+	}
+	if o.Index != "" && !elasticsearchIndexRegexp.MatchString(o.Index) {
+		allErrs = append(allErrs, field.Invalid(p.Child("index"), o.Index, "must consist of lower case alphanumeric characters, '.', '_', '+', or '-'"))
+	}
+	if o.Topic != "" && !kafkaTopicRegexp.MatchString(o.Topic) {
+		allErrs = append(allErrs, field.Invalid(p.Child("topic"), o.Topic, "must consist of alphanumeric characters, '.', '_', or '-'"))
+	}
+	for i, b := range o.Brokers {
+		pp := p.Child("brokers").Index(i)
+		host, port, err := net.SplitHostPort(b)
+		if err != nil {
+			allErrs = append(allErrs, field.Invalid(pp, b, "must be in host:port form"))
+			continue
+		}
+		allErrs = append(allErrs, validateHost(pp, host)...)
+		if n, err := strconv.Atoi(port); err != nil || validation.IsValidPortNum(n) != nil {
+			allErrs = append(allErrs, field.Invalid(pp, b, "invalid port number"))
+		}
+	}
+	return allErrs
+}
+
+// AgentProbeSpec represents the parameters of the startup and liveness probes of the "agent" container.
+// The probes check that the gRPC port of the agent accepts connections.
+//
+// No readiness probe is added because the readiness of the Pod should be
+// decided only by mysqld.
+type AgentProbeSpec struct {
+	// InitialDelaySeconds is the number of seconds after the container has started
+	// before the probe is initiated.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	InitialDelaySeconds int32 `json:"initialDelaySeconds,omitempty"`
+
+	// TimeoutSeconds is the number of seconds after which the probe times out.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=5
+	// +optional
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+
+	// PeriodSeconds is how often in seconds to perform the probe.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures to restart the container.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=6
+	// +optional
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
+
+	// StartupPeriodSeconds is how often in seconds to perform the startup probe.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=10
+	// +optional
+	StartupPeriodSeconds int32 `json:"startupPeriodSeconds,omitempty"`
+
+	// StartupFailureThreshold is the number of consecutive failures of the startup probe
+	// to restart the container.  The liveness probe starts after the startup probe succeeds.
+	// The default allows the agent one hour to start, which tolerates a long clone.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:default=360
+	// +optional
+	StartupFailureThreshold int32 `json:"startupFailureThreshold,omitempty"`
+}
+
+// ProbeThresholdsSpec represents the thresholds of a probe.
+type ProbeThresholdsSpec struct {
+	// SuccessThreshold is the number of consecutive successes for the probe to be considered
+	// successful after having failed.  If not set, the Kubernetes default, 1, is used.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SuccessThreshold *int32 `json:"successThreshold,omitempty"`
+
+	// FailureThreshold is the number of consecutive failures for the probe to be considered
+	// failed after having succeeded.  If not set, the Kubernetes default, 3, is used.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	FailureThreshold *int32 `json:"failureThreshold,omitempty"`
+}
+
+// InstanceResourcesSpec represents the resources of the mysqld container of an instance.
+type InstanceResourcesSpec struct {
+	// Index is the index of the instance, which starts from 0 regardless of `spec.ordinals.start`.
+	// +kubebuilder:validation:Minimum=0
+	Index int32 `json:"index"`
+
+	// Resources replaces the resources of the mysqld container given in `spec.podTemplate`.
+	Resources *ResourceRequirementsApplyConfiguration `json:"resources"`
+}
+
+// WarmUpSpec represents the warm-up hook run after mysqld starts.
+type WarmUpSpec struct {
+	// Command is the command to be executed in the mysqld container as a postStart hook.
+	// If empty, MOCO loads the InnoDB buffer pool dump if present.
+	// +optional
+	Command []string `json:"command,omitempty"`
+}
+
+// GatewayRouteSpec specifies a TCPRoute or TLSRoute of Gateway API for the primary `Service`.
+type GatewayRouteSpec struct {
+	// Kind is the kind of the route, either "TCPRoute" or "TLSRoute".
+	// TLSRoute routes connections by the server name indication (SNI) of TLS.
+	// The default is "TCPRoute".
+	// +kubebuilder:validation:Enum=TCPRoute;TLSRoute
+	// +kubebuilder:default=TCPRoute
+	// +optional
+	Kind string `json:"kind,omitempty"`
+
+	// ParentRefs is the list of the Gateways that the route attaches to.
+	// +kubebuilder:validation:MinItems=1
+	ParentRefs []GatewayParentReference `json:"parentRefs"`
+
+	// Hostnames is the list of the SNI host names that the route matches.
+	// This can be specified only for TLSRoute.
+	// +optional
+	Hostnames []string `json:"hostnames,omitempty"`
+}
+
+// GatewayParentReference refers to a Gateway of Gateway API.
+type GatewayParentReference struct {
+	// Name is the name of the Gateway.
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// Namespace is the namespace of the Gateway.
+	// If not specified, the namespace of this MySQLCluster is used.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// SectionName is the name of the listener of the Gateway.
+	// If not specified, the route attaches to all the listeners that accept it.
+	// +optional
+	SectionName string `json:"sectionName,omitempty"`
+}
+
+// Kinds of GatewayRouteSpec.
+const (
+	GatewayRouteTCP = "TCPRoute"
+	GatewayRouteTLS = "TLSRoute"
+)
+
+// ReplicationSourceSpec specifies the MySQLCluster to replicate data from.
+type ReplicationSourceSpec struct {
+	// ClusterName is the name of the source MySQLCluster.
+	// +kubebuilder:validation:MinLength=1
+	ClusterName string `json:"clusterName"`
+
+	// Namespace is the namespace of the source MySQLCluster.
+	// If not specified, the namespace of this MySQLCluster is used.
+	// The source in another namespace must be annotated with `moco.cybozu.com/replication-allowed-namespaces`
+	// to allow the namespace of this MySQLCluster.
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+}
+
+// RedoLogSpec represents the size of InnoDB redo log.
+// Specify either `capacity` for MySQL 8.0.30 or later, or `fileSize` and
+// `filesInGroup` for older versions.
+type RedoLogSpec struct {
+	// Capacity is `innodb_redo_log_capacity`, the total size of the redo log files.
+	// This is available in MySQL 8.0.30 or later, and cannot be specified together with the other fields.
+	// +optional
+	Capacity *resource.Quantity `json:"capacity,omitempty"`
+
+	// FileSize is `innodb_log_file_size`, the size of each redo log file.
+	// +optional
+	FileSize *resource.Quantity `json:"fileSize,omitempty"`
+
+	// FilesInGroup is `innodb_log_files_in_group`, the number of redo log files.
+	// +kubebuilder:validation:Minimum=2
+	// +kubebuilder:validation:Maximum=100
+	// +optional
+	FilesInGroup *int32 `json:"filesInGroup,omitempty"`
+}
+
+// Mycnf returns the options of mysqld for the redo log.
+func (s *RedoLogSpec) Mycnf() map[string]string {
+	if s == nil {
+		return nil
+	}
+	if s.Capacity != nil {
+		return map[string]string{"innodb_redo_log_capacity": strconv.FormatInt(s.Capacity.Value(), 10)}
+	}
+
+	conf := make(map[string]string)
+	if s.FileSize != nil {
+		conf["innodb_log_file_size"] = strconv.FormatInt(s.FileSize.Value(), 10)
+	}
+	if s.FilesInGroup != nil {
+		conf["innodb_log_files_in_group"] = strconv.Itoa(int(*s.FilesInGroup))
+	}
+	return conf
+}
+
+// TotalSize returns the total size of the redo log files.
+// The values not specified are assumed to be the defaults of MOCO.
+func (s *RedoLogSpec) TotalSize() int64 {
+	if s.Capacity != nil {
+		return s.Capacity.Value()
+	}
+
+	fileSize := int64(800 << 20)
+	if s.FileSize != nil {
+		fileSize = s.FileSize.Value()
+	}
+	files := int64(2)
+	if s.FilesInGroup != nil {
+		files = int64(*s.FilesInGroup)
+	}
+	return fileSize * files
+}
+
+// SlowQueryLogSpec represents the options of mysqld for the slow query log.
+type SlowQueryLogSpec struct {
+	// LongQueryTime is `long_query_time`.  Queries that take longer than this are logged.
+	// The precision is microseconds.  The default of MOCO is 2 seconds.
+	// +optional
+	LongQueryTime *metav1.Duration `json:"longQueryTime,omitempty"`
+
+	// LogQueriesNotUsingIndexes is `log_queries_not_using_indexes`.
+	// If true, queries that do not use indexes are logged regardless of their duration.
+	// +optional
+	LogQueriesNotUsingIndexes *bool `json:"logQueriesNotUsingIndexes,omitempty"`
+}
+
+// Mycnf returns the options of mysqld for the slow query log.
+func (s *SlowQueryLogSpec) Mycnf() map[string]string {
+	if s == nil {
+		return nil
+	}
+
+	conf := make(map[string]string)
+	if s.LongQueryTime != nil {
+		conf["long_query_time"] = strconv.FormatFloat(s.LongQueryTime.Seconds(), 'f', -1, 64)
+	}
+	if s.LogQueriesNotUsingIndexes != nil {
+		if *s.LogQueriesNotUsingIndexes {
+			conf["log_queries_not_using_indexes"] = "ON"
+		} else {
+			conf["log_queries_not_using_indexes"] = "OFF"
+		}
+	}
+	return conf
+}
+
+// MaintenanceSpec represents the schedule to refresh the statistics of tables.
+type MaintenanceSpec struct {
+	// Schedule is the schedule of the CronJob in Cron format.
+	// See https://pkg.go.dev/github.com/robfig/cron/v3#hdr-CRON_Expression_Format for the field format.
+	Schedule string `json:"schedule"`
+
+	// Suspend suspends the CronJob.
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+
+	// Databases is the list of databases whose tables are analyzed.
+	// If empty, the tables in all databases except for the system ones are analyzed.
+	// +optional
+	Databases []string `json:"databases,omitempty"`
+}
+
+// SemiSyncSpec configures the semi-synchronous replication.
+type SemiSyncSpec struct {
+	// WaitForReplicaCount is the number of replicas from which the primary waits for
+	// the acknowledgements before committing a transaction.
+	// This is set to `rpl_semi_sync_master_wait_for_slave_count` of the primary instance.
+	// This must be less than `spec.replicas`.  The default is `spec.replicas / 2`.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	WaitForReplicaCount *int32 `json:"waitForReplicaCount,omitempty"`
+
+	// Timeout is the time for which the primary waits for the acknowledgements.
+	// When it expires, the primary falls back to the asynchronous replication.
+	// This is set to `rpl_semi_sync_master_timeout` of the primary instance.
+	// The default is 24 hours.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+}
+
+const (
+	// DefaultMinMaxConnections is the default floor of `max_connections` derived by MaxConnectionsSpec.
+	DefaultMinMaxConnections = 100
+
+	// DefaultMaxMaxConnections is the default ceiling of `max_connections` derived by MaxConnectionsSpec.
+	// This is the largest value mysqld accepts.
+	DefaultMaxMaxConnections = 100000
+)
+
+// MaxConnectionsSpec represents how to derive `max_connections` from the memory request.
+// `max_connections` is the memory request divided by `perConnectionMemory`, bounded by `min` and `max`.
+type MaxConnectionsSpec struct {
+	// PerConnectionMemory is the estimated memory used by each connection,
+	// such as `sort_buffer_size`, `join_buffer_size`, and thread stack.
+	// Note that the memory request is shared with InnoDB buffer pool.
+	PerConnectionMemory resource.Quantity `json:"perConnectionMemory"`
+
+	// Min is the floor of the derived `max_connections`.
+	// If not specified, 100 is used.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100000
+	// +optional
+	Min *int32 `json:"min,omitempty"`
+
+	// Max is the ceiling of the derived `max_connections`.
+	// If not specified, 100000 is used.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=100000
+	// +optional
+	Max *int32 `json:"max,omitempty"`
+}
+
+// Derive returns `max_connections` for the memory request `mem`.
+// This returns 0 if `s` is nil or `mem` is not positive, which means the default of MOCO should be used.
+func (s *MaxConnectionsSpec) Derive(mem int64) int64 {
+	if s == nil || mem <= 0 || s.PerConnectionMemory.Value() <= 0 {
+		return 0
+	}
+
+	minConns, maxConns := s.bounds()
+	n := mem / s.PerConnectionMemory.Value()
+	if n < minConns {
+		return minConns
+	}
+	if n > maxConns {
+		return maxConns
+	}
+	return n
+}
+
+func (s *MaxConnectionsSpec) bounds() (int64, int64) {
+	minConns := int64(DefaultMinMaxConnections)
+	if s.Min != nil {
+		minConns = int64(*s.Min)
+	}
+	maxConns := int64(DefaultMaxMaxConnections)
+	if s.Max != nil {
+		maxConns = int64(*s.Max)
+	}
+	return minConns, maxConns
+}
+
+func (s MySQLClusterSpec) validateMaxConnections(p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s.MaxConnections == nil {
+		return nil
+	}
+
+	if pcm := s.MaxConnections.PerConnectionMemory; pcm.Value() < 1<<20 {
+		allErrs = append(allErrs, field.Invalid(p.Child("perConnectionMemory"), pcm.String(), "must be 1Mi or larger"))
+	}
+	if minConns, maxConns := s.MaxConnections.bounds(); minConns > maxConns {
+		allErrs = append(allErrs, field.Invalid(p.Child("min"), minConns, fmt.Sprintf("must not be greater than max (%d)", maxConns)))
+	}
+	return allErrs
+}
+
+func (s MySQLClusterSpec) validateRedoLog(p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if s.RedoLog == nil {
+		return nil
+	}
+
+	if c := s.RedoLog.Capacity; c != nil {
+		if s.RedoLog.FileSize != nil || s.RedoLog.FilesInGroup != nil {
+			allErrs = append(allErrs, field.Forbidden(p.Child("capacity"), "cannot be specified together with fileSize or filesInGroup"))
+		}
+		if c.Value() < 8<<20 || c.Value() > 128<<30 {
+			allErrs = append(allErrs, field.Invalid(p.Child("capacity"), c.String(), "must be between 8Mi and 128Gi"))
+		}
+	}
+	if fs := s.RedoLog.FileSize; fs != nil && fs.Value() < 4<<20 {
+		allErrs = append(allErrs, field.Invalid(p.Child("fileSize"), fs.String(), "must be 4Mi or larger"))
+	}
+	if len(allErrs) > 0 {
+		return allErrs
+	}
+
+	total := s.RedoLog.TotalSize()
+	if s.RedoLog.Capacity == nil && total > 512<<30 {
+		allErrs = append(allErrs, field.Invalid(p, total, "the total size of the redo log files must not exceed 512Gi"))
+	}
+	for _, vc := range s.VolumeClaimTemplates {
+		if vc.Name != constants.MySQLDataVolumeName {
+			continue
+		}
+		size := vc.StorageSize()
+		if !size.IsZero() && total > size.Value()/2 {
+			allErrs = append(allErrs, field.Invalid(p, total, fmt.Sprintf("the total size of the redo log files must not exceed half of the %s volume (%s)", constants.MySQLDataVolumeName, size.String())))
+		}
+	}
+	return allErrs
 }
 
 func (s MySQLClusterSpec) validateCreate() (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
 	var allErrs field.ErrorList
 	p := field.NewPath("spec")
 	pp := p.Child("volumeClaimTemplates")
@@ -153,16 +1055,207 @@ func (s MySQLClusterSpec) validateCreate() (admission.Warnings, field.ErrorList)
 		}
 	}
 
-	pp = p.Child("replicas")
-	if s.Replicas%2 == 0 {
-		allErrs = append(allErrs, field.Invalid(pp, s.Replicas, "replicas must be a positive odd number"))
+	pp = p.Child("maintenance")
+	if s.Maintenance != nil {
+		if _, err := cron.ParseStandard(s.Maintenance.Schedule); err != nil {
+			allErrs = append(allErrs, field.Invalid(pp.Child("schedule"), s.Maintenance.Schedule, err.Error()))
+		}
+		if s.Replicas == 1 {
+			warns = append(warns, "spec.maintenance has no effect because the cluster has no replica instances")
+		}
 	}
+
+	pp = p.Child("replicas")
 	if s.Replicas <= 0 {
 		allErrs = append(allErrs, field.Invalid(pp, s.Replicas, "replicas must be a positive integer"))
 	}
 
+	pp = p.Child("headlessServicePorts")
+	portNames := make(map[string]bool)
+	ports := make(map[string]bool)
+	for i, port := range s.HeadlessServicePorts {
+		if port.Port != nil {
+			protocol := corev1.ProtocolTCP
+			if port.Protocol != nil {
+				protocol = *port.Protocol
+			}
+			key := fmt.Sprintf("%d/%s", *port.Port, protocol)
+			switch *port.Port {
+			case constants.MySQLPort, constants.MySQLXPort, constants.MySQLAdminPort:
+				allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("port"), *port.Port, "reserved port"))
+			default:
+				if ports[key] {
+					allErrs = append(allErrs, field.Duplicate(pp.Index(i).Child("port"), key))
+				}
+			}
+			ports[key] = true
+		} else {
+			allErrs = append(allErrs, field.Required(pp.Index(i).Child("port"), "port is required"))
+		}
+
+		if port.Name != nil {
+			switch *port.Name {
+			case constants.MySQLPortName, constants.MySQLXPortName, constants.MySQLAdminPortName:
+				allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("name"), *port.Name, "reserved port name"))
+			default:
+				if portNames[*port.Name] {
+					allErrs = append(allErrs, field.Duplicate(pp.Index(i).Child("name"), *port.Name))
+				}
+			}
+			portNames[*port.Name] = true
+		} else {
+			allErrs = append(allErrs, field.Required(pp.Index(i).Child("name"), "port name is required"))
+		}
+	}
+
+	if s.Restore != nil {
+		allErrs = append(allErrs, s.Restore.JobConfig.validate(p.Child("restore", "jobConfig"))...)
+	}
+
+	if s.ReplicationSource != nil && s.ReplicationSourceSecretName != nil {
+		allErrs = append(allErrs, field.Forbidden(p.Child("replicationSource"), "cannot be specified together with replicationSourceSecretName"))
+	}
+
+	if s.AdoptSourceSecretName != nil && (s.ReplicationSourceSecretName != nil || s.ReplicationSource != nil || s.Restore != nil) {
+		allErrs = append(allErrs, field.Forbidden(p.Child("adoptSourceSecretName"), "cannot be specified together with replicationSourceSecretName, replicationSource, or restore"))
+	}
+
+	allErrs = append(allErrs, s.validateDirs(p)...)
+	allErrs = append(allErrs, s.validateRedoLog(p.Child("redoLog"))...)
+	allErrs = append(allErrs, s.validateMaxConnections(p.Child("maxConnections"))...)
+
+	if s.MinSyncedReplicas != nil && *s.MinSyncedReplicas >= s.Replicas {
+		allErrs = append(allErrs, field.Invalid(p.Child("minSyncedReplicas"), *s.MinSyncedReplicas, "must be less than spec.replicas"))
+	}
+
+	pp = p.Child("semiSync")
+	if ss := s.SemiSync; ss != nil {
+		if ss.WaitForReplicaCount != nil {
+			if *ss.WaitForReplicaCount >= s.Replicas {
+				allErrs = append(allErrs, field.Invalid(pp.Child("waitForReplicaCount"), *ss.WaitForReplicaCount, "must be less than spec.replicas"))
+			} else if *ss.WaitForReplicaCount < s.Replicas/2 {
+				warns = append(warns, "spec.semiSync.waitForReplicaCount is less than spec.replicas / 2; acknowledged transactions may be lost on failover")
+			}
+		}
+		if ss.Timeout != nil && ss.Timeout.Duration < time.Millisecond {
+			allErrs = append(allErrs, field.Invalid(pp.Child("timeout"), ss.Timeout.Duration.String(), "must be at least 1ms"))
+		}
+	}
+
+	pp = p.Child("instanceResources")
+	for i, ir := range s.InstanceResources {
+		if ir.Index >= s.Replicas {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("index"), ir.Index, "must be less than spec.replicas"))
+		}
+		if ir.Resources == nil {
+			allErrs = append(allErrs, field.Required(pp.Index(i).Child("resources"), "resources is required"))
+		}
+	}
+	if len(s.InstanceResources) > 0 && s.Replicas == 1 {
+		warns = append(warns, "spec.instanceResources overrides the resources of the primary because the cluster has no replica instances")
+	}
+
+	if s.ReplicaDrainSeconds != nil && *s.ReplicaDrainSeconds != 0 && *s.ReplicaDrainSeconds < 20 {
+		allErrs = append(allErrs, field.Invalid(p.Child("replicaDrainSeconds"), *s.ReplicaDrainSeconds, "must be 0 or at least 20"))
+	}
+
+	if s.CloneRetryBackoff != nil && s.CloneRetryBackoff.Duration < 0 {
+		allErrs = append(allErrs, field.Invalid(p.Child("cloneRetryBackoff"), s.CloneRetryBackoff.Duration.String(), "must not be negative"))
+	}
+
+	if sl := s.SlowQueryLog; sl != nil {
+		if sl.LongQueryTime != nil && sl.LongQueryTime.Duration < 0 {
+			allErrs = append(allErrs, field.Invalid(p.Child("slowQueryLog", "longQueryTime"), sl.LongQueryTime.Duration.String(), "must not be negative"))
+		}
+		if s.DisableSlowQueryLog {
+			warns = append(warns, "spec.slowQueryLog has no effect because spec.disableSlowQueryLog is true")
+		}
+	}
+
+	if s.DisableSlowQueryLog && !s.DisableSlowQueryLogContainer {
+		warns = append(warns, "the slow-log sidecar container has nothing to read because spec.disableSlowQueryLog is true; consider setting spec.disableSlowQueryLogContainer")
+	}
+
+	pp = p.Child("gatewayRoute")
+	if gr := s.GatewayRoute; gr != nil {
+		if gr.Kind != GatewayRouteTLS && len(gr.Hostnames) > 0 {
+			allErrs = append(allErrs, field.Forbidden(pp.Child("hostnames"), "hostnames can be specified only for TLSRoute"))
+		}
+	}
+
+	pp = p.Child("disableMyCnfSecretVolume")
+	if s.DisableMyCnfSecretVolume {
+		if s.ExporterSidecarEnabled() {
+			allErrs = append(allErrs, field.Forbidden(pp, "the mysqld_exporter sidecar container needs the my.cnf Secret"))
+		}
+		if s.WarmUp != nil && len(s.WarmUp.Command) == 0 {
+			allErrs = append(allErrs, field.Forbidden(pp, "the default warm-up command needs the my.cnf Secret"))
+		}
+	}
+
+	pp = p.Child("slowQueryLogOutput")
+	if out := s.SlowQueryLogOutput; out != nil {
+		if s.DisableSlowQueryLogContainer {
+			warns = append(warns, "spec.slowQueryLogOutput is ignored because spec.disableSlowQueryLogContainer is true")
+		}
+		switch out.Type {
+		case SlowQueryLogOutputLoki, SlowQueryLogOutputElasticsearch:
+			if out.Host == "" {
+				allErrs = append(allErrs, field.Required(pp.Child("host"), fmt.Sprintf("host is required for %s", out.Type)))
+			}
+		case SlowQueryLogOutputKafka:
+			if len(out.Brokers) == 0 {
+				allErrs = append(allErrs, field.Required(pp.Child("brokers"), "brokers are required for kafka"))
+			}
+			if out.Topic == "" {
+				allErrs = append(allErrs, field.Required(pp.Child("topic"), "topic is required for kafka"))
+			}
+		}
+		if out.CredentialsSecretName != nil && (out.Type == "" || out.Type == SlowQueryLogOutputStdout) {
+			allErrs = append(allErrs, field.Forbidden(pp.Child("credentialsSecretName"), "credentials cannot be used for stdout"))
+		}
+		allErrs = append(allErrs, out.validate(pp)...)
+	}
+
+	pp = p.Child("databases")
+	for i, db := range s.Databases {
+		if len(db) == 0 || len(db) > 64 {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i), db, "database name must be 1 to 64 characters"))
+		}
+		switch db {
+		case "mysql", "sys", "information_schema", "performance_schema", constants.InstanceRolesSchema:
+			allErrs = append(allErrs, field.Invalid(pp.Index(i), db, "reserved database name"))
+		}
+	}
+
+	pp = p.Child("users")
+	userNames := make(map[string]bool)
+	for i, u := range s.Users {
+		if u.Name == "root" || slices.Contains(constants.MocoUsers, u.Name) {
+			allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("name"), u.Name, "reserved user name"))
+		}
+		if userNames[u.Name] {
+			allErrs = append(allErrs, field.Duplicate(pp.Index(i).Child("name"), u.Name))
+		}
+		userNames[u.Name] = true
+		for j, g := range u.Grants {
+			if _, err := ParseGrant(g); err != nil {
+				allErrs = append(allErrs, field.Invalid(pp.Index(i).Child("grants").Index(j), g, err.Error()))
+			}
+		}
+	}
+	if s.ExporterUserName != "" && !userNames[s.ExporterUserName] {
+		allErrs = append(allErrs, field.Invalid(p.Child("exporterUserName"), s.ExporterUserName, "must be the name of a user in spec.users"))
+	}
+
 	p = p.Child("podTemplate", "spec")
 
+	if s.AgentOnlyServiceAccountToken {
+		if v := s.PodTemplate.Spec.AutomountServiceAccountToken; v != nil && *v {
+			allErrs = append(allErrs, field.Forbidden(p.Child("automountServiceAccountToken"), "the token is mounted only in the agent container because spec.agentOnlyServiceAccountToken is true"))
+		}
+	}
+
 	pp = p.Child("containers")
 	mysqldIndex := -1
 	for i, container := range s.PodTemplate.Spec.Containers {
@@ -180,10 +1273,25 @@ func (s MySQLClusterSpec) validateCreate() (admission.Warnings, field.ErrorList)
 		if *container.Name == constants.SlowQueryLogAgentContainerName && !s.DisableSlowQueryLogContainer {
 			allErrs = append(allErrs, field.Forbidden(pp.Index(i), "reserved container name"))
 		}
+		if *container.Name == constants.AuditLogAgentContainerName && s.EnableAuditLogContainer {
+			allErrs = append(allErrs, field.Forbidden(pp.Index(i), "reserved container name"))
+		}
 		if *container.Name == constants.ExporterContainerName && len(s.Collectors) > 0 {
 			allErrs = append(allErrs, field.Forbidden(pp.Index(i), "reserved container name"))
 		}
 	}
+	if len(s.MysqldCommand) > 0 {
+		pp := p.Child("mysqldCommand")
+		if s.MysqldCommand[0] == "" {
+			allErrs = append(allErrs, field.Invalid(pp.Index(0), s.MysqldCommand[0], "the executable must not be empty"))
+		}
+		if mysqldIndex != -1 && len(s.PodTemplate.Spec.Containers[mysqldIndex].Command) > 0 {
+			allErrs = append(allErrs, field.Forbidden(pp, "cannot be specified together with the command of mysqld container in podTemplate"))
+		}
+		if !slices.ContainsFunc(s.MysqldCommand, func(arg string) bool { return strings.Contains(arg, "mysqld") }) {
+			warns = append(warns, "spec.mysqldCommand does not seem to run mysqld; the command must eventually exec mysqld with the given arguments for MOCO to work")
+		}
+	}
 	if mysqldIndex == -1 {
 		allErrs = append(allErrs, field.Required(pp, fmt.Sprintf("required container %s is missing", constants.MysqldContainerName)))
 	} else {
@@ -227,13 +1335,14 @@ func (s MySQLClusterSpec) validateCreate() (admission.Warnings, field.ErrorList)
 		switch *vol.Name {
 		case constants.TmpVolumeName, constants.RunVolumeName, constants.VarLogVolumeName,
 			constants.MySQLConfVolumeName, constants.MySQLInitConfVolumeName,
-			constants.MySQLConfSecretVolumeName, constants.SlowQueryLogAgentConfigVolumeName:
+			constants.MySQLConfSecretVolumeName, constants.SlowQueryLogAgentConfigVolumeName,
+			constants.AuditLogAgentConfigVolumeName, constants.ServiceAccountTokenVolumeName:
 
 			allErrs = append(allErrs, field.Invalid(pp.Index(i), vol.Name, "reserved volume name"))
 		}
 	}
 
-	return nil, allErrs
+	return warns, allErrs
 }
 
 func (s MySQLClusterSpec) validateUpdate(ctx context.Context, apiReader client.Reader, old MySQLClusterSpec) (admission.Warnings, field.ErrorList) {
@@ -244,6 +1353,14 @@ func (s MySQLClusterSpec) validateUpdate(ctx context.Context, apiReader client.R
 		p := p.Child("replicas")
 		allErrs = append(allErrs, field.Forbidden(p, "decreasing replicas is not supported yet"))
 	}
+	if s.ReplicationSource != nil {
+		p := p.Child("replicationSource")
+		if old.ReplicationSource == nil {
+			allErrs = append(allErrs, field.Forbidden(p, "replication can be initiated only with new clusters"))
+		} else if s.ReplicationSource.ClusterName != old.ReplicationSource.ClusterName || s.ReplicationSource.Namespace != old.ReplicationSource.Namespace {
+			allErrs = append(allErrs, field.Forbidden(p, "replication source cannot be modified"))
+		}
+	}
 	if s.ReplicationSourceSecretName != nil {
 		p := p.Child("replicationSourceSecretName")
 		if old.ReplicationSourceSecretName == nil {
@@ -252,6 +1369,22 @@ func (s MySQLClusterSpec) validateUpdate(ctx context.Context, apiReader client.R
 			allErrs = append(allErrs, field.Forbidden(p, "replication source secret name cannot be modified"))
 		}
 	}
+	if s.AdoptSourceSecretName != nil {
+		p := p.Child("adoptSourceSecretName")
+		if old.AdoptSourceSecretName == nil {
+			allErrs = append(allErrs, field.Forbidden(p, "adoption can be initiated only with new clusters"))
+		} else if *s.AdoptSourceSecretName != *old.AdoptSourceSecretName {
+			allErrs = append(allErrs, field.Forbidden(p, "adoption source secret name cannot be modified"))
+		}
+	}
+	if (s.PersistentVolumeClaimRetentionPolicy == nil) != (old.PersistentVolumeClaimRetentionPolicy == nil) {
+		p := p.Child("persistentVolumeClaimRetentionPolicy")
+		allErrs = append(allErrs, field.Forbidden(p, "can be set only at creation"))
+	}
+	if s.OrdinalStart() != old.OrdinalStart() {
+		p := p.Child("ordinals", "start")
+		allErrs = append(allErrs, field.Forbidden(p, "not editable"))
+	}
 	if !equality.Semantic.DeepEqual(s.Restore, old.Restore) {
 		p := p.Child("restore")
 		allErrs = append(allErrs, field.Forbidden(p, "not editable"))
@@ -411,7 +1544,7 @@ type PodTemplateSpec struct {
 }
 
 // OverwriteableContainerName is the name of the container.
-// +kubebuilder:validation:Enum=agent;moco-init;slow-log;mysqld-exporter
+// +kubebuilder:validation:Enum=agent;moco-init;slow-log;audit-log;mysqld-exporter
 type OverwriteableContainerName string
 
 // String implements the fmt.Stringer interface.
@@ -423,6 +1556,7 @@ const (
 	AgentContainerName             OverwriteableContainerName = constants.AgentContainerName
 	InitContainerName              OverwriteableContainerName = constants.InitContainerName
 	SlowQueryLogAgentContainerName OverwriteableContainerName = constants.SlowQueryLogAgentContainerName
+	AuditLogAgentContainerName     OverwriteableContainerName = constants.AuditLogAgentContainerName
 	ExporterContainerName          OverwriteableContainerName = constants.ExporterContainerName
 )
 
@@ -435,6 +1569,17 @@ type OverwriteContainer struct {
 	// Resources is the container resource to be overwritten.
 	// +optional
 	Resources *ResourceRequirementsApplyConfiguration `json:"resources,omitempty"`
+
+	// SecurityContext is merged into the security context of the container.
+	// Fields specified here take precedence over the defaults set by MOCO.
+	// +optional
+	SecurityContext *SecurityContextApplyConfiguration `json:"securityContext,omitempty"`
+
+	// ImagePullPolicy is the image pull policy of the container.
+	// If not specified, IfNotPresent is used.
+	// +kubebuilder:validation:Enum=Always;Never;IfNotPresent
+	// +optional
+	ImagePullPolicy corev1.PullPolicy `json:"imagePullPolicy,omitempty"`
 }
 
 // ResourceRequirementsApplyConfiguration is the type defined to implement the DeepCopy method.
@@ -454,6 +1599,23 @@ func (in *ResourceRequirementsApplyConfiguration) DeepCopy() *ResourceRequiremen
 	return out
 }
 
+// SecurityContextApplyConfiguration is the type defined to implement the DeepCopy method.
+type SecurityContextApplyConfiguration corev1ac.SecurityContextApplyConfiguration
+
+// DeepCopy is copying the receiver, creating a new SecurityContextApplyConfiguration.
+func (in *SecurityContextApplyConfiguration) DeepCopy() *SecurityContextApplyConfiguration {
+	out := new(SecurityContextApplyConfiguration)
+	bytes, err := json.Marshal(in)
+	if err != nil {
+		panic("Failed to marshal")
+	}
+	err = json.Unmarshal(bytes, out)
+	if err != nil {
+		panic("Failed to unmarshal")
+	}
+	return out
+}
+
 // PersistentVolumeClaimSpecApplyConfiguration is the type defined to implement the DeepCopy method.
 type PersistentVolumeClaimSpecApplyConfiguration corev1ac.PersistentVolumeClaimSpecApplyConfiguration
 
@@ -541,6 +1703,23 @@ type ServiceTemplate struct {
 	Spec *ServiceSpecApplyConfiguration `json:"spec,omitempty"`
 }
 
+// ServicePortApplyConfiguration is the type defined to implement the DeepCopy method.
+type ServicePortApplyConfiguration corev1ac.ServicePortApplyConfiguration
+
+// DeepCopy is copying the receiver, creating a new ServicePortApplyConfiguration.
+func (in *ServicePortApplyConfiguration) DeepCopy() *ServicePortApplyConfiguration {
+	out := new(ServicePortApplyConfiguration)
+	bytes, err := json.Marshal(in)
+	if err != nil {
+		panic("Failed to marshal")
+	}
+	err = json.Unmarshal(bytes, out)
+	if err != nil {
+		panic("Failed to unmarshal")
+	}
+	return out
+}
+
 // RestoreSpec represents a set of parameters for Point-in-Time Recovery.
 type RestoreSpec struct {
 	// SourceName is the name of the source `MySQLCluster`.
@@ -557,6 +1736,51 @@ type RestoreSpec struct {
 
 	// Specifies parameters for restore Pod.
 	JobConfig `json:"jobConfig"`
+
+	// Prefix is the prefix of the object keys of the backup files in the bucket.
+	// If not set, the prefix is derived from SourceNamespace and SourceName.
+	// This is useful when the backup files have been moved to another location.
+	// +kubebuilder:validation:Pattern="^([0-9A-Za-z_.-]+/)*[0-9A-Za-z_.-]+/?$"
+	// +optional
+	Prefix string `json:"prefix,omitempty"`
+
+	// Specifies the duration in seconds relative to the startTime that the restore job
+	// may be continuously active before the system tries to terminate it; value
+	// must be positive integer. If not set, the restore job has no deadline.
+	// +kubebuilder:validation:Minimum=1
+	// +nullable
+	// +optional
+	ActiveDeadlineSeconds *int64 `json:"activeDeadlineSeconds,omitempty"`
+
+	// TTLSecondsAfterRestored is the number of seconds to keep the restore Job and its
+	// Role and RoleBinding after the restoration has completed successfully.
+	// If not set, they are deleted as soon as the restoration completes.
+	// +kubebuilder:validation:Minimum=0
+	// +nullable
+	// +optional
+	TTLSecondsAfterRestored *int32 `json:"ttlSecondsAfterRestored,omitempty"`
+
+	// UnpublishNotReadyAddresses makes the headless Service stop publishing the addresses
+	// of not-ready Pods until the restoration completes, so that clients do not connect
+	// to half-initialized instances.  The addresses are published again after that.
+	// The default is false.
+	// +optional
+	UnpublishNotReadyAddresses bool `json:"unpublishNotReadyAddresses,omitempty"`
+
+	// PointInTimeClone makes the cluster an ephemeral read-only clone of the source
+	// restored to RestorePoint.  MOCO never makes the primary instance writable,
+	// and deletes the MySQLCluster when the TTL expires after the restoration completes.
+	// +optional
+	PointInTimeClone *PointInTimeCloneSpec `json:"pointInTimeClone,omitempty"`
+}
+
+// PointInTimeCloneSpec represents the lifetime of a point-in-time clone.
+type PointInTimeCloneSpec struct {
+	// TTLSecondsAfterRestored is the number of seconds to keep the cluster after
+	// the restoration has completed successfully.  The MySQLCluster and its
+	// resources are deleted after that.
+	// +kubebuilder:validation:Minimum=1
+	TTLSecondsAfterRestored int32 `json:"ttlSecondsAfterRestored"`
 }
 
 // MySQLClusterStatus defines the observed state of MySQLCluster
@@ -584,31 +1808,179 @@ type MySQLClusterStatus struct {
 	// +optional
 	ErrantReplicaList []int `json:"errantReplicaList,omitempty"`
 
+	// IneligibleReplicaList is the list of indices of replicas that are ready but cannot
+	// become the primary yet because they have been ready for less than `spec.minReadySeconds`.
+	// +optional
+	IneligibleReplicaList []int `json:"ineligibleReplicaList,omitempty"`
+
 	// Backup is the status of the last successful backup.
 	// +optional
 	Backup BackupStatus `json:"backup"`
 
+	// BackupAttempts is the list of the results of the recent backup Jobs, newest first.
+	// Failed attempts are also recorded unlike `backup`.
+	// +optional
+	BackupAttempts []BackupAttemptStatus `json:"backupAttempts,omitempty"`
+
 	// RestoredTime is the time when the cluster data is restored.
 	// +optional
 	RestoredTime *metav1.Time `json:"restoredTime,omitempty"`
 
+	// ExpiryTime is the time when the cluster is deleted if it is a point-in-time clone.
+	// It is set when the restoration completes.
+	// +optional
+	ExpiryTime *metav1.Time `json:"expiryTime,omitempty"`
+
 	// Cloned indicates if the initial cloning from an external source has been completed.
 	// +optional
 	Cloned bool `json:"cloned,omitempty"`
 
+	// AdoptedTime is the time when the data of the external mysqld in `spec.adoptSourceSecretName`
+	// is cloned.  Once this is set, MOCO never clones the data again.
+	// +optional
+	AdoptedTime *metav1.Time `json:"adoptedTime,omitempty"`
+
+	// CloneFailures is the list of instances for which the last clone attempt failed.
+	// An entry is removed when cloning data to the instance succeeds.
+	// +optional
+	CloneFailures []CloneFailureStatus `json:"cloneFailures,omitempty"`
+
+	// CloningReplicas is the number of replicas to which data is being cloned from the primary.
+	// +optional
+	CloningReplicas int `json:"cloningReplicas,omitempty"`
+
+	// QueuedClones is the number of replicas waiting for the running clones to finish
+	// because of `spec.maxConcurrentClones`.
+	// +optional
+	QueuedClones int `json:"queuedClones,omitempty"`
+
+	// ReplicationSource is the state of the replication from the source of an intermediate primary.
+	// +optional
+	ReplicationSource *ReplicationSourceStatus `json:"replicationSource,omitempty"`
+
+	// Rollout is the progress of the rollout of the StatefulSet.
+	// +optional
+	Rollout *RolloutStatus `json:"rollout,omitempty"`
+
+	// CertificateExpiry is the time when the certificate for moco-agent expires.
+	// +optional
+	CertificateExpiry *metav1.Time `json:"certificateExpiry,omitempty"`
+
+	// MyCnfConfigMapName is the name of the ConfigMap that contains my.cnf
+	// generated by MOCO and currently used by mysqld.
+	// +optional
+	MyCnfConfigMapName string `json:"myCnfConfigMapName,omitempty"`
+
+	// Users is the list of users created from `spec.users`.
+	// +optional
+	Users []UserStatus `json:"users,omitempty"`
+
+	// Databases is the list of databases created from `spec.databases`.
+	// +optional
+	Databases []string `json:"databases,omitempty"`
+
+	// PasswordRotation is the state of rotating the passwords of the users for MOCO.
+	// +optional
+	PasswordRotation *PasswordRotationStatus `json:"passwordRotation,omitempty"`
+
 	// ReconcileInfo represents version information for reconciler.
 	// +optional
 	ReconcileInfo ReconcileInfo `json:"reconcileInfo"`
 }
 
+// UserStatus represents the state of a user created from `spec.users`.
+type UserStatus struct {
+	// Name is the name of the user.
+	Name string `json:"name"`
+
+	// Revision is a digest of the grants and the password Secret applied to the user.
+	Revision string `json:"revision"`
+}
+
+// PasswordRotationStatus represents the state of rotating the passwords of the users for MOCO.
+type PasswordRotationStatus struct {
+	// Retained indicates that mysqld accepts both the current and new passwords
+	// while a rotation is in progress.
+	// +optional
+	Retained bool `json:"retained,omitempty"`
+
+	// LastRotationTime is the time when the last rotation completed.
+	// +optional
+	LastRotationTime *metav1.Time `json:"lastRotationTime,omitempty"`
+}
+
+// ReplicationSourceStatus represents the state of the replication from the source.
+type ReplicationSourceStatus struct {
+	// Host is the host name of the source.
+	Host string `json:"host"`
+
+	// IOThreadRunning indicates if the replication I/O thread is running.
+	IOThreadRunning bool `json:"ioThreadRunning"`
+
+	// SQLThreadRunning indicates if the replication SQL thread is running.
+	SQLThreadRunning bool `json:"sqlThreadRunning"`
+
+	// LastIOError is the last error of the replication I/O thread.
+	// +optional
+	LastIOError string `json:"lastIOError,omitempty"`
+
+	// LastSQLError is the last error of the replication SQL thread.
+	// +optional
+	LastSQLError string `json:"lastSQLError,omitempty"`
+
+	// SecondsBehindSource is the replication lag from the source in seconds.
+	// This is not set if the lag is unknown.
+	// +optional
+	SecondsBehindSource *int64 `json:"secondsBehindSource,omitempty"`
+}
+
+// RolloutStatus represents the progress of the rollout of the StatefulSet.
+type RolloutStatus struct {
+	// Replicas is the desired number of instances.
+	Replicas int32 `json:"replicas"`
+
+	// UpdatedReplicas is the number of instances updated to UpdateRevision.
+	UpdatedReplicas int32 `json:"updatedReplicas"`
+
+	// ReadyReplicas is the number of ready instances.
+	ReadyReplicas int32 `json:"readyReplicas"`
+
+	// CurrentRevision is the revision of the StatefulSet before the rollout.
+	// +optional
+	CurrentRevision string `json:"currentRevision,omitempty"`
+
+	// UpdateRevision is the revision of the StatefulSet being rolled out.
+	// +optional
+	UpdateRevision string `json:"updateRevision,omitempty"`
+}
+
+// CloneFailureStatus represents the failed clone attempts for an instance.
+type CloneFailureStatus struct {
+	// Index is the index of the instance.
+	Index int `json:"index"`
+
+	// Count is the number of consecutive failed attempts.
+	Count int `json:"count"`
+
+	// LastError is the error of the last failed attempt.
+	LastError string `json:"lastError"`
+
+	// LastFailureTime is the time when the last attempt failed.
+	LastFailureTime metav1.Time `json:"lastFailureTime"`
+}
+
 const (
-	ConditionInitialized          string = "Initialized"
-	ConditionAvailable            string = "Available"
-	ConditionHealthy              string = "Healthy"
-	ConditionStatefulSetReady     string = "StatefulSetReady"
-	ConditionReconcileSuccess     string = "ReconcileSuccess"
-	ConditionReconciliationActive string = "ReconciliationActive"
-	ConditionClusteringActive     string = "ClusteringActive"
+	ConditionInitialized             string = "Initialized"
+	ConditionAvailable               string = "Available"
+	ConditionHealthy                 string = "Healthy"
+	ConditionStatefulSetReady        string = "StatefulSetReady"
+	ConditionReconcileSuccess        string = "ReconcileSuccess"
+	ConditionReconciliationActive    string = "ReconciliationActive"
+	ConditionClusteringActive        string = "ClusteringActive"
+	ConditionSplitBrainDetected      string = "SplitBrainDetected"
+	ConditionCertificateExpiringSoon string = "CertificateExpiringSoon"
+	ConditionProgressing             string = "Progressing"
+	ConditionDegraded                string = "Degraded"
 )
 
 // BackupStatus represents the status of the last successful backup.
@@ -651,6 +2023,38 @@ type BackupStatus struct {
 	Warnings []string `json:"warnings"`
 }
 
+// Results of backup attempts.
+const (
+	BackupAttemptRunning   = "Running"
+	BackupAttemptSucceeded = "Succeeded"
+	BackupAttemptFailed    = "Failed"
+)
+
+// BackupAttemptStatus represents the result of a backup Job.
+type BackupAttemptStatus struct {
+	// JobName is the name of the backup Job.
+	JobName string `json:"jobName"`
+
+	// StartTime is the time when the Job started.
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// CompletionTime is the time when the Job succeeded or failed.
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Result is one of "Running", "Succeeded", or "Failed".
+	Result string `json:"result"`
+
+	// Failures is the number of failed Pods of the Job, i.e. the number of retries taken.
+	// +optional
+	Failures int32 `json:"failures,omitempty"`
+
+	// Reason is the reason why the Job failed, if any.
+	// +optional
+	Reason string `json:"reason,omitempty"`
+}
+
 // ReconcileInfo is the type to record the last reconciliation information.
 type ReconcileInfo struct {
 	// Generation is the `metadata.generation` value of the last reconciliation.
@@ -673,7 +2077,10 @@ type ReconcileInfo struct {
 // +kubebuilder:printcolumn:name="Errant replicas",type="integer",JSONPath=".status.errantReplicas"
 // +kubebuilder:printcolumn:name="Clustering Active",type="string",JSONPath=".status.conditions[?(@.type=='ClusteringActive')].status"
 // +kubebuilder:printcolumn:name="Reconcile Active",type="string",JSONPath=".status.conditions[?(@.type=='ReconciliationActive')].status"
+// +kubebuilder:printcolumn:name="Progressing",type="string",JSONPath=".status.conditions[?(@.type=='Progressing')].status"
 // +kubebuilder:printcolumn:name="Last backup",type="string",JSONPath=".status.backup.time"
+// +kubebuilder:printcolumn:name="my.cnf",type="string",JSONPath=".status.myCnfConfigMapName",priority=1
+// +kubebuilder:printcolumn:name="Cert expiry",type="date",JSONPath=".status.certificateExpiry",priority=1
 
 // MySQLCluster is the Schema for the mysqlclusters API
 type MySQLCluster struct {
@@ -689,9 +2096,85 @@ func (r *MySQLCluster) PrefixedName() string {
 	return "moco-" + r.Name
 }
 
-// PodName returns PrefixedName() + "-" + index
+// OrdinalStart returns the ordinal number of the first Pod.
+func (s MySQLClusterSpec) OrdinalStart() int {
+	if s.Ordinals == nil {
+		return 0
+	}
+	return int(s.Ordinals.Start)
+}
+
+// MySQLDataDir returns the directory where the "mysql-data" volume is mounted.
+func (s MySQLClusterSpec) MySQLDataDir() string {
+	if s.DataDir != "" {
+		return s.DataDir
+	}
+	return constants.MySQLDataPath
+}
+
+// MySQLConfFile returns the path of my.cnf in mysqld container.
+func (s MySQLClusterSpec) MySQLConfFile() string {
+	if s.MySQLConfPath != "" {
+		return s.MySQLConfPath
+	}
+	return filepath.Join(constants.MySQLConfPath, constants.MySQLConfName)
+}
+
+// ExporterSidecarEnabled returns true if mysqld_exporter runs as a sidecar in the MySQL Pods.
+func (s MySQLClusterSpec) ExporterSidecarEnabled() bool {
+	return len(s.Collectors) > 0 && !s.ExporterDeploymentEnabled()
+}
+
+// ExporterDeploymentEnabled returns true if mysqld_exporter runs as a Deployment.
+func (s MySQLClusterSpec) ExporterDeploymentEnabled() bool {
+	return len(s.Collectors) > 0 && s.ExporterMode == ExporterModeDeployment
+}
+
+// ExporterUser returns the user in `spec.users` specified by `spec.exporterUserName`.
+// It returns nil if mysqld_exporter uses `moco-exporter`.
+func (s MySQLClusterSpec) ExporterUser() *UserSpec {
+	if s.ExporterUserName == "" {
+		return nil
+	}
+	for i := range s.Users {
+		if s.Users[i].Name == s.ExporterUserName {
+			return &s.Users[i]
+		}
+	}
+	return nil
+}
+
+// PodName returns PrefixedName() + "-" + ordinal of the index-th Pod.
 func (r *MySQLCluster) PodName(index int) string {
-	return fmt.Sprintf("%s-%d", r.PrefixedName(), index)
+	return fmt.Sprintf("%s-%d", r.PrefixedName(), r.Spec.OrdinalStart()+index)
+}
+
+// PodIndex returns the index of the Pod from its name.
+// This is the reverse of PodName.
+func (r *MySQLCluster) PodIndex(podName string) (int, error) {
+	fields := strings.Split(podName, "-")
+	ordinal, err := strconv.Atoi(fields[len(fields)-1])
+	if err != nil {
+		return 0, fmt.Errorf("bad pod name: %s", podName)
+	}
+	return ordinal - r.Spec.OrdinalStart(), nil
+}
+
+// InstanceResourcesOf returns the resources of the mysqld container of the index-th
+// instance given in `spec.instanceResources`, or nil if it is not given.
+func (s MySQLClusterSpec) InstanceResourcesOf(index int) *ResourceRequirementsApplyConfiguration {
+	for _, ir := range s.InstanceResources {
+		if int(ir.Index) == index {
+			return ir.Resources
+		}
+	}
+	return nil
+}
+
+// ServerID returns the server_id of the index-th instance.
+// moco-init calculates server_id by adding the ordinal number of the Pod to ServerIDBase.
+func (r *MySQLCluster) ServerID(index int) int32 {
+	return r.Spec.ServerIDBase + int32(r.Spec.OrdinalStart()+index)
 }
 
 // UserSecretName returns the name of the Secret for users.
@@ -706,6 +2189,70 @@ func (r *MySQLCluster) MyCnfSecretName() string {
 	return "moco-my-cnf-" + r.Name
 }
 
+// CloneSourceSecret returns the name of the Secret that contains the info of the mysqld
+// from which the primary clones the data.
+// It returns an empty string if the cluster does not clone data from an external mysqld.
+func (r *MySQLCluster) CloneSourceSecret() string {
+	if r.Spec.AdoptSourceSecretName != nil {
+		return *r.Spec.AdoptSourceSecretName
+	}
+	return r.ReplicationSourceSecret()
+}
+
+// IsAdopting returns true if the cluster has yet to clone the data of the external mysqld to adopt.
+func (r *MySQLCluster) IsAdopting() bool {
+	return r.Spec.AdoptSourceSecretName != nil && r.Status.AdoptedTime == nil
+}
+
+// ReplicationSourceSecret returns the name of the Secret that contains the replication source info.
+// For `spec.replicationSource`, the Secret is generated by the controller.
+// It returns an empty string if the cluster does not replicate data from a source.
+func (r *MySQLCluster) ReplicationSourceSecret() string {
+	switch {
+	case r.Spec.ReplicationSourceSecretName != nil:
+		return *r.Spec.ReplicationSourceSecretName
+	case r.Spec.ReplicationSource != nil:
+		return r.SourceClusterSecretName()
+	}
+	return ""
+}
+
+// IsIntermediatePrimary returns true if the primary of the cluster replicates data from a source.
+func (r *MySQLCluster) IsIntermediatePrimary() bool {
+	return r.Spec.ReplicationSourceSecretName != nil || r.Spec.ReplicationSource != nil
+}
+
+// IsPointInTimeClone returns true if the cluster is an ephemeral clone restored from backups.
+func (r *MySQLCluster) IsPointInTimeClone() bool {
+	return r.Spec.Restore != nil && r.Spec.Restore.PointInTimeClone != nil
+}
+
+// IsReadOnly returns true if MOCO never makes the primary instance of the cluster writable.
+func (r *MySQLCluster) IsReadOnly() bool {
+	return r.IsIntermediatePrimary() || r.IsPointInTimeClone()
+}
+
+// RotatingPasswordSecretName returns the name of the Secret that holds the new passwords
+// of the users for MOCO while they are being rotated.
+func (r *MySQLCluster) RotatingPasswordSecretName() string {
+	return "moco-rotating-" + r.Name
+}
+
+// SourceClusterSecretName returns the name of the Secret generated for `spec.replicationSource`.
+func (r *MySQLCluster) SourceClusterSecretName() string {
+	return "moco-repl-source-" + r.Name
+}
+
+// SourceCluster returns the key of the MySQLCluster in `spec.replicationSource`.
+// It must be called only when `spec.replicationSource` is set.
+func (r *MySQLCluster) SourceCluster() types.NamespacedName {
+	ns := r.Spec.ReplicationSource.Namespace
+	if ns == "" {
+		ns = r.Namespace
+	}
+	return types.NamespacedName{Namespace: ns, Name: r.Spec.ReplicationSource.ClusterName}
+}
+
 // ControllerSecretName returns the name of the Secret for MOCO controller.
 // This Secret is placed in the namespace of the controller.
 func (r *MySQLCluster) ControllerSecretName() string {
@@ -737,6 +2284,36 @@ func (r *MySQLCluster) SlowQueryLogAgentConfigMapName() string {
 	return fmt.Sprintf("moco-slow-log-agent-config-%s", r.Name)
 }
 
+// AuditLogAgentConfigMapName returns the name of the audit log agent config name.
+func (r *MySQLCluster) AuditLogAgentConfigMapName() string {
+	return fmt.Sprintf("moco-audit-log-agent-config-%s", r.Name)
+}
+
+// GatewayRouteName returns the name of the route of Gateway API for the primary instance.
+func (r *MySQLCluster) GatewayRouteName() string {
+	return r.PrefixedName()
+}
+
+// PodMonitorName returns the name of the PodMonitor for mysqld_exporter.
+func (r *MySQLCluster) PodMonitorName() string {
+	return r.PrefixedName()
+}
+
+// MetricsServiceName returns the name of the Service for mysqld_exporter running as a sidecar.
+func (r *MySQLCluster) MetricsServiceName() string {
+	return r.PrefixedName() + "-metrics"
+}
+
+// DashboardConfigMapName returns the name of the ConfigMap for the Grafana dashboard.
+func (r *MySQLCluster) DashboardConfigMapName() string {
+	return fmt.Sprintf("moco-dashboard-%s", r.Name)
+}
+
+// ExporterName returns the name of the Deployment and Service of mysqld_exporter.
+func (r *MySQLCluster) ExporterName() string {
+	return fmt.Sprintf("moco-exporter-%s", r.Name)
+}
+
 // CertificateName returns the name of Certificate issued for moco-agent gRPC server.
 // The Certificate will be created in the namespace of the controller.
 //
@@ -756,6 +2333,11 @@ func (r *MySQLCluster) BackupCronJobName() string {
 	return fmt.Sprintf("moco-backup-%s", r.Name)
 }
 
+// MaintenanceCronJobName returns the name of CronJob for maintenance.
+func (r *MySQLCluster) MaintenanceCronJobName() string {
+	return fmt.Sprintf("moco-maintenance-%s", r.Name)
+}
+
 // BackupRoleName returns the name of Role/RoleBinding for backup.
 func (r *MySQLCluster) BackupRoleName() string {
 	return fmt.Sprintf("moco-backup-%s", r.Name)
@@ -771,6 +2353,257 @@ func (r *MySQLCluster) RestoreRoleName() string {
 	return fmt.Sprintf("moco-restore-%s", r.Name)
 }
 
+// RoleLabelKey returns the key of the label to represent the role of Pods.
+// If the cluster is not annotated with the key, this returns constants.LabelMocoRole.
+func (r *MySQLCluster) RoleLabelKey() string {
+	if key := r.Annotations[constants.AnnRoleLabelKey]; key != "" {
+		return key
+	}
+	return constants.LabelMocoRole
+}
+
+// HostNamespaces returns the names of the fields in the Pod template that make
+// the Pods share the host namespaces, i.e., hostNetwork, hostPID, and hostIPC.
+func (r *MySQLCluster) HostNamespaces() []string {
+	var names []string
+	spec := &r.Spec.PodTemplate.Spec
+	if spec.HostNetwork != nil && *spec.HostNetwork {
+		names = append(names, "hostNetwork")
+	}
+	if spec.HostPID != nil && *spec.HostPID {
+		names = append(names, "hostPID")
+	}
+	if spec.HostIPC != nil && *spec.HostIPC {
+		names = append(names, "hostIPC")
+	}
+	return names
+}
+
+// HostNamespacesAllowed returns true if the cluster is annotated to allow
+// the Pods to share the host namespaces.
+func (r *MySQLCluster) HostNamespacesAllowed() bool {
+	return r.Annotations[constants.AnnAllowHostNamespaces] == "true"
+}
+
+// EvenReplicasAllowed returns true if the cluster is annotated to allow
+// an even number of instances.
+func (r *MySQLCluster) EvenReplicasAllowed() bool {
+	return r.Annotations[constants.AnnAllowEvenReplicas] == "true"
+}
+
+// ReplicationAllowedTo returns true if MySQLClusters in namespace may replicate from the cluster.
+// The replicas get the passwords of the cluster, so those in other namespaces are allowed
+// only if the cluster is annotated with their namespace.
+func (r *MySQLCluster) ReplicationAllowedTo(namespace string) bool {
+	if namespace == r.Namespace {
+		return true
+	}
+	for _, ns := range strings.Split(r.Annotations[constants.AnnReplicationAllowedNamespaces], ",") {
+		if strings.TrimSpace(ns) == namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// isSubPath returns true if path is dir or in dir.
+func isSubPath(path, dir string) bool {
+	return path == dir || strings.HasPrefix(path, strings.TrimSuffix(dir, "/")+"/")
+}
+
+func (s MySQLClusterSpec) validateDirs(p *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	var mounts []string
+	for _, c := range s.PodTemplate.Spec.Containers {
+		if c.Name == nil || *c.Name != constants.MysqldContainerName {
+			continue
+		}
+		for _, m := range c.VolumeMounts {
+			if m.MountPath != nil {
+				mounts = append(mounts, filepath.Clean(*m.MountPath))
+			}
+		}
+	}
+
+	if s.DataDir != "" {
+		pp := p.Child("dataDir")
+		reserved := []string{
+			constants.TmpPath, constants.RunPath, constants.LogDirPath, constants.MySQLConfPath,
+			constants.MySQLInitConfPath, constants.MyCnfSecretPath, constants.SharedPath,
+		}
+		if !filepath.IsAbs(s.DataDir) || filepath.Clean(s.DataDir) != s.DataDir || s.DataDir == "/" {
+			allErrs = append(allErrs, field.Invalid(pp, s.DataDir, "must be a clean absolute path other than /"))
+		} else {
+			for _, dir := range append(reserved, mounts...) {
+				if isSubPath(s.DataDir, dir) || isSubPath(dir, s.DataDir) {
+					allErrs = append(allErrs, field.Invalid(pp, s.DataDir, fmt.Sprintf("conflicts with the volume mounted at %s", dir)))
+					break
+				}
+			}
+		}
+	}
+
+	if s.MySQLConfPath != "" {
+		pp := p.Child("mysqlConfPath")
+		if !filepath.IsAbs(s.MySQLConfPath) || filepath.Clean(s.MySQLConfPath) != s.MySQLConfPath ||
+			s.MySQLConfPath == constants.MySQLConfPath || !isSubPath(s.MySQLConfPath, constants.MySQLConfPath) {
+			allErrs = append(allErrs, field.Invalid(pp, s.MySQLConfPath, fmt.Sprintf("must be a clean absolute path of a file in %s", constants.MySQLConfPath)))
+		}
+	}
+
+	if s.TmpDir != "" {
+		pp := p.Child("tmpDir")
+		if !filepath.IsAbs(s.TmpDir) || filepath.Clean(s.TmpDir) != s.TmpDir {
+			allErrs = append(allErrs, field.Invalid(pp, s.TmpDir, "must be a clean absolute path"))
+		} else {
+			// A subdirectory of a mount would not exist when mysqld starts, so the directory must be a mount path itself.
+			if !slices.Contains(append([]string{constants.TmpPath, s.MySQLDataDir()}, mounts...), s.TmpDir) {
+				allErrs = append(allErrs, field.Invalid(pp, s.TmpDir, "must be the mount path of a volume mounted in mysqld container"))
+			}
+		}
+	}
+
+	return allErrs
+}
+
+// validateServiceSelectors rejects `selector` in the Service templates because
+// MOCO must control it to route traffic to the right instances.
+// Selectors that already exist in old are allowed with a warning so that
+// clusters created before this validation can still be updated.
+func (s MySQLClusterSpec) validateServiceSelectors(old *MySQLClusterSpec) (admission.Warnings, field.ErrorList) {
+	var warns admission.Warnings
+	var allErrs field.ErrorList
+
+	selector := func(tmpl *ServiceTemplate) map[string]string {
+		if tmpl == nil || tmpl.Spec == nil {
+			return nil
+		}
+		return tmpl.Spec.Selector
+	}
+
+	check := func(name string, tmpl, oldTmpl *ServiceTemplate) {
+		sel := selector(tmpl)
+		if len(sel) == 0 {
+			return
+		}
+		p := field.NewPath("spec", name, "spec", "selector")
+		if old != nil && equality.Semantic.DeepEqual(sel, selector(oldTmpl)) {
+			warns = append(warns, fmt.Sprintf("%s is ignored because MOCO manages the selector; please remove it", p))
+			return
+		}
+		allErrs = append(allErrs, field.Forbidden(p, "the selector is reserved for MOCO"))
+	}
+
+	var oldPrimary, oldReplica *ServiceTemplate
+	if old != nil {
+		oldPrimary = old.PrimaryServiceTemplate
+		oldReplica = old.ReplicaServiceTemplate
+	}
+	check("primaryServiceTemplate", s.PrimaryServiceTemplate, oldPrimary)
+	check("replicaServiceTemplate", s.ReplicaServiceTemplate, oldReplica)
+
+	return warns, allErrs
+}
+
+func (r *MySQLCluster) validateReplicationSource() field.ErrorList {
+	if r.Spec.ReplicationSource == nil {
+		return nil
+	}
+	if r.SourceCluster() == (types.NamespacedName{Namespace: r.Namespace, Name: r.Name}) {
+		p := field.NewPath("spec", "replicationSource")
+		return field.ErrorList{field.Invalid(p, r.Spec.ReplicationSource.ClusterName, "the cluster cannot replicate from itself")}
+	}
+	return nil
+}
+
+// validateReplicationSourceNamespace validates that the source cluster in another namespace
+// exists and allows the cluster to replicate from it.
+func (r *MySQLCluster) validateReplicationSourceNamespace(ctx context.Context, apiReader client.Reader) field.ErrorList {
+	if r.Spec.ReplicationSource == nil {
+		return nil
+	}
+	srcKey := r.SourceCluster()
+	if srcKey.Namespace == r.Namespace {
+		return nil
+	}
+
+	p := field.NewPath("spec", "replicationSource", "namespace")
+	src := &MySQLCluster{}
+	if err := apiReader.Get(ctx, srcKey, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			return field.ErrorList{field.Invalid(p, srcKey.Namespace,
+				fmt.Sprintf("source cluster %s in another namespace must exist", srcKey))}
+		}
+		return field.ErrorList{field.InternalError(p, err)}
+	}
+	if !src.ReplicationAllowedTo(r.Namespace) {
+		return field.ErrorList{field.Forbidden(p,
+			fmt.Sprintf("source cluster %s does not allow replication to namespace %s; annotate it with %s to allow it", srcKey, r.Namespace, constants.AnnReplicationAllowedNamespaces))}
+	}
+	return nil
+}
+
+// validateRoleLabelKey validates the annotation of the role label key.
+// Once the cluster has been reconciled, the annotation cannot be added, changed,
+// or removed because the Services select Pods by the label.
+// Before that, moco-controller records the key given by its flag in the annotation.
+func (r *MySQLCluster) validateRoleLabelKey(old *MySQLCluster) field.ErrorList {
+	p := field.NewPath("metadata", "annotations").Key(constants.AnnRoleLabelKey)
+	key, ok := r.Annotations[constants.AnnRoleLabelKey]
+	if old != nil {
+		oldKey, oldOK := old.Annotations[constants.AnnRoleLabelKey]
+		if ok == oldOK && key == oldKey {
+			return nil
+		}
+		if oldOK || old.Status.ReconcileInfo.Generation != 0 {
+			return field.ErrorList{field.Forbidden(p, "the role label key cannot be changed; recreate the cluster to change it")}
+		}
+	}
+	if !ok {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsQualifiedName(key) {
+		allErrs = append(allErrs, field.Invalid(p, key, msg))
+	}
+	return allErrs
+}
+
+func (r *MySQLCluster) validateHostNamespaces() field.ErrorList {
+	if r.HostNamespacesAllowed() {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	p := field.NewPath("spec", "podTemplate", "spec")
+	for _, name := range r.HostNamespaces() {
+		allErrs = append(allErrs, field.Forbidden(p.Child(name),
+			fmt.Sprintf("sharing the host namespace is not allowed for MySQL Pods; annotate the cluster with %s=true to allow it", constants.AnnAllowHostNamespaces)))
+	}
+	return allErrs
+}
+
+// validateReplicas rejects an even number of instances unless it is allowed by the annotation.
+// The primary waits for the acknowledgements from the half of the replicas, so an even
+// number of instances does not tolerate more failures than one fewer instance.
+func (r *MySQLCluster) validateReplicas() (admission.Warnings, field.ErrorList) {
+	n := r.Spec.Replicas
+	if n < 2 || n%2 != 0 {
+		return nil, nil
+	}
+
+	msg := fmt.Sprintf("replicas should be an odd number: the primary waits for acknowledgements from %d of %d replicas, so %d instances tolerate no more failures than %d instances",
+		n/2, n-1, n, n-1)
+	if r.EvenReplicasAllowed() {
+		return admission.Warnings{"spec.replicas: " + msg}, nil
+	}
+
+	p := field.NewPath("spec", "replicas")
+	return nil, field.ErrorList{field.Invalid(p, n, fmt.Sprintf("%s; annotate the cluster with %s=true to allow it", msg, constants.AnnAllowEvenReplicas))}
+}
+
 //+kubebuilder:object:root=true
 
 // MySQLClusterList contains a list of MySQLCluster
@@ -4,12 +4,16 @@ import (
 	"context"
 	"crypto/rand"
 	"encoding/binary"
+	"fmt"
 	"math"
 
 	"github.com/cybozu-go/moco/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
@@ -17,21 +21,78 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 )
 
-func (r *MySQLCluster) SetupWebhookWithManager(mgr ctrl.Manager) error {
+// ServerIDAllocator allocates the base of server IDs for new MySQLClusters.
+type ServerIDAllocator interface {
+	// Allocate returns the base of server IDs for cluster.
+	// If dryRun is true, the allocation must not be recorded.
+	Allocate(ctx context.Context, cluster *MySQLCluster, dryRun bool) (int32, error)
+}
+
+// MysqldContainerDefaults represents the mysqld container that is filled in
+// for new clusters whose `spec.podTemplate` does not have it.
+// +kubebuilder:object:generate=false
+type MysqldContainerDefaults struct {
+	// Image is the image of mysqld container.
+	Image string
+
+	// Requests is the resource requests of mysqld container.
+	Requests corev1.ResourceList
+}
+
+// Apply fills in the image of mysqld container of cluster if it is empty, or adds mysqld
+// container with the image and resource requests of d if `spec.podTemplate` does not have it.
+// The other fields of the Pod template are kept as they are.
+func (d *MysqldContainerDefaults) Apply(cluster *MySQLCluster) {
+	spec := &cluster.Spec.PodTemplate.Spec
+	for i, c := range spec.Containers {
+		if c.Name == nil || *c.Name != constants.MysqldContainerName {
+			continue
+		}
+		if c.Image == nil || *c.Image == "" {
+			spec.Containers[i].WithImage(d.Image)
+		}
+		return
+	}
+
+	container := corev1ac.Container().
+		WithName(constants.MysqldContainerName).
+		WithImage(d.Image)
+	if len(d.Requests) > 0 {
+		container.WithResources(corev1ac.ResourceRequirements().
+			WithRequests(d.Requests.DeepCopy()))
+	}
+	spec.Containers = append(spec.Containers, *container)
+}
+
+// SetupWebhookWithManager registers the webhooks for MySQLCluster.
+// If allocator is nil, a random server ID base is assigned to new clusters.
+// If statefulSetOrdinals is false, new clusters with `spec.ordinals.start` are rejected
+// because the API server does not support `spec.ordinals` of StatefulSet.
+// If mysqldDefaults is not nil, the mysqld container is added to new clusters without it.
+func (r *MySQLCluster) SetupWebhookWithManager(mgr ctrl.Manager, allocator ServerIDAllocator, statefulSetOrdinals bool, mysqldDefaults *MysqldContainerDefaults) error {
+	a := &mySQLClusterAdmission{
+		client:              mgr.GetAPIReader(),
+		allocator:           allocator,
+		statefulSetOrdinals: statefulSetOrdinals,
+		mysqldDefaults:      mysqldDefaults,
+	}
 	return ctrl.NewWebhookManagedBy(mgr).
 		For(r).
-		WithValidator(&mySQLClusterAdmission{client: mgr.GetAPIReader()}).
-		WithDefaulter(&mySQLClusterAdmission{client: mgr.GetAPIReader()}).
+		WithValidator(a).
+		WithDefaulter(a).
 		Complete()
 }
 
 type mySQLClusterAdmission struct {
-	client client.Reader
+	client              client.Reader
+	allocator           ServerIDAllocator
+	statefulSetOrdinals bool
+	mysqldDefaults      *MysqldContainerDefaults
 }
 
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 
-//+kubebuilder:webhook:path=/mutate-moco-cybozu-com-v1beta2-mysqlcluster,mutating=true,failurePolicy=fail,sideEffects=None,matchPolicy=Equivalent,groups=moco.cybozu.com,resources=mysqlclusters,verbs=create,versions=v1beta2,name=mmysqlcluster.kb.io,admissionReviewVersions=v1
+//+kubebuilder:webhook:path=/mutate-moco-cybozu-com-v1beta2-mysqlcluster,mutating=true,failurePolicy=fail,sideEffects=NoneOnDryRun,matchPolicy=Equivalent,groups=moco.cybozu.com,resources=mysqlclusters,verbs=create,versions=v1beta2,name=mmysqlcluster.kb.io,admissionReviewVersions=v1
 
 var _ webhook.CustomDefaulter = &mySQLClusterAdmission{}
 
@@ -40,18 +101,39 @@ func (a *mySQLClusterAdmission) Default(ctx context.Context, obj runtime.Object)
 
 	controllerutil.AddFinalizer(cluster, constants.MySQLClusterFinalizer)
 
+	if a.mysqldDefaults != nil {
+		a.mysqldDefaults.Apply(cluster)
+	}
+
 	if cluster.Spec.ServerIDBase == 0 {
-		buf := make([]byte, 4) // server_id is a uint32 value
-		_, err := rand.Read(buf)
+		if a.allocator == nil {
+			cluster.Spec.ServerIDBase = randomServerIDBase()
+			return nil
+		}
+
+		var dryRun bool
+		if req, err := admission.RequestFromContext(ctx); err == nil && req.DryRun != nil {
+			dryRun = *req.DryRun
+		}
+		base, err := a.allocator.Allocate(ctx, cluster, dryRun)
 		if err != nil {
-			panic(err)
+			return fmt.Errorf("failed to allocate server IDs: %w", err)
 		}
-		cluster.Spec.ServerIDBase = int32(binary.LittleEndian.Uint32(buf)&uint32(math.MaxInt32>>1)) + 1
+		cluster.Spec.ServerIDBase = base
 	}
 
 	return nil
 }
 
+func randomServerIDBase() int32 {
+	buf := make([]byte, 4) // server_id is a uint32 value
+	_, err := rand.Read(buf)
+	if err != nil {
+		panic(err)
+	}
+	return int32(binary.LittleEndian.Uint32(buf)&uint32(math.MaxInt32>>1)) + 1
+}
+
 // TODO(user): change verbs to "verbs=create;update;delete" if you want to enable deletion validation.
 //+kubebuilder:webhook:path=/validate-moco-cybozu-com-v1beta2-mysqlcluster,mutating=false,failurePolicy=fail,sideEffects=None,matchPolicy=Equivalent,groups=moco.cybozu.com,resources=mysqlclusters,verbs=create;update,versions=v1beta2,name=vmysqlcluster.kb.io,admissionReviewVersions=v1
 
@@ -61,6 +143,20 @@ func (a *mySQLClusterAdmission) ValidateCreate(ctx context.Context, obj runtime.
 	cluster := obj.(*MySQLCluster)
 
 	warns, errs := cluster.Spec.validateCreate()
+	replicasWarns, replicasErrs := cluster.validateReplicas()
+	warns = append(warns, replicasWarns...)
+	errs = append(errs, replicasErrs...)
+	selectorWarns, selectorErrs := cluster.Spec.validateServiceSelectors(nil)
+	warns = append(warns, selectorWarns...)
+	errs = append(errs, selectorErrs...)
+	errs = append(errs, cluster.validateRoleLabelKey(nil)...)
+	if !a.statefulSetOrdinals && cluster.Spec.OrdinalStart() != 0 {
+		p := field.NewPath("spec", "ordinals", "start")
+		errs = append(errs, field.Forbidden(p, "the API server does not support spec.ordinals of StatefulSet"))
+	}
+	errs = append(errs, cluster.validateHostNamespaces()...)
+	errs = append(errs, cluster.validateReplicationSource()...)
+	errs = append(errs, cluster.validateReplicationSourceNamespace(ctx, a.client)...)
 	if len(errs) == 0 {
 		return warns, nil
 	}
@@ -73,6 +169,14 @@ func (a *mySQLClusterAdmission) ValidateUpdate(ctx context.Context, oldObj, newO
 	newCluster := newObj.(*MySQLCluster)
 
 	warns, errs := newCluster.Spec.validateUpdate(ctx, a.client, oldCluster.Spec)
+	replicasWarns, replicasErrs := newCluster.validateReplicas()
+	warns = append(warns, replicasWarns...)
+	errs = append(errs, replicasErrs...)
+	selectorWarns, selectorErrs := newCluster.Spec.validateServiceSelectors(&oldCluster.Spec)
+	warns = append(warns, selectorWarns...)
+	errs = append(errs, selectorErrs...)
+	errs = append(errs, newCluster.validateRoleLabelKey(oldCluster)...)
+	errs = append(errs, newCluster.validateHostNamespaces()...)
 	if len(errs) == 0 {
 		return warns, nil
 	}
@@ -2,6 +2,8 @@ package v1beta2_test
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/constants"
@@ -146,6 +148,80 @@ var _ = Describe("MySQLCluster Webhook", func() {
 		Expect(err).To(HaveOccurred())
 	})
 
+	It("should allow minSyncedReplicas less than replicas", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		r.Spec.MinSyncedReplicas = ptr.To[int32](2)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny minSyncedReplicas not less than replicas", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		r.Spec.MinSyncedReplicas = ptr.To[int32](3)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow a valid semiSync", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 5
+		r.Spec.SemiSync = &mocov1beta2.SemiSyncSpec{
+			WaitForReplicaCount: ptr.To[int32](3),
+			Timeout:             &metav1.Duration{Duration: 10 * time.Second},
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should warn semiSync.waitForReplicaCount less than the majority", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 5
+		r.Spec.SemiSync = &mocov1beta2.SemiSyncSpec{WaitForReplicaCount: ptr.To[int32](1)}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).To(ContainElement(ContainSubstring("spec.semiSync.waitForReplicaCount")))
+	})
+
+	It("should deny semiSync.waitForReplicaCount not less than replicas", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		r.Spec.SemiSync = &mocov1beta2.SemiSyncSpec{WaitForReplicaCount: ptr.To[int32](3)}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny a zero semiSync.timeout", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		r.Spec.SemiSync = &mocov1beta2.SemiSyncSpec{Timeout: &metav1.Duration{}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should validate replicaDrainSeconds", func() {
+		r := makeMySQLCluster()
+		r.Spec.ReplicaDrainSeconds = ptr.To[int32](10)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicaDrainSeconds = ptr.To[int32](0)
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.ReplicaDrainSeconds = ptr.To[int32](60)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny a negative cloneRetryBackoff", func() {
+		r := makeMySQLCluster()
+		r.Spec.CloneRetryBackoff = &metav1.Duration{Duration: -time.Minute}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
 	It("should allow a valid logRotationSchedule", func() {
 		r := makeMySQLCluster()
 		r.Spec.LogRotationSchedule = "@every 30s"
@@ -153,175 +229,860 @@ var _ = Describe("MySQLCluster Webhook", func() {
 		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny an invalid logRotationSchedule", func() {
+	It("should deny an invalid logRotationSchedule", func() {
+		r := makeMySQLCluster()
+		r.Spec.LogRotationSchedule = "hoge fuga"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny without mysqld container", func() {
+		r := makeMySQLCluster()
+		r.Spec.PodTemplate.Spec.Containers = nil
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny without container name", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithImage("image:dev"))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny without init container name", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithInitContainers(corev1ac.Container().WithImage("image:dev"))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny mysqld container using reserved port", func() {
+		for _, port := range []*corev1ac.ContainerPortApplyConfiguration{
+			corev1ac.ContainerPort().WithContainerPort(constants.MySQLPort),
+			corev1ac.ContainerPort().WithName(constants.MySQLPortName),
+			corev1ac.ContainerPort().WithContainerPort(constants.MySQLAdminPort),
+			corev1ac.ContainerPort().WithName(constants.MySQLAdminPortName),
+			corev1ac.ContainerPort().WithContainerPort(constants.MySQLXPort),
+			corev1ac.ContainerPort().WithName(constants.MySQLXPortName),
+			corev1ac.ContainerPort().WithContainerPort(constants.MySQLHealthPort),
+			corev1ac.ContainerPort().WithName(constants.MySQLHealthPortName),
+		} {
+			r := makeMySQLCluster()
+			r.Spec.PodTemplate.Spec.Containers[0].WithPorts(port)
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("should deny headless service ports using reserved port", func() {
+		for _, port := range []*corev1ac.ServicePortApplyConfiguration{
+			corev1ac.ServicePort().WithName("foo").WithPort(constants.MySQLPort),
+			corev1ac.ServicePort().WithName(constants.MySQLPortName).WithPort(10000),
+			corev1ac.ServicePort().WithName("foo").WithPort(constants.MySQLAdminPort),
+			corev1ac.ServicePort().WithName(constants.MySQLAdminPortName).WithPort(10000),
+			corev1ac.ServicePort().WithName("foo").WithPort(constants.MySQLXPort),
+			corev1ac.ServicePort().WithName(constants.MySQLXPortName).WithPort(10000),
+			corev1ac.ServicePort().WithName("foo"),
+			corev1ac.ServicePort().WithPort(10000),
+		} {
+			r := makeMySQLCluster()
+			r.Spec.HeadlessServicePorts = []mocov1beta2.ServicePortApplyConfiguration{mocov1beta2.ServicePortApplyConfiguration(*port)}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred())
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.HeadlessServicePorts = []mocov1beta2.ServicePortApplyConfiguration{
+			mocov1beta2.ServicePortApplyConfiguration(*corev1ac.ServicePort().WithName("foo").WithPort(10000)),
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny duplicate headless service ports", func() {
+		for _, ports := range [][]*corev1ac.ServicePortApplyConfiguration{
+			{
+				corev1ac.ServicePort().WithName("foo").WithPort(10000),
+				corev1ac.ServicePort().WithName("foo").WithPort(10001),
+			},
+			{
+				corev1ac.ServicePort().WithName("foo").WithPort(10000),
+				corev1ac.ServicePort().WithName("bar").WithPort(10000),
+			},
+			{
+				corev1ac.ServicePort().WithName("foo").WithPort(10000).WithProtocol(corev1.ProtocolTCP),
+				corev1ac.ServicePort().WithName("bar").WithPort(10000),
+			},
+		} {
+			r := makeMySQLCluster()
+			for _, port := range ports {
+				r.Spec.HeadlessServicePorts = append(r.Spec.HeadlessServicePorts, mocov1beta2.ServicePortApplyConfiguration(*port))
+			}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred())
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.HeadlessServicePorts = []mocov1beta2.ServicePortApplyConfiguration{
+			mocov1beta2.ServicePortApplyConfiguration(*corev1ac.ServicePort().WithName("foo").WithPort(10000)),
+			mocov1beta2.ServicePortApplyConfiguration(*corev1ac.ServicePort().WithName("bar").WithPort(10000).WithProtocol(corev1.ProtocolUDP)),
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny reserved volume names", func() {
+		for _, volname := range []string{
+			constants.TmpVolumeName, constants.RunVolumeName, constants.VarLogVolumeName,
+			constants.MySQLConfVolumeName, constants.MySQLInitConfVolumeName,
+			constants.MySQLConfSecretVolumeName, constants.SlowQueryLogAgentConfigVolumeName,
+			constants.AuditLogAgentConfigVolumeName, constants.ServiceAccountTokenVolumeName,
+		} {
+			r := makeMySQLCluster()
+			spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+			spec.WithVolumes(corev1ac.Volume().WithName(volname))
+			r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred())
+		}
+	})
+
+	It("should deny agent container", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName("agent"))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny slow query log container if not disabled", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName(constants.SlowQueryLogAgentContainerName))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow slow log container if disabled", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName(constants.SlowQueryLogAgentContainerName))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.DisableSlowQueryLogContainer = true
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny audit log container if enabled", func() {
+		r := makeMySQLCluster()
+		r.Spec.EnableAuditLogContainer = true
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName(constants.AuditLogAgentContainerName))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.EnableAuditLogContainer = false
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny mysqld_exporter container if enabled", func() {
+		r := makeMySQLCluster()
+		r.Spec.Collectors = []string{"engine_innodb_status", "info_schema.innodb_metrics"}
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName(constants.ExporterContainerName))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow mysqld_exporter container if not enabled", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithContainers(corev1ac.Container().WithName(constants.ExporterContainerName))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should allow non-reserved init containers", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithInitContainers(corev1ac.Container().WithName("foobar"))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny moco-init init containers", func() {
+		r := makeMySQLCluster()
+		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
+		spec.WithInitContainers(corev1ac.Container().WithName("moco-init"))
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny decreasing replicas", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Replicas = 1
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny an even number of replicas unless allowed by the annotation", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 2
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(constants.AnnAllowEvenReplicas))
+
+		r.Spec.Replicas = 1
+		warnings.take()
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).NotTo(ContainElement(ContainSubstring("spec.replicas")))
+
+		r.Spec.Replicas = 4
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		By("allowing an even number of replicas with a warning")
+		r.Annotations = map[string]string{constants.AnnAllowEvenReplicas: "true"}
+		warnings.take()
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).To(ContainElement(ContainSubstring("spec.replicas: replicas should be an odd number")))
+
+		r.Spec.Replicas = 5
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).NotTo(ContainElement(ContainSubstring("spec.replicas")))
+	})
+
+	It("should deny negative values for replicas", func() {
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 4
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.Replicas = -1
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny adding replication source secret", func() {
+		r := makeMySQLCluster()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.ReplicationSourceSecretName = ptr.To[string]("fuga")
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny changing replication source secret name", func() {
+		r := makeMySQLCluster()
+		r.Spec.ReplicationSourceSecretName = ptr.To[string]("foo")
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.ReplicationSourceSecretName = ptr.To[string]("bar")
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should validate spec.adoptSourceSecretName", func() {
+		r := makeMySQLCluster()
+		r.Spec.AdoptSourceSecretName = ptr.To[string]("foo")
+		r.Spec.ReplicationSourceSecretName = ptr.To[string]("foo")
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSourceSecretName = nil
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.AdoptSourceSecretName = ptr.To[string]("bar")
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.AdoptSourceSecretName = nil
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.AdoptSourceSecretName = ptr.To[string]("foo")
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should validate spec.replicationSource", func() {
+		r := makeMySQLCluster()
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "test"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSource.Namespace = "default"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source"}
+		r.Spec.ReplicationSourceSecretName = ptr.To[string]("foo")
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSourceSecretName = nil
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.ReplicationSource.ClusterName = "other"
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "other"}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.ReplicationSource = nil
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source"}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny replication from another namespace unless the source allows it", func() {
+		ns := &corev1.Namespace{}
+		ns.Name = "repl-source"
+		err := k8sClient.Create(ctx, ns)
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "repl-source"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		source := makeMySQLCluster()
+		source.Namespace = "repl-source"
+		source.Name = "source"
+		err = k8sClient.Create(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			source.Finalizers = nil
+			err := k8sClient.Update(ctx, source)
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Delete(ctx, source)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}()
+
+		r = makeMySQLCluster()
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "repl-source"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring(constants.AnnReplicationAllowedNamespaces))
+
+		source.Annotations = map[string]string{constants.AnnReplicationAllowedNamespaces: "default"}
+		err = k8sClient.Update(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+
+		r = makeMySQLCluster()
+		r.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "repl-source"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny changing the role label key", func() {
+		r := makeMySQLCluster()
+		r.Annotations = map[string]string{constants.AnnRoleLabelKey: "example.com/role"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Annotations[constants.AnnRoleLabelKey] = "example.com/other-role"
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		delete(r.Annotations, constants.AnnRoleLabelKey)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow adding the role label key before the first reconciliation", func() {
+		r := makeMySQLCluster()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Annotations = map[string]string{constants.AnnRoleLabelKey: "invalid key"}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Annotations[constants.AnnRoleLabelKey] = "example.com/role"
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(r.Annotations, constants.AnnRoleLabelKey)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny adding the role label key to a reconciled cluster", func() {
+		r := makeMySQLCluster()
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Status.ReconcileInfo.Generation = r.Generation
+		err = k8sClient.Status().Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Annotations = map[string]string{constants.AnnRoleLabelKey: "example.com/role"}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny an invalid role label key", func() {
+		for _, key := range []string{"", "example.com/", "invalid key", "a/b/c"} {
+			r := makeMySQLCluster()
+			r.Annotations = map[string]string{constants.AnnRoleLabelKey: key}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "key: %q", key)
+		}
+	})
+
+	It("should deny sharing the host namespaces", func() {
+		for _, f := range []func(*mocov1beta2.PodSpecApplyConfiguration){
+			func(s *mocov1beta2.PodSpecApplyConfiguration) { s.HostNetwork = ptr.To[bool](true) },
+			func(s *mocov1beta2.PodSpecApplyConfiguration) { s.HostPID = ptr.To[bool](true) },
+			func(s *mocov1beta2.PodSpecApplyConfiguration) { s.HostIPC = ptr.To[bool](true) },
+		} {
+			r := makeMySQLCluster()
+			f(&r.Spec.PodTemplate.Spec)
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred())
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.PodTemplate.Spec.HostNetwork = ptr.To[bool](false)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.PodTemplate.Spec.HostNetwork = ptr.To[bool](true)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should allow sharing the host namespaces with the annotation", func() {
+		r := makeMySQLCluster()
+		r.Annotations = map[string]string{constants.AnnAllowHostNamespaces: "true"}
+		r.Spec.PodTemplate.Spec.HostNetwork = ptr.To[bool](true)
+		r.Spec.PodTemplate.Spec.HostPID = ptr.To[bool](true)
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		delete(r.Annotations, constants.AnnAllowHostNamespaces)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny reserved user names", func() {
+		for _, name := range []string{"root", constants.AdminUser, constants.WritableUser} {
+			r := makeMySQLCluster()
+			r.Spec.Users = []mocov1beta2.UserSpec{{Name: name, PasswordSecretName: "password"}}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), name)
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.Users = []mocov1beta2.UserSpec{{Name: "app", Grants: []string{"SELECT ON app.*"}, PasswordSecretName: "password"}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.Users = append(r.Spec.Users, mocov1beta2.UserSpec{Name: constants.BackupUser, PasswordSecretName: "password"})
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should deny invalid grants in spec.users", func() {
+		for _, g := range []string{
+			"SELECT",
+			"SELECT ON app",
+			"SELECT ON *.t1",
+			"SELECT (c1) ON app.t1",
+			"SELECT ON app.* TO 'root'@'%'",
+			"SELECT ON `app.*",
+			"SELECT; DROP DATABASE app; ON app.*",
+		} {
+			r := makeMySQLCluster()
+			r.Spec.Users = []mocov1beta2.UserSpec{{Name: "app", Grants: []string{g}, PasswordSecretName: "app"}}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "grant: %q", g)
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.Users = []mocov1beta2.UserSpec{{
+			Name:               "app",
+			Grants:             []string{"SELECT, INSERT ON app.*", "all privileges on `my-db`.`t``1`", "PROCESS ON *.*"},
+			PasswordSecretName: "app",
+		}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny exporterUserName not in spec.users", func() {
+		r := makeMySQLCluster()
+		r.Spec.ExporterUserName = "monitor"
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.Users = []mocov1beta2.UserSpec{{Name: "monitor", Grants: []string{"PROCESS, REPLICATION CLIENT ON *.*"}, PasswordSecretName: "monitor-password"}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate mysqldCommand", func() {
+		r := makeMySQLCluster()
+		r.Spec.MysqldCommand = []string{"", "mysqld"}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeMySQLCluster()
+		r.Spec.MysqldCommand = []string{"/wrapper.sh", "mysqld"}
+		r.Spec.PodTemplate.Spec.Containers[0].WithCommand("mysqld")
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeMySQLCluster()
+		r.Spec.MysqldCommand = []string{"/bin/sh", "-c", "ulimit -n 65536 && exec mysqld \"$@\"", "--"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should deny reserved or too long database names", func() {
+		for _, name := range []string{"mysql", "sys", "information_schema", "performance_schema", constants.InstanceRolesSchema, strings.Repeat("a", 65)} {
+			r := makeMySQLCluster()
+			r.Spec.Databases = []string{name}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), name)
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.Databases = []string{"app", strings.Repeat("a", 64)}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate spec.dataDir and spec.tmpDir", func() {
+		for _, dir := range []string{"data", "/", "/data/", "/var/log", "/var/log/mysql/data", "/etc"} {
+			r := makeMySQLCluster()
+			r.Spec.DataDir = dir
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), dir)
+		}
+
+		for _, dir := range []string{"tmp", "/var/tmp", "/tmp/../var/tmp", "/var/lib/mysql2", "/tmp/mysql", "/var/lib/mysql/tmp"} {
+			r := makeMySQLCluster()
+			r.Spec.TmpDir = dir
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), dir)
+		}
+
 		r := makeMySQLCluster()
-		r.Spec.LogRotationSchedule = "hoge fuga"
+		r.Spec.DataDir = "/data"
+		r.Spec.PodTemplate.Spec.Containers[0].WithVolumeMounts(corev1ac.VolumeMount().WithName("foo").WithMountPath("/data/foo"))
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
-	})
 
-	It("should deny without mysqld container", func() {
-		r := makeMySQLCluster()
-		r.Spec.PodTemplate.Spec.Containers = nil
-		err := k8sClient.Create(ctx, r)
+		r = makeMySQLCluster()
+		r.Spec.DataDir = "/data/mysql"
+		r.Spec.TmpDir = "/mysql-tmp/tmp"
+		r.Spec.PodTemplate.Spec.Containers[0].WithVolumeMounts(corev1ac.VolumeMount().WithName("mysql-tmp").WithMountPath("/mysql-tmp"))
+		err = k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
+
+		r.Spec.TmpDir = "/mysql-tmp"
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.TmpDir = "/data/mysql"
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny without container name", func() {
+	It("should validate spec.mysqlConfPath", func() {
+		for _, path := range []string{"my.cnf", "/etc/mysql", "/etc/mysql/", "/etc/mysql/../my.cnf", "/etc/my.cnf", "/etc/mysql-conf.d/my.cnf"} {
+			r := makeMySQLCluster()
+			r.Spec.MySQLConfPath = path
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), path)
+		}
+
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithImage("image:dev"))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.MySQLConfPath = "/etc/mysql/conf.d/moco.cnf"
 		err := k8sClient.Create(ctx, r)
-		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny without init container name", func() {
+	It("should validate spec.slowQueryLog", func() {
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithInitContainers(corev1ac.Container().WithImage("image:dev"))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.SlowQueryLog = &mocov1beta2.SlowQueryLogSpec{
+			LongQueryTime: &metav1.Duration{Duration: -time.Second},
+		}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
-	})
 
-	It("should deny mysqld container using reserved port", func() {
-		for _, port := range []*corev1ac.ContainerPortApplyConfiguration{
-			corev1ac.ContainerPort().WithContainerPort(constants.MySQLPort),
-			corev1ac.ContainerPort().WithName(constants.MySQLPortName),
-			corev1ac.ContainerPort().WithContainerPort(constants.MySQLAdminPort),
-			corev1ac.ContainerPort().WithName(constants.MySQLAdminPortName),
-			corev1ac.ContainerPort().WithContainerPort(constants.MySQLXPort),
-			corev1ac.ContainerPort().WithName(constants.MySQLXPortName),
-			corev1ac.ContainerPort().WithContainerPort(constants.MySQLHealthPort),
-			corev1ac.ContainerPort().WithName(constants.MySQLHealthPortName),
-		} {
-			r := makeMySQLCluster()
-			r.Spec.PodTemplate.Spec.Containers[0].WithPorts(port)
-			err := k8sClient.Create(ctx, r)
-			Expect(err).To(HaveOccurred())
-		}
+		r.Spec.SlowQueryLog.LongQueryTime = &metav1.Duration{}
+		r.Spec.SlowQueryLog.LogQueriesNotUsingIndexes = ptr.To(true)
+		r.Spec.DisableSlowQueryLog = true
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).To(ContainElement(ContainSubstring("spec.slowQueryLog")))
 	})
 
-	It("should deny reserved volume names", func() {
-		for _, volname := range []string{
-			constants.TmpVolumeName, constants.RunVolumeName, constants.VarLogVolumeName,
-			constants.MySQLConfVolumeName, constants.MySQLInitConfVolumeName,
-			constants.MySQLConfSecretVolumeName, constants.SlowQueryLogAgentConfigVolumeName,
+	It("should validate spec.redoLog", func() {
+		for _, redoLog := range []*mocov1beta2.RedoLogSpec{
+			{Capacity: ptr.To(resource.MustParse("4Mi"))},
+			{Capacity: ptr.To(resource.MustParse("200Gi"))},
+			{Capacity: ptr.To(resource.MustParse("128Mi")), FileSize: ptr.To(resource.MustParse("64Mi"))},
+			{FileSize: ptr.To(resource.MustParse("1Mi"))},
+			// exceeds half of the data volume of 1Gi
+			{Capacity: ptr.To(resource.MustParse("768Mi"))},
+			{FileSize: ptr.To(resource.MustParse("128Mi")), FilesInGroup: ptr.To[int32](8)},
+			{FilesInGroup: ptr.To[int32](1)},
 		} {
 			r := makeMySQLCluster()
-			spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-			spec.WithVolumes(corev1ac.Volume().WithName(volname))
-			r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+			r.Spec.RedoLog = redoLog
 			err := k8sClient.Create(ctx, r)
-			Expect(err).To(HaveOccurred())
+			Expect(err).To(HaveOccurred(), "%+v", redoLog)
 		}
-	})
 
-	It("should deny agent container", func() {
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithName("agent"))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.RedoLog = &mocov1beta2.RedoLogSpec{Capacity: ptr.To(resource.MustParse("256Mi"))}
 		err := k8sClient.Create(ctx, r)
-		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.RedoLog = &mocov1beta2.RedoLogSpec{FileSize: ptr.To(resource.MustParse("128Mi")), FilesInGroup: ptr.To[int32](3)}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny slow query log container if not disabled", func() {
+	It("should validate spec.maintenance", func() {
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithName(constants.SlowQueryLogAgentContainerName))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.Maintenance = &mocov1beta2.MaintenanceSpec{Schedule: "every day"}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
+
+		By("warning about a cluster without replica instances")
+		r.Spec.Maintenance.Schedule = "0 3 * * *"
+		warnings.take()
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).To(ContainElement(ContainSubstring("spec.maintenance has no effect")))
+
+		r.Spec.Replicas = 3
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).NotTo(ContainElement(ContainSubstring("spec.maintenance")))
+
+		r.Spec.Maintenance.Schedule = ""
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
 	})
 
-	It("should allow slow log container if disabled", func() {
+	It("should validate spec.maxConnections", func() {
+		for _, mc := range []*mocov1beta2.MaxConnectionsSpec{
+			{PerConnectionMemory: resource.MustParse("512Ki")},
+			{PerConnectionMemory: resource.MustParse("8Mi"), Min: ptr.To[int32](1000), Max: ptr.To[int32](500)},
+			{PerConnectionMemory: resource.MustParse("8Mi"), Max: ptr.To[int32](50)},
+			{PerConnectionMemory: resource.MustParse("8Mi"), Min: ptr.To[int32](0)},
+		} {
+			r := makeMySQLCluster()
+			r.Spec.MaxConnections = mc
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "%+v", mc)
+		}
+
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithName(constants.SlowQueryLogAgentContainerName))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
-		r.Spec.DisableSlowQueryLogContainer = true
+		r.Spec.MaxConnections = &mocov1beta2.MaxConnectionsSpec{PerConnectionMemory: resource.MustParse("8Mi")}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.MaxConnections.Min = ptr.To[int32](10)
+		r.Spec.MaxConnections.Max = ptr.To[int32](10)
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny mysqld_exporter container if enabled", func() {
+	It("should validate spec.gatewayRoute", func() {
 		r := makeMySQLCluster()
-		r.Spec.Collectors = []string{"engine_innodb_status", "info_schema.innodb_metrics"}
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithName(constants.ExporterContainerName))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.GatewayRoute = &mocov1beta2.GatewayRouteSpec{
+			ParentRefs: []mocov1beta2.GatewayParentReference{{Name: "gw"}},
+			Hostnames:  []string{"mysql.example.com"},
+		}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
+
+		r.Spec.GatewayRoute.Kind = mocov1beta2.GatewayRouteTLS
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.GatewayRoute.ParentRefs = nil
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
 	})
 
-	It("should allow mysqld_exporter container if not enabled", func() {
+	It("should validate spec.disableMyCnfSecretVolume", func() {
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithContainers(corev1ac.Container().WithName(constants.ExporterContainerName))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.DisableMyCnfSecretVolume = true
+		r.Spec.Collectors = []string{"engine_innodb_status"}
 		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.Collectors = nil
+		r.Spec.WarmUp = &mocov1beta2.WarmUpSpec{}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.WarmUp.Command = []string{"true"}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("allowing the exporter running as a Deployment")
+		r.Spec.Collectors = []string{"engine_innodb_status"}
+		r.Spec.ExporterMode = mocov1beta2.ExporterModeDeployment
+		err = k8sClient.Update(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should allow non-reserved init containers", func() {
+	It("should validate spec.slowQueryLogOutput", func() {
+		for _, out := range []*mocov1beta2.SlowQueryLogOutputSpec{
+			{Type: mocov1beta2.SlowQueryLogOutputLoki},
+			{Type: mocov1beta2.SlowQueryLogOutputElasticsearch, Port: ptr.To[int32](9200)},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Topic: "slow-log"},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Brokers: []string{"kafka:9092"}},
+			{Type: mocov1beta2.SlowQueryLogOutputStdout, CredentialsSecretName: ptr.To("foo")},
+			{Type: "fluentd", Host: "fluentd.example.com"},
+			{Type: mocov1beta2.SlowQueryLogOutputLoki, Host: "loki\n  Match *"},
+			{Type: mocov1beta2.SlowQueryLogOutputElasticsearch, Host: "es.example.com", Index: "slow log"},
+			{Type: mocov1beta2.SlowQueryLogOutputElasticsearch, Host: "es.example.com", Index: "slow\n[OUTPUT]"},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Brokers: []string{"kafka"}, Topic: "slow-log"},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Brokers: []string{"kafka:99999"}, Topic: "slow-log"},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Brokers: []string{"kafka\n:9092"}, Topic: "slow-log"},
+			{Type: mocov1beta2.SlowQueryLogOutputKafka, Brokers: []string{"kafka:9092"}, Topic: "slow log"},
+		} {
+			r := makeMySQLCluster()
+			r.Spec.SlowQueryLogOutput = out
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "%+v", out)
+		}
+
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithInitContainers(corev1ac.Container().WithName("foobar"))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.SlowQueryLogOutput = &mocov1beta2.SlowQueryLogOutputSpec{
+			Type:                  mocov1beta2.SlowQueryLogOutputKafka,
+			Brokers:               []string{"kafka:9092", "10.0.0.1:9092", "[fd00::1]:9092"},
+			Topic:                 "slow-log",
+			CredentialsSecretName: ptr.To("foo"),
+		}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny moco-init init containers", func() {
+	It("should deny selectors in the service templates", func() {
 		r := makeMySQLCluster()
-		spec := (corev1ac.PodSpecApplyConfiguration)(r.Spec.PodTemplate.Spec)
-		spec.WithInitContainers(corev1ac.Container().WithName("moco-init"))
-		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(spec)
+		r.Spec.PrimaryServiceTemplate = &mocov1beta2.ServiceTemplate{
+			Spec: &mocov1beta2.ServiceSpecApplyConfiguration{
+				Selector: map[string]string{"foo": "bar"},
+			},
+		}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
-	})
 
-	It("should deny decreasing replicas", func() {
-		r := makeMySQLCluster()
-		r.Spec.Replicas = 3
-		err := k8sClient.Create(ctx, r)
+		r = makeMySQLCluster()
+		r.Spec.ReplicaServiceTemplate = &mocov1beta2.ServiceTemplate{
+			Spec: &mocov1beta2.ServiceSpecApplyConfiguration{
+				Selector: map[string]string{"foo": "bar"},
+			},
+		}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r = makeMySQLCluster()
+		r.Spec.ReplicaServiceTemplate = &mocov1beta2.ServiceTemplate{
+			Spec: &mocov1beta2.ServiceSpecApplyConfiguration{},
+		}
+		err = k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
 
-		r.Spec.Replicas = 1
+		r.Spec.ReplicaServiceTemplate.Spec.Selector = map[string]string{"foo": "bar"}
 		err = k8sClient.Update(ctx, r)
 		Expect(err).To(HaveOccurred())
 	})
 
-	It("should deny negative values for replicas", func() {
+	It("should deny automountServiceAccountToken with agentOnlyServiceAccountToken", func() {
 		r := makeMySQLCluster()
-		r.Spec.Replicas = 4
+		r.Spec.AgentOnlyServiceAccountToken = true
+		r.Spec.PodTemplate.Spec.AutomountServiceAccountToken = ptr.To(true)
 		err := k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
 
-		r.Spec.Replicas = -1
+		r = makeMySQLCluster()
+		r.Spec.AgentOnlyServiceAccountToken = true
+		r.Spec.PodTemplate.Spec.AutomountServiceAccountToken = ptr.To(false)
 		err = k8sClient.Create(ctx, r)
-		Expect(err).To(HaveOccurred())
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should deny adding replication source secret", func() {
+	It("should deny changing spec.ordinals.start", func() {
 		r := makeMySQLCluster()
+		r.Spec.Ordinals = &mocov1beta2.OrdinalsSpec{Start: 3}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
 
-		r.Spec.ReplicationSourceSecretName = ptr.To[string]("fuga")
+		r.Spec.Ordinals = nil
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.Ordinals = &mocov1beta2.OrdinalsSpec{Start: 5}
 		err = k8sClient.Update(ctx, r)
 		Expect(err).To(HaveOccurred())
 	})
 
-	It("should deny changing replication source secret name", func() {
+	It("should allow setting persistentVolumeClaimRetentionPolicy only at creation", func() {
 		r := makeMySQLCluster()
-		r.Spec.ReplicationSourceSecretName = ptr.To[string]("foo")
 		err := k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
 
-		r.Spec.ReplicationSourceSecretName = ptr.To[string]("bar")
+		r.Spec.PersistentVolumeClaimRetentionPolicy = &mocov1beta2.PersistentVolumeClaimRetentionPolicy{}
+		err = k8sClient.Update(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		err = deleteMySQLCluster()
+		Expect(err).NotTo(HaveOccurred())
+
+		r = makeMySQLCluster()
+		r.Spec.PersistentVolumeClaimRetentionPolicy = &mocov1beta2.PersistentVolumeClaimRetentionPolicy{}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(r.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted).To(Equal(mocov1beta2.PersistentVolumeClaimDelete))
+		Expect(r.Spec.PersistentVolumeClaimRetentionPolicy.WhenScaled).To(Equal(mocov1beta2.PersistentVolumeClaimRetain))
+
+		r.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted = mocov1beta2.PersistentVolumeClaimRetain
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.PersistentVolumeClaimRetentionPolicy = nil
 		err = k8sClient.Update(ctx, r)
 		Expect(err).To(HaveOccurred())
 	})
@@ -410,6 +1171,24 @@ var _ = Describe("MySQLCluster Webhook", func() {
 		}
 		err = k8sClient.Create(ctx, r)
 		Expect(err).To(HaveOccurred())
+
+		for _, prefix := range []string{"/moco/test/single", "moco//test", "moco/test single"} {
+			r = makeMySQLCluster()
+			r.Spec.Restore = &mocov1beta2.RestoreSpec{
+				SourceName:      "test",
+				SourceNamespace: "test",
+				RestorePoint:    metav1.Now(),
+				JobConfig: mocov1beta2.JobConfig{
+					ServiceAccountName: "foo",
+					BucketConfig: mocov1beta2.BucketConfig{
+						BucketName: "mybucket",
+					},
+				},
+				Prefix: prefix,
+			}
+			err = k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), prefix)
+		}
 	})
 
 	It("should allow valid restore spec", func() {
@@ -425,6 +1204,50 @@ var _ = Describe("MySQLCluster Webhook", func() {
 					EndpointURL: "https://foo.bar.svc:9000",
 				},
 			},
+			Prefix: "migrated/moco/test/test/",
+		}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should validate the custom restore container", func() {
+		for _, jc := range []mocov1beta2.JobConfig{
+			{Image: "custom-restore:1"},
+			{Args: []string{"--foo"}},
+			{Command: []string{"/restore.sh"}},
+		} {
+			r := makeMySQLCluster()
+			r.Spec.Restore = &mocov1beta2.RestoreSpec{
+				SourceName:      "test",
+				SourceNamespace: "test",
+				RestorePoint:    metav1.Now(),
+				JobConfig: mocov1beta2.JobConfig{
+					ServiceAccountName: "foo",
+					BucketConfig: mocov1beta2.BucketConfig{
+						BucketName: "mybucket",
+					},
+					Image:   jc.Image,
+					Command: jc.Command,
+					Args:    jc.Args,
+				},
+			}
+			err := k8sClient.Create(ctx, r)
+			Expect(err).To(HaveOccurred(), "image=%s, command=%v, args=%v", jc.Image, jc.Command, jc.Args)
+		}
+
+		r := makeMySQLCluster()
+		r.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:      "test",
+			SourceNamespace: "test",
+			RestorePoint:    metav1.Now(),
+			JobConfig: mocov1beta2.JobConfig{
+				ServiceAccountName: "foo",
+				BucketConfig: mocov1beta2.BucketConfig{
+					BucketName: "mybucket",
+				},
+				Image: "custom-restore:1",
+				Args:  []string{"--foo"},
+			},
 		}
 		err := k8sClient.Create(ctx, r)
 		Expect(err).NotTo(HaveOccurred())
@@ -544,4 +1367,118 @@ var _ = Describe("MySQLCluster Webhook", func() {
 		err = k8sClient.Update(ctx, r)
 		Expect(err).To(HaveOccurred())
 	})
+
+	It("should validate instanceResources", func() {
+		resources := (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+			WithRequests(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}))
+
+		r := makeMySQLCluster()
+		r.Spec.Replicas = 3
+		r.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{{Index: 3, Resources: resources}}
+		err := k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("spec.instanceResources[0].index"))
+
+		r.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{{Index: 1, Resources: resources}, {Index: 1, Resources: resources}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).To(HaveOccurred())
+
+		r.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{{Index: 2, Resources: resources}}
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		r.Spec.InstanceResources[0].Index = 1
+		err = k8sClient.Update(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("warning the override of the primary of a single-instance cluster")
+		err = deleteMySQLCluster()
+		Expect(err).NotTo(HaveOccurred())
+		r = makeMySQLCluster()
+		r.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{{Index: 0, Resources: resources}}
+		warnings.take()
+		err = k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(warnings.take()).To(ContainElement(ContainSubstring("spec.instanceResources")))
+	})
+})
+
+var _ = Describe("MysqldContainerDefaults", func() {
+	ctx := context.TODO()
+
+	defaults := &mocov1beta2.MysqldContainerDefaults{
+		Image: "ghcr.io/cybozu-go/moco/mysql:8.4.3",
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: resource.MustParse("1Gi"),
+		},
+	}
+
+	BeforeEach(func() {
+		err := createStorageClass()
+		Expect(err).NotTo(HaveOccurred())
+		err = deleteMySQLCluster()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fill in mysqld container of a minimal cluster", func() {
+		r := makeMySQLCluster()
+		r.Spec.PodTemplate = mocov1beta2.PodTemplateSpec{}
+		defaults.Apply(r)
+
+		Expect(r.Spec.PodTemplate.Spec.Containers).To(HaveLen(1))
+		c := r.Spec.PodTemplate.Spec.Containers[0]
+		Expect(c.Name).To(Equal(ptr.To(constants.MysqldContainerName)))
+		Expect(c.Image).To(Equal(ptr.To(defaults.Image)))
+		Expect(c.Resources).NotTo(BeNil())
+		Expect(c.Resources.Requests).To(Equal(&defaults.Requests))
+		Expect(c.Resources.Limits).To(BeNil())
+
+		// the requests are copied.
+		(*c.Resources.Requests)[corev1.ResourceCPU] = resource.MustParse("2")
+		Expect(defaults.Requests.Cpu().String()).To(Equal("1"))
+
+		err := k8sClient.Create(ctx, r)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep the explicit fields of the Pod template", func() {
+		r := makeMySQLCluster()
+		r.Spec.PodTemplate.Labels = map[string]string{"foo": "bar"}
+		r.Spec.PodTemplate.Spec = (mocov1beta2.PodSpecApplyConfiguration)(*corev1ac.PodSpec().
+			WithPriorityClassName("high").
+			WithContainers(corev1ac.Container().WithName("sidecar").WithImage("sidecar:dev")))
+		defaults.Apply(r)
+
+		Expect(r.Spec.PodTemplate.Labels).To(Equal(map[string]string{"foo": "bar"}))
+		Expect(r.Spec.PodTemplate.Spec.PriorityClassName).To(Equal(ptr.To("high")))
+		Expect(r.Spec.PodTemplate.Spec.Containers).To(HaveLen(2))
+		Expect(r.Spec.PodTemplate.Spec.Containers[0].Name).To(Equal(ptr.To("sidecar")))
+		Expect(r.Spec.PodTemplate.Spec.Containers[0].Image).To(Equal(ptr.To("sidecar:dev")))
+		Expect(r.Spec.PodTemplate.Spec.Containers[1].Name).To(Equal(ptr.To(constants.MysqldContainerName)))
+		Expect(r.Spec.PodTemplate.Spec.Containers[1].Image).To(Equal(ptr.To(defaults.Image)))
+
+		By("keeping mysqld container given by the user")
+		r = makeMySQLCluster()
+		r.Spec.PodTemplate.Spec.Containers[0].
+			WithImage("mysql:custom").
+			WithResources(corev1ac.ResourceRequirements().
+				WithLimits(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}))
+		defaults.Apply(r)
+
+		Expect(r.Spec.PodTemplate.Spec.Containers).To(HaveLen(1))
+		c := r.Spec.PodTemplate.Spec.Containers[0]
+		Expect(c.Image).To(Equal(ptr.To("mysql:custom")))
+		Expect(c.Resources.Requests).To(BeNil())
+		Expect(c.Resources.Limits).To(Equal(&corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")}))
+
+		By("filling in only the image of mysqld container without it")
+		r = makeMySQLCluster()
+		defaults.Apply(r)
+
+		Expect(r.Spec.PodTemplate.Spec.Containers).To(HaveLen(1))
+		c = r.Spec.PodTemplate.Spec.Containers[0]
+		Expect(c.Image).To(Equal(ptr.To(defaults.Image)))
+		Expect(c.Resources).To(BeNil())
+	})
 })
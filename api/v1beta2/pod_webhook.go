@@ -0,0 +1,116 @@
+package v1beta2
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/cybozu-go/moco/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// SetupPodWebhookWithManager registers the webhook for the Pods of MySQLClusters.
+// The webhook sets the resources of the mysqld container given in `spec.instanceResources`.
+func SetupPodWebhookWithManager(mgr ctrl.Manager) error {
+	mgr.GetWebhookServer().Register("/mutate-v1-pod", &webhook.Admission{
+		Handler: &podMutator{
+			client:  mgr.GetAPIReader(),
+			decoder: admission.NewDecoder(mgr.GetScheme()),
+		},
+	})
+	return nil
+}
+
+//+kubebuilder:webhook:path=/mutate-v1-pod,mutating=true,failurePolicy=ignore,sideEffects=None,matchPolicy=Equivalent,groups="",resources=pods,verbs=create,versions=v1,name=mpod.kb.io,admissionReviewVersions=v1
+
+type podMutator struct {
+	client  client.Reader
+	decoder *admission.Decoder
+}
+
+var _ admission.Handler = &podMutator{}
+
+// Handle sets the resources of the mysqld container.  The Pod is created as is if the
+// webhook fails internally, because rejecting it would block the StatefulSet of the cluster.
+func (m *podMutator) Handle(ctx context.Context, req admission.Request) admission.Response {
+	log := crlog.FromContext(ctx)
+
+	pod := &corev1.Pod{}
+	if err := m.decoder.Decode(req, pod); err != nil {
+		return admission.Errored(http.StatusBadRequest, err)
+	}
+
+	if pod.Labels[constants.LabelAppName] != constants.AppNameMySQL || pod.Labels[constants.LabelAppCreatedBy] != constants.AppCreator {
+		return admission.Allowed("")
+	}
+
+	cluster := &MySQLCluster{}
+	err := m.client.Get(ctx, client.ObjectKey{Namespace: req.Namespace, Name: pod.Labels[constants.LabelAppInstance]}, cluster)
+	if apierrors.IsNotFound(err) {
+		return admission.Allowed("")
+	}
+	if err != nil {
+		log.Error(err, "failed to get MySQLCluster; the resources of mysqld are not overridden", "pod", pod.Name)
+		return admission.Allowed("")
+	}
+
+	if ref := metav1.GetControllerOf(pod); ref == nil || ref.Kind != "StatefulSet" || ref.Name != cluster.PrefixedName() {
+		return admission.Allowed("")
+	}
+
+	index, err := cluster.PodIndex(pod.Name)
+	if err != nil {
+		log.Error(err, "failed to get the index of Pod; the resources of mysqld are not overridden", "pod", pod.Name)
+		return admission.Allowed("")
+	}
+	resources := cluster.Spec.InstanceResourcesOf(index)
+	if resources == nil {
+		return admission.Allowed("")
+	}
+
+	ok, err := setMysqldResources(pod, resources)
+	if err != nil {
+		log.Error(err, "failed to set the resources of mysqld; they are not overridden", "pod", pod.Name)
+		return admission.Allowed("")
+	}
+	if !ok {
+		return admission.Allowed("")
+	}
+
+	marshaled, err := json.Marshal(pod)
+	if err != nil {
+		log.Error(err, "failed to marshal Pod; the resources of mysqld are not overridden", "pod", pod.Name)
+		return admission.Allowed("")
+	}
+	return admission.PatchResponseFromRaw(req.Object.Raw, marshaled)
+}
+
+// setMysqldResources replaces the resources of the mysqld container of pod.
+// It returns false if pod does not have the mysqld container.
+func setMysqldResources(pod *corev1.Pod, resources *ResourceRequirementsApplyConfiguration) (bool, error) {
+	data, err := json.Marshal(resources)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal the resources: %w", err)
+	}
+	var rr corev1.ResourceRequirements
+	if err := json.Unmarshal(data, &rr); err != nil {
+		return false, fmt.Errorf("failed to unmarshal the resources: %w", err)
+	}
+
+	for i, c := range pod.Spec.Containers {
+		if c.Name != constants.MysqldContainerName {
+			continue
+		}
+		pod.Spec.Containers[i].Resources = rr
+		return true, nil
+	}
+	return false, nil
+}
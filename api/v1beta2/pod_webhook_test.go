@@ -0,0 +1,147 @@
+package v1beta2_test
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func makeMySQLPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels: map[string]string{
+				constants.LabelAppName:      constants.AppNameMySQL,
+				constants.LabelAppInstance:  "test",
+				constants.LabelAppCreatedBy: constants.AppCreator,
+			},
+			OwnerReferences: []metav1.OwnerReference{
+				{
+					APIVersion: "apps/v1",
+					Kind:       "StatefulSet",
+					Name:       "moco-test",
+					UID:        "c7b5f1b4-3a8e-4d2c-9a6e-5f0c2f2d8e11",
+					Controller: ptr.To(true),
+				},
+			},
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:  constants.MysqldContainerName,
+					Image: "mysql",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+					},
+				},
+				{
+					Name:  constants.AgentContainerName,
+					Image: "agent",
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("100Mi")},
+					},
+				},
+			},
+		},
+	}
+}
+
+var _ = Describe("Pod Webhook", func() {
+	ctx := context.TODO()
+
+	BeforeEach(func() {
+		err := createStorageClass()
+		Expect(err).NotTo(HaveOccurred())
+		err = deleteMySQLCluster()
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("default"), client.GracePeriodSeconds(0))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should override the resources of mysqld only for the designated instance", func() {
+		cluster := makeMySQLCluster()
+		cluster.Spec.Replicas = 3
+		cluster.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{
+			{
+				Index: 1,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithRequests(corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("4"),
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					}).
+					WithLimits(corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("8Gi"),
+					})),
+			},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"moco-test-0", "moco-test-1", "moco-test-2"} {
+			err = k8sClient.Create(ctx, makeMySQLPod(name))
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		pod := &corev1.Pod{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "moco-test-1"}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		mysqld := pod.Spec.Containers[0]
+		Expect(mysqld.Resources.Requests).To(HaveLen(2))
+		Expect(mysqld.Resources.Requests.Cpu().Equal(resource.MustParse("4"))).To(BeTrue())
+		Expect(mysqld.Resources.Requests.Memory().Equal(resource.MustParse("8Gi"))).To(BeTrue())
+		Expect(mysqld.Resources.Limits).To(HaveLen(1))
+		Expect(mysqld.Resources.Limits.Memory().Equal(resource.MustParse("8Gi"))).To(BeTrue())
+		agent := pod.Spec.Containers[1]
+		Expect(agent.Resources.Requests.Memory().Equal(resource.MustParse("100Mi"))).To(BeTrue())
+
+		for _, name := range []string{"moco-test-0", "moco-test-2"} {
+			pod := &corev1.Pod{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: name}, pod)
+			Expect(err).NotTo(HaveOccurred())
+			mysqld := pod.Spec.Containers[0]
+			Expect(mysqld.Resources.Requests).To(HaveLen(1))
+			Expect(mysqld.Resources.Requests.Memory().Equal(resource.MustParse("1Gi"))).To(BeTrue())
+			Expect(mysqld.Resources.Limits).To(BeEmpty())
+		}
+	})
+
+	It("should not modify Pods not owned by the StatefulSet of the cluster", func() {
+		cluster := makeMySQLCluster()
+		cluster.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{
+			{
+				Index: 0,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithRequests(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")})),
+			},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := makeMySQLPod("moco-test-0")
+		pod.OwnerReferences = nil
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Resources.Requests.Memory().Equal(resource.MustParse("1Gi"))).To(BeTrue())
+	})
+
+	It("should not modify Pods of clusters without instanceResources", func() {
+		cluster := makeMySQLCluster()
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := makeMySQLPod("moco-test-0")
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(pod.Spec.Containers[0].Resources.Requests.Memory().Equal(resource.MustParse("1Gi"))).To(BeTrue())
+	})
+})
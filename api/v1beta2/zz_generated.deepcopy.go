@@ -15,6 +15,44 @@ func (in *AffinityApplyConfiguration) DeepCopyInto(out *AffinityApplyConfigurati
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *AgentProbeSpec) DeepCopyInto(out *AgentProbeSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AgentProbeSpec.
+func (in *AgentProbeSpec) DeepCopy() *AgentProbeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(AgentProbeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BackupAttemptStatus) DeepCopyInto(out *BackupAttemptStatus) {
+	*out = *in
+	if in.StartTime != nil {
+		in, out := &in.StartTime, &out.StartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CompletionTime != nil {
+		in, out := &in.CompletionTime, &out.CompletionTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BackupAttemptStatus.
+func (in *BackupAttemptStatus) DeepCopy() *BackupAttemptStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(BackupAttemptStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *BackupPolicy) DeepCopyInto(out *BackupPolicy) {
 	*out = *in
@@ -158,6 +196,37 @@ func (in *BucketConfig) DeepCopy() *BucketConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CABundleSource) DeepCopyInto(out *CABundleSource) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CABundleSource.
+func (in *CABundleSource) DeepCopy() *CABundleSource {
+	if in == nil {
+		return nil
+	}
+	out := new(CABundleSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloneFailureStatus) DeepCopyInto(out *CloneFailureStatus) {
+	*out = *in
+	in.LastFailureTime.DeepCopyInto(&out.LastFailureTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloneFailureStatus.
+func (in *CloneFailureStatus) DeepCopy() *CloneFailureStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CloneFailureStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EnvFromSourceApplyConfiguration) DeepCopyInto(out *EnvFromSourceApplyConfiguration) {
 	clone := in.DeepCopy()
@@ -170,6 +239,65 @@ func (in *EnvVarApplyConfiguration) DeepCopyInto(out *EnvVarApplyConfiguration)
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayParentReference) DeepCopyInto(out *GatewayParentReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayParentReference.
+func (in *GatewayParentReference) DeepCopy() *GatewayParentReference {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayParentReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GatewayRouteSpec) DeepCopyInto(out *GatewayRouteSpec) {
+	*out = *in
+	if in.ParentRefs != nil {
+		in, out := &in.ParentRefs, &out.ParentRefs
+		*out = make([]GatewayParentReference, len(*in))
+		copy(*out, *in)
+	}
+	if in.Hostnames != nil {
+		in, out := &in.Hostnames, &out.Hostnames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GatewayRouteSpec.
+func (in *GatewayRouteSpec) DeepCopy() *GatewayRouteSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(GatewayRouteSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InstanceResourcesSpec) DeepCopyInto(out *InstanceResourcesSpec) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InstanceResourcesSpec.
+func (in *InstanceResourcesSpec) DeepCopy() *InstanceResourcesSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(InstanceResourcesSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *JobConfig) DeepCopyInto(out *JobConfig) {
 	*out = *in
@@ -213,6 +341,48 @@ func (in *JobConfig) DeepCopyInto(out *JobConfig) {
 		in, out := &in.Affinity, &out.Affinity
 		*out = (*in).DeepCopy()
 	}
+	if in.RuntimeClassName != nil {
+		in, out := &in.RuntimeClassName, &out.RuntimeClassName
+		*out = new(string)
+		**out = **in
+	}
+	if in.TerminationGracePeriodSeconds != nil {
+		in, out := &in.TerminationGracePeriodSeconds, &out.TerminationGracePeriodSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.PodFailurePolicy != nil {
+		in, out := &in.PodFailurePolicy, &out.PodFailurePolicy
+		*out = make([]PodFailurePolicyRule, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.IncludeDatabases != nil {
+		in, out := &in.IncludeDatabases, &out.IncludeDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeDatabases != nil {
+		in, out := &in.ExcludeDatabases, &out.ExcludeDatabases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PartSize != nil {
+		in, out := &in.PartSize, &out.PartSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.Volumes != nil {
 		in, out := &in.Volumes, &out.Volumes
 		*out = make([]VolumeApplyConfiguration, len(*in))
@@ -227,6 +397,16 @@ func (in *JobConfig) DeepCopyInto(out *JobConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CABundle != nil {
+		in, out := &in.CABundle, &out.CABundle
+		*out = new(CABundleSource)
+		**out = **in
+	}
+	if in.RoleRef != nil {
+		in, out := &in.RoleRef, &out.RoleRef
+		*out = new(JobRoleRef)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobConfig.
@@ -239,6 +419,67 @@ func (in *JobConfig) DeepCopy() *JobConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobRoleRef) DeepCopyInto(out *JobRoleRef) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobRoleRef.
+func (in *JobRoleRef) DeepCopy() *JobRoleRef {
+	if in == nil {
+		return nil
+	}
+	out := new(JobRoleRef)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceSpec) DeepCopyInto(out *MaintenanceSpec) {
+	*out = *in
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceSpec.
+func (in *MaintenanceSpec) DeepCopy() *MaintenanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaxConnectionsSpec) DeepCopyInto(out *MaxConnectionsSpec) {
+	*out = *in
+	out.PerConnectionMemory = in.PerConnectionMemory.DeepCopy()
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaxConnectionsSpec.
+func (in *MaxConnectionsSpec) DeepCopy() *MaxConnectionsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(MaxConnectionsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MySQLCluster) DeepCopyInto(out *MySQLCluster) {
 	*out = *in
@@ -301,6 +542,11 @@ func (in *MySQLClusterList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MySQLClusterSpec) DeepCopyInto(out *MySQLClusterSpec) {
 	*out = *in
+	if in.Ordinals != nil {
+		in, out := &in.Ordinals, &out.Ordinals
+		*out = new(OrdinalsSpec)
+		**out = **in
+	}
 	in.PodTemplate.DeepCopyInto(&out.PodTemplate)
 	if in.VolumeClaimTemplates != nil {
 		in, out := &in.VolumeClaimTemplates, &out.VolumeClaimTemplates
@@ -309,6 +555,11 @@ func (in *MySQLClusterSpec) DeepCopyInto(out *MySQLClusterSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.PersistentVolumeClaimRetentionPolicy != nil {
+		in, out := &in.PersistentVolumeClaimRetentionPolicy, &out.PersistentVolumeClaimRetentionPolicy
+		*out = new(PersistentVolumeClaimRetentionPolicy)
+		**out = **in
+	}
 	if in.PrimaryServiceTemplate != nil {
 		in, out := &in.PrimaryServiceTemplate, &out.PrimaryServiceTemplate
 		*out = new(ServiceTemplate)
@@ -319,16 +570,53 @@ func (in *MySQLClusterSpec) DeepCopyInto(out *MySQLClusterSpec) {
 		*out = new(ServiceTemplate)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.GatewayRoute != nil {
+		in, out := &in.GatewayRoute, &out.GatewayRoute
+		*out = new(GatewayRouteSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HeadlessServicePorts != nil {
+		in, out := &in.HeadlessServicePorts, &out.HeadlessServicePorts
+		*out = make([]ServicePortApplyConfiguration, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.MySQLConfigMapName != nil {
 		in, out := &in.MySQLConfigMapName, &out.MySQLConfigMapName
 		*out = new(string)
 		**out = **in
 	}
+	if in.RedoLog != nil {
+		in, out := &in.RedoLog, &out.RedoLog
+		*out = new(RedoLogSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaxConnections != nil {
+		in, out := &in.MaxConnections, &out.MaxConnections
+		*out = new(MaxConnectionsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MysqldCommand != nil {
+		in, out := &in.MysqldCommand, &out.MysqldCommand
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.ReplicationSourceSecretName != nil {
 		in, out := &in.ReplicationSourceSecretName, &out.ReplicationSourceSecretName
 		*out = new(string)
 		**out = **in
 	}
+	if in.ReplicationSource != nil {
+		in, out := &in.ReplicationSource, &out.ReplicationSource
+		*out = new(ReplicationSourceSpec)
+		**out = **in
+	}
+	if in.AdoptSourceSecretName != nil {
+		in, out := &in.AdoptSourceSecretName, &out.AdoptSourceSecretName
+		*out = new(string)
+		**out = **in
+	}
 	if in.Collectors != nil {
 		in, out := &in.Collectors, &out.Collectors
 		*out = make([]string, len(*in))
@@ -339,16 +627,105 @@ func (in *MySQLClusterSpec) DeepCopyInto(out *MySQLClusterSpec) {
 		*out = new(int)
 		**out = **in
 	}
+	if in.MinSyncedReplicas != nil {
+		in, out := &in.MinSyncedReplicas, &out.MinSyncedReplicas
+		*out = new(int32)
+		**out = **in
+	}
+	if in.SemiSync != nil {
+		in, out := &in.SemiSync, &out.SemiSync
+		*out = new(SemiSyncSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.CloneRetryBackoff != nil {
+		in, out := &in.CloneRetryBackoff, &out.CloneRetryBackoff
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.RevisionHistoryLimit != nil {
+		in, out := &in.RevisionHistoryLimit, &out.RevisionHistoryLimit
+		*out = new(int32)
+		**out = **in
+	}
 	if in.BackupPolicyName != nil {
 		in, out := &in.BackupPolicyName, &out.BackupPolicyName
 		*out = new(string)
 		**out = **in
 	}
+	if in.Maintenance != nil {
+		in, out := &in.Maintenance, &out.Maintenance
+		*out = new(MaintenanceSpec)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Restore != nil {
 		in, out := &in.Restore, &out.Restore
 		*out = new(RestoreSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SlowQueryLog != nil {
+		in, out := &in.SlowQueryLog, &out.SlowQueryLog
+		*out = new(SlowQueryLogSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SlowQueryLogOutput != nil {
+		in, out := &in.SlowQueryLogOutput, &out.SlowQueryLogOutput
+		*out = new(SlowQueryLogOutputSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.SlowQueryLogAgentPreStopSeconds != nil {
+		in, out := &in.SlowQueryLogAgentPreStopSeconds, &out.SlowQueryLogAgentPreStopSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PrimaryDrainTimeoutSeconds != nil {
+		in, out := &in.PrimaryDrainTimeoutSeconds, &out.PrimaryDrainTimeoutSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ReplicaDrainSeconds != nil {
+		in, out := &in.ReplicaDrainSeconds, &out.ReplicaDrainSeconds
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MysqldReadinessProbe != nil {
+		in, out := &in.MysqldReadinessProbe, &out.MysqldReadinessProbe
+		*out = new(ProbeThresholdsSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InstanceResources != nil {
+		in, out := &in.InstanceResources, &out.InstanceResources
+		*out = make([]InstanceResourcesSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.AgentMemoryPercent != nil {
+		in, out := &in.AgentMemoryPercent, &out.AgentMemoryPercent
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AgentProbe != nil {
+		in, out := &in.AgentProbe, &out.AgentProbe
+		*out = new(AgentProbeSpec)
+		**out = **in
+	}
+	if in.WarmUp != nil {
+		in, out := &in.WarmUp, &out.WarmUp
+		*out = new(WarmUpSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]UserSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MySQLClusterSpec.
@@ -376,11 +753,67 @@ func (in *MySQLClusterStatus) DeepCopyInto(out *MySQLClusterStatus) {
 		*out = make([]int, len(*in))
 		copy(*out, *in)
 	}
+	if in.IneligibleReplicaList != nil {
+		in, out := &in.IneligibleReplicaList, &out.IneligibleReplicaList
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
 	in.Backup.DeepCopyInto(&out.Backup)
+	if in.BackupAttempts != nil {
+		in, out := &in.BackupAttempts, &out.BackupAttempts
+		*out = make([]BackupAttemptStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.RestoredTime != nil {
 		in, out := &in.RestoredTime, &out.RestoredTime
 		*out = (*in).DeepCopy()
 	}
+	if in.ExpiryTime != nil {
+		in, out := &in.ExpiryTime, &out.ExpiryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.AdoptedTime != nil {
+		in, out := &in.AdoptedTime, &out.AdoptedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.CloneFailures != nil {
+		in, out := &in.CloneFailures, &out.CloneFailures
+		*out = make([]CloneFailureStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.ReplicationSource != nil {
+		in, out := &in.ReplicationSource, &out.ReplicationSource
+		*out = new(ReplicationSourceStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Rollout != nil {
+		in, out := &in.Rollout, &out.Rollout
+		*out = new(RolloutStatus)
+		**out = **in
+	}
+	if in.CertificateExpiry != nil {
+		in, out := &in.CertificateExpiry, &out.CertificateExpiry
+		*out = (*in).DeepCopy()
+	}
+	if in.Users != nil {
+		in, out := &in.Users, &out.Users
+		*out = make([]UserStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Databases != nil {
+		in, out := &in.Databases, &out.Databases
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.PasswordRotation != nil {
+		in, out := &in.PasswordRotation, &out.PasswordRotation
+		*out = new(PasswordRotationStatus)
+		(*in).DeepCopyInto(*out)
+	}
 	out.ReconcileInfo = in.ReconcileInfo
 }
 
@@ -423,6 +856,21 @@ func (in *ObjectMeta) DeepCopy() *ObjectMeta {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OrdinalsSpec) DeepCopyInto(out *OrdinalsSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrdinalsSpec.
+func (in *OrdinalsSpec) DeepCopy() *OrdinalsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OrdinalsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OverwriteContainer) DeepCopyInto(out *OverwriteContainer) {
 	*out = *in
@@ -430,6 +878,10 @@ func (in *OverwriteContainer) DeepCopyInto(out *OverwriteContainer) {
 		in, out := &in.Resources, &out.Resources
 		*out = (*in).DeepCopy()
 	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OverwriteContainer.
@@ -442,6 +894,25 @@ func (in *OverwriteContainer) DeepCopy() *OverwriteContainer {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PasswordRotationStatus) DeepCopyInto(out *PasswordRotationStatus) {
+	*out = *in
+	if in.LastRotationTime != nil {
+		in, out := &in.LastRotationTime, &out.LastRotationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PasswordRotationStatus.
+func (in *PasswordRotationStatus) DeepCopy() *PasswordRotationStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(PasswordRotationStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaim) DeepCopyInto(out *PersistentVolumeClaim) {
 	*out = *in
@@ -459,12 +930,47 @@ func (in *PersistentVolumeClaim) DeepCopy() *PersistentVolumeClaim {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopyInto(out *PersistentVolumeClaimRetentionPolicy) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PersistentVolumeClaimRetentionPolicy.
+func (in *PersistentVolumeClaimRetentionPolicy) DeepCopy() *PersistentVolumeClaimRetentionPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(PersistentVolumeClaimRetentionPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PersistentVolumeClaimSpecApplyConfiguration) DeepCopyInto(out *PersistentVolumeClaimSpecApplyConfiguration) {
 	clone := in.DeepCopy()
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PodFailurePolicyRule) DeepCopyInto(out *PodFailurePolicyRule) {
+	*out = *in
+	if in.ExitCodes != nil {
+		in, out := &in.ExitCodes, &out.ExitCodes
+		*out = make([]int32, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PodFailurePolicyRule.
+func (in *PodFailurePolicyRule) DeepCopy() *PodFailurePolicyRule {
+	if in == nil {
+		return nil
+	}
+	out := new(PodFailurePolicyRule)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *PodSpecApplyConfiguration) DeepCopyInto(out *PodSpecApplyConfiguration) {
 	clone := in.DeepCopy()
@@ -495,6 +1001,46 @@ func (in *PodTemplateSpec) DeepCopy() *PodTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *PointInTimeCloneSpec) DeepCopyInto(out *PointInTimeCloneSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PointInTimeCloneSpec.
+func (in *PointInTimeCloneSpec) DeepCopy() *PointInTimeCloneSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(PointInTimeCloneSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeThresholdsSpec) DeepCopyInto(out *ProbeThresholdsSpec) {
+	*out = *in
+	if in.SuccessThreshold != nil {
+		in, out := &in.SuccessThreshold, &out.SuccessThreshold
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FailureThreshold != nil {
+		in, out := &in.FailureThreshold, &out.FailureThreshold
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeThresholdsSpec.
+func (in *ProbeThresholdsSpec) DeepCopy() *ProbeThresholdsSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeThresholdsSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ReconcileInfo) DeepCopyInto(out *ReconcileInfo) {
 	*out = *in
@@ -510,17 +1056,97 @@ func (in *ReconcileInfo) DeepCopy() *ReconcileInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RedoLogSpec) DeepCopyInto(out *RedoLogSpec) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FileSize != nil {
+		in, out := &in.FileSize, &out.FileSize
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.FilesInGroup != nil {
+		in, out := &in.FilesInGroup, &out.FilesInGroup
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RedoLogSpec.
+func (in *RedoLogSpec) DeepCopy() *RedoLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(RedoLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResourceRequirementsApplyConfiguration) DeepCopyInto(out *ResourceRequirementsApplyConfiguration) {
 	clone := in.DeepCopy()
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceSpec) DeepCopyInto(out *ReplicationSourceSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceSpec.
+func (in *ReplicationSourceSpec) DeepCopy() *ReplicationSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ReplicationSourceStatus) DeepCopyInto(out *ReplicationSourceStatus) {
+	*out = *in
+	if in.SecondsBehindSource != nil {
+		in, out := &in.SecondsBehindSource, &out.SecondsBehindSource
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ReplicationSourceStatus.
+func (in *ReplicationSourceStatus) DeepCopy() *ReplicationSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ReplicationSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RestoreSpec) DeepCopyInto(out *RestoreSpec) {
 	*out = *in
 	in.RestorePoint.DeepCopyInto(&out.RestorePoint)
 	in.JobConfig.DeepCopyInto(&out.JobConfig)
+	if in.ActiveDeadlineSeconds != nil {
+		in, out := &in.ActiveDeadlineSeconds, &out.ActiveDeadlineSeconds
+		*out = new(int64)
+		**out = **in
+	}
+	if in.TTLSecondsAfterRestored != nil {
+		in, out := &in.TTLSecondsAfterRestored, &out.TTLSecondsAfterRestored
+		*out = new(int32)
+		**out = **in
+	}
+	if in.PointInTimeClone != nil {
+		in, out := &in.PointInTimeClone, &out.PointInTimeClone
+		*out = new(PointInTimeCloneSpec)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RestoreSpec.
@@ -533,12 +1159,64 @@ func (in *RestoreSpec) DeepCopy() *RestoreSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecurityContextApplyConfiguration) DeepCopyInto(out *SecurityContextApplyConfiguration) {
+	clone := in.DeepCopy()
+	*out = *clone
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SemiSyncSpec) DeepCopyInto(out *SemiSyncSpec) {
+	*out = *in
+	if in.WaitForReplicaCount != nil {
+		in, out := &in.WaitForReplicaCount, &out.WaitForReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SemiSyncSpec.
+func (in *SemiSyncSpec) DeepCopy() *SemiSyncSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SemiSyncSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ServicePortApplyConfiguration) DeepCopyInto(out *ServicePortApplyConfiguration) {
+	clone := in.DeepCopy()
+	*out = *clone
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceSpecApplyConfiguration) DeepCopyInto(out *ServiceSpecApplyConfiguration) {
 	clone := in.DeepCopy()
 	*out = *clone
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RolloutStatus) DeepCopyInto(out *RolloutStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RolloutStatus.
+func (in *RolloutStatus) DeepCopy() *RolloutStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(RolloutStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServiceTemplate) DeepCopyInto(out *ServiceTemplate) {
 	*out = *in
@@ -559,6 +1237,96 @@ func (in *ServiceTemplate) DeepCopy() *ServiceTemplate {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowQueryLogOutputSpec) DeepCopyInto(out *SlowQueryLogOutputSpec) {
+	*out = *in
+	if in.Port != nil {
+		in, out := &in.Port, &out.Port
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Brokers != nil {
+		in, out := &in.Brokers, &out.Brokers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CredentialsSecretName != nil {
+		in, out := &in.CredentialsSecretName, &out.CredentialsSecretName
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowQueryLogOutputSpec.
+func (in *SlowQueryLogOutputSpec) DeepCopy() *SlowQueryLogOutputSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowQueryLogOutputSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SlowQueryLogSpec) DeepCopyInto(out *SlowQueryLogSpec) {
+	*out = *in
+	if in.LongQueryTime != nil {
+		in, out := &in.LongQueryTime, &out.LongQueryTime
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.LogQueriesNotUsingIndexes != nil {
+		in, out := &in.LogQueriesNotUsingIndexes, &out.LogQueriesNotUsingIndexes
+		*out = new(bool)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SlowQueryLogSpec.
+func (in *SlowQueryLogSpec) DeepCopy() *SlowQueryLogSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SlowQueryLogSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserSpec) DeepCopyInto(out *UserSpec) {
+	*out = *in
+	if in.Grants != nil {
+		in, out := &in.Grants, &out.Grants
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserSpec.
+func (in *UserSpec) DeepCopy() *UserSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(UserSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UserStatus) DeepCopyInto(out *UserStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UserStatus.
+func (in *UserStatus) DeepCopy() *UserStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UserStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VolumeApplyConfiguration) DeepCopyInto(out *VolumeApplyConfiguration) {
 	clone := in.DeepCopy()
@@ -576,3 +1344,23 @@ func (in *VolumeSourceApplyConfiguration) DeepCopyInto(out *VolumeSourceApplyCon
 	clone := in.DeepCopy()
 	*out = *clone
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WarmUpSpec) DeepCopyInto(out *WarmUpSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WarmUpSpec.
+func (in *WarmUpSpec) DeepCopy() *WarmUpSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WarmUpSpec)
+	in.DeepCopyInto(out)
+	return out
+}
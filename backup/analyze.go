@@ -0,0 +1,86 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/cybozu-go/moco/pkg/bkop"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/go-logr/logr"
+	"go.uber.org/zap/zapcore"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+)
+
+// AnalyzeManager refreshes the statistics of tables on replica instances.
+type AnalyzeManager struct {
+	log       logr.Logger
+	hosts     []string
+	password  string
+	databases []string
+}
+
+// NewAnalyzeManager creates an AnalyzeManager.
+// `hosts` are the hostnames of all the instances of a MySQLCluster.
+func NewAnalyzeManager(hosts []string, password string, databases []string) *AnalyzeManager {
+	log := zap.New(zap.WriteTo(os.Stderr), zap.StacktraceLevel(zapcore.DPanicLevel))
+	return &AnalyzeManager{
+		log:       log,
+		hosts:     hosts,
+		password:  password,
+		databases: databases,
+	}
+}
+
+// Analyze runs `ANALYZE TABLE` on the instances whose `super_read_only` is enabled.
+// The primary instance is skipped because it is writable.  Unreachable instances are
+// skipped too, but this returns an error if no instance has been analyzed.
+func (am *AnalyzeManager) Analyze(ctx context.Context) error {
+	var errs []error
+	analyzed := 0
+	for _, host := range am.hosts {
+		ok, err := am.analyze(ctx, host)
+		if err != nil {
+			am.log.Error(err, "failed to analyze tables", "host", host)
+			errs = append(errs, err)
+			continue
+		}
+		if ok {
+			analyzed++
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if analyzed == 0 {
+		return errors.New("no replica instance is available")
+	}
+	return nil
+}
+
+func (am *AnalyzeManager) analyze(ctx context.Context, host string) (bool, error) {
+	op, err := newOperator(host, constants.MySQLPort, constants.AdminUser, am.password, 1)
+	if err != nil {
+		return false, fmt.Errorf("failed to create operator for %s: %w", host, err)
+	}
+	defer op.Close()
+
+	var status bkop.ServerStatus
+	if err := op.GetServerStatus(ctx, &status); err != nil {
+		am.log.Info("skipped an unreachable instance", "host", host, "error", err.Error())
+		return false, nil
+	}
+	if !status.SuperReadOnly {
+		am.log.Info("skipped the primary instance", "host", host)
+		return false, nil
+	}
+
+	am.log.Info("analyzing tables", "host", host)
+	if err := op.AnalyzeTables(ctx, am.databases); err != nil {
+		return false, fmt.Errorf("failed to analyze tables on %s: %w", host, err)
+	}
+	am.log.Info("analyzed tables", "host", host)
+	return true, nil
+}
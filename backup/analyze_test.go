@@ -0,0 +1,160 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/cybozu-go/moco/pkg/bkop"
+)
+
+type analyzeMockOp struct {
+	host          string
+	unreachable   bool
+	superReadOnly bool
+	analyzeErr    error
+
+	closed    bool
+	databases []string
+	analyzed  bool
+}
+
+func (o *analyzeMockOp) Ping() error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) Close() {
+	o.closed = true
+}
+
+func (o *analyzeMockOp) GetServerStatus(_ context.Context, st *bkop.ServerStatus) error {
+	if o.unreachable {
+		return errors.New("unreachable")
+	}
+	st.SuperReadOnly = o.superReadOnly
+	return nil
+}
+
+func (o *analyzeMockOp) DumpFull(ctx context.Context, dir string, opts bkop.DumpOptions) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) GetBinlogs(_ context.Context) ([]string, error) {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) DumpBinlog(ctx context.Context, dir string, binlogName string, filterGTID string) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) PrepareRestore(_ context.Context) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) LoadDump(ctx context.Context, dir string) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) LoadBinlog(ctx context.Context, binlogDir, tmpDir string, restorePoint time.Time) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) FinishRestore(_ context.Context) error {
+	panic("not implemented")
+}
+
+func (o *analyzeMockOp) AnalyzeTables(_ context.Context, databases []string) error {
+	if o.analyzeErr != nil {
+		return o.analyzeErr
+	}
+	o.databases = databases
+	o.analyzed = true
+	return nil
+}
+
+func TestAnalyze(t *testing.T) {
+	testCases := []struct {
+		name string
+		ops  []*analyzeMockOp
+
+		expectErr bool
+		analyzed  []bool
+	}{
+		{
+			name: "skip-primary",
+			ops: []*analyzeMockOp{
+				{host: "h0", superReadOnly: true},
+				{host: "h1", superReadOnly: false},
+				{host: "h2", superReadOnly: true},
+			},
+			analyzed: []bool{true, false, true},
+		},
+		{
+			name: "skip-unreachable",
+			ops: []*analyzeMockOp{
+				{host: "h0", superReadOnly: false},
+				{host: "h1", unreachable: true},
+				{host: "h2", superReadOnly: true},
+			},
+			analyzed: []bool{false, false, true},
+		},
+		{
+			name: "primary-only",
+			ops: []*analyzeMockOp{
+				{host: "h0", superReadOnly: false},
+			},
+			expectErr: true,
+			analyzed:  []bool{false},
+		},
+		{
+			name: "analyze-error",
+			ops: []*analyzeMockOp{
+				{host: "h0", superReadOnly: true, analyzeErr: errors.New("error")},
+				{host: "h1", superReadOnly: false},
+				{host: "h2", superReadOnly: true},
+			},
+			expectErr: true,
+			analyzed:  []bool{false, false, true},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ops := make(map[string]*analyzeMockOp)
+			var hosts []string
+			for _, op := range tc.ops {
+				ops[op.host] = op
+				hosts = append(hosts, op.host)
+			}
+			newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
+				return ops[host], nil
+			}
+
+			am := NewAnalyzeManager(hosts, "password", []string{"foo"})
+			err := am.Analyze(context.Background())
+			if tc.expectErr && err == nil {
+				t.Error("error is expected")
+			}
+			if !tc.expectErr && err != nil {
+				t.Error("unexpected error", err)
+			}
+
+			analyzed := make([]bool, len(tc.ops))
+			for i, op := range tc.ops {
+				analyzed[i] = op.analyzed
+				if !op.closed {
+					t.Errorf("operator for %s is not closed", op.host)
+				}
+				if op.analyzed && !reflect.DeepEqual(op.databases, []string{"foo"}) {
+					t.Errorf("unexpected databases for %s: %v", op.host, op.databases)
+				}
+			}
+			if !reflect.DeepEqual(analyzed, tc.analyzed) {
+				t.Errorf("unexpected analyzed instances: expected %v, actual %v", tc.analyzed, analyzed)
+			}
+		})
+	}
+}
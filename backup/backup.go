@@ -11,7 +11,6 @@ import (
 	"path/filepath"
 	"sort"
 	"strconv"
-	"strings"
 	"syscall"
 	"time"
 
@@ -42,6 +41,7 @@ type BackupManager struct {
 	workDir       string
 	bucket        bucket.Bucket
 	threads       int
+	dumpOpts      bkop.DumpOptions
 
 	// status fields
 	startTime    time.Time
@@ -55,7 +55,7 @@ type BackupManager struct {
 	warnings     []string
 }
 
-func NewBackupManager(cfg *rest.Config, bc bucket.Bucket, dir, ns, name, password string, threads int) (*BackupManager, error) {
+func NewBackupManager(cfg *rest.Config, bc bucket.Bucket, dir, ns, name, password string, threads int, dumpOpts bkop.DumpOptions) (*BackupManager, error) {
 	log := zap.New(zap.WriteTo(os.Stderr), zap.StacktraceLevel(zapcore.DPanicLevel))
 	scheme := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -89,6 +89,7 @@ func NewBackupManager(cfg *rest.Config, bc bucket.Bucket, dir, ns, name, passwor
 		workDir:       dir,
 		bucket:        bc,
 		threads:       threads,
+		dumpOpts:      dumpOpts,
 	}, nil
 }
 
@@ -108,10 +109,9 @@ func (bm *BackupManager) Backup(ctx context.Context) error {
 
 	orderedPods := make([]*corev1.Pod, bm.cluster.Spec.Replicas)
 	for i, pod := range pods.Items {
-		fields := strings.Split(pod.Name, "-")
-		index, err := strconv.Atoi(fields[len(fields)-1])
+		index, err := bm.cluster.PodIndex(pod.Name)
 		if err != nil {
-			return fmt.Errorf("bad pod name: %s", pod.Name)
+			return err
 		}
 
 		if index < 0 || index >= len(pods.Items) {
@@ -301,9 +301,12 @@ func (bm *BackupManager) backupFull(ctx context.Context, op bkop.Operator) error
 	}
 	defer os.RemoveAll(dumpDir)
 
-	if err := op.DumpFull(ctx, dumpDir); err != nil {
+	if err := op.DumpFull(ctx, dumpDir, bm.dumpOpts); err != nil {
 		return fmt.Errorf("failed to take a full dump: %w", err)
 	}
+	if err := bkop.WriteDumpOptions(dumpDir, bm.dumpOpts); err != nil {
+		return fmt.Errorf("failed to record the dump options: %w", err)
+	}
 
 	gtid, err := bkop.GetGTIDExecuted(dumpDir)
 	if err != nil {
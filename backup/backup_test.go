@@ -33,7 +33,7 @@ func (o *getUUIDSetMockOp) GetServerStatus(_ context.Context, st *bkop.ServerSta
 	return nil
 }
 
-func (o *getUUIDSetMockOp) DumpFull(ctx context.Context, dir string) error {
+func (o *getUUIDSetMockOp) DumpFull(ctx context.Context, dir string, opts bkop.DumpOptions) error {
 	panic("not implemented")
 }
 
@@ -61,6 +61,10 @@ func (o *getUUIDSetMockOp) FinishRestore(_ context.Context) error {
 	panic("not implemented")
 }
 
+func (o *getUUIDSetMockOp) AnalyzeTables(ctx context.Context, databases []string) error {
+	panic("not implemented")
+}
+
 func makePod(ready bool) *corev1.Pod {
 	pod := &corev1.Pod{}
 	if !ready {
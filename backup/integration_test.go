@@ -13,6 +13,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -121,7 +122,7 @@ var _ = Describe("Backup/Restore", func() {
 			return op, nil
 		}
 
-		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 
 		err = bm.Backup(ctx)
@@ -151,7 +152,7 @@ var _ = Describe("Backup/Restore", func() {
 		Expect(bs.WorkDirUsage).To(BeNumerically(">", 0))
 		Expect(bs.Warnings).To(BeEmpty())
 
-		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "restore", "target", "", 3, bs.Time.Time)
+		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "", "restore", "target", "", 3, bs.Time.Time)
 		Expect(err).NotTo(HaveOccurred())
 
 		ctx2, cancel := context.WithTimeout(ctx, 3*time.Second)
@@ -182,6 +183,50 @@ var _ = Describe("Backup/Restore", func() {
 		Expect(cluster.Status.RestoredTime).NotTo(BeNil())
 	})
 
+	It("should restore a backup after the source cluster has been deleted", func() {
+		newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
+			op := &mockOperator{
+				binlogs: []string{"binlog.000001"},
+				uuid:    "123",
+				gtid:    "gtid1",
+			}
+			ops = append(ops, op)
+			return op, nil
+		}
+
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
+		Expect(err).NotTo(HaveOccurred())
+
+		err = bm.Backup(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster := &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "single"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		backupTime := cluster.Status.Backup.Time.Time
+
+		By("deleting the source cluster and its Pods")
+		err = k8sClient.Delete(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "single"}, &mocov1beta2.MySQLCluster{})
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		By("restoring only from the bucket")
+		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "", "restore", "target", "", 3, backupTime)
+		Expect(err).NotTo(HaveOccurred())
+		err = rm.Restore(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "restore", Name: "target"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Status.RestoredTime).NotTo(BeNil())
+	})
+
 	It("should take an incremental backup and be able to do PiTR", func() {
 		newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
 			op := &mockOperator{
@@ -193,7 +238,7 @@ var _ = Describe("Backup/Restore", func() {
 			return op, nil
 		}
 
-		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 
 		err = bm.Backup(ctx)
@@ -218,7 +263,7 @@ var _ = Describe("Backup/Restore", func() {
 		// second shot
 		err = os.RemoveAll(filepath.Join(workDir, "dump"))
 		Expect(err).NotTo(HaveOccurred())
-		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		err = bm.Backup(ctx)
 		Expect(err).NotTo(HaveOccurred())
@@ -240,13 +285,61 @@ var _ = Describe("Backup/Restore", func() {
 		Expect(bs.WorkDirUsage).To(BeNumerically(">", 0))
 		Expect(bs.Warnings).To(BeEmpty())
 
-		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "restore", "target", "", 3, restorePoint)
+		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "", "restore", "target", "", 3, restorePoint)
 		Expect(err).NotTo(HaveOccurred())
 
 		err = rm.Restore(ctx)
 		Expect(err).NotTo(HaveOccurred())
 	})
 
+	It("should reject a PiTR from a partial backup before loading the dump", func() {
+		newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
+			op := &mockOperator{
+				binlogs: []string{"binlog.000001"},
+				uuid:    "123",
+				gtid:    "gtid1",
+			}
+			ops = append(ops, op)
+			return op, nil
+		}
+
+		dumpOpts := bkop.DumpOptions{IncludeDatabases: []string{"app"}}
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, dumpOpts)
+		Expect(err).NotTo(HaveOccurred())
+		err = bm.Backup(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		time.Sleep(1100 * time.Millisecond)
+		restorePoint := time.Now()
+		time.Sleep(1100 * time.Millisecond)
+
+		newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
+			op := &mockOperator{
+				binlogs: []string{"binlog.000001", "binlog.000002"},
+				uuid:    "123",
+				gtid:    "gtid2",
+			}
+			ops = append(ops, op)
+			return op, nil
+		}
+
+		err = os.RemoveAll(filepath.Join(workDir, "dump"))
+		Expect(err).NotTo(HaveOccurred())
+		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, dumpOpts)
+		Expect(err).NotTo(HaveOccurred())
+		err = bm.Backup(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(bc.contents).To(HaveLen(3))
+
+		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "", "restore", "target", "", 3, restorePoint)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = rm.Restore(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("partial backup"))
+		Expect(ops[len(ops)-1].prepared).To(BeFalse())
+	})
+
 	It("should NOT do a PiTR when the time matches the time of a full backup", func() {
 		newOperator = func(host string, port int, user, password string, threads int) (bkop.Operator, error) {
 			op := &mockOperator{
@@ -258,7 +351,7 @@ var _ = Describe("Backup/Restore", func() {
 			return op, nil
 		}
 
-		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 
 		err = bm.Backup(ctx)
@@ -286,13 +379,13 @@ var _ = Describe("Backup/Restore", func() {
 		// second shot
 		err = os.RemoveAll(filepath.Join(workDir, "dump"))
 		Expect(err).NotTo(HaveOccurred())
-		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		err = bm.Backup(ctx)
 		Expect(err).NotTo(HaveOccurred())
 		Expect(bc.contents).To(HaveLen(3))
 
-		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "restore", "target", "", 3, bt)
+		rm, err := NewRestoreManager(cfg, bc, workDir2, "test", "single", "", "restore", "target", "", 3, bt)
 		Expect(err).NotTo(HaveOccurred())
 
 		err = rm.Restore(ctx)
@@ -310,7 +403,7 @@ var _ = Describe("Backup/Restore", func() {
 			return op, nil
 		}
 
-		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err := NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 
 		err = bm.Backup(ctx)
@@ -332,7 +425,7 @@ var _ = Describe("Backup/Restore", func() {
 		// second shot
 		err = os.RemoveAll(filepath.Join(workDir, "dump"))
 		Expect(err).NotTo(HaveOccurred())
-		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3)
+		bm, err = NewBackupManager(cfg, bc, workDir, "test", "single", "", 3, bkop.DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 		err = bm.Backup(ctx)
 		Expect(err).NotTo(HaveOccurred())
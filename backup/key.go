@@ -2,6 +2,7 @@ package backup
 
 import (
 	"path"
+	"strings"
 	"time"
 
 	"github.com/cybozu-go/moco/pkg/constants"
@@ -16,3 +17,7 @@ func calcKey(clusterNS, clusterName, filename string, dt time.Time) string {
 func calcPrefix(clusterNS, clusterName string) string {
 	return path.Join(prefix, clusterNS, clusterName) + "/"
 }
+
+func normalizePrefix(keyPrefix string) string {
+	return strings.TrimSuffix(keyPrefix, "/") + "/"
+}
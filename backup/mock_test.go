@@ -52,7 +52,7 @@ func (o *mockOperator) GetServerStatus(_ context.Context, st *bkop.ServerStatus)
 	return nil
 }
 
-func (o *mockOperator) DumpFull(ctx context.Context, dir string) error {
+func (o *mockOperator) DumpFull(ctx context.Context, dir string, opts bkop.DumpOptions) error {
 	data, err := json.Marshal(map[string]string{
 		"gtidExecuted": o.gtid,
 	})
@@ -130,6 +130,10 @@ func (o *mockOperator) FinishRestore(_ context.Context) error {
 	return nil
 }
 
+func (o *mockOperator) AnalyzeTables(ctx context.Context, databases []string) error {
+	panic("not implemented")
+}
+
 type mockBucket struct {
 	contents map[string][]byte
 }
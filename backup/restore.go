@@ -46,7 +46,9 @@ type RestoreManager struct {
 
 var ErrBadConnection = errors.New("the connection hasn't reflected the latest user's privileges")
 
-func NewRestoreManager(cfg *rest.Config, bc bucket.Bucket, dir, srcNS, srcName, ns, name, password string, threads int, restorePoint time.Time) (*RestoreManager, error) {
+// NewRestoreManager creates a RestoreManager.
+// If keyPrefix is empty, the backup files are looked up under the prefix derived from srcNS and srcName.
+func NewRestoreManager(cfg *rest.Config, bc bucket.Bucket, dir, srcNS, srcName, keyPrefix, ns, name, password string, threads int, restorePoint time.Time) (*RestoreManager, error) {
 	log := zap.New(zap.WriteTo(os.Stderr), zap.StacktraceLevel(zapcore.DPanicLevel))
 	scheme := runtime.NewScheme()
 	if err := clientgoscheme.AddToScheme(scheme); err != nil {
@@ -62,6 +64,9 @@ func NewRestoreManager(cfg *rest.Config, bc bucket.Bucket, dir, srcNS, srcName,
 	}
 
 	prefix := calcPrefix(srcNS, srcName)
+	if keyPrefix != "" {
+		prefix = normalizePrefix(keyPrefix)
+	}
 	return &RestoreManager{
 		log:          log,
 		client:       k8sClient,
@@ -146,17 +151,33 @@ func (rm *RestoreManager) Restore(ctx context.Context) error {
 
 	rm.log.Info("restoring from a backup", "dump", dumpKey, "binlog", binlogKey)
 
+	dumpDir, dumpOpts, err := rm.extractDump(ctx, dumpKey)
+	defer func() {
+		os.RemoveAll(dumpDir)
+	}()
+	if err != nil {
+		return fmt.Errorf("failed to extract dump: %w", err)
+	}
+
+	pitr := !backupTime.Equal(rm.restorePoint) && binlogKey != ""
+	// binary logs contain transactions for the databases missing in a partial dump,
+	// so they cannot be applied.  This is checked before loading anything into the instance.
+	if pitr && dumpOpts.IsPartial() {
+		return fmt.Errorf("point-in-time recovery from a partial backup is not supported; use %s as the restore point",
+			backupTime.Format(constants.BackupTimeFormat))
+	}
+
 	if err := op.PrepareRestore(ctx); err != nil {
 		return fmt.Errorf("failed to prepare instance for restoration: %w", err)
 	}
 
-	if err := rm.loadDump(ctx, op, dumpKey); err != nil {
+	if err := op.LoadDump(ctx, dumpDir); err != nil {
 		return fmt.Errorf("failed to load dump: %w", err)
 	}
 
 	rm.log.Info("loaded dump successfully")
 
-	if !backupTime.Equal(rm.restorePoint) && binlogKey != "" {
+	if pitr {
 		if err := rm.applyBinlog(ctx, op, binlogKey); err != nil {
 			return fmt.Errorf("failed to apply transactions: %w", err)
 		}
@@ -234,27 +255,35 @@ func (rm *RestoreManager) FindNearestDump(keys []string) (string, string, time.T
 	return nearestDump, nearestBinlog, nearest
 }
 
-func (rm *RestoreManager) loadDump(ctx context.Context, op bkop.Operator, key string) error {
+// extractDump extracts the dump file into the working directory.
+// It returns the directory of the dump and the options used to take the dump.
+func (rm *RestoreManager) extractDump(ctx context.Context, key string) (string, bkop.DumpOptions, error) {
+	dumpDir := filepath.Join(rm.workDir, "dump")
+
 	r, err := rm.bucket.Get(ctx, key)
 	if err != nil {
-		return fmt.Errorf("failed to get object %s: %w", key, err)
+		return dumpDir, bkop.DumpOptions{}, fmt.Errorf("failed to get object %s: %w", key, err)
 	}
 	defer r.Close()
 
-	dumpDir := filepath.Join(rm.workDir, "dump")
-	defer func() {
-		os.RemoveAll(dumpDir)
-	}()
-
 	tarCmd := exec.CommandContext(ctx, "tar", "-C", rm.workDir, "-x", "-f", "-")
 	tarCmd.Stdin = r
 	tarCmd.Stdout = os.Stdout
 	tarCmd.Stderr = os.Stderr
 	if err := tarCmd.Run(); err != nil {
-		return fmt.Errorf("failed to untar dump file: %w", err)
+		return dumpDir, bkop.DumpOptions{}, fmt.Errorf("failed to untar dump file: %w", err)
 	}
 
-	return op.LoadDump(ctx, dumpDir)
+	opts, err := bkop.ReadDumpOptions(dumpDir)
+	if err != nil {
+		return dumpDir, opts, err
+	}
+	if opts.IsPartial() {
+		rm.log.Info("the backup is partial",
+			"includeDatabases", opts.IncludeDatabases,
+			"excludeDatabases", opts.ExcludeDatabases)
+	}
+	return dumpDir, opts, nil
 }
 
 func (rm *RestoreManager) applyBinlog(ctx context.Context, op bkop.Operator, key string) error {
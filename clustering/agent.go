@@ -36,13 +36,17 @@ type AgentFactory interface {
 }
 
 // NewAgentFactory returns a new AgentFactory.
-func NewAgentFactory(r dbop.Resolver, reloader *cert.Reloader) AgentFactory {
-	return defaultAgentFactory{resolver: r, reloader: reloader}
+//
+// maxMessageSize is the maximum size in bytes of gRPC messages sent to and
+// received from moco-agent.  If zero, the defaults of gRPC are used.
+func NewAgentFactory(r dbop.Resolver, reloader *cert.Reloader, maxMessageSize int) AgentFactory {
+	return defaultAgentFactory{resolver: r, reloader: reloader, maxMessageSize: maxMessageSize}
 }
 
 type defaultAgentFactory struct {
-	resolver dbop.Resolver
-	reloader *cert.Reloader
+	resolver       dbop.Resolver
+	reloader       *cert.Reloader
+	maxMessageSize int
 }
 
 var _ AgentFactory = defaultAgentFactory{}
@@ -64,7 +68,8 @@ func (f defaultAgentFactory) New(ctx context.Context, cluster *mocov1beta2.MySQL
 		grpc.WithAuthority(cluster.PodHostname(index)),
 		grpc.WithBlock(),
 		grpc.WithTransportCredentials(cred),
-		grpc.WithKeepaliveParams(kp))
+		grpc.WithKeepaliveParams(kp),
+		grpc.WithDefaultCallOptions(f.callOptions()...))
 	if err != nil {
 		return agentConn{}, err
 	}
@@ -73,3 +78,14 @@ func (f defaultAgentFactory) New(ctx context.Context, cluster *mocov1beta2.MySQL
 		ClientConn:  conn,
 	}, nil
 }
+
+// callOptions returns the default call options for the connections to moco-agent.
+func (f defaultAgentFactory) callOptions() []grpc.CallOption {
+	if f.maxMessageSize <= 0 {
+		return nil
+	}
+	return []grpc.CallOption{
+		grpc.MaxCallRecvMsgSize(f.maxMessageSize),
+		grpc.MaxCallSendMsgSize(f.maxMessageSize),
+	}
+}
@@ -0,0 +1,37 @@
+package clustering
+
+import (
+	"testing"
+
+	"google.golang.org/grpc"
+)
+
+func TestAgentFactoryCallOptions(t *testing.T) {
+	f := NewAgentFactory(nil, nil, 0).(defaultAgentFactory)
+	if opts := f.callOptions(); len(opts) != 0 {
+		t.Errorf("call options should be empty by default: %v", opts)
+	}
+
+	f = NewAgentFactory(nil, nil, 64<<20).(defaultAgentFactory)
+	opts := f.callOptions()
+	if len(opts) != 2 {
+		t.Fatalf("unexpected number of call options: %d", len(opts))
+	}
+	var recv, send int
+	for _, o := range opts {
+		switch o := o.(type) {
+		case grpc.MaxRecvMsgSizeCallOption:
+			recv = o.MaxRecvMsgSize
+		case grpc.MaxSendMsgSizeCallOption:
+			send = o.MaxSendMsgSize
+		default:
+			t.Errorf("unexpected call option: %T", o)
+		}
+	}
+	if recv != 64<<20 {
+		t.Errorf("unexpected max recv message size: %d", recv)
+	}
+	if send != 64<<20 {
+		t.Errorf("unexpected max send message size: %d", send)
+	}
+}
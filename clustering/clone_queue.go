@@ -0,0 +1,55 @@
+package clustering
+
+import (
+	"time"
+
+	"github.com/cybozu-go/moco/pkg/dbop"
+)
+
+// replicaNeedsClone returns true if the replica instance has no data and needs to clone it from the primary.
+func replicaNeedsClone(ss *StatusSet, index int) bool {
+	st := ss.MySQLStatus[index]
+	if st == nil || st.IsErrant {
+		return false
+	}
+	return st.GlobalVariables.ExecutedGTID == "" && ss.ExecutedGTID != "" && st.ReplicaStatus == nil
+}
+
+// isCloningReplica returns true if data is being cloned to the instance.
+func isCloningReplica(st *dbop.MySQLInstanceStatus) bool {
+	return st != nil && st.CloneStatus != nil && st.CloneStatus.State.String == "In Progress"
+}
+
+// cloneQueue decides the replicas to which data is cloned now so that no more than
+// `spec.maxConcurrentClones` clones from the primary run at a time.
+// It also returns the number of replicas being cloned, and the replicas waiting for
+// the running clones to finish.  Replicas waiting for `spec.cloneRetryBackoff` are not queued.
+func cloneQueue(ss *StatusSet, now time.Time) (start []int, cloning int, queued []int) {
+	var waiting []int
+	for i, st := range ss.MySQLStatus {
+		if i == ss.Primary {
+			continue
+		}
+		if isCloningReplica(st) {
+			cloning++
+			continue
+		}
+		if !replicaNeedsClone(ss, i) || cloneRetryWait(ss.Cluster, i, now) > 0 {
+			continue
+		}
+		waiting = append(waiting, i)
+	}
+
+	slots := int(ss.Cluster.Spec.MaxConcurrentClones)
+	if slots < 1 {
+		slots = 1
+	}
+	slots -= cloning
+	switch {
+	case slots >= len(waiting):
+		return waiting, cloning, nil
+	case slots <= 0:
+		return nil, cloning, waiting
+	}
+	return waiting[:slots], cloning, waiting[slots:]
+}
@@ -0,0 +1,109 @@
+package clustering
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/dbop"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCloneQueue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	empty := func() *dbop.MySQLInstanceStatus {
+		return &dbop.MySQLInstanceStatus{}
+	}
+	cloning := func() *dbop.MySQLInstanceStatus {
+		return &dbop.MySQLInstanceStatus{
+			CloneStatus: &dbop.CloneStatus{State: sql.NullString{Valid: true, String: "In Progress"}},
+		}
+	}
+	replicating := func() *dbop.MySQLInstanceStatus {
+		return &dbop.MySQLInstanceStatus{
+			GlobalVariables: dbop.GlobalVariables{ExecutedGTID: "gtid"},
+			ReplicaStatus:   &dbop.ReplicaStatus{SlaveIORunning: "Yes"},
+		}
+	}
+
+	testCases := []struct {
+		name        string
+		max         int32
+		statuses    []*dbop.MySQLInstanceStatus
+		failures    []mocov1beta2.CloneFailureStatus
+		wantStart   []int
+		wantCloning int
+		wantQueued  []int
+	}{
+		{
+			name:     "no clones",
+			max:      1,
+			statuses: []*dbop.MySQLInstanceStatus{replicating(), replicating(), replicating()},
+		},
+		{
+			name:       "one at a time by default",
+			statuses:   []*dbop.MySQLInstanceStatus{replicating(), empty(), empty(), empty(), empty()},
+			wantStart:  []int{1},
+			wantQueued: []int{2, 3, 4},
+		},
+		{
+			name:       "limited by the clones in progress",
+			max:        2,
+			statuses:   []*dbop.MySQLInstanceStatus{replicating(), cloning(), empty(), empty(), nil},
+			wantStart:  []int{2},
+			wantQueued: []int{3},
+
+			wantCloning: 1,
+		},
+		{
+			name:        "no slots",
+			max:         1,
+			statuses:    []*dbop.MySQLInstanceStatus{replicating(), cloning(), empty()},
+			wantCloning: 1,
+			wantQueued:  []int{2},
+		},
+		{
+			name:      "all clones start",
+			max:       3,
+			statuses:  []*dbop.MySQLInstanceStatus{replicating(), empty(), empty(), replicating(), empty()},
+			wantStart: []int{1, 2, 4},
+		},
+		{
+			name:     "backoff is not queued",
+			max:      1,
+			statuses: []*dbop.MySQLInstanceStatus{replicating(), empty(), empty()},
+			failures: []mocov1beta2.CloneFailureStatus{
+				{Index: 1, Count: 1, LastFailureTime: metav1.NewTime(now.Add(-time.Second))},
+			},
+			wantStart: []int{2},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Spec.MaxConcurrentClones = tc.max
+			cluster.Spec.CloneRetryBackoff = &metav1.Duration{Duration: time.Minute}
+			cluster.Status.CloneFailures = tc.failures
+			ss := &StatusSet{
+				Cluster:      cluster,
+				ExecutedGTID: "gtid",
+				MySQLStatus:  tc.statuses,
+			}
+
+			start, n, queued := cloneQueue(ss, now)
+			if !cmp.Equal(start, tc.wantStart) {
+				t.Error("unexpected replicas to start cloning", cmp.Diff(tc.wantStart, start))
+			}
+			if n != tc.wantCloning {
+				t.Errorf("unexpected number of clones in progress: expected %d, actual %d", tc.wantCloning, n)
+			}
+			if !cmp.Equal(queued, tc.wantQueued) {
+				t.Error("unexpected queued replicas", cmp.Diff(tc.wantQueued, queued))
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package clustering
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+)
+
+// cloneRetryWait returns the duration to wait before cloning data to the instance
+// because of `spec.cloneRetryBackoff`.  It returns zero if cloning can be started now.
+func cloneRetryWait(cluster *mocov1beta2.MySQLCluster, index int, now time.Time) time.Duration {
+	if cluster.Spec.CloneRetryBackoff == nil {
+		return 0
+	}
+	for _, f := range cluster.Status.CloneFailures {
+		if f.Index != index {
+			continue
+		}
+		wait := f.LastFailureTime.Add(cluster.Spec.CloneRetryBackoff.Duration).Sub(now)
+		if wait < 0 {
+			return 0
+		}
+		return wait
+	}
+	return 0
+}
+
+// updateCloneFailures returns the list of clone failures updated with the result of
+// cloning data to the instance.  A successful clone removes the entry of the instance.
+func updateCloneFailures(failures []mocov1beta2.CloneFailureStatus, index int, cloneErr error, now metav1.Time) []mocov1beta2.CloneFailureStatus {
+	var updated []mocov1beta2.CloneFailureStatus
+	count := 0
+	for _, f := range failures {
+		if f.Index == index {
+			count = f.Count
+			continue
+		}
+		updated = append(updated, f)
+	}
+	if cloneErr == nil {
+		return updated
+	}
+
+	updated = append(updated, mocov1beta2.CloneFailureStatus{
+		Index:           index,
+		Count:           count + 1,
+		LastError:       cloneErr.Error(),
+		LastFailureTime: now,
+	})
+	slices.SortFunc(updated, func(a, b mocov1beta2.CloneFailureStatus) int {
+		return cmp.Compare(a.Index, b.Index)
+	})
+	return updated
+}
+
+// recordCloneResult records the result of cloning data to the instance in the status of MySQLCluster.
+func (p *managerProcess) recordCloneResult(ctx context.Context, index int, cloneErr error) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+
+		failures := updateCloneFailures(cluster.Status.CloneFailures, index, cloneErr, metav1.Now())
+		if equality.Semantic.DeepEqual(failures, cluster.Status.CloneFailures) {
+			return nil
+		}
+		cluster.Status.CloneFailures = failures
+		return p.client.Status().Update(ctx, cluster)
+	})
+}
@@ -0,0 +1,71 @@
+package clustering
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestCloneRetryWait(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Status.CloneFailures = []mocov1beta2.CloneFailureStatus{
+		{Index: 1, Count: 2, LastError: "error", LastFailureTime: metav1.NewTime(now.Add(-10 * time.Second))},
+	}
+
+	if wait := cloneRetryWait(cluster, 1, now); wait != 0 {
+		t.Errorf("backoff is applied without cloneRetryBackoff: %v", wait)
+	}
+
+	cluster.Spec.CloneRetryBackoff = &metav1.Duration{Duration: time.Minute}
+	if wait := cloneRetryWait(cluster, 1, now); wait != 50*time.Second {
+		t.Errorf("unexpected wait: %v", wait)
+	}
+	if wait := cloneRetryWait(cluster, 2, now); wait != 0 {
+		t.Errorf("backoff is applied to an instance without failures: %v", wait)
+	}
+	if wait := cloneRetryWait(cluster, 1, now.Add(time.Minute)); wait != 0 {
+		t.Errorf("backoff is applied after it expired: %v", wait)
+	}
+}
+
+func TestUpdateCloneFailures(t *testing.T) {
+	t1 := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	t2 := metav1.NewTime(t1.Add(time.Minute))
+
+	failures := updateCloneFailures(nil, 2, errors.New("error 1"), t1)
+	expected := []mocov1beta2.CloneFailureStatus{
+		{Index: 2, Count: 1, LastError: "error 1", LastFailureTime: t1},
+	}
+	if !cmp.Equal(failures, expected) {
+		t.Error("unexpected failures", cmp.Diff(expected, failures))
+	}
+
+	failures = updateCloneFailures(failures, 1, errors.New("error 2"), t1)
+	failures = updateCloneFailures(failures, 2, errors.New("error 3"), t2)
+	expected = []mocov1beta2.CloneFailureStatus{
+		{Index: 1, Count: 1, LastError: "error 2", LastFailureTime: t1},
+		{Index: 2, Count: 2, LastError: "error 3", LastFailureTime: t2},
+	}
+	if !cmp.Equal(failures, expected) {
+		t.Error("unexpected failures", cmp.Diff(expected, failures))
+	}
+
+	failures = updateCloneFailures(failures, 2, nil, t2)
+	expected = []mocov1beta2.CloneFailureStatus{
+		{Index: 1, Count: 1, LastError: "error 2", LastFailureTime: t1},
+	}
+	if !cmp.Equal(failures, expected) {
+		t.Error("unexpected failures", cmp.Diff(expected, failures))
+	}
+
+	failures = updateCloneFailures(failures, 1, nil, t2)
+	if len(failures) != 0 {
+		t.Error("failures are not cleared", failures)
+	}
+}
@@ -0,0 +1,62 @@
+package clustering
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/client-go/util/retry"
+)
+
+// reconcileDatabases creates the databases in `spec.databases` on the primary instance.
+// Databases removed from `spec.databases` are never dropped automatically
+// because dropping them would lose data; they need to be dropped manually.
+func (p *managerProcess) reconcileDatabases(ctx context.Context, ss *StatusSet) error {
+	cluster := ss.Cluster
+	if cluster.IsReadOnly() {
+		return nil
+	}
+	if len(cluster.Spec.Databases) == 0 && len(cluster.Status.Databases) == 0 {
+		return nil
+	}
+
+	log := logFromContext(ctx)
+	op := ss.DBOps[ss.Primary]
+
+	created := make(map[string]bool)
+	for _, name := range cluster.Status.Databases {
+		created[name] = true
+	}
+
+	var databases []string
+	desired := make(map[string]bool)
+	for _, name := range cluster.Spec.Databases {
+		desired[name] = true
+		if !created[name] {
+			log.Info("create database", "database", name)
+			if err := op.CreateDatabase(ctx, name); err != nil {
+				return err
+			}
+		}
+		databases = append(databases, name)
+	}
+
+	for _, name := range cluster.Status.Databases {
+		if desired[name] {
+			continue
+		}
+		log.Info("database was removed from spec.databases; manual drop is required", "database", name)
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(cluster.Status.Databases, databases) {
+			return nil
+		}
+		cluster.Status.Databases = databases
+		return p.client.Status().Update(ctx, cluster)
+	})
+}
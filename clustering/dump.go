@@ -0,0 +1,97 @@
+package clustering
+
+import (
+	"time"
+)
+
+// ClusterStateDump is a snapshot of what the manager process believes about a MySQLCluster.
+// It is built from the status gathered in the last operation and is meant for troubleshooting.
+type ClusterStateDump struct {
+	Namespace    string              `json:"namespace"`
+	Name         string              `json:"name"`
+	State        string              `json:"state"`
+	Primary      int                 `json:"primary"`
+	NeedSwitch   bool                `json:"needSwitch"`
+	Candidate    int                 `json:"candidate"`
+	Errants      []int               `json:"errants"`
+	ExecutedGTID string              `json:"executedGTID"`
+	Instances    []InstanceStateDump `json:"instances"`
+	ObservedTime time.Time           `json:"observedTime"`
+
+	// LastOperation is the result of the last operation.
+	// It may be newer than the other fields if the last operation failed to gather the status.
+	LastOperation *OperationDump `json:"lastOperation,omitempty"`
+}
+
+// InstanceStateDump is the state of a MySQL instance in ClusterStateDump.
+type InstanceStateDump struct {
+	Index               int    `json:"index"`
+	Pod                 string `json:"pod"`
+	Ready               bool   `json:"ready"`
+	Reachable           bool   `json:"reachable"`
+	Errant              bool   `json:"errant"`
+	ReadOnly            bool   `json:"readOnly"`
+	SuperReadOnly       bool   `json:"superReadOnly"`
+	ExecutedGTID        string `json:"executedGTID,omitempty"`
+	SourceHost          string `json:"sourceHost,omitempty"`
+	IORunning           string `json:"ioRunning,omitempty"`
+	SQLRunning          string `json:"sqlRunning,omitempty"`
+	LastIOError         string `json:"lastIOError,omitempty"`
+	LastSQLError        string `json:"lastSQLError,omitempty"`
+	SecondsBehindSource *int64 `json:"secondsBehindSource,omitempty"`
+}
+
+// OperationDump is the result of an operation of the manager process.
+type OperationDump struct {
+	ID        string    `json:"id"`
+	Origin    string    `json:"origin"`
+	StartTime time.Time `json:"startTime"`
+	Duration  string    `json:"duration"`
+	Error     string    `json:"error,omitempty"`
+}
+
+func newClusterStateDump(ss *StatusSet, now time.Time) *ClusterStateDump {
+	d := &ClusterStateDump{
+		Namespace:    ss.Cluster.Namespace,
+		Name:         ss.Cluster.Name,
+		State:        ss.State.String(),
+		Primary:      ss.Primary,
+		NeedSwitch:   ss.NeedSwitch,
+		Candidate:    ss.Candidate,
+		Errants:      append([]int{}, ss.Errants...),
+		ExecutedGTID: ss.ExecutedGTID,
+		Instances:    make([]InstanceStateDump, 0, len(ss.Pods)),
+		ObservedTime: now,
+	}
+
+	for i, pod := range ss.Pods {
+		inst := InstanceStateDump{
+			Index: i,
+			Pod:   ss.Cluster.PodName(i),
+		}
+		if pod != nil {
+			inst.Ready = isPodReady(pod)
+		}
+		if i < len(ss.MySQLStatus) && ss.MySQLStatus[i] != nil {
+			ist := ss.MySQLStatus[i]
+			inst.Reachable = true
+			inst.Errant = ist.IsErrant
+			inst.ReadOnly = ist.GlobalVariables.ReadOnly
+			inst.SuperReadOnly = ist.GlobalVariables.SuperReadOnly
+			inst.ExecutedGTID = ist.GlobalVariables.ExecutedGTID
+			if rs := ist.ReplicaStatus; rs != nil {
+				inst.SourceHost = rs.MasterHost
+				inst.IORunning = rs.SlaveIORunning
+				inst.SQLRunning = rs.SlaveSQLRunning
+				inst.LastIOError = rs.LastIoError
+				inst.LastSQLError = rs.LastSQLError
+				if rs.SecondsBehindMaster.Valid {
+					lag := rs.SecondsBehindMaster.Int64
+					inst.SecondsBehindSource = &lag
+				}
+			}
+		}
+		d.Instances = append(d.Instances, inst)
+	}
+	return d
+}
@@ -0,0 +1,140 @@
+package clustering
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var (
+	operationLeaseDuration      = 30 * time.Second
+	operationLeaseRenewInterval = 10 * time.Second
+)
+
+// errOperationLeaseHeld is returned when another controller holds the operation lease of the cluster.
+var errOperationLeaseHeld = errors.New("the operation lease is held by another controller")
+
+// tryAcquireOperationLease acquires or renews the operation lease of the cluster.
+//
+// The lease is recorded in the annotations of MySQLCluster.  The annotations are
+// patched with the resource version read from the API server, so only one of
+// concurrent attempts can succeed.  The cluster is updated for other reasons, too,
+// so a conflict is retried after reading the cluster again.  It returns false only
+// if another controller holds an unexpired lease.
+func (p *managerProcess) tryAcquireOperationLease(ctx context.Context) (bool, error) {
+	acquired := false
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		acquired = false
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if holder := cluster.Annotations[constants.AnnOperationHolder]; holder != "" && holder != p.identity {
+			expires, err := time.Parse(time.RFC3339, cluster.Annotations[constants.AnnOperationLeaseExpires])
+			if err == nil && now.Before(expires) {
+				return nil
+			}
+		}
+
+		orig := cluster.DeepCopy()
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[constants.AnnOperationHolder] = p.identity
+		cluster.Annotations[constants.AnnOperationLeaseExpires] = now.Add(operationLeaseDuration).UTC().Format(time.RFC3339)
+		if err := p.client.Patch(ctx, cluster, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{})); err != nil {
+			return err
+		}
+		acquired = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+// releaseOperationLease removes the operation lease of the cluster if this process holds it.
+func (p *managerProcess) releaseOperationLease(ctx context.Context) error {
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+		if cluster.Annotations[constants.AnnOperationHolder] != p.identity {
+			return nil
+		}
+
+		orig := cluster.DeepCopy()
+		delete(cluster.Annotations, constants.AnnOperationHolder)
+		delete(cluster.Annotations, constants.AnnOperationLeaseExpires)
+		return p.client.Patch(ctx, cluster, client.MergeFromWithOptions(orig, client.MergeFromWithOptimisticLock{}))
+	})
+	return client.IgnoreNotFound(err)
+}
+
+// withOperationLease runs fn while holding the operation lease of the cluster.
+// Destructive operations such as clone, switchover, and failover must be run with
+// this so that two controllers never run them for a cluster at the same time,
+// e.g. during a leadership handoff.
+//
+// It returns errOperationLeaseHeld without calling fn if another controller holds the lease.
+// The lease is renewed while fn is running.  If the lease is lost, the context passed
+// to fn is canceled.
+func (p *managerProcess) withOperationLease(ctx context.Context, fn func(context.Context) error) error {
+	log := logFromContext(ctx)
+
+	ok, err := p.tryAcquireOperationLease(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to acquire the operation lease: %w", err)
+	}
+	if !ok {
+		return errOperationLeaseHeld
+	}
+
+	opCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		tick := time.NewTicker(operationLeaseRenewInterval)
+		defer tick.Stop()
+
+		renewed := time.Now()
+		for {
+			select {
+			case <-opCtx.Done():
+				return
+			case <-tick.C:
+			}
+
+			ok, err := p.tryAcquireOperationLease(opCtx)
+			switch {
+			case err != nil && time.Since(renewed) < operationLeaseDuration:
+				log.Error(err, "failed to renew the operation lease")
+			case err != nil || !ok:
+				log.Info("lost the operation lease; aborting the operation")
+				cancel()
+				return
+			default:
+				renewed = time.Now()
+			}
+		}
+	}()
+
+	err = fn(opCtx)
+	cancel()
+	<-done
+
+	if err := p.releaseOperationLease(ctx); err != nil {
+		log.Error(err, "failed to release the operation lease")
+	}
+	return err
+}
@@ -2,6 +2,7 @@ package clustering
 
 import (
 	"context"
+	"os"
 	"sync"
 	"time"
 
@@ -9,6 +10,7 @@ import (
 	"github.com/go-logr/logr"
 	_ "github.com/go-sql-driver/mysql"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -28,10 +30,16 @@ type ClusterManager interface {
 	Stop(types.NamespacedName)
 	StopAll()
 	Pause(types.NamespacedName)
+
+	// DumpState returns the current in-memory state of the cluster.
+	// It returns nil if the cluster is not managed or no operation has been run yet.
+	DumpState(types.NamespacedName) *ClusterStateDump
 }
 
 func NewClusterManager(interval time.Duration, m manager.Manager, opf dbop.OperatorFactory, af AgentFactory, log logr.Logger) ClusterManager {
+	hostname, _ := os.Hostname()
 	return &clusterManager{
+		identity:  hostname + "_" + rand.String(8),
 		client:    m.GetClient(),
 		reader:    m.GetAPIReader(),
 		recorder:  m.GetEventRecorderFor("moco-controller"),
@@ -46,6 +54,7 @@ func NewClusterManager(interval time.Duration, m manager.Manager, opf dbop.Opera
 //+kubebuilder:rbac:groups="",resources=pods,verbs=get;list;watch;update;patch
 
 type clusterManager struct {
+	identity string
 	client   client.Client
 	reader   client.Reader
 	recorder record.EventRecorder
@@ -89,7 +98,7 @@ func (m *clusterManager) update(name types.NamespacedName, noStart bool, origin
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	p = newManagerProcess(m.client, m.reader, m.recorder, m.dbf, m.agentf, name, cancel)
+	p = newManagerProcess(m.identity, m.client, m.reader, m.recorder, m.dbf, m.agentf, name, cancel)
 	m.wg.Add(1)
 	go func() {
 		p.Start(ctx, m.log.WithName(key), m.interval)
@@ -137,3 +146,14 @@ func (m *clusterManager) Pause(name types.NamespacedName) {
 		delete(m.processes, key)
 	}
 }
+
+func (m *clusterManager) DumpState(name types.NamespacedName) *ClusterStateDump {
+	m.mu.Lock()
+	p, ok := m.processes[name.String()]
+	m.mu.Unlock()
+
+	if !ok {
+		return nil
+	}
+	return p.Dump()
+}
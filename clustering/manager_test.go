@@ -2,21 +2,29 @@ package clustering
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"fmt"
+	"math"
 	"os"
+	"strconv"
+	"sync"
 	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/dbop"
 	"github.com/cybozu-go/moco/pkg/event"
 	"github.com/cybozu-go/moco/pkg/metrics"
+	"github.com/cybozu-go/moco/pkg/password"
 	"github.com/go-logr/stdr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -357,6 +365,14 @@ var _ = Describe("manager", func() {
 		}).Should(Succeed())
 
 		Expect(cluster.Status.Cloned).To(BeTrue())
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.ReplicationSource).NotTo(BeNil())
+			g.Expect(cluster.Status.ReplicationSource.Host).To(Equal("external"))
+			g.Expect(cluster.Status.ReplicationSource.IOThreadRunning).To(BeTrue())
+			g.Expect(cluster.Status.ReplicationSource.SQLThreadRunning).To(BeTrue())
+		}).Should(Succeed())
 
 		events := &corev1.EventList{}
 		err = k8sClient.List(ctx, events, client.InNamespace("test"))
@@ -566,6 +582,12 @@ var _ = Describe("manager", func() {
 			g.Expect(st.GlobalVariables.ReadOnly).To(BeFalse(), "the primary is still read-only")
 		}).Should(Succeed())
 
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.ReplicationSource).To(BeNil())
+		}).Should(Succeed())
+
 		// pods' metadata should not be changed
 		Consistently(func(g Gomega) {
 			for i := 0; i < 3; i++ {
@@ -759,6 +781,91 @@ var _ = Describe("manager", func() {
 		}
 	})
 
+	It("should postpone failover while the most advanced replica is ineligible", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		// wait for cluster's condition changes
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		By("making instance 2 ineligible")
+		pod := &corev1.Pod{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PodName(2)}, pod)
+		Expect(err).NotTo(HaveOccurred())
+		pod.Status.Conditions = []corev1.PodCondition{
+			{Type: corev1.PodReady, Status: corev1.ConditionTrue, LastTransitionTime: metav1.Now()},
+		}
+		err = k8sClient.Status().Update(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cluster, err := testGetCluster(ctx)
+			if err != nil {
+				return err
+			}
+			cluster.Spec.MinReadySeconds = 3600
+			return k8sClient.Update(ctx, cluster)
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.IneligibleReplicaList).To(Equal([]int{2}))
+		}).Should(Succeed())
+
+		By("triggering a failover")
+		testSetGTID(cluster.PodHostname(0), "p0:1,p0:2,p0:3") // primary
+		testSetGTID(cluster.PodHostname(1), "p0:1")
+		testSetGTID(cluster.PodHostname(2), "p0:1") // the most advanced replica
+		of.setRetrievedGTIDSet(cluster.PodHostname(1), "p0:1")
+		of.setRetrievedGTIDSet(cluster.PodHostname(2), "p0:1,p0:2,p0:3")
+		of.setFailing(cluster.PodHostname(0), true)
+
+		Consistently(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).To(Equal(0), "a less advanced replica is promoted")
+		}, 5*time.Second).Should(Succeed())
+		Expect(ms.failoverCount).To(MetricsIs("==", 0))
+
+		By("making instance 2 eligible")
+		Eventually(func() error {
+			cluster, err := testGetCluster(ctx)
+			if err != nil {
+				return err
+			}
+			cluster.Spec.MinReadySeconds = 0
+			return k8sClient.Update(ctx, cluster)
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).To(Equal(2), "the primary is not switched yet")
+		}).Should(Succeed())
+
+		st2 := of.getInstanceStatus(cluster.PodHostname(2))
+		Expect(st2.GlobalVariables.ExecutedGTID).To(Equal("p0:1,p0:2,p0:3"))
+		Expect(ms.failoverCount).To(MetricsIs("==", 1))
+	})
+
 	It("should handle errant replicas and lost", func() {
 		testSetupResources(ctx, 5, "")
 
@@ -980,6 +1087,191 @@ var _ = Describe("manager", func() {
 		}).Should(Succeed())
 	})
 
+	It("should allow only one of concurrent attempts to hold the operation lease", func() {
+		testSetupResources(ctx, 1, "")
+
+		name := types.NamespacedName{Namespace: "test", Name: "test"}
+		procs := make([]*managerProcess, 5)
+		for i := range procs {
+			procs[i] = newManagerProcess(fmt.Sprintf("controller-%d", i), mgr.GetClient(), mgr.GetAPIReader(), nil, of, af, name, func() {})
+		}
+
+		results := make([]bool, len(procs))
+		var wg sync.WaitGroup
+		for i := range procs {
+			i := i
+			wg.Add(1)
+			go func() {
+				defer GinkgoRecover()
+				defer wg.Done()
+				ok, err := procs[i].tryAcquireOperationLease(ctx)
+				Expect(err).NotTo(HaveOccurred())
+				results[i] = ok
+			}()
+		}
+		wg.Wait()
+
+		winner := -1
+		for i, ok := range results {
+			if ok {
+				Expect(winner).To(Equal(-1), "two processes acquired the lease")
+				winner = i
+			}
+		}
+		Expect(winner).NotTo(Equal(-1))
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Annotations).To(HaveKeyWithValue(constants.AnnOperationHolder, procs[winner].identity))
+		Expect(cluster.Annotations).To(HaveKey(constants.AnnOperationLeaseExpires))
+
+		By("checking that the lease can be renewed only by the holder")
+		for i, p := range procs {
+			ok, err := p.tryAcquireOperationLease(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(Equal(i == winner), "process %d", i)
+		}
+
+		By("releasing the lease")
+		loser := (winner + 1) % len(procs)
+		err = procs[loser].releaseOperationLease(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Annotations).To(HaveKeyWithValue(constants.AnnOperationHolder, procs[winner].identity))
+
+		err = procs[winner].releaseOperationLease(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Annotations).NotTo(HaveKey(constants.AnnOperationHolder))
+
+		ok, err := procs[loser].tryAcquireOperationLease(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		By("taking over an expired lease")
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Annotations[constants.AnnOperationLeaseExpires] = time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		ok, err = procs[winner].tryAcquireOperationLease(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+	})
+
+	It("should keep the operation lease while the cluster is updated by others", func() {
+		testSetupResources(ctx, 1, "")
+
+		name := types.NamespacedName{Namespace: "test", Name: "test"}
+		proc := newManagerProcess("controller-0", mgr.GetClient(), mgr.GetAPIReader(), nil, of, af, name, func() {})
+		ok, err := proc.tryAcquireOperationLease(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ok).To(BeTrue())
+
+		updateCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; updateCtx.Err() == nil; i++ {
+				cluster, err := testGetCluster(updateCtx)
+				if err != nil {
+					continue
+				}
+				if cluster.Labels == nil {
+					cluster.Labels = make(map[string]string)
+				}
+				cluster.Labels["foo"] = strconv.Itoa(i)
+				_ = k8sClient.Update(updateCtx, cluster)
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+
+		for i := 0; i < 20; i++ {
+			ok, err := proc.tryAcquireOperationLease(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue())
+		}
+		cancel()
+		wg.Wait()
+	})
+
+	It("should not failover while another controller holds the operation lease", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+			Eventually(func(g Gomega) {
+				ch := make(chan prometheus.Metric, 2)
+				metrics.ErrantReplicasVec.Collect(ch)
+				g.Expect(ch).NotTo(Receive())
+			}).Should(Succeed())
+		}()
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		By("holding the operation lease by another controller")
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			if cluster.Annotations == nil {
+				cluster.Annotations = make(map[string]string)
+			}
+			cluster.Annotations[constants.AnnOperationHolder] = "another-controller"
+			cluster.Annotations[constants.AnnOperationLeaseExpires] = time.Now().Add(time.Hour).UTC().Format(time.RFC3339)
+			g.Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+		}).Should(Succeed())
+
+		By("making the primary fail")
+		testSetGTID(cluster.PodHostname(0), "p0:1,p0:2,p0:3")
+		testSetGTID(cluster.PodHostname(1), "p0:1,p0:2,p0:3")
+		testSetGTID(cluster.PodHostname(2), "p0:1,p0:2,p0:3")
+		of.setRetrievedGTIDSet(cluster.PodHostname(1), "p0:1,p0:2,p0:3")
+		of.setRetrievedGTIDSet(cluster.PodHostname(2), "p0:1,p0:2,p0:3")
+		of.setFailing(cluster.PodHostname(0), true)
+
+		Consistently(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).To(Equal(0))
+		}, 5*time.Second).Should(Succeed())
+		Expect(ms.failoverCount).To(MetricsIs("==", 0))
+
+		By("expiring the lease")
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			cluster.Annotations[constants.AnnOperationLeaseExpires] = time.Now().Add(-time.Second).UTC().Format(time.RFC3339)
+			g.Expect(k8sClient.Update(ctx, cluster)).To(Succeed())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).NotTo(Equal(0))
+			g.Expect(cluster.Annotations).NotTo(HaveKey(constants.AnnOperationHolder))
+		}).Should(Succeed())
+		Expect(ms.failoverCount).To(MetricsIs("==", 1))
+	})
+
 	It("should export backup related metrics", func() {
 		testSetupResources(ctx, 1, "")
 
@@ -1013,4 +1305,717 @@ var _ = Describe("manager", func() {
 		Expect(ms.backupWorkDirUsage).To(MetricsIs("==", 30))
 		Expect(ms.backupWarnings).To(MetricsIs("==", 2))
 	})
+
+	It("should export the replication lag of each instance", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		of.setSecondsBehindMaster(cluster.PodHostname(1), sql.NullInt64{Int64: 42, Valid: true})
+		of.setSecondsBehindMaster(cluster.PodHostname(2), sql.NullInt64{})
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+
+		Eventually(func() interface{} {
+			return metrics.ReplicationLagVec.WithLabelValues("test", "test", cluster.PodName(1))
+		}).Should(MetricsIs("==", 42))
+		Expect(math.IsNaN(testutil.ToFloat64(metrics.ReplicationLagVec.WithLabelValues("test", "test", cluster.PodName(2))))).To(BeTrue())
+
+		// the primary does not replicate, so it should not have the metric.
+		Expect(metrics.ReplicationLagVec.DeleteLabelValues("test", "test", cluster.PodName(0))).To(BeFalse())
+
+		By("stopping the manager process")
+		cm.Stop(client.ObjectKeyFromObject(cluster))
+		Eventually(func(g Gomega) {
+			g.Expect(testutil.CollectAndCount(metrics.ReplicationLagVec)).To(Equal(0))
+		}).Should(Succeed())
+	})
+
+	It("should publish the role of each instance", func() {
+		testSetupResources(ctx, 3, "")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PublishInstanceRoles = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func() []dbop.InstanceRole {
+			return of.getInstanceRoles(cluster.PodHostname(0))
+		}).Should(Equal([]dbop.InstanceRole{
+			{ServerID: 10, Role: constants.RolePrimary},
+			{ServerID: 11, Role: constants.RoleReplica},
+			{ServerID: 12, Role: constants.RoleReplica},
+		}))
+
+		By("triggering a failover")
+		testSetGTID(cluster.PodHostname(0), "p0:1,p0:2,p0:3") // primary
+		testSetGTID(cluster.PodHostname(1), "p0:1")           // new primary
+		testSetGTID(cluster.PodHostname(2), "p0:1,p0:2,p0:3")
+		of.setRetrievedGTIDSet(cluster.PodHostname(1), "p0:1,p0:2,p0:3")
+		of.setRetrievedGTIDSet(cluster.PodHostname(2), "p0:1,p0:2,p0:3")
+		of.setFailing(cluster.PodHostname(0), true)
+
+		Eventually(func() []dbop.InstanceRole {
+			return of.getInstanceRoles(cluster.PodHostname(1))
+		}).Should(Equal([]dbop.InstanceRole{
+			{ServerID: 10, Role: constants.RoleReplica},
+			{ServerID: 11, Role: constants.RolePrimary},
+			{ServerID: 12, Role: constants.RoleReplica},
+		}))
+	})
+
+	It("should drain connections before switching over a deleted primary", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		By("deleting the primary Pod having connections")
+		of.setConnections(cluster.PodHostname(0), 3)
+
+		pod0 := &corev1.Pod{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PodName(0)}, pod0)
+		Expect(err).NotTo(HaveOccurred())
+		// keep the Pod terminating as kubelet does while running the preStop hook.
+		pod0.Finalizers = []string{"moco.cybozu.com/test"}
+		err = k8sClient.Update(ctx, pod0)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			pod := &corev1.Pod{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PodName(0)}, pod); err != nil {
+				return
+			}
+			pod.Finalizers = nil
+			k8sClient.Update(ctx, pod)
+		}()
+		err = k8sClient.Delete(ctx, pod0)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).NotTo(Equal(0), "the primary is not switched yet")
+		}).Should(Succeed())
+
+		Expect(of.getConnections(cluster.PodHostname(0))).To(Equal(0))
+		Expect(of.getKillConnectionsCount(cluster.PodHostname(0))).To(BeNumerically(">=", 1))
+		Expect(ms.switchoverCount).To(MetricsIs("==", 1))
+	})
+
+	It("should resolve split-brain", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+			condSplitBrain, err := testGetCondition(cluster, mocov1beta2.ConditionSplitBrainDetected)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condSplitBrain.Status).To(Equal(metav1.ConditionFalse))
+		}).Should(Succeed())
+
+		By("making a replica writable without diverging")
+		of.resetKillConnectionsCount()
+		testSetGTID(cluster.PodHostname(0), "p0:1,p0:2")
+		testSetGTID(cluster.PodHostname(1), "p0:1")
+		testSetGTID(cluster.PodHostname(2), "p0:1,p0:2")
+		of.setWritable(cluster.PodHostname(1))
+
+		Eventually(func(g Gomega) {
+			st := of.getInstanceStatus(cluster.PodHostname(1))
+			g.Expect(st).NotTo(BeNil())
+			g.Expect(st.GlobalVariables.SuperReadOnly).To(BeTrue())
+		}).Should(Succeed())
+
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Status.CurrentPrimaryIndex).To(Equal(0))
+		Expect(of.getKillConnectionsCount(cluster.PodHostname(1))).To(Equal(1))
+		st0 := of.getInstanceStatus(cluster.PodHostname(0))
+		Expect(st0.GlobalVariables.ReadOnly).To(BeFalse())
+
+		By("making a replica writable with diverged data")
+		of.resetKillConnectionsCount()
+		testSetGTID(cluster.PodHostname(0), "p0:1,p0:2,p0:3")
+		testSetGTID(cluster.PodHostname(1), "p0:1,p0:2")
+		testSetGTID(cluster.PodHostname(2), "p0:1,p0:2,p2:1")
+		of.setWritable(cluster.PodHostname(2))
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			condSplitBrain, err := testGetCondition(cluster, mocov1beta2.ConditionSplitBrainDetected)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condSplitBrain.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		Consistently(func(g Gomega) {
+			for _, i := range []int{0, 2} {
+				st := of.getInstanceStatus(cluster.PodHostname(i))
+				g.Expect(st).NotTo(BeNil())
+				g.Expect(st.GlobalVariables.ReadOnly).To(BeFalse())
+			}
+		}, 3*time.Second).Should(Succeed())
+
+		By("recording the events only when the split-brain changes")
+		events := &corev1.EventList{}
+		err = k8sClient.List(ctx, events, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		counts := make(map[string]int32)
+		for _, ev := range events.Items {
+			counts[ev.Reason] += ev.Count
+		}
+		Expect(counts[event.SplitBrainDetected.Reason]).To(BeNumerically("==", 2))
+		Expect(counts[event.SplitBrainDiverged.Reason]).To(BeNumerically("==", 1))
+
+		By("choosing the instance to keep")
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		if cluster.Annotations == nil {
+			cluster.Annotations = make(map[string]string)
+		}
+		cluster.Annotations[constants.AnnSplitBrainSurvivor] = "2"
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CurrentPrimaryIndex).To(Equal(2))
+			g.Expect(cluster.Annotations).NotTo(HaveKey(constants.AnnSplitBrainSurvivor))
+			condSplitBrain, err := testGetCondition(cluster, mocov1beta2.ConditionSplitBrainDetected)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condSplitBrain.Status).To(Equal(metav1.ConditionFalse))
+		}).Should(Succeed())
+
+		st0 = of.getInstanceStatus(cluster.PodHostname(0))
+		Expect(st0.GlobalVariables.SuperReadOnly).To(BeTrue())
+		Expect(of.getKillConnectionsCount(cluster.PodHostname(0))).To(BeNumerically(">=", 1))
+	})
+
+	It("should manage users in spec.users", func() {
+		testSetupResources(ctx, 1, "")
+
+		secret := &corev1.Secret{}
+		secret.Namespace = "test"
+		secret.Name = "app-password"
+		secret.Data = map[string][]byte{password.UserPasswordKey: []byte("foo")}
+		err := k8sClient.Create(ctx, secret)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Users = []mocov1beta2.UserSpec{
+			{Name: "app", Grants: []string{"SELECT ON app.*"}, PasswordSecretName: "app-password"},
+		}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func(g Gomega) {
+			users := of.getUsers(cluster.PodHostname(0))
+			g.Expect(users).To(HaveKey("app"))
+			g.Expect(users["app"].password).To(Equal("foo"))
+			g.Expect(users["app"].grants).To(Equal([]string{"SELECT ON app.*"}))
+
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Users).To(HaveLen(1))
+			g.Expect(cluster.Status.Users[0].Name).To(Equal("app"))
+		}).Should(Succeed())
+
+		By("changing the password")
+		secret.Data[password.UserPasswordKey] = []byte("bar")
+		err = k8sClient.Update(ctx, secret)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			users := of.getUsers(cluster.PodHostname(0))
+			g.Expect(users).To(HaveKey("app"))
+			g.Expect(users["app"].password).To(Equal("bar"))
+		}).Should(Succeed())
+
+		By("removing the user from spec")
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Users = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			users := of.getUsers(cluster.PodHostname(0))
+			g.Expect(users).NotTo(HaveKey("app"))
+
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Users).To(BeEmpty())
+		}).Should(Succeed())
+	})
+
+	It("should create databases in spec.databases", func() {
+		testSetupResources(ctx, 1, "")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Databases = []string{"app", "log"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Databases).To(Equal([]string{"app", "log"}))
+		}).Should(Succeed())
+
+		By("checking databases are created only once")
+		time.Sleep(2 * time.Second)
+		Expect(of.getDatabases(cluster.PodHostname(0))).To(Equal(map[string]int{"app": 1, "log": 1}))
+
+		By("removing a database from spec")
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Databases = []string{"app"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Databases).To(Equal([]string{"app"}))
+		}).Should(Succeed())
+		Expect(of.getDatabases(cluster.PodHostname(0))).To(Equal(map[string]int{"app": 1, "log": 1}))
+	})
+
+	It("should rotate the passwords of the users for MOCO", func() {
+		testSetupResources(ctx, 3, "")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		newPasswd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+		pending := newPasswd.ToSecret()
+		pending.Namespace = "test"
+		pending.Name = cluster.RotatingPasswordSecretName()
+		err = k8sClient.Create(ctx, pending)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.PasswordRotation).NotTo(BeNil())
+			g.Expect(cluster.Status.PasswordRotation.Retained).To(BeTrue())
+		}).Should(Succeed())
+		passwords := of.getPasswords(cluster.PodHostname(0))
+		Expect(passwords.admin).To(Equal(newPasswd.Admin()))
+		Expect(passwords.retained).To(BeTrue())
+
+		By("updating the Secrets as the controller does")
+		err = k8sClient.Delete(ctx, pending)
+		Expect(err).NotTo(HaveOccurred())
+		userSecret := &corev1.Secret{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.UserSecretName()}, userSecret)
+		Expect(err).NotTo(HaveOccurred())
+		userSecret.Annotations = map[string]string{constants.AnnPasswordRevision: "rev1"}
+		err = k8sClient.Update(ctx, userSecret)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the old passwords are retained until the pods are restarted")
+		Consistently(func() int {
+			return of.getPasswords(cluster.PodHostname(0)).discarded
+		}, 3*time.Second).Should(Equal(0))
+
+		for i := 0; i < 3; i++ {
+			pod := &corev1.Pod{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PodName(i)}, pod)
+			Expect(err).NotTo(HaveOccurred())
+			pod.Annotations = map[string]string{constants.AnnPasswordRevision: "rev1"}
+			err = k8sClient.Update(ctx, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.PasswordRotation).NotTo(BeNil())
+			g.Expect(cluster.Status.PasswordRotation.Retained).To(BeFalse())
+			g.Expect(cluster.Status.PasswordRotation.LastRotationTime).NotTo(BeNil())
+		}).Should(Succeed())
+		passwords = of.getPasswords(cluster.PodHostname(0))
+		Expect(passwords.retained).To(BeFalse())
+		Expect(passwords.discarded).To(Equal(1))
+
+		Eventually(func(g Gomega) {
+			events := &corev1.EventList{}
+			err := k8sClient.List(ctx, events, client.InNamespace("test"))
+			g.Expect(err).NotTo(HaveOccurred())
+			var rotated bool
+			for _, ev := range events.Items {
+				if ev.Reason == event.PasswordsRotated.Reason {
+					rotated = true
+				}
+			}
+			g.Expect(rotated).To(BeTrue())
+		}).Should(Succeed())
+	})
+
+	It("should wait for the backoff before retrying to clone data", func() {
+		testSetupResources(ctx, 1, "source")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.CloneRetryBackoff = &metav1.Duration{Duration: time.Hour}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		By("checking the failure is recorded")
+		// the clone fails because the source secret does not exist.
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CloneFailures).To(HaveLen(1))
+			g.Expect(cluster.Status.CloneFailures[0].Index).To(Equal(0))
+			g.Expect(cluster.Status.CloneFailures[0].Count).To(Equal(1))
+			g.Expect(cluster.Status.CloneFailures[0].LastError).To(ContainSubstring("source"))
+		}).Should(Succeed())
+
+		By("checking the clone is not retried during the backoff")
+		Consistently(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CloneFailures).To(HaveLen(1))
+			g.Expect(cluster.Status.CloneFailures[0].Count).To(Equal(1))
+		}, 3).Should(Succeed())
+
+		By("retrying after the backoff")
+		cluster, err = testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.CloneRetryBackoff = &metav1.Duration{Duration: time.Second}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CloneFailures).To(HaveLen(1))
+			g.Expect(cluster.Status.CloneFailures[0].Count).To(BeNumerically(">", 1))
+		}).Should(Succeed())
+
+		sourceSecret := &corev1.Secret{}
+		sourceSecret.Namespace = "test"
+		sourceSecret.Name = "source"
+		sourceSecret.Data = map[string][]byte{
+			constants.CloneSourceHostKey:         []byte("external"),
+			constants.CloneSourcePortKey:         []byte("3306"),
+			constants.CloneSourceUserKey:         []byte("external-donor"),
+			constants.CloneSourcePasswordKey:     []byte("p1"),
+			constants.CloneSourceInitUserKey:     []byte("external-init"),
+			constants.CloneSourceInitPasswordKey: []byte("init"),
+		}
+		err = k8sClient.Create(ctx, sourceSecret)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("checking the failure is cleared after a successful clone")
+		Eventually(func(g Gomega) {
+			cluster, err := testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Cloned).To(BeTrue())
+			g.Expect(cluster.Status.CloneFailures).To(BeEmpty())
+		}).Should(Succeed())
+	})
+
+	It("should keep replicating from the cluster in spec.replicationSource", func() {
+		testSetupResources(ctx, 3, "")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "source"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		// the Secret is generated by the controller from the source cluster.
+		sourceSecret := &corev1.Secret{}
+		sourceSecret.Namespace = "test"
+		sourceSecret.Name = cluster.SourceClusterSecretName()
+		sourceSecret.Data = map[string][]byte{
+			constants.CloneSourceHostKey:         []byte("external"),
+			constants.CloneSourcePortKey:         []byte("3306"),
+			constants.CloneSourceUserKey:         []byte("external-donor"),
+			constants.CloneSourcePasswordKey:     []byte("p1"),
+			constants.CloneSourceInitUserKey:     []byte("external-init"),
+			constants.CloneSourceInitPasswordKey: []byte("init"),
+		}
+		err = k8sClient.Create(ctx, sourceSecret)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		By("checking the cluster to become healthy")
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.Cloned).To(BeTrue())
+			g.Expect(cluster.Status.SyncedReplicas).To(Equal(3))
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+
+			g.Expect(cluster.Status.ReplicationSource).NotTo(BeNil())
+			g.Expect(cluster.Status.ReplicationSource.Host).To(Equal("external"))
+			g.Expect(cluster.Status.ReplicationSource.IOThreadRunning).To(BeTrue())
+		}).Should(Succeed())
+
+		primary := cluster.Status.CurrentPrimaryIndex
+		for i := 0; i < 3; i++ {
+			st := of.getInstanceStatus(cluster.PodHostname(i))
+			Expect(st).NotTo(BeNil())
+			Expect(st.GlobalVariables.SuperReadOnly).To(BeTrue())
+			Expect(st.ReplicaStatus).NotTo(BeNil())
+			if i == primary {
+				Expect(st.ReplicaStatus.MasterHost).To(Equal("external"))
+				Expect(st.GlobalVariables.SemiSyncSlaveEnabled).To(BeFalse())
+			} else {
+				Expect(st.ReplicaStatus.MasterHost).To(Equal(cluster.PodHostname(primary)))
+			}
+		}
+
+		By("restarting the replication broken by a failover of the source")
+		of.breakReplicaIOThread(cluster.PodHostname(primary), "error reconnecting to source")
+		Eventually(func(g Gomega) {
+			st := of.getInstanceStatus(cluster.PodHostname(primary))
+			g.Expect(st).NotTo(BeNil())
+			g.Expect(st.ReplicaStatus.SlaveIORunning).To(Equal("Yes"))
+
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.ReplicationSource.IOThreadRunning).To(BeTrue())
+			g.Expect(cluster.Status.ReplicationSource.LastIOError).To(BeEmpty())
+		}).Should(Succeed())
+
+		// the primary never becomes writable.
+		Consistently(func(g Gomega) {
+			st := of.getInstanceStatus(cluster.PodHostname(primary))
+			g.Expect(st).NotTo(BeNil())
+			g.Expect(st.GlobalVariables.SuperReadOnly).To(BeTrue())
+		}, 3).Should(Succeed())
+	})
+
+	It("should keep the primary of a point-in-time clone read-only", func() {
+		testSetupResources(ctx, 3, "")
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:       "source",
+			SourceNamespace:  "source",
+			RestorePoint:     metav1.Now(),
+			PointInTimeClone: &mocov1beta2.PointInTimeCloneSpec{TTLSecondsAfterRestored: 3600},
+		}
+		cluster.Spec.Restore.JobConfig.ServiceAccountName = "foo"
+		cluster.Spec.Restore.JobConfig.BucketConfig.BucketName = "mybucket"
+		cluster.Spec.Restore.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		cluster.Spec.PublishInstanceRoles = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cm.Update(client.ObjectKeyFromObject(cluster), "test")
+		defer func() {
+			cm.Stop(client.ObjectKeyFromObject(cluster))
+			time.Sleep(400 * time.Millisecond)
+		}()
+
+		By("completing the restoration")
+		Eventually(func() error {
+			cluster, err := testGetCluster(ctx)
+			if err != nil {
+				return err
+			}
+			now := metav1.Now()
+			cluster.Status.RestoredTime = &now
+			return k8sClient.Status().Update(ctx, cluster)
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.SyncedReplicas).To(Equal(3))
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		// the primary never becomes writable, and nothing is written to it.
+		primary := cluster.Status.CurrentPrimaryIndex
+		Consistently(func(g Gomega) {
+			st := of.getInstanceStatus(cluster.PodHostname(primary))
+			g.Expect(st).NotTo(BeNil())
+			g.Expect(st.GlobalVariables.SuperReadOnly).To(BeTrue())
+			g.Expect(of.getInstanceRoles(cluster.PodHostname(primary))).To(BeEmpty())
+		}, 3).Should(Succeed())
+
+		events := &corev1.EventList{}
+		err = k8sClient.List(ctx, events, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		for _, ev := range events.Items {
+			Expect(ev.Reason).NotTo(Equal(event.SetWritable.Reason))
+		}
+	})
+
+	It("should dump the in-memory state of the cluster", func() {
+		testSetupResources(ctx, 3, "")
+
+		cm := NewClusterManager(1*time.Second, mgr, of, af, stdr.New(nil))
+		defer cm.StopAll()
+
+		cluster, err := testGetCluster(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		key := client.ObjectKeyFromObject(cluster)
+		Expect(cm.DumpState(key)).To(BeNil())
+		cm.Update(key, "test")
+
+		Eventually(func(g Gomega) {
+			cluster, err = testGetCluster(ctx)
+			g.Expect(err).NotTo(HaveOccurred())
+
+			condHealthy, err := testGetCondition(cluster, mocov1beta2.ConditionHealthy)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(condHealthy.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		of.setSecondsBehindMaster(cluster.PodHostname(1), sql.NullInt64{Int64: 42, Valid: true})
+		cm.Update(key, "test")
+
+		Eventually(func(g Gomega) {
+			state := cm.DumpState(key)
+			g.Expect(state).NotTo(BeNil())
+			g.Expect(state.Namespace).To(Equal("test"))
+			g.Expect(state.Name).To(Equal("test"))
+			g.Expect(state.State).To(Equal(StateHealthy.String()))
+			g.Expect(state.Primary).To(Equal(0))
+			g.Expect(state.Errants).To(BeEmpty())
+			g.Expect(state.Instances).To(HaveLen(3))
+
+			primary := state.Instances[0]
+			g.Expect(primary.Pod).To(Equal(cluster.PodName(0)))
+			g.Expect(primary.Ready).To(BeTrue())
+			g.Expect(primary.Reachable).To(BeTrue())
+			g.Expect(primary.ReadOnly).To(BeFalse())
+			g.Expect(primary.SourceHost).To(BeEmpty())
+
+			replica := state.Instances[1]
+			g.Expect(replica.Pod).To(Equal(cluster.PodName(1)))
+			g.Expect(replica.SuperReadOnly).To(BeTrue())
+			g.Expect(replica.SourceHost).To(Equal(cluster.PodHostname(0)))
+			g.Expect(replica.SecondsBehindSource).To(HaveValue(BeEquivalentTo(42)))
+
+			g.Expect(state.LastOperation).NotTo(BeNil())
+			g.Expect(state.LastOperation.ID).NotTo(BeEmpty())
+			g.Expect(state.LastOperation.Error).To(BeEmpty())
+		}).Should(Succeed())
+
+		By("stopping the manager process")
+		cm.Stop(key)
+		Expect(cm.DumpState(key)).To(BeNil())
+	})
 })
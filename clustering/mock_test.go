@@ -2,12 +2,14 @@ package clustering
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	agent "github.com/cybozu-go/moco-agent/proto"
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
@@ -292,10 +294,11 @@ func (o *mockOperator) ConfigureReplica(ctx context.Context, source dbop.AccessI
 
 	gtid, _ := testGetGTID(source.Host)
 	o.mysql.status.ReplicaStatus = &dbop.ReplicaStatus{
-		MasterHost:       source.Host,
-		RetrievedGtidSet: gtid,
-		SlaveIORunning:   "Yes",
-		SlaveSQLRunning:  "Yes",
+		MasterHost:          source.Host,
+		RetrievedGtidSet:    gtid,
+		SlaveIORunning:      "Yes",
+		SlaveSQLRunning:     "Yes",
+		SecondsBehindMaster: sql.NullInt64{Valid: true},
 	}
 	o.mysql.status.GlobalVariables.SemiSyncSlaveEnabled = semisync
 	return setPodReadiness(ctx, o.cluster.PodName(o.index), true)
@@ -303,7 +306,7 @@ func (o *mockOperator) ConfigureReplica(ctx context.Context, source dbop.AccessI
 
 // ConfigurePrimary configures server-side semi-synchronous replication.
 // For asynchronous replication, this method should not be called.
-func (o *mockOperator) ConfigurePrimary(ctx context.Context, waitForCount int) error {
+func (o *mockOperator) ConfigurePrimary(ctx context.Context, waitForCount int, timeout time.Duration) error {
 	if o.failing {
 		return errors.New("mysqld is down")
 	}
@@ -311,6 +314,7 @@ func (o *mockOperator) ConfigurePrimary(ctx context.Context, waitForCount int) e
 	defer o.mysql.mu.Unlock()
 
 	o.mysql.status.GlobalVariables.WaitForSlaveCount = waitForCount
+	o.mysql.status.GlobalVariables.SemiSyncMasterTimeout = timeout.Milliseconds()
 	o.mysql.status.GlobalVariables.SemiSyncMasterEnabled = true
 	return nil
 }
@@ -405,6 +409,21 @@ func (o *mockOperator) SetReadOnly(ctx context.Context, readonly bool) error {
 	return setPodReadiness(ctx, o.cluster.PodName(o.index), true)
 }
 
+// CountConnections returns the number of connections set by setConnections.
+// Each call closes one connection to emulate draining.
+func (o *mockOperator) CountConnections(ctx context.Context) (int, error) {
+	if o.failing {
+		return 0, errors.New("mysqld is down")
+	}
+	o.factory.mu.Lock()
+	defer o.factory.mu.Unlock()
+	n := o.factory.connections[o.Name()]
+	if n > 0 {
+		o.factory.connections[o.Name()] = n - 1
+	}
+	return n, nil
+}
+
 func (o *mockOperator) KillConnections(ctx context.Context) error {
 	if o.failing {
 		return errors.New("mysqld is down")
@@ -415,9 +434,122 @@ func (o *mockOperator) KillConnections(ctx context.Context) error {
 	return nil
 }
 
+func (o *mockOperator) UpdateInstanceRoles(ctx context.Context, roles []dbop.InstanceRole) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("updateInstanceRoles: the instance is read-only")
+	}
+	o.mysql.instanceRoles = make([]dbop.InstanceRole, len(roles))
+	copy(o.mysql.instanceRoles, roles)
+	return nil
+}
+
+func (o *mockOperator) ApplyUser(ctx context.Context, user, password string, grants []string) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("applyUser: the instance is read-only")
+	}
+	if o.mysql.users == nil {
+		o.mysql.users = make(map[string]mockUser)
+	}
+	o.mysql.users[user] = mockUser{password: password, grants: append([]string(nil), grants...)}
+	return nil
+}
+
+func (o *mockOperator) DropUser(ctx context.Context, user string) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("dropUser: the instance is read-only")
+	}
+	delete(o.mysql.users, user)
+	return nil
+}
+
+func (o *mockOperator) RotatePasswords(ctx context.Context, passwd *password.MySQLPassword) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("rotatePasswords: the instance is read-only")
+	}
+	if !o.mysql.passwords.retained {
+		o.mysql.passwords.admin = passwd.Admin()
+		o.mysql.passwords.retained = true
+	}
+	return nil
+}
+
+func (o *mockOperator) DiscardOldPasswords(ctx context.Context) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("discardOldPasswords: the instance is read-only")
+	}
+	if o.mysql.passwords.retained {
+		o.mysql.passwords.retained = false
+		o.mysql.passwords.discarded++
+	}
+	return nil
+}
+
+func (o *mockOperator) CreateDatabase(ctx context.Context, name string) error {
+	if o.failing {
+		return errors.New("mysqld is down")
+	}
+	o.mysql.mu.Lock()
+	defer o.mysql.mu.Unlock()
+
+	if o.mysql.status.GlobalVariables.ReadOnly {
+		return errors.New("createDatabase: the instance is read-only")
+	}
+	if o.mysql.databases == nil {
+		o.mysql.databases = make(map[string]int)
+	}
+	o.mysql.databases[name]++
+	return nil
+}
+
+type mockUser struct {
+	password string
+	grants   []string
+}
+
+// mockPasswords records the rotation of the passwords of the users for MOCO.
+type mockPasswords struct {
+	admin     string // the new password of moco-admin
+	retained  bool
+	discarded int // the number of DiscardOldPasswords calls that discarded the old passwords
+}
+
 type mockMySQL struct {
-	mu     sync.Mutex
-	status dbop.MySQLInstanceStatus
+	mu            sync.Mutex
+	status        dbop.MySQLInstanceStatus
+	instanceRoles []dbop.InstanceRole
+	users         map[string]mockUser
+	databases     map[string]int // the number of CreateDatabase calls for each database
+	passwords     mockPasswords
 }
 
 func (m *mockMySQL) getStatus() *dbop.MySQLInstanceStatus {
@@ -447,6 +579,26 @@ func (m *mockMySQL) setRetrievedGTIDSet(gtid string) {
 	m.status.ReplicaStatus.RetrievedGtidSet = gtid
 }
 
+func (m *mockMySQL) setSecondsBehindMaster(lag sql.NullInt64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.ReplicaStatus.SecondsBehindMaster = lag
+}
+
+func (m *mockMySQL) breakReplicaIOThread(lastErr string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.ReplicaStatus.SlaveIORunning = "No"
+	m.status.ReplicaStatus.LastIoError = lastErr
+}
+
+func (m *mockMySQL) setWritable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status.GlobalVariables.ReadOnly = false
+	m.status.GlobalVariables.SuperReadOnly = false
+}
+
 type mockOpFactory struct {
 	orphaned int64
 
@@ -454,6 +606,7 @@ type mockOpFactory struct {
 	mysqls               map[string]*mockMySQL
 	failing              map[string]bool
 	countKillConnections map[string]int
+	connections          map[string]int
 }
 
 func newMockOpFactory() *mockOpFactory {
@@ -461,6 +614,7 @@ func newMockOpFactory() *mockOpFactory {
 		mysqls:               make(map[string]*mockMySQL),
 		failing:              make(map[string]bool),
 		countKillConnections: make(map[string]int),
+		connections:          make(map[string]int),
 	}
 }
 
@@ -517,6 +671,68 @@ func (f *mockOpFactory) getInstanceStatus(name string) *dbop.MySQLInstanceStatus
 	return m.getStatus()
 }
 
+func (f *mockOpFactory) getInstanceRoles(name string) []dbop.InstanceRole {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := f.mysqls[name]
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	roles := make([]dbop.InstanceRole, len(m.instanceRoles))
+	copy(roles, m.instanceRoles)
+	return roles
+}
+
+func (f *mockOpFactory) getUsers(name string) map[string]mockUser {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := f.mysqls[name]
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	users := make(map[string]mockUser)
+	for k, v := range m.users {
+		users[k] = v
+	}
+	return users
+}
+
+func (f *mockOpFactory) getPasswords(name string) mockPasswords {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := f.mysqls[name]
+	if m == nil {
+		return mockPasswords{}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.passwords
+}
+
+func (f *mockOpFactory) getDatabases(name string) map[string]int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m := f.mysqls[name]
+	if m == nil {
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	databases := make(map[string]int)
+	for k, v := range m.databases {
+		databases[k] = v
+	}
+	return databases
+}
+
 func (f *mockOpFactory) allClosed() bool {
 	return atomic.LoadInt64(&f.orphaned) == 0
 }
@@ -537,6 +753,21 @@ func (f *mockOpFactory) setRetrievedGTIDSet(name string, gtid string) {
 	m.setRetrievedGTIDSet(gtid)
 }
 
+func (f *mockOpFactory) setSecondsBehindMaster(name string, lag sql.NullInt64) {
+	m := f.getInstance(name)
+	m.setSecondsBehindMaster(lag)
+}
+
+func (f *mockOpFactory) breakReplicaIOThread(name string, lastErr string) {
+	m := f.getInstance(name)
+	m.breakReplicaIOThread(lastErr)
+}
+
+func (f *mockOpFactory) setWritable(name string) {
+	m := f.getInstance(name)
+	m.setWritable()
+}
+
 func (f *mockOpFactory) resetKillConnectionsCount() {
 	f.mu.Lock()
 	defer f.mu.Unlock()
@@ -548,3 +779,15 @@ func (f *mockOpFactory) getKillConnectionsCount(name string) int {
 	defer f.mu.Unlock()
 	return f.countKillConnections[name]
 }
+
+func (f *mockOpFactory) setConnections(name string, n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.connections[name] = n
+}
+
+func (f *mockOpFactory) getConnections(name string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.connections[name]
+}
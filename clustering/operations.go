@@ -2,9 +2,13 @@ package clustering
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"slices"
 	"strconv"
+	"sync"
 	"time"
 
 	agent "github.com/cybozu-go/moco-agent/proto"
@@ -14,6 +18,8 @@ import (
 	"github.com/cybozu-go/moco/pkg/event"
 	"google.golang.org/protobuf/types/known/durationpb"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -21,11 +27,14 @@ import (
 const (
 	switchOverTimeoutSeconds = 70
 	failOverTimeoutSeconds   = 3600
+
+	cloneSourceDialTimeout = 10 * time.Second
 )
 
 var (
 	waitForCloneRestartDuration = 3 * time.Second
 	waitForRoleChangeDuration   = 300 * time.Millisecond
+	drainPollInterval           = 500 * time.Millisecond
 )
 
 func init() {
@@ -68,7 +77,7 @@ func (p *managerProcess) isCloning(ctx context.Context, ss *StatusSet) bool {
 
 func (p *managerProcess) clone(ctx context.Context, ss *StatusSet) (bool, error) {
 	secret := &corev1.Secret{}
-	name := client.ObjectKey{Namespace: ss.Cluster.Namespace, Name: *ss.Cluster.Spec.ReplicationSourceSecretName}
+	name := client.ObjectKey{Namespace: ss.Cluster.Namespace, Name: ss.Cluster.CloneSourceSecret()}
 	if err := p.client.Get(ctx, name, secret); err != nil {
 		return false, fmt.Errorf("failed to get secret %s: %w", name.String(), err)
 	}
@@ -113,13 +122,20 @@ func (p *managerProcess) clone(ctx context.Context, ss *StatusSet) (bool, error)
 	}
 	req.BootTimeout = durationpb.New(time.Duration(ss.Cluster.Spec.StartupWaitSeconds) * time.Second)
 
+	log := logFromContext(ctx)
+	if ss.Cluster.IsAdopting() {
+		if err := checkCloneSource(ctx, req.Host, req.Port); err != nil {
+			return false, err
+		}
+		log.Info("begin adopting the external mysqld", "source", req.Host)
+	}
+
 	ag, err := p.agentf.New(ctx, ss.Cluster, ss.Primary)
 	if err != nil {
 		return false, fmt.Errorf("failed to connect to moco-agent for instance %d: %w", ss.Primary, err)
 	}
 	defer ag.Close()
 
-	log := logFromContext(ctx)
 	log.Info("begin cloning data", "source", req.Host)
 	if _, err := ag.Clone(ctx, req); err != nil {
 		log.Error(err, "clone failed", "source", req.Host)
@@ -146,10 +162,85 @@ func (p *managerProcess) clone(ctx context.Context, ss *StatusSet) (bool, error)
 	return true, nil
 }
 
+// checkCloneSource checks that the mysqld to clone the data from accepts TCP connections
+// so that an unreachable source is reported before the primary instance is touched.
+func checkCloneSource(ctx context.Context, host string, port int32) error {
+	addr := net.JoinHostPort(host, strconv.Itoa(int(port)))
+	d := &net.Dialer{Timeout: cloneSourceDialTimeout}
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to the clone source %s: %w", addr, err)
+	}
+	return conn.Close()
+}
+
+// primaryDrainTimeout returns the duration to wait for connections to the primary to finish.
+func primaryDrainTimeout(cluster *mocov1beta2.MySQLCluster) time.Duration {
+	seconds := int32(constants.PrimaryDrainTimeoutSeconds)
+	if cluster.Spec.PrimaryDrainTimeoutSeconds != nil {
+		seconds = *cluster.Spec.PrimaryDrainTimeoutSeconds
+	}
+	return time.Duration(seconds) * time.Second
+}
+
+// drainPrimary removes the role label from the primary Pod so that the primary Service
+// no longer routes new connections to it, then waits for the existing connections to finish.
+// The remaining connections are killed by the switchover afterwards.
+func (p *managerProcess) drainPrimary(ctx context.Context, ss *StatusSet) error {
+	log := logFromContext(ctx)
+
+	timeout := primaryDrainTimeout(ss.Cluster)
+	if timeout == 0 {
+		return nil
+	}
+
+	key := ss.Cluster.RoleLabelKey()
+	pod := ss.Pods[ss.Primary]
+	if _, ok := pod.Labels[key]; ok {
+		modified := pod.DeepCopy()
+		delete(modified.Labels, key)
+		if err := p.client.Patch(ctx, modified, client.MergeFrom(pod)); err != nil {
+			return fmt.Errorf("failed to remove %s label from %s/%s: %w", key, pod.Namespace, pod.Name, err)
+		}
+	}
+
+	log.Info("draining connections to the primary", "timeout", timeout)
+	op := ss.DBOps[ss.Primary]
+	deadline := time.Now().Add(timeout)
+	for {
+		n, err := op.CountConnections(ctx)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			log.Info("connections to the primary have been drained")
+			return nil
+		}
+		if !time.Now().Before(deadline) {
+			log.Info("timed out draining connections to the primary", "connections", n)
+			return nil
+		}
+
+		select {
+		case <-time.After(drainPollInterval):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 func (p *managerProcess) switchover(ctx context.Context, ss *StatusSet) error {
 	log := logFromContext(ctx)
 	log.Info("begin switchover the primary", "current", ss.Primary, "next", ss.Candidate)
 
+	// Drain connections only when the primary Pod is being deleted, e.g. by a rolling update.
+	// For a switchover requested by the demote annotation, connections are killed right away.
+	if ss.Pods[ss.Primary].DeletionTimestamp != nil {
+		if err := p.drainPrimary(ctx, ss); err != nil {
+			return fmt.Errorf("failed to drain connections to instance %d: %w", ss.Primary, err)
+		}
+	}
+
 	pdb := ss.DBOps[ss.Primary]
 	if err := pdb.SetReadOnly(ctx, true); err != nil {
 		return fmt.Errorf("failed to make instance %d read-only: %w", ss.Primary, err)
@@ -194,6 +285,10 @@ func (p *managerProcess) switchover(ctx context.Context, ss *StatusSet) error {
 	return nil
 }
 
+// errFailoverPostponed is returned when the most advanced replica has not been ready
+// for `spec.minReadySeconds` and cannot be the next primary yet.
+var errFailoverPostponed = errors.New("the most advanced replica has not been ready for spec.minReadySeconds")
+
 func (p *managerProcess) failover(ctx context.Context, ss *StatusSet) error {
 	log := logFromContext(ctx)
 	log.Info("begin failover the primary", "current", ss.Primary)
@@ -237,6 +332,10 @@ func (p *managerProcess) failover(ctx context.Context, ss *StatusSet) error {
 	if err != nil {
 		return fmt.Errorf("failed to choose the next primary: %w", err)
 	}
+	// Promoting a less advanced replica would lose the transactions acknowledged by the top runner.
+	if slices.Contains(ss.Ineligibles, candidate) {
+		return fmt.Errorf("%w: instance %d", errFailoverPostponed, candidate)
+	}
 	ss.Candidate = candidate
 
 	gtid := candidates[candidate].ReplicaStatus.RetrievedGtidSet
@@ -264,10 +363,141 @@ func (p *managerProcess) failover(ctx context.Context, ss *StatusSet) error {
 	return nil
 }
 
+// resolveSplitBrain makes all but one of the writable instances in `ss.SplitBrain` read-only.
+// The instance kept writable is the one whose executed GTID set contains those of the others.
+// If there is no such instance, the data has diverged and the transactions only in the demoted
+// instances would be lost, so the operator has to choose the instance to keep by annotating
+// the cluster with moco.cybozu.com/split-brain-survivor.
+// This returns true if the split-brain is resolved.
+func (p *managerProcess) resolveSplitBrain(ctx context.Context, ss *StatusSet) (bool, error) {
+	log := logFromContext(ctx)
+	log.Info("split-brain is detected", "writable", ss.SplitBrain)
+	// The events are recorded only when the split-brain is detected or the writable instances change.
+	changed := splitBrainChanged(ss)
+	if changed {
+		event.SplitBrainDetected.Emit(ss.Cluster, p.recorder, ss.SplitBrain)
+	}
+
+	survivor, err := splitBrainSurvivor(ctx, ss)
+	if err != nil {
+		return false, err
+	}
+	if survivor == -1 {
+		v, ok := ss.Cluster.Annotations[constants.AnnSplitBrainSurvivor]
+		if !ok {
+			log.Info("writable instances have diverged; waiting for the operator to choose the instance to keep", "writable", ss.SplitBrain)
+			if changed {
+				event.SplitBrainDiverged.Emit(ss.Cluster, p.recorder, ss.SplitBrain, constants.AnnSplitBrainSurvivor)
+			}
+			return false, nil
+		}
+		index, err := strconv.Atoi(v)
+		if err != nil || !slices.Contains(ss.SplitBrain, index) {
+			return false, fmt.Errorf("%s=%s is not the index of a writable instance", constants.AnnSplitBrainSurvivor, v)
+		}
+		survivor = index
+	}
+
+	for _, i := range ss.SplitBrain {
+		if i == survivor {
+			continue
+		}
+		if err := ss.DBOps[i].KillConnections(ctx); err != nil {
+			return false, fmt.Errorf("failed to kill connections in instance %d: %w", i, err)
+		}
+		log.Info("set super_read_only=1", "instance", i)
+		if err := ss.DBOps[i].SetReadOnly(ctx, true); err != nil {
+			return false, fmt.Errorf("failed to make instance %d read-only: %w", i, err)
+		}
+	}
+
+	if survivor != ss.Primary {
+		err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+				return err
+			}
+			cluster.Status.CurrentPrimaryIndex = survivor
+			return p.client.Status().Update(ctx, cluster)
+		})
+		if err != nil {
+			return false, fmt.Errorf("failed to set the current primary index: %w", err)
+		}
+	}
+
+	if err := p.removeSplitBrainSurvivor(ctx, ss.Cluster); err != nil {
+		return false, err
+	}
+
+	log.Info("split-brain is resolved", "primary", survivor)
+	event.SplitBrainResolved.Emit(ss.Cluster, p.recorder, survivor)
+	return true, nil
+}
+
+// splitBrainChanged returns true unless the status of the cluster, which is gathered before
+// this round, has already recorded the split-brain of the same writable instances.
+func splitBrainChanged(ss *StatusSet) bool {
+	cond := meta.FindStatusCondition(ss.Cluster.Status.Conditions, mocov1beta2.ConditionSplitBrainDetected)
+	return cond == nil || cond.Status != metav1.ConditionTrue || cond.Message != splitBrainMessage(ss.SplitBrain)
+}
+
+func splitBrainMessage(writable []int) string {
+	return fmt.Sprintf("instances %v are writable", writable)
+}
+
+// splitBrainSurvivor returns the index of the writable instance whose executed GTID set
+// contains those of all the other writable instances.  The current primary is preferred
+// if there are two or more such instances. This returns -1 if there is no such instance.
+func splitBrainSurvivor(ctx context.Context, ss *StatusSet) (int, error) {
+	candidates := make([]int, 0, len(ss.SplitBrain))
+	if slices.Contains(ss.SplitBrain, ss.Primary) {
+		candidates = append(candidates, ss.Primary)
+	}
+	for _, i := range ss.SplitBrain {
+		if i != ss.Primary {
+			candidates = append(candidates, i)
+		}
+	}
+
+OUTER:
+	for _, i := range candidates {
+		gtid := ss.MySQLStatus[i].GlobalVariables.ExecutedGTID
+		for _, j := range ss.SplitBrain {
+			if j == i {
+				continue
+			}
+			isSubset, err := ss.DBOps[i].IsSubsetGTID(ctx, ss.MySQLStatus[j].GlobalVariables.ExecutedGTID, gtid)
+			if err != nil {
+				return -1, fmt.Errorf("failed to compare GTID of instances %d and %d: %w", j, i, err)
+			}
+			if !isSubset {
+				continue OUTER
+			}
+		}
+		return i, nil
+	}
+	return -1, nil
+}
+
+// removeSplitBrainSurvivor removes moco.cybozu.com/split-brain-survivor annotation
+// so that it is not applied to a future split-brain.
+func (p *managerProcess) removeSplitBrainSurvivor(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	if _, ok := cluster.Annotations[constants.AnnSplitBrainSurvivor]; !ok {
+		return nil
+	}
+	newCluster := cluster.DeepCopy()
+	delete(newCluster.Annotations, constants.AnnSplitBrainSurvivor)
+	if err := p.client.Patch(ctx, newCluster, client.MergeFrom(cluster)); err != nil {
+		return fmt.Errorf("failed to remove %s annotation: %w", constants.AnnSplitBrainSurvivor, err)
+	}
+	return nil
+}
+
 func (p *managerProcess) removeRoleLabel(ctx context.Context, ss *StatusSet) ([]int, error) {
 	var noRoles []int
+	key := ss.Cluster.RoleLabelKey()
 	for i, pod := range ss.Pods {
-		v := pod.Labels[constants.LabelMocoRole]
+		v := pod.Labels[key]
 		if v == "" {
 			noRoles = append(noRoles, i)
 			continue
@@ -282,15 +512,16 @@ func (p *managerProcess) removeRoleLabel(ctx context.Context, ss *StatusSet) ([]
 
 		noRoles = append(noRoles, i)
 		modified := pod.DeepCopy()
-		delete(modified.Labels, constants.LabelMocoRole)
+		delete(modified.Labels, key)
 		if err := p.client.Patch(ctx, modified, client.MergeFrom(pod)); err != nil {
-			return nil, fmt.Errorf("failed to remove %s label from %s/%s: %w", constants.LabelMocoRole, pod.Namespace, pod.Name, err)
+			return nil, fmt.Errorf("failed to remove %s label from %s/%s: %w", key, pod.Namespace, pod.Name, err)
 		}
 	}
 	return noRoles, nil
 }
 
 func (p *managerProcess) addRoleLabel(ctx context.Context, ss *StatusSet, noRoles []int) error {
+	key := ss.Cluster.RoleLabelKey()
 	for _, i := range noRoles {
 		if isErrantReplica(ss, i) {
 			continue
@@ -308,9 +539,9 @@ func (p *managerProcess) addRoleLabel(ctx context.Context, ss *StatusSet, noRole
 		if modified.Labels == nil {
 			modified.Labels = make(map[string]string)
 		}
-		modified.Labels[constants.LabelMocoRole] = newValue
+		modified.Labels[key] = newValue
 		if err := p.client.Patch(ctx, modified, client.MergeFrom(pod)); err != nil {
-			return fmt.Errorf("failed to add %s label to pod %s/%s: %w", constants.LabelMocoRole, pod.Namespace, pod.Name, err)
+			return fmt.Errorf("failed to add %s label to pod %s/%s: %w", key, pod.Namespace, pod.Name, err)
 		}
 	}
 	return nil
@@ -348,7 +579,7 @@ func (p *managerProcess) configure(ctx context.Context, ss *StatusSet) (bool, er
 	}
 
 	// configure primary instance
-	if ss.Cluster.Spec.ReplicationSourceSecretName != nil {
+	if ss.Cluster.IsIntermediatePrimary() {
 		r, err := p.configureIntermediatePrimary(ctx, ss)
 		if err != nil {
 			return false, err
@@ -377,14 +608,40 @@ func (p *managerProcess) configure(ctx context.Context, ss *StatusSet) (bool, er
 		redo = redo || r
 	}
 
+	// clone data to the replicas without data
+	start, _, queued := cloneQueue(ss, time.Now())
+	if len(queued) > 0 {
+		logFromContext(ctx).Info("queued cloning data", "instances", queued, "maxConcurrentClones", ss.Cluster.Spec.MaxConcurrentClones)
+	}
+	if len(start) > 0 {
+		if err := p.cloneReplicas(ctx, ss, start); err != nil {
+			return false, err
+		}
+		redo = true
+	}
+
 	// add new role label
 	err = p.addRoleLabel(ctx, ss, noRoles)
 	if err != nil {
 		return false, err
 	}
 
+	// keep the primary of a point-in-time clone read-only
+	if ss.Cluster.IsPointInTimeClone() {
+		pst := ss.MySQLStatus[ss.Primary]
+		op := ss.DBOps[ss.Primary]
+		if !pst.GlobalVariables.SuperReadOnly {
+			redo = true
+			logFromContext(ctx).Info("set super_read_only=1", "instance", ss.Primary)
+			if err := op.SetReadOnly(ctx, true); err != nil {
+				return false, fmt.Errorf("failed to make the primary read-only: %w", err)
+			}
+		}
+		return redo, nil
+	}
+
 	// make the primary writable if it is not an intermediate primary
-	if ss.Cluster.Spec.ReplicationSourceSecretName == nil {
+	if !ss.Cluster.IsIntermediatePrimary() {
 		pst := ss.MySQLStatus[ss.Primary]
 		op := ss.DBOps[ss.Primary]
 		if pst.GlobalVariables.ReadOnly {
@@ -399,6 +656,30 @@ func (p *managerProcess) configure(ctx context.Context, ss *StatusSet) (bool, er
 	return redo, nil
 }
 
+// updateInstanceRoles records the role of each instance in the table on the primary instance
+// if `spec.publishInstanceRoles` is true.
+func (p *managerProcess) updateInstanceRoles(ctx context.Context, ss *StatusSet) error {
+	if !ss.Cluster.Spec.PublishInstanceRoles || ss.Cluster.IsReadOnly() {
+		return nil
+	}
+
+	roles := make([]dbop.InstanceRole, len(ss.MySQLStatus))
+	for i := range ss.MySQLStatus {
+		role := constants.RoleReplica
+		if i == ss.Primary {
+			role = constants.RolePrimary
+		}
+		roles[i] = dbop.InstanceRole{
+			ServerID: ss.Cluster.ServerID(i),
+			Role:     role,
+		}
+	}
+	if err := ss.DBOps[ss.Primary].UpdateInstanceRoles(ctx, roles); err != nil {
+		return fmt.Errorf("failed to update instance roles: %w", err)
+	}
+	return nil
+}
+
 func (p *managerProcess) configureIntermediatePrimary(ctx context.Context, ss *StatusSet) (redo bool, e error) {
 	log := logFromContext(ctx)
 	pst := ss.MySQLStatus[ss.Primary]
@@ -412,7 +693,7 @@ func (p *managerProcess) configureIntermediatePrimary(ctx context.Context, ss *S
 	}
 
 	secret := &corev1.Secret{}
-	name := client.ObjectKey{Namespace: ss.Cluster.Namespace, Name: *ss.Cluster.Spec.ReplicationSourceSecretName}
+	name := client.ObjectKey{Namespace: ss.Cluster.Namespace, Name: ss.Cluster.ReplicationSourceSecret()}
 	if err := p.client.Get(ctx, name, secret); err != nil {
 		return false, fmt.Errorf("failed to get secret %s: %w", name.String(), err)
 	}
@@ -463,17 +744,96 @@ func (p *managerProcess) configurePrimary(ctx context.Context, ss *StatusSet) (r
 		return
 	}
 
-	waitFor := int(ss.Cluster.Spec.Replicas / 2)
-	if !pst.GlobalVariables.SemiSyncMasterEnabled || pst.GlobalVariables.WaitForSlaveCount != waitFor {
+	waitFor, timeout := semiSyncConfig(ss.Cluster)
+	gv := pst.GlobalVariables
+	if !gv.SemiSyncMasterEnabled || gv.WaitForSlaveCount != waitFor || gv.SemiSyncMasterTimeout != timeout.Milliseconds() {
 		redo = true
-		log.Info("enable semi-sync primary")
-		if err := op.ConfigurePrimary(ctx, waitFor); err != nil {
+		log.Info("enable semi-sync primary", "waitFor", waitFor, "timeout", timeout)
+		if err := op.ConfigurePrimary(ctx, waitFor, timeout); err != nil {
 			return false, err
 		}
 	}
 	return
 }
 
+// cloneReplicas clones data from the primary to the replica instances concurrently.
+// It returns the first error if cloning data to any of the instances fails.
+func (p *managerProcess) cloneReplicas(ctx context.Context, ss *StatusSet, indices []int) error {
+	addr := ss.Pods[ss.Primary].Status.PodIP
+	if addr == "0.0.0.0" {
+		addr = ss.Cluster.PodHostname(ss.Primary)
+	}
+	if addr == "" {
+		return fmt.Errorf("pod %s has not been assigned an IP address", ss.Pods[ss.Primary].Name)
+	}
+
+	errs := make([]error, len(indices))
+	var wg sync.WaitGroup
+	for n, index := range indices {
+		wg.Add(1)
+		go func(n, index int) {
+			defer wg.Done()
+			errs[n] = p.cloneReplica(ctx, ss, index, addr)
+		}(n, index)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *managerProcess) cloneReplica(ctx context.Context, ss *StatusSet, index int, addr string) error {
+	log := logFromContext(ctx)
+
+	req := &agent.CloneRequest{
+		Host:         addr,
+		Port:         constants.MySQLAdminPort,
+		User:         constants.CloneDonorUser,
+		Password:     ss.Password.Donor(),
+		InitUser:     constants.AdminUser,
+		InitPassword: ss.Password.Admin(),
+	}
+
+	ag, err := p.agentf.New(ctx, ss.Cluster, index)
+	if err != nil {
+		return fmt.Errorf("failed to connect moco-agent of instance %d: %w", index, err)
+	}
+	defer ag.Close()
+
+	log.Info("begin cloning data", "instance", index)
+	_, err = ag.Clone(ctx, req)
+	if err2 := p.recordCloneResult(ctx, index, err); err2 != nil {
+		log.Error(err2, "failed to record the result of cloning data", "instance", index)
+	}
+	if err != nil {
+		event.CloneFailed.Emit(ss.Cluster, p.recorder, index, err)
+		log.Error(err, "clone failed", "instance", index)
+		return fmt.Errorf("failed to clone data on instance %d: %w", index, err)
+	}
+	event.CloneSucceeded.Emit(ss.Cluster, p.recorder, index)
+	log.Info("clone succeeded", "instance", index)
+
+	// wait until the instance restarts after clone
+	time.Sleep(waitForCloneRestartDuration)
+	for i := 0; i < 60; i++ {
+		select {
+		case <-time.After(1 * time.Second):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		_, err := ss.DBOps[index].GetStatus(ctx)
+		if err == nil {
+			break
+		}
+	}
+	return nil
+}
+
 func (p *managerProcess) configureReplica(ctx context.Context, ss *StatusSet, index int) (redo bool, e error) {
 	log := logFromContext(ctx)
 	st := ss.MySQLStatus[index]
@@ -509,55 +869,12 @@ func (p *managerProcess) configureReplica(ctx context.Context, ss *StatusSet, in
 		}
 	}
 
-	// clone and start replication for all non-errant replicas
-	if st.GlobalVariables.ExecutedGTID == "" && ss.ExecutedGTID != "" && st.ReplicaStatus == nil {
-		addr := ss.Pods[ss.Primary].Status.PodIP
-		if addr == "0.0.0.0" {
-			addr = ss.Cluster.PodHostname(ss.Primary)
-		}
-		if addr == "" {
-			return false, fmt.Errorf("pod %s has not been assigned an IP address", ss.Pods[ss.Primary].Name)
-		}
-
-		redo = true
-		req := &agent.CloneRequest{
-			Host:         addr,
-			Port:         constants.MySQLAdminPort,
-			User:         constants.CloneDonorUser,
-			Password:     ss.Password.Donor(),
-			InitUser:     constants.AdminUser,
-			InitPassword: ss.Password.Admin(),
-		}
-
-		ag, err := p.agentf.New(ctx, ss.Cluster, index)
-		if err != nil {
-			return false, fmt.Errorf("failed to connect moco-agent of instance %d: %w", index, err)
-		}
-		defer ag.Close()
-
-		log.Info("begin cloning data", "instance", index)
-		if _, err := ag.Clone(ctx, req); err != nil {
-			event.CloneFailed.Emit(ss.Cluster, p.recorder, index, err)
-			log.Error(err, "clone failed", "instance", index)
-			return false, fmt.Errorf("failed to clone data on instance %d: %w", index, err)
-		}
-		event.CloneSucceeded.Emit(ss.Cluster, p.recorder, index)
-		log.Info("clone succeeded", "instance", index)
-
-		// wait until the instance restarts after clone
-		time.Sleep(waitForCloneRestartDuration)
-		for i := 0; i < 60; i++ {
-			select {
-			case <-time.After(1 * time.Second):
-			case <-ctx.Done():
-				return false, ctx.Err()
-			}
-
-			_, err := op.GetStatus(ctx)
-			if err == nil {
-				break
-			}
+	// replication is started after the data is cloned by cloneReplicas.
+	if isCloningReplica(st) || replicaNeedsClone(ss, index) {
+		if wait := cloneRetryWait(ss.Cluster, index, time.Now()); wait > 0 {
+			log.Info("waiting for the backoff to retry cloning data", "instance", index, "wait", wait)
 		}
+		return
 	}
 
 	ai := dbop.AccessInfo{
@@ -566,7 +883,7 @@ func (p *managerProcess) configureReplica(ctx context.Context, ss *StatusSet, in
 		User:     constants.ReplicationUser,
 		Password: ss.Password.Replicator(),
 	}
-	semisync := ss.Cluster.Spec.ReplicationSourceSecretName == nil
+	semisync := !ss.Cluster.IsIntermediatePrimary()
 	if st.ReplicaStatus == nil || st.ReplicaStatus.SlaveIORunning != "Yes" || st.ReplicaStatus.MasterHost != ai.Host || st.GlobalVariables.SemiSyncSlaveEnabled != semisync {
 		redo = true
 		log.Info("start replication", "instance", index, "semisync", semisync)
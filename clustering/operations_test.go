@@ -0,0 +1,24 @@
+package clustering
+
+import (
+	"context"
+	"net"
+	"testing"
+)
+
+func TestCheckCloneSource(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().(*net.TCPAddr)
+
+	if err := checkCloneSource(context.Background(), "127.0.0.1", int32(addr.Port)); err != nil {
+		t.Errorf("failed to connect to a listening source: %v", err)
+	}
+
+	l.Close()
+	if err := checkCloneSource(context.Background(), "127.0.0.1", int32(addr.Port)); err == nil {
+		t.Error("connected to a closed source")
+	}
+}
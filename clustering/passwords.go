@@ -0,0 +1,103 @@
+package clustering
+
+import (
+	"context"
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/event"
+	"github.com/cybozu-go/moco/pkg/password"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// rotatePasswords rotates the passwords of the users for MOCO in cooperation with the controller.
+//
+//  1. The controller generates new passwords in the Secret of `RotatingPasswordSecretName`.
+//  2. This sets the new passwords while retaining the current ones, and records it in the status.
+//  3. The controller updates the Secrets with the new passwords, which restarts the Pods.
+//  4. This discards the old passwords once all Pods have been restarted with the new ones.
+//
+// The statements are executed on the primary instance and replicated to the replicas.
+func (p *managerProcess) rotatePasswords(ctx context.Context, ss *StatusSet) error {
+	cluster := ss.Cluster
+	if cluster.IsReadOnly() {
+		return nil
+	}
+
+	log := logFromContext(ctx)
+	op := ss.DBOps[ss.Primary]
+	retained := cluster.Status.PasswordRotation != nil && cluster.Status.PasswordRotation.Retained
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.RotatingPasswordSecretName()}
+	err := p.client.Get(ctx, key, secret)
+	switch {
+	case err == nil:
+		if retained {
+			// waiting for the controller to update the Secrets.
+			return nil
+		}
+		passwd, err := password.NewMySQLPasswordFromSecret(secret)
+		if err != nil {
+			return err
+		}
+		log.Info("set the new passwords of the users for MOCO")
+		if err := op.RotatePasswords(ctx, passwd); err != nil {
+			return err
+		}
+		return p.updatePasswordRotation(ctx, func(st *mocov1beta2.PasswordRotationStatus) {
+			st.Retained = true
+		})
+	case !apierrors.IsNotFound(err):
+		return fmt.Errorf("failed to get secret %s: %w", key.String(), err)
+	}
+
+	if !retained {
+		return nil
+	}
+	for _, pod := range ss.Pods {
+		if pod.Annotations[constants.AnnPasswordRevision] != ss.PasswordRev {
+			log.Info("waiting for the pods to be restarted with the new passwords", "pod", pod.Name)
+			return nil
+		}
+	}
+
+	log.Info("discard the old passwords of the users for MOCO")
+	if err := op.DiscardOldPasswords(ctx); err != nil {
+		return err
+	}
+	now := metav1.Now()
+	if err := p.updatePasswordRotation(ctx, func(st *mocov1beta2.PasswordRotationStatus) {
+		st.Retained = false
+		st.LastRotationTime = &now
+	}); err != nil {
+		return err
+	}
+	event.PasswordsRotated.Emit(cluster, p.recorder)
+	return nil
+}
+
+func (p *managerProcess) updatePasswordRotation(ctx context.Context, fn func(*mocov1beta2.PasswordRotationStatus)) error {
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+		st := cluster.Status.PasswordRotation.DeepCopy()
+		if st == nil {
+			st = &mocov1beta2.PasswordRotationStatus{}
+		}
+		fn(st)
+		if equality.Semantic.DeepEqual(cluster.Status.PasswordRotation, st) {
+			return nil
+		}
+		cluster.Status.PasswordRotation = st
+		return p.client.Status().Update(ctx, cluster)
+	})
+}
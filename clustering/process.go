@@ -2,8 +2,11 @@ package clustering
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
+	"slices"
+	"sync"
 	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
@@ -43,6 +46,7 @@ type metricsSet struct {
 }
 
 type managerProcess struct {
+	identity string
 	client   client.Client
 	reader   client.Reader
 	recorder record.EventRecorder
@@ -54,12 +58,18 @@ type managerProcess struct {
 
 	ch            chan string
 	metrics       metricsSet
+	lagPods       []string
 	deleteMetrics func()
 	pauseMetrics  func()
+
+	dumpMu        sync.Mutex
+	lastState     *ClusterStateDump
+	lastOperation *OperationDump
 }
 
-func newManagerProcess(c client.Client, r client.Reader, recorder record.EventRecorder, dbf dbop.OperatorFactory, agentf AgentFactory, name types.NamespacedName, cancel func()) *managerProcess {
+func newManagerProcess(identity string, c client.Client, r client.Reader, recorder record.EventRecorder, dbf dbop.OperatorFactory, agentf AgentFactory, name types.NamespacedName, cancel func()) *managerProcess {
 	return &managerProcess{
+		identity: identity,
 		client:   c,
 		reader:   r,
 		recorder: recorder,
@@ -103,12 +113,14 @@ func newManagerProcess(c client.Client, r client.Reader, recorder record.EventRe
 			metrics.BackupBinlogSize.DeleteLabelValues(name.Name, name.Namespace)
 			metrics.BackupWorkDirUsage.DeleteLabelValues(name.Name, name.Namespace)
 			metrics.BackupWarnings.DeleteLabelValues(name.Name, name.Namespace)
+			metrics.ReplicationLagVec.DeletePartialMatch(prometheus.Labels{"name": name.Name, "namespace": name.Namespace})
 		},
 		pauseMetrics: func() {
 			metrics.AvailableVec.WithLabelValues(name.Name, name.Namespace).Set(math.NaN())
 			metrics.HealthyVec.WithLabelValues(name.Name, name.Namespace).Set(math.NaN())
 			metrics.ReadyReplicasVec.WithLabelValues(name.Name, name.Namespace).Set(math.NaN())
 			metrics.ErrantReplicasVec.WithLabelValues(name.Name, name.Namespace).Set(math.NaN())
+			metrics.ReplicationLagVec.DeletePartialMatch(prometheus.Labels{"name": name.Name, "namespace": name.Namespace})
 		},
 	}
 }
@@ -153,13 +165,15 @@ func (p *managerProcess) Start(ctx context.Context, rootLog logr.Logger, interva
 			return
 		}
 
-		log := rootLog.WithValues("operationId", "op-"+rand.String(5))
+		opID := "op-" + rand.String(5)
+		log := rootLog.WithValues("operationId", opID)
 		log.Info("start operation", "origin", origin)
 		p.metrics.checkCount.Inc()
 		startTime := time.Now()
 		redo, err := p.do(logr.NewContext(ctx, log))
 		duration := time.Since(startTime)
 		p.metrics.processingTime.Observe(duration.Seconds())
+		p.recordOperation(opID, origin, startTime, duration, err)
 		if err != nil {
 			p.metrics.errorCount.Inc()
 			log.Error(err, "error", "duration", duration)
@@ -174,6 +188,45 @@ func (p *managerProcess) Start(ctx context.Context, rootLog logr.Logger, interva
 	}
 }
 
+func (p *managerProcess) recordOperation(id, origin string, startTime time.Time, duration time.Duration, err error) {
+	op := &OperationDump{
+		ID:        id,
+		Origin:    origin,
+		StartTime: startTime,
+		Duration:  duration.String(),
+	}
+	if err != nil {
+		op.Error = err.Error()
+	}
+
+	p.dumpMu.Lock()
+	defer p.dumpMu.Unlock()
+	p.lastOperation = op
+}
+
+// Dump returns the snapshot of the cluster state observed by the last operation.
+// It returns nil if no operation has been run yet.
+func (p *managerProcess) Dump() *ClusterStateDump {
+	p.dumpMu.Lock()
+	defer p.dumpMu.Unlock()
+
+	if p.lastState == nil && p.lastOperation == nil {
+		return nil
+	}
+	d := &ClusterStateDump{
+		Namespace: p.name.Namespace,
+		Name:      p.name.Name,
+	}
+	if p.lastState != nil {
+		*d = *p.lastState
+	}
+	if p.lastOperation != nil {
+		op := *p.lastOperation
+		d.LastOperation = &op
+	}
+	return d
+}
+
 func (p *managerProcess) do(ctx context.Context) (bool, error) {
 	ss, err := p.GatherStatus(ctx)
 	if err != nil {
@@ -181,18 +234,59 @@ func (p *managerProcess) do(ctx context.Context) (bool, error) {
 	}
 	defer ss.Close()
 
+	p.dumpMu.Lock()
+	p.lastState = newClusterStateDump(ss, time.Now())
+	p.dumpMu.Unlock()
+
 	if err := p.updateStatus(ctx, ss); err != nil {
 		return false, fmt.Errorf("failed to update status fields in MySQLCluster: %w", err)
 	}
 
 	logFromContext(ctx).Info("cluster state is " + ss.State.String())
+	if len(ss.SplitBrain) > 0 && ss.State != StateCloning && ss.State != StateRestoring {
+		var resolved bool
+		err := p.withOperationLease(ctx, func(ctx context.Context) error {
+			var err error
+			resolved, err = p.resolveSplitBrain(ctx, ss)
+			return err
+		})
+		if errors.Is(err, errOperationLeaseHeld) {
+			logFromContext(ctx).Info("skip resolving split-brain because " + err.Error())
+			return false, nil
+		}
+		if err != nil {
+			return false, fmt.Errorf("failed to resolve split-brain: %w", err)
+		}
+		// gather the status again to configure the cluster with the demoted instances.
+		return resolved, nil
+	}
+	if err := p.removeSplitBrainSurvivor(ctx, ss.Cluster); err != nil {
+		return false, err
+	}
+
 	switch ss.State {
 	case StateCloning:
 		if p.isCloning(ctx, ss) {
 			return false, nil
 		}
+		if wait := cloneRetryWait(ss.Cluster, ss.Primary, time.Now()); wait > 0 {
+			logFromContext(ctx).Info("waiting for the backoff to retry cloning data", "instance", ss.Primary, "wait", wait)
+			return false, nil
+		}
 
-		redo, err := p.clone(ctx, ss)
+		var redo bool
+		err := p.withOperationLease(ctx, func(ctx context.Context) error {
+			var err error
+			redo, err = p.clone(ctx, ss)
+			return err
+		})
+		if errors.Is(err, errOperationLeaseHeld) {
+			logFromContext(ctx).Info("skip cloning data because " + err.Error())
+			return false, nil
+		}
+		if err2 := p.recordCloneResult(ctx, ss.Primary, err); err2 != nil {
+			logFromContext(ctx).Error(err2, "failed to record the result of cloning data", "instance", ss.Primary)
+		}
 		if err != nil {
 			event.InitCloneFailed.Emit(ss.Cluster, p.recorder, err)
 			return false, fmt.Errorf("failed to clone data: %w", err)
@@ -205,7 +299,14 @@ func (p *managerProcess) do(ctx context.Context) (bool, error) {
 
 	case StateHealthy, StateDegraded:
 		if ss.NeedSwitch {
-			if err := p.switchover(ctx, ss); err != nil {
+			err := p.withOperationLease(ctx, func(ctx context.Context) error {
+				return p.switchover(ctx, ss)
+			})
+			if errors.Is(err, errOperationLeaseHeld) {
+				logFromContext(ctx).Info("skip switchover because " + err.Error())
+				return false, nil
+			}
+			if err != nil {
 				event.SwitchOverFailed.Emit(ss.Cluster, p.recorder, err)
 				return false, fmt.Errorf("failed to switchover: %w", err)
 			}
@@ -214,13 +315,42 @@ func (p *managerProcess) do(ctx context.Context) (bool, error) {
 			return true, nil
 		}
 		if ss.State == StateDegraded {
-			return p.configure(ctx, ss)
+			redo, err := p.configure(ctx, ss)
+			if err != nil || redo {
+				return redo, err
+			}
+		}
+		if err := p.updateInstanceRoles(ctx, ss); err != nil {
+			return false, err
+		}
+		if err := p.reconcileUsers(ctx, ss); err != nil {
+			return false, fmt.Errorf("failed to reconcile users: %w", err)
+		}
+		if err := p.reconcileDatabases(ctx, ss); err != nil {
+			return false, fmt.Errorf("failed to reconcile databases: %w", err)
+		}
+		// all instances need to be healthy to replicate the new passwords and restart with them.
+		if ss.State == StateHealthy {
+			if err := p.rotatePasswords(ctx, ss); err != nil {
+				return false, fmt.Errorf("failed to rotate passwords: %w", err)
+			}
 		}
 		return false, nil
 
 	case StateFailed:
 		// in this case, only applicable operation is a failover.
-		if err := p.failover(ctx, ss); err != nil {
+		err := p.withOperationLease(ctx, func(ctx context.Context) error {
+			return p.failover(ctx, ss)
+		})
+		if errors.Is(err, errOperationLeaseHeld) {
+			logFromContext(ctx).Info("skip failover because " + err.Error())
+			return false, nil
+		}
+		if errors.Is(err, errFailoverPostponed) {
+			logFromContext(ctx).Info("postpone failover because " + err.Error())
+			return false, nil
+		}
+		if err != nil {
 			event.FailOverFailed.Emit(ss.Cluster, p.recorder, err)
 			return false, fmt.Errorf("failed to failover: %w", err)
 		}
@@ -248,6 +378,7 @@ func (p *managerProcess) updateStatus(ctx context.Context, ss *StatusSet) error
 		p.metrics.backupWorkDirUsage.Set(float64(bs.WorkDirUsage))
 		p.metrics.backupWarnings.Set(float64(len(bs.Warnings)))
 	}
+	p.updateReplicationLag(ss)
 
 	ststr := ss.State.String()
 	updateCond := func(typ string, val metav1.ConditionStatus) metav1.Condition {
@@ -287,6 +418,19 @@ func (p *managerProcess) updateStatus(ctx context.Context, ss *StatusSet) error
 		meta.SetStatusCondition(&cluster.Status.Conditions, updateCond(mocov1beta2.ConditionAvailable, available))
 		meta.SetStatusCondition(&cluster.Status.Conditions, updateCond(mocov1beta2.ConditionHealthy, healthy))
 
+		splitBrain := metav1.Condition{
+			Type:    mocov1beta2.ConditionSplitBrainDetected,
+			Status:  metav1.ConditionFalse,
+			Reason:  "NoSplitBrain",
+			Message: "at most one instance is writable",
+		}
+		if len(ss.SplitBrain) > 0 {
+			splitBrain.Status = metav1.ConditionTrue
+			splitBrain.Reason = "SplitBrain"
+			splitBrain.Message = splitBrainMessage(ss.SplitBrain)
+		}
+		meta.SetStatusCondition(&cluster.Status.Conditions, splitBrain)
+
 		meta.SetStatusCondition(&cluster.Status.Conditions,
 			metav1.Condition{
 				Type:    mocov1beta2.ConditionClusteringActive,
@@ -314,8 +458,22 @@ func (p *managerProcess) updateStatus(ctx context.Context, ss *StatusSet) error
 			}
 		}
 		cluster.Status.SyncedReplicas = syncedReplicas
+		if degraded, ok := degradedCondition(cluster.Spec.MinSyncedReplicas, countSyncedReplicas(cluster, ss)); ok {
+			meta.SetStatusCondition(&cluster.Status.Conditions, degraded)
+		} else {
+			meta.RemoveStatusCondition(&cluster.Status.Conditions, mocov1beta2.ConditionDegraded)
+		}
 		cluster.Status.ErrantReplicas = len(ss.Errants)
 		cluster.Status.ErrantReplicaList = ss.Errants
+		cluster.Status.IneligibleReplicaList = ss.Ineligibles
+		// forget clone failures of the instances removed by scaling in.
+		cluster.Status.CloneFailures = slices.DeleteFunc(cluster.Status.CloneFailures, func(f mocov1beta2.CloneFailureStatus) bool {
+			return f.Index >= len(ss.Pods)
+		})
+		// the clones to be started in this round are regarded as in progress.
+		start, cloning, queued := cloneQueue(ss, time.Now())
+		cluster.Status.CloningReplicas = cloning + len(start)
+		cluster.Status.QueuedClones = len(queued)
 		p.metrics.replicas.Set(float64(len(ss.Pods)))
 		p.metrics.readyReplicas.Set(float64(syncedReplicas))
 		p.metrics.errantReplicas.Set(float64(len(ss.Errants)))
@@ -323,10 +481,23 @@ func (p *managerProcess) updateStatus(ctx context.Context, ss *StatusSet) error
 		// the completion of initial cloning is recorded in the status
 		// to make it possible to determine the cloning status even while
 		// the primary instance is down.
-		if cluster.Spec.ReplicationSourceSecretName != nil && ss.State != StateCloning {
+		if cluster.IsIntermediatePrimary() && ss.State != StateCloning {
 			cluster.Status.Cloned = true
 		}
 
+		// the completion of adoption is recorded in the status not to clone the data again.
+		if cluster.IsAdopting() && ss.State != StateCloning {
+			now := metav1.Now()
+			cluster.Status.AdoptedTime = &now
+		}
+
+		// keep the last known state of the replication from the source while the primary is down.
+		if !cluster.IsIntermediatePrimary() {
+			cluster.Status.ReplicationSource = nil
+		} else if st := replicationSourceStatus(ss); st != nil {
+			cluster.Status.ReplicationSource = st
+		}
+
 		// if nothing has changed, skip updating.
 		if equality.Semantic.DeepEqual(orig, cluster) {
 			return nil
@@ -336,3 +507,30 @@ func (p *managerProcess) updateStatus(ctx context.Context, ss *StatusSet) error
 		return p.client.Status().Update(ctx, cluster)
 	})
 }
+
+// updateReplicationLag sets the replication lag of each instance to the metrics.
+// The lag is NaN if the instance is not replicating or its lag is unknown.
+// The primary instance does not have the metric unless it replicates from an external source.
+func (p *managerProcess) updateReplicationLag(ss *StatusSet) {
+	pods := make([]string, 0, len(ss.MySQLStatus))
+	for i, ist := range ss.MySQLStatus {
+		podName := ss.Cluster.PodName(i)
+		if i == ss.Primary && (ist == nil || ist.ReplicaStatus == nil) {
+			continue
+		}
+		pods = append(pods, podName)
+
+		lag := math.NaN()
+		if ist != nil && ist.ReplicaStatus != nil && ist.ReplicaStatus.SecondsBehindMaster.Valid {
+			lag = float64(ist.ReplicaStatus.SecondsBehindMaster.Int64)
+		}
+		metrics.ReplicationLagVec.WithLabelValues(p.name.Name, p.name.Namespace, podName).Set(lag)
+	}
+
+	for _, podName := range p.lagPods {
+		if !slices.Contains(pods, podName) {
+			metrics.ReplicationLagVec.DeleteLabelValues(p.name.Name, p.name.Namespace, podName)
+		}
+	}
+	p.lagPods = pods
+}
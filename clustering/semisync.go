@@ -0,0 +1,28 @@
+package clustering
+
+import (
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+)
+
+// defaultSemiSyncTimeout is the default of `rpl_semi_sync_master_timeout`.
+// This is long enough to keep the primary from falling back to the asynchronous replication.
+const defaultSemiSyncTimeout = 24 * time.Hour
+
+// semiSyncConfig returns the wait count and the timeout of the semi-synchronous replication
+// for the primary instance of the cluster.
+func semiSyncConfig(cluster *mocov1beta2.MySQLCluster) (int, time.Duration) {
+	waitFor := int(cluster.Spec.Replicas / 2)
+	timeout := defaultSemiSyncTimeout
+
+	if ss := cluster.Spec.SemiSync; ss != nil {
+		if ss.WaitForReplicaCount != nil {
+			waitFor = int(*ss.WaitForReplicaCount)
+		}
+		if ss.Timeout != nil {
+			timeout = ss.Timeout.Duration
+		}
+	}
+	return waitFor, timeout
+}
@@ -0,0 +1,60 @@
+package clustering
+
+import (
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestSemiSyncConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		replicas      int32
+		semiSync      *mocov1beta2.SemiSyncSpec
+		expectWaitFor int
+		expectTimeout time.Duration
+	}{
+		{
+			name:          "default",
+			replicas:      5,
+			expectWaitFor: 2,
+			expectTimeout: 24 * time.Hour,
+		},
+		{
+			name:          "empty",
+			replicas:      3,
+			semiSync:      &mocov1beta2.SemiSyncSpec{},
+			expectWaitFor: 1,
+			expectTimeout: 24 * time.Hour,
+		},
+		{
+			name:     "configured",
+			replicas: 5,
+			semiSync: &mocov1beta2.SemiSyncSpec{
+				WaitForReplicaCount: ptr.To[int32](3),
+				Timeout:             &metav1.Duration{Duration: 10 * time.Second},
+			},
+			expectWaitFor: 3,
+			expectTimeout: 10 * time.Second,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Spec.Replicas = tc.replicas
+			cluster.Spec.SemiSync = tc.semiSync
+
+			waitFor, timeout := semiSyncConfig(cluster)
+			if waitFor != tc.expectWaitFor {
+				t.Errorf("unexpected wait count: %d", waitFor)
+			}
+			if timeout != tc.expectTimeout {
+				t.Errorf("unexpected timeout: %v", timeout)
+			}
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"os"
 	"slices"
 	"sort"
-	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -16,6 +15,7 @@ import (
 	"github.com/cybozu-go/moco/pkg/dbop"
 	"github.com/cybozu-go/moco/pkg/password"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/utils/ptr"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -82,12 +82,15 @@ type StatusSet struct {
 	Primary      int
 	Cluster      *mocov1beta2.MySQLCluster
 	Password     *password.MySQLPassword
+	PasswordRev  string
 	Pods         []*corev1.Pod
 	DBOps        []dbop.Operator
 	MySQLStatus  []*dbop.MySQLInstanceStatus
 	ExecutedGTID string
 	Errants      []int
 	Candidates   []int
+	Ineligibles  []int
+	SplitBrain   []int
 
 	NeedSwitch bool
 	Candidate  int
@@ -122,6 +125,10 @@ func (ss *StatusSet) DecideState() {
 	default:
 		ss.State = StateIncomplete
 	}
+	// replicas that have not been ready for long enough cannot be the next primary.
+	ss.Candidates = slices.DeleteFunc(ss.Candidates, func(i int) bool {
+		return slices.Contains(ss.Ineligibles, i)
+	})
 	if len(ss.Candidates) > 0 {
 		ss.NeedSwitch = needSwitch(ss.Pods[ss.Primary])
 		// Choose the lowest ordinal for a switchover target.
@@ -151,6 +158,7 @@ func (p *managerProcess) GatherStatus(ctx context.Context) (*StatusSet, error) {
 		return nil, err
 	}
 	ss.Password = passwd
+	ss.PasswordRev = passwdSecret.Annotations[constants.AnnPasswordRevision]
 
 	pods := &corev1.PodList{}
 	if err := p.client.List(ctx, pods, client.InNamespace(p.name.Namespace), client.MatchingLabels{
@@ -165,10 +173,9 @@ func (p *managerProcess) GatherStatus(ctx context.Context) (*StatusSet, error) {
 	}
 	ss.Pods = make([]*corev1.Pod, cluster.Spec.Replicas)
 	for i, pod := range pods.Items {
-		fields := strings.Split(pod.Name, "-")
-		index, err := strconv.Atoi(fields[len(fields)-1])
+		index, err := cluster.PodIndex(pod.Name)
 		if err != nil {
-			return nil, fmt.Errorf("bad pod name: %s", pod.Name)
+			return nil, err
 		}
 
 		if index < 0 || index >= len(pods.Items) {
@@ -261,10 +268,94 @@ func (p *managerProcess) GatherStatus(ctx context.Context) (*StatusSet, error) {
 		}
 	}
 
+	ss.Ineligibles = ineligibleReplicas(ss, time.Now())
+	ss.SplitBrain = splitBrainInstances(ss)
+
 	ss.DecideState()
 	return ss, nil
 }
 
+// ineligibleReplicas returns the indices of replicas that are ready but have been ready
+// for less than `spec.minReadySeconds` at `now`.  They cannot be the next primary.
+func ineligibleReplicas(ss *StatusSet, now time.Time) []int {
+	minReady := time.Duration(ss.Cluster.Spec.MinReadySeconds) * time.Second
+	if minReady <= 0 {
+		return nil
+	}
+
+	var ineligibles []int
+	for i, pod := range ss.Pods {
+		if i == ss.Primary {
+			continue
+		}
+		if !isPodReady(pod) {
+			continue
+		}
+		if readySince(pod).Add(minReady).After(now) {
+			ineligibles = append(ineligibles, i)
+		}
+	}
+	return ineligibles
+}
+
+// readySince returns the time when the Pod became ready.
+func readySince(pod *corev1.Pod) time.Time {
+	for _, cond := range pod.Status.Conditions {
+		if cond.Type == corev1.PodReady {
+			return cond.LastTransitionTime.Time
+		}
+	}
+	return time.Time{}
+}
+
+// splitBrainInstances returns the indices of writable instances if two or more
+// instances are writable, i.e. they may all believe that they are the primary.
+// An intermediate primary is not writable, so this always returns nil for it.
+func splitBrainInstances(ss *StatusSet) []int {
+	if ss.Cluster.IsIntermediatePrimary() {
+		return nil
+	}
+
+	var writables []int
+	for i, ist := range ss.MySQLStatus {
+		if ist == nil {
+			continue
+		}
+		if !ist.GlobalVariables.ReadOnly {
+			writables = append(writables, i)
+		}
+	}
+	if len(writables) < 2 {
+		return nil
+	}
+	return writables
+}
+
+// replicationSourceStatus returns the state of the replication from the source of an intermediate primary.
+// It returns nil if the primary is not an intermediate one or its replication status is unknown.
+func replicationSourceStatus(ss *StatusSet) *mocov1beta2.ReplicationSourceStatus {
+	if !ss.Cluster.IsIntermediatePrimary() {
+		return nil
+	}
+	pst := ss.MySQLStatus[ss.Primary]
+	if pst == nil || pst.ReplicaStatus == nil {
+		return nil
+	}
+
+	repl := pst.ReplicaStatus
+	st := &mocov1beta2.ReplicationSourceStatus{
+		Host:             repl.MasterHost,
+		IOThreadRunning:  repl.SlaveIORunning == "Yes",
+		SQLThreadRunning: repl.SlaveSQLRunning == "Yes",
+		LastIOError:      repl.LastIoError,
+		LastSQLError:     repl.LastSQLError,
+	}
+	if repl.SecondsBehindMaster.Valid {
+		st.SecondsBehindSource = ptr.To(repl.SecondsBehindMaster.Int64)
+	}
+	return st
+}
+
 // containErrantTransactions check whether a GTID set contains errant transactions.
 // When the primary load is high, in the rare case, gtid_executed of replicas precedes the primary.
 // Assuming such a situation, this function ignores primary's event.
@@ -322,7 +413,7 @@ func isPodReady(pod *corev1.Pod) bool {
 func replicasInCluster(cluster *mocov1beta2.MySQLCluster, replicas []dbop.ReplicaHost) int32 {
 	var n int32
 	for _, r := range replicas {
-		if r.ServerID >= cluster.Spec.ServerIDBase && r.ServerID < (cluster.Spec.ServerIDBase+cluster.Spec.Replicas) {
+		if r.ServerID >= cluster.ServerID(0) && r.ServerID < cluster.ServerID(int(cluster.Spec.Replicas)) {
 			n++
 		}
 	}
@@ -385,7 +476,7 @@ func isHealthy(ss *StatusSet) bool {
 	if replicasInCluster(ss.Cluster, pst.ReplicaHosts) != (ss.Cluster.Spec.Replicas - 1) {
 		return false
 	}
-	if ss.Cluster.Spec.ReplicationSourceSecretName != nil {
+	if ss.Cluster.IsReadOnly() {
 		if !pst.GlobalVariables.SuperReadOnly {
 			return false
 		}
@@ -399,7 +490,7 @@ func isHealthy(ss *StatusSet) bool {
 }
 
 func isCloning(ss *StatusSet) bool {
-	if ss.Cluster.Spec.ReplicationSourceSecretName == nil {
+	if !ss.Cluster.IsIntermediatePrimary() && !ss.Cluster.IsAdopting() {
 		return false
 	}
 
@@ -445,7 +536,7 @@ func isDegraded(ss *StatusSet) bool {
 	if pst == nil {
 		return false
 	}
-	if ss.Cluster.Spec.ReplicationSourceSecretName != nil {
+	if ss.Cluster.IsReadOnly() {
 		if !pst.GlobalVariables.SuperReadOnly {
 			return false
 		}
@@ -3,11 +3,13 @@ package clustering
 import (
 	"database/sql"
 	"fmt"
+	"slices"
 	"testing"
 	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/dbop"
+	"github.com/google/go-cmp/cmp"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/utils/ptr"
@@ -22,6 +24,8 @@ type ssBuilder struct {
 	toRestore      bool
 	isRestored     bool
 	isCloned       bool
+	toAdopt        bool
+	isAdopted      bool
 	pods           []*corev1.Pod
 	mysqlStatus    []*dbop.MySQLInstanceStatus
 }
@@ -54,6 +58,13 @@ func (b *ssBuilder) build() *StatusSet {
 	if b.isCloned {
 		cluster.Status.Cloned = true
 	}
+	if b.toAdopt {
+		cluster.Spec.AdoptSourceSecretName = ptr.To[string]("fuga")
+	}
+	if b.isAdopted {
+		t := metav1.Now()
+		cluster.Status.AdoptedTime = &t
+	}
 	var errants []int
 	for i, ist := range b.mysqlStatus {
 		if i == b.primaryIndex {
@@ -91,6 +102,12 @@ func newSS(replicas int32, primary int, intermediate, toRestore, isRestored, isC
 	}
 }
 
+func (b *ssBuilder) withAdoption(adopted bool) *ssBuilder {
+	b.toAdopt = true
+	b.isAdopted = adopted
+	return b
+}
+
 func (b *ssBuilder) withPod(ready, deleting, demoting bool) *ssBuilder {
 	pod := &corev1.Pod{}
 	if ready {
@@ -369,6 +386,46 @@ func TestStatusSet(t *testing.T) {
 				build(),
 			expectedState: StateCloning,
 		},
+		{
+			name: "adopting-not-started",
+			statusSet: newSS(3, 0, false, false, false, false).
+				withAdoption(false).
+				withPod(false, false, false).
+				withPod(false, false, false).
+				withPod(false, false, false).
+				withMySQL(newMySQL("", true, false, false).build()).
+				withMySQL(newMySQL("", true, false, false).build()).
+				withMySQL(newMySQL("", true, false, false).build()).
+				build(),
+			expectedState: StateCloning,
+		},
+		{
+			name: "adopting-in-progress",
+			statusSet: newSS(1, 0, false, false, false, false).
+				withAdoption(false).
+				withPod(false, false, false).
+				withMySQL(newMySQL("1234", true, false, true).build()).
+				build(),
+			expectedState: StateCloning,
+		},
+		{
+			name: "adopted-primary-failing",
+			statusSet: newSS(1, 0, false, false, false, false).
+				withAdoption(true).
+				withPod(false, false, false).
+				withMySQL(nil).
+				build(),
+			expectedState: StateLost,
+		},
+		{
+			name: "adopted-healthy",
+			statusSet: newSS(1, 0, false, false, false, false).
+				withAdoption(true).
+				withPod(true, false, false).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				build(),
+			expectedState: StateHealthy,
+		},
 		{
 			name: "cloned-primary-failing",
 			statusSet: newSS(1, 0, true, false, false, true).
@@ -598,3 +655,199 @@ func TestStatusSet(t *testing.T) {
 		})
 	}
 }
+
+func TestIneligibleCandidates(t *testing.T) {
+	now := time.Now()
+	build := func(minReadySeconds int32, readySince ...time.Time) *StatusSet {
+		ss := newSS(3, 0, false, false, false, false).
+			withPod(true, false, true).
+			withPod(true, false, false).
+			withPod(true, false, false).
+			withMySQL(newMySQL("1234", false, false, false).
+				withReplica(11, "replica1").
+				withReplica(12, "replica2").
+				build()).
+			withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+			withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+			build()
+		ss.Cluster.Spec.MinReadySeconds = minReadySeconds
+		for i, ts := range readySince {
+			conds := ss.Pods[i+1].Status.Conditions
+			for j := range conds {
+				if conds[j].Type == corev1.PodReady {
+					conds[j].LastTransitionTime = metav1.NewTime(ts)
+				}
+			}
+		}
+		return ss
+	}
+
+	testCases := []struct {
+		name               string
+		statusSet          *StatusSet
+		expectedIneligible []int
+		expectedSwitch     bool
+		expectedCandidate  int
+	}{
+		{
+			name:              "disabled",
+			statusSet:         build(0, now, now),
+			expectedSwitch:    true,
+			expectedCandidate: 1,
+		},
+		{
+			name:               "new-replica",
+			statusSet:          build(60, now.Add(-10*time.Second), now.Add(-10*time.Minute)),
+			expectedIneligible: []int{1},
+			expectedSwitch:     true,
+			expectedCandidate:  2,
+		},
+		{
+			name:              "all-eligible",
+			statusSet:         build(60, now.Add(-time.Minute), now.Add(-10*time.Minute)),
+			expectedSwitch:    true,
+			expectedCandidate: 1,
+		},
+		{
+			name:               "no-eligible",
+			statusSet:          build(60, now.Add(-10*time.Second), now.Add(-20*time.Second)),
+			expectedIneligible: []int{1, 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ss := tc.statusSet
+			ss.Ineligibles = ineligibleReplicas(ss, now)
+			if !slices.Equal(ss.Ineligibles, tc.expectedIneligible) {
+				t.Errorf("unexpected ineligible replicas %v: expected=%v", ss.Ineligibles, tc.expectedIneligible)
+			}
+
+			ss.DecideState()
+			if ss.State != StateHealthy {
+				t.Errorf("unexpected state %s", ss.State.String())
+			}
+			if ss.NeedSwitch != tc.expectedSwitch {
+				t.Errorf("wrong NeedSwitch: expected=%v", tc.expectedSwitch)
+			}
+			if tc.expectedSwitch && ss.Candidate != tc.expectedCandidate {
+				t.Errorf("unexpected candidate %d: expected=%d", ss.Candidate, tc.expectedCandidate)
+			}
+		})
+	}
+}
+
+func TestSplitBrainInstances(t *testing.T) {
+	testCases := []struct {
+		name      string
+		statusSet *StatusSet
+		expected  []int
+	}{
+		{
+			name: "healthy",
+			statusSet: newSS(3, 0, false, false, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+				withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+				build(),
+		},
+		{
+			name: "unreachable",
+			statusSet: newSS(3, 0, false, false, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				withMySQL(nil).
+				withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+				build(),
+		},
+		{
+			name: "split-brain",
+			statusSet: newSS(3, 0, false, false, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+				withMySQL(newMySQL("1234", false, false, false).build()).
+				build(),
+			expected: []int{0, 2},
+		},
+		{
+			name: "intermediate",
+			statusSet: newSS(3, 0, true, false, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withPod(true, false, false).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				withMySQL(newMySQL("123", false, false, false).build()).
+				withMySQL(newMySQL("123", true, false, false).withPrimary(testPrimaryHostname).build()).
+				build(),
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			actual := splitBrainInstances(tc.statusSet)
+			if !slices.Equal(actual, tc.expected) {
+				t.Errorf("unexpected split-brain instances %v: expected=%v", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestReplicationSourceStatus(t *testing.T) {
+	ss := newSS(1, 0, false, false, false, false).
+		withPod(true, false, false).
+		withMySQL(newMySQL("123", false, false, false).build()).
+		build()
+	if st := replicationSourceStatus(ss); st != nil {
+		t.Errorf("unexpected status for a normal primary: %+v", st)
+	}
+
+	ss = newSS(1, 0, true, false, false, true).
+		withPod(true, false, false).
+		withMySQL(nil).
+		build()
+	if st := replicationSourceStatus(ss); st != nil {
+		t.Errorf("unexpected status for an unreachable primary: %+v", st)
+	}
+
+	ss = newSS(1, 0, true, false, false, true).
+		withPod(true, false, false).
+		withMySQL(newMySQL("123", true, false, false).withPrimary("moco-source-primary.src.svc").build()).
+		build()
+	repl := ss.MySQLStatus[0].ReplicaStatus
+	repl.SlaveIORunning = "Connecting"
+	repl.SlaveSQLRunning = "Yes"
+	repl.LastIoError = "error connecting to source"
+	st := replicationSourceStatus(ss)
+	expected := &mocov1beta2.ReplicationSourceStatus{
+		Host:             "moco-source-primary.src.svc",
+		SQLThreadRunning: true,
+		LastIOError:      "error connecting to source",
+	}
+	if !cmp.Equal(st, expected) {
+		t.Error("unexpected status", cmp.Diff(expected, st))
+	}
+
+	repl.SlaveIORunning = "Yes"
+	repl.LastIoError = ""
+	repl.SecondsBehindMaster = sql.NullInt64{Valid: true, Int64: 3}
+	st = replicationSourceStatus(ss)
+	expected = &mocov1beta2.ReplicationSourceStatus{
+		Host:                "moco-source-primary.src.svc",
+		IOThreadRunning:     true,
+		SQLThreadRunning:    true,
+		SecondsBehindSource: ptr.To[int64](3),
+	}
+	if !cmp.Equal(st, expected) {
+		t.Error("unexpected status", cmp.Diff(expected, st))
+	}
+}
@@ -0,0 +1,40 @@
+package clustering
+
+import (
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// countSyncedReplicas returns the number of synced replica instances, excluding the primary,
+// from `status.syncedReplicas`.  The readiness probe of mysqld fails while the replication
+// threads of a replica are stopped or too delayed, so a ready replica is synced with the primary.
+func countSyncedReplicas(cluster *mocov1beta2.MySQLCluster, ss *StatusSet) int {
+	n := cluster.Status.SyncedReplicas
+	if isPodReady(ss.Pods[ss.Primary]) {
+		n--
+	}
+	return n
+}
+
+// degradedCondition returns the `Degraded` condition for `spec.minSyncedReplicas`.
+// The second return value is false if no minimum is configured.
+func degradedCondition(minSynced *int32, synced int) (metav1.Condition, bool) {
+	if minSynced == nil {
+		return metav1.Condition{}, false
+	}
+
+	cond := metav1.Condition{
+		Type:    mocov1beta2.ConditionDegraded,
+		Status:  metav1.ConditionFalse,
+		Reason:  "EnoughSyncedReplicas",
+		Message: fmt.Sprintf("%d replicas are synced", synced),
+	}
+	if synced < int(*minSynced) {
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "TooFewSyncedReplicas"
+		cond.Message = fmt.Sprintf("%d replicas are synced while at least %d are required", synced, *minSynced)
+	}
+	return cond, true
+}
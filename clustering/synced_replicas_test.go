@@ -0,0 +1,79 @@
+package clustering
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestCountSyncedReplicas(t *testing.T) {
+	readyPod := func(ready bool) *corev1.Pod {
+		status := corev1.ConditionFalse
+		if ready {
+			status = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			Status: corev1.PodStatus{
+				Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+			},
+		}
+	}
+
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Status.SyncedReplicas = 3
+	ss := &StatusSet{
+		Primary: 1,
+		Pods:    []*corev1.Pod{readyPod(true), readyPod(true), readyPod(false), readyPod(true)},
+	}
+	if n := countSyncedReplicas(cluster, ss); n != 2 {
+		t.Errorf("unexpected count: %d", n)
+	}
+
+	// the primary is not counted in status.syncedReplicas while it is not ready.
+	cluster.Status.SyncedReplicas = 2
+	ss.Pods[1] = readyPod(false)
+	if n := countSyncedReplicas(cluster, ss); n != 2 {
+		t.Errorf("unexpected count without the ready primary: %d", n)
+	}
+}
+
+func TestDegradedCondition(t *testing.T) {
+	testCases := []struct {
+		name       string
+		minSynced  *int32
+		synced     int
+		expectOK   bool
+		expectStat metav1.ConditionStatus
+		expectRsn  string
+	}{
+		{name: "no minimum", minSynced: nil, synced: 0, expectOK: false},
+		{name: "zero minimum", minSynced: ptr.To[int32](0), synced: 0, expectOK: true, expectStat: metav1.ConditionFalse, expectRsn: "EnoughSyncedReplicas"},
+		{name: "enough", minSynced: ptr.To[int32](1), synced: 2, expectOK: true, expectStat: metav1.ConditionFalse, expectRsn: "EnoughSyncedReplicas"},
+		{name: "exact", minSynced: ptr.To[int32](2), synced: 2, expectOK: true, expectStat: metav1.ConditionFalse, expectRsn: "EnoughSyncedReplicas"},
+		{name: "too few", minSynced: ptr.To[int32](2), synced: 1, expectOK: true, expectStat: metav1.ConditionTrue, expectRsn: "TooFewSyncedReplicas"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cond, ok := degradedCondition(tc.minSynced, tc.synced)
+			if ok != tc.expectOK {
+				t.Fatalf("unexpected ok: %v", ok)
+			}
+			if !ok {
+				return
+			}
+			if cond.Type != mocov1beta2.ConditionDegraded {
+				t.Errorf("unexpected type: %s", cond.Type)
+			}
+			if cond.Status != tc.expectStat {
+				t.Errorf("unexpected status: %s", cond.Status)
+			}
+			if cond.Reason != tc.expectRsn {
+				t.Errorf("unexpected reason: %s", cond.Reason)
+			}
+		})
+	}
+}
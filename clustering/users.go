@@ -0,0 +1,102 @@
+package clustering
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/password"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// userRevision returns a digest of the grants of the user and the version of its password Secret.
+func userRevision(u *mocov1beta2.UserSpec, secret *corev1.Secret) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\n%s\n", secret.UID, secret.ResourceVersion)
+	h.Write([]byte(strings.Join(u.Grants, "\n")))
+	return hex.EncodeToString(h.Sum(nil))[:16]
+}
+
+// reconcileUsers creates or updates the users in `spec.users` on the primary instance,
+// and drops the users that were removed from `spec.users`.
+// A user is updated only when its grants or password Secret are changed.
+func (p *managerProcess) reconcileUsers(ctx context.Context, ss *StatusSet) error {
+	cluster := ss.Cluster
+	if cluster.IsReadOnly() {
+		return nil
+	}
+	if len(cluster.Spec.Users) == 0 && len(cluster.Status.Users) == 0 {
+		return nil
+	}
+
+	log := logFromContext(ctx)
+	op := ss.DBOps[ss.Primary]
+
+	applied := make(map[string]string)
+	for _, u := range cluster.Status.Users {
+		applied[u.Name] = u.Revision
+	}
+
+	var users []mocov1beta2.UserStatus
+	desired := make(map[string]bool)
+	for i := range cluster.Spec.Users {
+		u := &cluster.Spec.Users[i]
+		desired[u.Name] = true
+
+		secret := &corev1.Secret{}
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: u.PasswordSecretName}
+		if err := p.client.Get(ctx, key, secret); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return fmt.Errorf("failed to get secret %s: %w", key.String(), err)
+			}
+			// The Secret will be created by the controller.
+			log.Info("password secret for user is not found", "user", u.Name, "secret", key.String())
+			if rev, ok := applied[u.Name]; ok {
+				users = append(users, mocov1beta2.UserStatus{Name: u.Name, Revision: rev})
+			}
+			continue
+		}
+		passwd, ok := secret.Data[password.UserPasswordKey]
+		if !ok {
+			return fmt.Errorf("no %s in secret %s", password.UserPasswordKey, key.String())
+		}
+
+		rev := userRevision(u, secret)
+		if applied[u.Name] != rev {
+			log.Info("apply user", "user", u.Name)
+			if err := op.ApplyUser(ctx, u.Name, string(passwd), u.Grants); err != nil {
+				return err
+			}
+		}
+		users = append(users, mocov1beta2.UserStatus{Name: u.Name, Revision: rev})
+	}
+
+	for _, u := range cluster.Status.Users {
+		if desired[u.Name] {
+			continue
+		}
+		log.Info("drop user", "user", u.Name)
+		if err := op.DropUser(ctx, u.Name); err != nil {
+			return err
+		}
+	}
+
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cluster := &mocov1beta2.MySQLCluster{}
+		if err := p.reader.Get(ctx, p.name, cluster); err != nil {
+			return err
+		}
+		if equality.Semantic.DeepEqual(cluster.Status.Users, users) {
+			return nil
+		}
+		cluster.Status.Users = users
+		return p.client.Status().Update(ctx, cluster)
+	})
+}
@@ -0,0 +1,37 @@
+package cmd
+
+import (
+	"github.com/cybozu-go/moco/backup"
+	"github.com/spf13/cobra"
+)
+
+var analyzeCmd = &cobra.Command{
+	Use:   "analyze HOST...",
+	Short: "refresh the statistics of tables on replica instances",
+	Long: `Run ANALYZE TABLE on replica instances of a MySQLCluster.
+
+HOST: The hostnames of the instances of the MySQLCluster.
+
+The primary instance, i.e., the instance whose super_read_only is
+disabled, is skipped.  ANALYZE TABLE is not written to the binary
+log so that it does not create errant transactions.
+
+If --databases is not given, the tables in all databases except for
+the system ones are analyzed.`,
+	Args: cobra.MinimumNArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		am := backup.NewAnalyzeManager(args, mysqlPassword, analyzeArgs.databases)
+		return am.Analyze(cmd.Context())
+	},
+}
+
+var analyzeArgs struct {
+	databases []string
+}
+
+func init() {
+	fs := analyzeCmd.Flags()
+	fs.StringSliceVar(&analyzeArgs.databases, "databases", nil, "The databases to be analyzed")
+
+	rootCmd.AddCommand(analyzeCmd)
+}
@@ -1,9 +1,12 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/cybozu-go/moco/backup"
+	"github.com/cybozu-go/moco/pkg/bkop"
+	"github.com/cybozu-go/moco/pkg/constants"
 	"github.com/spf13/cobra"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
@@ -15,14 +18,28 @@ var backupCmd = &cobra.Command{
 
 BUCKET:    The bucket name.
 NAMESPACE: The namespace of the MySQLCluster.
-NAME:      The name of the MySQLCluster.`,
+NAME:      The name of the MySQLCluster.
+
+If --include-databases or --exclude-databases is given, only the
+matching databases are dumped.  Such partial backups cannot be used
+for point-in-time recovery.
+
+--part-size is raised for a large file so that the file is uploaded
+within the limit of the number of parts.`,
 	Args: cobra.ExactArgs(3),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		bucketName := args[0]
 		namespace := args[1]
 		name := args[2]
 
-		b, err := makeBucket(bucketName)
+		if len(backupArgs.includeDatabases) > 0 && len(backupArgs.excludeDatabases) > 0 {
+			return errors.New("--include-databases and --exclude-databases are mutually exclusive")
+		}
+		if ps := backupArgs.partSize; ps != 0 && (ps < constants.MinPartSize || ps > constants.MaxPartSize) {
+			return fmt.Errorf("--part-size must be between %d and %d", constants.MinPartSize, constants.MaxPartSize)
+		}
+
+		b, err := makeBucket(bucketName, backupArgs.partSize)
 		if err != nil {
 			return fmt.Errorf("failed to create a bucket interface: %w", err)
 		}
@@ -32,7 +49,12 @@ NAME:      The name of the MySQLCluster.`,
 			return fmt.Errorf("failed to get config for Kubernetes: %w", err)
 		}
 
-		bm, err := backup.NewBackupManager(cfg, b, commonArgs.workDir, namespace, name, mysqlPassword, commonArgs.threads)
+		dumpOpts := bkop.DumpOptions{
+			IncludeDatabases: backupArgs.includeDatabases,
+			ExcludeDatabases: backupArgs.excludeDatabases,
+		}
+
+		bm, err := backup.NewBackupManager(cfg, b, commonArgs.workDir, namespace, name, mysqlPassword, commonArgs.threads, dumpOpts)
 		if err != nil {
 			return fmt.Errorf("failed to create a backup manager: %w", err)
 		}
@@ -40,6 +62,17 @@ NAME:      The name of the MySQLCluster.`,
 	},
 }
 
+var backupArgs struct {
+	includeDatabases []string
+	excludeDatabases []string
+	partSize         int64
+}
+
 func init() {
+	fs := backupCmd.Flags()
+	fs.StringSliceVar(&backupArgs.includeDatabases, "include-databases", nil, "The databases to be backed up")
+	fs.StringSliceVar(&backupArgs.excludeDatabases, "exclude-databases", nil, "The databases not to be backed up")
+	fs.Int64Var(&backupArgs.partSize, "part-size", 0, "The part size in bytes of multipart uploads to S3. If 0, it is decided from the size of each file")
+
 	rootCmd.AddCommand(backupCmd)
 }
@@ -21,7 +21,10 @@ SOURCE_NAMESPACE: The source MySQLCluster's namespace.
 SOURCE_NAME:      The source MySQLCluster's name.
 NAMESPACE:        The target MySQLCluster's namespace.
 NAME:             The target MySQLCluster's name.
-YYYYMMDD-hhmmss:  The point-in-time to restore data.  e.g. 20210523-150423`,
+YYYYMMDD-hhmmss:  The point-in-time to restore data.  e.g. 20210523-150423
+
+If --prefix is given, the backup files are looked up under the prefix
+instead of the one derived from SOURCE_NAMESPACE and SOURCE_NAME.`,
 	Args: cobra.ExactArgs(6),
 	RunE: func(cmd *cobra.Command, args []string) error {
 		maxRetry := 3
@@ -60,7 +63,7 @@ func runRestore(cmd *cobra.Command, args []string) (e error) {
 		return fmt.Errorf("invalid restore point %s: %w", args[5], err)
 	}
 
-	b, err := makeBucket(bucketName)
+	b, err := makeBucket(bucketName, 0)
 	if err != nil {
 		return fmt.Errorf("failed to create a bucket interface: %w", err)
 	}
@@ -71,7 +74,7 @@ func runRestore(cmd *cobra.Command, args []string) (e error) {
 	}
 
 	rm, err := backup.NewRestoreManager(cfg, b, commonArgs.workDir,
-		srcNamespace, srcName,
+		srcNamespace, srcName, restoreArgs.prefix,
 		namespace, name,
 		mysqlPassword,
 		commonArgs.threads,
@@ -82,6 +85,13 @@ func runRestore(cmd *cobra.Command, args []string) (e error) {
 	return rm.Restore(cmd.Context())
 }
 
+var restoreArgs struct {
+	prefix string
+}
+
 func init() {
+	fs := restoreCmd.Flags()
+	fs.StringVar(&restoreArgs.prefix, "prefix", "", "The prefix of the object keys of the backup files")
+
 	rootCmd.AddCommand(restoreCmd)
 }
@@ -27,18 +27,20 @@ var commonArgs struct {
 	caCertFilePath string
 }
 
-func makeBucket(bucketName string) (bucket.Bucket, error) {
+// makeBucket creates a Bucket.  `partSize` is the part size of multipart uploads to S3;
+// it is decided from the size of each file if zero.
+func makeBucket(bucketName string, partSize int64) (bucket.Bucket, error) {
 	switch commonArgs.backendType {
 	case constants.BackendTypeS3:
-		return makeS3Bucket(bucketName)
+		return makeS3Bucket(bucketName, partSize)
 	case constants.BackendTypeGCS:
 		return makeGCSBucket(bucketName)
 	default:
-		return makeS3Bucket(bucketName)
+		return makeS3Bucket(bucketName, partSize)
 	}
 }
 
-func makeS3Bucket(bucketName string) (bucket.Bucket, error) {
+func makeS3Bucket(bucketName string, partSize int64) (bucket.Bucket, error) {
 	var opts []func(*s3.Options)
 	if len(commonArgs.region) > 0 {
 		opts = append(opts, bucket.WithRegion(commonArgs.region))
@@ -70,7 +72,7 @@ func makeS3Bucket(bucketName string) (bucket.Bucket, error) {
 			Transport: transport,
 		}))
 	}
-	return bucket.NewS3Bucket(bucketName, opts...)
+	return bucket.NewS3Bucket(bucketName, partSize, opts...)
 }
 
 func makeGCSBucket(bucketName string) (bucket.Bucket, error) {
@@ -82,8 +84,8 @@ var mysqlPassword = os.Getenv("MYSQL_PASSWORD")
 var rootCmd = &cobra.Command{
 	Use:     "moco-backup",
 	Version: moco.Version,
-	Short:   "backup and restore MySQL data",
-	Long:    "Backup and restore MySQL data.",
+	Short:   "backup, restore, and maintain MySQL data",
+	Long:    "Backup, restore, and maintain MySQL data.",
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		cmd.SilenceUsage = true
 
@@ -13,6 +13,8 @@ import (
 	"github.com/cybozu-go/moco"
 	"github.com/cybozu-go/moco/pkg/constants"
 	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
 )
@@ -22,24 +24,47 @@ var (
 	defaultBackupImage = "ghcr.io/cybozu-go/moco-backup:" + moco.Version
 )
 
+// defaultCertDuration is the default duration of certificates issued by cert-manager.
+const defaultCertDuration = 90 * 24 * time.Hour
+
 var config struct {
-	metricsAddr             string
-	probeAddr               string
-	pprofAddr               string
-	leaderElectionID        string
-	webhookAddr             string
-	certDir                 string
-	grpcCertDir             string
-	agentImage              string
-	backupImage             string
-	fluentBitImage          string
-	exporterImage           string
-	pvcSyncAnnotationKeys   []string
-	pvcSyncLabelKeys        []string
-	interval                time.Duration
-	maxConcurrentReconciles int
-	qps                     int
-	zapOpts                 zap.Options
+	metricsAddr              string
+	probeAddr                string
+	pprofAddr                string
+	leaderElectionID         string
+	webhookAddr              string
+	certDir                  string
+	grpcCertDir              string
+	agentImage               string
+	backupImage              string
+	fluentBitImage           string
+	exporterImage            string
+	pvcSyncAnnotationKeys    []string
+	pvcSyncLabelKeys         []string
+	interval                 time.Duration
+	maxConcurrentReconciles  int
+	qps                      int
+	pdbForTwoReplicas        bool
+	disableAntiAffinity      bool
+	reloaderAnnotations      bool
+	roleLabelKey             string
+	transientBaseBackoff     time.Duration
+	transientMaxBackoff      time.Duration
+	allocateServerID         bool
+	agentCertDuration        time.Duration
+	agentCertRenewBefore     time.Duration
+	agentCertExpiryThreshold time.Duration
+	gracePeriodPerGiB        time.Duration
+	maxGracePeriod           time.Duration
+	canaryReadyTimeout       time.Duration
+	agentGRPCMaxMessageSize  int
+	watchNamespace           string
+	mycnfLabels              map[string]string
+	mycnfAnnotations         map[string]string
+	mysqlDNSOptions          map[string]string
+	defaultMysqldImage       string
+	defaultMysqldRequests    map[string]string
+	zapOpts                  zap.Options
 }
 
 func init() {
@@ -75,6 +100,67 @@ var rootCmd = &cobra.Command{
 		if err != nil {
 			return fmt.Errorf("invalid webhook address: %s, %v", config.webhookAddr, err)
 		}
+		if errs := validation.IsQualifiedName(config.roleLabelKey); len(errs) > 0 {
+			return fmt.Errorf("invalid role label key: %s, %s", config.roleLabelKey, strings.Join(errs, ", "))
+		}
+		if config.transientBaseBackoff > config.transientMaxBackoff {
+			return fmt.Errorf("transient-backoff-base must not be greater than transient-backoff-max")
+		}
+		if config.agentCertDuration < 0 || config.agentCertRenewBefore < 0 {
+			return fmt.Errorf("agent-cert-duration and agent-cert-renew-before must not be negative")
+		}
+		if config.agentCertRenewBefore > 0 {
+			duration := config.agentCertDuration
+			if duration == 0 {
+				duration = defaultCertDuration
+			}
+			if config.agentCertRenewBefore >= duration {
+				return fmt.Errorf("agent-cert-renew-before must be less than the duration of the certificate (%s)", duration)
+			}
+		}
+		if config.agentCertExpiryThreshold < 0 {
+			return fmt.Errorf("agent-cert-expiry-threshold must not be negative")
+		}
+		if config.gracePeriodPerGiB < 0 || config.maxGracePeriod < 0 {
+			return fmt.Errorf("termination-grace-period-per-gib and max-termination-grace-period must not be negative")
+		}
+		if config.canaryReadyTimeout < 0 {
+			return fmt.Errorf("canary-ready-timeout must not be negative")
+		}
+		if config.agentGRPCMaxMessageSize < 0 {
+			return fmt.Errorf("agent-grpc-max-message-size must not be negative")
+		}
+		if config.watchNamespace != "" {
+			if errs := validation.IsDNS1123Label(config.watchNamespace); len(errs) > 0 {
+				return fmt.Errorf("invalid watch namespace: %s, %s", config.watchNamespace, strings.Join(errs, ", "))
+			}
+		}
+		for k, v := range config.mycnfLabels {
+			if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+				return fmt.Errorf("invalid label key of my.cnf ConfigMap: %s, %s", k, strings.Join(errs, ", "))
+			}
+			if errs := validation.IsValidLabelValue(v); len(errs) > 0 {
+				return fmt.Errorf("invalid label value of my.cnf ConfigMap: %s, %s", v, strings.Join(errs, ", "))
+			}
+		}
+		for k := range config.mycnfAnnotations {
+			if errs := validation.IsQualifiedName(k); len(errs) > 0 {
+				return fmt.Errorf("invalid annotation key of my.cnf ConfigMap: %s, %s", k, strings.Join(errs, ", "))
+			}
+		}
+		for k := range config.mysqlDNSOptions {
+			if k == "" {
+				return fmt.Errorf("mysql-dns-options must not have an empty name")
+			}
+		}
+		for k, v := range config.defaultMysqldRequests {
+			if k == "" {
+				return fmt.Errorf("default-mysqld-requests must not have an empty resource name")
+			}
+			if _, err := resource.ParseQuantity(v); err != nil {
+				return fmt.Errorf("invalid quantity of default-mysqld-requests: %s=%s, %v", k, v, err)
+			}
+		}
 		ns := os.Getenv(constants.PodNamespaceEnvKey)
 		if ns == "" {
 			return fmt.Errorf("no environment variable %s", constants.PodNamespaceEnvKey)
@@ -109,6 +195,26 @@ func init() {
 	fs.StringSliceVar(&config.pvcSyncLabelKeys, "pvc-sync-label-keys", []string{}, "The keys of labels from MySQLCluster's volumeClaimTemplates to be synced to the PVC")
 	fs.DurationVar(&config.interval, "check-interval", 1*time.Minute, "Interval of cluster maintenance")
 	fs.IntVar(&config.maxConcurrentReconciles, "max-concurrent-reconciles", 8, "The maximum number of concurrent reconciles which can be run")
+	fs.StringVar(&config.roleLabelKey, "role-label-key", constants.LabelMocoRole, "The key of the label to represent the role of MySQL Pods. This is applied only to newly created clusters")
+	fs.DurationVar(&config.transientBaseBackoff, "transient-backoff-base", 5*time.Second, "The initial delay to requeue a cluster after a transient error in reconciling backup or restore resources. 0 disables it")
+	fs.DurationVar(&config.transientMaxBackoff, "transient-backoff-max", 5*time.Minute, "The maximum delay to requeue a cluster after transient errors in reconciling backup or restore resources")
+	fs.BoolVar(&config.disableAntiAffinity, "disable-default-anti-affinity", false, "Do not add the default preferred pod anti-affinity to MySQL Pods without affinity")
+	fs.BoolVar(&config.reloaderAnnotations, "reloader-annotations", false, "Annotate StatefulSets of MySQL with the names of my.cnf ConfigMap and Secret for Stakater Reloader")
+	fs.BoolVar(&config.allocateServerID, "allocate-server-id", false, "Allocate non-overlapping server ID ranges to new MySQLClusters instead of random ones")
+	fs.DurationVar(&config.agentCertDuration, "agent-cert-duration", 0, "The duration of the certificate for moco-agent. 0 uses the default of cert-manager")
+	fs.DurationVar(&config.agentCertRenewBefore, "agent-cert-renew-before", 0, "How long before the expiry the certificate for moco-agent is renewed. 0 uses the default of cert-manager")
+	fs.DurationVar(&config.agentCertExpiryThreshold, "agent-cert-expiry-threshold", 7*24*time.Hour, "How long before the expiry of the certificate for moco-agent the CertificateExpiringSoon condition of MySQLCluster becomes true. 0 disables the condition")
+	fs.DurationVar(&config.gracePeriodPerGiB, "termination-grace-period-per-gib", 0, "The termination grace period of MySQL Pods per GiB of innodb_buffer_pool_size, used when the Pod template does not specify one. 0 uses the static default of 300s")
+	fs.DurationVar(&config.maxGracePeriod, "max-termination-grace-period", 1*time.Hour, "The maximum termination grace period derived from innodb_buffer_pool_size. 0 means no limit")
+	fs.DurationVar(&config.canaryReadyTimeout, "canary-ready-timeout", 30*time.Minute, "How long the canary upgrade waits for the updated instances to become ready before recording a warning event. 0 disables it")
+	fs.IntVar(&config.agentGRPCMaxMessageSize, "agent-grpc-max-message-size", 0, "The maximum size in bytes of gRPC messages sent to and received from moco-agent. 0 uses the defaults of gRPC")
+	fs.StringVar(&config.watchNamespace, "watch-namespace", "", "The only namespace of MySQLClusters to be managed. All namespaces are watched if empty")
+	fs.StringToStringVar(&config.mycnfLabels, "mycnf-configmap-labels", nil, "Extra labels of the ConfigMaps for my.cnf, e.g. to be ignored by GitOps tools")
+	fs.StringToStringVar(&config.mycnfAnnotations, "mycnf-configmap-annotations", nil, "Extra annotations of the ConfigMaps for my.cnf, e.g. argocd.argoproj.io/compare-options=IgnoreExtraneous")
+	fs.StringToStringVar(&config.mysqlDNSOptions, "mysql-dns-options", nil, "DNS resolver options of MySQL Pods whose Pod template does not specify dnsConfig, e.g. ndots=2")
+	fs.StringVar(&config.defaultMysqldImage, "default-mysqld-image", "", "The image of mysqld container added to new MySQLClusters whose Pod template does not have it. Empty disables the defaulting")
+	fs.StringToStringVar(&config.defaultMysqldRequests, "default-mysqld-requests", map[string]string{"cpu": "1", "memory": "1Gi"}, "The resource requests of mysqld container added by --default-mysqld-image")
+	fs.BoolVar(&config.pdbForTwoReplicas, "pdb-for-two-replicas", false, "Create a PodDisruptionBudget with maxUnavailable=1 for clusters with 2 replicas")
 	// The default QPS is 20.
 	// https://github.com/kubernetes-sigs/controller-runtime/blob/a26de2d610c3cf4b2a02688534aaf5a65749c743/pkg/client/config/config.go#L84-L85
 	fs.IntVar(&config.qps, "apiserver-qps-throttle", 20, "The maximum QPS to the API server.")
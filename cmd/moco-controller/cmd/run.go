@@ -12,10 +12,14 @@ import (
 	"github.com/cybozu-go/moco/pkg/dbop"
 	"github.com/cybozu-go/moco/pkg/metrics"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/version"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
@@ -52,6 +56,45 @@ func (r resolver) Resolve(ctx context.Context, cluster *mocov1beta2.MySQLCluster
 	return pod.Status.PodIP, nil
 }
 
+func serverVersion(dc discovery.ServerVersionInterface) (*version.Version, error) {
+	info, err := dc.ServerVersion()
+	if err != nil {
+		return nil, err
+	}
+	return version.ParseGeneric(info.GitVersion)
+}
+
+// statefulSetOrdinalsSupported returns true if the API server supports `spec.ordinals` of StatefulSet.
+// The feature is enabled by default since Kubernetes 1.27.
+func statefulSetOrdinalsSupported(v *version.Version) bool {
+	return v.AtLeast(version.MustParseGeneric("1.27"))
+}
+
+// pvcRetentionPolicySupported returns true if the API server supports `spec.persistentVolumeClaimRetentionPolicy` of StatefulSet.
+// The feature is enabled by default since Kubernetes 1.27.
+func pvcRetentionPolicySupported(v *version.Version) bool {
+	return v.AtLeast(version.MustParseGeneric("1.27"))
+}
+
+// podFailurePolicySupported returns true if the API server supports `spec.podFailurePolicy` of Job.
+// The feature is enabled by default since Kubernetes 1.26.
+func podFailurePolicySupported(v *version.Version) bool {
+	return v.AtLeast(version.MustParseGeneric("1.26"))
+}
+
+// cacheOptions returns the options of the cache of the manager.
+// If watchNS is not empty, the cache is scoped to it and the namespace of the controller
+// where the certificates and Secrets for moco-agent are.
+func cacheOptions(watchNS, systemNS string) cache.Options {
+	if watchNS == "" {
+		return cache.Options{}
+	}
+	if watchNS == systemNS {
+		return cache.Options{Namespaces: []string{watchNS}}
+	}
+	return cache.Options{Namespaces: []string{watchNS, systemNS}}
+}
+
 func subMain(ns, addr string, port int) error {
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&config.zapOpts)))
 	setupLog := ctrl.Log.WithName("setup")
@@ -68,6 +111,7 @@ func subMain(ns, addr string, port int) error {
 
 	mgr, err := ctrl.NewManager(restCfg, ctrl.Options{
 		Scheme:                  scheme,
+		Cache:                   cacheOptions(config.watchNamespace, ns),
 		MetricsBindAddress:      config.metricsAddr,
 		HealthProbeBindAddress:  config.probeAddr,
 		PprofBindAddress:        config.pprofAddr,
@@ -83,6 +127,46 @@ func subMain(ns, addr string, port int) error {
 		return err
 	}
 
+	if config.watchNamespace != "" {
+		if err := mgr.GetAPIReader().Get(context.Background(), client.ObjectKey{Name: config.watchNamespace}, &corev1.Namespace{}); err != nil {
+			setupLog.Error(err, "failed to get the namespace to watch", "namespace", config.watchNamespace)
+			return err
+		}
+		setupLog.Info("watching only one namespace", "namespace", config.watchNamespace)
+	}
+
+	dc, err := discovery.NewDiscoveryClientForConfig(restCfg)
+	if err != nil {
+		setupLog.Error(err, "failed to create discovery client")
+		return err
+	}
+	serverVer, err := serverVersion(dc)
+	if err != nil {
+		setupLog.Error(err, "failed to get the version of the API server")
+		return err
+	}
+	ordinals := statefulSetOrdinalsSupported(serverVer)
+	if !ordinals {
+		setupLog.Info("StatefulSet ordinals are not supported; new MySQLClusters with spec.ordinals will be rejected")
+	}
+	pvcRetentionPolicy := pvcRetentionPolicySupported(serverVer)
+	if !pvcRetentionPolicy {
+		setupLog.Info("PVC retention policy of StatefulSets is not supported; spec.persistentVolumeClaimRetentionPolicy of MySQLCluster will be ignored")
+	}
+	podFailurePolicy := podFailurePolicySupported(serverVer)
+	if !podFailurePolicy {
+		setupLog.Info("pod failure policy of Jobs is not supported; podFailurePolicy of jobConfig will be ignored")
+	}
+
+	var idAllocator *controllers.ServerIDAllocator
+	if config.allocateServerID {
+		idAllocator = &controllers.ServerIDAllocator{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Namespace: ns,
+		}
+	}
+
 	r := resolver{reader: mgr.GetClient()}
 	opf := dbop.NewFactory(r)
 	defer opf.Cleanup()
@@ -91,23 +175,43 @@ func subMain(ns, addr string, port int) error {
 		setupLog.Error(err, "failed to initialize gRPC certificate loader")
 		return err
 	}
-	af := clustering.NewAgentFactory(r, reloader)
+	af := clustering.NewAgentFactory(r, reloader, config.agentGRPCMaxMessageSize)
 	clusterMgr := clustering.NewClusterManager(config.interval, mgr, opf, af, clusterLog)
 	defer clusterMgr.StopAll()
 
 	if err = (&controllers.MySQLClusterReconciler{
-		Client:                  mgr.GetClient(),
-		Scheme:                  mgr.GetScheme(),
-		Recorder:                mgr.GetEventRecorderFor("moco-controller"),
-		AgentImage:              config.agentImage,
-		BackupImage:             config.backupImage,
-		FluentBitImage:          config.fluentBitImage,
-		ExporterImage:           config.exporterImage,
-		SystemNamespace:         ns,
-		PVCSyncAnnotationKeys:   config.pvcSyncAnnotationKeys,
-		PVCSyncLabelKeys:        config.pvcSyncLabelKeys,
-		ClusterManager:          clusterMgr,
-		MaxConcurrentReconciles: config.maxConcurrentReconciles,
+		Client:                     mgr.GetClient(),
+		Scheme:                     mgr.GetScheme(),
+		Recorder:                   mgr.GetEventRecorderFor("moco-controller"),
+		AgentImage:                 config.agentImage,
+		BackupImage:                config.backupImage,
+		FluentBitImage:             config.fluentBitImage,
+		ExporterImage:              config.exporterImage,
+		SystemNamespace:            ns,
+		WatchNamespace:             config.watchNamespace,
+		PVCSyncAnnotationKeys:      config.pvcSyncAnnotationKeys,
+		PVCSyncLabelKeys:           config.pvcSyncLabelKeys,
+		ClusterManager:             clusterMgr,
+		MaxConcurrentReconciles:    config.maxConcurrentReconciles,
+		PDBForTwoReplicas:          config.pdbForTwoReplicas,
+		DisableDefaultAntiAffinity: config.disableAntiAffinity,
+		ReloaderAnnotations:        config.reloaderAnnotations,
+		RoleLabelKey:               config.roleLabelKey,
+		TransientErrorBaseBackoff:  config.transientBaseBackoff,
+		TransientErrorMaxBackoff:   config.transientMaxBackoff,
+		StatefulSetOrdinals:        ordinals,
+		PVCRetentionPolicy:         pvcRetentionPolicy,
+		PodFailurePolicy:           podFailurePolicy,
+		ServerIDAllocator:          idAllocator,
+		AgentCertDuration:          config.agentCertDuration,
+		AgentCertRenewBefore:       config.agentCertRenewBefore,
+		AgentCertExpiryThreshold:   config.agentCertExpiryThreshold,
+		GracePeriodPerGiB:          config.gracePeriodPerGiB,
+		MaxGracePeriod:             config.maxGracePeriod,
+		CanaryReadyTimeout:         config.canaryReadyTimeout,
+		MyCnfConfigMapLabels:       config.mycnfLabels,
+		MyCnfConfigMapAnnotations:  config.mycnfAnnotations,
+		MySQLDNSOptions:            config.mysqlDNSOptions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "MySQLCluster")
 		return err
@@ -122,7 +226,21 @@ func subMain(ns, addr string, port int) error {
 		return err
 	}
 
-	if err = (&mocov1beta2.MySQLCluster{}).SetupWebhookWithManager(mgr); err != nil {
+	var serverIDAllocator mocov1beta2.ServerIDAllocator
+	if idAllocator != nil {
+		serverIDAllocator = idAllocator
+	}
+	var mysqldDefaults *mocov1beta2.MysqldContainerDefaults
+	if config.defaultMysqldImage != "" {
+		mysqldDefaults = &mocov1beta2.MysqldContainerDefaults{
+			Image:    config.defaultMysqldImage,
+			Requests: make(corev1.ResourceList),
+		}
+		for k, v := range config.defaultMysqldRequests {
+			mysqldDefaults.Requests[corev1.ResourceName(k)] = resource.MustParse(v)
+		}
+	}
+	if err = (&mocov1beta2.MySQLCluster{}).SetupWebhookWithManager(mgr, serverIDAllocator, ordinals, mysqldDefaults); err != nil {
 		setupLog.Error(err, "unable to setup webhook", "webhook", "MySQLCluster")
 		return err
 	}
@@ -132,6 +250,11 @@ func subMain(ns, addr string, port int) error {
 		return err
 	}
 
+	if err = mocov1beta2.SetupPodWebhookWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to setup webhook", "webhook", "Pod")
+		return err
+	}
+
 	if err := mgr.AddHealthzCheck("health", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		return err
@@ -141,6 +264,16 @@ func subMain(ns, addr string, port int) error {
 		return err
 	}
 
+	if err := mgr.AddMetricsExtraHandler(controllers.ClusterHealthPath, controllers.ClusterHealthHandler{Reader: mgr.GetClient()}); err != nil {
+		setupLog.Error(err, "unable to set up cluster health handler")
+		return err
+	}
+
+	if err := mgr.AddMetricsExtraHandler(controllers.ClusterStatePath, controllers.ClusterStateHandler{ClusterManager: clusterMgr}); err != nil {
+		setupLog.Error(err, "unable to set up cluster state handler")
+		return err
+	}
+
 	metrics.Register(k8smetrics.Registry)
 
 	setupLog.Info("starting manager")
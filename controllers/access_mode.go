@@ -0,0 +1,110 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/event"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const annDefaultStorageClass = "storageclass.kubernetes.io/is-default-class"
+
+// singleNodeProvisioners is the set of provisioners whose volumes can be attached
+// to only one node at a time.  Volumes of these provisioners cannot satisfy
+// ReadWriteMany or ReadOnlyMany.
+var singleNodeProvisioners = map[string]bool{
+	"kubernetes.io/aws-ebs":        true,
+	"ebs.csi.aws.com":              true,
+	"kubernetes.io/gce-pd":         true,
+	"pd.csi.storage.gke.io":        true,
+	"kubernetes.io/azure-disk":     true,
+	"disk.csi.azure.com":           true,
+	"kubernetes.io/cinder":         true,
+	"cinder.csi.openstack.org":     true,
+	"kubernetes.io/no-provisioner": true,
+	"rancher.io/local-path":        true,
+	"topolvm.io":                   true,
+	"topolvm.cybozu.com":           true,
+}
+
+// checkVolumeAccessModes checks the access modes of the volume claim templates against
+// the provisioners of their StorageClasses.  If a template requests an access mode that
+// the provisioner is unlikely to satisfy, it emits a VolumeAccessModeUnsupported event
+// because the Pods would otherwise be stuck in Pending with a cryptic error.
+// The event is recorded once for each volume claim template.
+//
+// This is only a warning and never blocks the reconciliation.
+// Unknown provisioners are assumed to support any access mode.
+func (r *MySQLClusterReconciler) checkVolumeAccessModes(ctx context.Context, cluster *mocov1beta2.MySQLCluster) {
+	log := crlog.FromContext(ctx)
+
+	for _, vct := range cluster.Spec.VolumeClaimTemplates {
+		slot := "AccessMode/" + vct.Name
+		modes := multiNodeAccessModes(vct.Spec.AccessModes)
+		if len(modes) == 0 {
+			r.eventTracker.clear(cluster, slot)
+			continue
+		}
+
+		sc, err := r.storageClassFor(ctx, vct.Spec.StorageClassName)
+		if err != nil {
+			log.Error(err, "failed to get StorageClass", "volumeClaimTemplate", vct.Name)
+			continue
+		}
+		if sc == nil || !singleNodeProvisioners[sc.Provisioner] {
+			r.eventTracker.clear(cluster, slot)
+			continue
+		}
+
+		msg := make([]string, len(modes))
+		for i, m := range modes {
+			msg[i] = string(m)
+		}
+		log.Info("StorageClass may not satisfy the access modes", "volumeClaimTemplate", vct.Name, "storageClass", sc.Name, "accessModes", modes)
+		r.eventTracker.emit(cluster, r.Recorder, slot, event.VolumeAccessModeUnsupported, vct.Name, strings.Join(msg, ", "), sc.Name, sc.Provisioner)
+	}
+}
+
+// storageClassFor returns the StorageClass used by a PVC with `name` as its storageClassName.
+// It returns nil if the StorageClass is not found or the PVC is not to be provisioned dynamically.
+func (r *MySQLClusterReconciler) storageClassFor(ctx context.Context, name *string) (*storagev1.StorageClass, error) {
+	if name != nil {
+		if *name == "" {
+			return nil, nil
+		}
+		sc := &storagev1.StorageClass{}
+		if err := r.Get(ctx, client.ObjectKey{Name: *name}, sc); err != nil {
+			return nil, client.IgnoreNotFound(err)
+		}
+		return sc, nil
+	}
+
+	classes := &storagev1.StorageClassList{}
+	if err := r.List(ctx, classes); err != nil {
+		return nil, fmt.Errorf("failed to list StorageClasses: %w", err)
+	}
+	for i := range classes.Items {
+		if classes.Items[i].Annotations[annDefaultStorageClass] == "true" {
+			return &classes.Items[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// multiNodeAccessModes returns the access modes in `modes` that require a volume to be
+// attached to multiple nodes.
+func multiNodeAccessModes(modes []corev1.PersistentVolumeAccessMode) []corev1.PersistentVolumeAccessMode {
+	var ret []corev1.PersistentVolumeAccessMode
+	for _, m := range modes {
+		if m == corev1.ReadWriteMany || m == corev1.ReadOnlyMany {
+			ret = append(ret, m)
+		}
+	}
+	return ret
+}
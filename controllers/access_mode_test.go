@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckVolumeAccessModes(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := mocov1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	storageClasses := []runtime.Object{
+		&storagev1.StorageClass{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "block",
+				Annotations: map[string]string{annDefaultStorageClass: "true"},
+			},
+			Provisioner: "ebs.csi.aws.com",
+		},
+		&storagev1.StorageClass{
+			ObjectMeta:  metav1.ObjectMeta{Name: "shared"},
+			Provisioner: "efs.csi.aws.com",
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		storageClass *string
+		accessModes  []corev1.PersistentVolumeAccessMode
+		expectEvent  bool
+	}{
+		{
+			name:         "rwo on block storage",
+			storageClass: ptr.To("block"),
+			accessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+		},
+		{
+			name:         "rwx on block storage",
+			storageClass: ptr.To("block"),
+			accessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			expectEvent:  true,
+		},
+		{
+			name:        "rox on the default block storage",
+			accessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce, corev1.ReadOnlyMany},
+			expectEvent: true,
+		},
+		{
+			name:         "rwx on shared storage",
+			storageClass: ptr.To("shared"),
+			accessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+		},
+		{
+			name:         "rwx on a missing storage class",
+			storageClass: ptr.To("missing"),
+			accessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+		},
+		{
+			name:         "rwx without dynamic provisioning",
+			storageClass: ptr.To(""),
+			accessModes:  []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Namespace = "test"
+			cluster.Name = "test"
+			cluster.Spec.VolumeClaimTemplates = []mocov1beta2.PersistentVolumeClaim{
+				{
+					ObjectMeta: mocov1beta2.ObjectMeta{Name: "mysql-data"},
+					Spec: mocov1beta2.PersistentVolumeClaimSpecApplyConfiguration{
+						StorageClassName: tc.storageClass,
+						AccessModes:      tc.accessModes,
+					},
+				},
+			}
+
+			recorder := record.NewFakeRecorder(10)
+			r := &MySQLClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(storageClasses...).Build(),
+				Recorder: recorder,
+			}
+			r.checkVolumeAccessModes(context.Background(), cluster)
+			r.checkVolumeAccessModes(context.Background(), cluster)
+
+			var events []string
+			for len(recorder.Events) > 0 {
+				events = append(events, <-recorder.Events)
+			}
+			if !tc.expectEvent {
+				if len(events) != 0 {
+					t.Errorf("unexpected events: %v", events)
+				}
+				return
+			}
+			if len(events) != 1 {
+				t.Fatalf("unexpected events: %v", events)
+			}
+			if !strings.Contains(events[0], "VolumeAccessModeUnsupported") || !strings.Contains(events[0], "mysql-data") {
+				t.Errorf("unexpected event: %s", events[0])
+			}
+		})
+	}
+}
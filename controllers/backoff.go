@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// isTransientError returns true if err is likely to be resolved by retrying later.
+// The following errors are treated as transient:
+//
+//   - 429 Too Many Requests (API server throttling)
+//   - 503 Service Unavailable
+//   - 504 Gateway Timeout and other server-side timeouts
+//   - context.DeadlineExceeded
+func isTransientError(err error) bool {
+	return apierrors.IsTooManyRequests(err) ||
+		apierrors.IsServiceUnavailable(err) ||
+		apierrors.IsServerTimeout(err) ||
+		apierrors.IsTimeout(err) ||
+		errors.Is(err, context.DeadlineExceeded)
+}
+
+// transientBackoff calculates the delay to requeue a cluster after consecutive transient errors.
+// The zero value is ready to use.
+type transientBackoff struct {
+	mu       sync.Mutex
+	failures map[types.NamespacedName]int
+}
+
+// next records a failure for key and returns the delay before the next attempt.
+// The delay is base * 2^(failures-1), capped by limit.
+func (b *transientBackoff) next(key types.NamespacedName, base, limit time.Duration) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.failures == nil {
+		b.failures = make(map[types.NamespacedName]int)
+	}
+	n := b.failures[key]
+	b.failures[key] = n + 1
+
+	d := base
+	for i := 0; i < n && d < limit; i++ {
+		d *= 2
+	}
+	if limit > 0 && d > limit {
+		d = limit
+	}
+	return d
+}
+
+// reset forgets the failures recorded for key.
+func (b *transientBackoff) reset(key types.NamespacedName) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	delete(b.failures, key)
+}
+
+// requeueTransient returns the result to requeue the cluster after a transient error.
+func (r *MySQLClusterReconciler) requeueTransient(ctx context.Context, req ctrl.Request, err error) ctrl.Result {
+	log := crlog.FromContext(ctx)
+
+	d := r.transientBackoff.next(req.NamespacedName, r.TransientErrorBaseBackoff, r.TransientErrorMaxBackoff)
+	log.Error(err, "transient error; requeue later", "requeueAfter", d)
+	return ctrl.Result{RequeueAfter: d}
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestIsTransientError(t *testing.T) {
+	gr := schema.GroupResource{Group: "batch", Resource: "cronjobs"}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"too many requests", apierrors.NewTooManyRequests("throttled", 1), true},
+		{"service unavailable", apierrors.NewServiceUnavailable("unavailable"), true},
+		{"server timeout", apierrors.NewServerTimeout(gr, "create", 1), true},
+		{"timeout", apierrors.NewTimeoutError("timeout", 1), true},
+		{"deadline exceeded", context.DeadlineExceeded, true},
+		{"wrapped", fmt.Errorf("failed to reconcile: %w", apierrors.NewTooManyRequests("throttled", 1)), true},
+		{"not found", apierrors.NewNotFound(gr, "foo"), false},
+		{"invalid", apierrors.NewBadRequest("bad"), false},
+		{"other", errors.New("error"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isTransientError(tt.err); got != tt.want {
+				t.Errorf("isTransientError() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTransientBackoff(t *testing.T) {
+	var b transientBackoff
+	key1 := types.NamespacedName{Namespace: "test", Name: "a"}
+	key2 := types.NamespacedName{Namespace: "test", Name: "b"}
+
+	base := time.Second
+	limit := 5 * time.Second
+	for i, want := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if got := b.next(key1, base, limit); got != want {
+			t.Errorf("%d: next() = %v, want %v", i, got, want)
+		}
+	}
+
+	if got := b.next(key2, base, limit); got != base {
+		t.Errorf("next() for another key = %v, want %v", got, base)
+	}
+
+	b.reset(key1)
+	if got := b.next(key1, base, limit); got != base {
+		t.Errorf("next() after reset = %v, want %v", got, base)
+	}
+}
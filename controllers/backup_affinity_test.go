@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+)
+
+func TestAddBackupAffinity(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Namespace = "test"
+	cluster.Name = "test"
+
+	term := func(role string) *corev1ac.WeightedPodAffinityTermApplyConfiguration {
+		return corev1ac.WeightedPodAffinityTerm().
+			WithWeight(100).
+			WithPodAffinityTerm(corev1ac.PodAffinityTerm().
+				WithLabelSelector(metav1ac.LabelSelector().WithMatchLabels(map[string]string{
+					constants.LabelAppName:      constants.AppNameMySQL,
+					constants.LabelAppInstance:  "test",
+					constants.LabelAppCreatedBy: constants.AppCreator,
+					constants.LabelMocoRole:     role,
+				})).
+				WithTopologyKey(corev1.LabelHostname))
+	}
+	userTerm := corev1ac.WeightedPodAffinityTerm().
+		WithWeight(10).
+		WithPodAffinityTerm(corev1ac.PodAffinityTerm().WithTopologyKey(corev1.LabelTopologyZone))
+
+	cases := []struct {
+		name     string
+		mode     string
+		affinity *corev1ac.AffinityApplyConfiguration
+		want     *corev1ac.AffinityApplyConfiguration
+	}{
+		{
+			name: "none",
+			mode: mocov1beta2.BackupAffinityNone,
+		},
+		{
+			name: "empty",
+			mode: "",
+		},
+		{
+			name: "prefer replica",
+			mode: mocov1beta2.BackupAffinityPreferReplica,
+			want: corev1ac.Affinity().
+				WithPodAffinity(corev1ac.PodAffinity().
+					WithPreferredDuringSchedulingIgnoredDuringExecution(term(constants.RoleReplica))),
+		},
+		{
+			name: "avoid primary",
+			mode: mocov1beta2.BackupAffinityAvoidPrimary,
+			want: corev1ac.Affinity().
+				WithPodAntiAffinity(corev1ac.PodAntiAffinity().
+					WithPreferredDuringSchedulingIgnoredDuringExecution(term(constants.RolePrimary))),
+		},
+		{
+			name: "avoid primary with the existing affinity",
+			mode: mocov1beta2.BackupAffinityAvoidPrimary,
+			affinity: corev1ac.Affinity().
+				WithPodAntiAffinity(corev1ac.PodAntiAffinity().
+					WithPreferredDuringSchedulingIgnoredDuringExecution(userTerm)),
+			want: corev1ac.Affinity().
+				WithPodAntiAffinity(corev1ac.PodAntiAffinity().
+					WithPreferredDuringSchedulingIgnoredDuringExecution(userTerm, term(constants.RolePrimary))),
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			podSpec := corev1ac.PodSpec()
+			if tc.affinity != nil {
+				podSpec.WithAffinity(tc.affinity)
+			}
+			addBackupAffinity(podSpec, cluster, tc.mode)
+			if diff := cmp.Diff(tc.want, podSpec.Affinity); diff != "" {
+				t.Errorf("unexpected affinity (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
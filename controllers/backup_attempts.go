@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateBackupAttempts records the results of the recent backup Jobs in the status of `cluster`.
+// The previous list is kept if the Jobs cannot be listed.
+func (r *MySQLClusterReconciler) updateBackupAttempts(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	jobs := &batchv1.JobList{}
+	if err := r.List(ctx, jobs, client.InNamespace(cluster.Namespace), client.MatchingLabels(labelSetForJob(cluster))); err != nil {
+		return err
+	}
+
+	cluster.Status.BackupAttempts = backupAttempts(jobs.Items, cluster.BackupCronJobName(), constants.BackupAttemptsHistoryLimit)
+	return nil
+}
+
+// backupAttempts returns the results of at most `limit` Jobs created by the CronJob named `cronJobName`, newest first.
+func backupAttempts(jobs []batchv1.Job, cronJobName string, limit int) []mocov1beta2.BackupAttemptStatus {
+	var owned []*batchv1.Job
+	for i := range jobs {
+		job := &jobs[i]
+		for _, ref := range job.OwnerReferences {
+			if ref.Kind == "CronJob" && ref.Name == cronJobName {
+				owned = append(owned, job)
+				break
+			}
+		}
+	}
+	sort.SliceStable(owned, func(i, j int) bool {
+		return owned[j].CreationTimestamp.Before(&owned[i].CreationTimestamp)
+	})
+	if len(owned) > limit {
+		owned = owned[:limit]
+	}
+
+	var attempts []mocov1beta2.BackupAttemptStatus
+	for _, job := range owned {
+		attempt := mocov1beta2.BackupAttemptStatus{
+			JobName:   job.Name,
+			StartTime: job.Status.StartTime,
+			Result:    mocov1beta2.BackupAttemptRunning,
+			Failures:  job.Status.Failed,
+		}
+		for _, cond := range job.Status.Conditions {
+			if cond.Status != corev1.ConditionTrue {
+				continue
+			}
+			switch cond.Type {
+			case batchv1.JobComplete:
+				attempt.Result = mocov1beta2.BackupAttemptSucceeded
+				attempt.CompletionTime = job.Status.CompletionTime
+			case batchv1.JobFailed:
+				attempt.Result = mocov1beta2.BackupAttemptFailed
+				attempt.CompletionTime = cond.LastTransitionTime.DeepCopy()
+				attempt.Reason = cond.Reason
+			}
+		}
+		attempts = append(attempts, attempt)
+	}
+	return attempts
+}
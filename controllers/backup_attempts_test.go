@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestBackupAttempts(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newJob := func(name, owner string, created int, failed int32, cond batchv1.JobConditionType, reason string) batchv1.Job {
+		job := batchv1.Job{}
+		job.Name = name
+		job.CreationTimestamp = metav1.NewTime(base.Add(time.Duration(created) * time.Hour))
+		job.OwnerReferences = []metav1.OwnerReference{{Kind: "CronJob", Name: owner}}
+		job.Status.StartTime = &job.CreationTimestamp
+		job.Status.Failed = failed
+		if cond != "" {
+			job.Status.Conditions = []batchv1.JobCondition{{
+				Type:               cond,
+				Status:             corev1.ConditionTrue,
+				Reason:             reason,
+				LastTransitionTime: metav1.NewTime(job.CreationTimestamp.Add(time.Minute)),
+			}}
+			if cond == batchv1.JobComplete {
+				completion := metav1.NewTime(job.CreationTimestamp.Add(time.Minute))
+				job.Status.CompletionTime = &completion
+			}
+		}
+		return job
+	}
+
+	jobs := []batchv1.Job{
+		newJob("backup-1", "moco-backup-test", 1, 0, batchv1.JobComplete, ""),
+		newJob("backup-3", "moco-backup-test", 3, 0, "", ""),
+		newJob("backup-2", "moco-backup-test", 2, 6, batchv1.JobFailed, "BackoffLimitExceeded"),
+		newJob("other", "moco-backup-other", 4, 0, batchv1.JobComplete, ""),
+		newJob("backup-0", "moco-backup-test", 0, 1, batchv1.JobComplete, ""),
+	}
+
+	attempts := backupAttempts(jobs, "moco-backup-test", 3)
+	if len(attempts) != 3 {
+		t.Fatalf("unexpected number of attempts: %d", len(attempts))
+	}
+
+	expected := []struct {
+		name     string
+		result   string
+		failures int32
+		reason   string
+	}{
+		{"backup-3", mocov1beta2.BackupAttemptRunning, 0, ""},
+		{"backup-2", mocov1beta2.BackupAttemptFailed, 6, "BackoffLimitExceeded"},
+		{"backup-1", mocov1beta2.BackupAttemptSucceeded, 0, ""},
+	}
+	for i, e := range expected {
+		a := attempts[i]
+		if a.JobName != e.name || a.Result != e.result || a.Failures != e.failures || a.Reason != e.reason {
+			t.Errorf("unexpected attempt #%d: %+v", i, a)
+		}
+		if a.StartTime == nil {
+			t.Errorf("start time of %s is not set", a.JobName)
+		}
+		if (a.Result == mocov1beta2.BackupAttemptRunning) != (a.CompletionTime == nil) {
+			t.Errorf("unexpected completion time of %s: %v", a.JobName, a.CompletionTime)
+		}
+	}
+
+	if attempts := backupAttempts(nil, "moco-backup-test", 3); attempts != nil {
+		t.Errorf("unexpected attempts for no jobs: %v", attempts)
+	}
+}
@@ -0,0 +1,237 @@
+package controllers
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/event"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// canaryState is the progress of the canary upgrade of a cluster.
+type canaryState struct {
+	// partition is the partition of the StatefulSet, and since is when it was set.
+	partition int32
+	since     time.Time
+
+	// held and stalled tell that the warning events have been recorded for the partition.
+	held    bool
+	stalled bool
+}
+
+// canaryTracker remembers the progress of canary upgrades so that the warning events
+// are recorded only once for each partition.  The zero value is ready to use.
+type canaryTracker struct {
+	mu     sync.Mutex
+	states map[types.NamespacedName]canaryState
+}
+
+// get returns the state of key for partition.
+// The state is reset if the partition has been changed.
+func (t *canaryTracker) get(key types.NamespacedName, partition int32, now time.Time) canaryState {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[key]
+	if !ok || st.partition != partition {
+		st = canaryState{partition: partition, since: now}
+	}
+	return st
+}
+
+func (t *canaryTracker) set(key types.NamespacedName, st canaryState) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.states == nil {
+		t.states = make(map[types.NamespacedName]canaryState)
+	}
+	t.states[key] = st
+}
+
+// forget removes the state of key.
+func (t *canaryTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.states, key)
+}
+
+// untilStalled returns the duration until the canary upgrade of key is regarded as stalled.
+// It returns 0 if the upgrade is not in progress or the event has already been recorded.
+func (t *canaryTracker) untilStalled(key types.NamespacedName, timeout time.Duration, now time.Time) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.states[key]
+	if !ok || timeout <= 0 || st.held || st.stalled {
+		return 0
+	}
+	d := st.since.Add(timeout).Sub(now)
+	if d < 0 {
+		return 0
+	}
+	// requeue a little later so that the upgrade is surely regarded as stalled.
+	return d + time.Second
+}
+
+// canaryPartition decides the partition of the StatefulSet for `spec.canaryUpgrade`.
+//
+// When the Pod template is changed, the partition is set to `replicas - 1` so that
+// only the instance with the highest ordinal is updated.  The partition is then
+// decremented one by one while the updated instances are ready and the cluster has
+// become healthy after the partition was set.
+// If the updated instances are unhealthy, the partition is kept as is.
+// If they do not become ready within CanaryReadyTimeout, a warning event is recorded.
+//
+// The primary is updated only after all the other instances.  If the next partition
+// would update the primary earlier, the primary is switched over to a lower ordinal first.
+func (r *MySQLClusterReconciler) canaryPartition(ctx context.Context, cluster *mocov1beta2.MySQLCluster, orig *appsv1.StatefulSet, origApplyConfig, sts *appsv1ac.StatefulSetApplyConfiguration) int32 {
+	log := crlog.FromContext(ctx)
+	key := types.NamespacedName{Namespace: cluster.Namespace, Name: cluster.Name}
+	now := time.Now()
+
+	replicas := cluster.Spec.Replicas
+	if orig.ResourceVersion == "" || replicas <= 1 {
+		r.canaryTracker.forget(key)
+		return 0
+	}
+
+	if origApplyConfig.Spec == nil || !equality.Semantic.DeepEqual(sts.Spec.Template, origApplyConfig.Spec.Template) {
+		// no instance has the new Pod template yet.
+		partition := r.nextCanaryPartition(ctx, cluster, replicas, replicas-1)
+		log.Info("start canary upgrade", "partition", partition)
+		r.canaryTracker.set(key, canaryState{partition: partition, since: now})
+		return partition
+	}
+
+	var partition int32
+	if orig.Spec.UpdateStrategy.RollingUpdate != nil && orig.Spec.UpdateStrategy.RollingUpdate.Partition != nil {
+		partition = *orig.Spec.UpdateStrategy.RollingUpdate.Partition
+	}
+	if partition > replicas {
+		partition = replicas
+	}
+	if partition == 0 {
+		r.canaryTracker.forget(key)
+		return 0
+	}
+
+	if partition == replicas {
+		// waiting for the switchover of the primary with the highest ordinal.
+		next := r.nextCanaryPartition(ctx, cluster, partition, partition-1)
+		if next != partition {
+			log.Info("start canary upgrade", "partition", next)
+			r.canaryTracker.set(key, canaryState{partition: next, since: now})
+		}
+		return next
+	}
+
+	st := r.canaryTracker.get(key, partition, now)
+	defer func() {
+		r.canaryTracker.set(key, st)
+	}()
+
+	stalled := func() {
+		if r.CanaryReadyTimeout <= 0 || st.stalled || now.Sub(st.since) < r.CanaryReadyTimeout {
+			return
+		}
+		log.Info("the updated instances do not become ready", "partition", partition, "since", st.since)
+		event.CanaryUpgradeStalled.Emit(cluster, r.Recorder, partition, r.CanaryReadyTimeout)
+		st.stalled = true
+	}
+
+	if orig.Status.ObservedGeneration != orig.Generation || orig.Status.UpdatedReplicas < replicas-partition {
+		// the instances above the partition are still being updated.
+		stalled()
+		return partition
+	}
+
+	errant := false
+	for _, idx := range cluster.Status.ErrantReplicaList {
+		if int32(idx) >= partition {
+			errant = true
+		}
+	}
+	ready := orig.Status.ReadyReplicas == replicas
+	healthy := meta.IsStatusConditionTrue(cluster.Status.Conditions, mocov1beta2.ConditionHealthy)
+
+	switch {
+	case errant || (ready && !healthy):
+		if !st.held {
+			log.Info("hold canary upgrade because the updated instances are not healthy", "partition", partition)
+			event.CanaryUpgradeHeld.Emit(cluster, r.Recorder, partition)
+			st.held = true
+		}
+		return partition
+	case ready && healthy && healthySince(cluster, st.since):
+		next := r.nextCanaryPartition(ctx, cluster, partition, partition-1)
+		if next != partition {
+			log.Info("advance canary upgrade", "partition", next)
+			st = canaryState{partition: next, since: now}
+		}
+		return next
+	}
+
+	// the Healthy condition may have been evaluated before the updated instances restarted.
+	st.held = false
+	stalled()
+	return partition
+}
+
+// nextCanaryPartition returns next unless it would update the primary while the other
+// instances below the current partition still have the old Pod template.  In that case,
+// it requests a switchover of the primary and returns current to hold the rollout until
+// the primary moves to a lower ordinal.
+func (r *MySQLClusterReconciler) nextCanaryPartition(ctx context.Context, cluster *mocov1beta2.MySQLCluster, current, next int32) int32 {
+	primary := int32(cluster.Status.CurrentPrimaryIndex)
+	if next == 0 || primary < next || primary >= current {
+		return next
+	}
+
+	log := crlog.FromContext(ctx)
+	log.Info("switch over the primary before updating it", "primary", primary, "partition", current)
+	if err := r.demotePrimary(ctx, cluster); err != nil {
+		log.Error(err, "failed to request a switchover of the primary", "primary", primary)
+	}
+	return current
+}
+
+// demotePrimary annotates the primary Pod to request a switchover like `kubectl moco switchover`.
+func (r *MySQLClusterReconciler) demotePrimary(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	pod := &corev1.Pod{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.PodName(cluster.Status.CurrentPrimaryIndex)}, pod); err != nil {
+		return err
+	}
+	if pod.Annotations[constants.AnnDemote] == "true" {
+		return nil
+	}
+
+	newPod := pod.DeepCopy()
+	if newPod.Annotations == nil {
+		newPod.Annotations = make(map[string]string)
+	}
+	newPod.Annotations[constants.AnnDemote] = "true"
+	return r.Patch(ctx, newPod, client.MergeFrom(pod))
+}
+
+// healthySince returns true if the Healthy condition of the cluster has become true at or after t.
+// The time of a condition has a resolution of seconds.
+func healthySince(cluster *mocov1beta2.MySQLCluster, t time.Time) bool {
+	cond := meta.FindStatusCondition(cluster.Status.Conditions, mocov1beta2.ConditionHealthy)
+	if cond == nil || cond.Status != metav1.ConditionTrue {
+		return false
+	}
+	return !cond.LastTransitionTime.Time.Before(t.Truncate(time.Second))
+}
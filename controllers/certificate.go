@@ -6,10 +6,13 @@ import (
 	_ "embed"
 	"fmt"
 	"text/template"
+	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer/yaml"
@@ -41,6 +44,8 @@ type certTmplVal struct {
 	Namespace       string
 	ServiceName     string
 	TargetNamespace string
+	Duration        string
+	RenewBefore     string
 }
 
 func (r *MySQLClusterReconciler) reconcileV1Certificate(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
@@ -53,13 +58,21 @@ func (r *MySQLClusterReconciler) reconcileV1Certificate(ctx context.Context, req
 		return fmt.Errorf("failed to get certificate %s: %w", cluster.CertificateName(), err)
 	}
 
-	buf := new(bytes.Buffer)
-	err = certTmpl.Execute(buf, certTmplVal{
+	val := certTmplVal{
 		Name:            cluster.CertificateName(),
 		Namespace:       r.SystemNamespace,
 		ServiceName:     cluster.HeadlessServiceName(),
 		TargetNamespace: cluster.Namespace,
-	})
+	}
+	if r.AgentCertDuration > 0 {
+		val.Duration = r.AgentCertDuration.String()
+	}
+	if r.AgentCertRenewBefore > 0 {
+		val.RenewBefore = r.AgentCertRenewBefore.String()
+	}
+
+	buf := new(bytes.Buffer)
+	err = certTmpl.Execute(buf, val)
 	if err != nil {
 		return err
 	}
@@ -76,6 +89,81 @@ func (r *MySQLClusterReconciler) reconcileV1Certificate(ctx context.Context, req
 	return nil
 }
 
+// updateCertificateStatus records the expiry of the certificate for moco-agent
+// in `status.certificateExpiry` and sets CertificateExpiringSoon condition.
+func (r *MySQLClusterReconciler) updateCertificateStatus(ctx context.Context, cluster *mocov1beta2.MySQLCluster, now time.Time) {
+	expiry, err := r.certificateExpiry(ctx, cluster)
+	if err != nil {
+		crlog.FromContext(ctx).Error(err, "failed to get the expiry of the certificate", "name", cluster.CertificateName())
+		return
+	}
+	cluster.Status.CertificateExpiry = expiry
+
+	if r.AgentCertExpiryThreshold <= 0 {
+		meta.RemoveStatusCondition(&cluster.Status.Conditions, mocov1beta2.ConditionCertificateExpiringSoon)
+		return
+	}
+
+	cond := metav1.Condition{
+		Type:    mocov1beta2.ConditionCertificateExpiringSoon,
+		Status:  metav1.ConditionUnknown,
+		Reason:  "CertificateNotIssued",
+		Message: "the certificate is not issued yet",
+	}
+	if expiry != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "CertificateValid"
+		cond.Message = "the certificate expires at " + expiry.UTC().Format(time.RFC3339)
+		if expiry.Sub(now) < r.AgentCertExpiryThreshold {
+			cond.Status = metav1.ConditionTrue
+			cond.Reason = "CertificateExpiringSoon"
+		}
+	}
+	meta.SetStatusCondition(&cluster.Status.Conditions, cond)
+}
+
+// certificateExpiry returns `status.notAfter` of the certificate for moco-agent.
+// This returns nil if the certificate is not found or not issued yet.
+func (r *MySQLClusterReconciler) certificateExpiry(ctx context.Context, cluster *mocov1beta2.MySQLCluster) (*metav1.Time, error) {
+	obj := certificateObj.DeepCopy()
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: r.SystemNamespace, Name: cluster.CertificateName()}, obj)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	notAfter, found, err := unstructured.NestedString(obj.Object, "status", "notAfter")
+	if err != nil {
+		return nil, err
+	}
+	if !found || notAfter == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, notAfter)
+	if err != nil {
+		return nil, fmt.Errorf("invalid status.notAfter of certificate %s: %w", cluster.CertificateName(), err)
+	}
+	expiry := metav1.NewTime(t)
+	return &expiry, nil
+}
+
+// untilCertificateExpiringSoon returns the duration until CertificateExpiringSoon
+// condition of the cluster becomes true.  This returns zero if it is already true
+// or the expiry is unknown.
+func (r *MySQLClusterReconciler) untilCertificateExpiringSoon(cluster *mocov1beta2.MySQLCluster, now time.Time) time.Duration {
+	if r.AgentCertExpiryThreshold <= 0 || cluster.Status.CertificateExpiry == nil {
+		return 0
+	}
+	d := cluster.Status.CertificateExpiry.Sub(now) - r.AgentCertExpiryThreshold
+	if d < 0 {
+		return 0
+	}
+	// requeue a little later so that the condition surely becomes true.
+	return d + time.Second
+}
+
 func (r *MySQLClusterReconciler) reconcileV1GRPCSecret(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
 	log := crlog.FromContext(ctx)
 
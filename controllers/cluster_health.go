@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ClusterHealthPath is the path to serve ClusterHealthHandler.
+const ClusterHealthPath = "/clusters/health"
+
+// ClusterHealth is a summary of the health of a MySQLCluster.
+type ClusterHealth struct {
+	Namespace           string `json:"namespace"`
+	Name                string `json:"name"`
+	Available           bool   `json:"available"`
+	Healthy             bool   `json:"healthy"`
+	Replicas            int32  `json:"replicas"`
+	CurrentPrimaryIndex int    `json:"currentPrimaryIndex"`
+	SyncedReplicas      int    `json:"syncedReplicas"`
+	ErrantReplicas      int    `json:"errantReplicas"`
+	ClusteringActive    bool   `json:"clusteringActive"`
+}
+
+// ClusterHealthHandler is an http.Handler that reports the health of all MySQLClusters in JSON.
+// The status is read through Reader, which is expected to be the cache of the manager.
+type ClusterHealthHandler struct {
+	Reader client.Reader
+}
+
+var _ http.Handler = ClusterHealthHandler{}
+
+// ServeHTTP implements http.Handler.
+func (h ClusterHealthHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var opts []client.ListOption
+	if ns := req.URL.Query().Get("namespace"); ns != "" {
+		opts = append(opts, client.InNamespace(ns))
+	}
+
+	clusters := &mocov1beta2.MySQLClusterList{}
+	if err := h.Reader.List(req.Context(), clusters, opts...); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	summaries := make([]ClusterHealth, 0, len(clusters.Items))
+	for i := range clusters.Items {
+		summaries = append(summaries, summarizeClusterHealth(&clusters.Items[i]))
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		if summaries[i].Namespace != summaries[j].Namespace {
+			return summaries[i].Namespace < summaries[j].Namespace
+		}
+		return summaries[i].Name < summaries[j].Name
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(summaries); err != nil {
+		crlog.Log.WithName("cluster-health").Error(err, "failed to write the health of clusters")
+	}
+}
+
+func summarizeClusterHealth(cluster *mocov1beta2.MySQLCluster) ClusterHealth {
+	conds := cluster.Status.Conditions
+	return ClusterHealth{
+		Namespace:           cluster.Namespace,
+		Name:                cluster.Name,
+		Available:           meta.IsStatusConditionTrue(conds, mocov1beta2.ConditionAvailable),
+		Healthy:             meta.IsStatusConditionTrue(conds, mocov1beta2.ConditionHealthy),
+		Replicas:            cluster.Spec.Replicas,
+		CurrentPrimaryIndex: cluster.Status.CurrentPrimaryIndex,
+		SyncedReplicas:      cluster.Status.SyncedReplicas,
+		ErrantReplicas:      cluster.Status.ErrantReplicas,
+		ClusteringActive:    !meta.IsStatusConditionFalse(conds, mocov1beta2.ConditionClusteringActive),
+	}
+}
@@ -0,0 +1,95 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+var _ = Describe("ClusterHealthHandler", func() {
+	ctx := context.Background()
+
+	BeforeEach(func() {
+		ns := &corev1.Namespace{}
+		ns.Name = "health"
+		err := k8sClient.Create(ctx, ns)
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+		err = k8sClient.DeleteAllOf(ctx, &mocov1beta2.MySQLCluster{}, client.InNamespace("health"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should report health of clusters", func() {
+		for _, name := range []string{"b", "a"} {
+			cluster := testNewMySQLCluster("health")
+			cluster.Name = name
+			cluster.Finalizers = nil
+			err := k8sClient.Create(ctx, cluster)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		cluster := &mocov1beta2.MySQLCluster{}
+		err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "health", Name: "a"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Status.CurrentPrimaryIndex = 1
+		cluster.Status.SyncedReplicas = 2
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:   mocov1beta2.ConditionAvailable,
+			Status: metav1.ConditionTrue,
+			Reason: "test",
+		})
+		meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+			Type:   mocov1beta2.ConditionHealthy,
+			Status: metav1.ConditionFalse,
+			Reason: "test",
+		})
+		err = k8sClient.Status().Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		h := ClusterHealthHandler{Reader: k8sClient}
+		req := httptest.NewRequest(http.MethodGet, ClusterHealthPath+"?namespace=health", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var summaries []ClusterHealth
+		err = json.Unmarshal(w.Body.Bytes(), &summaries)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(summaries).To(Equal([]ClusterHealth{
+			{
+				Namespace:           "health",
+				Name:                "a",
+				Available:           true,
+				Healthy:             false,
+				Replicas:            3,
+				CurrentPrimaryIndex: 1,
+				SyncedReplicas:      2,
+				ClusteringActive:    true,
+			},
+			{
+				Namespace:        "health",
+				Name:             "b",
+				Replicas:         3,
+				ClusteringActive: true,
+			},
+		}))
+
+		req = httptest.NewRequest(http.MethodPost, ClusterHealthPath, nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
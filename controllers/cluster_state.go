@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/cybozu-go/moco/clustering"
+	"k8s.io/apimachinery/pkg/types"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// ClusterStatePath is the path to serve ClusterStateHandler.
+const ClusterStatePath = "/clusters/state"
+
+// ClusterStateHandler is an http.Handler that dumps the in-memory state of
+// the clustering manager for a MySQLCluster in JSON.
+// The cluster is specified by `namespace` and `name` query parameters.
+type ClusterStateHandler struct {
+	ClusterManager clustering.ClusterManager
+}
+
+var _ http.Handler = ClusterStateHandler{}
+
+// ServeHTTP implements http.Handler.
+func (h ClusterStateHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := req.URL.Query()
+	name := types.NamespacedName{Namespace: q.Get("namespace"), Name: q.Get("name")}
+	if name.Namespace == "" || name.Name == "" {
+		http.Error(w, "namespace and name must be specified", http.StatusBadRequest)
+		return
+	}
+
+	state := h.ClusterManager.DumpState(name)
+	if state == nil {
+		http.Error(w, "no clustering state for "+name.String(), http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(state); err != nil {
+		crlog.Log.WithName("cluster-state").Error(err, "failed to write the clustering state", "name", name.String())
+	}
+}
@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/cybozu-go/moco/clustering"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ClusterStateHandler", func() {
+	It("should dump the clustering state of a cluster", func() {
+		state := &clustering.ClusterStateDump{
+			Namespace: "foo",
+			Name:      "bar",
+			State:     "Healthy",
+			Primary:   1,
+			Errants:   []int{},
+			Instances: []clustering.InstanceStateDump{
+				{Index: 0, Pod: "moco-bar-0", Ready: true, Reachable: true, SuperReadOnly: true, SourceHost: "moco-bar-1.moco-bar.foo.svc"},
+				{Index: 1, Pod: "moco-bar-1", Ready: true, Reachable: true},
+			},
+			LastOperation: &clustering.OperationDump{ID: "op-abcde", Origin: "interval"},
+		}
+		mgr := &mockManager{
+			states: map[string]*clustering.ClusterStateDump{"foo/bar": state},
+		}
+		h := ClusterStateHandler{ClusterManager: mgr}
+
+		req := httptest.NewRequest(http.MethodGet, ClusterStatePath+"?namespace=foo&name=bar", nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		Expect(w.Code).To(Equal(http.StatusOK))
+		Expect(w.Header().Get("Content-Type")).To(Equal("application/json"))
+
+		var dumped clustering.ClusterStateDump
+		err := json.Unmarshal(w.Body.Bytes(), &dumped)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(&dumped).To(Equal(state))
+
+		By("requesting an unknown cluster")
+		req = httptest.NewRequest(http.MethodGet, ClusterStatePath+"?namespace=foo&name=baz", nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusNotFound))
+
+		By("requesting without the name")
+		req = httptest.NewRequest(http.MethodGet, ClusterStatePath+"?namespace=foo", nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusBadRequest))
+
+		By("requesting with POST")
+		req = httptest.NewRequest(http.MethodPost, ClusterStatePath+"?namespace=foo&name=bar", nil)
+		w = httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		Expect(w.Code).To(Equal(http.StatusMethodNotAllowed))
+	})
+})
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"text/template"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+//go:embed dashboard_tmpl.json
+var dashboardTmplData string
+
+var dashboardTmpl = template.Must(template.New("").Parse(dashboardTmplData))
+
+type dashboardTmplVal struct {
+	UID        string
+	Name       string
+	Namespace  string
+	Exporter   bool
+	collectors map[string]bool
+}
+
+// Has returns true if the mysqld_exporter collector is enabled for the cluster.
+func (v dashboardTmplVal) Has(collector string) bool {
+	return v.collectors[collector]
+}
+
+// dashboardKey returns the key of the dashboard JSON in the ConfigMap.
+// The Grafana sidecar writes each key as a file, so the key has to be unique across namespaces.
+func dashboardKey(cluster *mocov1beta2.MySQLCluster) string {
+	return fmt.Sprintf("moco-%s-%s.json", cluster.Namespace, cluster.Name)
+}
+
+func renderDashboard(cluster *mocov1beta2.MySQLCluster) (string, error) {
+	// Grafana limits the length of dashboard UIDs to 40 characters.
+	sum := sha256.Sum256([]byte(cluster.Namespace + "/" + cluster.Name))
+
+	val := dashboardTmplVal{
+		UID:        "moco-" + hex.EncodeToString(sum[:])[:32],
+		Name:       cluster.Name,
+		Namespace:  cluster.Namespace,
+		Exporter:   len(cluster.Spec.Collectors) > 0,
+		collectors: make(map[string]bool),
+	}
+	for _, c := range cluster.Spec.Collectors {
+		val.collectors[c] = true
+	}
+
+	buf := new(bytes.Buffer)
+	if err := dashboardTmpl.Execute(buf, val); err != nil {
+		return "", err
+	}
+	if !json.Valid(buf.Bytes()) {
+		return "", errors.New("rendered dashboard is not a valid JSON")
+	}
+	return buf.String(), nil
+}
+
+func (r *MySQLClusterReconciler) reconcileV1Dashboard(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.DashboardConfigMapName()
+	if !cluster.Spec.CreateDashboard {
+		cm := &corev1.ConfigMap{}
+		cm.Namespace = cluster.Namespace
+		cm.Name = name
+		err := r.Client.Delete(ctx, cm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to delete configmap for dashboard: %w", err)
+		}
+		return nil
+	}
+
+	dashboard, err := renderDashboard(cluster)
+	if err != nil {
+		return fmt.Errorf("failed to render dashboard: %w", err)
+	}
+
+	cm := corev1ac.ConfigMap(name, cluster.Namespace).
+		WithLabels(labelSet(cluster, false)).
+		WithLabels(map[string]string{constants.LabelGrafanaDashboard: "1"}).
+		WithData(map[string]string{
+			dashboardKey(cluster): dashboard,
+		})
+
+	if err := setControllerReferenceWithConfigMap(cluster, cm, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to ConfigMap %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, cm, corev1ac.ExtractConfigMap); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile configmap %s/%s for dashboard: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("reconciled ConfigMap for dashboard", "configMapName", name)
+
+	return nil
+}
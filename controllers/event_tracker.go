@@ -0,0 +1,75 @@
+package controllers
+
+import (
+	"fmt"
+	"sync"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/event"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// eventTracker remembers the events recorded for lasting problems of clusters so that
+// they are recorded once instead of on every reconciliation.  The zero value is ready to use.
+//
+// Each problem is identified by a slot chosen by the caller.  An event is recorded again
+// if the event of the slot changes or the slot has been cleared after the problem is resolved.
+type eventTracker struct {
+	mu     sync.Mutex
+	events map[types.NamespacedName]map[string]string
+}
+
+// emit records ev for cluster unless the same event has already been recorded in slot.
+func (t *eventTracker) emit(cluster *mocov1beta2.MySQLCluster, recorder record.EventRecorder, slot string, ev event.MOCOEvent, args ...interface{}) {
+	t.record(cluster, recorder, slot, "", ev, args...)
+}
+
+// emitForGeneration is the same as emit except that the event is recorded again
+// when the generation of cluster changes.
+func (t *eventTracker) emitForGeneration(cluster *mocov1beta2.MySQLCluster, recorder record.EventRecorder, slot string, ev event.MOCOEvent, args ...interface{}) {
+	t.record(cluster, recorder, slot, fmt.Sprintf("generation %d: ", cluster.Generation), ev, args...)
+}
+
+func (t *eventTracker) record(cluster *mocov1beta2.MySQLCluster, recorder record.EventRecorder, slot, prefix string, ev event.MOCOEvent, args ...interface{}) {
+	key := client.ObjectKeyFromObject(cluster)
+	msg := prefix + ev.Reason + ": " + fmt.Sprintf(ev.Message, args...)
+
+	t.mu.Lock()
+	if t.events == nil {
+		t.events = make(map[types.NamespacedName]map[string]string)
+	}
+	slots := t.events[key]
+	if slots == nil {
+		slots = make(map[string]string)
+		t.events[key] = slots
+	}
+	recorded := slots[slot] == msg
+	slots[slot] = msg
+	t.mu.Unlock()
+
+	if !recorded {
+		ev.Emit(cluster, recorder, args...)
+	}
+}
+
+// clear forgets the event recorded in slot so that it is recorded again if the problem recurs.
+func (t *eventTracker) clear(cluster *mocov1beta2.MySQLCluster, slot string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := client.ObjectKeyFromObject(cluster)
+	delete(t.events[key], slot)
+	if len(t.events[key]) == 0 {
+		delete(t.events, key)
+	}
+}
+
+// forget removes all the events recorded for key.
+func (t *eventTracker) forget(key types.NamespacedName) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.events, key)
+}
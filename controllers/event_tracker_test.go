@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/event"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestEventTracker(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Namespace = "test"
+	cluster.Name = "test"
+	recorder := record.NewFakeRecorder(10)
+
+	var tracker eventTracker
+	expect := func(n int) {
+		t.Helper()
+		if len(recorder.Events) != n {
+			t.Fatalf("expected %d events, but got %d", n, len(recorder.Events))
+		}
+		for i := 0; i < n; i++ {
+			<-recorder.Events
+		}
+	}
+
+	tracker.emit(cluster, recorder, "backup", event.JobRoleNotFound, "Role", "foo", "backup")
+	expect(1)
+	tracker.emit(cluster, recorder, "backup", event.JobRoleNotFound, "Role", "foo", "backup")
+	expect(0)
+
+	// another slot
+	tracker.emit(cluster, recorder, "restore", event.JobRoleNotFound, "Role", "foo", "restore")
+	expect(1)
+
+	// the event of the slot has changed
+	tracker.emit(cluster, recorder, "backup", event.JobRoleNotAllowed, "Role", "foo", "moco.cybozu.com/job-role")
+	expect(1)
+	tracker.emit(cluster, recorder, "backup", event.JobRoleNotAllowed, "Role", "foo", "moco.cybozu.com/job-role")
+	expect(0)
+
+	// the problem is resolved and recurs
+	tracker.clear(cluster, "backup")
+	tracker.emit(cluster, recorder, "backup", event.JobRoleNotAllowed, "Role", "foo", "moco.cybozu.com/job-role")
+	expect(1)
+	tracker.emit(cluster, recorder, "restore", event.JobRoleNotFound, "Role", "foo", "restore")
+	expect(0)
+
+	tracker.forget(client.ObjectKeyFromObject(cluster))
+	tracker.emit(cluster, recorder, "restore", event.JobRoleNotFound, "Role", "foo", "restore")
+	expect(1)
+
+	// the event is recorded for each generation
+	cluster.Generation = 1
+	tracker.emitForGeneration(cluster, recorder, "spec", event.HostNamespacesRejected, "hostNetwork", "foo")
+	expect(1)
+	tracker.emitForGeneration(cluster, recorder, "spec", event.HostNamespacesRejected, "hostNetwork", "foo")
+	expect(0)
+	cluster.Generation = 2
+	tracker.emitForGeneration(cluster, recorder, "spec", event.HostNamespacesRejected, "hostNetwork", "foo")
+	expect(1)
+}
@@ -0,0 +1,233 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/password"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	metav1ac "k8s.io/client-go/applyconfigurations/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func labelSetForExporter(cluster *mocov1beta2.MySQLCluster) map[string]string {
+	return map[string]string{
+		constants.LabelAppName:      constants.AppNameExporter,
+		constants.LabelAppInstance:  cluster.Name,
+		constants.LabelAppCreatedBy: constants.AppCreator,
+	}
+}
+
+// reconcileV1Exporter reconciles the Deployment and Service of mysqld_exporter
+// if it runs separately from the MySQL Pods, or deletes them otherwise.
+func (r *MySQLClusterReconciler) reconcileV1Exporter(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	if !cluster.Spec.ExporterDeploymentEnabled() {
+		deploy := &appsv1.Deployment{}
+		deploy.Namespace = cluster.Namespace
+		deploy.Name = cluster.ExporterName()
+		if err := r.Delete(ctx, deploy); err == nil {
+			log.Info("removed mysqld_exporter Deployment")
+		} else if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete Deployment %s/%s: %w", deploy.Namespace, deploy.Name, err)
+		}
+
+		svc := &corev1.Service{}
+		svc.Namespace = cluster.Namespace
+		svc.Name = cluster.ExporterName()
+		if err := r.Delete(ctx, svc); err == nil {
+			log.Info("removed mysqld_exporter Service")
+		} else if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete Service %s/%s: %w", svc.Namespace, svc.Name, err)
+		}
+		return nil
+	}
+
+	if err := r.reconcileV1ExporterDeployment(ctx, cluster); err != nil {
+		return err
+	}
+	return r.reconcileV1ExporterService(ctx, cluster)
+}
+
+func (r *MySQLClusterReconciler) reconcileV1ExporterDeployment(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.ExporterName()
+	labels := labelSetForExporter(cluster)
+	mycnf := exporterMyCnf(cluster, constants.ExporterRemoteMyCnf, constants.ExporterUserRemoteMyCnf)
+
+	podSpec := corev1ac.PodSpec().
+		WithContainers(r.makeV1ExporterDeploymentContainer(cluster)).
+		WithVolumes(
+			corev1ac.Volume().
+				WithName(constants.MySQLConfSecretVolumeName).
+				WithSecret(corev1ac.SecretVolumeSource().
+					WithSecretName(cluster.MyCnfSecretName()).
+					WithItems(corev1ac.KeyToPath().
+						WithKey(mycnf).
+						WithPath(mycnf)).
+					WithDefaultMode(0644)),
+		)
+	for _, s := range cluster.Spec.PodTemplate.Spec.ImagePullSecrets {
+		s := s
+		podSpec.WithImagePullSecrets(&s)
+	}
+
+	// The exporter is scheduled like the MySQL Pods so that it runs on the nodes dedicated to the cluster.
+	templateSpec := (*corev1ac.PodSpecApplyConfiguration)(cluster.Spec.PodTemplate.Spec.DeepCopy())
+	podSpec.NodeSelector = templateSpec.NodeSelector
+	podSpec.Tolerations = templateSpec.Tolerations
+	podSpec.Affinity = templateSpec.Affinity
+	podSpec.PriorityClassName = templateSpec.PriorityClassName
+
+	deploy := appsv1ac.Deployment(name, cluster.Namespace).
+		WithLabels(labels).
+		WithSpec(appsv1ac.DeploymentSpec().
+			WithReplicas(1).
+			WithSelector(metav1ac.LabelSelector().
+				WithMatchLabels(labels)).
+			WithTemplate(corev1ac.PodTemplateSpec().
+				WithLabels(labels).
+				WithSpec(podSpec)))
+
+	// mysqld_exporter does not reload my.cnf, so it is restarted when the passwords are rotated.
+	passwordRev, err := r.passwordRevision(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if passwordRev != "" {
+		deploy.Spec.Template.WithAnnotations(map[string]string{
+			constants.AnnPasswordRevision: passwordRev,
+		})
+	}
+
+	if err := setControllerReferenceWithDeployment(cluster, deploy, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to Deployment %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, deploy, appsv1ac.ExtractDeployment); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile Deployment %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("reconciled mysqld_exporter Deployment", "deploymentName", name)
+
+	return nil
+}
+
+func (r *MySQLClusterReconciler) makeV1ExporterDeploymentContainer(cluster *mocov1beta2.MySQLCluster) *corev1ac.ContainerApplyConfiguration {
+	c := corev1ac.Container().
+		WithName(constants.ExporterContainerName).
+		WithImage(r.ExporterImage).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
+		WithArgs("--config.my-cnf=" + filepath.Join(constants.MyCnfSecretPath, exporterMyCnf(cluster, constants.ExporterRemoteMyCnf, constants.ExporterUserRemoteMyCnf))).
+		WithPorts(
+			corev1ac.ContainerPort().
+				WithName(constants.ExporterPortName).
+				WithContainerPort(constants.ExporterPort).
+				WithProtocol(corev1.ProtocolTCP)).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().
+				WithName(constants.MySQLConfSecretVolumeName).
+				WithMountPath(constants.MyCnfSecretPath).
+				WithReadOnly(true),
+		).
+		WithResources(
+			corev1ac.ResourceRequirements().
+				WithRequests(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(constants.ExporterContainerCPURequest),
+					corev1.ResourceMemory: resource.MustParse(constants.ExporterContainerMemRequest),
+				}).
+				WithLimits(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(constants.ExporterContainerCPULimit),
+					corev1.ResourceMemory: resource.MustParse(constants.ExporterContainerMemLimit),
+				}),
+		)
+
+	for _, cl := range cluster.Spec.Collectors {
+		c.WithArgs("--collect." + cl)
+	}
+	c.WithEnv(exporterEnvs(cluster)...)
+
+	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
+	updateContainerWithOverwriteContainers(cluster, c)
+
+	return c
+}
+
+// exporterMyCnf returns the my.cnf filename for mysqld_exporter.
+// userMyCnf is used if `spec.exporterUserName` is set.
+func exporterMyCnf(cluster *mocov1beta2.MySQLCluster, mocoMyCnf, userMyCnf string) string {
+	if cluster.Spec.ExporterUser() != nil {
+		return userMyCnf
+	}
+	return mocoMyCnf
+}
+
+// exporterEnvs returns the environment variables of mysqld_exporter to pass
+// the password of the user specified by `spec.exporterUserName`.
+func exporterEnvs(cluster *mocov1beta2.MySQLCluster) []*corev1ac.EnvVarApplyConfiguration {
+	u := cluster.Spec.ExporterUser()
+	if u == nil {
+		return nil
+	}
+
+	return []*corev1ac.EnvVarApplyConfiguration{
+		corev1ac.EnvVar().
+			WithName(constants.ExporterPasswordEnvName).
+			WithValueFrom(corev1ac.EnvVarSource().
+				WithSecretKeyRef(corev1ac.SecretKeySelector().
+					WithName(u.PasswordSecretName).
+					WithKey(password.UserPasswordKey))),
+	}
+}
+
+func (r *MySQLClusterReconciler) reconcileV1ExporterService(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.ExporterName()
+	labels := labelSetForExporter(cluster)
+
+	svc := corev1ac.Service(name, cluster.Namespace).
+		WithLabels(labels).
+		WithSpec(corev1ac.ServiceSpec().
+			WithType(corev1.ServiceTypeClusterIP).
+			WithSelector(labels).
+			WithPorts(corev1ac.ServicePort().
+				WithName(constants.ExporterPortName).
+				WithProtocol(corev1.ProtocolTCP).
+				WithPort(constants.ExporterPort).
+				WithTargetPort(intstr.FromString(constants.ExporterPortName))))
+
+	if err := setControllerReferenceWithService(cluster, svc, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to Service %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, svc, corev1ac.ExtractService); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile Service %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("reconciled mysqld_exporter Service", "serviceName", name)
+
+	return nil
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var gatewayRouteGVKs = map[string]schema.GroupVersionKind{
+	mocov1beta2.GatewayRouteTCP: {
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    mocov1beta2.GatewayRouteTCP,
+	},
+	mocov1beta2.GatewayRouteTLS: {
+		Group:   "gateway.networking.k8s.io",
+		Version: "v1alpha2",
+		Kind:    mocov1beta2.GatewayRouteTLS,
+	},
+}
+
+// reconcileV1GatewayRoute reconciles the TCPRoute or TLSRoute of Gateway API for the primary Service,
+// and deletes the route of the other kind.
+// The routes are handled as unstructured objects so that MOCO works without Gateway API.
+func (r *MySQLClusterReconciler) reconcileV1GatewayRoute(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	kind := ""
+	if gr := cluster.Spec.GatewayRoute; gr != nil {
+		kind = gr.Kind
+		if kind == "" {
+			kind = mocov1beta2.GatewayRouteTCP
+		}
+	}
+
+	for _, k := range []string{mocov1beta2.GatewayRouteTCP, mocov1beta2.GatewayRouteTLS} {
+		if k == kind {
+			if err := r.applyGatewayRoute(ctx, cluster, gatewayRouteGVKs[k]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := r.deleteGatewayRoute(ctx, cluster, gatewayRouteGVKs[k]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *MySQLClusterReconciler) applyGatewayRoute(ctx context.Context, cluster *mocov1beta2.MySQLCluster, gvk schema.GroupVersionKind) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.GatewayRouteName()
+	orig := &unstructured.Unstructured{}
+	orig.SetGroupVersionKind(gvk)
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, orig)
+	switch {
+	case meta.IsNoMatchError(err):
+		log.Info("skipped creating the route because its CRD is not installed", "kind", gvk.Kind)
+		return nil
+	case apierrors.IsNotFound(err):
+		orig = nil
+	case err != nil:
+		return fmt.Errorf("failed to get %s %s/%s: %w", gvk.Kind, cluster.Namespace, name, err)
+	}
+
+	route := makeGatewayRoute(cluster, gvk)
+	if err := controllerutil.SetControllerReference(cluster, route, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to %s %s/%s: %w", gvk.Kind, cluster.Namespace, name, err)
+	}
+
+	err = r.Client.Patch(ctx, route, client.Apply, &client.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        ptr.To[bool](true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile %s %s/%s: %w", gvk.Kind, cluster.Namespace, name, err)
+	}
+
+	if orig == nil || orig.GetResourceVersion() != route.GetResourceVersion() {
+		log.Info("reconciled the route", "kind", gvk.Kind, "routeName", name)
+	}
+	return nil
+}
+
+func (r *MySQLClusterReconciler) deleteGatewayRoute(ctx context.Context, cluster *mocov1beta2.MySQLCluster, gvk schema.GroupVersionKind) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.GatewayRouteName()
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(gvk)
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, route)
+	if meta.IsNoMatchError(err) || apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s/%s: %w", gvk.Kind, cluster.Namespace, name, err)
+	}
+	if !metav1.IsControlledBy(route, cluster) {
+		return nil
+	}
+
+	if err := r.Client.Delete(ctx, route); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete %s %s/%s: %w", gvk.Kind, cluster.Namespace, name, err)
+	}
+	log.Info("removed the route", "kind", gvk.Kind, "routeName", name)
+	return nil
+}
+
+// makeGatewayRoute returns the route of `gvk` that forwards connections to the MySQL port of the primary Service.
+func makeGatewayRoute(cluster *mocov1beta2.MySQLCluster, gvk schema.GroupVersionKind) *unstructured.Unstructured {
+	gr := cluster.Spec.GatewayRoute
+
+	parentRefs := make([]interface{}, 0, len(gr.ParentRefs))
+	for _, ref := range gr.ParentRefs {
+		ns := ref.Namespace
+		if ns == "" {
+			ns = cluster.Namespace
+		}
+		parent := map[string]interface{}{
+			"name":      ref.Name,
+			"namespace": ns,
+		}
+		if ref.SectionName != "" {
+			parent["sectionName"] = ref.SectionName
+		}
+		parentRefs = append(parentRefs, parent)
+	}
+
+	spec := map[string]interface{}{
+		"parentRefs": parentRefs,
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": cluster.PrimaryServiceName(),
+						"port": int64(constants.MySQLPort),
+					},
+				},
+			},
+		},
+	}
+	if gvk.Kind == mocov1beta2.GatewayRouteTLS && len(gr.Hostnames) > 0 {
+		hostnames := make([]interface{}, 0, len(gr.Hostnames))
+		for _, h := range gr.Hostnames {
+			hostnames = append(hostnames, h)
+		}
+		spec["hostnames"] = hostnames
+	}
+
+	route := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": spec,
+		},
+	}
+	route.SetGroupVersionKind(gvk)
+	route.SetNamespace(cluster.Namespace)
+	route.SetName(cluster.GatewayRouteName())
+	route.SetLabels(labelSet(cluster, false))
+	return route
+}
@@ -0,0 +1,58 @@
+package controllers
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMakeGatewayRoute(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Namespace = "test"
+	cluster.Name = "test"
+	cluster.Spec.GatewayRoute = &mocov1beta2.GatewayRouteSpec{
+		Kind: mocov1beta2.GatewayRouteTLS,
+		ParentRefs: []mocov1beta2.GatewayParentReference{
+			{Name: "gw1"},
+			{Name: "gw2", Namespace: "gateway", SectionName: "mysql"},
+		},
+		Hostnames: []string{"mysql.example.com"},
+	}
+
+	expectedSpec := map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": "gw1", "namespace": "test"},
+			map[string]interface{}{"name": "gw2", "namespace": "gateway", "sectionName": "mysql"},
+		},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": "moco-test-primary", "port": int64(3306)},
+				},
+			},
+		},
+		"hostnames": []interface{}{"mysql.example.com"},
+	}
+
+	route := makeGatewayRoute(cluster, gatewayRouteGVKs[mocov1beta2.GatewayRouteTLS])
+	if route.GetKind() != "TLSRoute" || route.GetAPIVersion() != "gateway.networking.k8s.io/v1alpha2" {
+		t.Errorf("unexpected type: %s %s", route.GetAPIVersion(), route.GetKind())
+	}
+	if route.GetNamespace() != "test" || route.GetName() != "moco-test" {
+		t.Errorf("unexpected name: %s/%s", route.GetNamespace(), route.GetName())
+	}
+	if diff := cmp.Diff(expectedSpec, route.Object["spec"]); diff != "" {
+		t.Errorf("unexpected spec (-want +got):\n%s", diff)
+	}
+
+	// TCPRoute does not have hostnames.
+	delete(expectedSpec, "hostnames")
+	route = makeGatewayRoute(cluster, gatewayRouteGVKs[mocov1beta2.GatewayRouteTCP])
+	if route.GetKind() != "TCPRoute" {
+		t.Errorf("unexpected kind: %s", route.GetKind())
+	}
+	if diff := cmp.Diff(expectedSpec, route.Object["spec"]); diff != "" {
+		t.Errorf("unexpected spec (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,39 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cybozu-go/moco/pkg/mycnf"
+)
+
+func TestPodTerminationGracePeriodSeconds(t *testing.T) {
+	conf := func(size string) string {
+		return mycnf.Generate(map[string]string{"innodb_buffer_pool_size": size}, mycnf.Options{MemTotal: 1 << 30})
+	}
+
+	tests := []struct {
+		name     string
+		perGiB   time.Duration
+		max      time.Duration
+		conf     string
+		expected int64
+	}{
+		{"disabled", 0, time.Hour, conf("8G"), defaultTerminationGracePeriodSeconds},
+		{"proportional", time.Minute, time.Hour, conf("8G"), 480},
+		{"round up", time.Minute, time.Hour, conf("8500M"), 499},
+		{"not shorter than the default", time.Minute, time.Hour, conf("1G"), defaultTerminationGracePeriodSeconds},
+		{"capped", time.Minute, time.Hour, conf("100G"), 3600},
+		{"no limit", time.Minute, 0, conf("100G"), 6000},
+		{"unknown size", time.Minute, time.Hour, "[mysqld]\n", defaultTerminationGracePeriodSeconds},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := &MySQLClusterReconciler{GracePeriodPerGiB: tt.perGiB, MaxGracePeriod: tt.max}
+			if actual := r.podTerminationGracePeriodSeconds(tt.conf); actual != tt.expected {
+				t.Errorf("expected %d, actual %d", tt.expected, actual)
+			}
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+)
+
+// instanceResourcesHash returns the hash of `spec.instanceResources`, or an empty string if it is empty.
+// The resources are set to the Pods by the webhook only when they are created, so the hash is
+// recorded in the Pod template to restart the Pods when it changes.
+func instanceResourcesHash(cluster *mocov1beta2.MySQLCluster) (string, error) {
+	if len(cluster.Spec.InstanceResources) == 0 {
+		return "", nil
+	}
+
+	data, err := json.Marshal(cluster.Spec.InstanceResources)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal spec.instanceResources: %w", err)
+	}
+	fnv32a := fnv.New32a()
+	fnv32a.Write(data)
+	return hex.EncodeToString(fnv32a.Sum(nil)), nil
+}
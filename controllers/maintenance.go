@@ -0,0 +1,117 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/password"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	batchv1ac "k8s.io/client-go/applyconfigurations/batch/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func labelSetForMaintenanceJob(cluster *mocov1beta2.MySQLCluster) map[string]string {
+	return map[string]string{
+		constants.LabelAppName:      constants.AppNameMaintenance,
+		constants.LabelAppInstance:  cluster.Name,
+		constants.LabelAppCreatedBy: constants.AppCreator,
+	}
+}
+
+// reconcileV1MaintenanceJob reconciles the CronJob for `spec.maintenance`.
+// The Job runs the analyze subcommand of moco-backup with the hostnames of all the
+// instances, and the subcommand skips the writable primary instance at runtime
+// so that the Job does not need to access Kubernetes API.
+func (r *MySQLClusterReconciler) reconcileV1MaintenanceJob(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	cronJobName := cluster.MaintenanceCronJobName()
+	spec := cluster.Spec.Maintenance
+	if spec == nil {
+		cj := &batchv1.CronJob{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cronJobName}, cj); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(cj, cluster) {
+			return nil
+		}
+		if err := r.Delete(ctx, cj); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		log.Info("deleted CronJob for maintenance", "cronJobName", cronJobName)
+		return nil
+	}
+
+	args := []string{constants.AnalyzeSubcommand}
+	if len(spec.Databases) > 0 {
+		args = append(args, "--databases="+strings.Join(spec.Databases, ","))
+	}
+	for i := 0; i < int(cluster.Spec.Replicas); i++ {
+		args = append(args, cluster.PodHostname(i))
+	}
+
+	container := corev1ac.Container().
+		WithName("maintenance").
+		WithImage(r.BackupImage).
+		WithArgs(args...).
+		WithEnv(corev1ac.EnvVar().
+			WithName("MYSQL_PASSWORD").
+			WithValueFrom(corev1ac.EnvVarSource().
+				WithSecretKeyRef(corev1ac.SecretKeySelector().
+					WithKey(password.AdminPasswordKey).
+					WithName(cluster.UserSecretName()),
+				),
+			),
+		).
+		WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true))
+	updateContainerWithSecurityContext(container)
+
+	cronJob := batchv1ac.CronJob(cronJobName, cluster.Namespace).
+		WithLabels(labelSetForMaintenanceJob(cluster)).
+		WithSpec(batchv1ac.CronJobSpec().
+			WithSchedule(spec.Schedule).
+			WithConcurrencyPolicy(batchv1.ForbidConcurrent).
+			WithSuspend(spec.Suspend).
+			WithJobTemplate(batchv1ac.JobTemplateSpec().
+				WithLabels(labelSetForMaintenanceJob(cluster)).
+				WithSpec(batchv1ac.JobSpec().
+					WithTemplate(corev1ac.PodTemplateSpec().
+						WithLabels(labelSetForMaintenanceJob(cluster)).
+						WithSpec(corev1ac.PodSpec().
+							WithRestartPolicy(corev1.RestartPolicyNever).
+							WithAutomountServiceAccountToken(false).
+							WithContainers(container).
+							WithSecurityContext(corev1ac.PodSecurityContext().
+								WithFSGroup(constants.ContainerGID).
+								WithFSGroupChangePolicy(corev1.FSGroupChangeOnRootMismatch),
+							),
+						),
+					),
+				),
+			),
+		)
+
+	if err := setControllerReferenceWithCronJob(cluster, cronJob, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to CronJob %s/%s: %w", cluster.Namespace, cronJobName, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: cronJobName}
+	if _, err := apply(ctx, r.Client, key, cronJob, batchv1ac.ExtractCronJob); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile %s CronJob for maintenance: %w", cronJobName, err)
+	}
+
+	log.Info("reconciled CronJob for maintenance", "cronJobName", cronJobName)
+	return nil
+}
@@ -0,0 +1,64 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileV1MetricsService reconciles the headless Service that exposes the port of
+// mysqld_exporter running as a sidecar in the MySQL Pods, or deletes it otherwise.
+func (r *MySQLClusterReconciler) reconcileV1MetricsService(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.MetricsServiceName()
+
+	if !cluster.Spec.CreateMetricsService || !cluster.Spec.ExporterSidecarEnabled() {
+		svc := &corev1.Service{}
+		svc.Namespace = cluster.Namespace
+		svc.Name = name
+		if err := r.Delete(ctx, svc); err == nil {
+			log.Info("removed metrics Service")
+		} else if client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete Service %s/%s: %w", cluster.Namespace, name, err)
+		}
+		return nil
+	}
+
+	svc := corev1ac.Service(name, cluster.Namespace).
+		WithLabels(labelSet(cluster, false)).
+		WithSpec(corev1ac.ServiceSpec().
+			WithClusterIP(corev1.ClusterIPNone).
+			WithType(corev1.ServiceTypeClusterIP).
+			WithSelector(labelSet(cluster, false)).
+			WithPorts(corev1ac.ServicePort().
+				WithName(constants.ExporterPortName).
+				WithProtocol(corev1.ProtocolTCP).
+				WithPort(constants.ExporterPort).
+				WithTargetPort(intstr.FromString(constants.ExporterPortName))))
+
+	if err := setControllerReferenceWithService(cluster, svc, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to Service %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, svc, corev1ac.ExtractService); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile Service %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("reconciled metrics Service", "serviceName", name)
+
+	return nil
+}
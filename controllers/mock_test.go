@@ -11,6 +11,7 @@ type mockManager struct {
 	mu       sync.Mutex
 	clusters map[string]struct{}
 	updated  []types.NamespacedName
+	states   map[string]*clustering.ClusterStateDump
 }
 
 var _ clustering.ClusterManager = &mockManager{}
@@ -45,6 +46,13 @@ func (m *mockManager) Pause(key types.NamespacedName) {
 	delete(m.clusters, key.String())
 }
 
+func (m *mockManager) DumpState(key types.NamespacedName) *clustering.ClusterStateDump {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.states[key.String()]
+}
+
 func (m *mockManager) getKeys() map[string]bool {
 	m.mu.Lock()
 	defer m.mu.Unlock()
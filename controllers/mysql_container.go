@@ -2,8 +2,12 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/constants"
@@ -29,14 +33,29 @@ func (r *MySQLClusterReconciler) makeV1MySQLDContainer(cluster *mocov1beta2.MySQ
 		return nil, fmt.Errorf("MySQLD container not found")
 	}
 
+	lifecycle := corev1ac.Lifecycle().
+		WithPreStop(corev1ac.LifecycleHandler().
+			WithExec(corev1ac.ExecAction().
+				WithCommand(mysqldPreStopCommand(cluster)...)),
+		)
+	if warmUp := cluster.Spec.WarmUp; warmUp != nil {
+		command := warmUp.Command
+		if len(command) == 0 {
+			command = defaultWarmUpCommand(cluster)
+		}
+		lifecycle.WithPostStart(corev1ac.LifecycleHandler().
+			WithExec(corev1ac.ExecAction().
+				WithCommand(command...)),
+		)
+	}
+
+	if len(cluster.Spec.MysqldCommand) > 0 {
+		source.WithCommand(cluster.Spec.MysqldCommand...)
+	}
+
 	source.
-		WithArgs("--defaults-file="+filepath.Join(constants.MySQLConfPath, constants.MySQLConfName)).
-		WithLifecycle(corev1ac.Lifecycle().
-			WithPreStop(corev1ac.LifecycleHandler().
-				WithExec(corev1ac.ExecAction().
-					WithCommand("sleep", constants.PreStopSeconds)),
-			),
-		).WithPorts(
+		WithArgs("--defaults-file="+cluster.Spec.MySQLConfFile()).
+		WithLifecycle(lifecycle).WithPorts(
 		corev1ac.ContainerPort().
 			WithName(constants.MySQLPortName).
 			WithContainerPort(constants.MySQLPort).
@@ -92,6 +111,15 @@ func (r *MySQLClusterReconciler) makeV1MySQLDContainer(cluster *mocov1beta2.MySQ
 		WithPort(intstr.FromString(constants.MySQLHealthPortName)).
 		WithScheme(corev1.URISchemeHTTP))
 
+	if p := cluster.Spec.MysqldReadinessProbe; p != nil {
+		if source.ReadinessProbe.SuccessThreshold == nil && p.SuccessThreshold != nil {
+			source.ReadinessProbe.WithSuccessThreshold(*p.SuccessThreshold)
+		}
+		if source.ReadinessProbe.FailureThreshold == nil && p.FailureThreshold != nil {
+			source.ReadinessProbe.WithFailureThreshold(*p.FailureThreshold)
+		}
+	}
+
 	source.WithVolumeMounts(
 		corev1ac.VolumeMount().
 			WithName(constants.TmpVolumeName).
@@ -102,30 +130,114 @@ func (r *MySQLClusterReconciler) makeV1MySQLDContainer(cluster *mocov1beta2.MySQ
 		corev1ac.VolumeMount().
 			WithName(constants.VarLogVolumeName).
 			WithMountPath(constants.LogDirPath),
-		corev1ac.VolumeMount().
-			WithName(constants.MySQLConfVolumeName).
-			WithMountPath(constants.MySQLConfPath),
+		mysqlConfMount(cluster),
 		corev1ac.VolumeMount().
 			WithName(constants.MySQLInitConfVolumeName).
 			WithMountPath(constants.MySQLInitConfPath),
-		corev1ac.VolumeMount().
-			WithName(constants.MySQLConfSecretVolumeName).
-			WithMountPath(constants.MyCnfSecretPath).
-			WithReadOnly(true),
+	)
+	// Keep the order of the mounts so that existing StatefulSets are not updated.
+	if !cluster.Spec.DisableMyCnfSecretVolume {
+		source.WithVolumeMounts(
+			corev1ac.VolumeMount().
+				WithName(constants.MySQLConfSecretVolumeName).
+				WithMountPath(constants.MyCnfSecretPath).
+				WithReadOnly(true),
+		)
+	}
+	source.WithVolumeMounts(
 		corev1ac.VolumeMount().
 			WithName(constants.MySQLDataVolumeName).
-			WithMountPath(constants.MySQLDataPath),
+			WithMountPath(cluster.Spec.MySQLDataDir()),
 	)
 
 	updateContainerWithSecurityContext(source)
+	updateContainerWithRestrictedSecurityContext(source)
+	if cluster.Spec.MysqldReadOnlyRootFilesystem {
+		if source.SecurityContext.ReadOnlyRootFilesystem == nil {
+			source.SecurityContext.WithReadOnlyRootFilesystem(true)
+		}
+		if err := verifyWritableMounts(cluster, source); err != nil {
+			return nil, err
+		}
+	}
 
 	return source, nil
 }
 
+// mysqlConfMount returns the volume mount of the ConfigMap having my.cnf.
+// If `spec.mysqlConfPath` is set, only my.cnf is mounted at the path.
+func mysqlConfMount(cluster *mocov1beta2.MySQLCluster) *corev1ac.VolumeMountApplyConfiguration {
+	mount := corev1ac.VolumeMount().WithName(constants.MySQLConfVolumeName)
+	if cluster.Spec.MySQLConfPath == "" {
+		return mount.WithMountPath(constants.MySQLConfPath)
+	}
+	return mount.WithMountPath(cluster.Spec.MySQLConfPath).WithSubPath(constants.MySQLConfName)
+}
+
+// verifyWritableMounts checks that all the paths mysqld writes to are backed by
+// writable volume mounts so that mysqld can run with a read-only root filesystem.
+func verifyWritableMounts(cluster *mocov1beta2.MySQLCluster, container *corev1ac.ContainerApplyConfiguration) error {
+	paths := []string{cluster.Spec.MySQLDataDir(), constants.TmpPath, constants.RunPath, constants.LogDirPath}
+	if cluster.Spec.TmpDir != "" {
+		paths = append(paths, cluster.Spec.TmpDir)
+	}
+
+	for _, p := range paths {
+		// the innermost mount decides whether the path is writable.
+		var mount *corev1ac.VolumeMountApplyConfiguration
+		for i, vm := range container.VolumeMounts {
+			if vm.MountPath == nil {
+				continue
+			}
+			mp := filepath.Clean(*vm.MountPath)
+			if p != mp && !strings.HasPrefix(p, strings.TrimSuffix(mp, "/")+"/") {
+				continue
+			}
+			if mount == nil || len(mp) > len(filepath.Clean(*mount.MountPath)) {
+				mount = &container.VolumeMounts[i]
+			}
+		}
+
+		if mount == nil {
+			return fmt.Errorf("%s is not backed by a volume mount of the mysqld container", p)
+		}
+		if mount.ReadOnly != nil && *mount.ReadOnly {
+			return fmt.Errorf("%s is backed by a read-only volume mount %s of the mysqld container", p, *mount.Name)
+		}
+	}
+	return nil
+}
+
+// defaultWarmUpCommand returns the command that waits for mysqld to accept
+// connections and loads the InnoDB buffer pool dumped at the last shutdown.
+// The command never fails so that the container is not killed by the hook.
+func defaultWarmUpCommand(cluster *mocov1beta2.MySQLCluster) []string {
+	socket := filepath.Join(constants.RunPath, "mysqld.sock")
+	cnf := filepath.Join(constants.MyCnfSecretPath, constants.AdminMyCnf)
+	dump := filepath.Join(cluster.Spec.MySQLDataDir(), "data", "ib_buffer_pool")
+	opts := fmt.Sprintf("--defaults-extra-file=%s --socket=%s", cnf, socket)
+
+	script := fmt.Sprintf(`i=0
+while [ $i -lt %d ]; do
+  if mysqladmin %s ping >/dev/null 2>&1; then
+    if [ -f %s ]; then
+      mysql %s -e 'SET GLOBAL innodb_buffer_pool_load_now = ON' || true
+    fi
+    exit 0
+  fi
+  i=$((i+1))
+  sleep 1
+done
+exit 0
+`, constants.WarmUpWaitSeconds, opts, dump, opts)
+	return []string{"sh", "-c", script}
+}
+
 func (r *MySQLClusterReconciler) makeV1AgentContainer(cluster *mocov1beta2.MySQLCluster) *corev1ac.ContainerApplyConfiguration {
 	c := corev1ac.Container().
 		WithName(constants.AgentContainerName).
-		WithImage(r.AgentImage)
+		WithImage(r.AgentImage).
+		WithImagePullPolicy(corev1.PullIfNotPresent)
 
 	if cluster.Spec.MaxDelaySeconds != nil {
 		c.WithArgs("--max-delay", fmt.Sprintf("%ds", *cluster.Spec.MaxDelaySeconds))
@@ -145,7 +257,16 @@ func (r *MySQLClusterReconciler) makeV1AgentContainer(cluster *mocov1beta2.MySQL
 			WithName(constants.GRPCSecretVolumeName).
 			WithMountPath("/grpc-cert").
 			WithReadOnly(true),
-	).WithEnv(
+	)
+	if cluster.Spec.AgentOnlyServiceAccountToken {
+		c.WithVolumeMounts(
+			corev1ac.VolumeMount().
+				WithName(constants.ServiceAccountTokenVolumeName).
+				WithMountPath(constants.ServiceAccountTokenPath).
+				WithReadOnly(true),
+		)
+	}
+	c.WithEnv(
 		corev1ac.EnvVar().
 			WithName(constants.PodNameEnvKey).
 			WithValueFrom(corev1ac.EnvVarSource().
@@ -169,24 +290,104 @@ func (r *MySQLClusterReconciler) makeV1AgentContainer(cluster *mocov1beta2.MySQL
 			WithName(constants.AgentMetricsPortName).
 			WithContainerPort(constants.AgentMetricsPort).
 			WithProtocol(corev1.ProtocolTCP),
-	).WithResources(
+	)
+
+	if p := cluster.Spec.AgentProbe; p != nil {
+		probe := corev1ac.Probe().
+			WithTCPSocket(corev1ac.TCPSocketAction().
+				WithPort(intstr.FromString(constants.AgentPortName))).
+			WithTimeoutSeconds(p.TimeoutSeconds).
+			WithPeriodSeconds(p.PeriodSeconds).
+			WithFailureThreshold(p.FailureThreshold)
+		// zero is omitted from the applied StatefulSet, so setting it would
+		// make the extracted configuration differ on every reconciliation.
+		if p.InitialDelaySeconds > 0 {
+			probe.WithInitialDelaySeconds(p.InitialDelaySeconds)
+		}
+		c.WithLivenessProbe(probe)
+
+		// The liveness probe does not start until the startup probe succeeds,
+		// so the agent is not restarted while it is slow to start.
+		c.WithStartupProbe(corev1ac.Probe().
+			WithTCPSocket(corev1ac.TCPSocketAction().
+				WithPort(intstr.FromString(constants.AgentPortName))).
+			WithTimeoutSeconds(p.TimeoutSeconds).
+			WithPeriodSeconds(p.StartupPeriodSeconds).
+			WithFailureThreshold(p.StartupFailureThreshold))
+	}
+
+	memRequest := resource.MustParse(constants.AgentContainerMemRequest)
+	memLimit := resource.MustParse(constants.AgentContainerMemLimit)
+	if mem := derivedAgentMemory(cluster); mem != nil {
+		if mem.Cmp(memRequest) > 0 {
+			memRequest = *mem
+		}
+		if mem.Cmp(memLimit) > 0 {
+			memLimit = *mem
+		}
+	}
+	c.WithResources(
 		corev1ac.ResourceRequirements().
 			WithRequests(corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse(constants.AgentContainerCPURequest),
-				corev1.ResourceMemory: resource.MustParse(constants.AgentContainerMemRequest),
+				corev1.ResourceMemory: memRequest,
 			}).
 			WithLimits(corev1.ResourceList{
 				corev1.ResourceCPU:    resource.MustParse(constants.AgentContainerCPULimit),
-				corev1.ResourceMemory: resource.MustParse(constants.AgentContainerMemLimit),
+				corev1.ResourceMemory: memLimit,
 			}),
 	)
 
 	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
 	updateContainerWithOverwriteContainers(cluster, c)
 
 	return c
 }
 
+// derivedAgentMemory returns the memory for the agent container derived from the memory
+// of mysqld container by `spec.agentMemoryPercent`.
+// It returns nil if the field is not set or the memory of mysqld is not specified.
+func derivedAgentMemory(cluster *mocov1beta2.MySQLCluster) *resource.Quantity {
+	if cluster.Spec.AgentMemoryPercent == nil {
+		return nil
+	}
+
+	for i, c := range cluster.Spec.PodTemplate.Spec.Containers {
+		if c.Name == nil || *c.Name != constants.MysqldContainerName {
+			continue
+		}
+		mem := mysqldContainerMemory(&cluster.Spec.PodTemplate.Spec.Containers[i]) * int64(*cluster.Spec.AgentMemoryPercent) / 100
+		if mem == 0 {
+			return nil
+		}
+		return resource.NewQuantity(mem, resource.BinarySI)
+	}
+	return nil
+}
+
+// mysqldContainerMemory returns the memory size of mysqld container in bytes.
+// resources.requests.memory takes precedence over resources.limits.memory.
+// It returns zero if neither is specified.
+func mysqldContainerMemory(container *corev1ac.ContainerApplyConfiguration) int64 {
+	var mem int64
+	if container.Resources == nil {
+		return mem
+	}
+
+	if container.Resources.Limits != nil {
+		if res := container.Resources.Limits.Memory(); !res.IsZero() {
+			mem = res.Value()
+		}
+	}
+	if container.Resources.Requests != nil {
+		if res := container.Resources.Requests.Memory(); !res.IsZero() {
+			mem = res.Value()
+		}
+	}
+	return mem
+}
+
 func (r *MySQLClusterReconciler) makeV1SlowQueryLogContainer(cluster *mocov1beta2.MySQLCluster, sts *appsv1ac.StatefulSetApplyConfiguration, force bool) *corev1ac.ContainerApplyConfiguration {
 	stsINotNil := (sts != nil && sts.Spec != nil && sts.Spec.Template != nil && sts.Spec.Template.Spec != nil)
 
@@ -201,6 +402,13 @@ func (r *MySQLClusterReconciler) makeV1SlowQueryLogContainer(cluster *mocov1beta
 	c := corev1ac.Container().
 		WithName(constants.SlowQueryLogAgentContainerName).
 		WithImage(r.FluentBitImage).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
+		WithLifecycle(corev1ac.Lifecycle().
+			WithPreStop(corev1ac.LifecycleHandler().
+				WithExec(corev1ac.ExecAction().
+					WithCommand("sleep", strconv.Itoa(int(slowQueryLogAgentPreStopSeconds(cluster))))),
+			),
+		).
 		WithVolumeMounts(
 			corev1ac.VolumeMount().
 				WithName(constants.SlowQueryLogAgentConfigVolumeName).
@@ -222,7 +430,122 @@ func (r *MySQLClusterReconciler) makeV1SlowQueryLogContainer(cluster *mocov1beta
 				}),
 		)
 
+	if envs := slowQueryLogOutputEnvs(cluster); len(envs) > 0 {
+		c.WithEnv(envs...)
+	}
+
 	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
+	updateContainerWithOverwriteContainers(cluster, c)
+
+	return c
+}
+
+// slowQueryLogAgentPreStopSeconds returns the preStop sleep duration of the slow-log container.
+// The default is extended when the preStop hook of mysqld container may take longer than usual.
+func slowQueryLogAgentPreStopSeconds(cluster *mocov1beta2.MySQLCluster) int32 {
+	if cluster.Spec.SlowQueryLogAgentPreStopSeconds != nil {
+		return *cluster.Spec.SlowQueryLogAgentPreStopSeconds
+	}
+	extra := replicaDrainSeconds(cluster) - int32(constants.ReplicaDrainSeconds)
+	if extra > 0 {
+		return constants.SlowQueryLogAgentPreStopSeconds + extra
+	}
+	return constants.SlowQueryLogAgentPreStopSeconds
+}
+
+// replicaDrainSeconds returns the maximum duration of the preStop hook of mysqld container
+// to wait for client connections to finish.  Zero means draining is disabled.
+func replicaDrainSeconds(cluster *mocov1beta2.MySQLCluster) int32 {
+	if cluster.Spec.DisableMyCnfSecretVolume {
+		// the hook cannot log in to mysqld without the credentials.
+		return 0
+	}
+	if cluster.Spec.ReplicaDrainSeconds != nil {
+		return *cluster.Spec.ReplicaDrainSeconds
+	}
+	return constants.ReplicaDrainSeconds
+}
+
+// mysqldPreStopCommand returns the command of the preStop hook of mysqld container.
+//
+// The hook waits until no client connections remain and no replica replicates from
+// the instance, up to `replicaDrainSeconds`.  Because the primary has replicas connected,
+// it waits for the whole duration so that the switchover completes before mysqld stops.
+// If the state of mysqld is unknown, the hook keeps waiting as the plain sleep did.
+func mysqldPreStopCommand(cluster *mocov1beta2.MySQLCluster) []string {
+	seconds := replicaDrainSeconds(cluster)
+	if seconds == 0 {
+		return []string{"sleep", constants.PreStopSeconds}
+	}
+
+	users := make([]string, 0, len(constants.MocoSystemUsers))
+	for u := range constants.MocoSystemUsers {
+		users = append(users, "'"+u+"'")
+	}
+	sort.Strings(users)
+
+	socket := filepath.Join(constants.RunPath, "mysqld.sock")
+	cnf := filepath.Join(constants.MyCnfSecretPath, constants.AdminMyCnf)
+	query := fmt.Sprintf("SELECT COUNT(*) FROM information_schema.PROCESSLIST WHERE (HOST NOT IN ('', 'localhost') AND USER NOT IN (%s)) OR COMMAND LIKE 'Binlog Dump%%'",
+		strings.Join(users, ", "))
+
+	script := fmt.Sprintf(`i=0
+while [ $i -lt %d ]; do
+  n=$(mysql --defaults-extra-file=%s --socket=%s -N -B -e "%s" 2>/dev/null)
+  if [ "$n" = 0 ]; then
+    exit 0
+  fi
+  i=$((i+1))
+  sleep 1
+done
+exit 0
+`, seconds, cnf, socket, query)
+	return []string{"sh", "-c", script}
+}
+
+func (r *MySQLClusterReconciler) makeV1AuditLogContainer(cluster *mocov1beta2.MySQLCluster, sts *appsv1ac.StatefulSetApplyConfiguration, force bool) *corev1ac.ContainerApplyConfiguration {
+	if !force && sts != nil && sts.Spec != nil && sts.Spec.Template != nil && sts.Spec.Template.Spec != nil {
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if *c.Name == constants.AuditLogAgentContainerName {
+				return &c
+			}
+		}
+	}
+
+	c := corev1ac.Container().
+		WithName(constants.AuditLogAgentContainerName).
+		WithImage(r.FluentBitImage).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
+		WithLifecycle(corev1ac.Lifecycle().
+			WithPreStop(corev1ac.LifecycleHandler().
+				WithExec(corev1ac.ExecAction().
+					WithCommand("sleep", strconv.Itoa(constants.AuditLogAgentPreStopSeconds))),
+			),
+		).
+		WithVolumeMounts(
+			corev1ac.VolumeMount().
+				WithName(constants.AuditLogAgentConfigVolumeName).
+				WithMountPath(constants.FluentBitConfigPath).
+				WithReadOnly(true),
+			corev1ac.VolumeMount().
+				WithName(constants.VarLogVolumeName).
+				WithMountPath(constants.LogDirPath),
+		).
+		WithResources(
+			corev1ac.ResourceRequirements().
+				WithRequests(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(constants.AuditLogAgentCPURequest),
+					corev1.ResourceMemory: resource.MustParse(constants.AuditLogAgentMemRequest),
+				}).
+				WithLimits(corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse(constants.AuditLogAgentCPULimit),
+					corev1.ResourceMemory: resource.MustParse(constants.AuditLogAgentMemLimit),
+				}),
+		)
+
+	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
 	updateContainerWithOverwriteContainers(cluster, c)
 
 	return c
@@ -232,7 +555,8 @@ func (r *MySQLClusterReconciler) makeV1ExporterContainer(cluster *mocov1beta2.My
 	c := corev1ac.Container().
 		WithName(constants.ExporterContainerName).
 		WithImage(r.ExporterImage).
-		WithArgs("--config.my-cnf="+filepath.Join(constants.MyCnfSecretPath, constants.ExporterMyCnf)).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
+		WithArgs("--config.my-cnf="+filepath.Join(constants.MyCnfSecretPath, exporterMyCnf(cluster, constants.ExporterMyCnf, constants.ExporterUserMyCnf))).
 		WithPorts(
 			corev1ac.ContainerPort().
 				WithName(constants.ExporterPortName).
@@ -262,8 +586,10 @@ func (r *MySQLClusterReconciler) makeV1ExporterContainer(cluster *mocov1beta2.My
 	for _, cl := range collectors {
 		c.WithArgs("--collect." + cl)
 	}
+	c.WithEnv(exporterEnvs(cluster)...)
 
 	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
 	updateContainerWithOverwriteContainers(cluster, c)
 
 	return c
@@ -289,6 +615,10 @@ func (r *MySQLClusterReconciler) makeV1OptionalContainers(cluster *mocov1beta2.M
 			if cluster.Spec.DisableSlowQueryLogContainer {
 				containers = append(containers, &c)
 			}
+		case constants.AuditLogAgentContainerName:
+			if !cluster.Spec.EnableAuditLogContainer {
+				containers = append(containers, &c)
+			}
 		case constants.ExporterContainerName:
 			if len(cluster.Spec.Collectors) == 0 {
 				containers = append(containers, &c)
@@ -323,9 +653,10 @@ func (r *MySQLClusterReconciler) makeMocoInitContainer(ctx context.Context, clus
 	c := corev1ac.Container().
 		WithName(constants.InitContainerName).
 		WithImage(image).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
 		WithCommand(
 			filepath.Join(constants.SharedPath, constants.InitCommand),
-			fmt.Sprintf("%s=%s", constants.MocoInitDataDirFlag, constants.MySQLDataPath),
+			fmt.Sprintf("%s=%s", constants.MocoInitDataDirFlag, cluster.Spec.MySQLDataDir()),
 			fmt.Sprintf("%s=%s", constants.MocoInitConfDirFlag, constants.MySQLInitConfPath),
 			fmt.Sprintf("%d", cluster.Spec.ServerIDBase),
 		).WithEnv(
@@ -339,7 +670,7 @@ func (r *MySQLClusterReconciler) makeMocoInitContainer(ctx context.Context, clus
 	).WithVolumeMounts(
 		corev1ac.VolumeMount().
 			WithName(constants.MySQLDataVolumeName).
-			WithMountPath(constants.MySQLDataPath),
+			WithMountPath(cluster.Spec.MySQLDataDir()),
 		corev1ac.VolumeMount().
 			WithName(constants.MySQLInitConfVolumeName).
 			WithMountPath(constants.MySQLInitConfPath),
@@ -369,6 +700,7 @@ func (r *MySQLClusterReconciler) makeMocoInitContainer(ctx context.Context, clus
 	}
 
 	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
 	updateContainerWithOverwriteContainers(cluster, c)
 
 	return c, nil
@@ -378,6 +710,7 @@ func (r *MySQLClusterReconciler) makeInitContainerWithCopyMocoInitBin(cluster *m
 	c := corev1ac.Container().
 		WithName(constants.CopyInitContainerName).
 		WithImage(r.AgentImage).
+		WithImagePullPolicy(corev1.PullIfNotPresent).
 		WithCommand("cp",
 			filepath.Join("/", constants.InitCommand),
 			filepath.Join(constants.SharedPath, constants.InitContainerName)).
@@ -397,6 +730,7 @@ func (r *MySQLClusterReconciler) makeInitContainerWithCopyMocoInitBin(cluster *m
 			WithMountPath(constants.SharedPath))
 
 	updateContainerWithSecurityContext(c)
+	updateContainerWithRestrictedSecurityContext(c)
 	updateContainerWithOverwriteContainers(cluster, c)
 
 	return c
@@ -425,6 +759,20 @@ func updateContainerWithSecurityContext(container *corev1ac.ContainerApplyConfig
 		WithRunAsGroup(constants.ContainerGID)
 }
 
+// updateContainerWithRestrictedSecurityContext disallows privilege escalation and drops all capabilities
+// unless they are specified, as required by the "restricted" Pod Security Standard.
+func updateContainerWithRestrictedSecurityContext(container *corev1ac.ContainerApplyConfiguration) {
+	if container.SecurityContext == nil {
+		container.WithSecurityContext(corev1ac.SecurityContext())
+	}
+	if container.SecurityContext.AllowPrivilegeEscalation == nil {
+		container.SecurityContext.WithAllowPrivilegeEscalation(false)
+	}
+	if container.SecurityContext.Capabilities == nil {
+		container.SecurityContext.WithCapabilities(corev1ac.Capabilities().WithDrop("ALL"))
+	}
+}
+
 func updateContainerWithOverwriteContainers(cluster *mocov1beta2.MySQLCluster, container *corev1ac.ContainerApplyConfiguration) {
 	if len(cluster.Spec.PodTemplate.OverwriteContainers) == 0 {
 		return
@@ -436,6 +784,22 @@ func updateContainerWithOverwriteContainers(cluster *mocov1beta2.MySQLCluster, c
 			if overwrite.Resources != nil {
 				container.WithResources((*corev1ac.ResourceRequirementsApplyConfiguration)(overwrite.Resources))
 			}
+			if overwrite.ImagePullPolicy != "" {
+				container.WithImagePullPolicy(overwrite.ImagePullPolicy)
+			}
+			if overwrite.SecurityContext != nil {
+				if container.SecurityContext == nil {
+					container.WithSecurityContext(corev1ac.SecurityContext())
+				}
+				// Merge the given fields into the security context.
+				data, err := json.Marshal(overwrite.SecurityContext)
+				if err != nil {
+					panic("Failed to marshal")
+				}
+				if err := json.Unmarshal(data, container.SecurityContext); err != nil {
+					panic("Failed to unmarshal")
+				}
+			}
 		}
 	}
 }
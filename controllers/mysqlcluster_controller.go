@@ -6,14 +6,17 @@ import (
 	"errors"
 	"fmt"
 	"hash/fnv"
+	"math"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/clustering"
 	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/event"
 	"github.com/cybozu-go/moco/pkg/metrics"
 	"github.com/cybozu-go/moco/pkg/mycnf"
 	"github.com/cybozu-go/moco/pkg/password"
@@ -41,18 +44,26 @@ import (
 	"k8s.io/client-go/tools/record"
 	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 const (
-	defaultTerminationGracePeriodSeconds = 300
-	fieldManager                         = "moco-controller"
+	defaultTerminationGracePeriodSeconds    = 300
+	defaultJobTerminationGracePeriodSeconds = 60
+	defaultRevisionHistoryLimit             = 3
+	serviceAccountTokenExpirationSeconds    = 3607
+	fieldManager                            = "moco-controller"
+
+	// sourceClusterIndexField is the field index of MySQLClusters by `spec.replicationSource`.
+	sourceClusterIndexField = ".spec.replicationSource"
 )
 
 // debug and test variables
@@ -147,13 +158,88 @@ type MySQLClusterReconciler struct {
 	PVCSyncLabelKeys        []string
 	ClusterManager          clustering.ClusterManager
 	MaxConcurrentReconciles int
+
+	// PDBForTwoReplicas enables creating a PodDisruptionBudget for clusters with 2 replicas.
+	PDBForTwoReplicas bool
+
+	// DisableDefaultAntiAffinity disables the default preferred pod anti-affinity
+	// added to MySQL Pods that do not specify any affinity.
+	DisableDefaultAntiAffinity bool
+
+	// ReloaderAnnotations enables annotating StatefulSets with the names of
+	// my.cnf ConfigMap and Secret for Stakater Reloader.
+	ReloaderAnnotations bool
+
+	// RoleLabelKey is the key of the role label for newly created clusters.
+	// If empty, constants.LabelMocoRole is used.
+	RoleLabelKey string
+
+	// TransientErrorBaseBackoff and TransientErrorMaxBackoff configure the delay to requeue
+	// a cluster when reconciling backup or restore resources fails with a transient error.
+	// If TransientErrorBaseBackoff is zero, such errors are returned as is.
+	TransientErrorBaseBackoff time.Duration
+	TransientErrorMaxBackoff  time.Duration
+
+	// StatefulSetOrdinals tells that the Kubernetes cluster supports `spec.ordinals` of StatefulSet.
+	// If false, `spec.ordinals` of MySQLCluster is ignored.
+	StatefulSetOrdinals bool
+
+	// PVCRetentionPolicy tells that the Kubernetes cluster supports `spec.persistentVolumeClaimRetentionPolicy`
+	// of StatefulSet.  If false, `spec.persistentVolumeClaimRetentionPolicy` of MySQLCluster is ignored.
+	PVCRetentionPolicy bool
+
+	// PodFailurePolicy tells that the Kubernetes cluster supports `spec.podFailurePolicy` of Job.
+	// If false, `podFailurePolicy` of JobConfig is ignored.
+	PodFailurePolicy bool
+
+	// AgentCertDuration and AgentCertRenewBefore configure `duration` and `renewBefore`
+	// of the Certificate for moco-agent.  If zero, the defaults of cert-manager are used.
+	// They are applied only to newly created Certificates.
+	AgentCertDuration    time.Duration
+	AgentCertRenewBefore time.Duration
+
+	// AgentCertExpiryThreshold, if positive, makes CertificateExpiringSoon condition of
+	// MySQLCluster true when the certificate for moco-agent expires within the duration.
+	AgentCertExpiryThreshold time.Duration
+
+	// GracePeriodPerGiB, if positive, makes the termination grace period of MySQL Pods
+	// proportional to `innodb_buffer_pool_size` when the Pod template does not specify one.
+	// MaxGracePeriod, if positive, caps the derived period.
+	GracePeriodPerGiB time.Duration
+	MaxGracePeriod    time.Duration
+
+	// WatchNamespace, if not empty, is the only namespace of MySQLClusters to be reconciled.
+	// The cache of the manager is expected to be scoped to it and SystemNamespace.
+	WatchNamespace string
+
+	// ServerIDAllocator, if not nil, releases the server ID range of deleted clusters.
+	ServerIDAllocator *ServerIDAllocator
+
+	// CanaryReadyTimeout, if positive, is how long the canary upgrade waits for the updated
+	// instances to become ready before recording a warning event.
+	CanaryReadyTimeout time.Duration
+
+	// MyCnfConfigMapLabels and MyCnfConfigMapAnnotations are added to the ConfigMaps for my.cnf.
+	// They are useful to keep GitOps tools from pruning the ConfigMaps that MOCO generates.
+	// The labels of MOCO take precedence.
+	MyCnfConfigMapLabels      map[string]string
+	MyCnfConfigMapAnnotations map[string]string
+
+	// MySQLDNSOptions are the DNS resolver options of MySQL Pods, such as ndots.
+	// They are added only to the Pods whose template does not specify dnsConfig.
+	MySQLDNSOptions map[string]string
+
+	transientBackoff transientBackoff
+	canaryTracker    canaryTracker
+	eventTracker     eventTracker
 }
 
-//+kubebuilder:rbac:groups=moco.cybozu.com,resources=mysqlclusters,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups=moco.cybozu.com,resources=mysqlclusters,verbs=get;list;watch;update;patch;delete
 //+kubebuilder:rbac:groups=moco.cybozu.com,resources=mysqlclusters/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=moco.cybozu.com,resources=mysqlclusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups=moco.cybozu.com,resources=backuppolicies,verbs=get;list;watch
 //+kubebuilder:rbac:groups=apps,resources=statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=apps,resources=statefulsets/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=secrets/status,verbs=get
@@ -164,12 +250,18 @@ type MySQLClusterReconciler struct {
 //+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="",resources=configmaps/status,verbs=get
 //+kubebuilder:rbac:groups="",resources=events,verbs=create;update;patch
+//+kubebuilder:rbac:groups="",resources=namespaces,verbs=get
+//+kubebuilder:rbac:groups="",resources=nodes,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=persistentvolumeclaims,verbs=get;list;watch;update;patch
+//+kubebuilder:rbac:groups="",resources=resourcequotas,verbs=get;list;watch
 //+kubebuilder:rbac:groups="storage.k8s.io",resources=storageclasses,verbs=get;list;watch
 //+kubebuilder:rbac:groups="policy",resources=poddisruptionbudgets,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="cert-manager.io",resources=certificates,verbs=get;list;watch;create;delete
+//+kubebuilder:rbac:groups="monitoring.coreos.com",resources=podmonitors,verbs=get;create;update;patch;delete
+//+kubebuilder:rbac:groups="gateway.networking.k8s.io",resources=tcproutes;tlsroutes,verbs=get;create;update;patch;delete
 //+kubebuilder:rbac:groups="batch",resources=cronjobs;jobs,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=roles;rolebindings,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups="rbac.authorization.k8s.io",resources=clusterroles,verbs=get;list;watch
 
 // Reconcile implements Reconciler interface.
 // See https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.8.3/pkg/reconcile#Reconciler
@@ -217,6 +309,7 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		log.Info("start finalizing MySQLCluster")
 
 		r.ClusterManager.Stop(req.NamespacedName)
+		r.transientBackoff.reset(req.NamespacedName)
 
 		if err = r.finalizeV1(ctx, cluster); err != nil {
 			log.Error(err, "failed to finalize")
@@ -252,10 +345,44 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, nil
 	}
 
+	if err = r.reconcileV1RoleLabelKey(ctx, cluster); err != nil {
+		log.Error(err, "failed to reconcile role label key")
+		return ctrl.Result{}, err
+	}
+
+	deleted, err := r.deleteExpiredPointInTimeClone(ctx, cluster, time.Now())
+	if err != nil {
+		log.Error(err, "failed to delete the expired point-in-time clone")
+		return ctrl.Result{}, err
+	}
+	if deleted {
+		return ctrl.Result{}, nil
+	}
+
+	// transientErr is recorded in the status even when the error is not returned.
+	var transientErr error
+	var mycnfName string
 	defer func() {
-		if err2 := r.updateStatus(ctx, cluster, err); err2 != nil {
+		statusErr := err
+		if statusErr == nil {
+			statusErr = transientErr
+		}
+		if err2 := r.updateStatus(ctx, cluster, mycnfName, statusErr); err2 != nil {
 			err = err2
 			log.Error(err2, "failed to update status")
+			return
+		}
+		if err != nil {
+			return
+		}
+		if d := r.untilCertificateExpiringSoon(cluster, time.Now()); d > 0 && (result.RequeueAfter == 0 || d < result.RequeueAfter) {
+			result.RequeueAfter = d
+		}
+		if d := untilPointInTimeCloneExpiry(cluster, time.Now()); d > 0 && (result.RequeueAfter == 0 || d < result.RequeueAfter) {
+			result.RequeueAfter = d
+		}
+		if d := r.canaryTracker.untilStalled(req.NamespacedName, r.CanaryReadyTimeout, time.Now()); d > 0 && (result.RequeueAfter == 0 || d < result.RequeueAfter) {
+			result.RequeueAfter = d
 		}
 	}()
 
@@ -264,6 +391,16 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
+	if err = r.reconcileV1UserSecrets(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile password secrets for users")
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileV1ReplicationSourceSecret(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile replication source secret")
+		return ctrl.Result{}, err
+	}
+
 	if err = r.reconcileV1Certificate(ctx, req, cluster); err != nil {
 		log.Error(err, "failed to reconcile certificate")
 		return ctrl.Result{}, err
@@ -279,12 +416,25 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		log.Error(err, "failed to reconcile my.conf config map")
 		return ctrl.Result{}, err
 	}
+	// mycnf is nil if the ConfigMap of spec.mysqlConfigMapName is missing.
+	// The other resources are still reconciled, and the ConfigMap watch
+	// triggers another reconciliation once it is created.
+	if mycnf != nil {
+		mycnfName = *mycnf.Name
+	} else {
+		transientErr = fmt.Errorf("configmap %s/%s is not found", cluster.Namespace, *cluster.Spec.MySQLConfigMapName)
+	}
 
 	if err = r.reconcileV1FluentBitConfigMap(ctx, req, cluster); err != nil {
 		log.Error(err, "failed to reconcile config maps for fluent-bit")
 		return ctrl.Result{}, err
 	}
 
+	if err = r.reconcileV1Dashboard(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile config map for dashboard")
+		return ctrl.Result{}, err
+	}
+
 	if err = r.reconcileV1ServiceAccount(ctx, req, cluster); err != nil {
 		return ctrl.Result{}, err
 	}
@@ -297,8 +447,30 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	if err = r.reconcileV1StatefulSet(ctx, req, cluster, mycnf); err != nil {
-		log.Error(err, "failed to reconcile stateful set")
+	if mycnf != nil {
+		if err = r.reconcileV1StatefulSet(ctx, req, cluster, mycnf); err != nil {
+			log.Error(err, "failed to reconcile stateful set")
+			return ctrl.Result{}, err
+		}
+	}
+
+	if err = r.reconcileV1Exporter(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile mysqld_exporter")
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileV1PodMonitor(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile pod monitor")
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileV1MetricsService(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile metrics service")
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileV1GatewayRoute(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile gateway route")
 		return ctrl.Result{}, err
 	}
 
@@ -306,11 +478,37 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		return ctrl.Result{}, err
 	}
 
-	if err = r.reconcileV1BackupJob(ctx, req, cluster); err != nil {
+	// The RoleBinding for a job is not created while the referenced role is unusable,
+	// but the other resources are still reconciled.
+	if err = r.reconcileV1BackupJob(ctx, req, cluster); errors.Is(err, errJobRoleUnusable) {
+		transientErr, err = err, nil
+	} else if err != nil {
+		if r.TransientErrorBaseBackoff > 0 && isTransientError(err) {
+			transientErr = err
+			return r.requeueTransient(ctx, req, err), nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	if err = r.reconcileV1MaintenanceJob(ctx, req, cluster); err != nil {
+		log.Error(err, "failed to reconcile maintenance job")
 		return ctrl.Result{}, err
 	}
 
-	if err = r.reconcileV1RestoreJob(ctx, req, cluster); err != nil {
+	if err = r.reconcileV1RestoreJob(ctx, req, cluster); errors.Is(err, errJobRoleUnusable) {
+		transientErr, err = err, nil
+	} else if err != nil {
+		if r.TransientErrorBaseBackoff > 0 && isTransientError(err) {
+			transientErr = err
+			return r.requeueTransient(ctx, req, err), nil
+		}
+		return ctrl.Result{}, err
+	}
+	r.transientBackoff.reset(req.NamespacedName)
+
+	restoreCleanupAfter, err := r.cleanupV1RestoreJob(ctx, req, cluster)
+	if err != nil {
+		log.Error(err, "failed to clean up restore job")
 		return ctrl.Result{}, err
 	}
 
@@ -318,12 +516,39 @@ func (r *MySQLClusterReconciler) reconcileV1(ctx context.Context, req ctrl.Reque
 		if err := r.clusteringStopV1(ctx, cluster); err != nil {
 			return ctrl.Result{}, err
 		}
-		return ctrl.Result{}, nil
+		return ctrl.Result{RequeueAfter: restoreCleanupAfter}, nil
 	}
 
 	r.ClusterManager.Update(client.ObjectKeyFromObject(cluster), string(controller.ReconcileIDFromContext(ctx)))
 	metrics.ClusteringStoppedVec.WithLabelValues(cluster.Name, cluster.Namespace).Set(0)
-	return ctrl.Result{}, nil
+	return ctrl.Result{RequeueAfter: restoreCleanupAfter}, nil
+}
+
+// reconcileV1RoleLabelKey records the role label key of the cluster in an annotation.
+// Once the Pods are labeled, the key must not be changed, so the configured key
+// is only applied to clusters that have never been reconciled.
+func (r *MySQLClusterReconciler) reconcileV1RoleLabelKey(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	if _, ok := cluster.Annotations[constants.AnnRoleLabelKey]; ok {
+		return nil
+	}
+	if r.RoleLabelKey == "" || r.RoleLabelKey == constants.LabelMocoRole {
+		return nil
+	}
+	if cluster.Status.ReconcileInfo.Generation != 0 {
+		return nil
+	}
+
+	orig := cluster.DeepCopy()
+	if cluster.Annotations == nil {
+		cluster.Annotations = make(map[string]string)
+	}
+	cluster.Annotations[constants.AnnRoleLabelKey] = r.RoleLabelKey
+	if err := r.Patch(ctx, cluster, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to set %s annotation: %w", constants.AnnRoleLabelKey, err)
+	}
+
+	crlog.FromContext(ctx).Info("set role label key", "key", r.RoleLabelKey)
+	return nil
 }
 
 func (r *MySQLClusterReconciler) reconcileV1Secret(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
@@ -351,6 +576,10 @@ func (r *MySQLClusterReconciler) reconcileV1Secret(ctx context.Context, req ctrl
 		return err
 	}
 
+	if err := r.reconcilePasswordRotation(ctx, cluster, secret); err != nil {
+		return err
+	}
+
 	if err := r.reconcileUserSecret(ctx, req, cluster, secret); err != nil {
 		return err
 	}
@@ -376,6 +605,9 @@ func (r *MySQLClusterReconciler) reconcileUserSecret(ctx context.Context, req ct
 		WithAnnotations(newSecret.Annotations).
 		WithLabels(labelSet(cluster, false)).
 		WithData(newSecret.Data)
+	if rev := controllerSecret.Annotations[constants.AnnPasswordRevision]; rev != "" {
+		secret.WithAnnotations(map[string]string{constants.AnnPasswordRevision: rev})
+	}
 
 	if err := setControllerReferenceWithSecret(cluster, secret, r.Scheme); err != nil {
 		return fmt.Errorf("failed to set ownerReference to Secret %s/%s: %w", cluster.Namespace, name, err)
@@ -406,6 +638,16 @@ func (r *MySQLClusterReconciler) reconcileMyCnfSecret(ctx context.Context, req c
 		return fmt.Errorf("failed to create password from Secret %s/%s: %w", controllerSecret.Namespace, controllerSecret.Name, err)
 	}
 	mycnfSecret := passwd.ToMyCnfSecret()
+	if cluster.Spec.ExporterDeploymentEnabled() {
+		host := fmt.Sprintf("%s.%s.svc", cluster.PrimaryServiceName(), cluster.Namespace)
+		mycnfSecret.Data[constants.ExporterRemoteMyCnf] = passwd.ExporterMyCnf(host)
+		if u := cluster.Spec.ExporterUser(); u != nil {
+			mycnfSecret.Data[constants.ExporterUserRemoteMyCnf] = password.UserMyCnf(u.Name, "", host)
+		}
+	}
+	if u := cluster.Spec.ExporterUser(); u != nil && cluster.Spec.ExporterSidecarEnabled() {
+		mycnfSecret.Data[constants.ExporterUserMyCnf] = password.UserMyCnf(u.Name, filepath.Join(constants.RunPath, "mysqld.sock"), "")
+	}
 
 	name := cluster.MyCnfSecretName()
 	secret := corev1ac.Secret(name, cluster.Namespace).
@@ -444,34 +686,38 @@ func (r *MySQLClusterReconciler) reconcileV1MyCnf(ctx context.Context, req ctrl.
 		return nil, fmt.Errorf("MySQLD container not found")
 	}
 
-	// resources.requests.memory takes precedence over resources.limits.memory.
-	var totalMem int64
-	if mysqldContainer.Resources != nil {
-		if mysqldContainer.Resources.Limits != nil {
-			if res := mysqldContainer.Resources.Limits.Memory(); !res.IsZero() {
-				totalMem = res.Value()
-			}
-		}
-
-		if mysqldContainer.Resources.Requests != nil {
-			if res := mysqldContainer.Resources.Requests.Memory(); !res.IsZero() {
-				totalMem = res.Value()
-			}
-		}
-	}
+	totalMem := mysqldContainerMemory(mysqldContainer)
 
 	var userConf map[string]string
 	if cluster.Spec.MySQLConfigMapName != nil {
 		cm := &corev1.ConfigMap{}
 		err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: *cluster.Spec.MySQLConfigMapName}, cm)
+		if apierrors.IsNotFound(err) {
+			// Generating my.cnf without the user configuration would restart mysqld with
+			// wrong parameters, so keep the current my.cnf until the ConfigMap is created.
+			// The event is recorded once; the status tells that the problem continues.
+			log.Info("specified configmap is not found", "configmap", *cluster.Spec.MySQLConfigMapName)
+			r.eventTracker.emit(cluster, r.Recorder, "MySQLConfigMap", event.MySQLConfigMapNotFound, *cluster.Spec.MySQLConfigMapName)
+			return nil, nil
+		}
 		if err != nil {
 			log.Error(err, "failed to get specified configmap", "configmap", *cluster.Spec.MySQLConfigMapName)
 			return nil, err
 		}
+		r.eventTracker.clear(cluster, "MySQLConfigMap")
 		userConf = cm.Data
 	}
 
-	conf := mycnf.Generate(userConf, totalMem)
+	conf := mycnf.Generate(userConf, mycnf.Options{
+		MemTotal:            totalMem,
+		DataDir:             cluster.Spec.DataDir,
+		TmpDir:              cluster.Spec.TmpDir,
+		DisableSlowQueryLog: cluster.Spec.DisableSlowQueryLog,
+		EnableAuditLog:      cluster.Spec.EnableAuditLogContainer,
+		RedoLog:             cluster.Spec.RedoLog.Mycnf(),
+		SlowQueryLog:        cluster.Spec.SlowQueryLog.Mycnf(),
+		MaxConnections:      cluster.Spec.MaxConnections.Derive(totalMem),
+	})
 
 	fnv32a := fnv.New32a()
 	fnv32a.Write([]byte(conf))
@@ -485,8 +731,12 @@ func (r *MySQLClusterReconciler) reconcileV1MyCnf(ctx context.Context, req ctrl.
 	}
 
 	cm := corev1ac.ConfigMap(cmName, cluster.Namespace).
+		WithLabels(r.MyCnfConfigMapLabels).
 		WithLabels(labelSet(cluster, false)).
 		WithData(cmData)
+	if len(r.MyCnfConfigMapAnnotations) > 0 {
+		cm.WithAnnotations(r.MyCnfConfigMapAnnotations)
+	}
 
 	if err := setControllerReferenceWithConfigMap(cluster, cm, r.Scheme); err != nil {
 		return nil, fmt.Errorf("failed to set ownerReference to ConfigMap %s/%s: %w", cluster.Namespace, cmName, err)
@@ -502,6 +752,13 @@ func (r *MySQLClusterReconciler) reconcileV1MyCnf(ctx context.Context, req ctrl.
 
 	log.Info("reconciled my.cnf ConfigMap", "configMapName", cmName)
 
+	// the buffer pool is derived from the memory of the container, so it is tiny without a memory request.
+	// The event is recorded only when the generated ConfigMap changes.
+	if totalMem == 0 && !mycnf.HasInnoDBBufferPoolSize(userConf) {
+		log.Info("innodb_buffer_pool_size is not derived because the mysqld container has no memory request")
+		event.BufferPoolSizeNotDerived.Emit(cluster, r.Recorder, mycnf.MinInnoDBBufferPoolSize>>20)
+	}
+
 	cms := &corev1.ConfigMapList{}
 	if err := r.List(ctx, cms, client.InNamespace(cluster.Namespace)); err != nil {
 		return nil, err
@@ -518,57 +775,71 @@ func (r *MySQLClusterReconciler) reconcileV1MyCnf(ctx context.Context, req ctrl.
 }
 
 func (r *MySQLClusterReconciler) reconcileV1FluentBitConfigMap(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
-	log := crlog.FromContext(ctx)
-
 	configTmpl := `[SERVICE]
   Log_Level      error
+  Grace          %d
 [INPUT]
   Name           tail
   Path           %s
   Read_from_Head true
-[OUTPUT]
-  Name           file
-  Match          *
-  Path           /dev
-  File           stdout
-  Format         template
-  Template       {log}
-`
+%s%s`
 
+	var slowConf string
 	if !cluster.Spec.DisableSlowQueryLogContainer {
-		name := cluster.SlowQueryLogAgentConfigMapName()
-		confVal := fmt.Sprintf(configTmpl, filepath.Join(constants.LogDirPath, constants.MySQLSlowLogName))
-		data := map[string]string{
-			constants.FluentBitConfigName: confVal,
-		}
-
-		cm := corev1ac.ConfigMap(name, cluster.Namespace).
-			WithLabels(labelSet(cluster, false)).
-			WithData(data)
+		slowConf = fmt.Sprintf(configTmpl, constants.SlowQueryLogAgentGraceSeconds, filepath.Join(constants.LogDirPath, constants.MySQLSlowLogName), "", makeSlowQueryLogOutput(cluster))
+	}
+	if err := r.applyFluentBitConfigMap(ctx, cluster, cluster.SlowQueryLogAgentConfigMapName(), slowConf, "slow logs"); err != nil {
+		return err
+	}
 
-		if err := setControllerReferenceWithConfigMap(cluster, cm, r.Scheme); err != nil {
-			return fmt.Errorf("failed to set ownerReference to ConfigMap %s/%s: %w", cluster.Namespace, name, err)
-		}
+	var auditConf string
+	if cluster.Spec.EnableAuditLogContainer {
+		// The audit log plugin renames the file on rotation.  fluent-bit keeps reading the renamed
+		// file for Rotate_Wait seconds, and finds the new file at every Refresh_Interval.
+		tailOpts := "  Refresh_Interval 5\n  Rotate_Wait    60\n"
+		auditConf = fmt.Sprintf(configTmpl, constants.AuditLogAgentGraceSeconds, filepath.Join(constants.LogDirPath, constants.MySQLAuditLogName), tailOpts, makeAuditLogOutput())
+	}
+	return r.applyFluentBitConfigMap(ctx, cluster, cluster.AuditLogAgentConfigMapName(), auditConf, "audit logs")
+}
 
-		key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
-		if _, err := apply(ctx, r.Client, key, cm, corev1ac.ExtractConfigMap); err != nil {
-			if errors.Is(err, ErrApplyConfigurationNotChanged) {
-				return nil
-			}
-			return fmt.Errorf("failed to reconcile configmap %s/%s for slow logs: %w", cluster.Namespace, name, err)
-		}
+// applyFluentBitConfigMap applies the ConfigMap of fluent-bit with `conf`.
+// If `conf` is empty, the ConfigMap is deleted.
+func (r *MySQLClusterReconciler) applyFluentBitConfigMap(ctx context.Context, cluster *mocov1beta2.MySQLCluster, name, conf, logs string) error {
+	log := crlog.FromContext(ctx)
 
-		log.Info("reconciled ConfigMap for slow logs", "configMapName", name)
-	} else {
+	if conf == "" {
 		cm := &corev1.ConfigMap{}
 		cm.Namespace = cluster.Namespace
-		cm.Name = cluster.SlowQueryLogAgentConfigMapName()
+		cm.Name = name
 		err := r.Client.Delete(ctx, cm)
 		if err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to delete configmap for slow logs: %w", err)
+			return fmt.Errorf("failed to delete configmap for %s: %w", logs, err)
+		}
+		return nil
+	}
+
+	data := map[string]string{
+		constants.FluentBitConfigName: conf,
+	}
+
+	cm := corev1ac.ConfigMap(name, cluster.Namespace).
+		WithLabels(labelSet(cluster, false)).
+		WithData(data)
+
+	if err := setControllerReferenceWithConfigMap(cluster, cm, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to ConfigMap %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, cm, corev1ac.ExtractConfigMap); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
 		}
+		return fmt.Errorf("failed to reconcile configmap %s/%s for %s: %w", cluster.Namespace, name, logs, err)
 	}
 
+	log.Info("reconciled ConfigMap for "+logs, "configMapName", name)
+
 	return nil
 }
 
@@ -597,25 +868,41 @@ func (r *MySQLClusterReconciler) reconcileV1ServiceAccount(ctx context.Context,
 }
 
 func (r *MySQLClusterReconciler) reconcileV1Service(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
-	if err := r.reconcileV1Service1(ctx, cluster, nil, cluster.HeadlessServiceName(), true, labelSet(cluster, false)); err != nil {
+	if err := r.reconcileV1Service1(ctx, cluster, nil, cluster.HeadlessServiceName(), true, labelSet(cluster, false), nil); err != nil {
 		return err
 	}
 
 	primarySelector := labelSet(cluster, false)
-	primarySelector[constants.LabelMocoRole] = constants.RolePrimary
-	if err := r.reconcileV1Service1(ctx, cluster, cluster.Spec.PrimaryServiceTemplate, cluster.PrimaryServiceName(), false, primarySelector); err != nil {
+	primarySelector[cluster.RoleLabelKey()] = constants.RolePrimary
+	if err := r.reconcileV1Service1(ctx, cluster, cluster.Spec.PrimaryServiceTemplate, cluster.PrimaryServiceName(), false, primarySelector, nil); err != nil {
 		return err
 	}
 
 	replicaSelector := labelSet(cluster, false)
-	replicaSelector[constants.LabelMocoRole] = constants.RoleReplica
-	if err := r.reconcileV1Service1(ctx, cluster, cluster.Spec.ReplicaServiceTemplate, cluster.ReplicaServiceName(), false, replicaSelector); err != nil {
+	replicaSelector[cluster.RoleLabelKey()] = constants.RoleReplica
+	var replicaAnnotations map[string]string
+	if cluster.Spec.TopologyAwareReplicaService {
+		replicaAnnotations = map[string]string{corev1.AnnotationTopologyMode: "Auto"}
+	}
+	if err := r.reconcileV1Service1(ctx, cluster, cluster.Spec.ReplicaServiceTemplate, cluster.ReplicaServiceName(), false, replicaSelector, replicaAnnotations); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluster *mocov1beta2.MySQLCluster, template *mocov1beta2.ServiceTemplate, name string, headless bool, selector map[string]string) error {
+// publishNotReadyAddresses returns false while the cluster is being restored
+// if `spec.restore.unpublishNotReadyAddresses` is true, and true otherwise.
+func publishNotReadyAddresses(cluster *mocov1beta2.MySQLCluster) bool {
+	restore := cluster.Spec.Restore
+	if restore == nil || !restore.UnpublishNotReadyAddresses {
+		return true
+	}
+	return cluster.Status.RestoredTime != nil
+}
+
+// reconcileV1Service1 reconciles a Service of the cluster.
+// annotations are added to the Service in preference to those in the template.
+func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluster *mocov1beta2.MySQLCluster, template *mocov1beta2.ServiceTemplate, name string, headless bool, selector map[string]string, annotations map[string]string) error {
 	log := crlog.FromContext(ctx)
 
 	svc := corev1ac.Service(name, cluster.Namespace).WithSpec(corev1ac.ServiceSpec())
@@ -627,6 +914,8 @@ func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluste
 			WithLabels(tmpl.Labels).
 			WithLabels(labelSet(cluster, false))
 
+		// The template spec, including fields such as loadBalancerClass, is applied as is.
+		// Only the selector and the ports are overwritten below.
 		if tmpl.Spec != nil {
 			s := (*corev1ac.ServiceSpecApplyConfiguration)(tmpl.Spec)
 			svc.WithSpec(s)
@@ -635,10 +924,14 @@ func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluste
 		svc.WithLabels(labelSet(cluster, false))
 	}
 
+	if len(annotations) > 0 {
+		svc.WithAnnotations(annotations)
+	}
+
 	if headless {
 		svc.Spec.WithClusterIP(corev1.ClusterIPNone).
 			WithType(corev1.ServiceTypeClusterIP).
-			WithPublishNotReadyAddresses(true)
+			WithPublishNotReadyAddresses(publishNotReadyAddresses(cluster))
 	}
 
 	svc.Spec.WithSelector(selector)
@@ -664,6 +957,9 @@ func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluste
 				WithPort(constants.MySQLAdminPort).
 				WithTargetPort(intstr.FromString(constants.MySQLAdminPortName)),
 		)
+		for _, port := range cluster.Spec.HeadlessServicePorts {
+			svc.Spec.WithPorts((*corev1ac.ServicePortApplyConfiguration)(port.DeepCopy()))
+		}
 	}
 
 	if err := setControllerReferenceWithService(cluster, svc, r.Scheme); err != nil {
@@ -699,16 +995,31 @@ func (r *MySQLClusterReconciler) reconcileV1Service1(ctx context.Context, cluste
 func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster, mycnf *corev1ac.ConfigMapApplyConfiguration) error {
 	log := crlog.FromContext(ctx)
 
+	// Clusters created before the webhook started to reject host namespaces may still have them.
+	// Keep the current StatefulSet as is rather than failing the whole reconciliation.
+	if hostNamespaces := cluster.HostNamespaces(); len(hostNamespaces) > 0 && !cluster.HostNamespacesAllowed() {
+		log.Info("refuse to apply StatefulSet sharing the host namespaces", "fields", hostNamespaces)
+		r.eventTracker.emitForGeneration(cluster, r.Recorder, "HostNamespaces", event.HostNamespacesRejected, strings.Join(hostNamespaces, ", "), constants.AnnAllowHostNamespaces)
+		return nil
+	}
+	r.eventTracker.clear(cluster, "HostNamespaces")
+
 	var orig appsv1.StatefulSet
 	err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: cluster.PrefixedName()}, &orig)
 	if err != nil && !apierrors.IsNotFound(err) {
 		return fmt.Errorf("failed to get StatefulSet %s/%s: %w", cluster.Namespace, cluster.PrefixedName(), err)
 	}
 
+	revisionHistoryLimit := int32(defaultRevisionHistoryLimit)
+	if cluster.Spec.RevisionHistoryLimit != nil {
+		revisionHistoryLimit = *cluster.Spec.RevisionHistoryLimit
+	}
+
 	sts := appsv1ac.StatefulSet(cluster.PrefixedName(), cluster.Namespace).
 		WithLabels(labelSet(cluster, false)).
 		WithSpec(appsv1ac.StatefulSetSpec().
 			WithReplicas(cluster.Spec.Replicas).
+			WithRevisionHistoryLimit(revisionHistoryLimit).
 			WithSelector(metav1ac.LabelSelector().
 				WithMatchLabels(labelSet(cluster, false))).
 			WithPodManagementPolicy(appsv1.ParallelPodManagement).
@@ -716,6 +1027,27 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 				WithType(appsv1.RollingUpdateStatefulSetStrategyType)).
 			WithServiceName(cluster.HeadlessServiceName()))
 
+	if cluster.Spec.MinReadySeconds > 0 {
+		sts.Spec.WithMinReadySeconds(cluster.Spec.MinReadySeconds)
+	}
+
+	if start := cluster.Spec.OrdinalStart(); start > 0 {
+		if r.StatefulSetOrdinals {
+			sts.Spec.WithOrdinals(appsv1ac.StatefulSetOrdinals().WithStart(int32(start)))
+		} else {
+			log.Info("spec.ordinals.start is ignored because StatefulSet ordinals are not supported", "start", start)
+			event.OrdinalsUnsupported.Emit(cluster, r.Recorder)
+		}
+	}
+
+	retentionPolicy := r.pvcRetentionPolicy(cluster)
+	if retentionPolicy != nil {
+		sts.Spec.WithPersistentVolumeClaimRetentionPolicy(retentionPolicy)
+	} else if cluster.Spec.PersistentVolumeClaimRetentionPolicy != nil {
+		log.Info("spec.persistentVolumeClaimRetentionPolicy is ignored because it is not supported")
+		event.PVCRetentionPolicyUnsupported.Emit(cluster, r.Recorder)
+	}
+
 	volumeClaimTemplates := make([]*corev1ac.PersistentVolumeClaimApplyConfiguration, 0, len(cluster.Spec.VolumeClaimTemplates))
 	for _, v := range cluster.Spec.VolumeClaimTemplates {
 		pvc := v.ToCoreV1()
@@ -728,8 +1060,17 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 			}
 		}
 
-		if err := setControllerReferenceWithPVC(cluster, pvc, origPVC, r.Scheme); err != nil {
-			return fmt.Errorf("failed to set ownerReference to PVC %s/%s: %w", cluster.Namespace, *pvc.Name, err)
+		// Volume claim templates cannot be updated without recreating the StatefulSet.
+		// Add the labels of MOCO only to new templates and ones that already have them
+		// so that upgrading MOCO does not recreate StatefulSets of existing clusters.
+		if origPVC == nil || origPVC.Labels[constants.LabelAppCreatedBy] == constants.AppCreator {
+			pvc.WithLabels(labelSet(cluster, false))
+		}
+
+		if pvcOwnedByCluster(cluster, origPVC, retentionPolicy != nil) {
+			if err := setControllerReferenceWithPVC(cluster, pvc, origPVC, r.Scheme); err != nil {
+				return fmt.Errorf("failed to set ownerReference to PVC %s/%s: %w", cluster.Namespace, *pvc.Name, err)
+			}
 		}
 
 		volumeClaimTemplates = append(volumeClaimTemplates, pvc)
@@ -740,16 +1081,55 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 		WithAnnotations(cluster.Spec.PodTemplate.Annotations).
 		WithLabels(cluster.Spec.PodTemplate.Labels).
 		WithLabels(labelSet(cluster, false)))
+	if h := slowQueryLogOutputHash(cluster); h != "" {
+		sts.Spec.Template.WithAnnotations(map[string]string{
+			constants.AnnSlowQueryLogOutput: h,
+		})
+	}
+	instanceResources, err := instanceResourcesHash(cluster)
+	if err != nil {
+		return err
+	}
+	if instanceResources != "" {
+		sts.Spec.Template.WithAnnotations(map[string]string{
+			constants.AnnInstanceResources: instanceResources,
+		})
+	}
+	passwordRev, err := r.passwordRevision(ctx, cluster)
+	if err != nil {
+		return err
+	}
+	if passwordRev != "" {
+		sts.Spec.Template.WithAnnotations(map[string]string{
+			constants.AnnPasswordRevision: passwordRev,
+		})
+	}
 
 	podSpec := corev1ac.PodSpecApplyConfiguration(*cluster.Spec.PodTemplate.Spec.DeepCopy())
 	podSpec.WithServiceAccountName(cluster.PrefixedName())
 
+	if mycnf.Name == nil {
+		return errors.New("unexpected error: my.conf ConfigMap name is nil")
+	}
+
 	if podSpec.TerminationGracePeriodSeconds == nil {
-		podSpec.WithTerminationGracePeriodSeconds(defaultTerminationGracePeriodSeconds)
+		gracePeriod := r.podTerminationGracePeriodSeconds(mycnf.Data[constants.MySQLConfName])
+		// Keep the time for mysqld to shut down even if the preStop hook waits longer than the default.
+		if extra := int64(replicaDrainSeconds(cluster)) - constants.ReplicaDrainSeconds; extra > 0 {
+			gracePeriod += extra
+		}
+		podSpec.WithTerminationGracePeriodSeconds(gracePeriod)
 	}
 
-	if mycnf.Name == nil {
-		return errors.New("unexpected error: my.conf ConfigMap name is nil")
+	if r.ReloaderAnnotations {
+		sts.WithAnnotations(map[string]string{
+			constants.AnnReloaderConfigMaps: *mycnf.Name,
+		})
+		if !cluster.Spec.DisableMyCnfSecretVolume {
+			sts.WithAnnotations(map[string]string{
+				constants.AnnReloaderSecrets: cluster.MyCnfSecretName(),
+			})
+		}
 	}
 
 	podSpec.WithVolumes(
@@ -774,11 +1154,18 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 			WithName(constants.MySQLConfVolumeName).
 			WithConfigMap(corev1ac.ConfigMapVolumeSource().
 				WithName(*mycnf.Name).WithDefaultMode(0644)),
-		corev1ac.Volume().
-			WithName(constants.MySQLConfSecretVolumeName).
-			WithSecret(corev1ac.SecretVolumeSource().
-				WithSecretName(cluster.MyCnfSecretName()).
-				WithDefaultMode(0644)),
+	)
+	// Keep the order of the volumes so that existing StatefulSets are not updated.
+	if !cluster.Spec.DisableMyCnfSecretVolume {
+		podSpec.WithVolumes(
+			corev1ac.Volume().
+				WithName(constants.MySQLConfSecretVolumeName).
+				WithSecret(corev1ac.SecretVolumeSource().
+					WithSecretName(cluster.MyCnfSecretName()).
+					WithDefaultMode(0644)),
+		)
+	}
+	podSpec.WithVolumes(
 		corev1ac.Volume().
 			WithName(constants.GRPCSecretVolumeName).
 			WithSecret(corev1ac.SecretVolumeSource().
@@ -796,6 +1183,47 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 		)
 	}
 
+	if cluster.Spec.EnableAuditLogContainer {
+		podSpec.WithVolumes(
+			corev1ac.Volume().
+				WithName(constants.AuditLogAgentConfigVolumeName).
+				WithConfigMap(corev1ac.ConfigMapVolumeSource().
+					WithName(cluster.AuditLogAgentConfigMapName()).
+					WithDefaultMode(0644)),
+		)
+	}
+
+	// Only the agent container needs to access the Kubernetes API.
+	// Project the token into it in the same way as the kube-api-access volume of kubelet.
+	if cluster.Spec.AgentOnlyServiceAccountToken {
+		podSpec.WithAutomountServiceAccountToken(false)
+		podSpec.WithVolumes(
+			corev1ac.Volume().
+				WithName(constants.ServiceAccountTokenVolumeName).
+				WithProjected(corev1ac.ProjectedVolumeSource().
+					WithSources(
+						corev1ac.VolumeProjection().
+							WithServiceAccountToken(corev1ac.ServiceAccountTokenProjection().
+								WithPath("token").
+								WithExpirationSeconds(serviceAccountTokenExpirationSeconds)),
+						corev1ac.VolumeProjection().
+							WithConfigMap(corev1ac.ConfigMapProjection().
+								WithName("kube-root-ca.crt").
+								WithItems(corev1ac.KeyToPath().
+									WithKey("ca.crt").
+									WithPath("ca.crt"))),
+						corev1ac.VolumeProjection().
+							WithDownwardAPI(corev1ac.DownwardAPIProjection().
+								WithItems(corev1ac.DownwardAPIVolumeFile().
+									WithPath("namespace").
+									WithFieldRef(corev1ac.ObjectFieldSelector().
+										WithAPIVersion("v1").
+										WithFieldPath("metadata.namespace")))),
+					).
+					WithDefaultMode(0644)),
+		)
+	}
+
 	containers := make([]*corev1ac.ContainerApplyConfiguration, 0, 4)
 
 	mysqldContainer, err := r.makeV1MySQLDContainer(cluster)
@@ -805,16 +1233,33 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 	containers = append(containers, mysqldContainer)
 	containers = append(containers, r.makeV1AgentContainer(cluster))
 
+	// fluent-bit sidecar containers are kept as they are until the cluster is edited
+	// so that upgrading MOCO does not restart mysqld only for a new fluent-bit image.
+	force := cluster.Status.ReconcileInfo.Generation != cluster.Generation
+	current, err := appsv1ac.ExtractStatefulSet(&orig, fieldManager)
+	if err != nil {
+		return fmt.Errorf("failed to extract StatefulSet: %w", err)
+	}
+
 	if !cluster.Spec.DisableSlowQueryLogContainer {
-		force := cluster.Status.ReconcileInfo.Generation != cluster.Generation
-		sts, err := appsv1ac.ExtractStatefulSet(&orig, fieldManager)
-		if err != nil {
-			return fmt.Errorf("failed to extract StatefulSet: %w", err)
+		containers = append(containers, r.makeV1SlowQueryLogContainer(cluster, current, force))
+
+		// The Pod has to live long enough for the slow-log container to sleep and flush.
+		minGracePeriod := int64(slowQueryLogAgentPreStopSeconds(cluster)) + constants.SlowQueryLogAgentGraceSeconds
+		if *podSpec.TerminationGracePeriodSeconds < minGracePeriod {
+			podSpec.WithTerminationGracePeriodSeconds(minGracePeriod)
 		}
+	}
+	if cluster.Spec.EnableAuditLogContainer {
+		containers = append(containers, r.makeV1AuditLogContainer(cluster, current, force))
 
-		containers = append(containers, r.makeV1SlowQueryLogContainer(cluster, sts, force))
+		// The Pod has to live long enough for the audit-log container to sleep and flush.
+		minGracePeriod := int64(constants.AuditLogAgentPreStopSeconds) + constants.AuditLogAgentGraceSeconds
+		if *podSpec.TerminationGracePeriodSeconds < minGracePeriod {
+			podSpec.WithTerminationGracePeriodSeconds(minGracePeriod)
+		}
 	}
-	if len(cluster.Spec.Collectors) > 0 {
+	if cluster.Spec.ExporterSidecarEnabled() {
 		containers = append(containers, r.makeV1ExporterContainer(cluster, cluster.Spec.Collectors))
 	}
 	containers = append(containers, r.makeV1OptionalContainers(cluster)...)
@@ -832,6 +1277,10 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 	podSpec.WithContainers(containers...)
 	podSpec.WithInitContainers(initContainers...)
 
+	// Service links flood the environment of mysqld in namespaces with many Services.
+	if podSpec.EnableServiceLinks == nil {
+		podSpec.WithEnableServiceLinks(false)
+	}
 	if podSpec.SecurityContext == nil {
 		podSpec.WithSecurityContext(corev1ac.PodSecurityContext())
 	}
@@ -841,7 +1290,10 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 	if podSpec.SecurityContext.FSGroupChangePolicy == nil {
 		podSpec.SecurityContext.WithFSGroupChangePolicy(corev1.FSGroupChangeOnRootMismatch)
 	}
-	if podSpec.Affinity == nil {
+	if podSpec.DNSConfig == nil && len(r.MySQLDNSOptions) > 0 {
+		podSpec.WithDNSConfig(mysqlDNSConfig(r.MySQLDNSOptions))
+	}
+	if podSpec.Affinity == nil && !r.DisableDefaultAntiAffinity {
 		podSpec.WithAffinity(corev1ac.Affinity().
 			WithPodAntiAffinity(corev1ac.PodAntiAffinity().
 				WithPreferredDuringSchedulingIgnoredDuringExecution(corev1ac.WeightedPodAffinityTerm().
@@ -876,6 +1328,16 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 
 	sts.Spec.Template.WithSpec(&podSpec)
 
+	origApplyConfig, err := appsv1ac.ExtractStatefulSet(&orig, fieldManager)
+	if err != nil {
+		return fmt.Errorf("failed to extract StatefulSet %s/%s: %w", cluster.Namespace, cluster.PrefixedName(), err)
+	}
+
+	if cluster.Spec.CanaryUpgrade {
+		sts.Spec.UpdateStrategy.WithRollingUpdate(appsv1ac.RollingUpdateStatefulSetStrategy().
+			WithPartition(r.canaryPartition(ctx, cluster, &orig, origApplyConfig, sts)))
+	}
+
 	if err := setControllerReferenceWithStatefulSet(cluster, sts, r.Scheme); err != nil {
 		return fmt.Errorf("failed to set ownerReference to StatefulSet %s/%s: %w", cluster.Namespace, cluster.PrefixedName(), err)
 	}
@@ -888,15 +1350,12 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 		Object: obj,
 	}
 
-	origApplyConfig, err := appsv1ac.ExtractStatefulSet(&orig, fieldManager)
-	if err != nil {
-		return fmt.Errorf("failed to extract StatefulSet %s/%s: %w", cluster.Namespace, cluster.PrefixedName(), err)
-	}
-
 	if equality.Semantic.DeepEqual(sts, origApplyConfig) {
 		return nil
 	}
 
+	r.checkResourceQuota(ctx, cluster, &orig, &podSpec)
+
 	needRecreate := false
 
 	// Recreate StatefulSet if VolumeClaimTemplates has differences.
@@ -934,6 +1393,11 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 		}
 	}
 
+	// The volume claim templates take effect only when the StatefulSet is created or recreated.
+	if orig.ResourceVersion == "" || needRecreate {
+		r.checkVolumeAccessModes(ctx, cluster)
+	}
+
 	err = r.Patch(ctx, patch, client.Apply, &client.PatchOptions{
 		FieldManager: fieldManager,
 		Force:        ptr.To[bool](true),
@@ -965,6 +1429,27 @@ func (r *MySQLClusterReconciler) reconcileV1StatefulSet(ctx context.Context, req
 	return nil
 }
 
+// mysqlDNSConfig returns the dnsConfig of MySQL Pods with the given resolver options.
+// An option with an empty value, such as use-vc, is added without a value.
+// The options are sorted by name so that the StatefulSet does not change on every reconciliation.
+func mysqlDNSConfig(options map[string]string) *corev1ac.PodDNSConfigApplyConfiguration {
+	names := make([]string, 0, len(options))
+	for name := range options {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	dnsConfig := corev1ac.PodDNSConfig()
+	for _, name := range names {
+		opt := corev1ac.PodDNSConfigOption().WithName(name)
+		if v := options[name]; v != "" {
+			opt.WithValue(v)
+		}
+		dnsConfig.WithOptions(opt)
+	}
+	return dnsConfig
+}
+
 func (r *MySQLClusterReconciler) reconcileV1PDB(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
 	log := crlog.FromContext(ctx)
 
@@ -972,7 +1457,23 @@ func (r *MySQLClusterReconciler) reconcileV1PDB(ctx context.Context, req ctrl.Re
 	pdb.Namespace = cluster.Namespace
 	pdb.Name = cluster.PrefixedName()
 
-	if cluster.Spec.Replicas < 3 {
+	if cluster.Spec.DisablePodDisruptionBudget {
+		// The PDB may be managed by someone else; delete it only if MOCO created it.
+		if err := r.Get(ctx, client.ObjectKeyFromObject(pdb), pdb); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		if !metav1.IsControlledBy(pdb, cluster) {
+			return nil
+		}
+		err := r.Delete(ctx, pdb)
+		if err == nil {
+			log.Info("removed pod disruption budget")
+		}
+		return client.IgnoreNotFound(err)
+	}
+
+	needPDB := cluster.Spec.Replicas >= 3 || (r.PDBForTwoReplicas && cluster.Spec.Replicas == 2)
+	if !needPDB {
 		err := r.Delete(ctx, pdb)
 		if err == nil {
 			log.Info("removed pod disruption budget")
@@ -992,9 +1493,16 @@ func (r *MySQLClusterReconciler) reconcileV1PDB(ctx context.Context, req ctrl.Re
 	}
 
 	var maxUnavailable intstr.IntOrString
-	if backupCronJobIsRunning {
+	switch {
+	case backupCronJobIsRunning:
 		maxUnavailable = intstr.FromInt(0)
-	} else {
+	case cluster.Spec.ZoneAwarePodDisruptionBudget:
+		zones, err := r.podsPerZone(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		maxUnavailable = intstr.FromInt(zoneMaxUnavailable(cluster.Spec.Replicas, zones))
+	default:
 		maxUnavailable = intstr.FromInt(int(cluster.Spec.Replicas / 2))
 	}
 
@@ -1037,6 +1545,54 @@ func (r *MySQLClusterReconciler) reconcileV1PDB(ctx context.Context, req ctrl.Re
 	return nil
 }
 
+// podsPerZone returns the number of MySQL Pods of the cluster in each zone.
+// It returns nil if the zone of any Pod is unknown, e.g. the Pod is not scheduled yet.
+func (r *MySQLClusterReconciler) podsPerZone(ctx context.Context, cluster *mocov1beta2.MySQLCluster) (map[string]int, error) {
+	pods := &corev1.PodList{}
+	if err := r.List(ctx, pods, client.InNamespace(cluster.Namespace), client.MatchingLabels(labelSet(cluster, false))); err != nil {
+		return nil, fmt.Errorf("failed to list Pods: %w", err)
+	}
+	if len(pods.Items) != int(cluster.Spec.Replicas) {
+		return nil, nil
+	}
+
+	zones := make(map[string]int)
+	for _, pod := range pods.Items {
+		if pod.Spec.NodeName == "" {
+			return nil, nil
+		}
+		node := &corev1.Node{}
+		if err := r.Get(ctx, client.ObjectKey{Name: pod.Spec.NodeName}, node); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("failed to get Node %s: %w", pod.Spec.NodeName, err)
+		}
+		zone := node.Labels[corev1.LabelTopologyZone]
+		if zone == "" {
+			return nil, nil
+		}
+		zones[zone]++
+	}
+	return zones, nil
+}
+
+// zoneMaxUnavailable returns `maxUnavailable` of the PodDisruptionBudget that allows evicting
+// all the Pods in the largest zone, as long as the majority of the instances keeps running.
+// `zones` is the number of the Pods in each zone.  If it is empty, the half of the replicas is returned.
+func zoneMaxUnavailable(replicas int32, zones map[string]int) int {
+	limit := int(replicas / 2)
+	if len(zones) == 0 {
+		return limit
+	}
+
+	var largest int
+	for _, n := range zones {
+		largest = max(largest, n)
+	}
+	return min(largest, limit)
+}
+
 func bucketArgs(bc mocov1beta2.BucketConfig) []string {
 	var args []string
 	if bc.Region != "" {
@@ -1104,8 +1660,26 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 	}
 
 	jc := &bp.Spec.JobConfig
+	if ok, err := r.checkJobCABundle(ctx, cluster, jc, "backup"); err != nil || !ok {
+		return err
+	}
 
 	args := []string{constants.BackupSubcommand, fmt.Sprintf("--threads=%d", jc.Threads)}
+	if jc.WorkDir() != constants.DefaultJobWorkVolumeMount {
+		args = append(args, "--work-dir="+jc.WorkDir())
+	}
+	if len(jc.IncludeDatabases) > 0 {
+		args = append(args, "--include-databases="+strings.Join(jc.IncludeDatabases, ","))
+	}
+	if len(jc.ExcludeDatabases) > 0 {
+		args = append(args, "--exclude-databases="+strings.Join(jc.ExcludeDatabases, ","))
+	}
+	if jc.PartSize != nil {
+		args = append(args, fmt.Sprintf("--part-size=%d", jc.PartSize.Value()))
+	}
+	if jc.CABundle != nil {
+		args = append(args, "--ca-cert="+jobCABundlePath())
+	}
 	args = append(args, bucketArgs(jc.BucketConfig)...)
 	args = append(args, cluster.Namespace, cluster.Name)
 
@@ -1163,8 +1737,8 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 			return envFrom
 		}()...).
 		WithVolumeMounts(corev1ac.VolumeMount().
-			WithName("work").
-			WithMountPath("/work"),
+			WithName(constants.JobWorkVolumeName).
+			WithMountPath(jc.WorkDir()),
 		).
 		WithVolumeMounts(func() []*corev1ac.VolumeMountApplyConfiguration {
 			volumeMounts := make([]*corev1ac.VolumeMountApplyConfiguration, 0, len(jc.VolumeMounts))
@@ -1178,6 +1752,9 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 		WithResources(resources)
 
 	updateContainerWithSecurityContext(container)
+	if jc.CABundle != nil {
+		updateContainerWithCABundle(container)
+	}
 
 	cronJobName := cluster.BackupCronJobName()
 	cronJob := batchv1ac.CronJob(cronJobName, cluster.Namespace).
@@ -1185,6 +1762,7 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 		WithSpec(batchv1ac.CronJobSpec().
 			WithSchedule(bp.Spec.Schedule).
 			WithConcurrencyPolicy(bp.Spec.ConcurrencyPolicy).
+			WithSuspend(bp.Spec.Suspend).
 			WithJobTemplate(batchv1ac.JobTemplateSpec().
 				WithLabels(labelSetForJob(cluster)).
 				WithSpec(batchv1ac.JobSpec().
@@ -1195,7 +1773,7 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 							WithRestartPolicy(corev1.RestartPolicyNever).
 							WithServiceAccountName(bp.Spec.JobConfig.ServiceAccountName).
 							WithVolumes(&corev1ac.VolumeApplyConfiguration{
-								Name:                           ptr.To[string]("work"),
+								Name:                           ptr.To[string](constants.JobWorkVolumeName),
 								VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration(*jc.WorkVolume.DeepCopy()),
 							}).
 							WithVolumes(func() []*corev1ac.VolumeApplyConfiguration {
@@ -1228,6 +1806,8 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 	}
 	if bp.Spec.ActiveDeadlineSeconds != nil {
 		cronJob.Spec.JobTemplate.Spec.WithActiveDeadlineSeconds(*bp.Spec.ActiveDeadlineSeconds)
+	} else {
+		cronJob.Spec.JobTemplate.Spec.WithActiveDeadlineSeconds(constants.DefaultBackupActiveDeadlineSeconds)
 	}
 	if bp.Spec.BackoffLimit != nil {
 		cronJob.Spec.JobTemplate.Spec.WithBackoffLimit(*bp.Spec.BackoffLimit)
@@ -1258,6 +1838,20 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 			),
 		)
 	}
+	addBackupAffinity(cronJob.Spec.JobTemplate.Spec.Template.Spec, cluster, bp.Spec.BackupAffinity)
+	if jc.SchedulerName != "" {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.WithSchedulerName(jc.SchedulerName)
+	}
+	if jc.RuntimeClassName != nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.WithRuntimeClassName(*jc.RuntimeClassName)
+	}
+	if jc.CABundle != nil {
+		cronJob.Spec.JobTemplate.Spec.Template.Spec.WithVolumes(jobCABundleVolume(jc))
+	}
+	cronJob.Spec.JobTemplate.Spec.Template.Spec.WithTerminationGracePeriodSeconds(jobTerminationGracePeriodSeconds(jc))
+	if r.PodFailurePolicy && len(jc.PodFailurePolicy) > 0 {
+		cronJob.Spec.JobTemplate.Spec.WithPodFailurePolicy(jobPodFailurePolicy(jc, "backup"))
+	}
 
 	if err := setControllerReferenceWithCronJob(cluster, cronJob, r.Scheme); err != nil {
 		return fmt.Errorf("failed to set ownerReference to CronJob %s/%s: %w", cluster.Namespace, cronJobName, err)
@@ -1286,7 +1880,7 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 
 	log.Info("reconciled CronJob for backup", "cronJobName", cronJobName)
 
-	if err := r.reconcileV1BackupJobRole(ctx, req, cluster); err != nil {
+	if err := r.reconcileV1BackupJobRole(ctx, req, cluster, bp); err != nil {
 		return err
 	}
 
@@ -1297,10 +1891,238 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJob(ctx context.Context, req c
 	return nil
 }
 
-func (r *MySQLClusterReconciler) reconcileV1BackupJobRole(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+// podTerminationGracePeriodSeconds returns the termination grace period of MySQL Pods
+// whose template does not specify one.
+//
+// If GracePeriodPerGiB is set, the period is proportional to `innodb_buffer_pool_size`
+// in `conf` because mysqld takes longer to flush a larger buffer pool.  It is not shorter than
+// the static default and is capped by MaxGracePeriod.
+func (r *MySQLClusterReconciler) podTerminationGracePeriodSeconds(conf string) int64 {
+	if r.GracePeriodPerGiB <= 0 {
+		return defaultTerminationGracePeriodSeconds
+	}
+
+	size, err := mycnf.InnoDBBufferPoolSize(conf)
+	if err != nil {
+		return defaultTerminationGracePeriodSeconds
+	}
+
+	seconds := int64(math.Ceil(float64(size) / (1 << 30) * r.GracePeriodPerGiB.Seconds()))
+	if seconds < defaultTerminationGracePeriodSeconds {
+		seconds = defaultTerminationGracePeriodSeconds
+	}
+	if limit := int64(r.MaxGracePeriod.Seconds()); limit > 0 && seconds > limit {
+		seconds = limit
+	}
+	return seconds
+}
+
+// jobTerminationGracePeriodSeconds returns the termination grace period of backup and restore Pods.
+func jobTerminationGracePeriodSeconds(jc *mocov1beta2.JobConfig) int64 {
+	if jc.TerminationGracePeriodSeconds != nil {
+		return *jc.TerminationGracePeriodSeconds
+	}
+	return defaultJobTerminationGracePeriodSeconds
+}
+
+// addBackupAffinity adds the preference for the nodes of the backup Pods to `podSpec`
+// according to `mode`, which is the value of BackupPolicySpec.BackupAffinity.
+func addBackupAffinity(podSpec *corev1ac.PodSpecApplyConfiguration, cluster *mocov1beta2.MySQLCluster, mode string) {
+	var role string
+	switch mode {
+	case mocov1beta2.BackupAffinityPreferReplica:
+		role = constants.RoleReplica
+	case mocov1beta2.BackupAffinityAvoidPrimary:
+		role = constants.RolePrimary
+	default:
+		return
+	}
+
+	selector := labelSet(cluster, false)
+	selector[cluster.RoleLabelKey()] = role
+	term := corev1ac.WeightedPodAffinityTerm().
+		WithWeight(100).
+		WithPodAffinityTerm(corev1ac.PodAffinityTerm().
+			WithLabelSelector(metav1ac.LabelSelector().WithMatchLabels(selector)).
+			WithTopologyKey(corev1.LabelHostname))
+
+	if podSpec.Affinity == nil {
+		podSpec.WithAffinity(corev1ac.Affinity())
+	}
+	affinity := podSpec.Affinity
+	if mode == mocov1beta2.BackupAffinityPreferReplica {
+		if affinity.PodAffinity == nil {
+			affinity.WithPodAffinity(corev1ac.PodAffinity())
+		}
+		affinity.PodAffinity.WithPreferredDuringSchedulingIgnoredDuringExecution(term)
+		return
+	}
+	if affinity.PodAntiAffinity == nil {
+		affinity.WithPodAntiAffinity(corev1ac.PodAntiAffinity())
+	}
+	affinity.PodAntiAffinity.WithPreferredDuringSchedulingIgnoredDuringExecution(term)
+}
+
+// jobPodFailurePolicy returns the pod failure policy of backup and restore Jobs.
+func jobPodFailurePolicy(jc *mocov1beta2.JobConfig, containerName string) *batchv1ac.PodFailurePolicyApplyConfiguration {
+	policy := batchv1ac.PodFailurePolicy()
+	for _, rule := range jc.PodFailurePolicy {
+		policy.WithRules(batchv1ac.PodFailurePolicyRule().
+			WithAction(batchv1.PodFailurePolicyAction(rule.Action)).
+			WithOnExitCodes(batchv1ac.PodFailurePolicyOnExitCodesRequirement().
+				WithContainerName(containerName).
+				WithOperator(batchv1.PodFailurePolicyOnExitCodesOpIn).
+				WithValues(rule.ExitCodes...)))
+	}
+	return policy
+}
+
+// checkJobCABundle returns false if the ConfigMap or Secret referenced by `jc.CABundle`
+// does not exist or does not have the key of the CA bundle.  In that case, a warning event
+// is recorded and the job for `purpose` should not be created until the object is fixed.
+// The object is watched, so the cluster is reconciled again when it is created or updated.
+func (r *MySQLClusterReconciler) checkJobCABundle(ctx context.Context, cluster *mocov1beta2.MySQLCluster, jc *mocov1beta2.JobConfig, purpose string) (bool, error) {
+	log := crlog.FromContext(ctx)
+
+	cb := jc.CABundle
+	if cb == nil {
+		return true, nil
+	}
+
+	key := cb.BundleKey()
+	kind, name := "Secret", cb.SecretName
+	var found bool
+	if cb.ConfigMapName != "" {
+		kind, name = "ConfigMap", cb.ConfigMapName
+		cm := &corev1.ConfigMap{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, cm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get ConfigMap %s/%s for the CA bundle: %w", cluster.Namespace, name, err)
+		}
+		_, found = cm.Data[key]
+	} else {
+		secret := &corev1.Secret{}
+		err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, secret)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get Secret %s/%s for the CA bundle: %w", cluster.Namespace, name, err)
+		}
+		_, found = secret.Data[key]
+	}
+
+	slot := "CABundle/" + purpose
+	if !found {
+		log.Info("the CA bundle is not found", "kind", kind, "name", name, "key", key, "purpose", purpose)
+		r.eventTracker.emit(cluster, r.Recorder, slot, event.CABundleNotFound, kind, name, key, purpose)
+	} else {
+		r.eventTracker.clear(cluster, slot)
+	}
+	return found, nil
+}
+
+// usesCABundle returns true if the backup or restore job of the cluster refers to obj,
+// a ConfigMap or Secret in the namespace of the cluster, for the CA bundle.
+func (r *MySQLClusterReconciler) usesCABundle(ctx context.Context, cluster *mocov1beta2.MySQLCluster, obj client.Object) bool {
+	refers := func(cb *mocov1beta2.CABundleSource) bool {
+		if cb == nil {
+			return false
+		}
+		switch obj.(type) {
+		case *corev1.ConfigMap:
+			return cb.ConfigMapName == obj.GetName()
+		case *corev1.Secret:
+			return cb.SecretName == obj.GetName()
+		}
+		return false
+	}
+
+	if cluster.Spec.Restore != nil && cluster.Status.RestoredTime == nil && refers(cluster.Spec.Restore.JobConfig.CABundle) {
+		return true
+	}
+	if cluster.Spec.BackupPolicyName == nil {
+		return false
+	}
+	bp := &mocov1beta2.BackupPolicy{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: *cluster.Spec.BackupPolicyName}, bp); err != nil {
+		return false
+	}
+	return refers(bp.Spec.JobConfig.CABundle)
+}
+
+// usesJobRole returns true if the backup or restore job of the cluster refers to obj,
+// a Role or ClusterRole, to be bound to its ServiceAccount.
+func (r *MySQLClusterReconciler) usesJobRole(ctx context.Context, cluster *mocov1beta2.MySQLCluster, obj client.Object) bool {
+	refers := func(ref *mocov1beta2.JobRoleRef) bool {
+		if ref == nil || ref.Name != obj.GetName() {
+			return false
+		}
+		switch obj.(type) {
+		case *rbacv1.Role:
+			return ref.Kind == "Role" && obj.GetNamespace() == cluster.Namespace
+		case *rbacv1.ClusterRole:
+			return ref.Kind == "ClusterRole"
+		}
+		return false
+	}
+
+	if cluster.Spec.Restore != nil && cluster.Status.RestoredTime == nil && refers(cluster.Spec.Restore.JobConfig.RoleRef) {
+		return true
+	}
+	if cluster.Spec.BackupPolicyName == nil {
+		return false
+	}
+	bp := &mocov1beta2.BackupPolicy{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: *cluster.Spec.BackupPolicyName}, bp); err != nil {
+		return false
+	}
+	return refers(bp.Spec.JobConfig.RoleRef)
+}
+
+// jobCABundlePath returns the path of the CA bundle in backup and restore containers.
+func jobCABundlePath() string {
+	return filepath.Join(constants.JobCABundleMountPath, constants.JobCABundleFilename)
+}
+
+// jobCABundleVolume returns the volume for the CA bundle referenced by `jc.CABundle`.
+func jobCABundleVolume(jc *mocov1beta2.JobConfig) *corev1ac.VolumeApplyConfiguration {
+	cb := jc.CABundle
+	item := corev1ac.KeyToPath().
+		WithKey(cb.BundleKey()).
+		WithPath(constants.JobCABundleFilename)
+
+	vol := corev1ac.Volume().WithName(constants.JobCABundleVolumeName)
+	if cb.ConfigMapName != "" {
+		return vol.WithConfigMap(corev1ac.ConfigMapVolumeSource().
+			WithName(cb.ConfigMapName).
+			WithItems(item))
+	}
+	return vol.WithSecret(corev1ac.SecretVolumeSource().
+		WithSecretName(cb.SecretName).
+		WithItems(item))
+}
+
+// updateContainerWithCABundle mounts the CA bundle in the container and sets
+// `AWS_CA_BUNDLE` environment variable to its path.
+func updateContainerWithCABundle(container *corev1ac.ContainerApplyConfiguration) {
+	container.
+		WithEnv(corev1ac.EnvVar().
+			WithName(constants.JobCABundleEnvName).
+			WithValue(jobCABundlePath()),
+		).
+		WithVolumeMounts(corev1ac.VolumeMount().
+			WithName(constants.JobCABundleVolumeName).
+			WithMountPath(constants.JobCABundleMountPath).
+			WithReadOnly(true),
+		)
+}
+
+func (r *MySQLClusterReconciler) reconcileV1BackupJobRole(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster, bp *mocov1beta2.BackupPolicy) error {
 	log := crlog.FromContext(ctx)
 
 	name := cluster.BackupRoleName()
+	if ref := bp.Spec.JobConfig.RoleRef; ref != nil {
+		return r.reconcileV1JobRoleRef(ctx, cluster, ref, name, "backup")
+	}
+
 	role := rbacv1ac.Role(name, cluster.Namespace).
 		WithLabels(labelSetForJob(cluster)).
 		WithRules(
@@ -1353,12 +2175,17 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJobRoleBinding(ctx context.Con
 	log := crlog.FromContext(ctx)
 
 	name := cluster.BackupRoleName()
+	roleKind, roleName := jobRoleRef(bp.Spec.JobConfig.RoleRef, name)
+	if err := r.deleteRoleBindingIfRoleRefChanged(ctx, cluster, name, roleKind, roleName); err != nil {
+		return err
+	}
+
 	roleBinding := rbacv1ac.RoleBinding(name, cluster.Namespace).
 		WithLabels(labelSetForJob(cluster)).
 		WithRoleRef(rbacv1ac.RoleRef().
 			WithAPIGroup(rbacv1.SchemeGroupVersion.Group).
-			WithKind("Role").
-			WithName(cluster.BackupRoleName())).
+			WithKind(roleKind).
+			WithName(roleName)).
 		WithSubjects(rbacv1ac.Subject().
 			WithKind("ServiceAccount").
 			WithName(bp.Spec.JobConfig.ServiceAccountName).
@@ -1394,6 +2221,90 @@ func (r *MySQLClusterReconciler) reconcileV1BackupJobRoleBinding(ctx context.Con
 	return nil
 }
 
+// jobRoleRef returns the kind and name of the role to be bound to the ServiceAccount of
+// backup or restore jobs.  If `ref` is nil, the Role named `defaultName` that MOCO creates is used.
+func jobRoleRef(ref *mocov1beta2.JobRoleRef, defaultName string) (string, string) {
+	if ref == nil {
+		return "Role", defaultName
+	}
+	return ref.Kind, ref.Name
+}
+
+// errJobRoleUnusable is returned when the role referenced for backup or restore jobs
+// is missing or not allowed.  The RoleBinding for the job is not created until it is fixed.
+var errJobRoleUnusable = errors.New("the role referenced for the job is unusable")
+
+// reconcileV1JobRoleRef checks that the Role or ClusterRole referenced by `ref` exists and is
+// labeled with constants.LabelJobRole, and deletes the Role named `name` that MOCO created for
+// the cluster as it is no longer used.  It returns errJobRoleUnusable if the check fails.
+//
+// The label is an allow-list of the roles that users of MySQLCluster and BackupPolicy may bind.
+// Moreover, moco-controller is not granted the `bind` verb, so the API server refuses to bind
+// a role granting permissions that moco-controller does not have.
+func (r *MySQLClusterReconciler) reconcileV1JobRoleRef(ctx context.Context, cluster *mocov1beta2.MySQLCluster, ref *mocov1beta2.JobRoleRef, name, purpose string) error {
+	log := crlog.FromContext(ctx)
+
+	var role client.Object = &rbacv1.Role{}
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: ref.Name}
+	if ref.Kind == "ClusterRole" {
+		role = &rbacv1.ClusterRole{}
+		key.Namespace = ""
+	}
+	slot := "JobRole/" + purpose
+	err := r.Get(ctx, key, role)
+	if apierrors.IsNotFound(err) {
+		r.eventTracker.emit(cluster, r.Recorder, slot, event.JobRoleNotFound, ref.Kind, ref.Name, purpose)
+		return fmt.Errorf("%w: %s %s referenced for the %s job is not found", errJobRoleUnusable, ref.Kind, ref.Name, purpose)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get %s %s referenced for jobs: %w", ref.Kind, ref.Name, err)
+	}
+	if role.GetLabels()[constants.LabelJobRole] != "true" {
+		r.eventTracker.emit(cluster, r.Recorder, slot, event.JobRoleNotAllowed, ref.Kind, ref.Name, constants.LabelJobRole)
+		return fmt.Errorf("%w: %s %s referenced for the %s job is not labeled with %s=true", errJobRoleUnusable, ref.Kind, ref.Name, purpose, constants.LabelJobRole)
+	}
+	r.eventTracker.clear(cluster, slot)
+
+	own := &rbacv1.Role{}
+	err = r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, own)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get Role %s/%s: %w", cluster.Namespace, name, err)
+	}
+	if !metav1.IsControlledBy(own, cluster) {
+		return nil
+	}
+	if err := r.Delete(ctx, own); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete Role %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("deleted Role replaced by the referenced role", "roleName", name, "roleRef", ref.Kind+"/"+ref.Name)
+	return nil
+}
+
+// deleteRoleBindingIfRoleRefChanged deletes the RoleBinding named `name` if it refers to a role
+// other than the specified one.  This is necessary because `roleRef` of a RoleBinding is immutable.
+func (r *MySQLClusterReconciler) deleteRoleBindingIfRoleRefChanged(ctx context.Context, cluster *mocov1beta2.MySQLCluster, name, roleKind, roleName string) error {
+	rb := &rbacv1.RoleBinding{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, rb)
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get RoleBinding %s/%s: %w", cluster.Namespace, name, err)
+	}
+	if rb.RoleRef.Kind == roleKind && rb.RoleRef.Name == roleName {
+		return nil
+	}
+
+	if err := r.Delete(ctx, rb); err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to delete RoleBinding %s/%s: %w", cluster.Namespace, name, err)
+	}
+	return nil
+}
+
 func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
 	// `spec.restore` is not editable, so we can safely return early if it is nil.
 	if cluster.Spec.Restore == nil {
@@ -1414,13 +2325,31 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 		}
 
 		jc := &cluster.Spec.Restore.JobConfig
+		if ok, err := r.checkJobCABundle(ctx, cluster, jc, "restore"); err != nil || !ok {
+			return err
+		}
 
 		args := []string{constants.RestoreSubcommand, fmt.Sprintf("--threads=%d", jc.Threads)}
+		if jc.WorkDir() != constants.DefaultJobWorkVolumeMount {
+			args = append(args, "--work-dir="+jc.WorkDir())
+		}
+		if cluster.Spec.Restore.Prefix != "" {
+			args = append(args, "--prefix="+cluster.Spec.Restore.Prefix)
+		}
+		if jc.CABundle != nil {
+			args = append(args, "--ca-cert="+jobCABundlePath())
+		}
 		args = append(args, bucketArgs(jc.BucketConfig)...)
 		args = append(args, cluster.Spec.Restore.SourceNamespace, cluster.Spec.Restore.SourceName)
 		args = append(args, cluster.Namespace, cluster.Name)
 		args = append(args, cluster.Spec.Restore.RestorePoint.UTC().Format(constants.BackupTimeFormat))
 
+		image := r.BackupImage
+		if jc.HasCustomContainer() {
+			image = jc.Image
+			args = jc.Args
+		}
+
 		resources := corev1ac.ResourceRequirements()
 		if !noJobResource {
 			request := corev1.ResourceList{}
@@ -1447,7 +2376,8 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 
 		container := corev1ac.Container().
 			WithName("restore").
-			WithImage(r.BackupImage).
+			WithImage(image).
+			WithCommand(jc.Command...).
 			WithArgs(args...).
 			WithEnv(corev1ac.EnvVar().
 				WithName("MYSQL_PASSWORD").
@@ -1475,8 +2405,8 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 				return envFrom
 			}()...).
 			WithVolumeMounts(corev1ac.VolumeMount().
-				WithName("work").
-				WithMountPath("/work")).
+				WithName(constants.JobWorkVolumeName).
+				WithMountPath(jc.WorkDir())).
 			WithVolumeMounts(func() []*corev1ac.VolumeMountApplyConfiguration {
 				volumeMounts := make([]*corev1ac.VolumeMountApplyConfiguration, 0, len(jc.VolumeMounts))
 				for _, v := range jc.VolumeMounts {
@@ -1487,19 +2417,26 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 			}()...).
 			WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true)).
 			WithResources(resources)
+		if jc.CABundle != nil {
+			updateContainerWithCABundle(container)
+		}
 
 		jobName := cluster.RestoreJobName()
+		jobSpec := batchv1ac.JobSpec().
+			WithBackoffLimit(0)
+		if cluster.Spec.Restore.ActiveDeadlineSeconds != nil {
+			jobSpec.WithActiveDeadlineSeconds(*cluster.Spec.Restore.ActiveDeadlineSeconds)
+		}
 		job := batchv1ac.Job(jobName, cluster.Namespace).
 			WithLabels(labelSetForJob(cluster)).
-			WithSpec(batchv1ac.JobSpec().
-				WithBackoffLimit(0).
+			WithSpec(jobSpec.
 				WithTemplate(corev1ac.PodTemplateSpec().
 					WithLabels(labelSetForJob(cluster)).
 					WithSpec(corev1ac.PodSpec().
 						WithRestartPolicy(corev1.RestartPolicyNever).
 						WithServiceAccountName(cluster.Spec.Restore.JobConfig.ServiceAccountName).
 						WithVolumes(&corev1ac.VolumeApplyConfiguration{
-							Name:                           ptr.To[string]("work"),
+							Name:                           ptr.To[string](constants.JobWorkVolumeName),
 							VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration(*cluster.Spec.Restore.JobConfig.WorkVolume.DeepCopy()),
 						}).
 						WithVolumes(func() []*corev1ac.VolumeApplyConfiguration {
@@ -1518,6 +2455,19 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 					),
 				),
 			)
+		if jc.SchedulerName != "" {
+			job.Spec.Template.Spec.WithSchedulerName(jc.SchedulerName)
+		}
+		if jc.RuntimeClassName != nil {
+			job.Spec.Template.Spec.WithRuntimeClassName(*jc.RuntimeClassName)
+		}
+		if jc.CABundle != nil {
+			job.Spec.Template.Spec.WithVolumes(jobCABundleVolume(jc))
+		}
+		job.Spec.Template.Spec.WithTerminationGracePeriodSeconds(jobTerminationGracePeriodSeconds(jc))
+		if r.PodFailurePolicy && len(jc.PodFailurePolicy) > 0 {
+			job.Spec.WithPodFailurePolicy(jobPodFailurePolicy(jc, "restore"))
+		}
 
 		if err := setControllerReferenceWithJob(cluster, job, r.Scheme); err != nil {
 			return fmt.Errorf("failed to set ownerReference to Job %s/%s: %w", cluster.Namespace, jobName, err)
@@ -1545,6 +2495,19 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 		}
 
 		log.Info("reconciled Job for restore", "jobName", jobName)
+	} else if isJobDeadlineExceeded(job) && job.Annotations[constants.AnnDeadlineExceededReported] != "true" {
+		log.Info("restore job exceeded the active deadline", "jobName", job.Name)
+		event.RestoreDeadlineExceeded.Emit(cluster, r.Recorder, job.Name)
+
+		// The annotation prevents the event from being recorded on every reconciliation.
+		orig := job.DeepCopy()
+		if job.Annotations == nil {
+			job.Annotations = make(map[string]string)
+		}
+		job.Annotations[constants.AnnDeadlineExceededReported] = "true"
+		if err := r.Patch(ctx, job, client.MergeFrom(orig)); err != nil {
+			return fmt.Errorf("failed to annotate Job %s/%s: %w", job.Namespace, job.Name, err)
+		}
 	}
 
 	if err := r.reconcileV1RestoreJobRole(ctx, req, cluster); err != nil {
@@ -1558,10 +2521,76 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJob(ctx context.Context, req
 	return nil
 }
 
+// cleanupV1RestoreJob deletes the restore Job, Role, and RoleBinding after the restoration
+// has completed and `spec.restore.ttlSecondsAfterRestored` has passed.
+// If the TTL has not passed yet, it returns the remaining duration.
+//
+// `ttlSecondsAfterFinished` of the Job is not used because it also removes failed Jobs,
+// which would make the controller create the restore Job again.
+func (r *MySQLClusterReconciler) cleanupV1RestoreJob(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) (time.Duration, error) {
+	if cluster.Spec.Restore == nil || cluster.Status.RestoredTime == nil {
+		return 0, nil
+	}
+
+	var ttl time.Duration
+	if cluster.Spec.Restore.TTLSecondsAfterRestored != nil {
+		ttl = time.Duration(*cluster.Spec.Restore.TTLSecondsAfterRestored) * time.Second
+	}
+	if d := time.Until(cluster.Status.RestoredTime.Add(ttl)); d > 0 {
+		return d, nil
+	}
+
+	log := crlog.FromContext(ctx)
+
+	resources := []struct {
+		kind string
+		name string
+		obj  client.Object
+	}{
+		{"Job", cluster.RestoreJobName(), &batchv1.Job{}},
+		{"Role", cluster.RestoreRoleName(), &rbacv1.Role{}},
+		{"RoleBinding", cluster.RestoreRoleName(), &rbacv1.RoleBinding{}},
+	}
+	for _, res := range resources {
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: res.name}
+		if err := r.Get(ctx, key, res.obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return 0, fmt.Errorf("failed to get %s %s: %w", res.kind, key, err)
+		}
+		if !metav1.IsControlledBy(res.obj, cluster) {
+			continue
+		}
+
+		// Background propagation deletes the Pods of the Job as well.
+		err := r.Delete(ctx, res.obj, client.PropagationPolicy(metav1.DeletePropagationBackground))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return 0, fmt.Errorf("failed to delete %s %s: %w", res.kind, key, err)
+		}
+		log.Info("deleted "+res.kind+" for restore", "name", res.name)
+	}
+
+	return 0, nil
+}
+
+func isJobDeadlineExceeded(job *batchv1.Job) bool {
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == corev1.ConditionTrue && cond.Reason == "DeadlineExceeded" {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *MySQLClusterReconciler) reconcileV1RestoreJobRole(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
 	log := crlog.FromContext(ctx)
 
 	name := cluster.RestoreRoleName()
+	if ref := cluster.Spec.Restore.JobConfig.RoleRef; ref != nil {
+		return r.reconcileV1JobRoleRef(ctx, cluster, ref, name, "restore")
+	}
+
 	role := rbacv1ac.Role(name, cluster.Namespace).
 		WithLabels(labelSetForJob(cluster)).
 		WithRules(
@@ -1614,12 +2643,17 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJobRoleBinding(ctx context.Co
 	log := crlog.FromContext(ctx)
 
 	name := cluster.RestoreRoleName()
+	roleKind, roleName := jobRoleRef(cluster.Spec.Restore.JobConfig.RoleRef, name)
+	if err := r.deleteRoleBindingIfRoleRefChanged(ctx, cluster, name, roleKind, roleName); err != nil {
+		return err
+	}
+
 	roleBinding := rbacv1ac.RoleBinding(name, cluster.Namespace).
 		WithLabels(labelSetForJob(cluster)).
 		WithRoleRef(rbacv1ac.RoleRef().
 			WithAPIGroup(rbacv1.SchemeGroupVersion.Group).
-			WithKind("Role").
-			WithName(cluster.RestoreRoleName())).
+			WithKind(roleKind).
+			WithName(roleName)).
 		WithSubjects(rbacv1ac.Subject().
 			WithKind("ServiceAccount").
 			WithName(cluster.Spec.Restore.JobConfig.ServiceAccountName).
@@ -1656,6 +2690,9 @@ func (r *MySQLClusterReconciler) reconcileV1RestoreJobRoleBinding(ctx context.Co
 }
 
 func (r *MySQLClusterReconciler) finalizeV1(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	r.canaryTracker.forget(client.ObjectKeyFromObject(cluster))
+	r.eventTracker.forget(client.ObjectKeyFromObject(cluster))
+
 	secretName := cluster.ControllerSecretName()
 	secret := &corev1.Secret{}
 	secret.SetNamespace(r.SystemNamespace)
@@ -1672,19 +2709,30 @@ func (r *MySQLClusterReconciler) finalizeV1(ctx context.Context, cluster *mocov1
 		return fmt.Errorf("failed to delete certificate %s: %w", certName, err)
 	}
 
+	if r.ServerIDAllocator != nil {
+		if err := r.ServerIDAllocator.Release(ctx, cluster); err != nil {
+			return err
+		}
+	}
+
 	metrics.ClusteringStoppedVec.DeleteLabelValues(cluster.Name, cluster.Namespace)
 	metrics.ReconciliationStoppedVec.DeleteLabelValues(cluster.Name, cluster.Namespace)
 
 	return nil
 }
 
-func (r *MySQLClusterReconciler) updateStatus(ctx context.Context, cluster *mocov1beta2.MySQLCluster, reconcileErr error) error {
+func (r *MySQLClusterReconciler) updateStatus(ctx context.Context, cluster *mocov1beta2.MySQLCluster, mycnfName string, reconcileErr error) error {
 	log := crlog.FromContext(ctx)
 	orig := cluster.DeepCopy()
 
 	cluster.Status.ReconcileInfo.Generation = cluster.Generation
 	cluster.Status.ReconcileInfo.ReconcileVersion = 1
 
+	// Keep the last known name if the reconciliation failed before my.cnf was generated.
+	if mycnfName != "" {
+		cluster.Status.MyCnfConfigMapName = mycnfName
+	}
+
 	stsReady := metav1.ConditionFalse
 	reason := "StatefulSetNotReady"
 	message := "StatefulSet is not ready"
@@ -1713,6 +2761,30 @@ func (r *MySQLClusterReconciler) updateStatus(ctx context.Context, cluster *moco
 		},
 	)
 
+	if err == nil {
+		rollout, cond := rolloutStatus(&sts, cluster.Generation)
+		cluster.Status.Rollout = rollout
+		meta.SetStatusCondition(&cluster.Status.Conditions, cond)
+	} else if apierrors.IsNotFound(err) {
+		cluster.Status.Rollout = nil
+		meta.SetStatusCondition(&cluster.Status.Conditions,
+			metav1.Condition{
+				Type:               mocov1beta2.ConditionProgressing,
+				Status:             metav1.ConditionUnknown,
+				ObservedGeneration: cluster.Generation,
+				Reason:             reason,
+				Message:            message,
+			},
+		)
+	}
+
+	r.updateCertificateStatus(ctx, cluster, time.Now())
+	cluster.Status.ExpiryTime = pointInTimeCloneExpiry(cluster)
+
+	if err := r.updateBackupAttempts(ctx, cluster); err != nil {
+		log.Error(err, "failed to list backup jobs")
+	}
+
 	reconcileSuccess := metav1.ConditionFalse
 	reason = "ReconcileFailed"
 	message = "reconcile failed"
@@ -1887,6 +2959,21 @@ func setControllerReferenceWithStatefulSet(cluster *mocov1beta2.MySQLCluster, st
 	return nil
 }
 
+func setControllerReferenceWithDeployment(cluster *mocov1beta2.MySQLCluster, deploy *appsv1ac.DeploymentApplyConfiguration, scheme *runtime.Scheme) error {
+	gvk, err := apiutil.GVKForObject(cluster, scheme)
+	if err != nil {
+		return err
+	}
+	deploy.WithOwnerReferences(metav1ac.OwnerReference().
+		WithAPIVersion(gvk.GroupVersion().String()).
+		WithKind(gvk.Kind).
+		WithName(cluster.Name).
+		WithUID(cluster.GetUID()).
+		WithBlockOwnerDeletion(true).
+		WithController(true))
+	return nil
+}
+
 func setControllerReferenceWithPVC(cluster *mocov1beta2.MySQLCluster, pvc *corev1ac.PersistentVolumeClaimApplyConfiguration, origPVC *corev1.PersistentVolumeClaim, scheme *runtime.Scheme) error {
 	gvk, err := apiutil.GVKForObject(cluster, scheme)
 	if err != nil {
@@ -2006,8 +3093,35 @@ func setControllerReferenceWithCronJob(cluster *mocov1beta2.MySQLCluster, cronJo
 	return nil
 }
 
+// listClusters lists MySQLClusters in `namespace`, or in all namespaces if it is empty.
+// The list is limited to WatchNamespace if it is set.
+func (r *MySQLClusterReconciler) listClusters(ctx context.Context, namespace string) (*mocov1beta2.MySQLClusterList, error) {
+	clusters := &mocov1beta2.MySQLClusterList{}
+	if r.WatchNamespace != "" {
+		if namespace != "" && namespace != r.WatchNamespace {
+			return clusters, nil
+		}
+		namespace = r.WatchNamespace
+	}
+	if err := r.List(ctx, clusters, client.InNamespace(namespace)); err != nil {
+		return nil, err
+	}
+	return clusters, nil
+}
+
 // SetupWithManager sets up the controller with the Manager.
 func (r *MySQLClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	err := mgr.GetFieldIndexer().IndexField(context.Background(), &mocov1beta2.MySQLCluster{}, sourceClusterIndexField, func(o client.Object) []string {
+		c := o.(*mocov1beta2.MySQLCluster)
+		if c.Spec.ReplicationSource == nil {
+			return nil
+		}
+		return []string{c.SourceCluster().String()}
+	})
+	if err != nil {
+		return err
+	}
+
 	certHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
 		// the certificate name is formatted as "moco-agent-<cluster.Namespace>.<cluster.Name>"
 		if a.GetNamespace() != r.SystemNamespace {
@@ -2028,16 +3142,42 @@ func (r *MySQLClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	})
 
 	configMapHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
-		clusters := &mocov1beta2.MySQLClusterList{}
-		if err := r.List(ctx, clusters, client.InNamespace(a.GetNamespace())); err != nil {
+		clusters, err := r.listClusters(ctx, a.GetNamespace())
+		if err != nil {
 			return nil
 		}
 		var req []reconcile.Request
 		for _, c := range clusters.Items {
-			if c.Spec.MySQLConfigMapName == nil {
-				continue
+			if (c.Spec.MySQLConfigMapName != nil && *c.Spec.MySQLConfigMapName == a.GetName()) || r.usesCABundle(ctx, &c, a) {
+				req = append(req, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
 			}
-			if *c.Spec.MySQLConfigMapName == a.GetName() {
+		}
+		return req
+	})
+
+	secretHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		clusters, err := r.listClusters(ctx, a.GetNamespace())
+		if err != nil {
+			return nil
+		}
+		var req []reconcile.Request
+		for _, c := range clusters.Items {
+			if r.usesCABundle(ctx, &c, a) {
+				req = append(req, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+			}
+		}
+		return req
+	})
+
+	// Roles and ClusterRoles are watched to bind them once they are created or labeled.
+	jobRoleHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		clusters, err := r.listClusters(ctx, a.GetNamespace())
+		if err != nil {
+			return nil
+		}
+		var req []reconcile.Request
+		for _, c := range clusters.Items {
+			if r.usesJobRole(ctx, &c, a) {
 				req = append(req, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
 			}
 		}
@@ -2045,8 +3185,8 @@ func (r *MySQLClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	})
 
 	backupPolicyHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
-		clusters := &mocov1beta2.MySQLClusterList{}
-		if err := r.List(ctx, clusters, client.InNamespace(a.GetNamespace())); err != nil {
+		clusters, err := r.listClusters(ctx, a.GetNamespace())
+		if err != nil {
 			return nil
 		}
 		var req []reconcile.Request
@@ -2061,9 +3201,46 @@ func (r *MySQLClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return req
 	})
 
+	// backup Jobs are owned by the CronJob, so they are mapped to the owner of the CronJob.
+	backupJobHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		ref := metav1.GetControllerOf(a)
+		if ref == nil || ref.Kind != "CronJob" {
+			return nil
+		}
+		cj := &batchv1.CronJob{}
+		if err := r.Get(ctx, client.ObjectKey{Namespace: a.GetNamespace(), Name: ref.Name}, cj); err != nil {
+			return nil
+		}
+		ref = metav1.GetControllerOf(cj)
+		if ref == nil || ref.Kind != "MySQLCluster" || !strings.HasPrefix(ref.APIVersion, mocov1beta2.GroupVersion.Group+"/") {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: client.ObjectKey{Namespace: a.GetNamespace(), Name: ref.Name}}}
+	})
+
+	// only the Jobs created from the backup CronJobs are handled so that other Jobs in the
+	// cluster do not cause lookups of their CronJobs.
+	backupJobPredicate := predicate.NewPredicateFuncs(func(a client.Object) bool {
+		labels := a.GetLabels()
+		return labels[constants.LabelAppName] == constants.AppNameBackup && labels[constants.LabelAppCreatedBy] == constants.AppCreator
+	})
+
+	sourceClusterHandler := handler.EnqueueRequestsFromMapFunc(func(ctx context.Context, a client.Object) []reconcile.Request {
+		clusters := &mocov1beta2.MySQLClusterList{}
+		if err := r.List(ctx, clusters, client.MatchingFields{sourceClusterIndexField: client.ObjectKeyFromObject(a).String()}); err != nil {
+			return nil
+		}
+		var req []reconcile.Request
+		for _, c := range clusters.Items {
+			req = append(req, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&c)})
+		}
+		return req
+	})
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&mocov1beta2.MySQLCluster{}).
 		Owns(&appsv1.StatefulSet{}).
+		Owns(&appsv1.Deployment{}).
 		Owns(&corev1.Service{}).
 		Owns(&corev1.Secret{}).
 		Owns(&corev1.ServiceAccount{}).
@@ -2075,7 +3252,12 @@ func (r *MySQLClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		Owns(&batchv1.Job{}).
 		Watches(certificateObj, certHandler).
 		Watches(&corev1.ConfigMap{}, configMapHandler).
+		Watches(&corev1.Secret{}, secretHandler).
+		Watches(&rbacv1.Role{}, jobRoleHandler).
+		Watches(&rbacv1.ClusterRole{}, jobRoleHandler).
 		Watches(&mocov1beta2.BackupPolicy{}, backupPolicyHandler).
+		Watches(&batchv1.Job{}, backupJobHandler, builder.WithPredicates(backupJobPredicate)).
+		Watches(&mocov1beta2.MySQLCluster{}, sourceClusterHandler).
 		WithOptions(
 			controller.Options{MaxConcurrentReconciles: r.MaxConcurrentReconciles},
 		).
@@ -2,13 +2,16 @@ package controllers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/password"
 	appsv1 "k8s.io/api/apps/v1"
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -72,35 +75,7 @@ var _ = Describe("MySQLCluster reconciler", func() {
 	var stopFunc func()
 	var mockMgr *mockManager
 
-	BeforeEach(func() {
-		cs := &mocov1beta2.MySQLClusterList{}
-		err := k8sClient.List(ctx, cs, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		for _, cluster := range cs.Items {
-			cluster.Finalizers = nil
-			err := k8sClient.Update(ctx, &cluster)
-			Expect(err).NotTo(HaveOccurred())
-		}
-		svcs := &corev1.ServiceList{}
-		err = k8sClient.List(ctx, svcs, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		for _, svc := range svcs.Items {
-			err := k8sClient.Delete(ctx, &svc)
-			Expect(err).NotTo(HaveOccurred())
-		}
-		err = k8sClient.DeleteAllOf(ctx, &mocov1beta2.MySQLCluster{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.DeleteAllOf(ctx, &appsv1.StatefulSet{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.DeleteAllOf(ctx, &corev1.ServiceAccount{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.DeleteAllOf(ctx, &policyv1.PodDisruptionBudget{}, client.InNamespace("test"))
-		Expect(err).NotTo(HaveOccurred())
-
+	startManager := func(opts ...func(*MySQLClusterReconciler)) {
 		mgr, err := ctrl.NewManager(cfg, ctrl.Options{
 			Scheme:             scheme,
 			LeaderElection:     false,
@@ -122,6 +97,9 @@ var _ = Describe("MySQLCluster reconciler", func() {
 			FluentBitImage:  testFluentBitImage,
 			ExporterImage:   testExporterImage,
 		}
+		for _, opt := range opts {
+			opt(mysqlr)
+		}
 		err = mysqlr.SetupWithManager(mgr)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -134,6 +112,42 @@ var _ = Describe("MySQLCluster reconciler", func() {
 			}
 		}()
 		time.Sleep(100 * time.Millisecond)
+	}
+
+	BeforeEach(func() {
+		cs := &mocov1beta2.MySQLClusterList{}
+		err := k8sClient.List(ctx, cs, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		for _, cluster := range cs.Items {
+			cluster.Finalizers = nil
+			err := k8sClient.Update(ctx, &cluster)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		svcs := &corev1.ServiceList{}
+		err = k8sClient.List(ctx, svcs, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		for _, svc := range svcs.Items {
+			err := k8sClient.Delete(ctx, &svc)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		err = k8sClient.DeleteAllOf(ctx, &mocov1beta2.MySQLCluster{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &appsv1.StatefulSet{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &appsv1.Deployment{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.Secret{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.ConfigMap{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.ServiceAccount{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &policyv1.PodDisruptionBudget{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &corev1.ResourceQuota{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+
+		startManager()
 	})
 
 	AfterEach(func() {
@@ -240,6 +254,263 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		})
 	})
 
+	It("should rotate the passwords of the users for MOCO", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		controllerKey := client.ObjectKey{Namespace: testMocoSystemNamespace, Name: cluster.ControllerSecretName()}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, controllerKey, &corev1.Secret{})
+		}).Should(Succeed())
+
+		By("requesting the rotation")
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Annotations = map[string]string{constants.AnnRotatePasswords: "true"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+
+		pendingKey := client.ObjectKey{Namespace: "test", Name: cluster.RotatingPasswordSecretName()}
+		pending := &corev1.Secret{}
+		Eventually(func(g Gomega) {
+			err := k8sClient.Get(ctx, pendingKey, pending)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(pending.OwnerReferences).To(HaveLen(1))
+
+			c := &mocov1beta2.MySQLCluster{}
+			err = k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Annotations).NotTo(HaveKey(constants.AnnRotatePasswords))
+
+			events := &corev1.EventList{}
+			err = k8sClient.List(ctx, events, client.InNamespace("test"))
+			g.Expect(err).NotTo(HaveOccurred())
+			var started bool
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "PasswordRotationStarted" {
+					started = true
+				}
+			}
+			g.Expect(started).To(BeTrue())
+		}).Should(Succeed())
+
+		By("checking the Secrets are not updated until the new passwords are set")
+		Consistently(func(g Gomega) {
+			secret := &corev1.Secret{}
+			err := k8sClient.Get(ctx, controllerKey, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.Annotations).NotTo(HaveKey(constants.AnnPasswordRevision))
+		}, 3*time.Second).Should(Succeed())
+
+		By("recording the new passwords are set as the clustering does")
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Status.PasswordRotation = &mocov1beta2.PasswordRotationStatus{Retained: true}
+			return k8sClient.Status().Update(ctx, c)
+		}).Should(Succeed())
+
+		rev := string(pending.UID)
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			err := k8sClient.Get(ctx, controllerKey, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.Data).To(Equal(pending.Data))
+			g.Expect(secret.Annotations).To(HaveKeyWithValue(constants.AnnPasswordRevision, rev))
+
+			userSecret := &corev1.Secret{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.UserSecretName()}, userSecret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(userSecret.Data).To(Equal(pending.Data))
+			g.Expect(userSecret.Annotations).To(HaveKeyWithValue(constants.AnnPasswordRevision, rev))
+
+			err = k8sClient.Get(ctx, pendingKey, &corev1.Secret{})
+			g.Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+			sts := &appsv1.StatefulSet{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(sts.Spec.Template.Annotations).To(HaveKeyWithValue(constants.AnnPasswordRevision, rev))
+		}).Should(Succeed())
+	})
+
+	It("should refuse to rotate the passwords of a replication source", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		downstream := testNewMySQLCluster("test")
+		downstream.Name = "downstream"
+		downstream.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: cluster.Name}
+		err = k8sClient.Create(ctx, downstream)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Annotations = map[string]string{constants.AnnRotatePasswords: "true"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			c := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Annotations).NotTo(HaveKey(constants.AnnRotatePasswords))
+
+			events := &corev1.EventList{}
+			err = k8sClient.List(ctx, events, client.InNamespace("test"))
+			g.Expect(err).NotTo(HaveOccurred())
+			var refused bool
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "PasswordRotationRefused" {
+					refused = true
+				}
+			}
+			g.Expect(refused).To(BeTrue())
+		}).Should(Succeed())
+
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RotatingPasswordSecretName()}, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should generate the replication source Secret from the source cluster", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source"}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "test", Name: "moco-repl-source-test"}
+		Consistently(func() error {
+			return k8sClient.Get(ctx, key, &corev1.Secret{})
+		}, 3*time.Second).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+
+		By("creating the source cluster")
+		source := testNewMySQLCluster("test")
+		source.Name = "source"
+		err = k8sClient.Create(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+
+		var passwd *password.MySQLPassword
+		Eventually(func(g Gomega) {
+			controllerSecret := &corev1.Secret{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: testMocoSystemNamespace, Name: "mysql-test.source"}, controllerSecret)
+			g.Expect(err).NotTo(HaveOccurred())
+			passwd, err = password.NewMySQLPasswordFromSecret(controllerSecret)
+			g.Expect(err).NotTo(HaveOccurred())
+		}).Should(Succeed())
+
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			err := k8sClient.Get(ctx, key, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(secret.OwnerReferences).To(HaveLen(1))
+			g.Expect(secret.OwnerReferences[0].Name).To(Equal("test"))
+			g.Expect(secret.Data).To(Equal(map[string][]byte{
+				constants.CloneSourceHostKey:         []byte("moco-source-primary.test.svc"),
+				constants.CloneSourcePortKey:         []byte("3306"),
+				constants.CloneSourceUserKey:         []byte(constants.BackupUser),
+				constants.CloneSourcePasswordKey:     []byte(passwd.Backup()),
+				constants.CloneSourceInitUserKey:     []byte(constants.AdminUser),
+				constants.CloneSourceInitPasswordKey: []byte(passwd.Admin()),
+			}))
+		}).Should(Succeed())
+
+		By("stopping the replication")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ReplicationSource = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, &corev1.Secret{})
+		}).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+	})
+
+	It("should not replicate from a cluster in another namespace unless allowed", func() {
+		ns := &corev1.Namespace{}
+		ns.Name = "repl-source"
+		err := k8sClient.Create(ctx, ns)
+		if !apierrors.IsAlreadyExists(err) {
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		source := testNewMySQLCluster("repl-source")
+		source.Name = "source"
+		source.Finalizers = nil
+		err = k8sClient.Create(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, source)
+			Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+		}()
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: testMocoSystemNamespace, Name: "mysql-repl-source.source"}, &corev1.Secret{})
+		}).Should(Succeed())
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "repl-source"}
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "test", Name: "moco-repl-source-test"}
+		countEvents := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "ReplicationSourceNotAllowed" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+		Eventually(countEvents).Should(BeNumerically("==", 1))
+		Consistently(func() error {
+			return k8sClient.Get(ctx, key, &corev1.Secret{})
+		}, 3*time.Second).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Expect(countEvents()).To(BeNumerically("==", 1))
+
+		By("allowing the namespace to replicate from the source")
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(source), source)
+		Expect(err).NotTo(HaveOccurred())
+		source.Annotations = map[string]string{constants.AnnReplicationAllowedNamespaces: "foo, test"}
+		err = k8sClient.Update(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			secret := &corev1.Secret{}
+			err := k8sClient.Get(ctx, key, secret)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(string(secret.Data[constants.CloneSourceHostKey])).To(Equal("moco-source-primary.repl-source.svc"))
+		}).Should(Succeed())
+
+		By("disallowing the namespace again")
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(source), source)
+		Expect(err).NotTo(HaveOccurred())
+		source.Annotations = nil
+		err = k8sClient.Update(ctx, source)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, &corev1.Secret{})
+		}).Should(WithTransform(apierrors.IsNotFound, BeTrue()))
+		Eventually(countEvents).Should(BeNumerically("==", 2))
+	})
+
 	It("should create certificate and copy secret", func() {
 		By("creating a cluster")
 		cluster := testNewMySQLCluster("test")
@@ -300,6 +571,111 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(Equal([]byte("baz")))
 	})
 
+	It("should set duration and renewBefore of the certificate", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.AgentCertDuration = 24 * time.Hour
+			r.AgentCertRenewBefore = 8 * time.Hour
+		})
+
+		By("deleting the certificate created by other tests")
+		cert := certificateObj.DeepCopy()
+		cert.SetNamespace(testMocoSystemNamespace)
+		cert.SetName("moco-agent-test.test")
+		err := k8sClient.Delete(ctx, cert)
+		Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+
+		By("creating a cluster")
+		cluster := testNewMySQLCluster("test")
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cert = certificateObj.DeepCopy()
+			key := client.ObjectKey{Namespace: testMocoSystemNamespace, Name: "moco-agent-test.test"}
+			return k8sClient.Get(ctx, key, cert)
+		}).Should(Succeed())
+
+		duration, _, err := unstructured.NestedString(cert.Object, "spec", "duration")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(duration).To(Equal("24h0m0s"))
+		renewBefore, _, err := unstructured.NestedString(cert.Object, "spec", "renewBefore")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(renewBefore).To(Equal("8h0m0s"))
+	})
+
+	It("should record the expiry of the certificate", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.AgentCertExpiryThreshold = 24 * time.Hour
+		})
+
+		By("deleting the certificate created by other tests")
+		cert := certificateObj.DeepCopy()
+		cert.SetNamespace(testMocoSystemNamespace)
+		cert.SetName("moco-agent-test.test")
+		err := k8sClient.Delete(ctx, cert)
+		Expect(client.IgnoreNotFound(err)).NotTo(HaveOccurred())
+
+		By("creating a cluster")
+		cluster := testNewMySQLCluster("test")
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CertificateExpiry).To(BeNil())
+			cond := meta.FindStatusCondition(cluster.Status.Conditions, mocov1beta2.ConditionCertificateExpiringSoon)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Status).To(Equal(metav1.ConditionUnknown))
+		}).Should(Succeed())
+
+		setNotAfter := func(notAfter time.Time) {
+			cert := certificateObj.DeepCopy()
+			key := client.ObjectKey{Namespace: testMocoSystemNamespace, Name: "moco-agent-test.test"}
+			err := k8sClient.Get(ctx, key, cert)
+			Expect(err).NotTo(HaveOccurred())
+			err = unstructured.SetNestedField(cert.Object, notAfter.UTC().Format(time.RFC3339), "status", "notAfter")
+			Expect(err).NotTo(HaveOccurred())
+			err = k8sClient.Status().Update(ctx, cert)
+			Expect(err).NotTo(HaveOccurred())
+		}
+
+		By("issuing a certificate that expires soon")
+		notAfter := time.Now().Add(12 * time.Hour).Truncate(time.Second)
+		setNotAfter(notAfter)
+
+		Eventually(func(g Gomega) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CertificateExpiry).NotTo(BeNil())
+			g.Expect(cluster.Status.CertificateExpiry.Time.Equal(notAfter)).To(BeTrue())
+			cond := meta.FindStatusCondition(cluster.Status.Conditions, mocov1beta2.ConditionCertificateExpiringSoon)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Status).To(Equal(metav1.ConditionTrue))
+		}).Should(Succeed())
+
+		By("renewing the certificate")
+		notAfter = time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+		setNotAfter(notAfter)
+
+		Eventually(func(g Gomega) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.CertificateExpiry).NotTo(BeNil())
+			g.Expect(cluster.Status.CertificateExpiry.Time.Equal(notAfter)).To(BeTrue())
+			cond := meta.FindStatusCondition(cluster.Status.Conditions, mocov1beta2.ConditionCertificateExpiringSoon)
+			g.Expect(cond).NotTo(BeNil())
+			g.Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+		}).Should(Succeed())
+	})
+
 	It("should create config maps for fluent-bit", func() {
 		cluster := testNewMySQLCluster("test")
 		err := k8sClient.Create(ctx, cluster)
@@ -313,6 +689,7 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(Succeed())
 
 		Expect(slowCM.OwnerReferences).NotTo(BeEmpty())
+		Expect(slowCM.Data[constants.FluentBitConfigName]).To(ContainSubstring("  File           stdout\n"))
 
 		slowCM.Data = nil
 		err = k8sClient.Update(ctx, slowCM)
@@ -345,42 +722,445 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(BeTrue())
 	})
 
-	It("should create config maps for my.cnf", func() {
-		cluster := testNewMySQLCluster("test")
-		cluster.Spec.PodTemplate.Spec.Containers[0].WithResources(
-			corev1ac.ResourceRequirements().WithLimits(corev1.ResourceList{
-				corev1.ResourceMemory: resource.MustParse("1000Mi"),
-			}))
-		err := k8sClient.Create(ctx, cluster)
-		Expect(err).NotTo(HaveOccurred())
+	It("should control the slow query log and its sidecar container independently", func() {
+		testcases := []struct {
+			disableLog       bool
+			disableContainer bool
+		}{
+			{false, false},
+			{false, true},
+			{true, false},
+			{true, true},
+		}
 
-		Eventually(func() error {
-			c := &mocov1beta2.MySQLCluster{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
-				return err
-			}
-			if c.Status.ReconcileInfo.Generation != c.Generation {
-				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
-			}
-			return nil
-		}).Should(Succeed())
+		for i, tc := range testcases {
+			By(fmt.Sprintf("disableSlowQueryLog=%v, disableSlowQueryLogContainer=%v", tc.disableLog, tc.disableContainer))
+			cluster := testNewMySQLCluster("test")
+			cluster.Name = fmt.Sprintf("test%d", i)
+			cluster.Spec.DisableSlowQueryLog = tc.disableLog
+			cluster.Spec.DisableSlowQueryLogContainer = tc.disableContainer
+			err := k8sClient.Create(ctx, cluster)
+			Expect(err).NotTo(HaveOccurred())
 
-		var cm *corev1.ConfigMap
-		Eventually(func() error {
-			cms := &corev1.ConfigMapList{}
-			if err := k8sClient.List(ctx, cms, client.InNamespace("test")); err != nil {
-				return err
-			}
+			var sts *appsv1.StatefulSet
+			Eventually(func() error {
+				sts = &appsv1.StatefulSet{}
+				return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			}).Should(Succeed())
 
-			var mycnfCMs []*corev1.ConfigMap
-			for i, cm := range cms.Items {
-				if strings.HasPrefix(cm.Name, "moco-test.") {
-					mycnfCMs = append(mycnfCMs, &cms.Items[i])
+			hasSidecar := false
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.SlowQueryLogAgentContainerName {
+					hasSidecar = true
 				}
 			}
+			Expect(hasSidecar).To(Equal(!tc.disableContainer))
 
-			if len(mycnfCMs) != 1 {
-				return fmt.Errorf("the number of config maps is not 1: %d", len(mycnfCMs))
+			slowCM := &corev1.ConfigMap{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.SlowQueryLogAgentConfigMapName()}, slowCM)
+			if tc.disableContainer {
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			var mycnfName string
+			for _, v := range sts.Spec.Template.Spec.Volumes {
+				if v.Name == constants.MySQLConfVolumeName {
+					mycnfName = v.ConfigMap.Name
+				}
+			}
+			cm := &corev1.ConfigMap{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: mycnfName}, cm)
+			Expect(err).NotTo(HaveOccurred())
+			if tc.disableLog {
+				Expect(cm.Data["my.cnf"]).To(ContainSubstring("slow_query_log = OFF"))
+			} else {
+				Expect(cm.Data["my.cnf"]).To(ContainSubstring("slow_query_log = ON"))
+			}
+		}
+	})
+
+	It("should add the audit-log sidecar container and its config map", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.EnableAuditLogContainer = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		var found bool
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name != constants.AuditLogAgentContainerName {
+				continue
+			}
+			found = true
+			Expect(c.Image).To(Equal(testFluentBitImage))
+			Expect(c.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      constants.AuditLogAgentConfigVolumeName,
+				MountPath: constants.FluentBitConfigPath,
+				ReadOnly:  true,
+			}))
+			Expect(c.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      constants.VarLogVolumeName,
+				MountPath: constants.LogDirPath,
+			}))
+		}
+		Expect(found).To(BeTrue())
+
+		var mycnfName string
+		var auditVolume bool
+		for _, v := range sts.Spec.Template.Spec.Volumes {
+			switch v.Name {
+			case constants.MySQLConfVolumeName:
+				mycnfName = v.ConfigMap.Name
+			case constants.AuditLogAgentConfigVolumeName:
+				auditVolume = true
+				Expect(v.ConfigMap.Name).To(Equal(cluster.AuditLogAgentConfigMapName()))
+			}
+		}
+		Expect(auditVolume).To(BeTrue())
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.AuditLogAgentConfigMapName()}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data[constants.FluentBitConfigName]).To(ContainSubstring("  Path           /var/log/mysql/audit.log\n"))
+		Expect(cm.Data[constants.FluentBitConfigName]).To(ContainSubstring("  Rotate_Wait    60\n"))
+		Expect(cm.Data[constants.FluentBitConfigName]).To(ContainSubstring("  File           stdout\n"))
+
+		cm = &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: mycnfName}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("plugin_load_add = audit_log.so\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("loose_audit_log_file = /var/log/mysql/audit.log\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("loose_audit_log_rotate_on_size = 134217728\n"))
+
+		By("disabling the audit log")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.EnableAuditLogContainer = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.AuditLogAgentContainerName {
+					return errors.New("audit-log container still exists")
+				}
+			}
+			return nil
+		}).Should(Succeed())
+
+		Eventually(func() bool {
+			cm := &corev1.ConfigMap{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.AuditLogAgentConfigMapName()}, cm)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should create a config map for Grafana dashboard", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CreateDashboard = true
+		cluster.Spec.Collectors = []string{"binlog_size"}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cm *corev1.ConfigMap
+		Eventually(func() error {
+			cm = &corev1.ConfigMap{}
+			key := client.ObjectKey{Namespace: "test", Name: "moco-dashboard-test"}
+			return k8sClient.Get(ctx, key, cm)
+		}).Should(Succeed())
+
+		Expect(cm.OwnerReferences).NotTo(BeEmpty())
+		Expect(cm.Labels).To(HaveKeyWithValue("grafana_dashboard", "1"))
+		Expect(cm.Data).To(HaveKey("moco-test-test.json"))
+
+		dashboard := make(map[string]interface{})
+		err = json.Unmarshal([]byte(cm.Data["moco-test-test.json"]), &dashboard)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(dashboard["title"]).To(Equal("MOCO / test / test"))
+		Expect(cm.Data["moco-test-test.json"]).To(ContainSubstring("mysql_binlog_size_bytes"))
+		Expect(cm.Data["moco-test-test.json"]).NotTo(ContainSubstring("mysql_engine_innodb_queries_inside_innodb"))
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.CreateDashboard = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			cm = &corev1.ConfigMap{}
+			key := client.ObjectKey{Namespace: "test", Name: "moco-dashboard-test"}
+			err := k8sClient.Get(ctx, key, cm)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should create a pod monitor for mysqld_exporter", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CreatePodMonitor = true
+		cluster.Spec.Collectors = []string{"binlog_size"}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		pm := &unstructured.Unstructured{}
+		pm.SetGroupVersionKind(podMonitorGVK)
+		key := client.ObjectKey{Namespace: "test", Name: "moco-test"}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, pm)
+		}).Should(Succeed())
+
+		Expect(pm.GetOwnerReferences()).To(HaveLen(1))
+		Expect(pm.GetOwnerReferences()[0].Name).To(Equal("test"))
+		selector, _, err := unstructured.NestedStringMap(pm.Object, "spec", "selector", "matchLabels")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selector).To(Equal(map[string]string{
+			constants.LabelAppName:      constants.AppNameMySQL,
+			constants.LabelAppInstance:  "test",
+			constants.LabelAppCreatedBy: constants.AppCreator,
+		}))
+		endpoints, _, err := unstructured.NestedSlice(pm.Object, "spec", "podMetricsEndpoints")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(endpoints).To(Equal([]interface{}{
+			map[string]interface{}{
+				"port": constants.ExporterPortName,
+				"path": "/metrics",
+			},
+		}))
+
+		By("running mysqld_exporter as a Deployment")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ExporterMode = mocov1beta2.ExporterModeDeployment
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			pm := &unstructured.Unstructured{}
+			pm.SetGroupVersionKind(podMonitorGVK)
+			err := k8sClient.Get(ctx, key, pm)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		By("running mysqld_exporter as a sidecar again")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ExporterMode = mocov1beta2.ExporterModeSidecar
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			pm := &unstructured.Unstructured{}
+			pm.SetGroupVersionKind(podMonitorGVK)
+			return k8sClient.Get(ctx, key, pm)
+		}).Should(Succeed())
+
+		By("disabling the pod monitor")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.CreatePodMonitor = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			pm := &unstructured.Unstructured{}
+			pm.SetGroupVersionKind(podMonitorGVK)
+			err := k8sClient.Get(ctx, key, pm)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should create a metrics service for mysqld_exporter", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CreateMetricsService = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "test", Name: "moco-test-metrics"}
+		Consistently(func() bool {
+			err := k8sClient.Get(ctx, key, &corev1.Service{})
+			return apierrors.IsNotFound(err)
+		}, 3*time.Second).Should(BeTrue())
+
+		By("enabling collectors")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Collectors = []string{"binlog_size"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		svc := &corev1.Service{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, svc)
+		}).Should(Succeed())
+
+		Expect(svc.OwnerReferences).To(HaveLen(1))
+		Expect(svc.OwnerReferences[0].Name).To(Equal("test"))
+		Expect(svc.Spec.ClusterIP).To(Equal(corev1.ClusterIPNone))
+		Expect(svc.Spec.Selector).To(Equal(map[string]string{
+			constants.LabelAppName:      constants.AppNameMySQL,
+			constants.LabelAppInstance:  "test",
+			constants.LabelAppCreatedBy: constants.AppCreator,
+		}))
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Name).To(Equal(constants.ExporterPortName))
+		Expect(svc.Spec.Ports[0].Port).To(BeNumerically("==", constants.ExporterPort))
+		Expect(svc.Spec.Ports[0].TargetPort).To(Equal(intstr.FromString(constants.ExporterPortName)))
+
+		By("disabling the metrics service")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.CreateMetricsService = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, key, &corev1.Service{})
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should create a route of Gateway API for the primary service", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.GatewayRoute = &mocov1beta2.GatewayRouteSpec{
+			ParentRefs: []mocov1beta2.GatewayParentReference{
+				{Name: "gw", Namespace: "gateway", SectionName: "mysql"},
+			},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		key := client.ObjectKey{Namespace: "test", Name: "moco-test"}
+		route := &unstructured.Unstructured{}
+		route.SetGroupVersionKind(gatewayRouteGVKs[mocov1beta2.GatewayRouteTCP])
+		Eventually(func() error {
+			return k8sClient.Get(ctx, key, route)
+		}).Should(Succeed())
+
+		Expect(route.GetOwnerReferences()).To(HaveLen(1))
+		Expect(route.GetOwnerReferences()[0].Name).To(Equal("test"))
+		parentRefs, _, err := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(parentRefs).To(Equal([]interface{}{
+			map[string]interface{}{
+				"name":        "gw",
+				"namespace":   "gateway",
+				"sectionName": "mysql",
+			},
+		}))
+		rules, _, err := unstructured.NestedSlice(route.Object, "spec", "rules")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(rules).To(Equal([]interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{
+						"name": "moco-test-primary",
+						"port": int64(constants.MySQLPort),
+					},
+				},
+			},
+		}))
+
+		By("changing the kind to TLSRoute")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.GatewayRoute.Kind = mocov1beta2.GatewayRouteTLS
+		cluster.Spec.GatewayRoute.Hostnames = []string{"mysql.example.com"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			route := &unstructured.Unstructured{}
+			route.SetGroupVersionKind(gatewayRouteGVKs[mocov1beta2.GatewayRouteTLS])
+			if err := k8sClient.Get(ctx, key, route); err != nil {
+				return err
+			}
+			hostnames, _, err := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+			if err != nil {
+				return err
+			}
+			if len(hostnames) != 1 || hostnames[0] != "mysql.example.com" {
+				return fmt.Errorf("unexpected hostnames: %v", hostnames)
+			}
+			return nil
+		}).Should(Succeed())
+
+		Eventually(func() bool {
+			route := &unstructured.Unstructured{}
+			route.SetGroupVersionKind(gatewayRouteGVKs[mocov1beta2.GatewayRouteTCP])
+			err := k8sClient.Get(ctx, key, route)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		By("removing the route")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.GatewayRoute = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			route := &unstructured.Unstructured{}
+			route.SetGroupVersionKind(gatewayRouteGVKs[mocov1beta2.GatewayRouteTLS])
+			err := k8sClient.Get(ctx, key, route)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should create config maps for my.cnf", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PodTemplate.Spec.Containers[0].WithResources(
+			corev1ac.ResourceRequirements().WithLimits(corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("1000Mi"),
+			}))
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.ReconcileInfo.Generation != c.Generation {
+				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
+			}
+			return nil
+		}).Should(Succeed())
+
+		var cm *corev1.ConfigMap
+		Eventually(func() error {
+			cms := &corev1.ConfigMapList{}
+			if err := k8sClient.List(ctx, cms, client.InNamespace("test")); err != nil {
+				return err
+			}
+
+			var mycnfCMs []*corev1.ConfigMap
+			for i, cm := range cms.Items {
+				if strings.HasPrefix(cm.Name, "moco-test.") {
+					mycnfCMs = append(mycnfCMs, &cms.Items[i])
+				}
+			}
+
+			if len(mycnfCMs) != 1 {
+				return fmt.Errorf("the number of config maps is not 1: %d", len(mycnfCMs))
 			}
 
 			cm = mycnfCMs[0]
@@ -391,6 +1171,17 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		Expect(cm.Data).To(HaveKey("my.cnf"))
 		Expect(cm.Data["my.cnf"]).To(ContainSubstring("innodb_buffer_pool_size = 734003200"))
 
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.MyCnfConfigMapName != cm.Name {
+				return fmt.Errorf("status.myCnfConfigMapName is not %s: %s", cm.Name, c.Status.MyCnfConfigMapName)
+			}
+			return nil
+		}).Should(Succeed())
+
 		userCM := &corev1.ConfigMap{}
 		userCM.Namespace = "test"
 		userCM.Name = "user-conf"
@@ -474,15 +1265,247 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(Succeed())
 
 		Expect(cm.Data["my.cnf"]).To(ContainSubstring("foo = baz"))
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.MyCnfConfigMapName != cm.Name {
+				return fmt.Errorf("status.myCnfConfigMapName is not %s: %s", cm.Name, c.Status.MyCnfConfigMapName)
+			}
+			return nil
+		}).Should(Succeed())
 	})
 
-	It("should reconcile service account", func() {
+	It("should render spec.redoLog in my.cnf", func() {
 		cluster := testNewMySQLCluster("test")
+		cluster.Spec.RedoLog = &mocov1beta2.RedoLogSpec{
+			FileSize:     ptr.To(resource.MustParse("128Mi")),
+			FilesInGroup: ptr.To[int32](3),
+		}
 		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
-		var sa *corev1.ServiceAccount
-		Eventually(func() error {
+		mycnfName := func(g Gomega) string {
+			sts := &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, v := range sts.Spec.Template.Spec.Volumes {
+				if v.Name == constants.MySQLConfVolumeName {
+					return v.ConfigMap.Name
+				}
+			}
+			return ""
+		}
+
+		var oldName string
+		Eventually(func(g Gomega) {
+			oldName = mycnfName(g)
+			g.Expect(oldName).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: oldName}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("innodb_log_file_size = 134217728\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("innodb_log_files_in_group = 3\n"))
+
+		By("changing to innodb_redo_log_capacity")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.RedoLog = &mocov1beta2.RedoLogSpec{
+			Capacity: ptr.To(resource.MustParse("512Mi")),
+		}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var newName string
+		Eventually(func(g Gomega) {
+			newName = mycnfName(g)
+			g.Expect(newName).NotTo(Equal(oldName))
+		}).Should(Succeed())
+
+		cm = &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: newName}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("innodb_redo_log_capacity = 536870912\n"))
+		Expect(cm.Data["my.cnf"]).NotTo(ContainSubstring("innodb_log_file_size"))
+		Expect(cm.Data["my.cnf"]).NotTo(ContainSubstring("innodb_log_files_in_group"))
+	})
+
+	It("should render spec.slowQueryLog in my.cnf", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.SlowQueryLog = &mocov1beta2.SlowQueryLogSpec{
+			LongQueryTime:             &metav1.Duration{Duration: 500 * time.Millisecond},
+			LogQueriesNotUsingIndexes: ptr.To(true),
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		mycnfName := func(g Gomega) string {
+			sts := &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			g.Expect(err).NotTo(HaveOccurred())
+			for _, v := range sts.Spec.Template.Spec.Volumes {
+				if v.Name == constants.MySQLConfVolumeName {
+					return v.ConfigMap.Name
+				}
+			}
+			return ""
+		}
+
+		var oldName string
+		Eventually(func(g Gomega) {
+			oldName = mycnfName(g)
+			g.Expect(oldName).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: oldName}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("long_query_time = 0.5\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("log_queries_not_using_indexes = ON\n"))
+
+		By("changing long_query_time")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.SlowQueryLog.LongQueryTime = &metav1.Duration{Duration: 5 * time.Second}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			g.Expect(mycnfName(g)).NotTo(Equal(oldName))
+		}).Should(Succeed())
+	})
+
+	It("should add the configured labels and annotations to the config map for my.cnf", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.MyCnfConfigMapLabels = map[string]string{
+				"example.com/prune":        "false",
+				constants.LabelAppInstance: "foo",
+			}
+			r.MyCnfConfigMapAnnotations = map[string]string{
+				"argocd.argoproj.io/compare-options": "IgnoreExtraneous",
+			}
+		})
+
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cm *corev1.ConfigMap
+		Eventually(func(g Gomega) {
+			c := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Status.MyCnfConfigMapName).NotTo(BeEmpty())
+
+			cm = &corev1.ConfigMap{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: c.Status.MyCnfConfigMapName}, cm)
+			g.Expect(err).NotTo(HaveOccurred())
+		}).Should(Succeed())
+
+		Expect(cm.Labels).To(HaveKeyWithValue("example.com/prune", "false"))
+		Expect(cm.Labels).To(HaveKeyWithValue(constants.LabelAppInstance, "test"))
+		Expect(cm.Labels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameMySQL))
+		Expect(cm.Annotations).To(HaveKeyWithValue("argocd.argoproj.io/compare-options", "IgnoreExtraneous"))
+	})
+
+	It("should derive max_connections from the memory request", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PodTemplate.Spec.Containers[0].WithResources(
+			corev1ac.ResourceRequirements().WithRequests(corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("4Gi"),
+			}),
+		)
+		cluster.Spec.MaxConnections = &mocov1beta2.MaxConnectionsSpec{
+			PerConnectionMemory: resource.MustParse("8Mi"),
+			Max:                 ptr.To[int32](300),
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cm *corev1.ConfigMap
+		Eventually(func(g Gomega) {
+			c := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Status.MyCnfConfigMapName).NotTo(BeEmpty())
+
+			cm = &corev1.ConfigMap{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: c.Status.MyCnfConfigMapName}, cm)
+			g.Expect(err).NotTo(HaveOccurred())
+		}).Should(Succeed())
+
+		// 4Gi / 8Mi = 512 is bounded by max.
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("\nmax_connections = 300\n"))
+	})
+
+	It("should record an event when the mysqld container has no memory request", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "BufferPoolSizeNotDerived" {
+					if ev.Type != corev1.EventTypeNormal {
+						return fmt.Errorf("unexpected event type: %s", ev.Type)
+					}
+					return nil
+				}
+			}
+			return errors.New("no BufferPoolSizeNotDerived event")
+		}).Should(Succeed())
+
+		By("checking my.cnf is still generated")
+		Eventually(func(g Gomega) {
+			c := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(c.Status.MyCnfConfigMapName).NotTo(BeEmpty())
+		}).Should(Succeed())
+
+		By("recording the event only when my.cnf changes")
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Labels = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+		Consistently(func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "BufferPoolSizeNotDerived" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}, 3*time.Second).Should(BeNumerically("==", 1))
+	})
+
+	It("should reconcile service account", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sa *corev1.ServiceAccount
+		Eventually(func() error {
 			sa = &corev1.ServiceAccount{}
 			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sa)
 		}).Should(Succeed())
@@ -667,387 +1690,267 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(Succeed())
 	})
 
-	It("should reconcile statefulset", func() {
+	It("should publish additional ports on the headless service", func() {
 		cluster := testNewMySQLCluster("test")
-		cluster.Spec.ReplicationSourceSecretName = ptr.To[string]("source-secret")
-		cluster.Spec.PodTemplate.Annotations = map[string]string{"foo": "bar"}
-		cluster.Spec.PodTemplate.Labels = map[string]string{"foo": "baz"}
-
+		cluster.Spec.HeadlessServicePorts = []mocov1beta2.ServicePortApplyConfiguration{
+			mocov1beta2.ServicePortApplyConfiguration(*corev1ac.ServicePort().
+				WithName("proxy").
+				WithProtocol(corev1.ProtocolTCP).
+				WithPort(6033).
+				WithTargetPort(intstr.FromInt(6033))),
+		}
 		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
-		var sts *appsv1.StatefulSet
+		var headless, primary *corev1.Service
 		Eventually(func() error {
-			sts = &appsv1.StatefulSet{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
-				return err
-			}
-
-			cluster = &mocov1beta2.MySQLCluster{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+			headless = &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, headless); err != nil {
 				return err
 			}
-			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
-				return fmt.Errorf("status is not updated")
-			}
-
-			return nil
+			primary = &corev1.Service{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary)
 		}).Should(Succeed())
 
-		By("checking new statefulset")
-		Expect(sts.OwnerReferences).NotTo(BeEmpty())
-		Expect(sts.Spec.Template.Annotations).To(HaveKeyWithValue("foo", "bar"))
-		Expect(sts.Spec.Template.Labels).To(HaveKeyWithValue("foo", "baz"))
-		Expect(sts.Spec.Replicas).NotTo(BeNil())
-		Expect(*sts.Spec.Replicas).To(Equal(cluster.Spec.Replicas))
-		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).NotTo(BeNil())
-		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNumerically("==", defaultTerminationGracePeriodSeconds))
-		Expect(sts.Spec.Template.Spec.SecurityContext).NotTo(BeNil())
-		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(int64(constants.ContainerGID)))
-		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy).To(Equal(corev1.FSGroupChangeOnRootMismatch))
-		Expect(sts.Spec.Template.Spec.Affinity).NotTo(BeNil())
-		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
-		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).NotTo(BeNil())
+		Expect(headless.Spec.ClusterIP).To(Equal("None"))
+		Expect(headless.Spec.PublishNotReadyAddresses).To(BeTrue())
+		portNames := make(map[string]int32)
+		for _, port := range headless.Spec.Ports {
+			portNames[port.Name] = port.Port
+		}
+		Expect(portNames).To(Equal(map[string]int32{
+			constants.MySQLPortName:      constants.MySQLPort,
+			constants.MySQLXPortName:     constants.MySQLXPort,
+			constants.MySQLAdminPortName: constants.MySQLAdminPort,
+			"proxy":                      6033,
+		}))
 
-		Expect(sts.Spec.Template.Spec.Containers).To(HaveLen(3))
-		foundMysqld := false
-		foundAgent := false
-		foundSlowLogAgent := false
-		foundExporter := false
-		for _, c := range sts.Spec.Template.Spec.Containers {
-			Expect(c.SecurityContext).NotTo(BeNil())
-			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
-			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
-			switch c.Name {
-			case constants.MysqldContainerName:
-				foundMysqld = true
-				Expect(c.Image).To(Equal("moco-mysql:latest"))
-				Expect(c.StartupProbe).NotTo(BeNil())
-				Expect(c.StartupProbe.FailureThreshold).To(Equal(int32(360)))
-				Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(BeNil())
-			case constants.AgentContainerName:
-				foundAgent = true
-				Expect(c.Image).To(Equal(testAgentImage))
-				Expect(c.Args).To(Equal([]string{"--max-delay", "60s"}))
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
-			case constants.SlowQueryLogAgentContainerName:
-				foundSlowLogAgent = true
-				Expect(c.Image).To(Equal(testFluentBitImage))
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("20Mi")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("20Mi")}))
-			case constants.ExporterContainerName:
-				foundExporter = true
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
-			}
+		for _, port := range primary.Spec.Ports {
+			Expect(port.Name).NotTo(Equal("proxy"))
 		}
-		Expect(foundMysqld).To(BeTrue())
-		Expect(foundAgent).To(BeTrue())
-		Expect(foundSlowLogAgent).To(BeTrue())
-		Expect(foundExporter).To(BeFalse())
+	})
 
-		Expect(sts.Spec.Template.Spec.InitContainers).To(HaveLen(2))
+	It("should keep loadBalancerClass of the service templates", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PrimaryServiceTemplate = &mocov1beta2.ServiceTemplate{
+			Spec: (*mocov1beta2.ServiceSpecApplyConfiguration)(corev1ac.ServiceSpec().
+				WithType(corev1.ServiceTypeLoadBalancer).
+				WithLoadBalancerClass("example.com/internal-lb"),
+			),
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
-		cpInitContainer := &sts.Spec.Template.Spec.InitContainers[0]
-		Expect(cpInitContainer.Name).To(Equal(constants.CopyInitContainerName))
-		Expect(cpInitContainer.Image).To(Equal(testAgentImage))
-		Expect(cpInitContainer.Command).To(ContainElement("cp"))
-		Expect(cpInitContainer.SecurityContext).NotTo(BeNil())
-		Expect(cpInitContainer.SecurityContext.RunAsUser).NotTo(BeNil())
-		Expect(*cpInitContainer.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
-		Expect(cpInitContainer.SecurityContext.RunAsGroup).NotTo(BeNil())
-		Expect(*cpInitContainer.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+		var primary *corev1.Service
+		Eventually(func() error {
+			primary = &corev1.Service{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary)
+		}).Should(Succeed())
 
-		initContainer := &sts.Spec.Template.Spec.InitContainers[1]
-		Expect(initContainer.Name).To(Equal(constants.InitContainerName))
-		Expect(initContainer.Image).To(Equal("moco-mysql:latest"))
-		Expect(initContainer.Command).To(ContainElement(fmt.Sprintf("%d", cluster.Spec.ServerIDBase)))
-		Expect(initContainer.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("300Mi")}))
-		Expect(initContainer.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("300Mi")}))
-		Expect(initContainer.SecurityContext).NotTo(BeNil())
-		Expect(initContainer.SecurityContext.RunAsUser).NotTo(BeNil())
-		Expect(*initContainer.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
-		Expect(initContainer.SecurityContext.RunAsGroup).NotTo(BeNil())
-		Expect(*initContainer.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+		Expect(primary.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(primary.Spec.LoadBalancerClass).To(Equal(ptr.To[string]("example.com/internal-lb")))
+		Expect(primary.Spec.Selector).To(HaveKeyWithValue(constants.LabelMocoRole, constants.RolePrimary))
+		Expect(primary.Spec.Ports).To(HaveLen(2))
 
-		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
-		Expect(sts.Spec.VolumeClaimTemplates[0].Name).To(Equal(constants.MySQLDataVolumeName))
+		By("updating the template")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PrimaryServiceTemplate.Annotations = map[string]string{"foo": "bar"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
-		foundUserSecret := false
-		foundMyCnfConfig := false
-		foundSlowLogConfig := false
-		for _, v := range sts.Spec.Template.Spec.Volumes {
-			switch v.Name {
-			case constants.MySQLConfSecretVolumeName:
-				foundUserSecret = true
-			case constants.MySQLConfVolumeName:
-				foundMyCnfConfig = true
-			case constants.SlowQueryLogAgentConfigVolumeName:
-				foundSlowLogConfig = true
+		Eventually(func() error {
+			primary = &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary); err != nil {
+				return err
 			}
-		}
-		Expect(foundUserSecret).To(BeTrue())
-		Expect(foundMyCnfConfig).To(BeTrue())
-		Expect(foundSlowLogConfig).To(BeTrue())
+			if primary.Annotations["foo"] != "bar" {
+				return errors.New("service is not updated")
+			}
+			return nil
+		}).Should(Succeed())
 
-		By("editing statefulset")
-		// Sleep before editing statefulset to avoid slow query agent container from being restored by the controller
-		time.Sleep(1 * time.Second)
+		Expect(primary.Spec.LoadBalancerClass).To(Equal(ptr.To[string]("example.com/internal-lb")))
 
-		for i, c := range sts.Spec.Template.Spec.Containers {
-			switch c.Name {
-			case constants.AgentContainerName, constants.SlowQueryLogAgentContainerName:
-				sts.Spec.Template.Spec.Containers[i].Image = "invalid"
-			}
+		replica := &corev1.Service{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-replica"}, replica)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replica.Spec.LoadBalancerClass).To(BeNil())
+	})
+
+	It("should enable topology aware hints on the replica service", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.TopologyAwareReplicaService = true
+		cluster.Spec.ReplicaServiceTemplate = &mocov1beta2.ServiceTemplate{
+			ObjectMeta: mocov1beta2.ObjectMeta{
+				Annotations: map[string]string{"foo": "bar"},
+			},
+			Spec: (*mocov1beta2.ServiceSpecApplyConfiguration)(corev1ac.ServiceSpec().
+				WithType(corev1.ServiceTypeLoadBalancer),
+			),
 		}
-		err = k8sClient.Update(ctx, sts)
+		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
+		var replica *corev1.Service
 		Eventually(func() error {
-			sts = &appsv1.StatefulSet{}
-			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
-			if err != nil {
-				return err
-			}
-			for _, c := range sts.Spec.Template.Spec.Containers {
-				if c.Name != constants.AgentContainerName {
-					continue
-				}
-				if c.Image != testAgentImage {
-					return errors.New("c.Image is not reconciled yet")
-				}
-			}
-			return nil
+			replica = &corev1.Service{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-replica"}, replica)
 		}).Should(Succeed())
-		for _, c := range sts.Spec.Template.Spec.Containers {
-			switch c.Name {
-			case constants.SlowQueryLogAgentContainerName:
-				Expect(c.Image).To(Equal("invalid"), c.Name)
-			}
-		}
 
-		By("updating MySQLCluster")
+		Expect(replica.Annotations).To(HaveKeyWithValue(corev1.AnnotationTopologyMode, "Auto"))
+		Expect(replica.Annotations).To(HaveKeyWithValue("foo", "bar"))
+		Expect(replica.Spec.Type).To(Equal(corev1.ServiceTypeLoadBalancer))
+		Expect(replica.Spec.Selector).To(HaveKeyWithValue(constants.LabelMocoRole, constants.RoleReplica))
+		Expect(replica.Spec.Ports).To(HaveLen(2))
+
+		primary := &corev1.Service{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(primary.Annotations).NotTo(HaveKey(corev1.AnnotationTopologyMode))
+
 		cluster = &mocov1beta2.MySQLCluster{}
 		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
 		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.TopologyAwareReplicaService = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
-		cluster.Spec.Replicas = 5
-		cluster.Spec.ReplicationSourceSecretName = nil
-		cluster.Spec.Collectors = []string{"engine_innodb_status", "info_schema.innodb_metrics"}
-		cluster.Spec.MaxDelaySeconds = ptr.To[int](20)
-		cluster.Spec.StartupWaitSeconds = 3
-		cluster.Spec.LogRotationSchedule = "0 * * * *"
-		cluster.Spec.DisableSlowQueryLogContainer = true
-		cluster.Spec.PodTemplate.OverwriteContainers = []mocov1beta2.OverwriteContainer{
-			{
-				Name: mocov1beta2.AgentContainerName,
-				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
-					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}).
-					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}),
-				),
-			},
-			{
-				Name: mocov1beta2.ExporterContainerName,
-				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
-					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}).
-					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}),
-				),
-			},
-			{
-				Name: mocov1beta2.InitContainerName,
-				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
-					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}).
-					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}),
-				),
-			},
-		}
+		Eventually(func() error {
+			replica = &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-replica"}, replica); err != nil {
+				return err
+			}
+			if _, ok := replica.Annotations[corev1.AnnotationTopologyMode]; ok {
+				return errors.New("service still has the topology mode annotation")
+			}
+			return nil
+		}).Should(Succeed())
+	})
 
-		podSpec := corev1ac.PodSpec().
-			WithTerminationGracePeriodSeconds(512).
-			WithPriorityClassName("hoge").
-			WithContainers(corev1ac.Container().WithName("dummy").WithImage("dummy:latest")).
-			WithInitContainers(corev1ac.Container().WithName("init-dummy").WithImage("init-dummy:latest").
-				WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true))).
-			WithVolumes(corev1ac.Volume().WithName("dummy-vol").WithEmptyDir(corev1ac.EmptyDirVolumeSource())).
-			WithSecurityContext(corev1ac.PodSecurityContext().WithFSGroup(123)).
-			WithAffinity(corev1ac.Affinity().
-				WithPodAntiAffinity(corev1ac.PodAntiAffinity().
-					WithRequiredDuringSchedulingIgnoredDuringExecution(corev1ac.PodAffinityTerm().
-						WithLabelSelector(metav1ac.LabelSelector().
-							WithMatchExpressions(metav1ac.LabelSelectorRequirement().
-								WithKey(constants.LabelAppName).
-								WithOperator(metav1.LabelSelectorOpIn).
-								WithValues(constants.AppNameMySQL),
-							).
-							WithMatchExpressions(metav1ac.LabelSelectorRequirement().
-								WithKey(constants.LabelAppInstance).
-								WithOperator(metav1.LabelSelectorOpIn).
-								WithValues(cluster.Name),
-							),
-						),
-					),
-				))
+	It("should override the entrypoint of mysqld container", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MysqldCommand = []string{"/usr/local/bin/wrapper.sh", "mysqld"}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
-		for _, c := range cluster.Spec.PodTemplate.Spec.Containers {
-			switch *c.Name {
-			case constants.MysqldContainerName:
-				c.WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true)).
-					WithLivenessProbe(corev1ac.Probe().
-						WithTerminationGracePeriodSeconds(int64(200)).
-						WithHTTPGet(corev1ac.HTTPGetAction().
-							WithPath("/healthz").
-							WithPort(intstr.FromString(constants.MySQLHealthPortName)).
-							WithScheme(corev1.URISchemeHTTP)))
+		var mysqld *corev1.Container
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return err
 			}
-			podSpec.WithContainers(&c)
-		}
+			for i, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.MysqldContainerName {
+					mysqld = &sts.Spec.Template.Spec.Containers[i]
+					return nil
+				}
+			}
+			return errors.New("mysqld container not found")
+		}).Should(Succeed())
 
-		cluster.Spec.PodTemplate.Spec = mocov1beta2.PodSpecApplyConfiguration(*podSpec)
+		Expect(mysqld.Command).To(Equal([]string{"/usr/local/bin/wrapper.sh", "mysqld"}))
+		Expect(mysqld.Args).To(Equal([]string{"--defaults-file=" + filepath.Join(constants.MySQLConfPath, constants.MySQLConfName)}))
+	})
+
+	It("should derive the termination grace period from the buffer pool size", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.GracePeriodPerGiB = time.Minute
+			r.MaxGracePeriod = time.Hour
+		})
 
 		userCM := &corev1.ConfigMap{}
 		userCM.Namespace = "test"
 		userCM.Name = "user-conf"
 		userCM.Data = map[string]string{
-			constants.LowerCaseTableNamesConfKey: "1",
+			"innodb_buffer_pool_size": "8G",
 		}
-
-		err = k8sClient.Create(ctx, userCM)
+		err := k8sClient.Create(ctx, userCM)
 		Expect(err).NotTo(HaveOccurred())
 
+		cluster := testNewMySQLCluster("test")
 		cluster.Spec.MySQLConfigMapName = ptr.To[string](userCM.Name)
-
-		err = k8sClient.Update(ctx, cluster)
+		err = k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
-		Eventually(func() error {
-			c := &mocov1beta2.MySQLCluster{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
-				return err
-			}
-			if c.Status.ReconcileInfo.Generation != c.Generation {
-				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
+		Eventually(func() *int64 {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return nil
 			}
-			return nil
-		}).Should(Succeed())
+			return sts.Spec.Template.Spec.TerminationGracePeriodSeconds
+		}).Should(Equal(ptr.To[int64](480)))
 
-		sts = &appsv1.StatefulSet{}
-		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+		By("capping the derived value")
+		userCM.Data["innodb_buffer_pool_size"] = "100G"
+		err = k8sClient.Update(ctx, userCM)
 		Expect(err).NotTo(HaveOccurred())
 
-		Expect(sts.Spec.Replicas).NotTo(BeNil())
-		Expect(*sts.Spec.Replicas).To(Equal(cluster.Spec.Replicas))
-		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).NotTo(BeNil())
-		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNumerically("==", 512))
-		Expect(sts.Spec.Template.Spec.PriorityClassName).To(Equal("hoge"))
-		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(int64(123)))
-		Expect(sts.Spec.Template.Spec.Affinity).NotTo(BeNil())
-		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
-		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).NotTo(BeNil())
-		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeNil())
-
-		foundDummyContainer := false
-		for _, c := range sts.Spec.Template.Spec.Containers {
-			Expect(c.Name).NotTo(Equal(constants.SlowQueryLogAgentContainerName))
-			Expect(c.SecurityContext).NotTo(BeNil())
-			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
-			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
-			switch c.Name {
-			case constants.MysqldContainerName:
-				Expect(c.StartupProbe).NotTo(BeNil())
-				Expect(c.StartupProbe.FailureThreshold).To(Equal(int32(1)))
-				Expect(c.LivenessProbe).NotTo(BeNil())
-				Expect(c.LivenessProbe.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](200)))
-				Expect(c.SecurityContext.ReadOnlyRootFilesystem).NotTo(BeNil())
-				Expect(*c.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
-			case constants.AgentContainerName:
-				Expect(c.Args).To(ContainElement("20s"))
-				Expect(c.Args).To(ContainElement("0 * * * *"))
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}))
-			case constants.ExporterContainerName:
-				foundExporter = true
-				Expect(c.Image).To(Equal(testExporterImage))
-				Expect(c.Args).To(HaveLen(3))
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}))
-			case "dummy":
-				foundDummyContainer = true
-				Expect(c.Image).To(Equal("dummy:latest"))
-				Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(BeNil())
-			}
-		}
-		Expect(foundExporter).To(BeTrue())
-		Expect(foundDummyContainer).To(BeTrue())
-
-		foundInitDummyContainer := false
-		for _, c := range sts.Spec.Template.Spec.InitContainers {
-			Expect(c.SecurityContext).NotTo(BeNil())
-			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
-			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
-			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
-			switch c.Name {
-			case constants.InitContainerName:
-				Expect(c.Args).To(ContainElement(fmt.Sprintf("%s=1", constants.MocoInitLowerCaseTableNamesFlag)))
-				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}))
-				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}))
-			case "init-dummy":
-				foundInitDummyContainer = true
-				Expect(c.Image).To(Equal("init-dummy:latest"))
-				Expect(c.SecurityContext.ReadOnlyRootFilesystem).NotTo(BeNil())
-				Expect(*c.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
-			}
-		}
-		Expect(foundInitDummyContainer).To(BeTrue())
-
-		foundDummyVolume := false
-		for _, v := range sts.Spec.Template.Spec.Volumes {
-			switch v.Name {
-			case "dummy-vol":
-				foundDummyVolume = true
+		Eventually(func() *int64 {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return nil
 			}
-		}
-		Expect(foundDummyVolume).To(BeTrue())
+			return sts.Spec.Template.Spec.TerminationGracePeriodSeconds
+		}).Should(Equal(ptr.To[int64](3600)))
 
-		By("updating MySQLCluster (MaxDelaySeconds=0)")
+		By("respecting the value in the Pod template")
 		cluster = &mocov1beta2.MySQLCluster{}
 		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
 		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PodTemplate.Spec.TerminationGracePeriodSeconds = ptr.To[int64](100)
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
-		cluster.Spec.MaxDelaySeconds = ptr.To[int](0)
+		Eventually(func() *int64 {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return nil
+			}
+			return sts.Spec.Template.Spec.TerminationGracePeriodSeconds
+		}).Should(Equal(ptr.To[int64](100)))
+	})
 
-		err = k8sClient.Update(ctx, cluster)
+	It("should use the configured role label key for new clusters", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.RoleLabelKey = "example.com/role"
+		})
+
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
+		var primary, replica *corev1.Service
 		Eventually(func() error {
-			c := &mocov1beta2.MySQLCluster{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+			primary = &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary); err != nil {
 				return err
 			}
-			if c.Status.ReconcileInfo.Generation != c.Generation {
-				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
+			replica = &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-replica"}, replica); err != nil {
+				return err
 			}
 			return nil
 		}).Should(Succeed())
 
-		sts = &appsv1.StatefulSet{}
-		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
-		Expect(err).NotTo(HaveOccurred())
+		Expect(primary.Spec.Selector).To(HaveKeyWithValue("example.com/role", "primary"))
+		Expect(primary.Spec.Selector).NotTo(HaveKey(constants.LabelMocoRole))
+		Expect(replica.Spec.Selector).To(HaveKeyWithValue("example.com/role", "replica"))
+		Expect(replica.Spec.Selector).NotTo(HaveKey(constants.LabelMocoRole))
 
-		for _, c := range sts.Spec.Template.Spec.Containers {
-			switch c.Name {
-			case constants.AgentContainerName:
-				Expect(c.Args).To(ContainElement("0s"))
-			}
-		}
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Annotations).To(HaveKeyWithValue(constants.AnnRoleLabelKey, "example.com/role"))
+		Expect(cluster.RoleLabelKey()).To(Equal("example.com/role"))
 	})
 
-	It("should reconcile a pod disruption budget", func() {
+	It("should not change the role label key of existing clusters", func() {
 		cluster := testNewMySQLCluster("test")
 		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
@@ -1063,440 +1966,3381 @@ var _ = Describe("MySQLCluster reconciler", func() {
 			return nil
 		}).Should(Succeed())
 
-		var pdb *policyv1.PodDisruptionBudget
-		Eventually(func() error {
-			pdb = &policyv1.PodDisruptionBudget{}
-			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
-		}).Should(Succeed())
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.RoleLabelKey = "example.com/role"
+		})
+
+		Consistently(func() error {
+			primary := &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test-primary"}, primary); err != nil {
+				return err
+			}
+			if primary.Spec.Selector[constants.LabelMocoRole] != constants.RolePrimary {
+				return fmt.Errorf("the selector of the primary service has been changed: %v", primary.Spec.Selector)
+			}
+			return nil
+		}, 3*time.Second).Should(Succeed())
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cluster.Annotations).NotTo(HaveKey(constants.AnnRoleLabelKey))
+	})
+
+	It("should reconcile statefulset", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.ReplicationSourceSecretName = ptr.To[string]("source-secret")
+		cluster.Spec.PodTemplate.Annotations = map[string]string{"foo": "bar"}
+		cluster.Spec.PodTemplate.Labels = map[string]string{"foo": "baz"}
+
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return err
+			}
+
+			cluster = &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
+				return fmt.Errorf("status is not updated")
+			}
+
+			return nil
+		}).Should(Succeed())
+
+		By("checking new statefulset")
+		Expect(sts.OwnerReferences).NotTo(BeEmpty())
+		Expect(sts.Spec.Template.Annotations).To(HaveKeyWithValue("foo", "bar"))
+		Expect(sts.Spec.Template.Labels).To(HaveKeyWithValue("foo", "baz"))
+		Expect(sts.Spec.Replicas).NotTo(BeNil())
+		Expect(*sts.Spec.Replicas).To(Equal(cluster.Spec.Replicas))
+		Expect(sts.Spec.RevisionHistoryLimit).NotTo(BeNil())
+		Expect(*sts.Spec.RevisionHistoryLimit).To(BeNumerically("==", defaultRevisionHistoryLimit))
+		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).NotTo(BeNil())
+		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNumerically("==", defaultTerminationGracePeriodSeconds))
+		Expect(sts.Spec.Template.Spec.SecurityContext).NotTo(BeNil())
+		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(int64(constants.ContainerGID)))
+		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy).To(Equal(corev1.FSGroupChangeOnRootMismatch))
+		Expect(sts.Spec.Template.Spec.EnableServiceLinks).To(Equal(ptr.To[bool](false)))
+		Expect(sts.Spec.Template.Spec.Affinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).NotTo(BeNil())
+
+		Expect(sts.Spec.Template.Spec.Containers).To(HaveLen(3))
+		foundMysqld := false
+		foundAgent := false
+		foundSlowLogAgent := false
+		foundExporter := false
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			Expect(c.SecurityContext).NotTo(BeNil())
+			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
+			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+			Expect(c.SecurityContext.AllowPrivilegeEscalation).To(Equal(ptr.To[bool](false)))
+			Expect(c.SecurityContext.Capabilities).NotTo(BeNil())
+			Expect(c.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+			switch c.Name {
+			case constants.MysqldContainerName:
+				foundMysqld = true
+				Expect(c.Image).To(Equal("moco-mysql:latest"))
+				Expect(c.StartupProbe).NotTo(BeNil())
+				Expect(c.StartupProbe.FailureThreshold).To(Equal(int32(360)))
+				Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(BeNil())
+				Expect(c.Lifecycle).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop).NotTo(BeNil())
+				Expect(c.Lifecycle.PostStart).To(BeNil())
+			case constants.AgentContainerName:
+				foundAgent = true
+				Expect(c.Image).To(Equal(testAgentImage))
+				Expect(c.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+				Expect(c.Args).To(Equal([]string{"--max-delay", "60s"}))
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
+			case constants.SlowQueryLogAgentContainerName:
+				foundSlowLogAgent = true
+				Expect(c.Image).To(Equal(testFluentBitImage))
+				Expect(c.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+				Expect(c.Lifecycle).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop.Exec).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "25"}))
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("20Mi")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("20Mi")}))
+			case constants.ExporterContainerName:
+				foundExporter = true
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("100Mi")}))
+			}
+		}
+		Expect(foundMysqld).To(BeTrue())
+		Expect(foundAgent).To(BeTrue())
+		Expect(foundSlowLogAgent).To(BeTrue())
+		Expect(foundExporter).To(BeFalse())
+
+		Expect(sts.Spec.Template.Spec.InitContainers).To(HaveLen(2))
+
+		cpInitContainer := &sts.Spec.Template.Spec.InitContainers[0]
+		Expect(cpInitContainer.Name).To(Equal(constants.CopyInitContainerName))
+		Expect(cpInitContainer.Image).To(Equal(testAgentImage))
+		Expect(cpInitContainer.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		Expect(cpInitContainer.Command).To(ContainElement("cp"))
+		Expect(cpInitContainer.SecurityContext).NotTo(BeNil())
+		Expect(cpInitContainer.SecurityContext.RunAsUser).NotTo(BeNil())
+		Expect(*cpInitContainer.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
+		Expect(cpInitContainer.SecurityContext.RunAsGroup).NotTo(BeNil())
+		Expect(*cpInitContainer.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+		Expect(cpInitContainer.SecurityContext.AllowPrivilegeEscalation).To(Equal(ptr.To[bool](false)))
+		Expect(cpInitContainer.SecurityContext.Capabilities).NotTo(BeNil())
+		Expect(cpInitContainer.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+
+		initContainer := &sts.Spec.Template.Spec.InitContainers[1]
+		Expect(initContainer.Name).To(Equal(constants.InitContainerName))
+		Expect(initContainer.Image).To(Equal("moco-mysql:latest"))
+		Expect(initContainer.Command).To(ContainElement(fmt.Sprintf("%d", cluster.Spec.ServerIDBase)))
+		Expect(initContainer.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("300Mi")}))
+		Expect(initContainer.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m"), corev1.ResourceMemory: resource.MustParse("300Mi")}))
+		Expect(initContainer.SecurityContext).NotTo(BeNil())
+		Expect(initContainer.SecurityContext.RunAsUser).NotTo(BeNil())
+		Expect(*initContainer.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
+		Expect(initContainer.SecurityContext.RunAsGroup).NotTo(BeNil())
+		Expect(*initContainer.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+		Expect(initContainer.SecurityContext.AllowPrivilegeEscalation).To(Equal(ptr.To[bool](false)))
+		Expect(initContainer.SecurityContext.Capabilities).NotTo(BeNil())
+		Expect(initContainer.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Name).To(Equal(constants.MySQLDataVolumeName))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Labels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameMySQL))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Labels).To(HaveKeyWithValue(constants.LabelAppInstance, cluster.Name))
+		Expect(sts.Spec.VolumeClaimTemplates[0].Labels).To(HaveKeyWithValue(constants.LabelAppCreatedBy, constants.AppCreator))
+
+		foundUserSecret := false
+		foundMyCnfConfig := false
+		foundSlowLogConfig := false
+		for _, v := range sts.Spec.Template.Spec.Volumes {
+			switch v.Name {
+			case constants.MySQLConfSecretVolumeName:
+				foundUserSecret = true
+			case constants.MySQLConfVolumeName:
+				foundMyCnfConfig = true
+			case constants.SlowQueryLogAgentConfigVolumeName:
+				foundSlowLogConfig = true
+			}
+		}
+		Expect(foundUserSecret).To(BeTrue())
+		Expect(foundMyCnfConfig).To(BeTrue())
+		Expect(foundSlowLogConfig).To(BeTrue())
+
+		By("editing statefulset")
+		// Sleep before editing statefulset to avoid slow query agent container from being restored by the controller
+		time.Sleep(1 * time.Second)
+
+		for i, c := range sts.Spec.Template.Spec.Containers {
+			switch c.Name {
+			case constants.AgentContainerName, constants.SlowQueryLogAgentContainerName:
+				sts.Spec.Template.Spec.Containers[i].Image = "invalid"
+			}
+		}
+		err = k8sClient.Update(ctx, sts)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+			if err != nil {
+				return err
+			}
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name != constants.AgentContainerName {
+					continue
+				}
+				if c.Image != testAgentImage {
+					return errors.New("c.Image is not reconciled yet")
+				}
+			}
+			return nil
+		}).Should(Succeed())
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			switch c.Name {
+			case constants.SlowQueryLogAgentContainerName:
+				Expect(c.Image).To(Equal("invalid"), c.Name)
+			}
+		}
+
+		By("updating MySQLCluster")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Spec.Replicas = 5
+		cluster.Spec.ReplicationSourceSecretName = nil
+		cluster.Spec.Collectors = []string{"engine_innodb_status", "info_schema.innodb_metrics"}
+		cluster.Spec.MaxDelaySeconds = ptr.To[int](20)
+		cluster.Spec.StartupWaitSeconds = 3
+		cluster.Spec.LogRotationSchedule = "0 * * * *"
+		cluster.Spec.DisableSlowQueryLogContainer = true
+		cluster.Spec.WarmUp = &mocov1beta2.WarmUpSpec{}
+		cluster.Spec.RevisionHistoryLimit = ptr.To[int32](5)
+		cluster.Spec.PodTemplate.OverwriteContainers = []mocov1beta2.OverwriteContainer{
+			{
+				Name: mocov1beta2.AgentContainerName,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}).
+					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}),
+				),
+				SecurityContext: (*mocov1beta2.SecurityContextApplyConfiguration)(corev1ac.SecurityContext().
+					WithCapabilities(corev1ac.Capabilities().WithAdd("NET_BIND_SERVICE")),
+				),
+			},
+			{
+				Name: mocov1beta2.ExporterContainerName,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}).
+					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}),
+				),
+				ImagePullPolicy: corev1.PullNever,
+			},
+			{
+				Name: mocov1beta2.InitContainerName,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithLimits(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}).
+					WithRequests(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}),
+				),
+			},
+		}
+
+		podSpec := corev1ac.PodSpec().
+			WithTerminationGracePeriodSeconds(512).
+			WithPriorityClassName("hoge").
+			WithRuntimeClassName("gvisor").
+			WithEnableServiceLinks(true).
+			WithContainers(corev1ac.Container().WithName("dummy").WithImage("dummy:latest")).
+			WithInitContainers(corev1ac.Container().WithName("init-dummy").WithImage("init-dummy:latest").
+				WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true))).
+			WithVolumes(corev1ac.Volume().WithName("dummy-vol").WithEmptyDir(corev1ac.EmptyDirVolumeSource())).
+			WithSecurityContext(corev1ac.PodSecurityContext().WithFSGroup(123).WithFSGroupChangePolicy(corev1.FSGroupChangeAlways)).
+			WithAffinity(corev1ac.Affinity().
+				WithPodAntiAffinity(corev1ac.PodAntiAffinity().
+					WithRequiredDuringSchedulingIgnoredDuringExecution(corev1ac.PodAffinityTerm().
+						WithLabelSelector(metav1ac.LabelSelector().
+							WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+								WithKey(constants.LabelAppName).
+								WithOperator(metav1.LabelSelectorOpIn).
+								WithValues(constants.AppNameMySQL),
+							).
+							WithMatchExpressions(metav1ac.LabelSelectorRequirement().
+								WithKey(constants.LabelAppInstance).
+								WithOperator(metav1.LabelSelectorOpIn).
+								WithValues(cluster.Name),
+							),
+						),
+					),
+				))
+
+		for _, c := range cluster.Spec.PodTemplate.Spec.Containers {
+			switch *c.Name {
+			case constants.MysqldContainerName:
+				c.WithSecurityContext(corev1ac.SecurityContext().WithReadOnlyRootFilesystem(true)).
+					WithLivenessProbe(corev1ac.Probe().
+						WithTerminationGracePeriodSeconds(int64(200)).
+						WithHTTPGet(corev1ac.HTTPGetAction().
+							WithPath("/healthz").
+							WithPort(intstr.FromString(constants.MySQLHealthPortName)).
+							WithScheme(corev1.URISchemeHTTP)))
+			}
+			podSpec.WithContainers(&c)
+		}
+
+		cluster.Spec.PodTemplate.Spec = mocov1beta2.PodSpecApplyConfiguration(*podSpec)
+
+		userCM := &corev1.ConfigMap{}
+		userCM.Namespace = "test"
+		userCM.Name = "user-conf"
+		userCM.Data = map[string]string{
+			constants.LowerCaseTableNamesConfKey: "1",
+		}
+
+		err = k8sClient.Create(ctx, userCM)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Spec.MySQLConfigMapName = ptr.To[string](userCM.Name)
+
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.ReconcileInfo.Generation != c.Generation {
+				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
+			}
+			return nil
+		}).Should(Succeed())
+
+		sts = &appsv1.StatefulSet{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(sts.Spec.Replicas).NotTo(BeNil())
+		Expect(*sts.Spec.Replicas).To(Equal(cluster.Spec.Replicas))
+		Expect(sts.Spec.RevisionHistoryLimit).NotTo(BeNil())
+		Expect(*sts.Spec.RevisionHistoryLimit).To(BeNumerically("==", 5))
+		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).NotTo(BeNil())
+		Expect(*sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(BeNumerically("==", 512))
+		Expect(sts.Spec.Template.Spec.PriorityClassName).To(Equal("hoge"))
+		Expect(sts.Spec.Template.Spec.RuntimeClassName).To(Equal(ptr.To("gvisor")))
+		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroup).To(Equal(int64(123)))
+		Expect(*sts.Spec.Template.Spec.SecurityContext.FSGroupChangePolicy).To(Equal(corev1.FSGroupChangeAlways))
+		Expect(sts.Spec.Template.Spec.EnableServiceLinks).To(Equal(ptr.To[bool](true)))
+		Expect(sts.Spec.Template.Spec.Affinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.RequiredDuringSchedulingIgnoredDuringExecution).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(BeNil())
+
+		foundDummyContainer := false
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			Expect(c.Name).NotTo(Equal(constants.SlowQueryLogAgentContainerName))
+			Expect(c.SecurityContext).NotTo(BeNil())
+			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
+			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+			switch c.Name {
+			case constants.MysqldContainerName:
+				Expect(c.StartupProbe).NotTo(BeNil())
+				Expect(c.StartupProbe.FailureThreshold).To(Equal(int32(1)))
+				Expect(c.LivenessProbe).NotTo(BeNil())
+				Expect(c.LivenessProbe.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](200)))
+				Expect(c.SecurityContext.ReadOnlyRootFilesystem).NotTo(BeNil())
+				Expect(*c.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+				Expect(c.Lifecycle).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop).NotTo(BeNil())
+				Expect(c.Lifecycle.PostStart).NotTo(BeNil())
+				Expect(c.Lifecycle.PostStart.Exec).NotTo(BeNil())
+				Expect(c.Lifecycle.PostStart.Exec.Command).To(Equal(defaultWarmUpCommand(cluster)))
+			case constants.AgentContainerName:
+				Expect(c.Args).To(ContainElement("20s"))
+				Expect(c.ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+				Expect(c.SecurityContext.AllowPrivilegeEscalation).To(Equal(ptr.To[bool](false)))
+				Expect(c.SecurityContext.Capabilities).NotTo(BeNil())
+				Expect(c.SecurityContext.Capabilities.Add).To(Equal([]corev1.Capability{"NET_BIND_SERVICE"}))
+				Expect(c.SecurityContext.Capabilities.Drop).To(Equal([]corev1.Capability{"ALL"}))
+				Expect(c.Args).To(ContainElement("0 * * * *"))
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("100m")}))
+			case constants.ExporterContainerName:
+				foundExporter = true
+				Expect(c.Image).To(Equal(testExporterImage))
+				Expect(c.Args).To(HaveLen(3))
+				Expect(c.ImagePullPolicy).To(Equal(corev1.PullNever))
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m")}))
+			case "dummy":
+				foundDummyContainer = true
+				Expect(c.Image).To(Equal("dummy:latest"))
+				Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(BeNil())
+			}
+		}
+		Expect(foundExporter).To(BeTrue())
+		Expect(foundDummyContainer).To(BeTrue())
+
+		foundInitDummyContainer := false
+		for _, c := range sts.Spec.Template.Spec.InitContainers {
+			Expect(c.SecurityContext).NotTo(BeNil())
+			Expect(c.SecurityContext.RunAsUser).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsUser).To(Equal(int64(constants.ContainerUID)))
+			Expect(c.SecurityContext.RunAsGroup).NotTo(BeNil())
+			Expect(*c.SecurityContext.RunAsGroup).To(Equal(int64(constants.ContainerGID)))
+			switch c.Name {
+			case constants.InitContainerName:
+				Expect(c.Args).To(ContainElement(fmt.Sprintf("%s=1", constants.MocoInitLowerCaseTableNamesFlag)))
+				Expect(c.Resources.Requests).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}))
+				Expect(c.Resources.Limits).To(Equal(corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m")}))
+			case "init-dummy":
+				foundInitDummyContainer = true
+				Expect(c.Image).To(Equal("init-dummy:latest"))
+				Expect(c.SecurityContext.ReadOnlyRootFilesystem).NotTo(BeNil())
+				Expect(*c.SecurityContext.ReadOnlyRootFilesystem).To(BeTrue())
+			}
+		}
+		Expect(foundInitDummyContainer).To(BeTrue())
+
+		foundDummyVolume := false
+		for _, v := range sts.Spec.Template.Spec.Volumes {
+			switch v.Name {
+			case "dummy-vol":
+				foundDummyVolume = true
+			}
+		}
+		Expect(foundDummyVolume).To(BeTrue())
+
+		By("updating MySQLCluster (MaxDelaySeconds=0)")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster.Spec.MaxDelaySeconds = ptr.To[int](0)
+
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.ReconcileInfo.Generation != c.Generation {
+				return fmt.Errorf("not yet reconciled: generation=%d", c.Status.ReconcileInfo.Generation)
+			}
+			return nil
+		}).Should(Succeed())
+
+		sts = &appsv1.StatefulSet{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			switch c.Name {
+			case constants.AgentContainerName:
+				Expect(c.Args).To(ContainElement("0s"))
+			}
+		}
+	})
+
+	It("should reconcile a pod disruption budget", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cluster = &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
+				return fmt.Errorf("not yet reconciled")
+			}
+			return nil
+		}).Should(Succeed())
+
+		var pdb *policyv1.PodDisruptionBudget
+		Eventually(func() error {
+			pdb = &policyv1.PodDisruptionBudget{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+		}).Should(Succeed())
+
+		Expect(pdb.Spec.MaxUnavailable).NotTo(BeNil())
+		Expect(pdb.Spec.MaxUnavailable.IntVal).To(Equal(int32(1)))
+
+		Eventually(func() error {
+			cluster = &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
+				return fmt.Errorf("not yet reconciled")
+			}
+			return nil
+		}).Should(Succeed())
+		cluster.Spec.Replicas = 1
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			pdb = &policyv1.PodDisruptionBudget{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should not create a pod disruption budget if disabled", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			pdb := &policyv1.PodDisruptionBudget{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+		}).Should(Succeed())
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.DisablePodDisruptionBudget = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			pdb := &policyv1.PodDisruptionBudget{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		Consistently(func() bool {
+			pdb := &policyv1.PodDisruptionBudget{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+			return apierrors.IsNotFound(err)
+		}, 5).Should(BeTrue())
+	})
+
+	It("should compute maxUnavailable of the PDB from the zones of the Pods", func() {
+		zones := []string{"zone-a", "zone-b", "zone-c", "zone-d", "zone-e"}
+		for i, zone := range zones {
+			node := &corev1.Node{}
+			node.Name = fmt.Sprintf("node-%d", i)
+			node.Labels = map[string]string{corev1.LabelTopologyZone: zone}
+			err := k8sClient.Create(ctx, node)
+			Expect(err).NotTo(HaveOccurred())
+			defer func() {
+				err := k8sClient.Delete(ctx, node)
+				Expect(err).NotTo(HaveOccurred())
+			}()
+		}
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Replicas = 5
+		cluster.Spec.ZoneAwarePodDisruptionBudget = true
+		for i := range zones {
+			pod := &corev1.Pod{}
+			pod.Namespace = "test"
+			pod.Name = cluster.PodName(i)
+			pod.Labels = labelSet(cluster, false)
+			pod.Spec.NodeName = fmt.Sprintf("node-%d", i)
+			pod.Spec.Containers = []corev1.Container{{Name: "mysqld", Image: "moco-mysql:latest"}}
+			err := k8sClient.Create(ctx, pod)
+			Expect(err).NotTo(HaveOccurred())
+		}
+		defer func() {
+			err := k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("test"), client.GracePeriodSeconds(0))
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var pdb *policyv1.PodDisruptionBudget
+		Eventually(func() error {
+			pdb = &policyv1.PodDisruptionBudget{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+		}).Should(Succeed())
+		Expect(pdb.Spec.MaxUnavailable).To(HaveValue(Equal(intstr.FromInt(1))))
+
+		By("disabling the zone-aware PDB")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ZoneAwarePodDisruptionBudget = false
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			pdb := &policyv1.PodDisruptionBudget{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb); err != nil {
+				return err
+			}
+			if pdb.Spec.MaxUnavailable.IntValue() != 2 {
+				return fmt.Errorf("PDB is not updated: %s", pdb.Spec.MaxUnavailable.String())
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should reconcile a pod disruption budget for 2 replicas", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Replicas = 2
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cluster = &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
+				return fmt.Errorf("not yet reconciled")
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("checking no PDB is created by default")
+		Consistently(func() bool {
+			pdb := &policyv1.PodDisruptionBudget{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+			return apierrors.IsNotFound(err)
+		}, 3*time.Second).Should(BeTrue())
+
+		By("restarting the controller with PDBForTwoReplicas")
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.PDBForTwoReplicas = true
+		})
+
+		var pdb *policyv1.PodDisruptionBudget
+		Eventually(func() error {
+			pdb = &policyv1.PodDisruptionBudget{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
+		}).Should(Succeed())
+
+		Expect(pdb.Spec.MaxUnavailable).NotTo(BeNil())
+		Expect(pdb.Spec.MaxUnavailable.IntVal).To(Equal(int32(1)))
+	})
+
+	It("should not add the default anti-affinity if disabled", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+		Expect(sts.Spec.Template.Spec.Affinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.Affinity.PodAntiAffinity.PreferredDuringSchedulingIgnoredDuringExecution).To(HaveLen(1))
+
+		By("restarting the controller with DisableDefaultAntiAffinity")
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.DisableDefaultAntiAffinity = true
+		})
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.Template.Spec.Affinity != nil {
+				return errors.New("affinity is not removed")
+			}
+			return nil
+		}).Should(Succeed())
+
+		generation := sts.Generation
+		Consistently(func() int64 {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0
+			}
+			return sts.Generation
+		}, 3*time.Second).Should(Equal(generation))
+	})
+
+	It("should add the configured DNS options to the pods", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.MySQLDNSOptions = map[string]string{
+				"ndots":  "2",
+				"use-vc": "",
+			}
+		})
+
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+		Expect(sts.Spec.Template.Spec.DNSConfig).NotTo(BeNil())
+		Expect(sts.Spec.Template.Spec.DNSConfig.Options).To(Equal([]corev1.PodDNSConfigOption{
+			{Name: "ndots", Value: ptr.To("2")},
+			{Name: "use-vc"},
+		}))
+
+		generation := sts.Generation
+		Consistently(func() int64 {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0
+			}
+			return sts.Generation
+		}, 3*time.Second).Should(Equal(generation))
+
+		By("overriding the options with the Pod template")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PodTemplate.Spec.DNSConfig = corev1ac.PodDNSConfig().
+			WithOptions(corev1ac.PodDNSConfigOption().WithName("ndots").WithValue("1"))
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			sts = &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(sts.Spec.Template.Spec.DNSConfig).NotTo(BeNil())
+			g.Expect(sts.Spec.Template.Spec.DNSConfig.Options).To(Equal([]corev1.PodDNSConfigOption{
+				{Name: "ndots", Value: ptr.To("1")},
+			}))
+		}).Should(Succeed())
+	})
+
+	It("should add annotations for Stakater Reloader if enabled", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.ReloaderAnnotations = true
+		})
+
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, c); err != nil {
+				return err
+			}
+			if c.Status.MyCnfConfigMapName == "" {
+				return errors.New("my.cnf ConfigMap name is not recorded")
+			}
+			cluster = c
+			return nil
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if v := sts.Annotations[constants.AnnReloaderConfigMaps]; v != cluster.Status.MyCnfConfigMapName {
+				return fmt.Errorf("unexpected %s annotation: %s", constants.AnnReloaderConfigMaps, v)
+			}
+			if v := sts.Annotations[constants.AnnReloaderSecrets]; v != cluster.MyCnfSecretName() {
+				return fmt.Errorf("unexpected %s annotation: %s", constants.AnnReloaderSecrets, v)
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("disabling the annotations")
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager()
+
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if _, ok := sts.Annotations[constants.AnnReloaderConfigMaps]; ok {
+				return errors.New("annotation for Reloader is not removed")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should apply labels and annotations to volume claim templates", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.VolumeClaimTemplates[0].ObjectMeta.Labels = map[string]string{"foo": "bar"}
+		cluster.Spec.VolumeClaimTemplates[0].ObjectMeta.Annotations = map[string]string{"backup.example.com/enabled": "true"}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		Expect(sts.Spec.VolumeClaimTemplates).To(HaveLen(1))
+		pvc := sts.Spec.VolumeClaimTemplates[0]
+		Expect(pvc.Labels).To(HaveKeyWithValue("foo", "bar"))
+		Expect(pvc.Labels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameMySQL))
+		Expect(pvc.Labels).To(HaveKeyWithValue(constants.LabelAppInstance, cluster.Name))
+		Expect(pvc.Annotations).To(HaveKeyWithValue("backup.example.com/enabled", "true"))
+	})
+
+	It("should set the start ordinal of StatefulSet if supported", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.StatefulSetOrdinals = true
+		})
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Ordinals = &mocov1beta2.OrdinalsSpec{Start: 3}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.Ordinals == nil {
+				return errors.New("ordinals is not set")
+			}
+			if sts.Spec.Ordinals.Start != 3 {
+				return fmt.Errorf("unexpected start ordinal: %d", sts.Spec.Ordinals.Start)
+			}
+			return nil
+		}).Should(Succeed())
+
+		Expect(cluster.PodName(0)).To(Equal("moco-test-3"))
+		index, err := cluster.PodIndex("moco-test-4")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(index).To(Equal(1))
+	})
+
+	It("should set the PVC retention policy of StatefulSet if supported", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.PVCRetentionPolicy = true
+		})
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PersistentVolumeClaimRetentionPolicy = &mocov1beta2.PersistentVolumeClaimRetentionPolicy{
+			WhenDeleted: mocov1beta2.PersistentVolumeClaimRetain,
+			WhenScaled:  mocov1beta2.PersistentVolumeClaimRetain,
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.PersistentVolumeClaimRetentionPolicy == nil {
+				return errors.New("persistentVolumeClaimRetentionPolicy is not set")
+			}
+			return nil
+		}).Should(Succeed())
+
+		Expect(sts.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted).To(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType))
+		Expect(sts.Spec.PersistentVolumeClaimRetentionPolicy.WhenScaled).To(Equal(appsv1.RetainPersistentVolumeClaimRetentionPolicyType))
+		for _, pvc := range sts.Spec.VolumeClaimTemplates {
+			Expect(pvc.OwnerReferences).To(BeEmpty())
+		}
+
+		By("changing the policy")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted = mocov1beta2.PersistentVolumeClaimDelete
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.PersistentVolumeClaimRetentionPolicy.WhenDeleted != appsv1.DeletePersistentVolumeClaimRetentionPolicyType {
+				return errors.New("persistentVolumeClaimRetentionPolicy is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+
+		// MOCO does not add the owner reference to the volume claim templates, which are immutable.
+		for _, pvc := range sts.Spec.VolumeClaimTemplates {
+			Expect(pvc.OwnerReferences).To(BeEmpty())
+		}
+	})
+
+	It("should own the PVCs by MySQLCluster without the PVC retention policy", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		Expect(sts.Spec.VolumeClaimTemplates).NotTo(BeEmpty())
+		for _, pvc := range sts.Spec.VolumeClaimTemplates {
+			Expect(pvc.OwnerReferences).To(HaveLen(1))
+			Expect(pvc.OwnerReferences[0].UID).To(Equal(cluster.UID))
+		}
+	})
+
+	It("should derive the memory of the agent container from mysqld", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PodTemplate.Spec.Containers[0].WithResources(
+			corev1ac.ResourceRequirements().WithRequests(corev1.ResourceList{
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			}))
+		cluster.Spec.AgentMemoryPercent = ptr.To[int32](25)
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		agentMemory := func() (int64, int64, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0, 0, err
+			}
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.AgentContainerName {
+					return c.Resources.Requests.Memory().Value(), c.Resources.Limits.Memory().Value(), nil
+				}
+			}
+			return 0, 0, errors.New("agent container not found")
+		}
+
+		Eventually(func() error {
+			req, lim, err := agentMemory()
+			if err != nil {
+				return err
+			}
+			if req != 512<<20 || lim != 512<<20 {
+				return fmt.Errorf("unexpected memory of agent: request=%d, limit=%d", req, lim)
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("making the derived value less than the default")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.AgentMemoryPercent = ptr.To[int32](1)
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		defaultMem := resource.MustParse(constants.AgentContainerMemRequest)
+		Eventually(func() error {
+			req, lim, err := agentMemory()
+			if err != nil {
+				return err
+			}
+			if req != defaultMem.Value() || lim != defaultMem.Value() {
+				return fmt.Errorf("unexpected memory of agent: request=%d, limit=%d", req, lim)
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should configure the startup and liveness probes of the agent container", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		agentContainer := func() (*appsv1.StatefulSet, *corev1.Container, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return nil, nil, err
+			}
+			for i, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.AgentContainerName {
+					return sts, &sts.Spec.Template.Spec.Containers[i], nil
+				}
+			}
+			return nil, nil, errors.New("agent container not found")
+		}
+
+		var c *corev1.Container
+		Eventually(func() error {
+			_, c, err = agentContainer()
+			return err
+		}).Should(Succeed())
+		Expect(c.LivenessProbe).To(BeNil())
+		Expect(c.StartupProbe).To(BeNil())
+		Expect(c.ReadinessProbe).To(BeNil())
+
+		By("enabling the probe with the default parameters")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.AgentProbe = &mocov1beta2.AgentProbeSpec{}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts, c, err = agentContainer()
+			if err != nil {
+				return err
+			}
+			if c.LivenessProbe == nil {
+				return errors.New("liveness probe is not set")
+			}
+			return nil
+		}).Should(Succeed())
+		Expect(c.LivenessProbe.TCPSocket).NotTo(BeNil())
+		Expect(c.LivenessProbe.TCPSocket.Port).To(Equal(intstr.FromString(constants.AgentPortName)))
+		Expect(c.LivenessProbe.InitialDelaySeconds).To(BeNumerically("==", 0))
+		Expect(c.LivenessProbe.TimeoutSeconds).To(BeNumerically("==", 5))
+		Expect(c.LivenessProbe.PeriodSeconds).To(BeNumerically("==", 10))
+		Expect(c.LivenessProbe.FailureThreshold).To(BeNumerically("==", 6))
+		Expect(c.StartupProbe).NotTo(BeNil())
+		Expect(c.StartupProbe.TCPSocket).NotTo(BeNil())
+		Expect(c.StartupProbe.TCPSocket.Port).To(Equal(intstr.FromString(constants.AgentPortName)))
+		Expect(c.StartupProbe.TimeoutSeconds).To(BeNumerically("==", 5))
+		Expect(c.StartupProbe.PeriodSeconds).To(BeNumerically("==", 10))
+		Expect(c.StartupProbe.FailureThreshold).To(BeNumerically("==", 360))
+		Expect(c.ReadinessProbe).To(BeNil())
+
+		generation := sts.Generation
+		Consistently(func() int64 {
+			sts, _, err := agentContainer()
+			if err != nil {
+				return 0
+			}
+			return sts.Generation
+		}, 3*time.Second).Should(Equal(generation))
+
+		By("overriding the parameters")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.AgentProbe = &mocov1beta2.AgentProbeSpec{
+			InitialDelaySeconds: 15,
+			TimeoutSeconds:      3,
+			PeriodSeconds:       20,
+			FailureThreshold:    9,
+
+			StartupPeriodSeconds:    30,
+			StartupFailureThreshold: 240,
+		}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			_, c, err = agentContainer()
+			if err != nil {
+				return err
+			}
+			if c.LivenessProbe == nil || c.LivenessProbe.FailureThreshold != 9 {
+				return errors.New("liveness probe is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+		Expect(c.LivenessProbe.InitialDelaySeconds).To(BeNumerically("==", 15))
+		Expect(c.LivenessProbe.TimeoutSeconds).To(BeNumerically("==", 3))
+		Expect(c.LivenessProbe.PeriodSeconds).To(BeNumerically("==", 20))
+		Expect(c.StartupProbe).NotTo(BeNil())
+		Expect(c.StartupProbe.TimeoutSeconds).To(BeNumerically("==", 3))
+		Expect(c.StartupProbe.PeriodSeconds).To(BeNumerically("==", 30))
+		Expect(c.StartupProbe.FailureThreshold).To(BeNumerically("==", 240))
+	})
+
+	It("should make the root filesystem of mysqld read-only", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MysqldReadOnlyRootFilesystem = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		var mysqld *corev1.Container
+		for i, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == constants.MysqldContainerName {
+				mysqld = &sts.Spec.Template.Spec.Containers[i]
+			}
+		}
+		Expect(mysqld).NotTo(BeNil())
+		Expect(mysqld.SecurityContext).NotTo(BeNil())
+		Expect(mysqld.SecurityContext.ReadOnlyRootFilesystem).To(Equal(ptr.To(true)))
+
+		writable := make(map[string]string)
+		for _, vm := range mysqld.VolumeMounts {
+			if !vm.ReadOnly {
+				writable[vm.MountPath] = vm.Name
+			}
+		}
+		Expect(writable).To(HaveKeyWithValue(constants.MySQLDataPath, constants.MySQLDataVolumeName))
+		Expect(writable).To(HaveKeyWithValue(constants.TmpPath, constants.TmpVolumeName))
+		Expect(writable).To(HaveKeyWithValue(constants.RunPath, constants.RunVolumeName))
+		Expect(writable).To(HaveKeyWithValue(constants.LogDirPath, constants.VarLogVolumeName))
+
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == constants.MysqldContainerName {
+				continue
+			}
+			if c.SecurityContext != nil {
+				Expect(c.SecurityContext.ReadOnlyRootFilesystem).To(BeNil(), c.Name)
+			}
+		}
+	})
+
+	It("should override the thresholds of the readiness probe of mysqld", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MysqldReadinessProbe = &mocov1beta2.ProbeThresholdsSpec{
+			SuccessThreshold: ptr.To[int32](2),
+			FailureThreshold: ptr.To[int32](6),
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		mysqldContainer := func() (*corev1.Container, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return nil, err
+			}
+			for i, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.MysqldContainerName {
+					return &sts.Spec.Template.Spec.Containers[i], nil
+				}
+			}
+			return nil, errors.New("no mysqld container")
+		}
+
+		var mysqld *corev1.Container
+		Eventually(func() error {
+			mysqld, err = mysqldContainer()
+			return err
+		}).Should(Succeed())
+		Expect(mysqld.ReadinessProbe).NotTo(BeNil())
+		Expect(mysqld.ReadinessProbe.HTTPGet).NotTo(BeNil())
+		Expect(mysqld.ReadinessProbe.HTTPGet.Path).To(Equal("/readyz"))
+		Expect(mysqld.ReadinessProbe.SuccessThreshold).To(BeNumerically("==", 2))
+		Expect(mysqld.ReadinessProbe.FailureThreshold).To(BeNumerically("==", 6))
+		Expect(mysqld.LivenessProbe).NotTo(BeNil())
+		Expect(mysqld.LivenessProbe.SuccessThreshold).To(BeNumerically("==", 1))
+		Expect(mysqld.LivenessProbe.FailureThreshold).To(BeNumerically("==", 3))
+
+		By("giving a threshold in the pod template")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.PodTemplate.Spec.Containers[0].WithReadinessProbe(corev1ac.Probe().WithFailureThreshold(10))
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			mysqld, err = mysqldContainer()
+			if err != nil {
+				return err
+			}
+			if mysqld.ReadinessProbe == nil || mysqld.ReadinessProbe.FailureThreshold != 10 {
+				return errors.New("readiness probe is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+		Expect(mysqld.ReadinessProbe.SuccessThreshold).To(BeNumerically("==", 2))
+
+		By("removing the overrides")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.MysqldReadinessProbe = nil
+		cluster.Spec.PodTemplate.Spec.Containers[0].ReadinessProbe = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			mysqld, err = mysqldContainer()
+			if err != nil {
+				return err
+			}
+			if mysqld.ReadinessProbe == nil || mysqld.ReadinessProbe.SuccessThreshold != 1 || mysqld.ReadinessProbe.FailureThreshold != 3 {
+				return errors.New("readiness probe does not have the default thresholds")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should not apply StatefulSet if mysqld cannot write to tmpDir with a read-only root filesystem", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MysqldReadOnlyRootFilesystem = true
+		cluster.Spec.TmpDir = "/ro"
+		(*corev1ac.PodSpecApplyConfiguration)(&cluster.Spec.PodTemplate.Spec).WithVolumes(corev1ac.Volume().
+			WithName("ro").
+			WithEmptyDir(corev1ac.EmptyDirVolumeSource()))
+		cluster.Spec.PodTemplate.Spec.Containers[0].WithVolumeMounts(corev1ac.VolumeMount().
+			WithName("ro").
+			WithMountPath("/ro").
+			WithReadOnly(true))
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Consistently(func() error {
+			sts := &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}, 3*time.Second).ShouldNot(Succeed())
+	})
+
+	It("should use spec.dataDir and spec.tmpDir", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.DataDir = "/data/mysql"
+		cluster.Spec.TmpDir = "/data/mysql"
+		cluster.Spec.WarmUp = &mocov1beta2.WarmUpSpec{}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		dataMountPath := func(c corev1.Container) string {
+			for _, m := range c.VolumeMounts {
+				if m.Name == constants.MySQLDataVolumeName {
+					return m.MountPath
+				}
+			}
+			return ""
+		}
+
+		var foundMysqld, foundInit bool
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name != constants.MysqldContainerName {
+				continue
+			}
+			foundMysqld = true
+			Expect(dataMountPath(c)).To(Equal("/data/mysql"))
+			Expect(c.Lifecycle.PostStart.Exec.Command[2]).To(ContainSubstring("/data/mysql/data/ib_buffer_pool"))
+		}
+		for _, c := range sts.Spec.Template.Spec.InitContainers {
+			if c.Name != constants.InitContainerName {
+				continue
+			}
+			foundInit = true
+			Expect(dataMountPath(c)).To(Equal("/data/mysql"))
+			Expect(c.Command).To(ContainElement(constants.MocoInitDataDirFlag + "=/data/mysql"))
+		}
+		Expect(foundMysqld).To(BeTrue())
+		Expect(foundInit).To(BeTrue())
+
+		var cmName string
+		for _, v := range sts.Spec.Template.Spec.Volumes {
+			if v.Name == constants.MySQLConfVolumeName {
+				cmName = v.ConfigMap.Name
+			}
+		}
+		Expect(cmName).NotTo(BeEmpty())
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cmName}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("datadir = /data/mysql/data\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("innodb_tmpdir = /data/mysql\n"))
+		Expect(cm.Data["my.cnf"]).To(ContainSubstring("\ntmpdir = /data/mysql\n"))
+	})
+
+	It("should mount my.cnf at spec.mysqlConfPath", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MySQLConfPath = "/etc/mysql/conf.d/moco.cnf"
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		var mysqld *corev1.Container
+		for i, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == constants.MysqldContainerName {
+				mysqld = &sts.Spec.Template.Spec.Containers[i]
+			}
+		}
+		Expect(mysqld).NotTo(BeNil())
+		Expect(mysqld.Args).To(Equal([]string{"--defaults-file=/etc/mysql/conf.d/moco.cnf"}))
+
+		var found bool
+		for _, m := range mysqld.VolumeMounts {
+			if m.Name != constants.MySQLConfVolumeName {
+				continue
+			}
+			found = true
+			Expect(m.MountPath).To(Equal("/etc/mysql/conf.d/moco.cnf"))
+			Expect(m.SubPath).To(Equal(constants.MySQLConfName))
+		}
+		Expect(found).To(BeTrue())
+	})
+
+	It("should not mount the my.cnf Secret if disableMyCnfSecretVolume is true", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		hasMyCnfSecret := func(sts *appsv1.StatefulSet) (volume, mount bool) {
+			for _, v := range sts.Spec.Template.Spec.Volumes {
+				if v.Name == constants.MySQLConfSecretVolumeName {
+					volume = true
+				}
+			}
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name != constants.MysqldContainerName {
+					continue
+				}
+				for _, m := range c.VolumeMounts {
+					if m.Name == constants.MySQLConfSecretVolumeName {
+						mount = true
+					}
+				}
+			}
+			return
+		}
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+		volume, mount := hasMyCnfSecret(sts)
+		Expect(volume).To(BeTrue())
+		Expect(mount).To(BeTrue())
+
+		By("disabling the volume")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.DisableMyCnfSecretVolume = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if volume, mount := hasMyCnfSecret(sts); volume || mount {
+				return fmt.Errorf("my.cnf Secret is still mounted: volume=%v, mount=%v", volume, mount)
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("checking the StatefulSet is not updated any more")
+		rv := sts.ResourceVersion
+		Consistently(func() string {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return ""
+			}
+			return sts.ResourceVersion
+		}, 3*time.Second).Should(Equal(rv))
+
+		var secret corev1.Secret
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MyCnfSecretName()}, &secret)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should project the service account token only into the agent container", func() {
+		cluster := testNewMySQLCluster("test")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+		Expect(sts.Spec.Template.Spec.AutomountServiceAccountToken).To(BeNil())
+		for _, v := range sts.Spec.Template.Spec.Volumes {
+			Expect(v.Name).NotTo(Equal(constants.ServiceAccountTokenVolumeName))
+		}
+
+		By("enabling agentOnlyServiceAccountToken")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.AgentOnlyServiceAccountToken = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.Template.Spec.AutomountServiceAccountToken == nil || *sts.Spec.Template.Spec.AutomountServiceAccountToken {
+				return errors.New("automountServiceAccountToken is not false")
+			}
+			return nil
+		}).Should(Succeed())
+
+		var tokenVolume *corev1.Volume
+		for i, v := range sts.Spec.Template.Spec.Volumes {
+			if v.Name == constants.ServiceAccountTokenVolumeName {
+				tokenVolume = &sts.Spec.Template.Spec.Volumes[i]
+			}
+		}
+		Expect(tokenVolume).NotTo(BeNil())
+		Expect(tokenVolume.Projected).NotTo(BeNil())
+		Expect(tokenVolume.Projected.Sources).To(HaveLen(3))
+		Expect(tokenVolume.Projected.Sources[0].ServiceAccountToken).NotTo(BeNil())
+		Expect(tokenVolume.Projected.Sources[0].ServiceAccountToken.Path).To(Equal("token"))
+		Expect(tokenVolume.Projected.Sources[1].ConfigMap).NotTo(BeNil())
+		Expect(tokenVolume.Projected.Sources[1].ConfigMap.Name).To(Equal("kube-root-ca.crt"))
+		Expect(tokenVolume.Projected.Sources[2].DownwardAPI).NotTo(BeNil())
+
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			var mount *corev1.VolumeMount
+			for i, m := range c.VolumeMounts {
+				if m.Name == constants.ServiceAccountTokenVolumeName {
+					mount = &c.VolumeMounts[i]
+				}
+			}
+			if c.Name != constants.AgentContainerName {
+				Expect(mount).To(BeNil(), "container %s mounts the token", c.Name)
+				continue
+			}
+			Expect(mount).NotTo(BeNil())
+			Expect(mount.MountPath).To(Equal(constants.ServiceAccountTokenPath))
+			Expect(mount.ReadOnly).To(BeTrue())
+		}
+	})
+
+	It("should run mysqld_exporter as a Deployment", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Collectors = []string{"engine_innodb_status", "info_schema.innodb_metrics"}
+		cluster.Spec.ExporterMode = mocov1beta2.ExporterModeDeployment
+		cluster.Spec.PodTemplate.Spec.NodeSelector = map[string]string{"cybozu.com/role": "mysql"}
+		cluster.Spec.PodTemplate.Spec.Tolerations = []corev1ac.TolerationApplyConfiguration{
+			*corev1ac.Toleration().
+				WithKey("cybozu.com/dedicated").
+				WithOperator(corev1.TolerationOpEqual).
+				WithValue("mysql").
+				WithEffect(corev1.TaintEffectNoSchedule),
+		}
+		cluster.Spec.PodTemplate.Spec.Affinity = corev1ac.Affinity().
+			WithNodeAffinity(corev1ac.NodeAffinity().
+				WithRequiredDuringSchedulingIgnoredDuringExecution(corev1ac.NodeSelector().
+					WithNodeSelectorTerms(corev1ac.NodeSelectorTerm().
+						WithMatchExpressions(corev1ac.NodeSelectorRequirement().
+							WithKey("topology.kubernetes.io/zone").
+							WithOperator(corev1.NodeSelectorOpIn).
+							WithValues("zone-a")))))
+		cluster.Spec.PodTemplate.Spec.PriorityClassName = ptr.To("mysql")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var deploy *appsv1.Deployment
+		Eventually(func() error {
+			deploy = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-exporter-test"}, deploy)
+		}).Should(Succeed())
+
+		Expect(deploy.OwnerReferences).NotTo(BeEmpty())
+		Expect(deploy.Spec.Replicas).To(Equal(ptr.To[int32](1)))
+		Expect(deploy.Spec.Selector.MatchLabels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameExporter))
+		Expect(deploy.Spec.Template.Labels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameExporter))
+		Expect(deploy.Spec.Template.Spec.Containers).To(HaveLen(1))
+		c := deploy.Spec.Template.Spec.Containers[0]
+		Expect(c.Name).To(Equal(constants.ExporterContainerName))
+		Expect(c.Image).To(Equal(testExporterImage))
+		Expect(c.Args).To(Equal([]string{
+			"--config.my-cnf=" + filepath.Join(constants.MyCnfSecretPath, constants.ExporterRemoteMyCnf),
+			"--collect.engine_innodb_status",
+			"--collect.info_schema.innodb_metrics",
+		}))
+		Expect(deploy.Spec.Template.Spec.Volumes).To(HaveLen(1))
+		Expect(deploy.Spec.Template.Spec.Volumes[0].Secret).NotTo(BeNil())
+		Expect(deploy.Spec.Template.Spec.Volumes[0].Secret.SecretName).To(Equal(cluster.MyCnfSecretName()))
+		Expect(deploy.Spec.Template.Spec.NodeSelector).To(Equal(map[string]string{"cybozu.com/role": "mysql"}))
+		Expect(deploy.Spec.Template.Spec.Tolerations).To(HaveLen(1))
+		Expect(deploy.Spec.Template.Spec.Tolerations[0].Key).To(Equal("cybozu.com/dedicated"))
+		Expect(deploy.Spec.Template.Spec.Affinity).NotTo(BeNil())
+		Expect(deploy.Spec.Template.Spec.Affinity.NodeAffinity).NotTo(BeNil())
+		Expect(deploy.Spec.Template.Spec.PriorityClassName).To(Equal("mysql"))
+
+		svc := &corev1.Service{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-exporter-test"}, svc)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(svc.Spec.Selector).To(Equal(deploy.Spec.Selector.MatchLabels))
+		Expect(svc.Spec.Ports).To(HaveLen(1))
+		Expect(svc.Spec.Ports[0].Port).To(BeNumerically("==", constants.ExporterPort))
+
+		secret := &corev1.Secret{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MyCnfSecretName()}, secret)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(secret.Data[constants.ExporterRemoteMyCnf])).To(ContainSubstring(`host="moco-test-primary.test.svc"`))
+
+		sts := &appsv1.StatefulSet{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+		Expect(err).NotTo(HaveOccurred())
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			Expect(c.Name).NotTo(Equal(constants.ExporterContainerName))
+		}
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ExporterMode = mocov1beta2.ExporterModeSidecar
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-exporter-test"}, &appsv1.Deployment{})
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+		Eventually(func() bool {
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-exporter-test"}, &corev1.Service{})
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts); err != nil {
+				return err
+			}
+			for _, c := range sts.Spec.Template.Spec.Containers {
+				if c.Name == constants.ExporterContainerName {
+					return nil
+				}
+			}
+			return errors.New("no exporter container")
+		}).Should(Succeed())
+	})
+
+	It("should let mysqld_exporter use the user of exporterUserName", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Collectors = []string{"engine_innodb_status"}
+		cluster.Spec.Users = []mocov1beta2.UserSpec{
+			{Name: "monitor", Grants: []string{"PROCESS, REPLICATION CLIENT ON *.*"}, PasswordSecretName: "monitor-password"},
+		}
+		cluster.Spec.ExporterUserName = "monitor"
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-test"}, sts)
+		}).Should(Succeed())
+
+		var exporter *corev1.Container
+		for i, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == constants.ExporterContainerName {
+				exporter = &sts.Spec.Template.Spec.Containers[i]
+			}
+		}
+		Expect(exporter).NotTo(BeNil())
+		Expect(exporter.Args).To(ContainElement("--config.my-cnf=" + filepath.Join(constants.MyCnfSecretPath, constants.ExporterUserMyCnf)))
+		Expect(exporter.Env).To(HaveLen(1))
+		Expect(exporter.Env[0].Name).To(Equal(constants.ExporterPasswordEnvName))
+		Expect(exporter.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("monitor-password"))
+		Expect(exporter.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal(password.UserPasswordKey))
+
+		secret := &corev1.Secret{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MyCnfSecretName()}, secret)
+		Expect(err).NotTo(HaveOccurred())
+		mycnf := string(secret.Data[constants.ExporterUserMyCnf])
+		Expect(mycnf).To(ContainSubstring(`user="monitor"`))
+		Expect(mycnf).To(ContainSubstring(`socket=`))
+		Expect(mycnf).NotTo(ContainSubstring("password"))
+
+		By("running mysqld_exporter as a Deployment")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.ExporterMode = mocov1beta2.ExporterModeDeployment
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var deploy *appsv1.Deployment
+		Eventually(func() error {
+			deploy = &appsv1.Deployment{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "moco-exporter-test"}, deploy)
+		}).Should(Succeed())
+
+		c := deploy.Spec.Template.Spec.Containers[0]
+		Expect(c.Args).To(ContainElement("--config.my-cnf=" + filepath.Join(constants.MyCnfSecretPath, constants.ExporterUserRemoteMyCnf)))
+		Expect(c.Env).To(HaveLen(1))
+		Expect(c.Env[0].Name).To(Equal(constants.ExporterPasswordEnvName))
+		Expect(c.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("monitor-password"))
+		Expect(deploy.Spec.Template.Spec.Volumes[0].Secret.Items[0].Key).To(Equal(constants.ExporterUserRemoteMyCnf))
+
+		Eventually(func() error {
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MyCnfSecretName()}, secret); err != nil {
+				return err
+			}
+			mycnf := string(secret.Data[constants.ExporterUserRemoteMyCnf])
+			if !strings.Contains(mycnf, `host="moco-test-primary.test.svc"`) {
+				return fmt.Errorf("unexpected my.cnf: %s", mycnf)
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should drain client connections in the preStop hook of mysqld container", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.ReplicaDrainSeconds = ptr.To[int32](60)
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		var foundMysqld, foundSlowLog bool
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			switch c.Name {
+			case constants.MysqldContainerName:
+				foundMysqld = true
+				Expect(c.Lifecycle).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop.Exec).NotTo(BeNil())
+				Expect(c.Lifecycle.PreStop.Exec.Command).To(Equal(mysqldPreStopCommand(cluster)))
+				Expect(c.Lifecycle.PreStop.Exec.Command[2]).To(ContainSubstring("[ $i -lt 60 ]"))
+			case constants.SlowQueryLogAgentContainerName:
+				foundSlowLog = true
+				Expect(c.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "65"}))
+			}
+		}
+		Expect(foundMysqld).To(BeTrue())
+		Expect(foundSlowLog).To(BeTrue())
+		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](340)))
+	})
+
+	It("should configure the preStop hook of the slow-log container", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.SlowQueryLogAgentPreStopSeconds = ptr.To[int32](60)
+		cluster.Spec.PodTemplate.Spec.TerminationGracePeriodSeconds = ptr.To[int64](30)
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		var found bool
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name != constants.SlowQueryLogAgentContainerName {
+				continue
+			}
+			found = true
+			Expect(c.Lifecycle).NotTo(BeNil())
+			Expect(c.Lifecycle.PreStop).NotTo(BeNil())
+			Expect(c.Lifecycle.PreStop.Exec).NotTo(BeNil())
+			Expect(c.Lifecycle.PreStop.Exec.Command).To(Equal([]string{"sleep", "60"}))
+		}
+		Expect(found).To(BeTrue())
+		Expect(sts.Spec.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](65)))
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.SlowQueryLogAgentConfigMapName()}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(cm.Data[constants.FluentBitConfigName]).To(ContainSubstring("Grace          5\n"))
+	})
+
+	It("should configure the output of the slow-log container", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.SlowQueryLogOutput = &mocov1beta2.SlowQueryLogOutputSpec{
+			Type:                  mocov1beta2.SlowQueryLogOutputLoki,
+			Host:                  "loki.example.com",
+			CredentialsSecretName: ptr.To("loki-credentials"),
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		cm := &corev1.ConfigMap{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.SlowQueryLogAgentConfigMapName()}, cm)
+		Expect(err).NotTo(HaveOccurred())
+		conf := cm.Data[constants.FluentBitConfigName]
+		Expect(conf).To(ContainSubstring("  Name           loki\n"))
+		Expect(conf).To(ContainSubstring("  Host           loki.example.com\n"))
+		Expect(conf).To(ContainSubstring("  Port           3100\n"))
+		Expect(conf).To(ContainSubstring("  http_passwd    ${SLOW_LOG_OUTPUT_PASSWORD}\n"))
+		Expect(conf).NotTo(ContainSubstring("stdout"))
+
+		var found bool
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name != constants.SlowQueryLogAgentContainerName {
+				continue
+			}
+			found = true
+			Expect(c.Env).To(HaveLen(2))
+			Expect(c.Env[0].Name).To(Equal(constants.SlowQueryLogOutputUserEnvName))
+			Expect(c.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal("loki-credentials"))
+			Expect(c.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal("username"))
+			Expect(c.Env[1].Name).To(Equal(constants.SlowQueryLogOutputPasswordEnvName))
+			Expect(c.Env[1].ValueFrom.SecretKeyRef.Key).To(Equal("password"))
+		}
+		Expect(found).To(BeTrue())
+		hash := sts.Spec.Template.Annotations[constants.AnnSlowQueryLogOutput]
+		Expect(hash).NotTo(BeEmpty())
+
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.SlowQueryLogOutput.Host = "loki2.example.com"
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return err
+			}
+			if sts.Spec.Template.Annotations[constants.AnnSlowQueryLogOutput] == hash {
+				return errors.New("the annotation is not updated yet")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should restart the Pods when instanceResources changes", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.InstanceResources = []mocov1beta2.InstanceResourcesSpec{
+			{
+				Index: 1,
+				Resources: (*mocov1beta2.ResourceRequirementsApplyConfiguration)(corev1ac.ResourceRequirements().
+					WithRequests(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("8Gi")})),
+			},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		templateAnnotation := func() (string, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return "", err
+			}
+			return sts.Spec.Template.Annotations[constants.AnnInstanceResources], nil
+		}
+
+		var hash string
+		Eventually(func() error {
+			hash, err = templateAnnotation()
+			if err != nil {
+				return err
+			}
+			if hash == "" {
+				return errors.New("the annotation is not set yet")
+			}
+			return nil
+		}).Should(Succeed())
+
+		sts := &appsv1.StatefulSet{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		Expect(err).NotTo(HaveOccurred())
+		for _, c := range sts.Spec.Template.Spec.Containers {
+			if c.Name == constants.MysqldContainerName {
+				Expect(c.Resources.Requests.Memory().Equal(resource.MustParse("8Gi"))).To(BeFalse())
+			}
+		}
+
+		By("changing the resources")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.InstanceResources[0].Resources.WithRequests(corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")})
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			h, err := templateAnnotation()
+			if err != nil {
+				return err
+			}
+			if h == "" || h == hash {
+				return errors.New("the annotation is not updated yet")
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("removing the overrides")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.InstanceResources = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			h, err := templateAnnotation()
+			if err != nil {
+				return err
+			}
+			if h != "" {
+				return errors.New("the annotation is not removed yet")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should create password secrets for users", func() {
+		existing := &corev1.Secret{}
+		existing.Namespace = "test"
+		existing.Name = "existing-password"
+		existing.Data = map[string][]byte{password.UserPasswordKey: []byte("foo")}
+		err := k8sClient.Create(ctx, existing)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Users = []mocov1beta2.UserSpec{
+			{Name: "app", Grants: []string{"SELECT ON app.*"}, PasswordSecretName: "app-password"},
+			{Name: "other", PasswordSecretName: "existing-password"},
+		}
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var secret *corev1.Secret
+		Eventually(func() error {
+			secret = &corev1.Secret{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "app-password"}, secret)
+		}).Should(Succeed())
+		Expect(secret.Data).To(HaveKey(password.UserPasswordKey))
+		Expect(secret.Data[password.UserPasswordKey]).NotTo(BeEmpty())
+		Expect(secret.OwnerReferences).To(HaveLen(1))
+		Expect(secret.OwnerReferences[0].Name).To(Equal(cluster.Name))
+
+		Consistently(func() []byte {
+			secret := &corev1.Secret{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "existing-password"}, secret); err != nil {
+				return nil
+			}
+			return secret.Data[password.UserPasswordKey]
+		}, 3*time.Second).Should(Equal([]byte("foo")))
+	})
+
+	It("should warn when a ResourceQuota may reject Pods", func() {
+		quota := &corev1.ResourceQuota{}
+		quota.Namespace = "test"
+		quota.Name = "small"
+		quota.Spec.Hard = corev1.ResourceList{
+			corev1.ResourcePods: resource.MustParse("2"),
+		}
+		err := k8sClient.Create(ctx, quota)
+		Expect(err).NotTo(HaveOccurred())
+
+		cluster := testNewMySQLCluster("test")
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.Kind == "MySQLCluster" && ev.InvolvedObject.Name == cluster.Name && ev.Reason == "QuotaExceeded" {
+					if !strings.Contains(ev.Message, "small") {
+						return fmt.Errorf("unexpected message: %s", ev.Message)
+					}
+					return nil
+				}
+			}
+			return errors.New("no QuotaExceeded event")
+		}).Should(Succeed())
+
+		By("checking the StatefulSet is still applied")
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		By("recording the event only once while the result does not change")
+		quota = &corev1.ResourceQuota{}
+		quota.Namespace = "test"
+		quota.Name = "none"
+		quota.Spec.Hard = corev1.ResourceList{
+			corev1.ResourcePods: resource.MustParse("0"),
+		}
+		err = k8sClient.Create(ctx, quota)
+		Expect(err).NotTo(HaveOccurred())
+		countEvents := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "QuotaExceeded" && strings.Contains(ev.Message, "none") {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+		for i := 0; i < 3; i++ {
+			Eventually(func() error {
+				c := &mocov1beta2.MySQLCluster{}
+				if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+					return err
+				}
+				c.Spec.PodTemplate.Annotations = map[string]string{"count": fmt.Sprint(i)}
+				return k8sClient.Update(ctx, c)
+			}).Should(Succeed())
+			Eventually(countEvents).Should(BeNumerically("==", 1))
+		}
+		Consistently(countEvents, 3*time.Second).Should(BeNumerically("==", 1))
+	})
+
+	It("should not apply a StatefulSet sharing the host namespaces", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.PodTemplate.Spec.HostNetwork = ptr.To[bool](true)
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.Kind == "MySQLCluster" && ev.InvolvedObject.Name == cluster.Name && ev.Reason == "HostNamespacesRejected" {
+					if !strings.Contains(ev.Message, "hostNetwork") {
+						return fmt.Errorf("unexpected message: %s", ev.Message)
+					}
+					return nil
+				}
+			}
+			return errors.New("no HostNamespacesRejected event")
+		}).Should(Succeed())
+
+		Consistently(func() bool {
+			sts := &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			return apierrors.IsNotFound(err)
+		}, 3).Should(BeTrue())
+
+		By("recording the event only once for the generation")
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Labels = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+		Consistently(func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "HostNamespacesRejected" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}, 3*time.Second).Should(BeNumerically("==", 1))
+
+		By("allowing the host namespaces with the annotation")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Annotations = map[string]string{constants.AnnAllowHostNamespaces: "true"}
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var sts *appsv1.StatefulSet
+		Eventually(func() error {
+			sts = &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+		Expect(sts.Spec.Template.Spec.HostNetwork).To(BeTrue())
+	})
+
+	It("should reconcile backup related resources", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To[string]("test-policy")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating a backup policy")
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "test-policy"
+		bp.Spec.ActiveDeadlineSeconds = ptr.To[int64](100)
+		bp.Spec.BackoffLimit = ptr.To[int32](1)
+		bp.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
+		bp.Spec.Suspend = true
+		bp.Spec.StartingDeadlineSeconds = ptr.To[int64](10)
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.SuccessfulJobsHistoryLimit = ptr.To[int32](1)
+		bp.Spec.FailedJobsHistoryLimit = ptr.To[int32](2)
+		jc := &bp.Spec.JobConfig
+		jc.Threads = 3
+		jc.ServiceAccountName = "foo"
+		jc.SchedulerName = "custom-scheduler"
+		jc.RuntimeClassName = ptr.To("gvisor")
+		jc.CPU = resource.NewQuantity(1, resource.DecimalSI)
+		jc.MaxCPU = resource.NewQuantity(4, resource.DecimalSI)
+		jc.Memory = resource.NewQuantity(1<<30, resource.DecimalSI)
+		jc.MaxMemory = resource.NewQuantity(10<<30, resource.DecimalSI)
+		jc.Env = []mocov1beta2.EnvVarApplyConfiguration{{Name: ptr.To[string]("TEST"), Value: ptr.To[string]("123")}}
+		jc.EnvFrom = []mocov1beta2.EnvFromSourceApplyConfiguration{
+			{
+				ConfigMapRef: &corev1ac.ConfigMapEnvSourceApplyConfiguration{
+					LocalObjectReferenceApplyConfiguration: corev1ac.LocalObjectReferenceApplyConfiguration{
+						Name: ptr.To[string]("bucket-config"),
+					},
+				},
+			},
+		}
+		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		jc.Volumes = []mocov1beta2.VolumeApplyConfiguration{
+			{
+				Name: ptr.To[string]("test"),
+				VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration{
+					EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+				},
+			},
+		}
+		jc.VolumeMounts = []mocov1beta2.VolumeMountApplyConfiguration{
+			{
+				Name:      ptr.To[string]("test"),
+				MountPath: ptr.To[string]("/path/to/dir"),
+			},
+		}
+		jc.BucketConfig.BucketName = "mybucket"
+		jc.BucketConfig.EndpointURL = "https://foo.bar.baz"
+		jc.BucketConfig.Region = "us-east-1"
+		jc.BucketConfig.UsePathStyle = true
+		err = k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cj *batchv1.CronJob
+		var role *rbacv1.Role
+		var roleBinding *rbacv1.RoleBinding
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
+				return err
+			}
+			role = &rbacv1.Role{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, role); err != nil {
+				return err
+			}
+			roleBinding = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding); err != nil {
+				return err
+			}
+			return nil
+		}).Should(Succeed())
+
+		Expect(cj.Labels).NotTo(BeEmpty())
+		Expect(cj.OwnerReferences).NotTo(BeEmpty())
+		Expect(cj.Spec.Schedule).To(Equal("*/5 * * * *"))
+		Expect(cj.Spec.StartingDeadlineSeconds).To(Equal(ptr.To[int64](10)))
+		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1.ForbidConcurrent))
+		Expect(cj.Spec.Suspend).To(Equal(ptr.To(true)))
+		Expect(cj.Spec.SuccessfulJobsHistoryLimit).To(Equal(ptr.To[int32](1)))
+		Expect(cj.Spec.FailedJobsHistoryLimit).To(Equal(ptr.To[int32](2)))
+		Expect(cj.Spec.JobTemplate.Labels).NotTo(BeEmpty())
+		js := &cj.Spec.JobTemplate.Spec
+		Expect(js.ActiveDeadlineSeconds).To(Equal(ptr.To[int64](100)))
+		Expect(js.BackoffLimit).To(Equal(ptr.To[int32](1)))
+		Expect(js.Template.Labels).NotTo(BeEmpty())
+		Expect(js.Template.Spec.Affinity).NotTo(BeNil())
+		Expect(js.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(js.Template.Spec.ServiceAccountName).To(Equal("foo"))
+		Expect(js.Template.Spec.SchedulerName).To(Equal("custom-scheduler"))
+		Expect(js.Template.Spec.RuntimeClassName).To(Equal(ptr.To("gvisor")))
+		Expect(js.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](60)))
+		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
+		Expect(js.Template.Spec.Volumes[0].EmptyDir).NotTo(BeNil())
+		Expect(js.Template.Spec.Volumes[1].EmptyDir).NotTo(BeNil())
+		Expect(js.Template.Spec.Containers).To(HaveLen(1))
+		c := &js.Template.Spec.Containers[0]
+		Expect(c.Name).To(Equal("backup"))
+		Expect(c.Image).To(Equal(testBackupImage))
+		Expect(c.Args).To(Equal([]string{
+			"backup",
+			"--threads=3",
+			"--region=us-east-1",
+			"--endpoint=https://foo.bar.baz",
+			"--use-path-style",
+			"--backend-type=s3",
+			"mybucket",
+			"test",
+			"test",
+		}))
+		Expect(c.EnvFrom).To(HaveLen(1))
+		Expect(c.Env).To(HaveLen(2))
+		Expect(c.VolumeMounts).To(HaveLen(2))
+		cpuReq := c.Resources.Requests[corev1.ResourceCPU]
+		Expect(cpuReq.Value()).To(BeNumerically("==", 1))
+		cpuLim := c.Resources.Limits[corev1.ResourceCPU]
+		Expect(cpuLim.Value()).To(BeNumerically("==", 4))
+		memReq := c.Resources.Requests[corev1.ResourceMemory]
+		Expect(memReq.Value()).To(BeNumerically("==", 1<<30))
+		memLim := c.Resources.Limits[corev1.ResourceMemory]
+		Expect(memLim.Value()).To(BeNumerically("==", 10<<30))
+
+		Expect(role.Labels).NotTo(BeEmpty())
+		Expect(role.OwnerReferences).NotTo(BeEmpty())
+		Expect(role.Rules).NotTo(BeEmpty())
+		Expect(roleBinding.Labels).NotTo(BeEmpty())
+		Expect(roleBinding.OwnerReferences).NotTo(BeEmpty())
+		Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
+		Expect(roleBinding.Subjects).To(HaveLen(1))
+		Expect(roleBinding.Subjects[0].Name).To(Equal("foo"))
+
+		By("updating a backup policy")
+		bp = &mocov1beta2.BackupPolicy{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test-policy"}, bp)
+		Expect(err).NotTo(HaveOccurred())
+		bp.Spec.ActiveDeadlineSeconds = nil
+		bp.Spec.BackoffLimit = nil
+		bp.Spec.ConcurrencyPolicy = batchv1.AllowConcurrent
+		bp.Spec.Suspend = false
+		bp.Spec.StartingDeadlineSeconds = nil
+		bp.Spec.Schedule = "*/5 1 * * *"
+		bp.Spec.SuccessfulJobsHistoryLimit = nil
+		bp.Spec.FailedJobsHistoryLimit = nil
+		jc = &bp.Spec.JobConfig
+		jc.Threads = 1
+		jc.ServiceAccountName = "oof"
+		jc.SchedulerName = ""
+		jc.RuntimeClassName = nil
+		jc.TerminationGracePeriodSeconds = ptr.To[int64](120)
+		jc.CPU = nil
+		jc.MaxCPU = nil
+		jc.Memory = nil
+		jc.MaxMemory = nil
+		jc.Env = nil
+		jc.EnvFrom = nil
+		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			HostPath: &corev1ac.HostPathVolumeSourceApplyConfiguration{
+				Path: ptr.To[string]("/host"),
+			},
+		}
+		jc.BucketConfig.BucketName = "mybucket2"
+		jc.BucketConfig.EndpointURL = ""
+		jc.BucketConfig.Region = ""
+		jc.BucketConfig.UsePathStyle = false
+		err = k8sClient.Update(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
+				return err
+			}
+			if cj.Spec.Schedule != "*/5 1 * * *" {
+				return errors.New("CronJob is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+
+		Expect(cj.Spec.StartingDeadlineSeconds).To(BeNil())
+		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1.AllowConcurrent))
+		Expect(cj.Spec.Suspend).To(Equal(ptr.To(false)))
+		Expect(cj.Spec.SuccessfulJobsHistoryLimit).To(Equal(ptr.To[int32](3)))
+		Expect(cj.Spec.FailedJobsHistoryLimit).To(Equal(ptr.To[int32](1)))
+		js = &cj.Spec.JobTemplate.Spec
+		Expect(js.ActiveDeadlineSeconds).To(Equal(ptr.To[int64](constants.DefaultBackupActiveDeadlineSeconds)))
+		Expect(js.BackoffLimit).To(BeNil())
+		Expect(js.Template.Spec.ServiceAccountName).To(Equal("oof"))
+		Expect(js.Template.Spec.SchedulerName).To(Equal(corev1.DefaultSchedulerName))
+		Expect(js.Template.Spec.RuntimeClassName).To(BeNil())
+		Expect(js.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](120)))
+		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
+		Expect(js.Template.Spec.Volumes[0].EmptyDir).To(BeNil())
+		Expect(js.Template.Spec.Volumes[0].HostPath).NotTo(BeNil())
+		Expect(js.Template.Spec.Containers).To(HaveLen(1))
+		c = &js.Template.Spec.Containers[0]
+		Expect(c.Args).To(Equal([]string{
+			"backup",
+			"--threads=1",
+			"--backend-type=s3",
+			"mybucket2",
+			"test",
+			"test",
+		}))
+		Expect(c.EnvFrom).To(BeEmpty())
+		Expect(c.Env).To(HaveLen(1))
+		cpuReq = c.Resources.Requests[corev1.ResourceCPU]
+		Expect(cpuReq.Value()).To(BeNumerically("==", 4))
+		Expect(c.Resources.Limits).NotTo(HaveKey(corev1.ResourceCPU))
+		memReq = c.Resources.Requests[corev1.ResourceMemory]
+		Expect(memReq.Value()).To(BeNumerically("==", 4<<30))
+		Expect(c.Resources.Limits).NotTo(HaveKey(corev1.ResourceMemory))
+
+		Eventually(func() error {
+			roleBinding = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding); err != nil {
+				return err
+			}
+			if len(roleBinding.Subjects) != 1 {
+				return errors.New("empty subject")
+			}
+			if roleBinding.Subjects[0].Name != "oof" {
+				return errors.New("RoleBinding is not updated")
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("disabling backup")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.BackupPolicyName = nil
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() bool {
+			cj = &batchv1.CronJob{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test-policy"}, cj)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		Eventually(func() bool {
+			role = &rbacv1.Role{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, role)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+
+		Eventually(func() bool {
+			roleBinding = &rbacv1.RoleBinding{}
+			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding)
+			return apierrors.IsNotFound(err)
+		}).Should(BeTrue())
+	})
+
+	It("should reconcile restore related resources", func() {
+		By("creating a MySQLCluster with restore spec")
+		now := metav1.Now()
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:            "single",
+			SourceNamespace:       "ns",
+			RestorePoint:          now,
+			Prefix:                "migrated/moco/ns/single",
+			ActiveDeadlineSeconds: ptr.To[int64](3600),
+		}
+		jc := &cluster.Spec.Restore.JobConfig
+		jc.Threads = 3
+		jc.ServiceAccountName = "foo"
+		jc.SchedulerName = "custom-scheduler"
+		jc.RuntimeClassName = ptr.To("gvisor")
+		jc.TerminationGracePeriodSeconds = ptr.To[int64](90)
+		jc.CPU = resource.NewQuantity(1, resource.DecimalSI)
+		jc.MaxCPU = resource.NewQuantity(4, resource.DecimalSI)
+		jc.Memory = resource.NewQuantity(1<<30, resource.DecimalSI)
+		jc.MaxMemory = resource.NewQuantity(10<<30, resource.DecimalSI)
+		jc.Env = []mocov1beta2.EnvVarApplyConfiguration{{Name: ptr.To[string]("TEST"), Value: ptr.To[string]("123")}}
+		jc.EnvFrom = []mocov1beta2.EnvFromSourceApplyConfiguration{
+			{
+				ConfigMapRef: &corev1ac.ConfigMapEnvSourceApplyConfiguration{
+					LocalObjectReferenceApplyConfiguration: corev1ac.LocalObjectReferenceApplyConfiguration{
+						Name: ptr.To[string]("bucket-config"),
+					},
+				},
+			},
+		}
+		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		jc.Volumes = []mocov1beta2.VolumeApplyConfiguration{
+			{
+				Name: ptr.To[string]("test"),
+				VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration{
+					EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+				},
+			},
+		}
+		jc.VolumeMounts = []mocov1beta2.VolumeMountApplyConfiguration{
+			{
+				Name:      ptr.To[string]("test"),
+				MountPath: ptr.To[string]("/path/to/dir"),
+			},
+		}
+		jc.BucketConfig.BucketName = "mybucket"
+		jc.BucketConfig.EndpointURL = "https://foo.bar.baz"
+		jc.BucketConfig.Region = "us-east-1"
+		jc.BucketConfig.UsePathStyle = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var job *batchv1.Job
+		var role *rbacv1.Role
+		var roleBinding *rbacv1.RoleBinding
+		Eventually(func() error {
+			job = &batchv1.Job{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job); err != nil {
+				return err
+			}
+			role = &rbacv1.Role{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err != nil {
+				return err
+			}
+			roleBinding = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err != nil {
+				return err
+			}
+			return nil
+		}).Should(Succeed())
+
+		Expect(job.Labels).NotTo(BeEmpty())
+		Expect(job.OwnerReferences).NotTo(BeEmpty())
+		js := &job.Spec
+		Expect(js.BackoffLimit).To(Equal(ptr.To[int32](0)))
+		Expect(js.ActiveDeadlineSeconds).To(Equal(ptr.To[int64](3600)))
+		Expect(js.Template.Labels).NotTo(BeEmpty())
+		Expect(js.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
+		Expect(js.Template.Spec.ServiceAccountName).To(Equal("foo"))
+		Expect(js.Template.Spec.SchedulerName).To(Equal("custom-scheduler"))
+		Expect(js.Template.Spec.RuntimeClassName).To(Equal(ptr.To("gvisor")))
+		Expect(js.Template.Spec.TerminationGracePeriodSeconds).To(Equal(ptr.To[int64](90)))
+		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
+		Expect(js.Template.Spec.Volumes[0].EmptyDir).NotTo(BeNil())
+		Expect(js.Template.Spec.Volumes[1].EmptyDir).NotTo(BeNil())
+		Expect(js.Template.Spec.Containers).To(HaveLen(1))
+		c := &js.Template.Spec.Containers[0]
+		Expect(c.Name).To(Equal("restore"))
+		Expect(c.Image).To(Equal(testBackupImage))
+		Expect(c.Args).To(Equal([]string{
+			"restore",
+			"--threads=3",
+			"--prefix=migrated/moco/ns/single",
+			"--region=us-east-1",
+			"--endpoint=https://foo.bar.baz",
+			"--use-path-style",
+			"--backend-type=s3",
+			"mybucket",
+			"ns",
+			"single",
+			"test",
+			"test",
+			now.UTC().Format(constants.BackupTimeFormat),
+		}))
+		Expect(c.EnvFrom).To(HaveLen(1))
+		Expect(c.Env).To(HaveLen(2))
+		Expect(c.VolumeMounts).To(HaveLen(2))
+		cpuReq := c.Resources.Requests[corev1.ResourceCPU]
+		Expect(cpuReq.Value()).To(BeNumerically("==", 1))
+		cpuLim := c.Resources.Limits[corev1.ResourceCPU]
+		Expect(cpuLim.Value()).To(BeNumerically("==", 4))
+		memReq := c.Resources.Requests[corev1.ResourceMemory]
+		Expect(memReq.Value()).To(BeNumerically("==", 1<<30))
+		memLim := c.Resources.Limits[corev1.ResourceMemory]
+		Expect(memLim.Value()).To(BeNumerically("==", 10<<30))
+
+		Expect(role.Labels).NotTo(BeEmpty())
+		Expect(role.OwnerReferences).NotTo(BeEmpty())
+		Expect(role.Rules).NotTo(BeEmpty())
+		Expect(roleBinding.Labels).NotTo(BeEmpty())
+		Expect(roleBinding.OwnerReferences).NotTo(BeEmpty())
+		Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
+		Expect(roleBinding.Subjects).To(HaveLen(1))
+		Expect(roleBinding.Subjects[0].Name).To(Equal("foo"))
+
+		By("recording the event for the job exceeding the deadline only once")
+		job.Status.Conditions = []batchv1.JobCondition{
+			{Type: batchv1.JobFailed, Status: corev1.ConditionTrue, Reason: "DeadlineExceeded"},
+		}
+		err = k8sClient.Status().Update(ctx, job)
+		Expect(err).NotTo(HaveOccurred())
+		countDeadlineEvents := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "RestoreDeadlineExceeded" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+		Eventually(func(g Gomega) {
+			job := &batchv1.Job{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(job.Annotations).To(HaveKeyWithValue(constants.AnnDeadlineExceededReported, "true"))
+		}).Should(Succeed())
+		Eventually(countDeadlineEvents).Should(BeNumerically("==", 1))
+		Consistently(countDeadlineEvents, 3*time.Second).Should(BeNumerically("==", 1))
+
+		By("changing cluster status")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			t := metav1.Now()
+			cluster.Status.RestoredTime = &t
+			return k8sClient.Status().Update(ctx, cluster)
+		}).Should(Succeed())
+
+		By("checking the restore job and its RBAC resources are deleted")
+		Eventually(func() bool {
+			job = &batchv1.Job{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job); err == nil {
+				return false
+			}
+			role = &rbacv1.Role{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err == nil {
+				return false
+			}
+			roleBinding = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err == nil {
+				return false
+			}
+			return true
+		}).Should(BeTrue())
+
+		Consistently(func() bool {
+			job = &batchv1.Job{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job); err == nil {
+				return false
+			}
+			role = &rbacv1.Role{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err == nil {
+				return false
+			}
+			roleBinding = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err == nil {
+				return false
+			}
+			return true
+		}, 5).Should(BeTrue())
+	})
+
+	It("should keep the restore job until ttlSecondsAfterRestored passes", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:              "single",
+			SourceNamespace:         "ns",
+			RestorePoint:            metav1.Now(),
+			TTLSecondsAfterRestored: ptr.To[int32](3600),
+		}
+		cluster.Spec.Restore.JobConfig.ServiceAccountName = "foo"
+		cluster.Spec.Restore.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		cluster.Spec.Restore.JobConfig.BucketConfig.BucketName = "mybucket"
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			job := &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			t := metav1.Now()
+			cluster.Status.RestoredTime = &t
+			return k8sClient.Status().Update(ctx, cluster)
+		}).Should(Succeed())
+
+		Consistently(func() error {
+			job := &batchv1.Job{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job); err != nil {
+				return err
+			}
+			role := &rbacv1.Role{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role)
+		}, 5).Should(Succeed())
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should delete the point-in-time clone after the TTL expires", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:       "single",
+			SourceNamespace:  "ns",
+			RestorePoint:     metav1.Now(),
+			PointInTimeClone: &mocov1beta2.PointInTimeCloneSpec{TTLSecondsAfterRestored: 5},
+		}
+		cluster.Spec.Restore.JobConfig.ServiceAccountName = "foo"
+		cluster.Spec.Restore.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		cluster.Spec.Restore.JobConfig.BucketConfig.BucketName = "mybucket"
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			job := &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		By("not expiring until the restoration completes")
+		Consistently(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.DeletionTimestamp != nil {
+				return errors.New("the cluster is deleted")
+			}
+			if cluster.Status.ExpiryTime != nil {
+				return fmt.Errorf("unexpected expiry time: %v", cluster.Status.ExpiryTime)
+			}
+			return nil
+		}, 3*time.Second).Should(Succeed())
+
+		var restoredTime metav1.Time
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			restoredTime = metav1.NewTime(time.Now().Truncate(time.Second))
+			cluster.Status.RestoredTime = &restoredTime
+			return k8sClient.Status().Update(ctx, cluster)
+		}).Should(Succeed())
+
+		By("recording the expiry in the status")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			if cluster.Status.ExpiryTime == nil {
+				return errors.New("no expiry time")
+			}
+			if !cluster.Status.ExpiryTime.Time.Equal(restoredTime.Add(5 * time.Second)) {
+				return fmt.Errorf("unexpected expiry time: %v", cluster.Status.ExpiryTime)
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("deleting the cluster after the expiry")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if cluster.DeletionTimestamp == nil {
+				return errors.New("the cluster is not deleted yet")
+			}
+			if time.Now().Before(restoredTime.Add(5 * time.Second)) {
+				return errors.New("the cluster was deleted before the expiry")
+			}
+			return nil
+		}, 10*time.Second).Should(Succeed())
+
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "PointInTimeCloneExpired" {
+					return nil
+				}
+			}
+			return errors.New("no PointInTimeCloneExpired event")
+		}).Should(Succeed())
 
-		Expect(pdb.Spec.MaxUnavailable).NotTo(BeNil())
-		Expect(pdb.Spec.MaxUnavailable.IntVal).To(Equal(int32(1)))
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should unpublish not-ready addresses of the headless Service while restoring", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:                 "single",
+			SourceNamespace:            "ns",
+			RestorePoint:               metav1.Now(),
+			UnpublishNotReadyAddresses: true,
+		}
+		cluster.Spec.Restore.JobConfig.ServiceAccountName = "foo"
+		cluster.Spec.Restore.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		cluster.Spec.Restore.JobConfig.BucketConfig.BucketName = "mybucket"
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
 		Eventually(func() error {
-			cluster = &mocov1beta2.MySQLCluster{}
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.HeadlessServiceName()}, svc); err != nil {
+				return err
+			}
+			if svc.Spec.PublishNotReadyAddresses {
+				return errors.New("not-ready addresses are published during the restoration")
+			}
+			return nil
+		}).Should(Succeed())
+
+		By("completing the restoration")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
 			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
 				return err
 			}
-			if cluster.Status.ReconcileInfo.Generation != cluster.Generation {
-				return fmt.Errorf("not yet reconciled")
+			t := metav1.Now()
+			cluster.Status.RestoredTime = &t
+			return k8sClient.Status().Update(ctx, cluster)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			svc := &corev1.Service{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.HeadlessServiceName()}, svc); err != nil {
+				return err
+			}
+			if !svc.Spec.PublishNotReadyAddresses {
+				return errors.New("not-ready addresses are not published after the restoration")
 			}
 			return nil
 		}).Should(Succeed())
-		cluster.Spec.Replicas = 1
-		err = k8sClient.Update(ctx, cluster)
-		Expect(err).NotTo(HaveOccurred())
 
-		Eventually(func() bool {
-			pdb = &policyv1.PodDisruptionBudget{}
-			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, pdb)
-			return apierrors.IsNotFound(err)
-		}).Should(BeTrue())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
 	})
 
-	It("should reconcile backup related resources", func() {
+	It("should use a custom container for the restore job", func() {
 		cluster := testNewMySQLCluster("test")
-		cluster.Spec.BackupPolicyName = ptr.To[string]("test-policy")
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:      "single",
+			SourceNamespace: "ns",
+			RestorePoint:    metav1.Now(),
+		}
+		jc := &cluster.Spec.Restore.JobConfig
+		jc.ServiceAccountName = "foo"
+		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		jc.BucketConfig.BucketName = "mybucket"
+		jc.Image = "custom-restore:1"
+		jc.Command = []string{"/restore.sh"}
+		jc.Args = []string{"--source", "snapshot"}
 		err := k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
-		By("creating a backup policy")
+		var job *batchv1.Job
+		Eventually(func() error {
+			job = &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		Expect(job.Spec.Template.Spec.Containers).To(HaveLen(1))
+		c := job.Spec.Template.Spec.Containers[0]
+		Expect(c.Image).To(Equal("custom-restore:1"))
+		Expect(c.Command).To(Equal([]string{"/restore.sh"}))
+		Expect(c.Args).To(Equal([]string{"--source", "snapshot"}))
+		Expect(c.Env).To(ContainElement(HaveField("Name", "MYSQL_PASSWORD")))
+		Expect(c.VolumeMounts).To(ContainElement(HaveField("MountPath", "/work")))
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should set the pod failure policy of backup and restore jobs if supported", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.PodFailurePolicy = true
+		})
+
+		policy := []mocov1beta2.PodFailurePolicyRule{
+			{Action: string(batchv1.PodFailurePolicyActionFailJob), ExitCodes: []int32{2, 3}},
+			{Action: string(batchv1.PodFailurePolicyActionIgnore), ExitCodes: []int32{137}},
+		}
+		checkPolicy := func(p *batchv1.PodFailurePolicy, containerName string) {
+			ExpectWithOffset(1, p).NotTo(BeNil())
+			ExpectWithOffset(1, p.Rules).To(HaveLen(2))
+			ExpectWithOffset(1, p.Rules[0].Action).To(Equal(batchv1.PodFailurePolicyActionFailJob))
+			ExpectWithOffset(1, p.Rules[0].OnExitCodes).NotTo(BeNil())
+			ExpectWithOffset(1, p.Rules[0].OnExitCodes.ContainerName).To(Equal(ptr.To(containerName)))
+			ExpectWithOffset(1, p.Rules[0].OnExitCodes.Operator).To(Equal(batchv1.PodFailurePolicyOnExitCodesOpIn))
+			ExpectWithOffset(1, p.Rules[0].OnExitCodes.Values).To(Equal([]int32{2, 3}))
+			ExpectWithOffset(1, p.Rules[1].Action).To(Equal(batchv1.PodFailurePolicyActionIgnore))
+			ExpectWithOffset(1, p.Rules[1].OnExitCodes.Values).To(Equal([]int32{137}))
+		}
+
 		bp := &mocov1beta2.BackupPolicy{}
 		bp.Namespace = "test"
-		bp.Name = "test-policy"
-		bp.Spec.ActiveDeadlineSeconds = ptr.To[int64](100)
-		bp.Spec.BackoffLimit = ptr.To[int32](1)
-		bp.Spec.ConcurrencyPolicy = batchv1.ForbidConcurrent
-		bp.Spec.StartingDeadlineSeconds = ptr.To[int64](10)
+		bp.Name = "pod-failure-policy"
 		bp.Spec.Schedule = "*/5 * * * *"
-		bp.Spec.SuccessfulJobsHistoryLimit = ptr.To[int32](1)
-		bp.Spec.FailedJobsHistoryLimit = ptr.To[int32](2)
-		jc := &bp.Spec.JobConfig
-		jc.Threads = 3
-		jc.ServiceAccountName = "foo"
-		jc.CPU = resource.NewQuantity(1, resource.DecimalSI)
-		jc.MaxCPU = resource.NewQuantity(4, resource.DecimalSI)
-		jc.Memory = resource.NewQuantity(1<<30, resource.DecimalSI)
-		jc.MaxMemory = resource.NewQuantity(10<<30, resource.DecimalSI)
-		jc.Env = []mocov1beta2.EnvVarApplyConfiguration{{Name: ptr.To[string]("TEST"), Value: ptr.To[string]("123")}}
-		jc.EnvFrom = []mocov1beta2.EnvFromSourceApplyConfiguration{
-			{
-				ConfigMapRef: &corev1ac.ConfigMapEnvSourceApplyConfiguration{
-					LocalObjectReferenceApplyConfiguration: corev1ac.LocalObjectReferenceApplyConfiguration{
-						Name: ptr.To[string]("bucket-config"),
-					},
-				},
-			},
-		}
-		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
 			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
 		}
-		jc.Volumes = []mocov1beta2.VolumeApplyConfiguration{
-			{
-				Name: ptr.To[string]("test"),
-				VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration{
-					EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
-				},
-			},
-		}
-		jc.VolumeMounts = []mocov1beta2.VolumeMountApplyConfiguration{
-			{
-				Name:      ptr.To[string]("test"),
-				MountPath: ptr.To[string]("/path/to/dir"),
-			},
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		bp.Spec.JobConfig.PodFailurePolicy = policy
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:      "single",
+			SourceNamespace: "ns",
+			RestorePoint:    metav1.Now(),
 		}
-		jc.BucketConfig.BucketName = "mybucket"
-		jc.BucketConfig.EndpointURL = "https://foo.bar.baz"
-		jc.BucketConfig.Region = "us-east-1"
-		jc.BucketConfig.UsePathStyle = true
-		err = k8sClient.Create(ctx, bp)
+		cluster.Spec.Restore.JobConfig = bp.Spec.JobConfig
+		err = k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
 		var cj *batchv1.CronJob
-		var role *rbacv1.Role
-		var roleBinding *rbacv1.RoleBinding
+		var job *batchv1.Job
 		Eventually(func() error {
 			cj = &batchv1.CronJob{}
 			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
 				return err
 			}
-			role = &rbacv1.Role{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, role); err != nil {
+			job = &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		checkPolicy(cj.Spec.JobTemplate.Spec.PodFailurePolicy, "backup")
+		checkPolicy(job.Spec.PodFailurePolicy, "restore")
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should pass the databases to be backed up to the backup job", func() {
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "partial"
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.JobConfig.Threads = 1
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		bp.Spec.JobConfig.IncludeDatabases = []string{"foo", "bar"}
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cj *batchv1.CronJob
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj)
+		}).Should(Succeed())
+
+		Expect(cj.Spec.JobTemplate.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
+			"backup",
+			"--threads=1",
+			"--include-databases=foo,bar",
+			"--backend-type=s3",
+			"mybucket",
+			"test",
+			"test",
+		}))
+
+		By("excluding databases instead")
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(bp), bp)
+		Expect(err).NotTo(HaveOccurred())
+		bp.Spec.JobConfig.IncludeDatabases = nil
+		bp.Spec.JobConfig.ExcludeDatabases = []string{"analytics"}
+		err = k8sClient.Update(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
 				return err
 			}
-			roleBinding = &rbacv1.RoleBinding{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding); err != nil {
-				return err
+			args := cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args
+			if len(args) < 3 || args[2] != "--exclude-databases=analytics" {
+				return fmt.Errorf("CronJob is not updated: %v", args)
 			}
 			return nil
 		}).Should(Succeed())
 
-		Expect(cj.Labels).NotTo(BeEmpty())
-		Expect(cj.OwnerReferences).NotTo(BeEmpty())
-		Expect(cj.Spec.Schedule).To(Equal("*/5 * * * *"))
-		Expect(cj.Spec.StartingDeadlineSeconds).To(Equal(ptr.To[int64](10)))
-		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1.ForbidConcurrent))
-		Expect(cj.Spec.SuccessfulJobsHistoryLimit).To(Equal(ptr.To[int32](1)))
-		Expect(cj.Spec.FailedJobsHistoryLimit).To(Equal(ptr.To[int32](2)))
-		Expect(cj.Spec.JobTemplate.Labels).NotTo(BeEmpty())
-		js := &cj.Spec.JobTemplate.Spec
-		Expect(js.ActiveDeadlineSeconds).To(Equal(ptr.To[int64](100)))
-		Expect(js.BackoffLimit).To(Equal(ptr.To[int32](1)))
-		Expect(js.Template.Labels).NotTo(BeEmpty())
-		Expect(js.Template.Spec.Affinity).NotTo(BeNil())
-		Expect(js.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
-		Expect(js.Template.Spec.ServiceAccountName).To(Equal("foo"))
-		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
-		Expect(js.Template.Spec.Volumes[0].EmptyDir).NotTo(BeNil())
-		Expect(js.Template.Spec.Volumes[1].EmptyDir).NotTo(BeNil())
-		Expect(js.Template.Spec.Containers).To(HaveLen(1))
-		c := &js.Template.Spec.Containers[0]
-		Expect(c.Name).To(Equal("backup"))
-		Expect(c.Image).To(Equal(testBackupImage))
-		Expect(c.Args).To(Equal([]string{
+		Expect(cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
 			"backup",
-			"--threads=3",
-			"--region=us-east-1",
-			"--endpoint=https://foo.bar.baz",
-			"--use-path-style",
+			"--threads=1",
+			"--exclude-databases=analytics",
 			"--backend-type=s3",
 			"mybucket",
 			"test",
 			"test",
 		}))
-		Expect(c.EnvFrom).To(HaveLen(1))
-		Expect(c.Env).To(HaveLen(2))
-		Expect(c.VolumeMounts).To(HaveLen(2))
-		cpuReq := c.Resources.Requests[corev1.ResourceCPU]
-		Expect(cpuReq.Value()).To(BeNumerically("==", 1))
-		cpuLim := c.Resources.Limits[corev1.ResourceCPU]
-		Expect(cpuLim.Value()).To(BeNumerically("==", 4))
-		memReq := c.Resources.Requests[corev1.ResourceMemory]
-		Expect(memReq.Value()).To(BeNumerically("==", 1<<30))
-		memLim := c.Resources.Limits[corev1.ResourceMemory]
-		Expect(memLim.Value()).To(BeNumerically("==", 10<<30))
-
-		Expect(role.Labels).NotTo(BeEmpty())
-		Expect(role.OwnerReferences).NotTo(BeEmpty())
-		Expect(role.Rules).NotTo(BeEmpty())
-		Expect(roleBinding.Labels).NotTo(BeEmpty())
-		Expect(roleBinding.OwnerReferences).NotTo(BeEmpty())
-		Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
-		Expect(roleBinding.Subjects).To(HaveLen(1))
-		Expect(roleBinding.Subjects[0].Name).To(Equal("foo"))
-
-		By("updating a backup policy")
-		bp = &mocov1beta2.BackupPolicy{}
-		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test-policy"}, bp)
-		Expect(err).NotTo(HaveOccurred())
-		bp.Spec.ActiveDeadlineSeconds = nil
-		bp.Spec.BackoffLimit = nil
-		bp.Spec.ConcurrencyPolicy = batchv1.AllowConcurrent
-		bp.Spec.StartingDeadlineSeconds = nil
-		bp.Spec.Schedule = "*/5 1 * * *"
-		bp.Spec.SuccessfulJobsHistoryLimit = nil
-		bp.Spec.FailedJobsHistoryLimit = nil
-		jc = &bp.Spec.JobConfig
-		jc.Threads = 1
-		jc.ServiceAccountName = "oof"
-		jc.CPU = nil
-		jc.MaxCPU = nil
-		jc.Memory = nil
-		jc.MaxMemory = nil
-		jc.Env = nil
-		jc.EnvFrom = nil
-		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
-			HostPath: &corev1ac.HostPathVolumeSourceApplyConfiguration{
-				Path: ptr.To[string]("/host"),
-			},
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should pass the part size of multipart uploads to the backup job", func() {
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "part-size"
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.JobConfig.Threads = 1
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
 		}
-		jc.BucketConfig.BucketName = "mybucket2"
-		jc.BucketConfig.EndpointURL = ""
-		jc.BucketConfig.Region = ""
-		jc.BucketConfig.UsePathStyle = false
-		err = k8sClient.Update(ctx, bp)
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		bp.Spec.JobConfig.PartSize = ptr.To(resource.MustParse("64Mi"))
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
+		err = k8sClient.Create(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
+		var cj *batchv1.CronJob
 		Eventually(func() error {
 			cj = &batchv1.CronJob{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
-				return err
-			}
-			if cj.Spec.Schedule != "*/5 1 * * *" {
-				return errors.New("CronJob is not updated")
-			}
-			return nil
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj)
 		}).Should(Succeed())
 
-		Expect(cj.Spec.StartingDeadlineSeconds).To(BeNil())
-		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1.AllowConcurrent))
-		Expect(cj.Spec.SuccessfulJobsHistoryLimit).To(Equal(ptr.To[int32](3)))
-		Expect(cj.Spec.FailedJobsHistoryLimit).To(Equal(ptr.To[int32](1)))
-		js = &cj.Spec.JobTemplate.Spec
-		Expect(js.ActiveDeadlineSeconds).To(BeNil())
-		Expect(js.BackoffLimit).To(BeNil())
-		Expect(js.Template.Spec.ServiceAccountName).To(Equal("oof"))
-		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
-		Expect(js.Template.Spec.Volumes[0].EmptyDir).To(BeNil())
-		Expect(js.Template.Spec.Volumes[0].HostPath).NotTo(BeNil())
-		Expect(js.Template.Spec.Containers).To(HaveLen(1))
-		c = &js.Template.Spec.Containers[0]
-		Expect(c.Args).To(Equal([]string{
+		Expect(cj.Spec.JobTemplate.Spec.Template.Spec.Containers).To(HaveLen(1))
+		Expect(cj.Spec.JobTemplate.Spec.Template.Spec.Containers[0].Args).To(Equal([]string{
 			"backup",
 			"--threads=1",
+			"--part-size=67108864",
 			"--backend-type=s3",
-			"mybucket2",
+			"mybucket",
 			"test",
 			"test",
 		}))
-		Expect(c.EnvFrom).To(BeEmpty())
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reconcile the maintenance CronJob", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.Maintenance = &mocov1beta2.MaintenanceSpec{
+			Schedule:  "0 3 * * *",
+			Databases: []string{"foo", "bar"},
+		}
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cj *batchv1.CronJob
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MaintenanceCronJobName()}, cj)
+		}).Should(Succeed())
+
+		Expect(cj.OwnerReferences).NotTo(BeEmpty())
+		Expect(cj.Labels).To(HaveKeyWithValue(constants.LabelAppName, constants.AppNameMaintenance))
+		Expect(cj.Spec.Schedule).To(Equal("0 3 * * *"))
+		Expect(cj.Spec.ConcurrencyPolicy).To(Equal(batchv1.ForbidConcurrent))
+		Expect(cj.Spec.Suspend).To(Equal(ptr.To(false)))
+
+		podSpec := cj.Spec.JobTemplate.Spec.Template.Spec
+		Expect(podSpec.AutomountServiceAccountToken).To(Equal(ptr.To(false)))
+		Expect(podSpec.Containers).To(HaveLen(1))
+		c := podSpec.Containers[0]
+		Expect(c.Image).To(Equal(testBackupImage))
+		Expect(c.Args).To(Equal([]string{
+			"analyze",
+			"--databases=foo,bar",
+			cluster.PodHostname(0),
+			cluster.PodHostname(1),
+			cluster.PodHostname(2),
+		}))
 		Expect(c.Env).To(HaveLen(1))
-		cpuReq = c.Resources.Requests[corev1.ResourceCPU]
-		Expect(cpuReq.Value()).To(BeNumerically("==", 4))
-		Expect(c.Resources.Limits).NotTo(HaveKey(corev1.ResourceCPU))
-		memReq = c.Resources.Requests[corev1.ResourceMemory]
-		Expect(memReq.Value()).To(BeNumerically("==", 4<<30))
-		Expect(c.Resources.Limits).NotTo(HaveKey(corev1.ResourceMemory))
+		Expect(c.Env[0].Name).To(Equal("MYSQL_PASSWORD"))
+		Expect(c.Env[0].ValueFrom.SecretKeyRef.Name).To(Equal(cluster.UserSecretName()))
+		Expect(c.Env[0].ValueFrom.SecretKeyRef.Key).To(Equal(password.AdminPasswordKey))
+
+		By("suspending the CronJob")
+		cluster = &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+		Expect(err).NotTo(HaveOccurred())
+		cluster.Spec.Maintenance.Suspend = true
+		err = k8sClient.Update(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
 
 		Eventually(func() error {
-			roleBinding = &rbacv1.RoleBinding{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding); err != nil {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MaintenanceCronJobName()}, cj); err != nil {
 				return err
 			}
-			if len(roleBinding.Subjects) != 1 {
-				return errors.New("empty subject")
-			}
-			if roleBinding.Subjects[0].Name != "oof" {
-				return errors.New("RoleBinding is not updated")
+			if cj.Spec.Suspend == nil || !*cj.Spec.Suspend {
+				return errors.New("CronJob is not suspended")
 			}
 			return nil
 		}).Should(Succeed())
 
-		By("disabling backup")
+		By("disabling maintenance")
 		cluster = &mocov1beta2.MySQLCluster{}
 		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
 		Expect(err).NotTo(HaveOccurred())
-		cluster.Spec.BackupPolicyName = nil
+		cluster.Spec.Maintenance = nil
 		err = k8sClient.Update(ctx, cluster)
 		Expect(err).NotTo(HaveOccurred())
 
-		Eventually(func() bool {
+		Eventually(func() error {
 			cj = &batchv1.CronJob{}
-			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test-policy"}, cj)
-			return apierrors.IsNotFound(err)
-		}).Should(BeTrue())
-
-		Eventually(func() bool {
-			role = &rbacv1.Role{}
-			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, role)
-			return apierrors.IsNotFound(err)
-		}).Should(BeTrue())
-
-		Eventually(func() bool {
-			roleBinding = &rbacv1.RoleBinding{}
-			err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, roleBinding)
-			return apierrors.IsNotFound(err)
-		}).Should(BeTrue())
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.MaintenanceCronJobName()}, cj)
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			return errors.New("CronJob still exists")
+		}).Should(Succeed())
 	})
 
-	It("should reconcile restore related resources", func() {
-		By("creating a MySQLCluster with restore spec")
-		now := metav1.Now()
+	It("should mount the CA bundle of the object storage in backup and restore jobs", func() {
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "ca-bundle"
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.JobConfig.Threads = 1
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		bp.Spec.JobConfig.CABundle = &mocov1beta2.CABundleSource{ConfigMapName: "object-store-ca", Key: "bundle.pem"}
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
 		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
 		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
 			SourceName:      "single",
 			SourceNamespace: "ns",
-			RestorePoint:    now,
+			RestorePoint:    metav1.Now(),
 		}
-		jc := &cluster.Spec.Restore.JobConfig
-		jc.Threads = 3
-		jc.ServiceAccountName = "foo"
-		jc.CPU = resource.NewQuantity(1, resource.DecimalSI)
-		jc.MaxCPU = resource.NewQuantity(4, resource.DecimalSI)
-		jc.Memory = resource.NewQuantity(1<<30, resource.DecimalSI)
-		jc.MaxMemory = resource.NewQuantity(10<<30, resource.DecimalSI)
-		jc.Env = []mocov1beta2.EnvVarApplyConfiguration{{Name: ptr.To[string]("TEST"), Value: ptr.To[string]("123")}}
-		jc.EnvFrom = []mocov1beta2.EnvFromSourceApplyConfiguration{
-			{
-				ConfigMapRef: &corev1ac.ConfigMapEnvSourceApplyConfiguration{
-					LocalObjectReferenceApplyConfiguration: corev1ac.LocalObjectReferenceApplyConfiguration{
-						Name: ptr.To[string]("bucket-config"),
+		cluster.Spec.Restore.JobConfig = bp.Spec.JobConfig
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		By("not creating jobs until the ConfigMap exists")
+		Consistently(func() error {
+			cj := &batchv1.CronJob{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj)
+			if err == nil {
+				return errors.New("CronJob is created without the CA bundle")
+			}
+			return client.IgnoreNotFound(err)
+		}, 3*time.Second).Should(Succeed())
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "CABundleNotFound" {
+					return nil
+				}
+			}
+			return errors.New("no CABundleNotFound event")
+		}).Should(Succeed())
+
+		By("recording the events only once")
+		countEvents := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "CABundleNotFound" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+		Eventually(countEvents).Should(BeNumerically("==", 2))
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Labels = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+		Consistently(countEvents, 3*time.Second).Should(BeNumerically("==", 2))
+
+		By("creating the jobs after the ConfigMap is created")
+		cm := &corev1.ConfigMap{}
+		cm.Namespace = "test"
+		cm.Name = "object-store-ca"
+		cm.Data = map[string]string{"bundle.pem": "dummy"}
+		err = k8sClient.Create(ctx, cm)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cj *batchv1.CronJob
+		var job *batchv1.Job
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
+				return err
+			}
+			job = &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		for _, podSpec := range []corev1.PodSpec{cj.Spec.JobTemplate.Spec.Template.Spec, job.Spec.Template.Spec} {
+			Expect(podSpec.Volumes).To(ContainElement(corev1.Volume{
+				Name: constants.JobCABundleVolumeName,
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{Name: "object-store-ca"},
+						Items:                []corev1.KeyToPath{{Key: "bundle.pem", Path: constants.JobCABundleFilename}},
+						DefaultMode:          ptr.To[int32](0644),
 					},
 				},
-			},
+			}))
+			Expect(podSpec.Containers).To(HaveLen(1))
+			c := podSpec.Containers[0]
+			Expect(c.Args).To(ContainElement("--ca-cert=/etc/moco-backup/ca/ca-bundle.crt"))
+			Expect(c.Env).To(ContainElement(corev1.EnvVar{Name: "AWS_CA_BUNDLE", Value: "/etc/moco-backup/ca/ca-bundle.crt"}))
+			Expect(c.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      constants.JobCABundleVolumeName,
+				MountPath: constants.JobCABundleMountPath,
+				ReadOnly:  true,
+			}))
 		}
-		jc.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should mount the working directory at workVolumeMountPath in backup and restore jobs", func() {
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "work-dir"
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.JobConfig.Threads = 1
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
 			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
 		}
-		jc.Volumes = []mocov1beta2.VolumeApplyConfiguration{
-			{
-				Name: ptr.To[string]("test"),
-				VolumeSourceApplyConfiguration: corev1ac.VolumeSourceApplyConfiguration{
-					EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
-				},
-			},
+		bp.Spec.JobConfig.WorkVolumeMountPath = "/var/lib/backup"
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:      "single",
+			SourceNamespace: "ns",
+			RestorePoint:    metav1.Now(),
 		}
-		jc.VolumeMounts = []mocov1beta2.VolumeMountApplyConfiguration{
-			{
-				Name:      ptr.To[string]("test"),
-				MountPath: ptr.To[string]("/path/to/dir"),
-			},
+		cluster.Spec.Restore.JobConfig = bp.Spec.JobConfig
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		var cj *batchv1.CronJob
+		var job *batchv1.Job
+		Eventually(func() error {
+			cj = &batchv1.CronJob{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj); err != nil {
+				return err
+			}
+			job = &batchv1.Job{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job)
+		}).Should(Succeed())
+
+		for _, podSpec := range []corev1.PodSpec{cj.Spec.JobTemplate.Spec.Template.Spec, job.Spec.Template.Spec} {
+			Expect(podSpec.Containers).To(HaveLen(1))
+			c := podSpec.Containers[0]
+			Expect(c.Args).To(ContainElement("--work-dir=/var/lib/backup"))
+			Expect(c.VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      "work",
+				MountPath: "/var/lib/backup",
+			}))
+			Expect(c.VolumeMounts).NotTo(ContainElement(HaveField("MountPath", "/work")))
 		}
-		jc.BucketConfig.BucketName = "mybucket"
-		jc.BucketConfig.EndpointURL = "https://foo.bar.baz"
-		jc.BucketConfig.Region = "us-east-1"
-		jc.BucketConfig.UsePathStyle = true
-		err := k8sClient.Create(ctx, cluster)
+
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should bind the referenced roles to the ServiceAccounts of backup and restore jobs", func() {
+		bp := &mocov1beta2.BackupPolicy{}
+		bp.Namespace = "test"
+		bp.Name = "shared-role"
+		bp.Spec.Schedule = "*/5 * * * *"
+		bp.Spec.JobConfig.ServiceAccountName = "foo"
+		bp.Spec.JobConfig.WorkVolume = mocov1beta2.VolumeSourceApplyConfiguration{
+			EmptyDir: &corev1ac.EmptyDirVolumeSourceApplyConfiguration{},
+		}
+		bp.Spec.JobConfig.BucketConfig.BucketName = "mybucket"
+		bp.Spec.JobConfig.RoleRef = &mocov1beta2.JobRoleRef{Kind: "ClusterRole", Name: "moco-backup-shared"}
+		err := k8sClient.Create(ctx, bp)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, bp)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To(bp.Name)
+		cluster.Spec.Restore = &mocov1beta2.RestoreSpec{
+			SourceName:      "single",
+			SourceNamespace: "ns",
+			RestorePoint:    metav1.Now(),
+		}
+		cluster.Spec.Restore.JobConfig = bp.Spec.JobConfig
+		cluster.Spec.Restore.JobConfig.RoleRef = &mocov1beta2.JobRoleRef{Kind: "Role", Name: "restore-shared"}
+		err = k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		countEvents := func(reason string) (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == reason {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+
+		By("not binding roles until they exist")
+		Consistently(func() error {
+			rb := &rbacv1.RoleBinding{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, rb)
+			if err == nil {
+				return errors.New("RoleBinding is created for a missing role")
+			}
+			return client.IgnoreNotFound(err)
+		}, 3*time.Second).Should(Succeed())
+		Eventually(func() (int32, error) {
+			return countEvents("JobRoleNotFound")
+		}).Should(BeNumerically("==", 2))
+		Consistently(func() (int32, error) {
+			return countEvents("JobRoleNotFound")
+		}, 3*time.Second).Should(BeNumerically("==", 2))
+
+		By("reconciling the other resources")
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, &batchv1.Job{})
 		Expect(err).NotTo(HaveOccurred())
-
-		var job *batchv1.Job
-		var role *rbacv1.Role
-		var roleBinding *rbacv1.RoleBinding
 		Eventually(func() error {
-			job = &batchv1.Job{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreJobName()}, job); err != nil {
-				return err
-			}
-			role = &rbacv1.Role{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err != nil {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
 				return err
 			}
-			roleBinding = &rbacv1.RoleBinding{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err != nil {
-				return err
+			cond := meta.FindStatusCondition(c.Status.Conditions, mocov1beta2.ConditionReconcileSuccess)
+			if cond == nil || cond.Status != metav1.ConditionFalse {
+				return fmt.Errorf("the reconcile error is not recorded: %+v", cond)
 			}
 			return nil
 		}).Should(Succeed())
 
-		Expect(job.Labels).NotTo(BeEmpty())
-		Expect(job.OwnerReferences).NotTo(BeEmpty())
-		js := &job.Spec
-		Expect(js.BackoffLimit).To(Equal(ptr.To[int32](0)))
-		Expect(js.Template.Labels).NotTo(BeEmpty())
-		Expect(js.Template.Spec.RestartPolicy).To(Equal(corev1.RestartPolicyNever))
-		Expect(js.Template.Spec.ServiceAccountName).To(Equal("foo"))
-		Expect(js.Template.Spec.Volumes).To(HaveLen(2))
-		Expect(js.Template.Spec.Volumes[0].EmptyDir).NotTo(BeNil())
-		Expect(js.Template.Spec.Volumes[1].EmptyDir).NotTo(BeNil())
-		Expect(js.Template.Spec.Containers).To(HaveLen(1))
-		c := &js.Template.Spec.Containers[0]
-		Expect(c.Name).To(Equal("restore"))
-		Expect(c.Image).To(Equal(testBackupImage))
-		Expect(c.Args).To(Equal([]string{
-			"restore",
-			"--threads=3",
-			"--region=us-east-1",
-			"--endpoint=https://foo.bar.baz",
-			"--use-path-style",
-			"--backend-type=s3",
-			"mybucket",
-			"ns",
-			"single",
-			"test",
-			"test",
-			now.UTC().Format(constants.BackupTimeFormat),
-		}))
-		Expect(c.EnvFrom).To(HaveLen(1))
-		Expect(c.Env).To(HaveLen(2))
-		Expect(c.VolumeMounts).To(HaveLen(2))
-		cpuReq := c.Resources.Requests[corev1.ResourceCPU]
-		Expect(cpuReq.Value()).To(BeNumerically("==", 1))
-		cpuLim := c.Resources.Limits[corev1.ResourceCPU]
-		Expect(cpuLim.Value()).To(BeNumerically("==", 4))
-		memReq := c.Resources.Requests[corev1.ResourceMemory]
-		Expect(memReq.Value()).To(BeNumerically("==", 1<<30))
-		memLim := c.Resources.Limits[corev1.ResourceMemory]
-		Expect(memLim.Value()).To(BeNumerically("==", 10<<30))
-
-		Expect(role.Labels).NotTo(BeEmpty())
-		Expect(role.OwnerReferences).NotTo(BeEmpty())
-		Expect(role.Rules).NotTo(BeEmpty())
-		Expect(roleBinding.Labels).NotTo(BeEmpty())
-		Expect(roleBinding.OwnerReferences).NotTo(BeEmpty())
-		Expect(roleBinding.RoleRef.Name).To(Equal(role.Name))
-		Expect(roleBinding.Subjects).To(HaveLen(1))
-		Expect(roleBinding.Subjects[0].Name).To(Equal("foo"))
+		By("not binding roles without the allow-list label")
+		clusterRole := &rbacv1.ClusterRole{}
+		clusterRole.Name = "moco-backup-shared"
+		err = k8sClient.Create(ctx, clusterRole)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.Delete(ctx, clusterRole)
+			Expect(err).NotTo(HaveOccurred())
+		}()
+		Eventually(func() (int32, error) {
+			return countEvents("JobRoleNotAllowed")
+		}).Should(BeNumerically("==", 1))
+		Consistently(func() (int32, error) {
+			return countEvents("JobRoleNotAllowed")
+		}, 3*time.Second).Should(BeNumerically("==", 1))
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, &rbacv1.RoleBinding{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		By("binding the labeled roles")
+		clusterRole.Labels = map[string]string{constants.LabelJobRole: "true"}
+		err = k8sClient.Update(ctx, clusterRole)
+		Expect(err).NotTo(HaveOccurred())
+		role := &rbacv1.Role{}
+		role.Namespace = "test"
+		role.Name = "restore-shared"
+		role.Labels = map[string]string{constants.LabelJobRole: "true"}
+		err = k8sClient.Create(ctx, role)
+		Expect(err).NotTo(HaveOccurred())
 
-		By("changing cluster status")
+		var backupRB, restoreRB *rbacv1.RoleBinding
 		Eventually(func() error {
-			cluster := &mocov1beta2.MySQLCluster{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+			backupRB = &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, backupRB); err != nil {
 				return err
 			}
-			t := metav1.Now()
-			cluster.Status.RestoredTime = &t
-			return k8sClient.Status().Update(ctx, cluster)
+			restoreRB = &rbacv1.RoleBinding{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, restoreRB)
 		}).Should(Succeed())
 
-		time.Sleep(5 * time.Second)
+		Expect(backupRB.RoleRef.Kind).To(Equal("ClusterRole"))
+		Expect(backupRB.RoleRef.Name).To(Equal("moco-backup-shared"))
+		Expect(restoreRB.RoleRef.Kind).To(Equal("Role"))
+		Expect(restoreRB.RoleRef.Name).To(Equal("restore-shared"))
+
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, &rbacv1.Role{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		err = k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, &rbacv1.Role{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
 
-		err = k8sClient.Delete(ctx, role)
+		By("switching back to the per-cluster role")
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(bp), bp)
 		Expect(err).NotTo(HaveOccurred())
-		err = k8sClient.Delete(ctx, roleBinding)
+		bp.Spec.JobConfig.RoleRef = nil
+		err = k8sClient.Update(ctx, bp)
 		Expect(err).NotTo(HaveOccurred())
 
-		Eventually(func() bool {
-			role = &rbacv1.Role{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err == nil {
-				return false
+		Eventually(func() error {
+			rb := &rbacv1.RoleBinding{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, rb); err != nil {
+				return err
 			}
-			roleBinding = &rbacv1.RoleBinding{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err == nil {
-				return false
+			if rb.RoleRef.Kind != "Role" || rb.RoleRef.Name != cluster.BackupRoleName() {
+				return fmt.Errorf("RoleBinding is not updated: %+v", rb.RoleRef)
 			}
-			return true
-		}).Should(BeTrue())
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupRoleName()}, &rbacv1.Role{})
+		}).Should(Succeed())
 
-		Consistently(func() bool {
-			role = &rbacv1.Role{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, role); err == nil {
-				return false
-			}
-			roleBinding = &rbacv1.RoleBinding{}
-			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.RestoreRoleName()}, roleBinding); err == nil {
-				return false
-			}
-			return true
-		}, 5).Should(BeTrue())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.CronJob{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &batchv1.Job{}, client.InNamespace("test"), client.PropagationPolicy(metav1.DeletePropagationBackground))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.Role{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+		err = k8sClient.DeleteAllOf(ctx, &rbacv1.RoleBinding{}, client.InNamespace("test"))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should record the results of the backup Jobs created by the CronJob", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.BackupPolicyName = ptr.To[string]("test-policy")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		cj := &batchv1.CronJob{}
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.BackupCronJobName()}, cj)
+		}).Should(Succeed())
+
+		job := &batchv1.Job{}
+		job.Namespace = "test"
+		job.Name = cluster.BackupCronJobName() + "-1"
+		job.Labels = labelSetForJob(cluster)
+		job.OwnerReferences = []metav1.OwnerReference{{
+			APIVersion: "batch/v1",
+			Kind:       "CronJob",
+			Name:       cj.Name,
+			UID:        cj.UID,
+			Controller: ptr.To(true),
+		}}
+		job.Spec.Template.Spec.RestartPolicy = corev1.RestartPolicyNever
+		job.Spec.Template.Spec.Containers = []corev1.Container{{Name: "backup", Image: "moco-backup"}}
+		err = k8sClient.Create(ctx, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.BackupAttempts).To(HaveLen(1))
+			g.Expect(cluster.Status.BackupAttempts[0].JobName).To(Equal(job.Name))
+			g.Expect(cluster.Status.BackupAttempts[0].Result).To(Equal(mocov1beta2.BackupAttemptRunning))
+		}).Should(Succeed())
+
+		By("completing the Job")
+		now := metav1.Now()
+		job.Status.StartTime = &now
+		job.Status.CompletionTime = &now
+		job.Status.Succeeded = 1
+		job.Status.Conditions = []batchv1.JobCondition{{
+			Type:               batchv1.JobComplete,
+			Status:             corev1.ConditionTrue,
+			LastTransitionTime: now,
+		}}
+		err = k8sClient.Status().Update(ctx, job)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func(g Gomega) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster)
+			g.Expect(err).NotTo(HaveOccurred())
+			g.Expect(cluster.Status.BackupAttempts).To(HaveLen(1))
+			g.Expect(cluster.Status.BackupAttempts[0].Result).To(Equal(mocov1beta2.BackupAttemptSucceeded))
+		}).Should(Succeed())
 	})
 
 	It("should reconcile a pod disruption budget when backup cron job is running", func() {
@@ -1815,6 +5659,347 @@ var _ = Describe("MySQLCluster reconciler", func() {
 		}).Should(Succeed())
 	})
 
+	It("should reconcile the other resources when the ConfigMap for my.cnf is missing", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.MySQLConfigMapName = ptr.To[string]("user-conf")
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return err
+			}
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.Kind == "MySQLCluster" && ev.InvolvedObject.Name == cluster.Name && ev.Reason == "MySQLConfigMapNotFound" {
+					if !strings.Contains(ev.Message, "user-conf") {
+						return fmt.Errorf("unexpected message: %s", ev.Message)
+					}
+					return nil
+				}
+			}
+			return errors.New("no MySQLConfigMapNotFound event")
+		}).Should(Succeed())
+
+		By("checking the services and secrets are reconciled")
+		Eventually(func() error {
+			for _, name := range []string{cluster.HeadlessServiceName(), cluster.PrimaryServiceName(), cluster.ReplicaServiceName()} {
+				svc := &corev1.Service{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: name}, svc); err != nil {
+					return err
+				}
+			}
+			secret := &corev1.Secret{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.UserSecretName()}, secret)
+		}).Should(Succeed())
+
+		Consistently(func() bool {
+			sts := &appsv1.StatefulSet{}
+			err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+			return apierrors.IsNotFound(err)
+		}, 3).Should(BeTrue())
+
+		cluster2 := &mocov1beta2.MySQLCluster{}
+		err = k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster2)
+		Expect(err).NotTo(HaveOccurred())
+		cond := meta.FindStatusCondition(cluster2.Status.Conditions, mocov1beta2.ConditionReconcileSuccess)
+		Expect(cond).NotTo(BeNil())
+		Expect(cond.Status).To(Equal(metav1.ConditionFalse))
+
+		By("recording the event only once")
+		Eventually(func() error {
+			c := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), c); err != nil {
+				return err
+			}
+			c.Labels = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, c)
+		}).Should(Succeed())
+		Consistently(func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "MySQLConfigMapNotFound" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}, 3*time.Second).Should(BeNumerically("==", 1))
+
+		By("creating the ConfigMap")
+		userCM := &corev1.ConfigMap{}
+		userCM.Namespace = "test"
+		userCM.Name = "user-conf"
+		userCM.Data = map[string]string{"foo": "bar"}
+		err = k8sClient.Create(ctx, userCM)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(func() error {
+			sts := &appsv1.StatefulSet{}
+			return k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts)
+		}).Should(Succeed())
+
+		Eventually(func() error {
+			cluster2 := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster2); err != nil {
+				return err
+			}
+			if cluster2.Status.MyCnfConfigMapName == "" {
+				return errors.New("my.cnf ConfigMap name is not recorded")
+			}
+			cond := meta.FindStatusCondition(cluster2.Status.Conditions, mocov1beta2.ConditionReconcileSuccess)
+			if cond == nil || cond.Status != metav1.ConditionTrue {
+				return errors.New("reconciliation has not succeeded")
+			}
+			return nil
+		}).Should(Succeed())
+	})
+
+	It("should roll out the Pod template one instance at a time with canaryUpgrade", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CanaryUpgrade = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		getPartition := func() (int32, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0, err
+			}
+			if sts.Spec.UpdateStrategy.RollingUpdate == nil || sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+				return 0, errors.New("partition is not set")
+			}
+			return *sts.Spec.UpdateStrategy.RollingUpdate.Partition, nil
+		}
+		setStsStatus := func(updated, ready int32) {
+			Eventually(func() error {
+				sts := &appsv1.StatefulSet{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+					return err
+				}
+				sts.Status.Replicas = 3
+				sts.Status.ReadyReplicas = ready
+				sts.Status.UpdatedReplicas = updated
+				sts.Status.ObservedGeneration = sts.Generation
+				return k8sClient.Status().Update(ctx, sts)
+			}).Should(Succeed())
+		}
+		setClusterStatus := func(healthy metav1.ConditionStatus, errant []int) {
+			Eventually(func() error {
+				cluster := &mocov1beta2.MySQLCluster{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+					return err
+				}
+				meta.SetStatusCondition(&cluster.Status.Conditions, metav1.Condition{
+					Type:   mocov1beta2.ConditionHealthy,
+					Status: healthy,
+					Reason: "Test",
+				})
+				cluster.Status.ErrantReplicaList = errant
+				cluster.Status.ErrantReplicas = len(errant)
+				return k8sClient.Status().Update(ctx, cluster)
+			}).Should(Succeed())
+		}
+
+		Eventually(getPartition).Should(Equal(int32(0)))
+		setStsStatus(3, 3)
+		setClusterStatus(metav1.ConditionTrue, nil)
+		// the time of a condition has a resolution of seconds.
+		time.Sleep(time.Second)
+
+		By("updating the Pod template")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			cluster.Spec.PodTemplate.Annotations = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, cluster)
+		}).Should(Succeed())
+		Eventually(getPartition).Should(Equal(int32(2)))
+
+		By("advancing the partition after the canary instance becomes ready")
+		setStsStatus(0, 2)
+		Consistently(getPartition, 3).Should(Equal(int32(2)))
+
+		By("not advancing the partition with the Healthy condition before the restart")
+		setStsStatus(1, 3)
+		Consistently(getPartition, 3).Should(Equal(int32(2)))
+
+		setStsStatus(1, 2)
+		setClusterStatus(metav1.ConditionFalse, nil)
+		setClusterStatus(metav1.ConditionTrue, nil)
+		setStsStatus(1, 3)
+		Eventually(getPartition).Should(Equal(int32(1)))
+
+		By("holding the partition if an updated instance is errant")
+		setClusterStatus(metav1.ConditionFalse, []int{1})
+		setStsStatus(2, 3)
+		countHeld := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "CanaryUpgradeHeld" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+		Eventually(countHeld).Should(Equal(int32(1)))
+		Consistently(getPartition, 3).Should(Equal(int32(1)))
+
+		By("recording the CanaryUpgradeHeld event only once")
+		setStsStatus(2, 3)
+		Consistently(countHeld, 3).Should(Equal(int32(1)))
+
+		By("resuming the rollout after the cluster becomes healthy")
+		setClusterStatus(metav1.ConditionTrue, nil)
+		Eventually(getPartition).Should(Equal(int32(0)))
+	})
+
+	It("should switch over the primary before the canary upgrade updates it", func() {
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CanaryUpgrade = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		pod := &corev1.Pod{}
+		pod.Namespace = "test"
+		pod.Name = cluster.PodName(2)
+		pod.Labels = labelSet(cluster, false)
+		pod.Spec.Containers = []corev1.Container{{Name: "mysqld", Image: "moco-mysql:latest"}}
+		err = k8sClient.Create(ctx, pod)
+		Expect(err).NotTo(HaveOccurred())
+		defer func() {
+			err := k8sClient.DeleteAllOf(ctx, &corev1.Pod{}, client.InNamespace("test"), client.GracePeriodSeconds(0))
+			Expect(err).NotTo(HaveOccurred())
+		}()
+
+		getPartition := func() (int32, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0, err
+			}
+			if sts.Spec.UpdateStrategy.RollingUpdate == nil || sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+				return 0, errors.New("partition is not set")
+			}
+			return *sts.Spec.UpdateStrategy.RollingUpdate.Partition, nil
+		}
+		setPrimary := func(index int) {
+			Eventually(func() error {
+				cluster := &mocov1beta2.MySQLCluster{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+					return err
+				}
+				cluster.Status.CurrentPrimaryIndex = index
+				return k8sClient.Status().Update(ctx, cluster)
+			}).Should(Succeed())
+		}
+
+		Eventually(getPartition).Should(Equal(int32(0)))
+		setPrimary(2)
+
+		By("updating the Pod template")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			cluster.Spec.PodTemplate.Annotations = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, cluster)
+		}).Should(Succeed())
+
+		By("holding all the instances until the primary is switched over")
+		Eventually(getPartition).Should(Equal(int32(3)))
+		Eventually(func() (string, error) {
+			pod := &corev1.Pod{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PodName(2)}, pod); err != nil {
+				return "", err
+			}
+			return pod.Annotations[constants.AnnDemote], nil
+		}).Should(Equal("true"))
+		Consistently(getPartition, 3).Should(Equal(int32(3)))
+
+		By("updating the canary instance after the switchover")
+		setPrimary(0)
+		Eventually(getPartition).Should(Equal(int32(2)))
+	})
+
+	It("should record an event when the canary instance does not become ready", func() {
+		stopFunc()
+		time.Sleep(100 * time.Millisecond)
+		startManager(func(r *MySQLClusterReconciler) {
+			r.CanaryReadyTimeout = 3 * time.Second
+		})
+
+		cluster := testNewMySQLCluster("test")
+		cluster.Spec.CanaryUpgrade = true
+		err := k8sClient.Create(ctx, cluster)
+		Expect(err).NotTo(HaveOccurred())
+
+		setStsStatus := func(updated, ready int32) {
+			Eventually(func() error {
+				sts := &appsv1.StatefulSet{}
+				if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+					return err
+				}
+				sts.Status.Replicas = 3
+				sts.Status.ReadyReplicas = ready
+				sts.Status.UpdatedReplicas = updated
+				sts.Status.ObservedGeneration = sts.Generation
+				return k8sClient.Status().Update(ctx, sts)
+			}).Should(Succeed())
+		}
+		countStalled := func() (int32, error) {
+			events := &corev1.EventList{}
+			if err := k8sClient.List(ctx, events, client.InNamespace("test")); err != nil {
+				return 0, err
+			}
+			var count int32
+			for _, ev := range events.Items {
+				if ev.InvolvedObject.UID == cluster.UID && ev.Reason == "CanaryUpgradeStalled" {
+					count += ev.Count
+				}
+			}
+			return count, nil
+		}
+
+		setStsStatus(3, 3)
+
+		By("updating the Pod template")
+		Eventually(func() error {
+			cluster := &mocov1beta2.MySQLCluster{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: "test"}, cluster); err != nil {
+				return err
+			}
+			cluster.Spec.PodTemplate.Annotations = map[string]string{"foo": "bar"}
+			return k8sClient.Update(ctx, cluster)
+		}).Should(Succeed())
+		Eventually(func() (int32, error) {
+			sts := &appsv1.StatefulSet{}
+			if err := k8sClient.Get(ctx, client.ObjectKey{Namespace: "test", Name: cluster.PrefixedName()}, sts); err != nil {
+				return 0, err
+			}
+			if sts.Spec.UpdateStrategy.RollingUpdate == nil || sts.Spec.UpdateStrategy.RollingUpdate.Partition == nil {
+				return 0, errors.New("partition is not set")
+			}
+			return *sts.Spec.UpdateStrategy.RollingUpdate.Partition, nil
+		}).Should(Equal(int32(2)))
+
+		By("keeping the canary instance not ready")
+		setStsStatus(1, 2)
+		Eventually(countStalled, 10).Should(Equal(int32(1)))
+
+		setStsStatus(1, 1)
+		Consistently(countStalled, 3).Should(Equal(int32(1)))
+	})
+
 	It("should sets ConditionStatefulSetReady to be false when status of StatefulSet does not found", func() {
 		cluster := testNewMySQLCluster("test")
 		cluster.Spec.MySQLConfigMapName = ptr.To[string]("foobarhoge")
@@ -0,0 +1,136 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/event"
+	"github.com/cybozu-go/moco/pkg/password"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcilePasswordRotation generates new passwords of the users for MOCO when the rotation
+// is requested with the annotation, and updates the controller Secret with them once mysqld
+// accepts both the current and new passwords.  The other steps are done by the clustering.
+//
+// The rotation is refused while other clusters replicate from the cluster because
+// the new passwords would be replicated to them without updating their Secrets.
+func (r *MySQLClusterReconciler) reconcilePasswordRotation(ctx context.Context, cluster *mocov1beta2.MySQLCluster, controllerSecret *corev1.Secret) error {
+	log := crlog.FromContext(ctx)
+
+	retained := cluster.Status.PasswordRotation != nil && cluster.Status.PasswordRotation.Retained
+	_, requested := cluster.Annotations[constants.AnnRotatePasswords]
+
+	name := cluster.RotatingPasswordSecretName()
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, secret)
+	switch {
+	case apierrors.IsNotFound(err):
+		// A rotation is in progress if the passwords are retained.
+		if !requested || retained {
+			return nil
+		}
+		if cluster.IsReadOnly() {
+			log.Info("passwords of a read-only cluster cannot be rotated")
+			return nil
+		}
+		downstreams, err := r.downstreamClusters(ctx, cluster)
+		if err != nil {
+			return err
+		}
+		if len(downstreams) > 0 {
+			log.Info("passwords of a replication source cannot be rotated", "downstreams", downstreams)
+			event.PasswordRotationRefused.Emit(cluster, r.Recorder, strings.Join(downstreams, ", "))
+			return r.removeRotatePasswordsAnnotation(ctx, cluster)
+		}
+
+		passwd, err := password.NewMySQLPassword()
+		if err != nil {
+			return err
+		}
+		secret = passwd.ToSecret()
+		secret.Namespace = cluster.Namespace
+		secret.Name = name
+		secret.Labels = labelSet(cluster, false)
+		if err := controllerutil.SetControllerReference(cluster, secret, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set ownerReference to Secret %s/%s: %w", cluster.Namespace, name, err)
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			return fmt.Errorf("failed to create Secret %s/%s: %w", cluster.Namespace, name, err)
+		}
+		log.Info("created Secret for the new passwords", "secretName", name)
+		event.PasswordRotationStarted.Emit(cluster, r.Recorder)
+	case err != nil:
+		return fmt.Errorf("failed to get Secret %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	// The request is accepted, or ignored while the rotation is in progress.
+	if requested {
+		if err := r.removeRotatePasswordsAnnotation(ctx, cluster); err != nil {
+			return err
+		}
+	}
+
+	if !retained {
+		return nil
+	}
+
+	controllerSecret.Data = secret.Data
+	if controllerSecret.Annotations == nil {
+		controllerSecret.Annotations = make(map[string]string)
+	}
+	controllerSecret.Annotations[constants.AnnPasswordRevision] = string(secret.UID)
+	if err := r.Update(ctx, controllerSecret); err != nil {
+		return fmt.Errorf("failed to update controller Secret %s/%s: %w", controllerSecret.Namespace, controllerSecret.Name, err)
+	}
+	log.Info("updated controller Secret with the new passwords", "secretName", controllerSecret.Name)
+
+	if err := r.Delete(ctx, secret); client.IgnoreNotFound(err) != nil {
+		return fmt.Errorf("failed to delete Secret %s/%s: %w", cluster.Namespace, name, err)
+	}
+	return nil
+}
+
+func (r *MySQLClusterReconciler) removeRotatePasswordsAnnotation(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	orig := cluster.DeepCopy()
+	delete(cluster.Annotations, constants.AnnRotatePasswords)
+	if err := r.Patch(ctx, cluster, client.MergeFrom(orig)); err != nil {
+		return fmt.Errorf("failed to remove %s annotation: %w", constants.AnnRotatePasswords, err)
+	}
+	return nil
+}
+
+// downstreamClusters returns the names of the clusters replicating from the cluster.
+func (r *MySQLClusterReconciler) downstreamClusters(ctx context.Context, cluster *mocov1beta2.MySQLCluster) ([]string, error) {
+	clusters := &mocov1beta2.MySQLClusterList{}
+	if err := r.List(ctx, clusters, client.MatchingFields{sourceClusterIndexField: client.ObjectKeyFromObject(cluster).String()}); err != nil {
+		return nil, fmt.Errorf("failed to list the clusters replicating from %s/%s: %w", cluster.Namespace, cluster.Name, err)
+	}
+	var names []string
+	for _, c := range clusters.Items {
+		names = append(names, client.ObjectKeyFromObject(&c).String())
+	}
+	return names, nil
+}
+
+// passwordRevision returns the revision of the passwords of the users for MOCO.
+// It is empty if the passwords have never been rotated.  The controller Secret may not be
+// in the cache yet right after it is created, but it has not been rotated in that case.
+func (r *MySQLClusterReconciler) passwordRevision(ctx context.Context, cluster *mocov1beta2.MySQLCluster) (string, error) {
+	secret := &corev1.Secret{}
+	err := r.Get(ctx, client.ObjectKey{Namespace: r.SystemNamespace, Name: cluster.ControllerSecretName()}, secret)
+	if apierrors.IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get controller Secret: %w", err)
+	}
+	return secret.Annotations[constants.AnnPasswordRevision], nil
+}
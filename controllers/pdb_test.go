@@ -0,0 +1,28 @@
+package controllers
+
+import "testing"
+
+func TestZoneMaxUnavailable(t *testing.T) {
+	tests := []struct {
+		name     string
+		replicas int32
+		zones    map[string]int
+		expected int
+	}{
+		{"unknown zones", 5, nil, 2},
+		{"one per zone", 5, map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1}, 1},
+		{"largest zone", 5, map[string]int{"a": 2, "b": 2, "c": 1}, 2},
+		{"three zones", 3, map[string]int{"a": 1, "b": 1, "c": 1}, 1},
+		{"keep the majority", 3, map[string]int{"a": 2, "b": 1}, 1},
+		{"single zone", 5, map[string]int{"a": 5}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual := zoneMaxUnavailable(tt.replicas, tt.zones)
+			if actual != tt.expected {
+				t.Errorf("unexpected maxUnavailable: expected=%d, actual=%d", tt.expected, actual)
+			}
+		})
+	}
+}
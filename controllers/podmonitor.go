@@ -0,0 +1,97 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/utils/ptr"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+var podMonitorGVK = schema.GroupVersionKind{
+	Group:   "monitoring.coreos.com",
+	Version: "v1",
+	Kind:    "PodMonitor",
+}
+
+// reconcileV1PodMonitor reconciles the PodMonitor that scrapes mysqld_exporter in the MySQL Pods.
+// PodMonitor is handled as an unstructured object so that MOCO works without Prometheus Operator.
+func (r *MySQLClusterReconciler) reconcileV1PodMonitor(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.PodMonitorName()
+	orig := &unstructured.Unstructured{}
+	orig.SetGroupVersionKind(podMonitorGVK)
+	err := r.Client.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, orig)
+	switch {
+	case meta.IsNoMatchError(err):
+		if cluster.Spec.CreatePodMonitor {
+			log.Info("skipped creating PodMonitor because its CRD is not installed")
+		}
+		return nil
+	case apierrors.IsNotFound(err):
+		orig = nil
+	case err != nil:
+		return fmt.Errorf("failed to get PodMonitor %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	if !cluster.Spec.CreatePodMonitor || !cluster.Spec.ExporterSidecarEnabled() {
+		if orig == nil {
+			return nil
+		}
+		if err := r.Client.Delete(ctx, orig); client.IgnoreNotFound(err) != nil {
+			return fmt.Errorf("failed to delete PodMonitor %s/%s: %w", cluster.Namespace, name, err)
+		}
+		log.Info("removed PodMonitor")
+		return nil
+	}
+
+	labels := make(map[string]interface{})
+	for k, v := range labelSet(cluster, false) {
+		labels[k] = v
+	}
+	pm := &unstructured.Unstructured{
+		Object: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"selector": map[string]interface{}{
+					"matchLabels": labels,
+				},
+				"podMetricsEndpoints": []interface{}{
+					map[string]interface{}{
+						"port": constants.ExporterPortName,
+						"path": "/metrics",
+					},
+				},
+			},
+		},
+	}
+	pm.SetGroupVersionKind(podMonitorGVK)
+	pm.SetNamespace(cluster.Namespace)
+	pm.SetName(name)
+	pm.SetLabels(labelSet(cluster, false))
+	if err := controllerutil.SetControllerReference(cluster, pm, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to PodMonitor %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	err = r.Client.Patch(ctx, pm, client.Apply, &client.PatchOptions{
+		FieldManager: fieldManager,
+		Force:        ptr.To[bool](true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to reconcile PodMonitor %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	if orig == nil || orig.GetResourceVersion() != pm.GetResourceVersion() {
+		log.Info("reconciled PodMonitor", "podMonitorName", name)
+	}
+	return nil
+}
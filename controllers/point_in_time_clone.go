@@ -0,0 +1,62 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/event"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// pointInTimeCloneExpiry returns the time when the point-in-time clone is deleted.
+// It returns nil if the cluster is not a point-in-time clone or the restoration has not completed.
+func pointInTimeCloneExpiry(cluster *mocov1beta2.MySQLCluster) *metav1.Time {
+	if !cluster.IsPointInTimeClone() || cluster.Status.RestoredTime == nil {
+		return nil
+	}
+	ttl := time.Duration(cluster.Spec.Restore.PointInTimeClone.TTLSecondsAfterRestored) * time.Second
+	expiry := metav1.NewTime(cluster.Status.RestoredTime.Add(ttl))
+	return &expiry
+}
+
+// untilPointInTimeCloneExpiry returns the duration until the point-in-time clone expires.
+// It returns zero if the cluster is not a point-in-time clone, the restoration has not
+// completed, or the clone has already expired.
+func untilPointInTimeCloneExpiry(cluster *mocov1beta2.MySQLCluster, now time.Time) time.Duration {
+	expiry := pointInTimeCloneExpiry(cluster)
+	if expiry == nil {
+		return 0
+	}
+	if d := expiry.Sub(now); d > 0 {
+		return d
+	}
+	return 0
+}
+
+// deleteExpiredPointInTimeClone deletes the MySQLCluster if it is a point-in-time clone
+// and its TTL has expired.  The resources of the cluster are cleaned up by the finalizer
+// and the garbage collector as usual.  It returns true if the cluster has been deleted.
+func (r *MySQLClusterReconciler) deleteExpiredPointInTimeClone(ctx context.Context, cluster *mocov1beta2.MySQLCluster, now time.Time) (bool, error) {
+	expiry := pointInTimeCloneExpiry(cluster)
+	if expiry == nil || now.Before(expiry.Time) {
+		return false, nil
+	}
+
+	uid := cluster.UID
+	err := r.Delete(ctx, cluster, client.Preconditions{UID: &uid})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to delete the expired point-in-time clone: %w", err)
+	}
+
+	crlog.FromContext(ctx).Info("deleted the expired point-in-time clone", "expiry", expiry.Time)
+	event.PointInTimeCloneExpired.Emit(cluster, r.Recorder, expiry.UTC().Format(time.RFC3339))
+	return true, nil
+}
@@ -0,0 +1,72 @@
+package controllers
+
+import (
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestPointInTimeCloneExpiry(t *testing.T) {
+	restored := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+
+	testCases := []struct {
+		name       string
+		clone      bool
+		restored   *metav1.Time
+		now        time.Time
+		wantExpiry *time.Time
+		wantUntil  time.Duration
+	}{
+		{
+			name:     "not a clone",
+			restored: &restored,
+			now:      restored.Add(time.Hour),
+		},
+		{
+			name:  "not restored yet",
+			clone: true,
+			now:   restored.Add(time.Hour),
+		},
+		{
+			name:       "not expired",
+			clone:      true,
+			restored:   &restored,
+			now:        restored.Add(10 * time.Minute),
+			wantExpiry: ptr.To(restored.Add(time.Hour)),
+			wantUntil:  50 * time.Minute,
+		},
+		{
+			name:       "expired",
+			clone:      true,
+			restored:   &restored,
+			now:        restored.Add(2 * time.Hour),
+			wantExpiry: ptr.To(restored.Add(time.Hour)),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Spec.Restore = &mocov1beta2.RestoreSpec{}
+			if tc.clone {
+				cluster.Spec.Restore.PointInTimeClone = &mocov1beta2.PointInTimeCloneSpec{TTLSecondsAfterRestored: 3600}
+			}
+			cluster.Status.RestoredTime = tc.restored
+
+			expiry := pointInTimeCloneExpiry(cluster)
+			switch {
+			case tc.wantExpiry == nil && expiry != nil:
+				t.Errorf("unexpected expiry: %v", expiry)
+			case tc.wantExpiry != nil && (expiry == nil || !expiry.Time.Equal(*tc.wantExpiry)):
+				t.Errorf("unexpected expiry: expected %v, actual %v", *tc.wantExpiry, expiry)
+			}
+
+			if d := untilPointInTimeCloneExpiry(cluster, tc.now); d != tc.wantUntil {
+				t.Errorf("unexpected duration until the expiry: expected %v, actual %v", tc.wantUntil, d)
+			}
+		})
+	}
+}
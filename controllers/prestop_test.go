@@ -0,0 +1,83 @@
+package controllers
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+)
+
+func TestMysqldPreStopCommand(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+
+	cmd := mysqldPreStopCommand(cluster)
+	if len(cmd) != 3 || cmd[0] != "sh" || cmd[1] != "-c" {
+		t.Fatalf("unexpected command: %v", cmd)
+	}
+	script := cmd[2]
+	for _, s := range []string{"[ $i -lt 20 ]", "'moco-admin'", "'moco-repl'", "Binlog Dump%"} {
+		if !strings.Contains(script, s) {
+			t.Errorf("script does not contain %q: %s", s, script)
+		}
+	}
+
+	cluster.Spec.ReplicaDrainSeconds = ptr.To[int32](60)
+	if script := mysqldPreStopCommand(cluster)[2]; !strings.Contains(script, "[ $i -lt 60 ]") {
+		t.Errorf("replicaDrainSeconds is not used: %s", script)
+	}
+
+	cluster.Spec.ReplicaDrainSeconds = ptr.To[int32](0)
+	if diff := cmp.Diff([]string{"sleep", "20"}, mysqldPreStopCommand(cluster)); diff != "" {
+		t.Errorf("unexpected command (-want +got):\n%s", diff)
+	}
+
+	cluster.Spec.ReplicaDrainSeconds = nil
+	cluster.Spec.DisableMyCnfSecretVolume = true
+	if diff := cmp.Diff([]string{"sleep", "20"}, mysqldPreStopCommand(cluster)); diff != "" {
+		t.Errorf("unexpected command without credentials (-want +got):\n%s", diff)
+	}
+}
+
+func TestMysqldPreStopCommandDrained(t *testing.T) {
+	// a fake mysql command reporting no connections to drain.
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "mysql"), []byte("#!/bin/sh\necho 0\n"), 0755)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := mysqldPreStopCommand(&mocov1beta2.MySQLCluster{})
+	c := exec.Command(cmd[0], cmd[1:]...)
+	c.Env = append(os.Environ(), "PATH="+dir+":"+os.Getenv("PATH"))
+
+	start := time.Now()
+	if err := c.Run(); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("the hook did not finish after drained: %v", elapsed)
+	}
+}
+
+func TestSlowQueryLogAgentPreStopSeconds(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+	if n := slowQueryLogAgentPreStopSeconds(cluster); n != 25 {
+		t.Errorf("unexpected default: %d", n)
+	}
+
+	cluster.Spec.ReplicaDrainSeconds = ptr.To[int32](60)
+	if n := slowQueryLogAgentPreStopSeconds(cluster); n != 65 {
+		t.Errorf("the default is not extended for replicaDrainSeconds: %d", n)
+	}
+
+	cluster.Spec.SlowQueryLogAgentPreStopSeconds = ptr.To[int32](30)
+	if n := slowQueryLogAgentPreStopSeconds(cluster); n != 30 {
+		t.Errorf("slowQueryLogAgentPreStopSeconds is not used: %d", n)
+	}
+}
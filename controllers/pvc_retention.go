@@ -0,0 +1,47 @@
+package controllers
+
+import (
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	appsv1ac "k8s.io/client-go/applyconfigurations/apps/v1"
+)
+
+// pvcRetentionPolicy returns `spec.persistentVolumeClaimRetentionPolicy` of the StatefulSet for `cluster`.
+// It returns nil if the cluster does not specify the policy or the Kubernetes cluster does not support it.
+func (r *MySQLClusterReconciler) pvcRetentionPolicy(cluster *mocov1beta2.MySQLCluster) *appsv1ac.StatefulSetPersistentVolumeClaimRetentionPolicyApplyConfiguration {
+	policy := cluster.Spec.PersistentVolumeClaimRetentionPolicy
+	if policy == nil || !r.PVCRetentionPolicy {
+		return nil
+	}
+
+	whenDeleted := appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+	if policy.WhenDeleted == mocov1beta2.PersistentVolumeClaimRetain {
+		whenDeleted = appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+	}
+	whenScaled := appsv1.RetainPersistentVolumeClaimRetentionPolicyType
+	if policy.WhenScaled == mocov1beta2.PersistentVolumeClaimDelete {
+		whenScaled = appsv1.DeletePersistentVolumeClaimRetentionPolicyType
+	}
+	return appsv1ac.StatefulSetPersistentVolumeClaimRetentionPolicy().
+		WithWhenDeleted(whenDeleted).
+		WithWhenScaled(whenScaled)
+}
+
+// pvcOwnedByCluster returns true if the volume claim template should have the owner reference to `cluster`.
+//
+// If the StatefulSet has the retention policy, the StatefulSet controller manages the owner of
+// the PVCs, so MOCO does not add its own reference that would delete them regardless of the policy.
+// As volume claim templates are immutable, `origPVC`, the template in the current StatefulSet,
+// keeps its owner reference as is.
+func pvcOwnedByCluster(cluster *mocov1beta2.MySQLCluster, origPVC *corev1.PersistentVolumeClaim, hasRetentionPolicy bool) bool {
+	if origPVC == nil {
+		return !hasRetentionPolicy
+	}
+	for _, owner := range origPVC.OwnerReferences {
+		if owner.UID == cluster.UID {
+			return true
+		}
+	}
+	return false
+}
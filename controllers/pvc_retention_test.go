@@ -0,0 +1,82 @@
+package controllers
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestPVCRetentionPolicy(t *testing.T) {
+	cases := []struct {
+		name        string
+		supported   bool
+		policy      *mocov1beta2.PersistentVolumeClaimRetentionPolicy
+		whenDeleted appsv1.PersistentVolumeClaimRetentionPolicyType
+		whenScaled  appsv1.PersistentVolumeClaimRetentionPolicyType
+	}{
+		{"not set", true, nil, "", ""},
+		{"not supported", false, &mocov1beta2.PersistentVolumeClaimRetentionPolicy{WhenDeleted: mocov1beta2.PersistentVolumeClaimRetain}, "", ""},
+		{"defaults", true, &mocov1beta2.PersistentVolumeClaimRetentionPolicy{}, appsv1.DeletePersistentVolumeClaimRetentionPolicyType, appsv1.RetainPersistentVolumeClaimRetentionPolicyType},
+		{"retain", true, &mocov1beta2.PersistentVolumeClaimRetentionPolicy{
+			WhenDeleted: mocov1beta2.PersistentVolumeClaimRetain,
+			WhenScaled:  mocov1beta2.PersistentVolumeClaimDelete,
+		}, appsv1.RetainPersistentVolumeClaimRetentionPolicyType, appsv1.DeletePersistentVolumeClaimRetentionPolicyType},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Spec.PersistentVolumeClaimRetentionPolicy = tc.policy
+			r := &MySQLClusterReconciler{PVCRetentionPolicy: tc.supported}
+
+			policy := r.pvcRetentionPolicy(cluster)
+			if tc.whenDeleted == "" {
+				if policy != nil {
+					t.Errorf("unexpected policy: %+v", policy)
+				}
+				return
+			}
+			if policy == nil {
+				t.Fatal("policy is not set")
+			}
+			if *policy.WhenDeleted != tc.whenDeleted || *policy.WhenScaled != tc.whenScaled {
+				t.Errorf("unexpected policy: whenDeleted=%s, whenScaled=%s", *policy.WhenDeleted, *policy.WhenScaled)
+			}
+		})
+	}
+}
+
+func TestPVCOwnedByCluster(t *testing.T) {
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.UID = types.UID("cluster-uid")
+
+	owned := &corev1.PersistentVolumeClaim{}
+	owned.OwnerReferences = []metav1.OwnerReference{{Kind: "MySQLCluster", UID: cluster.UID}}
+	notOwned := &corev1.PersistentVolumeClaim{}
+
+	cases := []struct {
+		name               string
+		origPVC            *corev1.PersistentVolumeClaim
+		hasRetentionPolicy bool
+		expected           bool
+	}{
+		{"new without policy", nil, false, true},
+		{"new with policy", nil, true, false},
+		{"owned without policy", owned, false, true},
+		{"owned with policy added later", owned, true, true},
+		{"not owned with policy", notOwned, true, false},
+		{"not owned without policy", notOwned, false, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := pvcOwnedByCluster(cluster, tc.origPVC, tc.hasRetentionPolicy); got != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/event"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// checkResourceQuota compares the resources requested by the Pods of the StatefulSet
+// with ResourceQuotas in the namespace of the cluster.
+// If a quota would reject the Pods, it emits a QuotaExceeded event so that the
+// reason is visible on the MySQLCluster rather than only in the StatefulSet's events.
+// The event is recorded again only when the result for the quota changes.
+//
+// This is only a warning and never blocks the reconciliation.
+// Quotas with scopes or scope selectors are ignored because the controller
+// cannot tell which Pods they apply to without evaluating them.
+func (r *MySQLClusterReconciler) checkResourceQuota(ctx context.Context, cluster *mocov1beta2.MySQLCluster, orig *appsv1.StatefulSet, podSpec *corev1ac.PodSpecApplyConfiguration) {
+	log := crlog.FromContext(ctx)
+
+	quotas := &corev1.ResourceQuotaList{}
+	if err := r.List(ctx, quotas, client.InNamespace(cluster.Namespace)); err != nil {
+		log.Error(err, "failed to list ResourceQuotas")
+		return
+	}
+	if len(quotas.Items) == 0 {
+		return
+	}
+
+	perPod := podResourceUsage(podSpec)
+
+	// Pods that do not exist yet need to fit in the remaining quota.
+	newPods := int64(cluster.Spec.Replicas)
+	if orig.Spec.Replicas != nil {
+		newPods -= int64(*orig.Spec.Replicas)
+	}
+	if newPods < 0 {
+		newPods = 0
+	}
+
+	for _, q := range quotas.Items {
+		if len(q.Spec.Scopes) > 0 || q.Spec.ScopeSelector != nil {
+			continue
+		}
+
+		var problems []string
+		for _, name := range sortedResourceNames(q.Spec.Hard) {
+			hard := q.Spec.Hard[name]
+			var req resource.Quantity
+			if name == corev1.ResourcePods {
+				req = *resource.NewQuantity(1, resource.DecimalSI)
+			} else {
+				v, ok := perPod[name]
+				if !ok {
+					continue
+				}
+				req = v
+			}
+
+			if req.Cmp(hard) > 0 {
+				problems = append(problems, fmt.Sprintf("a Pod requires %s=%s but the hard limit is %s", name, req.String(), hard.String()))
+				continue
+			}
+
+			if newPods == 0 {
+				continue
+			}
+			var total resource.Quantity
+			for i := int64(0); i < newPods; i++ {
+				total.Add(req)
+			}
+			free := hard.DeepCopy()
+			if used, ok := q.Status.Used[name]; ok {
+				free.Sub(used)
+			}
+			if total.Cmp(free) > 0 {
+				problems = append(problems, fmt.Sprintf("%d new Pod(s) require %s=%s but only %s is left", newPods, name, total.String(), free.String()))
+			}
+		}
+
+		slot := "Quota/" + q.Name
+		if len(problems) == 0 {
+			r.eventTracker.clear(cluster, slot)
+			continue
+		}
+		msg := strings.Join(problems, "; ")
+		log.Info("ResourceQuota may reject MySQL Pods", "quota", q.Name, "reason", msg)
+		r.eventTracker.emit(cluster, r.Recorder, slot, event.QuotaExceeded, q.Name, msg)
+	}
+}
+
+// podResourceUsage returns the resources of a Pod in the form of ResourceQuota's keys.
+// As with the Kubernetes scheduler, the usage is the sum of the containers or
+// the maximum of the init containers, whichever is greater.
+func podResourceUsage(podSpec *corev1ac.PodSpecApplyConfiguration) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	limits := corev1.ResourceList{}
+	for i := range podSpec.Containers {
+		req, lim := containerResources(&podSpec.Containers[i])
+		addResourceList(requests, req)
+		addResourceList(limits, lim)
+	}
+	for i := range podSpec.InitContainers {
+		req, lim := containerResources(&podSpec.InitContainers[i])
+		maxResourceList(requests, req)
+		maxResourceList(limits, lim)
+	}
+
+	usage := corev1.ResourceList{}
+	for _, name := range []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory} {
+		if v, ok := requests[name]; ok {
+			usage[name] = v
+			usage[corev1.ResourceName("requests."+string(name))] = v
+		}
+		if v, ok := limits[name]; ok {
+			usage[corev1.ResourceName("limits."+string(name))] = v
+		}
+	}
+	return usage
+}
+
+func containerResources(c *corev1ac.ContainerApplyConfiguration) (corev1.ResourceList, corev1.ResourceList) {
+	if c.Resources == nil {
+		return nil, nil
+	}
+	var requests, limits corev1.ResourceList
+	if c.Resources.Limits != nil {
+		limits = *c.Resources.Limits
+	}
+	if c.Resources.Requests != nil {
+		requests = c.Resources.Requests.DeepCopy()
+	}
+	// Requests default to limits when they are omitted.
+	for name, v := range limits {
+		if _, ok := requests[name]; !ok {
+			if requests == nil {
+				requests = corev1.ResourceList{}
+			}
+			requests[name] = v
+		}
+	}
+	return requests, limits
+}
+
+func addResourceList(list, other corev1.ResourceList) {
+	for name, v := range other {
+		if cur, ok := list[name]; ok {
+			cur.Add(v)
+			list[name] = cur
+		} else {
+			list[name] = v.DeepCopy()
+		}
+	}
+}
+
+func maxResourceList(list, other corev1.ResourceList) {
+	for name, v := range other {
+		if cur, ok := list[name]; !ok || v.Cmp(cur) > 0 {
+			list[name] = v.DeepCopy()
+		}
+	}
+}
+
+func sortedResourceNames(list corev1.ResourceList) []corev1.ResourceName {
+	names := make([]corev1.ResourceName, 0, len(list))
+	for name := range list {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i] < names[j] })
+	return names
+}
@@ -0,0 +1,126 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/event"
+	"github.com/cybozu-go/moco/pkg/password"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileV1ReplicationSourceSecret generates the replication source Secret for `spec.replicationSource`.
+// The Secret points to the primary Service of the source cluster so that the replication
+// follows switchovers and failovers of the source.  The clustering manager reads it in the
+// same way as a Secret given by `spec.replicationSourceSecretName`.
+//
+// The Secret contains the passwords of the source, so it is not generated, or is removed,
+// unless the source cluster allows the namespace of this cluster to replicate from it.
+//
+// With WatchNamespace, the cache does not have the clusters in the other namespaces,
+// so a source cluster in another namespace is rejected.
+func (r *MySQLClusterReconciler) reconcileV1ReplicationSourceSecret(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.SourceClusterSecretName()
+	if cluster.Spec.ReplicationSource == nil {
+		r.eventTracker.clear(cluster, "ReplicationSource")
+		return r.deleteReplicationSourceSecret(ctx, cluster)
+	}
+
+	srcKey := cluster.SourceCluster()
+	if r.WatchNamespace != "" && srcKey.Namespace != r.WatchNamespace {
+		log.Info("source cluster is not in the watched namespace", "source", srcKey.String())
+		r.eventTracker.emit(cluster, r.Recorder, "ReplicationSource", event.ReplicationSourceNotWatched, srcKey.String(), r.WatchNamespace)
+		return r.deleteReplicationSourceSecret(ctx, cluster)
+	}
+
+	// The clustering manager keeps retrying to clone the data until the Secret is created.
+	// Changes of the source cluster trigger the reconciliation of this cluster.
+	// The events are recorded once until the problem is resolved.
+	src := &mocov1beta2.MySQLCluster{}
+	if err := r.Get(ctx, srcKey, src); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("source cluster is not found", "source", srcKey.String())
+			r.eventTracker.emit(cluster, r.Recorder, "ReplicationSource", event.ReplicationSourceNotFound, srcKey.String())
+			return nil
+		}
+		return fmt.Errorf("failed to get source cluster %s: %w", srcKey, err)
+	}
+	if !src.ReplicationAllowedTo(cluster.Namespace) {
+		log.Info("source cluster does not allow replication to the namespace", "source", srcKey.String())
+		r.eventTracker.emit(cluster, r.Recorder, "ReplicationSource", event.ReplicationSourceNotAllowed, srcKey.String(), constants.AnnReplicationAllowedNamespaces)
+		return r.deleteReplicationSourceSecret(ctx, cluster)
+	}
+	r.eventTracker.clear(cluster, "ReplicationSource")
+
+	srcSecret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: r.SystemNamespace, Name: src.ControllerSecretName()}, srcSecret); err != nil {
+		if apierrors.IsNotFound(err) {
+			log.Info("controller Secret of the source cluster is not created yet", "source", srcKey.String())
+			return nil
+		}
+		return fmt.Errorf("failed to get controller Secret of source cluster %s: %w", srcKey, err)
+	}
+	passwd, err := password.NewMySQLPasswordFromSecret(srcSecret)
+	if err != nil {
+		return fmt.Errorf("failed to create password from secret %s/%s: %w", srcSecret.Namespace, srcSecret.Name, err)
+	}
+
+	// moco-backup has the privileges for both cloning and replication.
+	// moco-admin is used to initialize the cloned data that contains the users of the source.
+	secret := corev1ac.Secret(name, cluster.Namespace).
+		WithLabels(labelSet(cluster, false)).
+		WithData(map[string][]byte{
+			constants.CloneSourceHostKey:         []byte(fmt.Sprintf("%s.%s.svc", src.PrimaryServiceName(), src.Namespace)),
+			constants.CloneSourcePortKey:         []byte(strconv.Itoa(constants.MySQLPort)),
+			constants.CloneSourceUserKey:         []byte(constants.BackupUser),
+			constants.CloneSourcePasswordKey:     []byte(passwd.Backup()),
+			constants.CloneSourceInitUserKey:     []byte(constants.AdminUser),
+			constants.CloneSourceInitPasswordKey: []byte(passwd.Admin()),
+		})
+
+	if err := setControllerReferenceWithSecret(cluster, secret, r.Scheme); err != nil {
+		return fmt.Errorf("failed to set ownerReference to Secret %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	key := client.ObjectKey{Namespace: cluster.Namespace, Name: name}
+	if _, err := apply(ctx, r.Client, key, secret, corev1ac.ExtractSecret); err != nil {
+		if errors.Is(err, ErrApplyConfigurationNotChanged) {
+			return nil
+		}
+		return fmt.Errorf("failed to reconcile replication source Secret %s/%s: %w", cluster.Namespace, name, err)
+	}
+
+	log.Info("reconciled replication source Secret", "secretName", name)
+
+	return nil
+}
+
+func (r *MySQLClusterReconciler) deleteReplicationSourceSecret(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	name := cluster.SourceClusterSecretName()
+	secret := &corev1.Secret{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: cluster.Namespace, Name: name}, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	if !metav1.IsControlledBy(secret, cluster) {
+		return nil
+	}
+	if err := r.Delete(ctx, secret); err != nil {
+		return client.IgnoreNotFound(err)
+	}
+	log.Info("removed replication source Secret", "secretName", name)
+	return nil
+}
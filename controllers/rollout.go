@@ -0,0 +1,50 @@
+package controllers
+
+import (
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// rolloutStatus returns the progress of the rollout of `sts` and the Progressing condition.
+// The condition is true while the StatefulSet controller has not observed the latest spec,
+// or some instances are not updated to the update revision or not ready.
+func rolloutStatus(sts *appsv1.StatefulSet, generation int64) (*mocov1beta2.RolloutStatus, metav1.Condition) {
+	replicas := int32(1)
+	if sts.Spec.Replicas != nil {
+		replicas = *sts.Spec.Replicas
+	}
+	status := &mocov1beta2.RolloutStatus{
+		Replicas:        replicas,
+		UpdatedReplicas: sts.Status.UpdatedReplicas,
+		ReadyReplicas:   sts.Status.ReadyReplicas,
+		CurrentRevision: sts.Status.CurrentRevision,
+		UpdateRevision:  sts.Status.UpdateRevision,
+	}
+
+	cond := metav1.Condition{
+		Type:               mocov1beta2.ConditionProgressing,
+		ObservedGeneration: generation,
+	}
+	switch {
+	case sts.Status.ObservedGeneration < sts.Generation:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "StatefulSetNotObserved"
+		cond.Message = "the update of StatefulSet is not observed yet"
+	case status.UpdatedReplicas < replicas || status.CurrentRevision != status.UpdateRevision:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "RollingUpdate"
+		cond.Message = fmt.Sprintf("%d of %d instances are updated to %s", status.UpdatedReplicas, replicas, status.UpdateRevision)
+	case status.ReadyReplicas < replicas:
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = "WaitingForReady"
+		cond.Message = fmt.Sprintf("%d of %d instances are ready", status.ReadyReplicas, replicas)
+	default:
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = "RolloutComplete"
+		cond.Message = "all instances are updated to " + status.UpdateRevision
+	}
+	return status, cond
+}
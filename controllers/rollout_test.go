@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+)
+
+func TestRolloutStatus(t *testing.T) {
+	newSTS := func(generation, observed int64, updated, ready int32, current, update string) *appsv1.StatefulSet {
+		sts := &appsv1.StatefulSet{}
+		sts.Generation = generation
+		sts.Spec.Replicas = ptr.To[int32](3)
+		sts.Status.ObservedGeneration = observed
+		sts.Status.Replicas = 3
+		sts.Status.UpdatedReplicas = updated
+		sts.Status.ReadyReplicas = ready
+		sts.Status.CurrentRevision = current
+		sts.Status.UpdateRevision = update
+		return sts
+	}
+
+	cases := []struct {
+		name   string
+		sts    *appsv1.StatefulSet
+		status metav1.ConditionStatus
+		reason string
+	}{
+		{"complete", newSTS(2, 2, 3, 3, "rev2", "rev2"), metav1.ConditionFalse, "RolloutComplete"},
+		{"not observed", newSTS(3, 2, 3, 3, "rev2", "rev2"), metav1.ConditionTrue, "StatefulSetNotObserved"},
+		{"rolling update", newSTS(2, 2, 1, 3, "rev1", "rev2"), metav1.ConditionTrue, "RollingUpdate"},
+		{"all updated but revision not switched", newSTS(2, 2, 3, 3, "rev1", "rev2"), metav1.ConditionTrue, "RollingUpdate"},
+		{"waiting for ready", newSTS(2, 2, 3, 2, "rev2", "rev2"), metav1.ConditionTrue, "WaitingForReady"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, cond := rolloutStatus(tc.sts, 5)
+			if cond.Type != "Progressing" {
+				t.Errorf("unexpected condition type: %s", cond.Type)
+			}
+			if cond.Status != tc.status {
+				t.Errorf("unexpected condition status: expected %s, actual %s", tc.status, cond.Status)
+			}
+			if cond.Reason != tc.reason {
+				t.Errorf("unexpected condition reason: expected %s, actual %s", tc.reason, cond.Reason)
+			}
+			if cond.ObservedGeneration != 5 {
+				t.Errorf("unexpected observed generation: %d", cond.ObservedGeneration)
+			}
+			if status.Replicas != 3 || status.UpdatedReplicas != tc.sts.Status.UpdatedReplicas || status.ReadyReplicas != tc.sts.Status.ReadyReplicas {
+				t.Errorf("unexpected rollout status: %+v", status)
+			}
+			if status.CurrentRevision != tc.sts.Status.CurrentRevision || status.UpdateRevision != tc.sts.Status.UpdateRevision {
+				t.Errorf("unexpected revisions: %+v", status)
+			}
+		})
+	}
+}
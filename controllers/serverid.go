@@ -0,0 +1,258 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/util/retry"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// ServerIDConfigMapName is the name of the ConfigMap to record the server ID ranges allocated to clusters.
+	ServerIDConfigMapName = "moco-server-id-ranges"
+
+	// DefaultServerIDBlockSize is the default number of server IDs allocated to a cluster.
+	DefaultServerIDBlockSize = 1000
+)
+
+// ErrServerIDExhausted is returned when no server ID range is left.
+var ErrServerIDExhausted = errors.New("server IDs are exhausted")
+
+// ServerIDAllocator allocates non-overlapping ranges of server IDs to MySQLClusters.
+//
+// A range consists of one or more consecutive blocks so that it covers the server IDs
+// from `spec.serverIDBase + spec.ordinals.start` for `spec.replicas` instances.
+// The ranges are recorded in a ConfigMap in Namespace with keys of "<namespace>.<name>"
+// of clusters and values of the base server IDs followed by ":<number of blocks>" if
+// the range has more than one block.  Concurrent allocations are serialized
+// by the optimistic concurrency control of the ConfigMap.
+// The ranges of existing clusters are also regarded as used even if they are not recorded,
+// so that clusters created before enabling the allocator do not collide with new ones.
+type ServerIDAllocator struct {
+	Client    client.Client
+	APIReader client.Reader
+	Namespace string
+
+	// BlockSize is the number of server IDs allocated to a cluster.
+	// If zero, DefaultServerIDBlockSize is used.
+	BlockSize int32
+
+	// MaxBlocks limits the number of ranges.
+	// If zero, the ranges fill the positive int32 values.
+	MaxBlocks int32
+}
+
+var _ mocov1beta2.ServerIDAllocator = &ServerIDAllocator{}
+
+func (a *ServerIDAllocator) blockSize() int32 {
+	if a.BlockSize == 0 {
+		return DefaultServerIDBlockSize
+	}
+	return a.BlockSize
+}
+
+func (a *ServerIDAllocator) maxBlocks() int32 {
+	n := int32(math.MaxInt32 / int64(a.blockSize()))
+	if a.MaxBlocks > 0 && a.MaxBlocks < n {
+		return a.MaxBlocks
+	}
+	return n
+}
+
+// serverIDBlocks returns the number of blocks needed for the server IDs of cluster.
+func (a *ServerIDAllocator) serverIDBlocks(cluster *mocov1beta2.MySQLCluster) int64 {
+	size := int64(a.blockSize())
+	n := int64(cluster.Spec.OrdinalStart()) + int64(cluster.Spec.Replicas)
+	if n <= size {
+		return 1
+	}
+	return (n + size - 1) / size
+}
+
+func formatServerIDRange(base int32, blocks int64) string {
+	if blocks <= 1 {
+		return strconv.FormatInt(int64(base), 10)
+	}
+	return fmt.Sprintf("%d:%d", base, blocks)
+}
+
+func parseServerIDRange(v string) (int64, int64, error) {
+	b, n, found := strings.Cut(v, ":")
+	base, err := strconv.ParseInt(b, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !found {
+		return base, 1, nil
+	}
+	blocks, err := strconv.ParseInt(n, 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	return base, blocks, nil
+}
+
+func serverIDKey(namespace, name string) string {
+	// namespaces cannot contain dots, so the key is not ambiguous.
+	return namespace + "." + name
+}
+
+// Allocate implements mocov1beta2.ServerIDAllocator.
+func (a *ServerIDAllocator) Allocate(ctx context.Context, cluster *mocov1beta2.MySQLCluster, dryRun bool) (int32, error) {
+	log := crlog.FromContext(ctx)
+	key := serverIDKey(cluster.Namespace, cluster.Name)
+
+	var base int32
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		err := a.APIReader.Get(ctx, client.ObjectKey{Namespace: a.Namespace, Name: ServerIDConfigMapName}, cm)
+		switch {
+		case apierrors.IsNotFound(err):
+			cm.Namespace = a.Namespace
+			cm.Name = ServerIDConfigMapName
+		case err != nil:
+			return err
+		}
+		if cm.Data == nil {
+			cm.Data = make(map[string]string)
+		}
+
+		clusters := &mocov1beta2.MySQLClusterList{}
+		// Clusters in all namespaces are listed even if moco-controller watches only one namespace
+		// because the webhook admits clusters in any namespace.
+		if err := a.APIReader.List(ctx, clusters); err != nil {
+			return err
+		}
+		existing := make(map[string]bool)
+		for _, c := range clusters.Items {
+			existing[serverIDKey(c.Namespace, c.Name)] = true
+		}
+
+		// If the cluster does not exist, the entry is stale; it was left by a cluster of
+		// the same name deleted while the controller was not running.
+		// If the cluster exists, the creation will fail anyway, so the entry is kept.
+		record := !dryRun && !existing[key]
+		if record {
+			delete(cm.Data, key)
+		}
+
+		blocks := a.serverIDBlocks(cluster)
+		b, err := a.findFree(cm.Data, clusters.Items, blocks)
+		if errors.Is(err, ErrServerIDExhausted) {
+			// Drop the entries for clusters that no longer exist and try again.
+			// They can be left by clusters whose creation was rejected after the allocation.
+			for k := range cm.Data {
+				if !existing[k] {
+					log.Info("removing the server ID range of a non-existent cluster", "cluster", k)
+					delete(cm.Data, k)
+				}
+			}
+			b, err = a.findFree(cm.Data, clusters.Items, blocks)
+		}
+		if err != nil {
+			return err
+		}
+		base = b
+
+		if !record {
+			return nil
+		}
+		cm.Data[key] = formatServerIDRange(base, blocks)
+		if cm.ResourceVersion == "" {
+			if err := a.Client.Create(ctx, cm); err != nil {
+				if apierrors.IsAlreadyExists(err) {
+					// retry as a conflict
+					return apierrors.NewConflict(corev1.Resource("configmaps"), cm.Name, err)
+				}
+				return err
+			}
+			return nil
+		}
+		return a.Client.Update(ctx, cm)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	log.Info("allocated server IDs", "cluster", key, "base", base, "size", a.blockSize())
+	return base, nil
+}
+
+// findFree returns the base of the first range of `blocks` blocks that does not overlap
+// with the recorded ranges nor the ranges of existing clusters.
+func (a *ServerIDAllocator) findFree(recorded map[string]string, clusters []mocov1beta2.MySQLCluster, blocks int64) (int32, error) {
+	size := int64(a.blockSize())
+	used := make(map[int64]bool)
+	// markUsed marks the blocks overlapping with n server IDs from base.
+	markUsed := func(base, n int64) {
+		if base <= 0 {
+			return
+		}
+		for i := (base - 1) / size; i <= (base+n-2)/size; i++ {
+			used[i] = true
+		}
+	}
+	for _, v := range recorded {
+		base, n, err := parseServerIDRange(v)
+		if err != nil {
+			continue
+		}
+		markUsed(base, n*size)
+	}
+	for _, c := range clusters {
+		n := int64(c.Spec.OrdinalStart()) + int64(c.Spec.Replicas)
+		if n < size {
+			n = size
+		}
+		markUsed(int64(c.Spec.ServerIDBase), n)
+	}
+
+	maxBlocks := int64(a.maxBlocks())
+	for i := int64(0); i+blocks <= maxBlocks; i++ {
+		free := true
+		for j := i; j < i+blocks; j++ {
+			if used[j] {
+				free = false
+				i = j
+				break
+			}
+		}
+		if free {
+			return int32(i*size + 1), nil
+		}
+	}
+	return 0, ErrServerIDExhausted
+}
+
+// Release forgets the range allocated to cluster.
+func (a *ServerIDAllocator) Release(ctx context.Context, cluster *mocov1beta2.MySQLCluster) error {
+	key := serverIDKey(cluster.Namespace, cluster.Name)
+	err := retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		cm := &corev1.ConfigMap{}
+		if err := a.APIReader.Get(ctx, client.ObjectKey{Namespace: a.Namespace, Name: ServerIDConfigMapName}, cm); err != nil {
+			return client.IgnoreNotFound(err)
+		}
+		v, ok := cm.Data[key]
+		if !ok {
+			return nil
+		}
+		if base, _, err := parseServerIDRange(v); err != nil || base != int64(cluster.Spec.ServerIDBase) {
+			return nil
+		}
+		delete(cm.Data, key)
+		return a.Client.Update(ctx, cm)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to release server IDs of %s/%s: %w", cluster.Namespace, cluster.Name, err)
+	}
+	return nil
+}
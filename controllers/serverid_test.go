@@ -0,0 +1,196 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newServerIDTestAllocator(t *testing.T, maxBlocks int32, objs ...client.Object) *ServerIDAllocator {
+	t.Helper()
+
+	scheme := runtime.NewScheme()
+	if err := clientgoscheme.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+	if err := mocov1beta2.AddToScheme(scheme); err != nil {
+		t.Fatalf("failed to add scheme: %v", err)
+	}
+
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+	return &ServerIDAllocator{
+		Client:    c,
+		APIReader: c,
+		Namespace: "moco-system",
+		BlockSize: 10,
+		MaxBlocks: maxBlocks,
+	}
+}
+
+func newServerIDTestCluster(name string, base int32) *mocov1beta2.MySQLCluster {
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Namespace = "test"
+	cluster.Name = name
+	cluster.Spec.ServerIDBase = base
+	return cluster
+}
+
+func getServerIDRanges(t *testing.T, a *ServerIDAllocator) map[string]string {
+	t.Helper()
+
+	cm := &corev1.ConfigMap{}
+	if err := a.APIReader.Get(context.Background(), client.ObjectKey{Namespace: a.Namespace, Name: ServerIDConfigMapName}, cm); err != nil {
+		t.Fatalf("failed to get ConfigMap: %v", err)
+	}
+	return cm.Data
+}
+
+func TestServerIDAllocatorAllocate(t *testing.T) {
+	ctx := context.Background()
+
+	// an existing cluster whose base was chosen randomly occupies the 3rd and 4th blocks.
+	a := newServerIDTestAllocator(t, 0, newServerIDTestCluster("random", 25))
+
+	for _, tt := range []struct {
+		name   string
+		dryRun bool
+		want   int32
+	}{
+		{"a", false, 1},
+		{"b", false, 11},
+		{"c", true, 41},
+		{"c", false, 41},
+		{"d", false, 51},
+	} {
+		base, err := a.Allocate(ctx, newServerIDTestCluster(tt.name, 0), tt.dryRun)
+		if err != nil {
+			t.Fatalf("failed to allocate for %s: %v", tt.name, err)
+		}
+		if base != tt.want {
+			t.Errorf("unexpected base for %s: want %d, got %d", tt.name, tt.want, base)
+		}
+	}
+
+	ranges := getServerIDRanges(t, a)
+	want := map[string]string{"test.a": "1", "test.b": "11", "test.c": "41", "test.d": "51"}
+	if len(ranges) != len(want) {
+		t.Errorf("unexpected ranges: %v", ranges)
+	}
+	for k, v := range want {
+		if ranges[k] != v {
+			t.Errorf("unexpected range for %s: want %s, got %s", k, v, ranges[k])
+		}
+	}
+
+	// releasing makes the range available again.
+	if err := a.Release(ctx, newServerIDTestCluster("b", 11)); err != nil {
+		t.Fatalf("failed to release: %v", err)
+	}
+	if _, ok := getServerIDRanges(t, a)["test.b"]; ok {
+		t.Error("range for b is not released")
+	}
+	base, err := a.Allocate(ctx, newServerIDTestCluster("e", 0), false)
+	if err != nil {
+		t.Fatalf("failed to allocate for e: %v", err)
+	}
+	if base != 11 {
+		t.Errorf("unexpected base for e: want 11, got %d", base)
+	}
+
+	// a mismatched base is not released.
+	if err := a.Release(ctx, newServerIDTestCluster("a", 100)); err != nil {
+		t.Fatalf("failed to release: %v", err)
+	}
+	if _, ok := getServerIDRanges(t, a)["test.a"]; !ok {
+		t.Error("range for a should not be released")
+	}
+}
+
+func TestServerIDAllocatorExhausted(t *testing.T) {
+	ctx := context.Background()
+
+	a := newServerIDTestAllocator(t, 2)
+
+	base, err := a.Allocate(ctx, newServerIDTestCluster("a", 0), false)
+	if err != nil {
+		t.Fatalf("failed to allocate for a: %v", err)
+	}
+	if err := a.Client.Create(ctx, newServerIDTestCluster("a", base)); err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	if _, err := a.Allocate(ctx, newServerIDTestCluster("b", 0), false); err != nil {
+		t.Fatalf("failed to allocate for b: %v", err)
+	}
+
+	// "b" does not exist, so its range is reclaimed.
+	base, err = a.Allocate(ctx, newServerIDTestCluster("c", 0), false)
+	if err != nil {
+		t.Fatalf("failed to allocate for c: %v", err)
+	}
+	if base != 11 {
+		t.Errorf("unexpected base for c: want 11, got %d", base)
+	}
+	if _, ok := getServerIDRanges(t, a)["test.b"]; ok {
+		t.Error("range for b is not reclaimed")
+	}
+
+	// create "c" so that the ranges cannot be reclaimed.
+	if err := a.Client.Create(ctx, newServerIDTestCluster("c", 11)); err != nil {
+		t.Fatalf("failed to create cluster: %v", err)
+	}
+	_, err = a.Allocate(ctx, newServerIDTestCluster("d", 0), false)
+	if !errors.Is(err, ErrServerIDExhausted) {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestServerIDAllocatorOrdinals(t *testing.T) {
+	ctx := context.Background()
+
+	// an existing cluster numbered from 15 uses the server IDs up to 28, so it occupies the 2nd and 3rd blocks.
+	existing := newServerIDTestCluster("existing", 11)
+	existing.Spec.Replicas = 3
+	existing.Spec.Ordinals = &mocov1beta2.OrdinalsSpec{Start: 15}
+	a := newServerIDTestAllocator(t, 0, existing)
+
+	for _, tt := range []struct {
+		name     string
+		start    int32
+		want     int32
+		wantData string
+	}{
+		// the server IDs from 9 to 11 need two blocks.
+		{"a", 8, 31, "31:2"},
+		{"b", 15, 51, "51:2"},
+		{"c", 0, 1, "1"},
+		{"d", 7, 71, "71"},
+	} {
+		cluster := newServerIDTestCluster(tt.name, 0)
+		cluster.Spec.Replicas = 3
+		cluster.Spec.Ordinals = &mocov1beta2.OrdinalsSpec{Start: tt.start}
+		base, err := a.Allocate(ctx, cluster, false)
+		if err != nil {
+			t.Fatalf("failed to allocate for %s: %v", tt.name, err)
+		}
+		if base != tt.want {
+			t.Errorf("unexpected base for %s: want %d, got %d", tt.name, tt.want, base)
+		}
+		if v := getServerIDRanges(t, a)["test."+tt.name]; v != tt.wantData {
+			t.Errorf("unexpected range for %s: want %s, got %s", tt.name, tt.wantData, v)
+		}
+	}
+
+	if err := a.Release(ctx, newServerIDTestCluster("b", 51)); err != nil {
+		t.Fatalf("failed to release: %v", err)
+	}
+	if _, ok := getServerIDRanges(t, a)["test.b"]; ok {
+		t.Error("range for b is not released")
+	}
+}
@@ -0,0 +1,169 @@
+package controllers
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	corev1ac "k8s.io/client-go/applyconfigurations/core/v1"
+)
+
+const (
+	defaultLokiPort           = 3100
+	defaultElasticsearchPort  = 9200
+	defaultElasticsearchIndex = "moco-slow-log"
+)
+
+type fluentBitSection struct {
+	sb strings.Builder
+}
+
+func (s *fluentBitSection) add(key, value string) {
+	fmt.Fprintf(&s.sb, "  %-14s %s\n", key, value)
+}
+
+func onOff(b bool) string {
+	if b {
+		return "On"
+	}
+	return "Off"
+}
+
+func envRef(name string) string {
+	return "${" + name + "}"
+}
+
+// slowQueryLogOutputType returns the type of the log sink for slow logs.
+func slowQueryLogOutputType(cluster *mocov1beta2.MySQLCluster) string {
+	out := cluster.Spec.SlowQueryLogOutput
+	if out == nil || out.Type == "" {
+		return mocov1beta2.SlowQueryLogOutputStdout
+	}
+	return out.Type
+}
+
+func slowQueryLogOutputPort(out *mocov1beta2.SlowQueryLogOutputSpec, defaultPort int32) string {
+	if out.Port != nil {
+		return strconv.Itoa(int(*out.Port))
+	}
+	return strconv.Itoa(int(defaultPort))
+}
+
+// makeSlowQueryLogOutput renders the [OUTPUT] section of fluent-bit for slow logs.
+// Credentials are not written in the configuration; they are referenced as
+// environment variables of the slow-log container.
+func makeSlowQueryLogOutput(cluster *mocov1beta2.MySQLCluster) string {
+	out := cluster.Spec.SlowQueryLogOutput
+	s := &fluentBitSection{}
+
+	switch slowQueryLogOutputType(cluster) {
+	case mocov1beta2.SlowQueryLogOutputLoki:
+		s.add("Name", "loki")
+		s.add("Match", "*")
+		s.add("Host", out.Host)
+		s.add("Port", slowQueryLogOutputPort(out, defaultLokiPort))
+		s.add("tls", onOff(out.TLS))
+		s.add("Labels", fmt.Sprintf("job=moco-slow-log, namespace=%s, cluster=%s, instance=${HOSTNAME}", cluster.Namespace, cluster.Name))
+		if out.CredentialsSecretName != nil {
+			s.add("http_user", envRef(constants.SlowQueryLogOutputUserEnvName))
+			s.add("http_passwd", envRef(constants.SlowQueryLogOutputPasswordEnvName))
+		}
+	case mocov1beta2.SlowQueryLogOutputElasticsearch:
+		index := out.Index
+		if index == "" {
+			index = defaultElasticsearchIndex
+		}
+		s.add("Name", "es")
+		s.add("Match", "*")
+		s.add("Host", out.Host)
+		s.add("Port", slowQueryLogOutputPort(out, defaultElasticsearchPort))
+		s.add("Index", index)
+		s.add("Suppress_Type_Name", "On")
+		s.add("tls", onOff(out.TLS))
+		if out.CredentialsSecretName != nil {
+			s.add("HTTP_User", envRef(constants.SlowQueryLogOutputUserEnvName))
+			s.add("HTTP_Passwd", envRef(constants.SlowQueryLogOutputPasswordEnvName))
+		}
+	case mocov1beta2.SlowQueryLogOutputKafka:
+		s.add("Name", "kafka")
+		s.add("Match", "*")
+		s.add("Brokers", strings.Join(out.Brokers, ","))
+		s.add("Topics", out.Topic)
+		s.add("Format", "json")
+		switch {
+		case out.CredentialsSecretName != nil && out.TLS:
+			s.add("rdkafka.security.protocol", "SASL_SSL")
+		case out.CredentialsSecretName != nil:
+			s.add("rdkafka.security.protocol", "SASL_PLAINTEXT")
+		case out.TLS:
+			s.add("rdkafka.security.protocol", "SSL")
+		}
+		if out.CredentialsSecretName != nil {
+			s.add("rdkafka.sasl.mechanism", "PLAIN")
+			s.add("rdkafka.sasl.username", envRef(constants.SlowQueryLogOutputUserEnvName))
+			s.add("rdkafka.sasl.password", envRef(constants.SlowQueryLogOutputPasswordEnvName))
+		}
+	default:
+		addStdoutOutput(s)
+	}
+
+	return "[OUTPUT]\n" + s.sb.String()
+}
+
+// makeAuditLogOutput renders the [OUTPUT] section of fluent-bit for audit logs.
+// Audit logs are always written to the standard output of the audit-log container.
+func makeAuditLogOutput() string {
+	s := &fluentBitSection{}
+	addStdoutOutput(s)
+	return "[OUTPUT]\n" + s.sb.String()
+}
+
+func addStdoutOutput(s *fluentBitSection) {
+	s.add("Name", "file")
+	s.add("Match", "*")
+	s.add("Path", "/dev")
+	s.add("File", "stdout")
+	s.add("Format", "template")
+	s.add("Template", "{log}")
+}
+
+// slowQueryLogOutputHash returns the hash of the output configuration for slow logs.
+// It returns an empty string for the default stdout output so that Pods of
+// existing clusters are not restarted.
+func slowQueryLogOutputHash(cluster *mocov1beta2.MySQLCluster) string {
+	if cluster.Spec.DisableSlowQueryLogContainer || slowQueryLogOutputType(cluster) == mocov1beta2.SlowQueryLogOutputStdout {
+		return ""
+	}
+
+	fnv32a := fnv.New32a()
+	fnv32a.Write([]byte(makeSlowQueryLogOutput(cluster)))
+	return hex.EncodeToString(fnv32a.Sum(nil))
+}
+
+// slowQueryLogOutputEnvs returns the environment variables of the slow-log container
+// to pass the credentials of the log sink.
+func slowQueryLogOutputEnvs(cluster *mocov1beta2.MySQLCluster) []*corev1ac.EnvVarApplyConfiguration {
+	out := cluster.Spec.SlowQueryLogOutput
+	if out == nil || out.CredentialsSecretName == nil || slowQueryLogOutputType(cluster) == mocov1beta2.SlowQueryLogOutputStdout {
+		return nil
+	}
+
+	return []*corev1ac.EnvVarApplyConfiguration{
+		corev1ac.EnvVar().
+			WithName(constants.SlowQueryLogOutputUserEnvName).
+			WithValueFrom(corev1ac.EnvVarSource().
+				WithSecretKeyRef(corev1ac.SecretKeySelector().
+					WithName(*out.CredentialsSecretName).
+					WithKey("username"))),
+		corev1ac.EnvVar().
+			WithName(constants.SlowQueryLogOutputPasswordEnvName).
+			WithValueFrom(corev1ac.EnvVarSource().
+				WithSecretKeyRef(corev1ac.SecretKeySelector().
+					WithName(*out.CredentialsSecretName).
+					WithKey("password"))),
+	}
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/google/go-cmp/cmp"
+	"k8s.io/utils/ptr"
+)
+
+func TestMakeSlowQueryLogOutput(t *testing.T) {
+	cases := []struct {
+		name   string
+		output *mocov1beta2.SlowQueryLogOutputSpec
+		want   string
+	}{
+		{
+			name:   "default",
+			output: nil,
+			want: `[OUTPUT]
+  Name           file
+  Match          *
+  Path           /dev
+  File           stdout
+  Format         template
+  Template       {log}
+`,
+		},
+		{
+			name: "loki",
+			output: &mocov1beta2.SlowQueryLogOutputSpec{
+				Type: mocov1beta2.SlowQueryLogOutputLoki,
+				Host: "loki.example.com",
+				Port: ptr.To[int32](443),
+				TLS:  true,
+			},
+			want: `[OUTPUT]
+  Name           loki
+  Match          *
+  Host           loki.example.com
+  Port           443
+  tls            On
+  Labels         job=moco-slow-log, namespace=test, cluster=test, instance=${HOSTNAME}
+`,
+		},
+		{
+			name: "elasticsearch",
+			output: &mocov1beta2.SlowQueryLogOutputSpec{
+				Type:                  mocov1beta2.SlowQueryLogOutputElasticsearch,
+				Host:                  "es.example.com",
+				CredentialsSecretName: ptr.To("es"),
+			},
+			want: `[OUTPUT]
+  Name           es
+  Match          *
+  Host           es.example.com
+  Port           9200
+  Index          moco-slow-log
+  Suppress_Type_Name On
+  tls            Off
+  HTTP_User      ${SLOW_LOG_OUTPUT_USER}
+  HTTP_Passwd    ${SLOW_LOG_OUTPUT_PASSWORD}
+`,
+		},
+		{
+			name: "kafka",
+			output: &mocov1beta2.SlowQueryLogOutputSpec{
+				Type:                  mocov1beta2.SlowQueryLogOutputKafka,
+				Brokers:               []string{"kafka-0:9092", "kafka-1:9092"},
+				Topic:                 "slow-log",
+				TLS:                   true,
+				CredentialsSecretName: ptr.To("kafka"),
+			},
+			want: `[OUTPUT]
+  Name           kafka
+  Match          *
+  Brokers        kafka-0:9092,kafka-1:9092
+  Topics         slow-log
+  Format         json
+  rdkafka.security.protocol SASL_SSL
+  rdkafka.sasl.mechanism PLAIN
+  rdkafka.sasl.username ${SLOW_LOG_OUTPUT_USER}
+  rdkafka.sasl.password ${SLOW_LOG_OUTPUT_PASSWORD}
+`,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cluster := &mocov1beta2.MySQLCluster{}
+			cluster.Namespace = "test"
+			cluster.Name = "test"
+			cluster.Spec.SlowQueryLogOutput = tc.output
+
+			got := makeSlowQueryLogOutput(cluster)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("unexpected output (-want +got):\n%s", diff)
+			}
+
+			hash := slowQueryLogOutputHash(cluster)
+			if tc.output == nil && hash != "" {
+				t.Errorf("hash should be empty for the default output: %s", hash)
+			}
+			if tc.output != nil && hash == "" {
+				t.Error("hash should not be empty")
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/password"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	crlog "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// reconcileV1UserSecrets creates the password Secrets of users in `spec.users` if they do not exist.
+// Existing Secrets are never modified so that users can bring their own passwords.
+// The users themselves are created by the clustering manager.
+func (r *MySQLClusterReconciler) reconcileV1UserSecrets(ctx context.Context, req ctrl.Request, cluster *mocov1beta2.MySQLCluster) error {
+	log := crlog.FromContext(ctx)
+
+	for _, u := range cluster.Spec.Users {
+		key := client.ObjectKey{Namespace: cluster.Namespace, Name: u.PasswordSecretName}
+		err := r.Get(ctx, key, &corev1.Secret{})
+		if err == nil {
+			continue
+		}
+		if !apierrors.IsNotFound(err) {
+			return fmt.Errorf("failed to get password Secret %s for user %s: %w", key, u.Name, err)
+		}
+
+		passwd, err := password.NewUserPassword()
+		if err != nil {
+			return err
+		}
+		secret := &corev1.Secret{}
+		secret.Namespace = key.Namespace
+		secret.Name = key.Name
+		secret.Labels = labelSet(cluster, false)
+		secret.Data = map[string][]byte{
+			password.UserPasswordKey: []byte(passwd),
+		}
+		if err := ctrl.SetControllerReference(cluster, secret, r.Scheme); err != nil {
+			return fmt.Errorf("failed to set ownerReference to Secret %s: %w", key, err)
+		}
+		if err := r.Client.Create(ctx, secret); err != nil {
+			return fmt.Errorf("failed to create password Secret %s for user %s: %w", key, u.Name, err)
+		}
+
+		log.Info("created password Secret for user", "user", u.Name, "secretName", key.Name)
+	}
+
+	return nil
+}
@@ -0,0 +1,107 @@
+package controllers
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestListClusters(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := mocov1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	var objs []client.Object
+	for _, key := range []client.ObjectKey{{Namespace: "a", Name: "foo"}, {Namespace: "a", Name: "bar"}, {Namespace: "b", Name: "foo"}} {
+		cluster := &mocov1beta2.MySQLCluster{}
+		cluster.Namespace = key.Namespace
+		cluster.Name = key.Name
+		objs = append(objs, cluster)
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objs...).Build()
+
+	cases := []struct {
+		name           string
+		watchNamespace string
+		namespace      string
+		expected       []string
+	}{
+		{"all namespaces", "", "", []string{"a/bar", "a/foo", "b/foo"}},
+		{"one namespace", "", "b", []string{"b/foo"}},
+		{"scoped", "a", "", []string{"a/bar", "a/foo"}},
+		{"scoped in the namespace", "a", "a", []string{"a/bar", "a/foo"}},
+		{"scoped out of the namespace", "a", "b", nil},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			r := &MySQLClusterReconciler{Client: c, WatchNamespace: tc.watchNamespace}
+			clusters, err := r.listClusters(context.Background(), tc.namespace)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var names []string
+			for _, cluster := range clusters.Items {
+				names = append(names, cluster.Namespace+"/"+cluster.Name)
+			}
+			sort.Strings(names)
+			if len(names) != len(tc.expected) {
+				t.Fatalf("unexpected clusters: expected %v, got %v", tc.expected, names)
+			}
+			for i := range names {
+				if names[i] != tc.expected[i] {
+					t.Errorf("unexpected clusters: expected %v, got %v", tc.expected, names)
+				}
+			}
+		})
+	}
+}
+
+func TestReplicationSourceOutOfWatchNamespace(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := mocov1beta2.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+
+	source := &mocov1beta2.MySQLCluster{}
+	source.Namespace = "b"
+	source.Name = "source"
+	source.Annotations = map[string]string{constants.AnnReplicationAllowedNamespaces: "a"}
+	cluster := &mocov1beta2.MySQLCluster{}
+	cluster.Namespace = "a"
+	cluster.Name = "replica"
+	cluster.Spec.ReplicationSource = &mocov1beta2.ReplicationSourceSpec{ClusterName: "source", Namespace: "b"}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(source, cluster).Build()
+
+	recorder := record.NewFakeRecorder(10)
+	r := &MySQLClusterReconciler{Client: c, Scheme: scheme, Recorder: recorder, WatchNamespace: "a"}
+	for i := 0; i < 2; i++ {
+		err := r.reconcileV1ReplicationSourceSecret(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(cluster)}, cluster)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: "a", Name: cluster.SourceClusterSecretName()}, &corev1.Secret{})
+	if !apierrors.IsNotFound(err) {
+		t.Errorf("the replication source Secret should not be created: %v", err)
+	}
+	if len(recorder.Events) != 1 {
+		t.Errorf("expected 1 event, but got %d", len(recorder.Events))
+	}
+}
@@ -0,0 +1,59 @@
+package bkop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// systemDatabases are the databases whose tables are not analyzed by AnalyzeTables.
+var systemDatabases = []string{"information_schema", "mysql", "performance_schema", "sys"}
+
+type analyzeResult struct {
+	Table   string `db:"Table"`
+	Op      string `db:"Op"`
+	MsgType string `db:"Msg_type"`
+	MsgText string `db:"Msg_text"`
+}
+
+func (o operator) AnalyzeTables(ctx context.Context, databases []string) error {
+	query := `SELECT TABLE_SCHEMA, TABLE_NAME FROM information_schema.TABLES WHERE TABLE_TYPE = 'BASE TABLE'`
+	var args []interface{}
+	var err error
+	if len(databases) > 0 {
+		query, args, err = sqlx.In(query+` AND TABLE_SCHEMA IN (?)`, databases)
+	} else {
+		query, args, err = sqlx.In(query+` AND TABLE_SCHEMA NOT IN (?)`, systemDatabases)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to build the query to list tables: %w", err)
+	}
+
+	var tables []struct {
+		Schema string `db:"TABLE_SCHEMA"`
+		Name   string `db:"TABLE_NAME"`
+	}
+	if err := o.db.SelectContext(ctx, &tables, query, args...); err != nil {
+		return fmt.Errorf("failed to list tables: %w", err)
+	}
+
+	for _, t := range tables {
+		// NO_WRITE_TO_BINLOG is required not to create errant transactions on replicas.
+		var results []analyzeResult
+		if err := o.db.SelectContext(ctx, &results, `ANALYZE NO_WRITE_TO_BINLOG TABLE `+quoteIdentifier(t.Schema)+`.`+quoteIdentifier(t.Name)); err != nil {
+			return fmt.Errorf("failed to analyze %s.%s: %w", t.Schema, t.Name, err)
+		}
+		for _, r := range results {
+			if r.MsgType == "error" {
+				return fmt.Errorf("failed to analyze %s: %s", r.Table, r.MsgText)
+			}
+		}
+	}
+	return nil
+}
+
+func quoteIdentifier(s string) string {
+	return "`" + strings.ReplaceAll(s, "`", "``") + "`"
+}
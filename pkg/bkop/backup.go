@@ -11,7 +11,7 @@ import (
 	"github.com/cybozu-go/moco/pkg/constants"
 )
 
-func (o operator) DumpFull(ctx context.Context, dir string) error {
+func (o operator) DumpFull(ctx context.Context, dir string, opts DumpOptions) error {
 	args := []string{
 		fmt.Sprintf("mysql://%s@%s", o.user, net.JoinHostPort(o.host, fmt.Sprint(o.port))),
 		"-p" + o.password,
@@ -24,6 +24,12 @@ func (o operator) DumpFull(ctx context.Context, dir string) error {
 		"--excludeUsers=" + strings.Join(constants.MocoUsers, ","),
 		"--threads=" + fmt.Sprint(o.threads),
 	}
+	if len(opts.IncludeDatabases) > 0 {
+		args = append(args, "--includeSchemas="+strings.Join(opts.IncludeDatabases, ","))
+	}
+	if len(opts.ExcludeDatabases) > 0 {
+		args = append(args, "--excludeSchemas="+strings.Join(opts.ExcludeDatabases, ","))
+	}
 
 	cmd := exec.CommandContext(ctx, "mysqlsh", args...)
 	cmd.Stdout = os.Stdout
@@ -0,0 +1,47 @@
+package bkop
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/cybozu-go/moco/pkg/constants"
+)
+
+// WriteDumpOptions records `opts` in the dump directory so that
+// the restore process can tell whether the dump is partial.
+// Nothing is written if the dump is not partial.
+func WriteDumpOptions(dir string, opts DumpOptions) error {
+	if !opts.IsPartial() {
+		return nil
+	}
+
+	data, err := json.Marshal(opts)
+	if err != nil {
+		return fmt.Errorf("failed to marshal dump options: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, constants.DumpOptionsFilename), data, 0644)
+}
+
+// ReadDumpOptions reads the options recorded by WriteDumpOptions from the dump directory.
+// It returns empty options for dumps of all databases.
+func ReadDumpOptions(dir string) (DumpOptions, error) {
+	var opts DumpOptions
+
+	fname := filepath.Join(dir, constants.DumpOptionsFilename)
+	data, err := os.ReadFile(fname)
+	if errors.Is(err, fs.ErrNotExist) {
+		return opts, nil
+	}
+	if err != nil {
+		return opts, fmt.Errorf("could not read %s: %w", fname, err)
+	}
+
+	if err := json.Unmarshal(data, &opts); err != nil {
+		return opts, fmt.Errorf("failed to parse contents in %s: %w", constants.DumpOptionsFilename, err)
+	}
+	return opts, nil
+}
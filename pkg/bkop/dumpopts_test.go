@@ -0,0 +1,45 @@
+package bkop
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDumpOptions(t *testing.T) {
+	dir := t.TempDir()
+
+	opts, err := ReadDumpOptions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.IsPartial() {
+		t.Error("dump without options should not be partial", opts)
+	}
+
+	if err := WriteDumpOptions(dir, DumpOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	opts, err = ReadDumpOptions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if opts.IsPartial() {
+		t.Error("empty options should not be recorded", opts)
+	}
+
+	expected := DumpOptions{ExcludeDatabases: []string{"analytics", "tmp"}}
+	if err := WriteDumpOptions(dir, expected); err != nil {
+		t.Fatal(err)
+	}
+	opts, err = ReadDumpOptions(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !opts.IsPartial() {
+		t.Error("options should be partial", opts)
+	}
+	if !cmp.Equal(opts, expected) {
+		t.Error("wrong options", cmp.Diff(opts, expected))
+	}
+}
@@ -22,8 +22,9 @@ type Operator interface {
 	GetServerStatus(context.Context, *ServerStatus) error
 
 	// DumpFull takes a full dump of the database instance.
+	// The dumped databases can be limited by `opts`.
 	// `dir` should exist before calling this.
-	DumpFull(ctx context.Context, dir string) error
+	DumpFull(ctx context.Context, dir string, opts DumpOptions) error
 
 	// GetBinlogs returns a list of binary log files on the mysql instance.
 	GetBinlogs(context.Context) ([]string, error)
@@ -44,6 +45,10 @@ type Operator interface {
 
 	// FinishRestore sets global variables of the database instance after restoration.
 	FinishRestore(context.Context) error
+
+	// AnalyzeTables runs `ANALYZE TABLE` for the tables in `databases` without writing binary logs.
+	// If `databases` is empty, the tables in all databases except for the system ones are analyzed.
+	AnalyzeTables(ctx context.Context, databases []string) error
 }
 
 type operator struct {
@@ -115,7 +115,7 @@ var _ = Describe("Operator", func() {
 		dumpDir := filepath.Join(baseDir, "dump")
 		err = os.MkdirAll(dumpDir, 0755)
 		Expect(err).NotTo(HaveOccurred())
-		err = opBk.DumpFull(ctx, dumpDir)
+		err = opBk.DumpFull(ctx, dumpDir, DumpOptions{})
 		Expect(err).NotTo(HaveOccurred())
 
 		dumpGTID, err := GetGTIDExecuted(dumpDir)
@@ -191,4 +191,26 @@ var _ = Describe("Operator", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(localInFile).To(BeFalse())
 	})
+	It("should analyze tables without writing binary logs", func() {
+		opRe.(operator).db.MustExec(`SET GLOBAL super_read_only=0`)
+		opRe.(operator).db.MustExec(`CREATE DATABASE bar`)
+		opRe.(operator).db.MustExec(`CREATE TABLE bar.t (i INT PRIMARY KEY)`)
+		opRe.(operator).db.MustExec(`CREATE DATABASE baz`)
+		opRe.(operator).db.MustExec(`CREATE TABLE baz.t (i INT PRIMARY KEY)`)
+		opRe.(operator).db.MustExec(`SET GLOBAL super_read_only=1`)
+
+		var gtid1 string
+		err := opRe.(operator).db.Get(&gtid1, `SELECT @@gtid_executed`)
+		Expect(err).NotTo(HaveOccurred())
+
+		err = opRe.AnalyzeTables(ctx, nil)
+		Expect(err).NotTo(HaveOccurred())
+		err = opRe.AnalyzeTables(ctx, []string{"bar"})
+		Expect(err).NotTo(HaveOccurred())
+
+		var gtid2 string
+		err = opRe.(operator).db.Get(&gtid2, `SELECT @@gtid_executed`)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gtid2).To(Equal(gtid1))
+	})
 })
@@ -9,6 +9,21 @@ type ServerStatus struct {
 	CurrentBinlog string
 }
 
+// DumpOptions specifies the databases to be dumped by DumpFull.
+// If both fields are empty, all databases are dumped.
+type DumpOptions struct {
+	// IncludeDatabases is the list of databases to be dumped.
+	IncludeDatabases []string `json:"includeDatabases,omitempty"`
+
+	// ExcludeDatabases is the list of databases not to be dumped.
+	ExcludeDatabases []string `json:"excludeDatabases,omitempty"`
+}
+
+// IsPartial returns true if the options limit the databases to be dumped.
+func (o DumpOptions) IsPartial() bool {
+	return len(o.IncludeDatabases) > 0 || len(o.ExcludeDatabases) > 0
+}
+
 type showMasterStatus struct {
 	File            string `db:"File"`
 	Position        int64  `db:"Position"`
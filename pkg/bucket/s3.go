@@ -56,20 +56,23 @@ func WithHTTPClient(c *http.Client) func(*s3.Options) {
 }
 
 type s3Bucket struct {
-	name   string
-	client *s3.Client
+	name     string
+	client   *s3.Client
+	partSize int64
 }
 
 // NewS3Bucket creates a Bucket that manage object in S3.
-func NewS3Bucket(name string, optFns ...func(*s3.Options)) (Bucket, error) {
+// `partSize` is the part size of multipart uploads.  If zero, it is decided from the size of each object.
+func NewS3Bucket(name string, partSize int64, optFns ...func(*s3.Options)) (Bucket, error) {
 	cfg, err := config.LoadDefaultConfig(context.Background())
 	if err != nil {
 		return nil, err
 	}
 
 	return s3Bucket{
-		name:   name,
-		client: s3.NewFromConfig(cfg, optFns...),
+		name:     name,
+		client:   s3.NewFromConfig(cfg, optFns...),
+		partSize: partSize,
 	}, nil
 }
 
@@ -85,7 +88,7 @@ func (b s3Bucket) Put(ctx context.Context, key string, data io.Reader, objectSiz
 	uploader := manager.NewUploader(b.client, func(u *manager.Uploader) {
 		u.Concurrency = 1
 		u.LeavePartsOnError = false
-		u.PartSize = decidePartSize(objectSize)
+		u.PartSize = decidePartSize(objectSize, b.partSize)
 	})
 	pi := &s3.PutObjectInput{
 		Bucket:      &b.name,
@@ -134,9 +137,12 @@ func (b s3Bucket) List(ctx context.Context, prefix string) ([]string, error) {
 	return keys, nil
 }
 
-func decidePartSize(objectSize int64) int64 {
-	var partSize int64
-	partSize = (objectSize + UploadParts - 1) / UploadParts                  // Round up the result of dividing objectSize by uploadPart.
-	partSize = ((partSize + PartSizeUnit - 1) / PartSizeUnit) * PartSizeUnit // Round up to the nearest PartSizeUnit.
-	return partSize
+// decidePartSize returns the part size to upload an object of `objectSize` in UploadParts parts at most.
+// If `partSize` is specified, it is used unless it is too small for the object.
+func decidePartSize(objectSize, partSize int64) int64 {
+	minSize := (objectSize + UploadParts - 1) / UploadParts // Round up the result of dividing objectSize by uploadPart.
+	if partSize > 0 {
+		return max(partSize, minSize)
+	}
+	return ((minSize + PartSizeUnit - 1) / PartSizeUnit) * PartSizeUnit // Round up to the nearest PartSizeUnit.
 }
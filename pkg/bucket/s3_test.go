@@ -70,7 +70,7 @@ var _ = Describe("S3Bucket", func() {
 		os.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
 		os.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
 
-		b, err := NewS3Bucket("test", WithEndpointURL("http://localhost:9000"), WithPathStyle())
+		b, err := NewS3Bucket("test", 0, WithEndpointURL("http://localhost:9000"), WithPathStyle())
 		Expect(err).NotTo(HaveOccurred())
 
 		err = b.Put(ctx, "foo/bar", strings.NewReader("01234567890123456789"), 128<<20)
@@ -103,7 +103,7 @@ var _ = Describe("S3Bucket", func() {
 		os.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
 		os.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
 
-		b, err := NewS3Bucket("test", WithEndpointURL("http://localhost:9000"), WithPathStyle())
+		b, err := NewS3Bucket("test", 0, WithEndpointURL("http://localhost:9000"), WithPathStyle())
 		Expect(err).NotTo(HaveOccurred())
 
 		dateCmd := exec.Command("date")
@@ -142,7 +142,7 @@ var _ = Describe("S3Bucket", func() {
 		os.Setenv("AWS_ACCESS_KEY_ID", "minioadmin")
 		os.Setenv("AWS_SECRET_ACCESS_KEY", "minioadmin")
 
-		b, err := NewS3Bucket("test", WithEndpointURL("http://localhost:9000"), WithPathStyle())
+		b, err := NewS3Bucket("test", 0, WithEndpointURL("http://localhost:9000"), WithPathStyle())
 		Expect(err).NotTo(HaveOccurred())
 
 		err = b.Put(ctx, "foo1/bar", strings.NewReader("01234567890123456789"), 128<<20)
@@ -175,31 +175,41 @@ var _ = Describe("S3Bucket", func() {
 	})
 
 	It("should calculate the partSize correctly", func() {
-		partSize := decidePartSize(0)
+		partSize := decidePartSize(0, 0)
 		Expect(partSize).Should(BeNumerically("==", 0))
 
-		partSize = decidePartSize(1)
+		partSize = decidePartSize(1, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit))
 
-		partSize = decidePartSize(50)
+		partSize = decidePartSize(50, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit))
 
-		partSize = decidePartSize(PartSizeUnit*UploadParts - 1)
+		partSize = decidePartSize(PartSizeUnit*UploadParts-1, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit))
 
-		partSize = decidePartSize(PartSizeUnit * UploadParts)
+		partSize = decidePartSize(PartSizeUnit*UploadParts, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit))
 
-		partSize = decidePartSize(PartSizeUnit*UploadParts + 1)
+		partSize = decidePartSize(PartSizeUnit*UploadParts+1, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit*2))
 
-		partSize = decidePartSize(PartSizeUnit*2*UploadParts - 1)
+		partSize = decidePartSize(PartSizeUnit*2*UploadParts-1, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit*2))
 
-		partSize = decidePartSize(PartSizeUnit * 2 * UploadParts)
+		partSize = decidePartSize(PartSizeUnit*2*UploadParts, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit*2))
 
-		partSize = decidePartSize(PartSizeUnit*2*UploadParts + 1)
+		partSize = decidePartSize(PartSizeUnit*2*UploadParts+1, 0)
 		Expect(partSize).Should(BeNumerically("==", PartSizeUnit*3))
+
+		By("specifying the part size")
+		partSize = decidePartSize(0, 16<<20)
+		Expect(partSize).Should(BeNumerically("==", 16<<20))
+
+		partSize = decidePartSize((16<<20)*UploadParts, 16<<20)
+		Expect(partSize).Should(BeNumerically("==", 16<<20))
+
+		partSize = decidePartSize((16<<20)*UploadParts+1, 16<<20)
+		Expect(partSize).Should(BeNumerically("==", (16<<20)+1))
 	})
 })
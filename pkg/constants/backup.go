@@ -4,13 +4,53 @@ package constants
 const (
 	BackupSubcommand  = "backup"
 	RestoreSubcommand = "restore"
+	AnalyzeSubcommand = "analyze"
 
 	BackupTimeFormat = "20060102-150405"
 	DumpFilename     = "dump.tar"
 	BinlogFilename   = "binlog.tar.zst"
+
+	// DumpOptionsFilename is the name of the file in the dump directory
+	// that records the databases included in or excluded from a partial dump.
+	DumpOptionsFilename = "moco-dump-options.json"
 )
 
 const (
 	BackendTypeS3  = "s3"
 	BackendTypeGCS = "gcs"
 )
+
+// The range of the part size of multipart uploads that S3 allows.
+const (
+	MinPartSize = 5 << 20
+	MaxPartSize = 5 << 30
+)
+
+// Working directory in backup and restore containers.
+const (
+	JobWorkVolumeName         = "work"
+	DefaultJobWorkVolumeMount = "/work"
+)
+
+// CA bundle for the object storage mounted in backup and restore containers.
+const (
+	JobCABundleVolumeName = "ca-bundle"
+	JobCABundleMountPath  = "/etc/moco-backup/ca"
+	JobCABundleFilename   = "ca-bundle.crt"
+
+	// JobCABundleEnvName is the environment variable for AWS SDKs to read the CA bundle.
+	JobCABundleEnvName = "AWS_CA_BUNDLE"
+
+	// DefaultCABundleKey is the default key of the CA bundle in the ConfigMap or Secret.
+	DefaultCABundleKey = "ca.crt"
+)
+
+// Backup jobs.
+const (
+	// DefaultBackupActiveDeadlineSeconds bounds the runtime of a backup Job
+	// including retries when `activeDeadlineSeconds` of BackupPolicy is not set.
+	DefaultBackupActiveDeadlineSeconds = 24 * 60 * 60
+
+	// BackupAttemptsHistoryLimit is the number of backup attempts recorded in MySQLCluster status.
+	BackupAttemptsHistoryLimit = 5
+)
@@ -22,6 +22,9 @@ const (
 	// MySQLSlowLogName is the filename of slow query log for MySQL.
 	MySQLSlowLogName = "mysql.slow"
 
+	// MySQLAuditLogName is the filename of audit log for MySQL.
+	MySQLAuditLogName = "audit.log"
+
 	// TmpPath is the path for /tmp.
 	TmpPath = "/tmp"
 
@@ -30,6 +33,9 @@ const (
 
 	// SharedPath is the path for shared dir.
 	SharedPath = "/shared"
+
+	// ServiceAccountTokenPath is the path where client-go looks for the ServiceAccount token.
+	ServiceAccountTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount"
 )
 
 const (
@@ -68,3 +74,9 @@ const (
 )
 
 const LowerCaseTableNamesConfKey = "lower_case_table_names"
+
+// The table to publish the role of each instance
+const (
+	InstanceRolesSchema = "moco"
+	InstanceRolesTable  = "instance_roles"
+)
@@ -7,6 +7,7 @@ const (
 	CopyInitContainerName          = "copy-moco-init"
 	MysqldContainerName            = "mysqld"
 	SlowQueryLogAgentContainerName = "slow-log"
+	AuditLogAgentContainerName     = "audit-log"
 	ExporterContainerName          = "mysqld-exporter"
 )
 
@@ -27,6 +28,11 @@ const (
 	SlowQueryLogAgentMemRequest = "20Mi"
 	SlowQueryLogAgentMemLimit   = "20Mi"
 
+	AuditLogAgentCPURequest = "100m"
+	AuditLogAgentCPULimit   = "100m"
+	AuditLogAgentMemRequest = "20Mi"
+	AuditLogAgentMemLimit   = "20Mi"
+
 	ExporterContainerCPURequest = "200m"
 	ExporterContainerCPULimit   = "200m"
 	ExporterContainerMemRequest = "100Mi"
@@ -44,7 +50,9 @@ const (
 	VarLogVolumeName                  = "var-log"
 	TmpVolumeName                     = "tmp"
 	SlowQueryLogAgentConfigVolumeName = "slow-fluent-bit-config"
+	AuditLogAgentConfigVolumeName     = "audit-fluent-bit-config"
 	SharedVolumeName                  = "shared"
+	ServiceAccountTokenVolumeName     = "kube-api-access"
 )
 
 // UID/GID
@@ -60,3 +68,36 @@ const (
 
 // PreStop sleep duration
 const PreStopSeconds = "20"
+
+// ReplicaDrainSeconds is the default maximum duration of the preStop hook of mysqld container
+// to wait for client connections to finish.
+const ReplicaDrainSeconds = 20
+
+// SlowQueryLogAgentPreStopSeconds is the default preStop sleep duration of the slow-log container.
+const SlowQueryLogAgentPreStopSeconds = 25
+
+// AuditLogAgentPreStopSeconds is the preStop sleep duration of the audit-log container.
+const AuditLogAgentPreStopSeconds = 25
+
+// PrimaryDrainTimeoutSeconds is the default duration to wait for connections to the primary
+// to finish before a switchover caused by the deletion of the primary Pod.
+const PrimaryDrainTimeoutSeconds = 5
+
+// SlowQueryLogAgentGraceSeconds is the duration for fluent-bit to flush the remaining logs after SIGTERM.
+const SlowQueryLogAgentGraceSeconds = 5
+
+// AuditLogAgentGraceSeconds is the duration for fluent-bit to flush the remaining audit logs after SIGTERM.
+const AuditLogAgentGraceSeconds = 5
+
+// environment variables of the slow-log container to pass the credentials of the log sink.
+const (
+	SlowQueryLogOutputUserEnvName     = "SLOW_LOG_OUTPUT_USER"
+	SlowQueryLogOutputPasswordEnvName = "SLOW_LOG_OUTPUT_PASSWORD"
+)
+
+// ExporterPasswordEnvName is the environment variable of mysqld_exporter to pass the password
+// of the user specified by `spec.exporterUserName`.
+const ExporterPasswordEnvName = "MYSQLD_EXPORTER_PASSWORD"
+
+// WarmUpWaitSeconds is the maximum duration for the default warm-up hook to wait for mysqld
+const WarmUpWaitSeconds = 60
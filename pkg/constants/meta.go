@@ -2,17 +2,25 @@ package constants
 
 // label keys and values
 const (
-	LabelAppInstance  = "app.kubernetes.io/instance"
-	LabelAppNamespace = "app.kubernetes.io/instance-namespace"
-	LabelAppName      = "app.kubernetes.io/name"
-	AppNameMySQL      = "mysql"
-	AppNameBackup     = "mysql-backup"
-	LabelAppCreatedBy = "app.kubernetes.io/created-by"
-	AppCreator        = "moco"
+	LabelAppInstance   = "app.kubernetes.io/instance"
+	LabelAppNamespace  = "app.kubernetes.io/instance-namespace"
+	LabelAppName       = "app.kubernetes.io/name"
+	AppNameMySQL       = "mysql"
+	AppNameBackup      = "mysql-backup"
+	AppNameExporter    = "mysql-exporter"
+	AppNameMaintenance = "mysql-maintenance"
+	LabelAppCreatedBy  = "app.kubernetes.io/created-by"
+	AppCreator         = "moco"
 
 	LabelMocoRole = "moco.cybozu.com/role"
 	RolePrimary   = "primary"
 	RoleReplica   = "replica"
+
+	// LabelJobRole is the label to allow a Role or ClusterRole to be referenced by `jobConfig.roleRef`.
+	LabelJobRole = "moco.cybozu.com/job-role"
+
+	// LabelGrafanaDashboard is the label key that the Grafana sidecar looks for.
+	LabelGrafanaDashboard = "grafana_dashboard"
 )
 
 // annotation keys and values
@@ -21,6 +29,51 @@ const (
 	AnnSecretVersion         = "moco.cybozu.com/secret-version"
 	AnnClusteringStopped     = "moco.cybozu.com/clustering-stopped"
 	AnnReconciliationStopped = "moco.cybozu.com/reconciliation-stopped"
+	AnnRoleLabelKey          = "moco.cybozu.com/role-label-key"
+	AnnAllowHostNamespaces   = "moco.cybozu.com/allow-host-namespaces"
+	AnnAllowEvenReplicas     = "moco.cybozu.com/allow-even-replicas"
+
+	// AnnReplicationAllowedNamespaces is the MySQLCluster annotation key to list the namespaces,
+	// separated by commas, whose MySQLClusters may replicate from the cluster by `spec.replicationSource`.
+	AnnReplicationAllowedNamespaces = "moco.cybozu.com/replication-allowed-namespaces"
+
+	// AnnOperationHolder and AnnOperationLeaseExpires are the MySQLCluster annotation keys
+	// to record the controller operating the cluster, e.g. switching over the primary,
+	// and when its lease expires.
+	AnnOperationHolder       = "moco.cybozu.com/operation-holder"
+	AnnOperationLeaseExpires = "moco.cybozu.com/operation-lease-expires"
+
+	// AnnSplitBrainSurvivor is the MySQLCluster annotation key to choose the index of
+	// the instance that keeps being writable when writable instances have diverged.
+	AnnSplitBrainSurvivor = "moco.cybozu.com/split-brain-survivor"
+
+	// AnnRotatePasswords is the MySQLCluster annotation key to request rotating
+	// the passwords of the users for MOCO.  The value is ignored.
+	AnnRotatePasswords = "moco.cybozu.com/rotate-passwords"
+
+	// AnnPasswordRevision is the annotation key to record the revision of the passwords
+	// of the users for MOCO.  Pods are restarted when it changes so that moco-agent
+	// and the other containers use the new passwords.
+	AnnPasswordRevision = "moco.cybozu.com/password-revision"
+
+	// AnnSlowQueryLogOutput is the Pod annotation key to record the hash of the
+	// slow log output configuration.  fluent-bit does not reload its configuration,
+	// so Pods are restarted when it changes.
+	AnnSlowQueryLogOutput = "moco.cybozu.com/slow-log-output"
+
+	// AnnInstanceResources is the Pod annotation key to record the hash of
+	// `spec.instanceResources`.  The resources are set to Pods only when they are
+	// created, so Pods are restarted when it changes.
+	AnnInstanceResources = "moco.cybozu.com/instance-resources"
+
+	// AnnDeadlineExceededReported is the Job annotation key to record that the event for
+	// the Job exceeding its active deadline has been recorded.
+	AnnDeadlineExceededReported = "moco.cybozu.com/deadline-exceeded-reported"
+
+	// AnnReloaderConfigMaps and AnnReloaderSecrets are the annotation keys that
+	// Stakater Reloader looks for to restart workloads.
+	AnnReloaderConfigMaps = "configmap.reloader.stakater.com/reload"
+	AnnReloaderSecrets    = "secret.reloader.stakater.com/reload"
 )
 
 // MySQLClusterFinalizer is the finalizer specifier for MySQLCluster.
@@ -42,4 +42,12 @@ const (
 	BackupMyCnf   = BackupUser + "-my.cnf"
 	ReadOnlyMyCnf = ReadOnlyUser + "-my.cnf"
 	WritableMyCnf = WritableUser + "-my.cnf"
+
+	// ExporterRemoteMyCnf is used by mysqld_exporter running as a Deployment.
+	ExporterRemoteMyCnf = ExporterUser + "-remote-my.cnf"
+
+	// ExporterUserMyCnf and ExporterUserRemoteMyCnf are used by mysqld_exporter
+	// instead of the above when `spec.exporterUserName` is set.  They have no password.
+	ExporterUserMyCnf       = "exporter-user-my.cnf"
+	ExporterUserRemoteMyCnf = "exporter-user-remote-my.cnf"
 )
@@ -0,0 +1,15 @@
+package dbop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+func (o *operator) CreateDatabase(ctx context.Context, name string) error {
+	quoted := "`" + strings.ReplaceAll(name, "`", "``") + "`"
+	if _, err := o.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+quoted); err != nil {
+		return fmt.Errorf("failed to create database %s: %w", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package dbop
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/password"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("database", func() {
+	It("should create databases idempotently", func() {
+		By("preparing a single node cluster")
+		cluster := &mocov1beta2.MySQLCluster{}
+		cluster.Namespace = "test"
+		cluster.Name = "database"
+		cluster.Spec.Replicas = 1
+
+		passwd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+
+		op, err := factory.New(context.Background(), cluster, passwd, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = op.(*operator).db.Exec("SET GLOBAL read_only=0")
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, name := range []string{"app", "app", "with`quote"} {
+			err = op.CreateDatabase(context.Background(), name)
+			Expect(err).NotTo(HaveOccurred(), name)
+		}
+
+		var count int
+		err = op.(*operator).db.Get(&count, "SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME IN ('app', 'with`quote')")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(2))
+	})
+})
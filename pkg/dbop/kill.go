@@ -9,13 +9,16 @@ import (
 	"github.com/go-sql-driver/mysql"
 )
 
-func (o *operator) KillConnections(ctx context.Context) error {
+// userProcesses returns the processes of connections except for ones from `localhost`
+// and ones from MOCO's system users.
+func (o *operator) userProcesses(ctx context.Context) ([]Process, error) {
 	var procs []Process
 
 	if err := o.db.SelectContext(ctx, &procs, `SELECT ID, USER, HOST FROM information_schema.PROCESSLIST`); err != nil {
-		return fmt.Errorf("failed to get process list: %w", err)
+		return nil, fmt.Errorf("failed to get process list: %w", err)
 	}
 
+	var userProcs []Process
 	for _, p := range procs {
 		if constants.MocoSystemUsers[p.User] {
 			continue
@@ -23,7 +26,26 @@ func (o *operator) KillConnections(ctx context.Context) error {
 		if p.Host == "localhost" {
 			continue
 		}
+		userProcs = append(userProcs, p)
+	}
+	return userProcs, nil
+}
+
+func (o *operator) CountConnections(ctx context.Context) (int, error) {
+	procs, err := o.userProcesses(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return len(procs), nil
+}
 
+func (o *operator) KillConnections(ctx context.Context) error {
+	procs, err := o.userProcesses(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range procs {
 		if _, err := o.db.ExecContext(ctx, `KILL CONNECTION ?`, p.ID); err != nil && !isNoSuchThread(err) {
 			return fmt.Errorf("failed to kill connection %d for %s from %s: %w", p.ID, p.User, p.Host, err)
 		}
@@ -47,6 +47,11 @@ var _ = Describe("kill", func() {
 		}
 		Expect(fooFound).To(BeTrue())
 
+		By("counting user processes")
+		n, err := op.CountConnections(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(1))
+
 		By("killing user process")
 		err = op.KillConnections(context.Background())
 		Expect(err).NotTo(HaveOccurred())
@@ -64,5 +69,9 @@ var _ = Describe("kill", func() {
 			}
 		}
 		Expect(fooFound).To(BeFalse())
+
+		n, err = op.CountConnections(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(n).To(Equal(0))
 	})
 })
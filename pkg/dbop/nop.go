@@ -3,6 +3,9 @@ package dbop
 import (
 	"context"
 	"errors"
+	"time"
+
+	"github.com/cybozu-go/moco/pkg/password"
 )
 
 // ErrNop is a sentinel error for NopOperator
@@ -39,7 +42,7 @@ func (o NopOperator) ConfigureReplica(ctx context.Context, source AccessInfo, se
 	return ErrNop
 }
 
-func (o NopOperator) ConfigurePrimary(ctx context.Context, waitForCount int) error {
+func (o NopOperator) ConfigurePrimary(ctx context.Context, waitForCount int, timeout time.Duration) error {
 	return ErrNop
 }
 
@@ -55,6 +58,34 @@ func (o NopOperator) SetReadOnly(context.Context, bool) error {
 	return ErrNop
 }
 
+func (o NopOperator) CountConnections(context.Context) (int, error) {
+	return 0, ErrNop
+}
+
 func (o NopOperator) KillConnections(context.Context) error {
 	return ErrNop
 }
+
+func (o NopOperator) UpdateInstanceRoles(context.Context, []InstanceRole) error {
+	return ErrNop
+}
+
+func (o NopOperator) ApplyUser(ctx context.Context, user, password string, grants []string) error {
+	return ErrNop
+}
+
+func (o NopOperator) DropUser(ctx context.Context, user string) error {
+	return ErrNop
+}
+
+func (o NopOperator) RotatePasswords(ctx context.Context, passwd *password.MySQLPassword) error {
+	return ErrNop
+}
+
+func (o NopOperator) DiscardOldPasswords(ctx context.Context) error {
+	return ErrNop
+}
+
+func (o NopOperator) CreateDatabase(ctx context.Context, name string) error {
+	return ErrNop
+}
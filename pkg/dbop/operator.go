@@ -43,8 +43,9 @@ type Operator interface {
 	ConfigureReplica(ctx context.Context, source AccessInfo, semisync bool) error
 
 	// ConfigurePrimary configures server-side semi-synchronous replication.
+	// `timeout` is the time to wait for the acknowledgements from replicas, in milliseconds precision.
 	// For asynchronous replication, this method should not be called.
-	ConfigurePrimary(ctx context.Context, waitForCount int) error
+	ConfigurePrimary(ctx context.Context, waitForCount int, timeout time.Duration) error
 
 	// StopReplicaIOThread executes `STOP SLAVE IO_THREAD`.
 	StopReplicaIOThread(context.Context) error
@@ -61,6 +62,35 @@ type Operator interface {
 	// KillConnections kills all connections except for ones from `localhost`
 	// and ones for MOCO.
 	KillConnections(context.Context) error
+
+	// CountConnections returns the number of connections that KillConnections would kill.
+	CountConnections(context.Context) (int, error)
+
+	// UpdateInstanceRoles records the role of each instance in the instance roles table.
+	// The table is created if it does not exist, and is written only if `roles` differ
+	// from its contents.
+	UpdateInstanceRoles(context.Context, []InstanceRole) error
+
+	// ApplyUser creates a user that can connect from any host, or updates the existing one,
+	// so that the user has exactly `password` and `grants`.
+	// Each item of `grants` is parsed by mocov1beta2.ParseGrant.
+	// Missing privileges are granted before extra ones are revoked so that the user
+	// does not lose the privileges to keep while it is being updated.
+	ApplyUser(ctx context.Context, user, password string, grants []string) error
+
+	// DropUser drops a user created by ApplyUser if it exists.
+	DropUser(ctx context.Context, user string) error
+
+	// RotatePasswords sets the passwords in `passwd` to the users for MOCO while retaining
+	// the current passwords as secondary ones, so that both are accepted.
+	// Users that already have a secondary password are skipped, so this can be retried.
+	RotatePasswords(ctx context.Context, passwd *password.MySQLPassword) error
+
+	// DiscardOldPasswords discards the secondary passwords of the users for MOCO.
+	DiscardOldPasswords(ctx context.Context) error
+
+	// CreateDatabase creates a database if it does not exist.
+	CreateDatabase(ctx context.Context, name string) error
 }
 
 // OperatorFactory represents the factory for Operators.
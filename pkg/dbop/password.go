@@ -0,0 +1,77 @@
+package dbop
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/password"
+	"github.com/jmoiron/sqlx"
+)
+
+type mocoAccount struct {
+	User     string `db:"User"`
+	Host     string `db:"Host"`
+	Retained bool   `db:"Retained"`
+}
+
+// getMOCOAccounts returns the accounts of the users for MOCO.
+// `Retained` is true if the account has a secondary password.
+func (o *operator) getMOCOAccounts(ctx context.Context) ([]mocoAccount, error) {
+	query, args, err := sqlx.In(`SELECT User, Host,
+  JSON_CONTAINS_PATH(COALESCE(User_attributes, '{}'), 'one', '$.additional_password') AS Retained
+FROM mysql.user WHERE User IN (?)`, constants.MocoUsers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build query: %w", err)
+	}
+
+	var accounts []mocoAccount
+	if err := o.db.SelectContext(ctx, &accounts, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get the accounts for MOCO: %w", err)
+	}
+	return accounts, nil
+}
+
+func (o *operator) RotatePasswords(ctx context.Context, passwd *password.MySQLPassword) error {
+	passwords := map[string]string{
+		constants.AdminUser:       passwd.Admin(),
+		constants.AgentUser:       passwd.Agent(),
+		constants.ReplicationUser: passwd.Replicator(),
+		constants.CloneDonorUser:  passwd.Donor(),
+		constants.ExporterUser:    passwd.Exporter(),
+		constants.BackupUser:      passwd.Backup(),
+		constants.ReadOnlyUser:    passwd.ReadOnly(),
+		constants.WritableUser:    passwd.Writable(),
+	}
+
+	accounts, err := o.getMOCOAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		// Retaining the current password again would discard the password in use.
+		if a.Retained {
+			continue
+		}
+		if _, err := o.db.ExecContext(ctx, `ALTER USER ?@? IDENTIFIED BY ? RETAIN CURRENT PASSWORD`, a.User, a.Host, passwords[a.User]); err != nil {
+			return fmt.Errorf("failed to set the new password of user %s: %w", a.User, err)
+		}
+	}
+	return nil
+}
+
+func (o *operator) DiscardOldPasswords(ctx context.Context) error {
+	accounts, err := o.getMOCOAccounts(ctx)
+	if err != nil {
+		return err
+	}
+	for _, a := range accounts {
+		if !a.Retained {
+			continue
+		}
+		if _, err := o.db.ExecContext(ctx, `ALTER USER ?@? DISCARD OLD PASSWORD`, a.User, a.Host); err != nil {
+			return fmt.Errorf("failed to discard the old password of user %s: %w", a.User, err)
+		}
+	}
+	return nil
+}
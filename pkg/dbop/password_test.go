@@ -0,0 +1,70 @@
+package dbop
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/constants"
+	"github.com/cybozu-go/moco/pkg/password"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("password", func() {
+	It("should rotate the passwords of the users for MOCO", func() {
+		By("preparing a single node cluster")
+		cluster := &mocov1beta2.MySQLCluster{}
+		cluster.Namespace = "test"
+		cluster.Name = "password"
+		cluster.Spec.Replicas = 1
+
+		passwd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+
+		op, err := factory.New(context.Background(), cluster, passwd, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = op.(*operator).db.Exec("SET GLOBAL read_only=0")
+		Expect(err).NotTo(HaveOccurred())
+
+		canConnect := func(user, passwd string) bool {
+			db, err := factory.(*testFactory).newConn(context.Background(), cluster, user, passwd, 0)
+			if err != nil {
+				return false
+			}
+			db.Close()
+			return true
+		}
+
+		newPasswd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+
+		By("setting the new passwords")
+		err = op.RotatePasswords(context.Background(), newPasswd)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canConnect(constants.AgentUser, passwd.Agent())).To(BeTrue())
+		Expect(canConnect(constants.AgentUser, newPasswd.Agent())).To(BeTrue())
+		Expect(canConnect(constants.ReadOnlyUser, passwd.ReadOnly())).To(BeTrue())
+		Expect(canConnect(constants.ReadOnlyUser, newPasswd.ReadOnly())).To(BeTrue())
+
+		By("retrying the rotation")
+		err = op.RotatePasswords(context.Background(), newPasswd)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canConnect(constants.AgentUser, passwd.Agent())).To(BeTrue())
+		Expect(canConnect(constants.AgentUser, newPasswd.Agent())).To(BeTrue())
+
+		By("discarding the old passwords")
+		err = op.DiscardOldPasswords(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(canConnect(constants.AgentUser, passwd.Agent())).To(BeFalse())
+		Expect(canConnect(constants.AgentUser, newPasswd.Agent())).To(BeTrue())
+		Expect(canConnect(constants.ReadOnlyUser, passwd.ReadOnly())).To(BeFalse())
+		Expect(canConnect(constants.ReadOnlyUser, newPasswd.ReadOnly())).To(BeTrue())
+
+		op.Close()
+		op, err = factory.New(context.Background(), cluster, newPasswd, 0)
+		Expect(err).NotTo(HaveOccurred())
+		defer op.Close()
+		err = op.DiscardOldPasswords(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
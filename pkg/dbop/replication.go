@@ -3,10 +3,9 @@ package dbop
 import (
 	"context"
 	"fmt"
+	"time"
 )
 
-const semiSyncMasterTimeout = 24 * 60 * 60 * 1000
-
 func (o *operator) ConfigureReplica(ctx context.Context, primary AccessInfo, semisync bool) error {
 	if _, err := o.db.ExecContext(ctx, `STOP SLAVE`); err != nil {
 		return fmt.Errorf("failed to stop replica: %w", err)
@@ -26,8 +25,8 @@ func (o *operator) ConfigureReplica(ctx context.Context, primary AccessInfo, sem
 	return nil
 }
 
-func (o *operator) ConfigurePrimary(ctx context.Context, waitForCount int) error {
-	if _, err := o.db.ExecContext(ctx, "SET GLOBAL rpl_semi_sync_master_timeout=?", semiSyncMasterTimeout); err != nil {
+func (o *operator) ConfigurePrimary(ctx context.Context, waitForCount int, timeout time.Duration) error {
+	if _, err := o.db.ExecContext(ctx, "SET GLOBAL rpl_semi_sync_master_timeout=?", timeout.Milliseconds()); err != nil {
 		return fmt.Errorf("failed to set rpl_semi_sync_master_timeout count: %w", err)
 	}
 	if _, err := o.db.ExecContext(ctx, "SET GLOBAL rpl_semi_sync_master_wait_for_slave_count=?", waitForCount); err != nil {
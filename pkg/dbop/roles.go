@@ -0,0 +1,72 @@
+package dbop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/cybozu-go/moco/pkg/constants"
+)
+
+const instanceRolesTable = constants.InstanceRolesSchema + "." + constants.InstanceRolesTable
+
+func (o *operator) UpdateInstanceRoles(ctx context.Context, roles []InstanceRole) error {
+	// Avoid `CREATE ... IF NOT EXISTS` because it is written to the binary log
+	// and consumes a GTID even if the table already exists.
+	var count int
+	if err := o.db.GetContext(ctx, &count, `SELECT COUNT(*) FROM information_schema.TABLES WHERE TABLE_SCHEMA = ? AND TABLE_NAME = ?`,
+		constants.InstanceRolesSchema, constants.InstanceRolesTable); err != nil {
+		return fmt.Errorf("failed to check the existence of the instance roles table: %w", err)
+	}
+	if count == 0 {
+		if _, err := o.db.ExecContext(ctx, "CREATE DATABASE IF NOT EXISTS "+constants.InstanceRolesSchema); err != nil {
+			return fmt.Errorf("failed to create database %s: %w", constants.InstanceRolesSchema, err)
+		}
+		if _, err := o.db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS "+instanceRolesTable+" (server_id INT UNSIGNED NOT NULL PRIMARY KEY, role VARCHAR(16) NOT NULL)"); err != nil {
+			return fmt.Errorf("failed to create the instance roles table: %w", err)
+		}
+	}
+
+	desired := make([]InstanceRole, len(roles))
+	copy(desired, roles)
+	sort.Slice(desired, func(i, j int) bool { return desired[i].ServerID < desired[j].ServerID })
+
+	var current []InstanceRole
+	if err := o.db.SelectContext(ctx, &current, "SELECT server_id, role FROM "+instanceRolesTable+" ORDER BY server_id"); err != nil {
+		return fmt.Errorf("failed to get instance roles: %w", err)
+	}
+	if equalInstanceRoles(current, desired) {
+		return nil
+	}
+
+	tx, err := o.db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin a transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM "+instanceRolesTable); err != nil {
+		return fmt.Errorf("failed to delete instance roles: %w", err)
+	}
+	for _, r := range desired {
+		if _, err := tx.ExecContext(ctx, "INSERT INTO "+instanceRolesTable+" (server_id, role) VALUES (?, ?)", r.ServerID, r.Role); err != nil {
+			return fmt.Errorf("failed to insert the role of server %d: %w", r.ServerID, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit instance roles: %w", err)
+	}
+	return nil
+}
+
+func equalInstanceRoles(a, b []InstanceRole) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,69 @@
+package dbop
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/password"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("roles", func() {
+	It("should record instance roles", func() {
+		By("preparing a single node cluster")
+		cluster := &mocov1beta2.MySQLCluster{}
+		cluster.Namespace = "test"
+		cluster.Name = "roles"
+		cluster.Spec.Replicas = 1
+
+		passwd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+
+		op, err := factory.New(context.Background(), cluster, passwd, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = op.(*operator).db.Exec("SET GLOBAL read_only=0")
+		Expect(err).NotTo(HaveOccurred())
+
+		By("creating the table and recording roles")
+		roles := []InstanceRole{
+			{ServerID: 11, Role: "replica"},
+			{ServerID: 10, Role: "primary"},
+		}
+		err = op.UpdateInstanceRoles(context.Background(), roles)
+		Expect(err).NotTo(HaveOccurred())
+
+		var current []InstanceRole
+		err = op.(*operator).db.Select(&current, "SELECT server_id, role FROM moco.instance_roles ORDER BY server_id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(Equal([]InstanceRole{
+			{ServerID: 10, Role: "primary"},
+			{ServerID: 11, Role: "replica"},
+		}))
+
+		By("not writing anything when roles are not changed")
+		var gtid1, gtid2 string
+		err = op.(*operator).db.Get(&gtid1, "SELECT @@gtid_executed")
+		Expect(err).NotTo(HaveOccurred())
+		err = op.UpdateInstanceRoles(context.Background(), roles)
+		Expect(err).NotTo(HaveOccurred())
+		err = op.(*operator).db.Get(&gtid2, "SELECT @@gtid_executed")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gtid2).To(Equal(gtid1))
+
+		By("updating roles")
+		err = op.UpdateInstanceRoles(context.Background(), []InstanceRole{
+			{ServerID: 10, Role: "replica"},
+			{ServerID: 11, Role: "primary"},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		current = nil
+		err = op.(*operator).db.Select(&current, "SELECT server_id, role FROM moco.instance_roles ORDER BY server_id")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(current).To(Equal([]InstanceRole{
+			{ServerID: 10, Role: "replica"},
+			{ServerID: 11, Role: "primary"},
+		}))
+	})
+})
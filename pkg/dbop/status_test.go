@@ -2,6 +2,7 @@ package dbop
 
 import (
 	"context"
+	"time"
 
 	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
 	"github.com/cybozu-go/moco/pkg/password"
@@ -50,12 +51,13 @@ var _ = Describe("status", func() {
 		Expect(status.GlobalVariables.SemiSyncSlaveEnabled).To(BeFalse())
 
 		By("enabling semi-sync master")
-		err = op.ConfigurePrimary(context.Background(), 3)
+		err = op.ConfigurePrimary(context.Background(), 3, time.Minute)
 		Expect(err).NotTo(HaveOccurred())
 		status, err = op.GetStatus(context.Background())
 		Expect(err).NotTo(HaveOccurred())
 		Expect(status).NotTo(BeNil())
 		Expect(status.GlobalVariables.WaitForSlaveCount).To(Equal(3))
+		Expect(status.GlobalVariables.SemiSyncMasterTimeout).To(Equal(int64(60000)))
 		Expect(status.GlobalVariables.SemiSyncMasterEnabled).To(BeTrue())
 		Expect(status.GlobalVariables.SemiSyncSlaveEnabled).To(BeFalse())
 
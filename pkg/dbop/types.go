@@ -26,6 +26,7 @@ var statusGlobalVars = []string{
 	"@@read_only",
 	"@@super_read_only",
 	"@@rpl_semi_sync_master_wait_for_slave_count",
+	"@@rpl_semi_sync_master_timeout",
 	"@@rpl_semi_sync_master_enabled",
 	"@@rpl_semi_sync_slave_enabled",
 }
@@ -37,6 +38,7 @@ type GlobalVariables struct {
 	ReadOnly              bool   `db:"@@read_only"`
 	SuperReadOnly         bool   `db:"@@super_read_only"`
 	WaitForSlaveCount     int    `db:"@@rpl_semi_sync_master_wait_for_slave_count"`
+	SemiSyncMasterTimeout int64  `db:"@@rpl_semi_sync_master_timeout"`
 	SemiSyncMasterEnabled bool   `db:"@@rpl_semi_sync_master_enabled"`
 	SemiSyncSlaveEnabled  bool   `db:"@@rpl_semi_sync_slave_enabled"`
 }
@@ -138,3 +140,9 @@ type Process struct {
 	User string `db:"USER"`
 	Host string `db:"HOST"`
 }
+
+// InstanceRole represents a row in the instance roles table.
+type InstanceRole struct {
+	ServerID int32  `db:"server_id"`
+	Role     string `db:"role"`
+}
@@ -0,0 +1,168 @@
+package dbop
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+)
+
+const allPrivileges = "ALL PRIVILEGES"
+
+func (o *operator) ApplyUser(ctx context.Context, user, password string, grants []string) error {
+	desired := make(map[string][]string)
+	var levels []string
+	for _, g := range grants {
+		pg, err := mocov1beta2.ParseGrant(g)
+		if err != nil {
+			return fmt.Errorf("invalid grant %q for user %s: %w", g, user, err)
+		}
+		l := pg.Level()
+		if _, ok := desired[l]; !ok {
+			levels = append(levels, l)
+		}
+		desired[l] = append(desired[l], pg.Privileges...)
+	}
+
+	if _, err := o.db.ExecContext(ctx, `CREATE USER IF NOT EXISTS ?@'%' IDENTIFIED BY ?`, user, password); err != nil {
+		return fmt.Errorf("failed to create user %s: %w", user, err)
+	}
+	if _, err := o.db.ExecContext(ctx, `ALTER USER ?@'%' IDENTIFIED BY ?`, user, password); err != nil {
+		return fmt.Errorf("failed to set the password of user %s: %w", user, err)
+	}
+
+	current, err := o.getUserPrivileges(ctx, user)
+	if err != nil {
+		return err
+	}
+	var extraLevels []string
+	for l := range current {
+		if _, ok := desired[l]; !ok {
+			extraLevels = append(extraLevels, l)
+		}
+	}
+	sort.Strings(extraLevels)
+
+	for _, l := range append(levels, extraLevels...) {
+		if err := o.applyPrivileges(ctx, user, l, desired[l], current[l]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyPrivileges grants `want` and revokes the others of `have` at the privilege level `l`.
+// The missing privileges are granted first so that the user keeps the privileges in both.
+func (o *operator) applyPrivileges(ctx context.Context, user, l string, want []string, have map[string]bool) error {
+	wantSet := make(map[string]bool)
+	var wantList []string
+	for _, p := range want {
+		if p == "USAGE" || wantSet[p] {
+			continue
+		}
+		wantSet[p] = true
+		wantList = append(wantList, p)
+	}
+
+	// ALL PRIVILEGES includes every privilege but GRANT OPTION.
+	covered := func(set map[string]bool, p string) bool {
+		return set[p] || (set[allPrivileges] && p != "GRANT OPTION")
+	}
+
+	var toGrant, toRevoke []string
+	for _, p := range wantList {
+		if !covered(have, p) {
+			toGrant = append(toGrant, p)
+		}
+	}
+	for p := range have {
+		if !covered(wantSet, p) {
+			toRevoke = append(toRevoke, p)
+		}
+	}
+	sort.Strings(toRevoke)
+
+	grant := func(privs []string) error {
+		if len(privs) == 0 {
+			return nil
+		}
+		g := strings.Join(privs, ", ") + " ON " + l
+		if _, err := o.db.ExecContext(ctx, `GRANT `+g+` TO ?@'%'`, user); err != nil {
+			return fmt.Errorf("failed to grant %q to user %s: %w", g, user, err)
+		}
+		return nil
+	}
+	revoke := func(privs []string) error {
+		if len(privs) == 0 {
+			return nil
+		}
+		g := strings.Join(privs, ", ") + " ON " + l
+		if _, err := o.db.ExecContext(ctx, `REVOKE `+g+` FROM ?@'%'`, user); err != nil {
+			return fmt.Errorf("failed to revoke %q from user %s: %w", g, user, err)
+		}
+		return nil
+	}
+
+	// Revoking ALL PRIVILEGES also revokes the privileges to keep, so they are granted again afterwards.
+	if slices.Contains(toRevoke, allPrivileges) {
+		if err := revoke(toRevoke); err != nil {
+			return err
+		}
+		return grant(wantList)
+	}
+	if err := grant(toGrant); err != nil {
+		return err
+	}
+	return revoke(toRevoke)
+}
+
+// getUserPrivileges returns the privileges of user for each privilege level from `SHOW GRANTS`.
+// Grants that cannot be specified in `spec.users`, such as column privileges and roles, are ignored.
+func (o *operator) getUserPrivileges(ctx context.Context, user string) (map[string]map[string]bool, error) {
+	var lines []string
+	if err := o.db.SelectContext(ctx, &lines, `SHOW GRANTS FOR ?@'%'`, user); err != nil {
+		return nil, fmt.Errorf("failed to show grants for user %s: %w", user, err)
+	}
+
+	suffix := " TO `" + strings.ReplaceAll(user, "`", "``") + "`@`%`"
+	privs := make(map[string]map[string]bool)
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "GRANT ") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "GRANT ")
+		withGrantOption := strings.HasSuffix(line, " WITH GRANT OPTION")
+		line = strings.TrimSuffix(line, " WITH GRANT OPTION")
+		if !strings.HasSuffix(line, suffix) {
+			continue
+		}
+		g, err := mocov1beta2.ParseGrant(strings.TrimSuffix(line, suffix))
+		if err != nil {
+			continue
+		}
+
+		l := g.Level()
+		if privs[l] == nil {
+			privs[l] = make(map[string]bool)
+		}
+		for _, p := range g.Privileges {
+			if p != "USAGE" {
+				privs[l][p] = true
+			}
+		}
+		if withGrantOption {
+			privs[l]["GRANT OPTION"] = true
+		}
+	}
+	return privs, nil
+}
+
+func (o *operator) DropUser(ctx context.Context, user string) error {
+	if _, err := o.db.ExecContext(ctx, `DROP USER IF EXISTS ?@'%'`, user); err != nil {
+		return fmt.Errorf("failed to drop user %s: %w", user, err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package dbop
+
+import (
+	"context"
+
+	mocov1beta2 "github.com/cybozu-go/moco/api/v1beta2"
+	"github.com/cybozu-go/moco/pkg/password"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("user", func() {
+	It("should create, update, and drop users", func() {
+		By("preparing a single node cluster")
+		cluster := &mocov1beta2.MySQLCluster{}
+		cluster.Namespace = "test"
+		cluster.Name = "user"
+		cluster.Spec.Replicas = 1
+
+		passwd, err := password.NewMySQLPassword()
+		Expect(err).NotTo(HaveOccurred())
+
+		op, err := factory.New(context.Background(), cluster, passwd, 0)
+		Expect(err).NotTo(HaveOccurred())
+		_, err = op.(*operator).db.Exec("SET GLOBAL read_only=0")
+		Expect(err).NotTo(HaveOccurred())
+
+		showGrants := func() []string {
+			var grants []string
+			err := op.(*operator).db.Select(&grants, "SHOW GRANTS FOR 'app'@'%'")
+			Expect(err).NotTo(HaveOccurred())
+			return grants
+		}
+
+		By("creating a user")
+		err = op.ApplyUser(context.Background(), "app", "foo", []string{"SELECT, INSERT ON app.*"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(showGrants()).To(ContainElement("GRANT SELECT, INSERT ON `app`.* TO `app`@`%`"))
+
+		db, err := factory.(*testFactory).newConn(context.Background(), cluster, "app", "foo", 0)
+		Expect(err).NotTo(HaveOccurred())
+		db.Close()
+
+		By("updating the password and grants")
+		err = op.ApplyUser(context.Background(), "app", "bar", []string{"SELECT ON app.*"})
+		Expect(err).NotTo(HaveOccurred())
+		grants := showGrants()
+		Expect(grants).To(ContainElement("GRANT SELECT ON `app`.* TO `app`@`%`"))
+		Expect(grants).NotTo(ContainElement("GRANT SELECT, INSERT ON `app`.* TO `app`@`%`"))
+
+		_, err = factory.(*testFactory).newConn(context.Background(), cluster, "app", "foo", 0)
+		Expect(err).To(HaveOccurred())
+		db, err = factory.(*testFactory).newConn(context.Background(), cluster, "app", "bar", 0)
+		Expect(err).NotTo(HaveOccurred())
+		db.Close()
+
+		By("replacing ALL PRIVILEGES with a subset of them")
+		err = op.ApplyUser(context.Background(), "app", "bar", []string{"ALL ON app.*", "SELECT ON `other`.*"})
+		Expect(err).NotTo(HaveOccurred())
+		grants = showGrants()
+		Expect(grants).To(ContainElement("GRANT ALL PRIVILEGES ON `app`.* TO `app`@`%`"))
+		Expect(grants).To(ContainElement("GRANT SELECT ON `other`.* TO `app`@`%`"))
+
+		err = op.ApplyUser(context.Background(), "app", "bar", []string{"select, update on app.*"})
+		Expect(err).NotTo(HaveOccurred())
+		grants = showGrants()
+		Expect(grants).To(ContainElement("GRANT SELECT, UPDATE ON `app`.* TO `app`@`%`"))
+		Expect(grants).NotTo(ContainElement(ContainSubstring("`other`")))
+
+		By("rejecting an invalid grant")
+		err = op.ApplyUser(context.Background(), "app", "bar", []string{"SELECT ON app.* TO 'root'@'%'; DROP DATABASE app"})
+		Expect(err).To(HaveOccurred())
+		Expect(showGrants()).To(ContainElement("GRANT SELECT, UPDATE ON `app`.* TO `app`@`%`"))
+
+		By("dropping the user")
+		err = op.DropUser(context.Background(), "app")
+		Expect(err).NotTo(HaveOccurred())
+		var count int
+		err = op.(*operator).db.Get(&count, "SELECT COUNT(*) FROM mysql.user WHERE user = 'app'")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(count).To(Equal(0))
+
+		err = op.DropUser(context.Background(), "app")
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
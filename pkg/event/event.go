@@ -72,6 +72,21 @@ var (
 		Reason:  "FailOverFailed",
 		Message: "The primary could not be changed: %v",
 	}
+	SplitBrainDetected = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "SplitBrainDetected",
+		Message: "Instances %v are writable at the same time",
+	}
+	SplitBrainDiverged = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "SplitBrainDiverged",
+		Message: "Writable instances %v have diverged; annotate the cluster with %s=<index> to choose the instance to keep",
+	}
+	SplitBrainResolved = MOCOEvent{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "SplitBrainResolved",
+		Message: "Instance %d is kept writable and the other instances became read-only",
+	}
 	CloneSucceeded = MOCOEvent{
 		Type:    corev1.EventTypeNormal,
 		Reason:  "Cloned",
@@ -102,4 +117,104 @@ var (
 		Reason:  "Restored",
 		Message: "Successfully restored data from backup",
 	}
+	RestoreDeadlineExceeded = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "RestoreDeadlineExceeded",
+		Message: "Restore job %s was terminated because it exceeded the active deadline",
+	}
+	PointInTimeCloneExpired = MOCOEvent{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "PointInTimeCloneExpired",
+		Message: "The point-in-time clone expired at %s and is deleted",
+	}
+	QuotaExceeded = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "QuotaExceeded",
+		Message: "ResourceQuota %s may reject MySQL Pods: %s",
+	}
+	HostNamespacesRejected = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "HostNamespacesRejected",
+		Message: "StatefulSet is not updated because the Pod template enables %s; annotate the cluster with %s=true to allow it",
+	}
+	CanaryUpgradeHeld = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "CanaryUpgradeHeld",
+		Message: "Rolling update is held at partition %d because the updated instances are not healthy",
+	}
+	CanaryUpgradeStalled = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "CanaryUpgradeStalled",
+		Message: "Rolling update is stalled at partition %d because the updated instances have not become ready for %s",
+	}
+	CABundleNotFound = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "CABundleNotFound",
+		Message: "%s %s does not have the CA bundle in key %q; the %s job is not created or updated until it does",
+	}
+	JobRoleNotFound = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "JobRoleNotFound",
+		Message: "%s %s is not found; it is not bound to the %s job until it is created",
+	}
+	JobRoleNotAllowed = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "JobRoleNotAllowed",
+		Message: "%s %s is not bound to the job because it is not labeled with %s=true",
+	}
+	MySQLConfigMapNotFound = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "MySQLConfigMapNotFound",
+		Message: "ConfigMap %s is not found; my.cnf and StatefulSet are not updated until it is created",
+	}
+	OrdinalsUnsupported = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "OrdinalsUnsupported",
+		Message: "spec.ordinals.start is ignored because the Kubernetes cluster does not support StatefulSet ordinals",
+	}
+	PVCRetentionPolicyUnsupported = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "PVCRetentionPolicyUnsupported",
+		Message: "spec.persistentVolumeClaimRetentionPolicy is ignored because the Kubernetes cluster does not support it",
+	}
+	VolumeAccessModeUnsupported = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "VolumeAccessModeUnsupported",
+		Message: "volumeClaimTemplate %s requests %s that StorageClass %s (%s) is unlikely to satisfy",
+	}
+	ReplicationSourceNotFound = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "ReplicationSourceNotFound",
+		Message: "MySQLCluster %s to replicate from is not found",
+	}
+	ReplicationSourceNotAllowed = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "ReplicationSourceNotAllowed",
+		Message: "MySQLCluster %s does not allow replication to this namespace; annotate it with %s to allow it",
+	}
+	ReplicationSourceNotWatched = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "ReplicationSourceNotWatched",
+		Message: "MySQLCluster %s cannot be replicated from because moco-controller watches only namespace %s",
+	}
+	PasswordRotationStarted = MOCOEvent{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "PasswordRotationStarted",
+		Message: "New passwords of the users for MOCO were generated",
+	}
+	PasswordRotationRefused = MOCOEvent{
+		Type:    corev1.EventTypeWarning,
+		Reason:  "PasswordRotationRefused",
+		Message: "Passwords of the users for MOCO are not rotated because this cluster is the replication source of MySQLCluster %s",
+	}
+	PasswordsRotated = MOCOEvent{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "PasswordsRotated",
+		Message: "The old passwords of the users for MOCO were discarded",
+	}
+	BufferPoolSizeNotDerived = MOCOEvent{
+		Type:    corev1.EventTypeNormal,
+		Reason:  "BufferPoolSizeNotDerived",
+		Message: "innodb_buffer_pool_size is the minimum %dMiB because the mysqld container has no memory request; set resources.requests.memory of the container",
+	}
 )
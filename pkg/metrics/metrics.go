@@ -23,6 +23,8 @@ var (
 	ErrantReplicasVec  *prometheus.GaugeVec
 	ProcessingTimeVec  *prometheus.HistogramVec
 
+	ReplicationLagVec *prometheus.GaugeVec
+
 	VolumeResizedTotal            *prometheus.CounterVec
 	VolumeResizedErrorTotal       *prometheus.CounterVec
 	StatefulSetRecreateTotal      *prometheus.CounterVec
@@ -125,6 +127,14 @@ func Register(registry prometheus.Registerer) {
 	}, []string{"name", "namespace"})
 	registry.MustRegister(ProcessingTimeVec)
 
+	ReplicationLagVec = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: metricsNamespace,
+		Subsystem: clusteringSubsystem,
+		Name:      "replication_lag_seconds",
+		Help:      "The replication lag of the instance observed by MOCO, or NaN if unknown",
+	}, []string{"name", "namespace", "pod"})
+	registry.MustRegister(ReplicationLagVec)
+
 	BackupTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
 		Namespace: metricsNamespace,
 		Subsystem: backupSubsystem,
@@ -11,9 +11,13 @@ import (
 )
 
 // InnoDBBufferPoolRatioPercent is the ratio of InnoDB buffer pool size to resource.requests.memory.
-// Note that the pool size can't be lower than 128MiB, which is the default value of `innodb_buffer_pool_size`.
+// Note that the pool size can't be lower than MinInnoDBBufferPoolSize.
 const InnoDBBufferPoolRatioPercent = 70
 
+// MinInnoDBBufferPoolSize is the minimum of the derived InnoDB buffer pool size,
+// which is the default value of `innodb_buffer_pool_size`.
+const MinInnoDBBufferPoolSize = 128 << 20
+
 const opaqueKey = "_include"
 
 // DefaultMycnf is the default options of mysqld.
@@ -144,24 +148,102 @@ var ConstMycnf = map[string]map[string]string{
 	},
 }
 
+// AuditLogMycnf is the default options of mysqld to load the audit log plugin.
+// These are added only if the audit log is enabled, and can be overridden by users
+// except for `audit_log_file` which is read by the "audit-log" sidecar container.
+//
+// The audit log is rotated every 128 MiB so that it does not fill up the log volume.
+// Percona Server keeps 8 rotated files, and MySQL Enterprise Edition prunes them over 1 GiB.
+var AuditLogMycnf = map[string]string{
+	"plugin_load_add":                "audit_log.so",
+	"loose_audit_log_format":         "JSON",
+	"loose_audit_log_rotate_on_size": "134217728",
+	"loose_audit_log_rotations":      "8",
+	"loose_audit_log_max_size":       "1073741824",
+}
+
 func calcBufferSize(total int64) int64 {
 	m := total / 100 * InnoDBBufferPoolRatioPercent >> 20 << 20
-	if m < 128<<20 {
-		// 128MiB is the minimum buffer size
-		return 128 << 20
+	if m < MinInnoDBBufferPoolSize {
+		return MinInnoDBBufferPoolSize
 	}
 	return m
 }
 
-// Generate generates my.cnf contents.
-//
-// If `userConf` does not specify `innodb_buffer_pool_size`, this
-// will automatically set it to 70% of `memTotal`.
-func Generate(userConf map[string]string, memTotal int64) string {
+// Options are the parameters of Generate other than the user configuration.
+type Options struct {
+	// MemTotal is the memory of the mysqld container.  If the user configuration does not
+	// specify `innodb_buffer_pool_size`, it is set to 70% of MemTotal.
+	MemTotal int64
+
+	// DataDir is the directory where the data volume is mounted, and TmpDir is the directory
+	// for temporary files.  They override `datadir`, `tmpdir` and `innodb_tmpdir` if not empty.
+	DataDir string
+	TmpDir  string
+
+	// DisableSlowQueryLog turns off `slow_query_log` regardless of the user configuration.
+	DisableSlowQueryLog bool
+
+	// EnableAuditLog loads the audit log plugin with AuditLogMycnf and writes the audit log
+	// in the log directory.
+	EnableAuditLog bool
+
+	// RedoLog overrides the options of InnoDB redo log in the user configuration.  If it has
+	// `innodb_redo_log_capacity`, the options replaced by it in MySQL 8.0.30 are removed.
+	RedoLog map[string]string
+
+	// SlowQueryLog overrides the options of the slow query log in the user configuration.
+	SlowQueryLog map[string]string
+
+	// MaxConnections, if positive, replaces the default of `max_connections` unless
+	// the user configuration specifies `max_connections`.
+	MaxConnections int64
+}
+
+// Generate generates my.cnf contents from `userConf` and `opts`.
+func Generate(userConf map[string]string, opts Options) string {
 	opaque := userConf[opaqueKey]
-	mysqldConf := mergeSection(DefaultMycnf, userConf)
+	mysqldConf := DefaultMycnf
+	if opts.MaxConnections > 0 {
+		mysqldConf = mergeSection(mysqldConf, map[string]string{
+			"max_connections": strconv.FormatInt(opts.MaxConnections, 10),
+		})
+	}
+	mysqldConf = mergeSection(mysqldConf, userConf)
+	if opts.EnableAuditLog {
+		mysqldConf = mergeSection(AuditLogMycnf, mysqldConf)
+		mysqldConf = mergeSection(mysqldConf, map[string]string{
+			"loose_audit_log_file": filepath.Join(constants.LogDirPath, constants.MySQLAuditLogName),
+		})
+	}
 	if _, ok := mysqldConf["innodb_buffer_pool_size"]; !ok {
-		mysqldConf["innodb_buffer_pool_size"] = fmt.Sprint(calcBufferSize(memTotal))
+		mysqldConf["innodb_buffer_pool_size"] = fmt.Sprint(calcBufferSize(opts.MemTotal))
+	}
+	if opts.TmpDir != "" {
+		mysqldConf = mergeSection(mysqldConf, map[string]string{
+			"tmpdir":        opts.TmpDir,
+			"innodb_tmpdir": opts.TmpDir,
+		})
+	}
+
+	if len(opts.SlowQueryLog) > 0 {
+		mysqldConf = mergeSection(mysqldConf, opts.SlowQueryLog)
+	}
+	if opts.DisableSlowQueryLog {
+		mysqldConf = mergeSection(mysqldConf, map[string]string{
+			"slow_query_log": "OFF",
+		})
+	}
+
+	if len(opts.RedoLog) > 0 {
+		mysqldConf = mergeSection(mysqldConf, opts.RedoLog)
+		if _, ok := opts.RedoLog["innodb_redo_log_capacity"]; ok {
+			for _, k := range []string{"innodb_log_file_size", "innodb_log_files_in_group"} {
+				for _, kk := range listConfKeyVariations(k) {
+					delete(mysqldConf, kk)
+				}
+			}
+		}
 	}
 
 	delete(mysqldConf, opaqueKey)
@@ -174,6 +256,9 @@ func Generate(userConf map[string]string, memTotal int64) string {
 	for sec, secConf := range ConstMycnf {
 		conf[sec] = mergeSection(conf[sec], secConf)
 	}
+	if opts.DataDir != "" {
+		conf["mysqld"]["datadir"] = filepath.Join(opts.DataDir, "data")
+	}
 
 	// sort keys to generate reproducible my.cnf
 	sections := make([]string, 0, len(conf))
@@ -246,3 +331,70 @@ func listConfKeyVariations(k string) []string {
 	base := strings.TrimPrefix(k, "loose_")
 	return []string{base, "loose_" + base}
 }
+
+// HasInnoDBBufferPoolSize returns true if `userConf` specifies `innodb_buffer_pool_size`.
+// Otherwise, Generate derives it from the memory of the mysqld container.
+func HasInnoDBBufferPoolSize(userConf map[string]string) bool {
+	for k := range userConf {
+		if strings.TrimPrefix(normalizeConfKey(k), "loose_") == "innodb_buffer_pool_size" {
+			return true
+		}
+	}
+	return false
+}
+
+// InnoDBBufferPoolSize returns `innodb_buffer_pool_size` in bytes from my.cnf generated by Generate.
+// The value may have a suffix of K, M, G, T, or P as mysqld accepts.
+func InnoDBBufferPoolSize(conf string) (int64, error) {
+	inMysqld := false
+	for _, line := range strings.Split(conf, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "[") {
+			inMysqld = line == "[mysqld]"
+			continue
+		}
+		if !inMysqld {
+			continue
+		}
+
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		k = normalizeConfKey(strings.TrimSpace(k))
+		if k != "innodb_buffer_pool_size" && k != "loose_innodb_buffer_pool_size" {
+			continue
+		}
+		return parseSize(strings.TrimSpace(v))
+	}
+	return 0, fmt.Errorf("innodb_buffer_pool_size is not found")
+}
+
+func parseSize(v string) (int64, error) {
+	if v == "" {
+		return 0, fmt.Errorf("empty size")
+	}
+
+	var shift uint
+	switch v[len(v)-1] {
+	case 'k', 'K':
+		shift = 10
+	case 'm', 'M':
+		shift = 20
+	case 'g', 'G':
+		shift = 30
+	case 't', 'T':
+		shift = 40
+	case 'p', 'P':
+		shift = 50
+	}
+	if shift > 0 {
+		v = v[:len(v)-1]
+	}
+
+	n, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid size %q: %w", v, err)
+	}
+	return n << shift, nil
+}
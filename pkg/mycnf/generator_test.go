@@ -2,6 +2,7 @@ package mycnf
 
 import (
 	_ "embed"
+	"strings"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
@@ -13,13 +14,19 @@ func TestGenerator(t *testing.T) {
 	t.Run("loose", testLoose)
 	t.Run("buffer-pool-size", testBufferPoolSize)
 	t.Run("opaque", testOpaque)
+	t.Run("dirs", testDirs)
+	t.Run("disable-slow-query-log", testDisableSlowQueryLog)
+	t.Run("audit-log", testAuditLog)
+	t.Run("redo-log", testRedoLog)
+	t.Run("slow-query-log", testSlowQueryLog)
+	t.Run("max-connections", testMaxConnections)
 }
 
 //go:embed testdata/nil.cnf
 var nilCnf string
 
 func testGeneratorNil(t *testing.T) {
-	actual := Generate(nil, 100<<20)
+	actual := Generate(nil, Options{MemTotal: 100 << 20})
 	if !cmp.Equal(nilCnf, actual) {
 		t.Error("not matched", cmp.Diff(nilCnf, actual))
 	}
@@ -32,7 +39,7 @@ func testNormalize(t *testing.T) {
 	actual := Generate(map[string]string{
 		"thread-cache-size": "200",
 		"foo":               "bar",
-	}, 1000<<20)
+	}, Options{MemTotal: 1000 << 20})
 	if !cmp.Equal(normalizeCnf, actual) {
 		t.Error("not matched", cmp.Diff(normalizeCnf, actual))
 	}
@@ -46,7 +53,7 @@ func testLoose(t *testing.T) {
 		"innodb_numa_interleave":                 "OFF",
 		"loose_temptable_use_mmap":               "ON",
 		"loose_innodb_validate_tablespace_paths": "ON",
-	}, 1000<<20)
+	}, Options{MemTotal: 1000 << 20})
 	if !cmp.Equal(looseCnf, actual) {
 		t.Error("not matched", cmp.Diff(looseCnf, actual))
 	}
@@ -58,7 +65,7 @@ var bufsizeCnf string
 func testBufferPoolSize(t *testing.T) {
 	actual := Generate(map[string]string{
 		"innodb_buffer_pool_size": "268435456",
-	}, 1000<<20)
+	}, Options{MemTotal: 1000 << 20})
 	if !cmp.Equal(bufsizeCnf, actual) {
 		t.Error("not matched", cmp.Diff(bufsizeCnf, actual))
 	}
@@ -73,9 +80,153 @@ func testOpaque(t *testing.T) {
 performance-schema-instrument='wait/synch/%/innodb/%=ON'
 performance-schema-instrument='wait/lock/table/sql/handler=OFF'
 performance-schema-instrument='wait/lock/metadata/sql/mdl=OFF'
-`}, 100<<20)
+`}, Options{MemTotal: 100 << 20})
 	if !cmp.Equal(opaqueCnf, actual) {
 		t.Error("not matched", cmp.Diff(opaqueCnf, actual))
 	}
 
 }
+
+//go:embed testdata/dirs.cnf
+var dirsCnf string
+
+func testDirs(t *testing.T) {
+	actual := Generate(map[string]string{
+		"tmpdir": "/var/tmp",
+	}, Options{MemTotal: 100 << 20, DataDir: "/data/mysql", TmpDir: "/mysql-tmp"})
+	if !cmp.Equal(dirsCnf, actual) {
+		t.Error("not matched", cmp.Diff(dirsCnf, actual))
+	}
+}
+
+//go:embed testdata/noslowlog.cnf
+var noSlowLogCnf string
+
+func testDisableSlowQueryLog(t *testing.T) {
+	actual := Generate(map[string]string{
+		"slow-query-log": "ON",
+	}, Options{MemTotal: 100 << 20, DisableSlowQueryLog: true})
+	if !cmp.Equal(noSlowLogCnf, actual) {
+		t.Error("not matched", cmp.Diff(noSlowLogCnf, actual))
+	}
+}
+
+//go:embed testdata/auditlog.cnf
+var auditLogCnf string
+
+func testAuditLog(t *testing.T) {
+	actual := Generate(map[string]string{
+		"audit-log-format": "NEW",
+		"audit_log_file":   "/tmp/audit.log",
+	}, Options{MemTotal: 100 << 20, EnableAuditLog: true})
+	if !cmp.Equal(auditLogCnf, actual) {
+		t.Error("not matched", cmp.Diff(auditLogCnf, actual))
+	}
+}
+
+//go:embed testdata/redolog.cnf
+var redoLogCnf string
+
+//go:embed testdata/redologcapacity.cnf
+var redoLogCapacityCnf string
+
+func testRedoLog(t *testing.T) {
+	actual := Generate(map[string]string{
+		"innodb-log-file-size": "1G",
+	}, Options{MemTotal: 100 << 20, RedoLog: map[string]string{
+		"innodb_log_file_size":      "2147483648",
+		"innodb_log_files_in_group": "4",
+	}})
+	if !cmp.Equal(redoLogCnf, actual) {
+		t.Error("not matched", cmp.Diff(redoLogCnf, actual))
+	}
+
+	actual = Generate(map[string]string{
+		"loose_innodb_log_file_size": "1G",
+	}, Options{MemTotal: 100 << 20, RedoLog: map[string]string{
+		"innodb_redo_log_capacity": "8589934592",
+	}})
+	if !cmp.Equal(redoLogCapacityCnf, actual) {
+		t.Error("not matched", cmp.Diff(redoLogCapacityCnf, actual))
+	}
+}
+
+//go:embed testdata/slowlog.cnf
+var slowLogCnf string
+
+func testSlowQueryLog(t *testing.T) {
+	actual := Generate(map[string]string{
+		"long-query-time":                     "10",
+		"loose_log_queries_not_using_indexes": "OFF",
+	}, Options{MemTotal: 100 << 20, SlowQueryLog: map[string]string{
+		"long_query_time":               "0.5",
+		"log_queries_not_using_indexes": "ON",
+	}})
+	if !cmp.Equal(slowLogCnf, actual) {
+		t.Error("not matched", cmp.Diff(slowLogCnf, actual))
+	}
+}
+
+func testMaxConnections(t *testing.T) {
+	actual := Generate(nil, Options{MemTotal: 100 << 20, MaxConnections: 400})
+	if !strings.Contains(actual, "\nmax_connections = 400\n") {
+		t.Error("derived max_connections is not used", actual)
+	}
+
+	for _, k := range []string{"max_connections", "max-connections", "loose_max_connections"} {
+		actual = Generate(map[string]string{k: "1000"}, Options{MemTotal: 100 << 20, MaxConnections: 400})
+		if strings.Contains(actual, "= 400\n") || !strings.Contains(actual, "max_connections = 1000\n") {
+			t.Error("max_connections in userConf should take precedence", k, actual)
+		}
+	}
+}
+
+func TestHasInnoDBBufferPoolSize(t *testing.T) {
+	for conf, expected := range map[string]bool{
+		"innodb_buffer_pool_size":       true,
+		"innodb-buffer-pool-size":       true,
+		"loose_innodb_buffer_pool_size": true,
+		"innodb_buffer_pool_instances":  false,
+	} {
+		if actual := HasInnoDBBufferPoolSize(map[string]string{conf: "1G"}); actual != expected {
+			t.Errorf("unexpected result for %s: %v", conf, actual)
+		}
+	}
+	if HasInnoDBBufferPoolSize(nil) {
+		t.Error("nil conf should not have innodb_buffer_pool_size")
+	}
+}
+
+func TestInnoDBBufferPoolSize(t *testing.T) {
+	size, err := InnoDBBufferPoolSize(Generate(nil, Options{MemTotal: 1 << 30}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != calcBufferSize(1<<30) {
+		t.Errorf("unexpected size: %d", size)
+	}
+
+	for v, expected := range map[string]int64{
+		"134217728": 128 << 20,
+		"512M":      512 << 20,
+		"8G":        8 << 30,
+		"2t":        2 << 40,
+	} {
+		size, err := InnoDBBufferPoolSize(Generate(map[string]string{"innodb-buffer-pool-size": v}, Options{MemTotal: 1 << 30}))
+		if err != nil {
+			t.Fatal(v, err)
+		}
+		if size != expected {
+			t.Errorf("unexpected size for %s: %d", v, size)
+		}
+	}
+
+	_, err = InnoDBBufferPoolSize(Generate(map[string]string{"innodb_buffer_pool_size": "foo"}, Options{MemTotal: 1 << 30}))
+	if err == nil {
+		t.Error("invalid size should be an error")
+	}
+	_, err = InnoDBBufferPoolSize("[client]\ninnodb_buffer_pool_size = 8G\n")
+	if err == nil {
+		t.Error("innodb_buffer_pool_size outside of [mysqld] should not be found")
+	}
+}
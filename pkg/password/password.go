@@ -29,6 +29,9 @@ const (
 	writablePasswordKey    = "WRITABLE_PASSWORD"
 )
 
+// UserPasswordKey is the key of the password in Secrets for users in `spec.users`.
+const UserPasswordKey = "password"
+
 // MySQLPassword represents a set of passwords of MySQL users for MOCO
 type MySQLPassword struct {
 	admin      string
@@ -140,18 +143,22 @@ user={{printf "%q" .User}}
 password={{printf "%q" .Password}}
 {{if .Socket -}}
 socket={{printf "%q" .Socket}}
+{{end}}{{if .Host -}}
+host={{printf "%q" .Host}}
 {{end}}`))
 
-func formatMyCnf(user, pwd, socket string) []byte {
+func formatMyCnf(user, pwd, socket, host string) []byte {
 	buf := new(bytes.Buffer)
 	err := mycnfTmpl.Execute(buf, struct {
 		User     string
 		Password string
 		Socket   string
+		Host     string
 	}{
 		user,
 		pwd,
 		socket,
+		host,
 	})
 	if err != nil {
 		panic(err)
@@ -159,6 +166,21 @@ func formatMyCnf(user, pwd, socket string) []byte {
 	return buf.Bytes()
 }
 
+// UserMyCnf returns my.cnf for a user in `spec.users`.
+// It does not contain the password so that it can be given separately,
+// e.g. from the password Secret of the user through an environment variable.
+func UserMyCnf(user, socket, host string) []byte {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "[client]\nuser=%q\n", user)
+	if socket != "" {
+		fmt.Fprintf(buf, "socket=%q\n", socket)
+	}
+	if host != "" {
+		fmt.Fprintf(buf, "host=%q\n", host)
+	}
+	return buf.Bytes()
+}
+
 // ToMyCnfSecret converts MySQLPassword to Secret in my.cnf format.
 // The caller have to fill Name and Namespace of the returned Secret.
 func (p MySQLPassword) ToMyCnfSecret() *corev1.Secret {
@@ -169,15 +191,20 @@ func (p MySQLPassword) ToMyCnfSecret() *corev1.Secret {
 			},
 		},
 		Data: map[string][]byte{
-			constants.AdminMyCnf:    formatMyCnf(constants.AdminUser, p.admin, ""),
-			constants.ExporterMyCnf: formatMyCnf(constants.ExporterUser, p.exporter, filepath.Join(constants.RunPath, "mysqld.sock")),
-			constants.BackupMyCnf:   formatMyCnf(constants.BackupUser, p.backup, ""),
-			constants.ReadOnlyMyCnf: formatMyCnf(constants.ReadOnlyUser, p.readOnly, ""),
-			constants.WritableMyCnf: formatMyCnf(constants.WritableUser, p.writable, ""),
+			constants.AdminMyCnf:    formatMyCnf(constants.AdminUser, p.admin, "", ""),
+			constants.ExporterMyCnf: formatMyCnf(constants.ExporterUser, p.exporter, filepath.Join(constants.RunPath, "mysqld.sock"), ""),
+			constants.BackupMyCnf:   formatMyCnf(constants.BackupUser, p.backup, "", ""),
+			constants.ReadOnlyMyCnf: formatMyCnf(constants.ReadOnlyUser, p.readOnly, "", ""),
+			constants.WritableMyCnf: formatMyCnf(constants.WritableUser, p.writable, "", ""),
 		},
 	}
 }
 
+// ExporterMyCnf returns my.cnf for moco-exporter to connect to host over TCP.
+func (p MySQLPassword) ExporterMyCnf(host string) []byte {
+	return formatMyCnf(constants.ExporterUser, p.exporter, "", host)
+}
+
 // Admin returns the password for moco-admin.
 func (p MySQLPassword) Admin() string {
 	return p.admin
@@ -218,6 +245,11 @@ func (p MySQLPassword) Writable() string {
 	return p.writable
 }
 
+// NewUserPassword generates a random password for a user in `spec.users`.
+func NewUserPassword() (string, error) {
+	return generateRandomPassword()
+}
+
 func generateRandomPassword() (string, error) {
 	password := make([]byte, passwordBytes)
 	_, err := rand.Read(password)